@@ -0,0 +1,178 @@
+/*
+Copyright 2025 The Vitess Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package cli
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"os/signal"
+	"strings"
+	"syscall"
+	"time"
+
+	"github.com/spf13/cobra"
+
+	"vitess.io/vitess/go/acl"
+	"vitess.io/vitess/go/vt/grpccommon"
+	"vitess.io/vitess/go/vt/log"
+	"vitess.io/vitess/go/vt/logutil"
+	"vitess.io/vitess/go/vt/servenv"
+	"vitess.io/vitess/go/vt/topo/topoproto"
+	"vitess.io/vitess/go/vt/vtgate/cdc"
+	"vitess.io/vitess/go/vt/vtgate/vtgateconn"
+
+	binlogdatapb "vitess.io/vitess/go/vt/proto/binlogdata"
+	vtgatepb "vitess.io/vitess/go/vt/proto/vtgate"
+
+	// Import and register the gRPC vtgateconn client
+	_ "vitess.io/vitess/go/vt/vtgate/grpcvtgateconn"
+)
+
+var (
+	server        string
+	keyspace      string
+	tables        []string
+	tabletType    = "primary"
+	startPosition = "current"
+
+	config = cdc.Config{
+		CheckpointTopic: "vitess-cdc-checkpoints",
+		Transactional:   true,
+		Tombstones:      true,
+		Format:          cdc.FormatJSON,
+		MaxBatchEvents:  1000,
+		CommitInterval:  time.Second,
+	}
+
+	Main = &cobra.Command{
+		Use:   "vtcdc",
+		Short: "vtcdc streams the row changes of a keyspace from vtgate to a Kafka-protocol broker, as Debezium change events.",
+		Long: `vtcdc streams the row changes of a keyspace from vtgate to a Kafka-protocol broker, as the change events
+of the Debezium connector for Vitess. They are serialized like the JSON converter with schemas disabled, or
+in Avro with --format=avro. vtcdc does not use a schema registry: every Avro message is an object container
+file that embeds the schema of its record.
+
+The changes of a table are written to the '<topic-prefix>.<keyspace>.<table>' topic, keyed by the primary key
+of their row. The vgtid of the stream is checkpointed, in the same Kafka transaction as the changes it covers,
+to the checkpoint topic, and vtcdc resumes from its last checkpoint when it is restarted.`,
+		Example: `vtcdc \
+	--server vtgate-host.my.domain:15991 \
+	--keyspace commerce \
+	--name commerce-cdc \
+	--kafka-brokers kafka-1:9092,kafka-2:9092`,
+		Args:    cobra.NoArgs,
+		Version: servenv.AppVersion.String(),
+		PreRunE: servenv.CobraPreRunE,
+		RunE:    run,
+	}
+)
+
+func init() {
+	servenv.MoveFlagsToCobraCommand(Main)
+
+	Main.Flags().StringVar(&server, "server", server, "vtgate server to stream from")
+	Main.Flags().StringVar(&keyspace, "keyspace", keyspace, "Keyspace to stream the row changes of")
+	Main.Flags().StringSliceVar(&tables, "tables", tables, "Tables to stream the row changes of, all of them if empty")
+	Main.Flags().StringVar(&tabletType, "tablet-type", tabletType, "Type of the tablets to stream from")
+	Main.Flags().StringVar(&startPosition, "start-position", startPosition, "Position to start from when there is no checkpoint: 'current' streams the changes from now on, and an empty position copies the existing rows first")
+
+	Main.Flags().StringVar(&config.Name, "name", config.Name, "Name of the sink, unique across the sinks that share brokers, used as the logical server name of the change events, the producer transactional id and the key of the checkpoints")
+	Main.Flags().StringSliceVar(&config.Brokers, "kafka-brokers", config.Brokers, "Addresses of the Kafka-protocol brokers to bootstrap from")
+	Main.Flags().StringVar(&config.TopicPrefix, "topic-prefix", config.TopicPrefix, "Prefix of the topics of the tables, the name of the sink if empty")
+	Main.Flags().StringVar(&config.CheckpointTopic, "checkpoint-topic", config.CheckpointTopic, "Compacted topic that holds the checkpoints of the sinks, created if it does not exist")
+	Main.Flags().BoolVar(&config.Transactional, "transactional", config.Transactional, "Write the changes and their checkpoint in Kafka transactions, which brokers that do not support transactions require to disable")
+	Main.Flags().BoolVar(&config.Tombstones, "tombstones", config.Tombstones, "Write a tombstone after the deletion of a row")
+	Main.Flags().StringVar(&config.Format, "format", config.Format, "Format of the keys and change events: 'json', or 'avro' for Avro object container files that embed their schema")
+	Main.Flags().IntVar(&config.MaxBatchEvents, "max-batch-events", config.MaxBatchEvents, "Number of changes after which a batch is committed")
+	Main.Flags().DurationVar(&config.CommitInterval, "commit-interval", config.CommitInterval, "Time after which a batch is committed")
+
+	Main.MarkFlagRequired("server")
+	Main.MarkFlagRequired("keyspace")
+	Main.MarkFlagRequired("name")
+	Main.MarkFlagRequired("kafka-brokers")
+
+	grpccommon.RegisterFlags(Main.Flags())
+	acl.RegisterFlags(Main.Flags())
+}
+
+func run(cmd *cobra.Command, args []string) error {
+	logger := logutil.NewConsoleLogger()
+	cmd.SetOutput(logutil.NewLoggerWriter(logger))
+	_ = cmd.Flags().Set("logtostderr", "true")
+
+	servenv.Init()
+
+	tt, err := topoproto.ParseTabletType(tabletType)
+	if err != nil {
+		return err
+	}
+	if startPosition != "current" && startPosition != "" && !strings.Contains(startPosition, "/") {
+		return fmt.Errorf("invalid start position %q", startPosition)
+	}
+
+	ctx, cancel := signal.NotifyContext(cmd.Context(), os.Interrupt, syscall.SIGTERM)
+	defer cancel()
+
+	sink, err := cdc.NewSink(ctx, config)
+	if err != nil {
+		return err
+	}
+	defer sink.Close()
+	vgtid, err := sink.Checkpoint(ctx)
+	if err != nil {
+		return err
+	}
+	if vgtid == nil {
+		vgtid = &binlogdatapb.VGtid{ShardGtids: []*binlogdatapb.ShardGtid{{
+			Keyspace: keyspace,
+			Gtid:     startPosition,
+		}}}
+		log.Infof("No checkpoint for sink %s, starting from position %q", config.Name, startPosition)
+	} else {
+		log.Infof("Resuming sink %s from %v", config.Name, vgtid)
+	}
+
+	filter := &binlogdatapb.Filter{}
+	if len(tables) == 0 {
+		filter.Rules = append(filter.Rules, &binlogdatapb.Rule{Match: "/.*"})
+	}
+	for _, table := range tables {
+		filter.Rules = append(filter.Rules, &binlogdatapb.Rule{Match: table})
+	}
+	flags := &vtgatepb.VStreamFlags{
+		// Heartbeats let an idle stream commit its last vgtid.
+		HeartbeatInterval: uint32(max(config.CommitInterval/time.Second, 1)),
+	}
+
+	conn, err := vtgateconn.Dial(ctx, server)
+	if err != nil {
+		return err
+	}
+	defer conn.Close()
+	reader, err := conn.VStream(ctx, tt, vgtid, filter, flags)
+	if err != nil {
+		return err
+	}
+	err = sink.Run(ctx, vgtid, reader)
+	if errors.Is(ctx.Err(), context.Canceled) {
+		log.Infof("Sink %s stopped", config.Name)
+		return nil
+	}
+	return err
+}
@@ -0,0 +1,44 @@
+/*
+Copyright 2025 The Vitess Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package cli
+
+// This plugin imports jwtauthserver to register the OIDC/JWT implementation of AuthServer.
+
+import (
+	"time"
+
+	"vitess.io/vitess/go/mysql/jwtauthserver"
+	"vitess.io/vitess/go/vt/vtgate"
+)
+
+var jwtAuthConfig = jwtauthserver.Config{
+	UsernameClaim:   "sub",
+	GroupsClaim:     "groups",
+	RefreshInterval: time.Hour,
+}
+
+func init() {
+	Main.Flags().StringVar(&jwtAuthConfig.Issuer, "mysql-auth-jwt-issuer", jwtAuthConfig.Issuer, "Issuer (iss claim) of the JWTs accepted by the jwt auth server. Unless a JWKS URL or file is given, the JWKS is discovered from the OpenID configuration of the issuer.")
+	Main.Flags().StringVar(&jwtAuthConfig.Audience, "mysql-auth-jwt-audience", jwtAuthConfig.Audience, "If set, the audience (aud claim) the JWTs accepted by the jwt auth server must be issued for.")
+	Main.Flags().StringVar(&jwtAuthConfig.JWKSURL, "mysql-auth-jwt-jwks-url", jwtAuthConfig.JWKSURL, "URL to fetch the JWKS used to verify JWTs from, instead of discovering it from the issuer.")
+	Main.Flags().StringVar(&jwtAuthConfig.JWKSFile, "mysql-auth-jwt-jwks-file", jwtAuthConfig.JWKSFile, "Path to a local JWKS file used to verify JWTs, for use without network access to the issuer.")
+	Main.Flags().StringVar(&jwtAuthConfig.UsernameClaim, "mysql-auth-jwt-username-claim", jwtAuthConfig.UsernameClaim, "JWT claim holding the username, which must match the MySQL user. Nested claims can be given as a dot separated path.")
+	Main.Flags().StringVar(&jwtAuthConfig.GroupsClaim, "mysql-auth-jwt-groups-claim", jwtAuthConfig.GroupsClaim, "JWT claim holding the groups of the caller, passed on to the table ACLs. Nested claims can be given as a dot separated path.")
+	Main.Flags().DurationVar(&jwtAuthConfig.RefreshInterval, "mysql-auth-jwt-jwks-refresh-interval", jwtAuthConfig.RefreshInterval, "How often to reload the JWKS used to verify JWTs. 0 disables periodic reloads.")
+
+	vtgate.RegisterPluginInitializer(func() { jwtauthserver.Init(jwtAuthConfig) })
+}
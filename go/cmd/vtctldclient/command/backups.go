@@ -41,6 +41,29 @@ var (
 		Args:                  cobra.ExactArgs(1),
 		RunE:                  commandBackup,
 	}
+	// BackupExpire makes a BackupExpire gRPC call to a vtctld.
+	BackupExpire = &cobra.Command{
+		Use:   "BackupExpire --retention <interval>:<window>[,<interval>:<window>...] [--dry-run] <keyspace/shard>",
+		Short: "Removes the backups of the given shard that a time-window retention policy does not retain.",
+		Long: `Removes the backups of the given shard that a time-window retention policy does not retain.
+
+Each retention rule keeps one backup per <interval> among the backups taken within <window>, e.g.
+--retention 1h:48h,24h:720h keeps hourly backups for two days, and daily backups for thirty days.
+The most recent full backup and the most recent restorable backup are always retained, as well as the
+full and incremental backups that the restore chains of the retained backups depend on.`,
+		Example:               `BackupExpire --retention 1h:48h,24h:720h --dry-run commerce/0`,
+		DisableFlagsInUseLine: true,
+		Args:                  cobra.ExactArgs(1),
+		RunE:                  commandBackupExpire,
+	}
+	// BackupRestorePlan makes a BackupRestorePlan gRPC call to a vtctld.
+	BackupRestorePlan = &cobra.Command{
+		Use:                   "BackupRestorePlan {--restore-to-pos <pos>|--restore-to-timestamp <timestamp>} <keyspace/shard>",
+		Short:                 "Computes the minimal chain of full and incremental backups to restore the given shard to a position or timestamp.",
+		DisableFlagsInUseLine: true,
+		Args:                  cobra.ExactArgs(1),
+		RunE:                  commandBackupRestorePlan,
+	}
 	// BackupShard makes a BackupShard gRPC call to a vtctld.
 	BackupShard = &cobra.Command{
 		Use:   "BackupShard [--concurrency <concurrency>] [--allow-primary] [--incremental-from-pos=<pos>|<backup-name>|auto] [--upgrade-safe] <keyspace/shard>",
@@ -52,6 +75,14 @@ If no replica-type tablet can be found, the backup can be taken on the primary i
 		Args:                  cobra.ExactArgs(1),
 		RunE:                  commandBackupShard,
 	}
+	// BackupValidate makes a BackupValidate gRPC call to a vtctld.
+	BackupValidate = &cobra.Command{
+		Use:                   "BackupValidate <keyspace/shard>",
+		Short:                 "Validates the continuity of the incremental backup chains of the given shard, and reports the incremental backups that cannot be restored.",
+		DisableFlagsInUseLine: true,
+		Args:                  cobra.ExactArgs(1),
+		RunE:                  commandBackupValidate,
+	}
 	// GetBackups makes a GetBackups gRPC call to a vtctld.
 	GetBackups = &cobra.Command{
 		Use:                   "GetBackups [--limit <limit>] [--json] <keyspace/shard>",
@@ -126,6 +157,132 @@ func commandBackup(cmd *cobra.Command, args []string) error {
 	}
 }
 
+var backupExpireOptions = struct {
+	Retention []string
+	DryRun    bool
+}{}
+
+func commandBackupExpire(cmd *cobra.Command, args []string) error {
+	keyspace, shard, err := topoproto.ParseKeyspaceShard(cmd.Flags().Arg(0))
+	if err != nil {
+		return err
+	}
+
+	if len(backupExpireOptions.Retention) == 0 {
+		return fmt.Errorf("--retention is required")
+	}
+
+	rules := make([]*vtctldatapb.BackupRetentionRule, 0, len(backupExpireOptions.Retention))
+	for _, s := range backupExpireOptions.Retention {
+		rule, err := mysqlctl.ParseBackupRetentionRule(s)
+		if err != nil {
+			return err
+		}
+
+		rules = append(rules, &vtctldatapb.BackupRetentionRule{
+			Interval: protoutil.DurationToProto(rule.Interval),
+			Window:   protoutil.DurationToProto(rule.Window),
+		})
+	}
+
+	cli.FinishedParsing(cmd)
+
+	resp, err := client.BackupExpire(commandCtx, &vtctldatapb.BackupExpireRequest{
+		Keyspace: keyspace,
+		Shard:    shard,
+		Rules:    rules,
+		DryRun:   backupExpireOptions.DryRun,
+	})
+	if err != nil {
+		return err
+	}
+
+	data, err := cli.MarshalJSON(resp)
+	if err != nil {
+		return err
+	}
+
+	fmt.Printf("%s\n", data)
+	return nil
+}
+
+var backupRestorePlanOptions = struct {
+	RestoreToPos       string
+	RestoreToTimestamp string
+}{}
+
+func commandBackupRestorePlan(cmd *cobra.Command, args []string) error {
+	keyspace, shard, err := topoproto.ParseKeyspaceShard(cmd.Flags().Arg(0))
+	if err != nil {
+		return err
+	}
+
+	if backupRestorePlanOptions.RestoreToPos != "" && backupRestorePlanOptions.RestoreToTimestamp != "" {
+		return fmt.Errorf("--restore-to-pos and --restore-to-timestamp are mutually exclusive")
+	}
+
+	if backupRestorePlanOptions.RestoreToPos == "" && backupRestorePlanOptions.RestoreToTimestamp == "" {
+		return fmt.Errorf("one of --restore-to-pos or --restore-to-timestamp is required")
+	}
+
+	var restoreToTimestamp time.Time
+	if backupRestorePlanOptions.RestoreToTimestamp != "" {
+		restoreToTimestamp, err = mysqlctl.ParseRFC3339(backupRestorePlanOptions.RestoreToTimestamp)
+		if err != nil {
+			return err
+		}
+	}
+
+	cli.FinishedParsing(cmd)
+
+	req := &vtctldatapb.BackupRestorePlanRequest{
+		Keyspace:     keyspace,
+		Shard:        shard,
+		RestoreToPos: backupRestorePlanOptions.RestoreToPos,
+	}
+	if !restoreToTimestamp.IsZero() {
+		req.RestoreToTimestamp = protoutil.TimeToProto(restoreToTimestamp)
+	}
+
+	resp, err := client.BackupRestorePlan(commandCtx, req)
+	if err != nil {
+		return err
+	}
+
+	data, err := cli.MarshalJSON(resp)
+	if err != nil {
+		return err
+	}
+
+	fmt.Printf("%s\n", data)
+	return nil
+}
+
+func commandBackupValidate(cmd *cobra.Command, args []string) error {
+	keyspace, shard, err := topoproto.ParseKeyspaceShard(cmd.Flags().Arg(0))
+	if err != nil {
+		return err
+	}
+
+	cli.FinishedParsing(cmd)
+
+	resp, err := client.BackupValidate(commandCtx, &vtctldatapb.BackupValidateRequest{
+		Keyspace: keyspace,
+		Shard:    shard,
+	})
+	if err != nil {
+		return err
+	}
+
+	data, err := cli.MarshalJSON(resp)
+	if err != nil {
+		return err
+	}
+
+	fmt.Printf("%s\n", data)
+	return nil
+}
+
 var backupShardOptions = struct {
 	AllowPrimary         bool
 	Concurrency          int32
@@ -308,6 +465,16 @@ func init() {
 	BackupShard.Flags().DurationVar(&backupShardOptions.MysqlShutdownTimeout, "mysql-shutdown-timeout", mysqlctl.DefaultShutdownTimeout, "Timeout to use when MySQL is being shut down.")
 	Root.AddCommand(BackupShard)
 
+	BackupExpire.Flags().StringSliceVar(&backupExpireOptions.Retention, "retention", nil, "Time-window retention rules, in the <interval>:<window> format. A backup is retained if any of the rules retains it.")
+	BackupExpire.Flags().BoolVar(&backupExpireOptions.DryRun, "dry-run", false, "Only list the backups the retention policy expires, do not remove them.")
+	Root.AddCommand(BackupExpire)
+
+	BackupRestorePlan.Flags().StringVar(&backupRestorePlanOptions.RestoreToPos, "restore-to-pos", "", "Plan a point in time recovery that ends with the given position.")
+	BackupRestorePlan.Flags().StringVar(&backupRestorePlanOptions.RestoreToTimestamp, "restore-to-timestamp", "", "Plan a point in time recovery that restores up to, and excluding, the given timestamp in RFC3339 format (`2006-01-02T15:04:05Z07:00`).")
+	Root.AddCommand(BackupRestorePlan)
+
+	Root.AddCommand(BackupValidate)
+
 	GetBackups.Flags().Uint32VarP(&getBackupsOptions.Limit, "limit", "l", 0, "Retrieve only the most recent N backups.")
 	GetBackups.Flags().BoolVarP(&getBackupsOptions.OutputJSON, "json", "j", false, "Output backup info in JSON format rather than a list of backups.")
 	Root.AddCommand(GetBackups)
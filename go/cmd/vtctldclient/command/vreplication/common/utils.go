@@ -25,6 +25,7 @@ import (
 	"time"
 
 	"github.com/spf13/cobra"
+	"google.golang.org/protobuf/encoding/protojson"
 
 	"vitess.io/vitess/go/cmd/vtctldclient/cli"
 	"vitess.io/vitess/go/vt/key"
@@ -147,10 +148,24 @@ func ParseTabletTypes(cmd *cobra.Command) error {
 
 func ParseTableMaterializeSettings(tableSettings string, parser *sqlparser.Parser) ([]*vtctldatapb.TableMaterializeSettings, error) {
 	tableMaterializeSettings := make([]*vtctldatapb.TableMaterializeSettings, 0)
-	err := json.Unmarshal([]byte(tableSettings), &tableMaterializeSettings)
-	if err != nil {
+	// Each setting is decoded as protobuf JSON, since encoding/json cannot
+	// decode the types of the column transforms, which are enums, from their
+	// names. Unknown fields are still ignored, as they were by encoding/json,
+	// and the snake_case names of the fields keep working, as do their
+	// lowerCamelCase names. Unlike with encoding/json, the names are case
+	// sensitive. Unknown enum names are discarded along with unknown fields,
+	// so the types of the column transforms are checked below.
+	var rawSettings []json.RawMessage
+	if err := json.Unmarshal([]byte(tableSettings), &rawSettings); err != nil {
 		return tableMaterializeSettings, fmt.Errorf("table-settings is not valid JSON")
 	}
+	for _, raw := range rawSettings {
+		tms := &vtctldatapb.TableMaterializeSettings{}
+		if err := (protojson.UnmarshalOptions{DiscardUnknown: true}).Unmarshal(raw, tms); err != nil {
+			return tableMaterializeSettings, fmt.Errorf("table-settings is not valid JSON: %v", err)
+		}
+		tableMaterializeSettings = append(tableMaterializeSettings, tms)
+	}
 	if len(tableMaterializeSettings) == 0 {
 		return tableMaterializeSettings, fmt.Errorf("empty table-settings")
 	}
@@ -161,6 +176,11 @@ func ParseTableMaterializeSettings(tableSettings string, parser *sqlparser.Parse
 		if tms.TargetTable == "" || tms.SourceExpression == "" {
 			return tableMaterializeSettings, fmt.Errorf("missing target_table or source_expression")
 		}
+		for column, transform := range tms.ColumnTransforms {
+			if transform.GetType() == binlogdatapb.ColumnTransform_UNSPECIFIED {
+				return tableMaterializeSettings, fmt.Errorf("missing or unknown type for the transform of column %s", column)
+			}
+		}
 		// Validate that the query is valid.
 		stmt, err := parser.Parse(tms.SourceExpression)
 		if err != nil {
@@ -26,6 +26,8 @@ import (
 
 	"vitess.io/vitess/go/cmd/vtctldclient/command"
 	"vitess.io/vitess/go/cmd/vtctldclient/command/vreplication/common"
+	"vitess.io/vitess/go/test/utils"
+	"vitess.io/vitess/go/vt/sqlparser"
 	"vitess.io/vitess/go/vt/topo"
 	"vitess.io/vitess/go/vt/topo/memorytopo"
 	"vitess.io/vitess/go/vt/vtctl/grpcvtctldserver"
@@ -33,6 +35,9 @@ import (
 	"vitess.io/vitess/go/vt/vtctl/vtctldclient"
 	"vitess.io/vitess/go/vt/vtenv"
 	"vitess.io/vitess/go/vt/vttablet/tmclient"
+
+	binlogdatapb "vitess.io/vitess/go/vt/proto/binlogdata"
+	vtctldatapb "vitess.io/vitess/go/vt/proto/vtctldata"
 )
 
 func TestParseAndValidateCreateOptions(t *testing.T) {
@@ -152,3 +157,40 @@ func SetupLocalVtctldClient(t *testing.T, ctx context.Context, cells ...string)
 	require.NoError(t, err, "failed to create local vtctld client which uses an internal vtctld server")
 	common.SetClient(client)
 }
+
+func TestParseTableMaterializeSettings(t *testing.T) {
+	parser := sqlparser.NewTestParser()
+	settings, err := common.ParseTableMaterializeSettings(`[{"target_table": "customer", "source_expression": "select * from customer",
+		"column_transforms": {"email": {"type": "HASH"}, "name": {"type": "TRUNCATE", "length": 1}}}]`, parser)
+	require.NoError(t, err)
+	require.Len(t, settings, 1)
+	utils.MustMatch(t, map[string]*binlogdatapb.ColumnTransform{
+		"email": {Type: binlogdatapb.ColumnTransform_HASH},
+		"name":  {Type: binlogdatapb.ColumnTransform_TRUNCATE, Length: 1},
+	}, settings[0].ColumnTransforms)
+
+	// The lowerCamelCase names of the fields are accepted as well, and unknown
+	// fields are ignored.
+	settings, err = common.ParseTableMaterializeSettings(`[{"targetTable": "customer", "sourceExpression": "select * from customer",
+		"createDdl": "copy", "unknown_setting": true, "columnTransforms": {"email": {"type": "TOKENIZE", "keyName": "email-key"}}}]`, parser)
+	require.NoError(t, err)
+	utils.MustMatch(t, []*vtctldatapb.TableMaterializeSettings{{
+		TargetTable:      "customer",
+		SourceExpression: "select * from customer",
+		CreateDdl:        "copy",
+		ColumnTransforms: map[string]*binlogdatapb.ColumnTransform{
+			"email": {Type: binlogdatapb.ColumnTransform_TOKENIZE, KeyName: "email-key"},
+		},
+	}}, settings)
+
+	// The names of the fields are case sensitive.
+	_, err = common.ParseTableMaterializeSettings(`[{"Target_Table": "customer", "source_expression": "select * from customer"}]`, parser)
+	require.EqualError(t, err, "missing target_table or source_expression")
+
+	_, err = common.ParseTableMaterializeSettings(`[{"target_table": "customer", "source_expression": "select * from customer",
+		"column_transforms": {"email": {"type": "ENCRYPT"}}}]`, parser)
+	require.EqualError(t, err, "missing or unknown type for the transform of column email")
+
+	_, err = common.ParseTableMaterializeSettings(`{"target_table": "customer"}`, parser)
+	require.EqualError(t, err, "table-settings is not valid JSON")
+}
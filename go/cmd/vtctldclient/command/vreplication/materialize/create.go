@@ -51,7 +51,14 @@ in the target-keyspace to store the results in. The second required key is 'sour
 and its value is the select query to run against the source table. An optional key/value pair
 can also be specified for 'create_ddl' which provides the DDL to create the target table if it
 does not exist -- you can alternatively specify a value of 'copy' if the target table schema
-should be copied as-is from the source keyspace. Here's an example value for table-settings:
+should be copied as-is from the source keyspace. Another optional key is 'column_transforms',
+which maps the names of source columns to the transform of their values before they are
+written to the target: HASH (hex SHA-256), TOKENIZE (hex HMAC-SHA256 with the key in the
+file named by 'key_name' in the --vreplication-column-transform-keys-dir of the target
+tablets), NULLIFY, TRUNCATE (to the given 'length' in characters, or bytes for binary values),
+or EXPRESSION (the given SQL 'expression', which can only refer to the column itself). Primary
+key columns cannot be transformed, and MoveTables workflows do not support column transforms.
+Here's an example value for table-settings:
 [
   {
     "target_table": "customer_one_email",
@@ -66,6 +73,16 @@ should be copied as-is from the source keyspace. Here's an example value for tab
     "target_table": "sales_by_sku",
     "source_expression": "select sku, count(*) as orders, sum(price) as revenue from corder group by sku",
     "create_ddl": "create table sales_by_sku (sku varbinary(128) not null primary key, orders bigint, revenue bigint)"
+  },
+  {
+    "target_table": "customer",
+    "source_expression": "select * from customer",
+    "create_ddl": "copy",
+    "column_transforms": {
+      "email": {"type": "HASH"},
+      "phone": {"type": "NULLIFY"},
+      "name": {"type": "EXPRESSION", "expression": "concat(left(name, 1), '***')"}
+    }
   }
 ]
 `,
@@ -0,0 +1,159 @@
+/*
+Copyright 2025 The Vitess Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+	http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package reshard
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/bndr/gotabulate"
+	"github.com/spf13/cobra"
+
+	"vitess.io/vitess/go/cmd/vtctldclient/cli"
+	"vitess.io/vitess/go/cmd/vtctldclient/command/vreplication/common"
+
+	vtctldatapb "vitess.io/vitess/go/vt/proto/vtctldata"
+)
+
+var (
+	reshardPlanOptions = struct {
+		sourceShards           []string
+		targetShards           []string
+		shardCount             int32
+		sampleSize             int64
+		hotKeyspaceIDThreshold float64
+	}{}
+
+	// reshardPlan makes a ReshardPlan gRPC call to a vtctld.
+	reshardPlan = &cobra.Command{
+		Use:   "plan",
+		Short: "Preview how the data of a keyspace would be distributed over new shards.",
+		Long: `Preview how the data of a keyspace would be distributed over new shards, without creating a workflow.
+The keyspace IDs of a sample of the rows of each table are computed using the table's primary vindex, and weighed
+by the table sizes of the source shards to project the rows and bytes of each of the target shards. Balanced split
+points are recommended from the same samples, and keyspace IDs that hold a large share of the rows are flagged
+as they cannot be split up. The rows are sampled from an RDONLY tablet of each source shard, or a REPLICA tablet
+when the shard has none, as sampling a table scans it.
+This can be used for splits, merges, and to preview the reversal of a Reshard.`,
+		Example:               `vtctldclient --server localhost:15999 reshard --workflow customer2customer --target-keyspace customer plan --target-shards="-40,40-80,80-"`,
+		SilenceUsage:          true,
+		DisableFlagsInUseLine: true,
+		Aliases:               []string{"Plan"},
+		Args:                  cobra.NoArgs,
+		PreRunE: func(cmd *cobra.Command, args []string) error {
+			if len(reshardPlanOptions.targetShards) == 0 && reshardPlanOptions.shardCount <= 0 {
+				return fmt.Errorf("either --target-shards or --shard-count must be provided")
+			}
+			if err := common.ValidateShards(reshardPlanOptions.sourceShards); err != nil {
+				return err
+			}
+			return common.ValidateShards(reshardPlanOptions.targetShards)
+		},
+		RunE: commandReshardPlan,
+	}
+)
+
+func commandReshardPlan(cmd *cobra.Command, args []string) error {
+	format, err := common.GetOutputFormat(cmd)
+	if err != nil {
+		return err
+	}
+	cli.FinishedParsing(cmd)
+
+	resp, err := common.GetClient().ReshardPlan(common.GetCommandCtx(), &vtctldatapb.ReshardPlanRequest{
+		Keyspace:               common.BaseOptions.TargetKeyspace,
+		SourceShards:           reshardPlanOptions.sourceShards,
+		TargetShards:           reshardPlanOptions.targetShards,
+		TargetShardCount:       reshardPlanOptions.shardCount,
+		SampleSize:             reshardPlanOptions.sampleSize,
+		HotKeyspaceIdThreshold: reshardPlanOptions.hotKeyspaceIDThreshold,
+	})
+	if err != nil {
+		return err
+	}
+	if format == "json" {
+		data, err := cli.MarshalJSONPretty(resp)
+		if err != nil {
+			return err
+		}
+		fmt.Fprintln(cmd.OutOrStdout(), string(data))
+		return nil
+	}
+	fmt.Fprint(cmd.OutOrStdout(), formatReshardPlan(resp))
+	return nil
+}
+
+// formatReshardPlan returns the text output of a reshard plan.
+func formatReshardPlan(resp *vtctldatapb.ReshardPlanResponse) string {
+	var sb strings.Builder
+	grid := func(title string, lines [][]string) {
+		fmt.Fprintf(&sb, "%s:\n", title)
+		if len(lines) == 1 {
+			sb.WriteString("None\n\n")
+			return
+		}
+		fmt.Fprintln(&sb, gotabulate.Create(lines).Render("grid"))
+	}
+	percent := func(part, total uint64) string {
+		if total == 0 {
+			return "0.00%"
+		}
+		return fmt.Sprintf("%.2f%%", float64(part)*100/float64(total))
+	}
+	shards := func(title string, shards []*vtctldatapb.ReshardPlanResponse_Shard) {
+		var totalRows, totalBytes uint64
+		for _, shard := range shards {
+			totalRows += shard.RowCount
+			totalBytes += shard.DataLength
+		}
+		lines := [][]string{{"Shard", "Rows", "Rows %", "Bytes", "Bytes %"}}
+		for _, shard := range shards {
+			lines = append(lines, []string{shard.Name,
+				fmt.Sprint(shard.RowCount), percent(shard.RowCount, totalRows),
+				fmt.Sprint(shard.DataLength), percent(shard.DataLength, totalBytes)})
+		}
+		grid(title, lines)
+	}
+
+	lines := [][]string{{"Table", "Rows", "Bytes", "Sampled Rows", "Skipped"}}
+	for _, table := range resp.Tables {
+		lines = append(lines, []string{table.Name, fmt.Sprint(table.RowCount), fmt.Sprint(table.DataLength),
+			fmt.Sprint(table.SampledRows), table.SkipReason})
+	}
+	grid("Tables", lines)
+	shards("Source shards", resp.SourceShards)
+	if len(resp.TargetShards) > 0 {
+		shards("Projected target shards", resp.TargetShards)
+	}
+	shards("Recommended shards", resp.RecommendedShards)
+	lines = [][]string{{"Keyspace ID", "Rows", "Bytes", "Share"}}
+	for _, hot := range resp.HotKeyspaceIds {
+		lines = append(lines, []string{hot.KeyspaceId, fmt.Sprint(hot.RowCount), fmt.Sprint(hot.DataLength),
+			fmt.Sprintf("%.2f%%", hot.Share*100)})
+	}
+	grid("Hot keyspace IDs", lines)
+	return sb.String()
+}
+
+func registerPlanCommand(root *cobra.Command) {
+	reshardPlan.Flags().StringSliceVar(&reshardPlanOptions.sourceShards, "source-shards", nil, "Source shards. Defaults to the serving shards of the keyspace.")
+	reshardPlan.Flags().StringSliceVar(&reshardPlanOptions.targetShards, "target-shards", nil, "Target shards to project the distribution of the data for.")
+	reshardPlan.Flags().Int32Var(&reshardPlanOptions.shardCount, "shard-count", 0, "Number of shards to recommend split points for. Defaults to the number of target shards.")
+	reshardPlan.Flags().Int64Var(&reshardPlanOptions.sampleSize, "sample-size", 10000, "Maximum number of rows to sample per table and source shard.")
+	reshardPlan.Flags().Float64Var(&reshardPlanOptions.hotKeyspaceIDThreshold, "hot-keyspace-id-threshold", 0.01, "Share of the rows, between 0 and 1, that a single keyspace ID must hold to be flagged as hot.")
+	root.AddCommand(reshardPlan)
+}
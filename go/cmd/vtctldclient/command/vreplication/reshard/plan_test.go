@@ -0,0 +1,45 @@
+/*
+Copyright 2025 The Vitess Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package reshard
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	vtctldatapb "vitess.io/vitess/go/vt/proto/vtctldata"
+)
+
+func TestFormatReshardPlan(t *testing.T) {
+	out := formatReshardPlan(&vtctldatapb.ReshardPlanResponse{
+		Tables: []*vtctldatapb.ReshardPlanResponse_Table{
+			{Name: "t1", RowCount: 5, DataLength: 500, SampledRows: 5},
+			{Name: "t2", RowCount: 10, DataLength: 100, SkipReason: "not in the vschema"},
+		},
+		SourceShards: []*vtctldatapb.ReshardPlanResponse_Shard{
+			{Name: "0", RowCount: 15, DataLength: 600},
+		},
+		RecommendedShards: []*vtctldatapb.ReshardPlanResponse_Shard{
+			{Name: "-166c", RowCount: 3, DataLength: 300},
+			{Name: "166c-", RowCount: 1, DataLength: 100},
+		},
+	})
+	assert.Contains(t, out, "|       t2 |      10 |      100 |               0 |    not in the vschema |")
+	assert.Contains(t, out, "|    -166c |       3 |    75.00% |      300 |     75.00% |")
+	assert.NotContains(t, out, "Projected target shards")
+	assert.Contains(t, out, "Hot keyspace IDs:\nNone\n")
+}
@@ -38,6 +38,7 @@ func registerReshardCommands(root *cobra.Command) {
 	root.AddCommand(reshard)
 
 	registerCreateCommand(reshard)
+	registerPlanCommand(reshard)
 	opts := &common.SubCommandsOpts{
 		SubCommand: "Reshard",
 		Workflow:   "cust2cust",
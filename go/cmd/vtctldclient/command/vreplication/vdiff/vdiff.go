@@ -22,6 +22,7 @@ import (
 	"io"
 	"math"
 	"reflect"
+	"sort"
 	"strings"
 	"time"
 
@@ -69,12 +70,21 @@ var (
 		MaxDiffDuration             time.Duration
 		RowDiffColumnTruncateAt     int64
 		AutoStart                   bool
+		ChunkRows                   int64
+		MaxConcurrentChunks         int64
+		ExportPath                  string
+		ExportRepairSQL             bool
 	}{}
 
 	deleteOptions = struct {
 		Arg string
 	}{}
 
+	repairOptions = struct {
+		UUID         uuid.UUID
+		TargetShards []string
+	}{}
+
 	resumeOptions = struct {
 		UUID         uuid.UUID
 		TargetShards []string
@@ -127,6 +137,15 @@ var (
 		if createOptions.MaxExtraRowsToCompare < 0 {
 			return fmt.Errorf("--max-extra-rows-to-compare must not be a negative value")
 		}
+		if createOptions.ChunkRows < 0 {
+			return fmt.Errorf("--chunk-rows must not be a negative value")
+		}
+		if createOptions.MaxConcurrentChunks < 1 {
+			return fmt.Errorf("--max-concurrent-chunks must be a positive value")
+		}
+		if createOptions.ExportRepairSQL && createOptions.ExportPath == "" {
+			return fmt.Errorf("--export-repair-sql requires --export-path")
+		}
 		return nil
 	}
 
@@ -178,6 +197,32 @@ vtctldclient --server localhost:15999 vdiff --workflow commerce2customer --targe
 		RunE: commandDelete,
 	}
 
+	// repair makes a VDiffRepair gRPC call to a vtctld.
+	repair = &cobra.Command{
+		Use:   "repair",
+		Short: "Repair the rows that a completed VDiff found to differ.",
+		Long: `Repair the rows that a completed VDiff found to differ.
+The source rows are read again for the primary keys of the rows that differ, and applied to the target the way the
+workflow applies them, while its streams are stopped. The primary keys are those of all the rows that differ when the
+VDiff exported them, and otherwise those of the sample rows of its report. The rows are then diffed again.
+The repair of a VDiff that was not created with --export-path is partial when its report did not sample every row that
+differs, and the number of differing rows that were not repaired is reported.`,
+		Example:               `vtctldclient --server localhost:15999 vdiff --workflow commerce2customer --target-keyspace customer repair a037a9e2-5628-11ee-8c99-0242ac120002`,
+		DisableFlagsInUseLine: true,
+		Aliases:               []string{"Repair"},
+		Args:                  cobra.ExactArgs(1),
+		PreRunE: func(cmd *cobra.Command, args []string) error {
+			uuid, err := uuid.Parse(args[0])
+			if err != nil {
+				return fmt.Errorf("invalid UUID provided: %v", err)
+			}
+			repairOptions.UUID = uuid
+
+			return common.ValidateShards(repairOptions.TargetShards)
+		},
+		RunE: commandRepair,
+	}
+
 	// resume makes a VDiffResume gRPC call to a vtctld.
 	resume = &cobra.Command{
 		Use:                   "resume",
@@ -300,6 +345,10 @@ func commandCreate(cmd *cobra.Command, args []string) error {
 		MaxDiffDuration:             protoutil.DurationToProto(createOptions.MaxDiffDuration),
 		RowDiffColumnTruncateAt:     createOptions.RowDiffColumnTruncateAt,
 		AutoStart:                   &createOptions.AutoStart,
+		ChunkRows:                   createOptions.ChunkRows,
+		MaxConcurrentChunks:         createOptions.MaxConcurrentChunks,
+		ExportPath:                  createOptions.ExportPath,
+		ExportRepairSql:             createOptions.ExportRepairSQL,
 	})
 
 	if err != nil {
@@ -395,6 +444,101 @@ func commandResume(cmd *cobra.Command, args []string) error {
 	return nil
 }
 
+// repairListing is the result of the repair of a table on a shard.
+type repairListing struct {
+	Shard          string
+	Table          string
+	RepairedKeys   int64
+	DeletedRows    int64
+	InsertedRows   int64
+	DifferingKeys  int64
+	UnrepairedRows int64
+}
+
+func commandRepair(cmd *cobra.Command, args []string) error {
+	format, err := common.GetOutputFormat(cmd)
+	if err != nil {
+		return err
+	}
+	cli.FinishedParsing(cmd)
+
+	resp, err := common.GetClient().VDiffRepair(common.GetCommandCtx(), &vtctldatapb.VDiffRepairRequest{
+		Workflow:       common.BaseOptions.Workflow,
+		TargetKeyspace: common.BaseOptions.TargetKeyspace,
+		Uuid:           repairOptions.UUID.String(),
+		TargetShards:   repairOptions.TargetShards,
+	})
+	if err != nil {
+		return err
+	}
+
+	listings := buildRepairListings(resp)
+	if format == "json" {
+		jsonText, err := cli.MarshalJSONPretty(listings)
+		if err != nil {
+			return err
+		}
+		output := string(jsonText)
+		if output == "null" {
+			output = "[]"
+		}
+		fmt.Fprintln(cmd.OutOrStdout(), output)
+		return nil
+	}
+	if len(listings) == 0 {
+		fmt.Fprintf(cmd.OutOrStdout(), "VDiff %s found no rows to repair\n", repairOptions.UUID)
+		return nil
+	}
+	fields := getStructFieldNames(repairListing{})
+	lines := [][]string{fields}
+	for _, listing := range listings {
+		v := reflect.ValueOf(*listing)
+		values := make([]string, 0, len(fields))
+		for _, field := range fields {
+			values = append(values, fmt.Sprint(v.FieldByName(field).Interface()))
+		}
+		lines = append(lines, values)
+	}
+	fmt.Fprintln(cmd.OutOrStdout(), gotabulate.Create(lines).Render("grid"))
+	for _, listing := range listings {
+		if listing.UnrepairedRows > 0 {
+			fmt.Fprintf(cmd.OutOrStdout(), "The repair is partial: only the sampled rows of the report were repaired, create the VDiff with --export-path to repair every row that differs\n")
+			break
+		}
+	}
+	return nil
+}
+
+// buildRepairListings returns the results of the repair on all shards, sorted
+// by shard and table.
+func buildRepairListings(resp *vtctldatapb.VDiffRepairResponse) []*repairListing {
+	var listings []*repairListing
+	for shard, resp := range resp.TabletResponses {
+		if resp == nil || resp.Output == nil {
+			continue
+		}
+		qr := sqltypes.Proto3ToResult(resp.Output)
+		for _, row := range qr.Named().Rows {
+			listings = append(listings, &repairListing{
+				Shard:          shard,
+				Table:          row.AsString("table_name", ""),
+				RepairedKeys:   row.AsInt64("repaired_keys", 0),
+				DeletedRows:    row.AsInt64("deleted_rows", 0),
+				InsertedRows:   row.AsInt64("inserted_rows", 0),
+				DifferingKeys:  row.AsInt64("differing_keys", 0),
+				UnrepairedRows: row.AsInt64("unrepaired_rows", 0),
+			})
+		}
+	}
+	sort.Slice(listings, func(i, j int) bool {
+		if listings[i].Shard != listings[j].Shard {
+			return listings[i].Shard < listings[j].Shard
+		}
+		return listings[i].Table < listings[j].Table
+	})
+	return listings
+}
+
 // tableSummary aggregates the current state of the table diff from all shards.
 type tableSummary struct {
 	TableName       string
@@ -680,13 +824,20 @@ func registerCommands(root *cobra.Command) {
 	create.Flags().DurationVar(&createOptions.WaitUpdateInterval, "wait-update-interval", time.Duration(1*time.Minute), "When waiting on a vdiff to finish, check and display the current status this often.")
 	create.Flags().BoolVar(&createOptions.AutoRetry, "auto-retry", true, "Should this vdiff automatically retry and continue in case of recoverable errors.")
 	create.Flags().BoolVar(&createOptions.UpdateTableStats, "update-table-stats", false, "Update the table statistics, using ANALYZE TABLE, on each table involved in the vdiff during initialization. This will ensure that progress estimates are as accurate as possible -- but it does involve locks and can potentially impact query processing on the target keyspace.")
-	create.Flags().DurationVar(&createOptions.MaxDiffDuration, "max-diff-duration", 0, "How long should an individual table diff run before being stopped and restarted in order to lessen the impact on tablets due to holding open database snapshots for long periods of time (0 is the default and means no time limit).")
+	create.Flags().DurationVar(&createOptions.MaxDiffDuration, "max-diff-duration", 0, "How long should an individual table diff run before being stopped and restarted in order to lessen the impact on tablets due to holding open database snapshots for long periods of time (0 is the default and means no time limit). It does not apply to tables that are diffed in chunks, with --chunk-rows or --export-path.")
 	create.Flags().Int64Var(&createOptions.RowDiffColumnTruncateAt, "row-diff-column-truncate-at", 128, "When showing row differences, truncate the non Primary Key column values to this length. A value less than 1 means do not truncate.")
 	create.Flags().BoolVar(&createOptions.AutoStart, "auto-start", true, "Start the vdiff upon creation. When false, the vdiff will be created but will not run until resumed.")
+	create.Flags().Int64Var(&createOptions.ChunkRows, "chunk-rows", 0, "Diff each table in chunks of about this many rows, which are diffed from snapshots of their own and are not diffed again when the vdiff is resumed (0 means tables are not split into chunks).")
+	create.Flags().Int64Var(&createOptions.MaxConcurrentChunks, "max-concurrent-chunks", 1, "The maximum number of chunks of a table to diff at the same time on each target shard.")
+	create.Flags().StringVar(&createOptions.ExportPath, "export-path", "", "Export the primary keys of all the rows that differ to this directory of the backup storage of the target tablets, in a file per chunk.")
+	create.Flags().BoolVar(&createOptions.ExportRepairSQL, "export-repair-sql", false, "Also export the SQL statements that make the target rows match the source rows. Requires --export-path.")
 	base.AddCommand(create)
 
 	base.AddCommand(delete)
 
+	repair.Flags().StringSliceVar(&repairOptions.TargetShards, "target-shards", nil, "The target shards to repair the rows of; default is all shards.")
+	base.AddCommand(repair)
+
 	resume.Flags().StringSliceVar(&resumeOptions.TargetShards, "target-shards", nil, "The target shards to resume the vdiff on; default is all shards.")
 	base.AddCommand(resume)
 
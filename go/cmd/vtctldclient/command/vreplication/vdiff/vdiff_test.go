@@ -758,3 +758,19 @@ func TestGetStructNames(t *testing.T) {
 	want := []string{"A", "B"}
 	require.EqualValues(t, want, got)
 }
+
+func TestBuildRepairListings(t *testing.T) {
+	repairFields := sqltypes.MakeTestFields("table_name|repaired_keys|deleted_rows|inserted_rows|differing_keys|unrepaired_rows", "varchar|int64|int64|int64|int64|int64")
+	resp := &vtctldatapb.VDiffRepairResponse{
+		TabletResponses: map[string]*tabletmanagerdatapb.VDiffResponse{
+			"80-": {Output: sqltypes.ResultToProto3(sqltypes.MakeTestResult(repairFields, "t2|1|1|0|0|0", "t1|3|2|3|1|0"))},
+			"-80": {Output: sqltypes.ResultToProto3(sqltypes.MakeTestResult(repairFields, "t1|2|0|2|0|7"))},
+			"40-": {},
+		},
+	}
+	require.Equal(t, []*repairListing{
+		{Shard: "-80", Table: "t1", RepairedKeys: 2, InsertedRows: 2, UnrepairedRows: 7},
+		{Shard: "80-", Table: "t1", RepairedKeys: 3, DeletedRows: 2, InsertedRows: 3, DifferingKeys: 1},
+		{Shard: "80-", Table: "t2", RepairedKeys: 1, DeletedRows: 1},
+	}, buildRepairListings(resp))
+}
@@ -0,0 +1,91 @@
+/*
+Copyright 2025 The Vitess Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package command
+
+import (
+	"fmt"
+
+	"github.com/spf13/cobra"
+
+	"vitess.io/vitess/go/cmd/vtctldclient/cli"
+
+	vtctldatapb "vitess.io/vitess/go/vt/proto/vtctldata"
+)
+
+var (
+	// DeleteVStreamSubscription makes a DeleteVStreamSubscription gRPC call to a vtctld.
+	DeleteVStreamSubscription = &cobra.Command{
+		Use:                   "DeleteVStreamSubscription <name>",
+		Short:                 "Deletes a VStream subscription, whose next stream starts from the position it is given.",
+		DisableFlagsInUseLine: true,
+		Args:                  cobra.ExactArgs(1),
+		RunE:                  commandDeleteVStreamSubscription,
+	}
+	// GetVStreamSubscriptions makes a GetVStreamSubscriptions gRPC call to a vtctld.
+	GetVStreamSubscriptions = &cobra.Command{
+		Use:   "GetVStreamSubscriptions [<name> ...]",
+		Short: "Displays the VStream subscriptions, with their lag and the shards whose binary log retention they fell behind.",
+		Long: `Displays the VStream subscriptions, all of them if no name is given.
+
+The lag of a subscription is the number of seconds since the timestamp of the last event it acknowledged, or since
+its creation if it did not acknowledge any. A subscription that fell behind the binary log retention of a shard
+cannot be resumed, since the primary of the shard purged binary logs it did not acknowledge yet.`,
+		DisableFlagsInUseLine: true,
+		Args:                  cobra.ArbitraryArgs,
+		RunE:                  commandGetVStreamSubscriptions,
+	}
+)
+
+func commandDeleteVStreamSubscription(cmd *cobra.Command, args []string) error {
+	cli.FinishedParsing(cmd)
+
+	_, err := client.DeleteVStreamSubscription(commandCtx, &vtctldatapb.DeleteVStreamSubscriptionRequest{
+		Name: cmd.Flags().Arg(0),
+	})
+	if err != nil {
+		return err
+	}
+
+	fmt.Printf("Successfully deleted VStream subscription %s\n", cmd.Flags().Arg(0))
+
+	return nil
+}
+
+func commandGetVStreamSubscriptions(cmd *cobra.Command, args []string) error {
+	cli.FinishedParsing(cmd)
+
+	resp, err := client.GetVStreamSubscriptions(commandCtx, &vtctldatapb.GetVStreamSubscriptionsRequest{
+		Names: cmd.Flags().Args(),
+	})
+	if err != nil {
+		return err
+	}
+
+	data, err := cli.MarshalJSONPretty(resp)
+	if err != nil {
+		return err
+	}
+
+	fmt.Printf("%s\n", data)
+
+	return nil
+}
+
+func init() {
+	Root.AddCommand(DeleteVStreamSubscription)
+	Root.AddCommand(GetVStreamSubscriptions)
+}
@@ -79,6 +79,10 @@ func (c fallbackClient) VStream(ctx context.Context, tabletType topodatapb.Table
 	return c.fallback.VStream(ctx, tabletType, vgtid, filter, flags, send)
 }
 
+func (c fallbackClient) VStreamAck(ctx context.Context, subscription string, vgtid *binlogdatapb.VGtid, timestamp int64) error {
+	return c.fallback.VStreamAck(ctx, subscription, vgtid, timestamp)
+}
+
 func (c fallbackClient) HandlePanic(err *error) {
 	c.fallback.HandlePanic(err)
 }
@@ -0,0 +1,192 @@
+/*
+Copyright 2025 The Vitess Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package mysql
+
+import (
+	"vitess.io/vitess/go/mysql/collations"
+	"vitess.io/vitess/go/mysql/sqlerror"
+	"vitess.io/vitess/go/sqlescape"
+	"vitess.io/vitess/go/sqltypes"
+	"vitess.io/vitess/go/vt/log"
+	vtrpcpb "vitess.io/vitess/go/vt/proto/vtrpc"
+	"vitess.io/vitess/go/vt/vterrors"
+)
+
+// ChangeUser re-authenticates the connection as the user of the params,
+// with the DbName of the params as its default database. The server
+// resets the state of the session, as with COM_RESET_CONNECTION.
+// Returns a SQLError.
+func (c *Conn) ChangeUser(params *ConnParams) error {
+	// This is a new command, need to reset the sequence.
+	c.resetSequence()
+
+	// The server may also ask for the password with an
+	// AuthSwitchRequest, which handleAuthResponse handles.
+	var scrambledPassword []byte
+	if c.authPluginName == CachingSha2Password {
+		scrambledPassword = ScrambleCachingSha2Password(c.salt, []byte(params.Pass))
+	} else {
+		scrambledPassword = ScrambleMysqlNativePassword(c.salt, []byte(params.Pass))
+	}
+	if err := c.writeComChangeUser(params.Uname, scrambledPassword, params.DbName, uint8(params.Charset)); err != nil {
+		return err
+	}
+	if err := c.handleAuthResponse(params); err != nil {
+		return err
+	}
+	c.schemaName = params.DbName
+	return nil
+}
+
+// writeComChangeUser writes the COM_CHANGE_USER packet, in the format of the
+// capabilities the client sent in its handshake response.
+// Client -> Server.
+// Returns SQLError(CRServerGone) if it can't.
+func (c *Conn) writeComChangeUser(user string, scrambledPassword []byte, dbName string, characterSet uint8) error {
+	length := 1 + // ComChangeUser
+		len(user) + 1 + // user
+		1 + len(scrambledPassword) + // auth-response
+		len(dbName) + 1 + // schema name
+		2 + // character set
+		len(c.authPluginName) + 1 // auth plugin name
+
+	data, pos := c.startEphemeralPacketWithHeader(length)
+	pos = writeByte(data, pos, ComChangeUser)
+	pos = writeNullString(data, pos, user)
+	pos = writeByte(data, pos, uint8(len(scrambledPassword)))
+	pos += copy(data[pos:], scrambledPassword)
+	pos = writeNullString(data, pos, dbName)
+	pos = writeUint16(data, pos, uint16(characterSet))
+	pos = writeNullString(data, pos, string(c.authPluginName))
+
+	// Sanity check.
+	if pos != len(data) {
+		return vterrors.Errorf(vtrpcpb.Code_INTERNAL, "error building ComChangeUser packet: got %v bytes expected %v", pos, len(data))
+	}
+	if err := c.writeEphemeralPacket(); err != nil {
+		return sqlerror.NewSQLError(sqlerror.CRServerGone, sqlerror.SSUnknownSQLState, err.Error())
+	}
+	return nil
+}
+
+// parseComChangeUser parses the COM_CHANGE_USER packet. The auth-response
+// is skipped, see handleComChangeUser.
+func (c *Conn) parseComChangeUser(data []byte) (user string, authMethod AuthMethodDescription, schemaName string, characterSet collations.ID, err error) {
+	pos := 1
+
+	user, pos, ok := readNullString(data, pos)
+	if !ok {
+		return "", "", "", 0, vterrors.Errorf(vtrpcpb.Code_INTERNAL, "parseComChangeUser: can't read username")
+	}
+
+	if c.Capabilities&CapabilityClientSecureConnection != 0 {
+		var l byte
+		l, pos, ok = readByte(data, pos)
+		if ok {
+			pos += int(l)
+			ok = pos <= len(data)
+		}
+	} else {
+		_, pos, ok = readNullString(data, pos)
+	}
+	if !ok {
+		return "", "", "", 0, vterrors.Errorf(vtrpcpb.Code_INTERNAL, "parseComChangeUser: can't read auth-response")
+	}
+
+	schemaName, pos, ok = readNullString(data, pos)
+	if !ok {
+		return "", "", "", 0, vterrors.Errorf(vtrpcpb.Code_INTERNAL, "parseComChangeUser: can't read schema name")
+	}
+
+	// The rest of the packet is optional.
+	authMethod = MysqlNativePassword
+	if pos == len(data) {
+		return user, authMethod, schemaName, c.CharacterSet, nil
+	}
+
+	cs, pos, ok := readUint16(data, pos)
+	if !ok {
+		return "", "", "", 0, vterrors.Errorf(vtrpcpb.Code_INTERNAL, "parseComChangeUser: can't read character set")
+	}
+	characterSet = collations.ID(cs)
+
+	if c.Capabilities&CapabilityClientPluginAuth != 0 {
+		var authMethodStr string
+		authMethodStr, _, ok = readNullString(data, pos)
+		if !ok {
+			return "", "", "", 0, vterrors.Errorf(vtrpcpb.Code_INTERNAL, "parseComChangeUser: can't read authMethod")
+		}
+		if authMethodStr != "" {
+			authMethod = AuthMethodDescription(authMethodStr)
+		}
+	}
+
+	// The connection attributes that may follow are not used.
+	return user, authMethod, schemaName, characterSet, nil
+}
+
+// handleComChangeUser re-authenticates the connection as the user of the
+// COM_CHANGE_USER packet, and resets the state of its session. The
+// connection is closed if the authentication fails.
+func (c *Conn) handleComChangeUser(handler Handler, data []byte) bool {
+	user, authMethod, schemaName, characterSet, err := c.parseComChangeUser(data)
+	c.recycleReadPacket()
+	if err != nil {
+		log.Errorf("Cannot parse COM_CHANGE_USER from %s: %v", c, err)
+		return false
+	}
+
+	// Depending on the client, the auth-response of the packet is
+	// scrambled with the plugin data of the handshake, or with the one
+	// of the AuthSwitchRequest that followed it. So the client is always
+	// asked for its credentials again, with new plugin data.
+	userData, ok := c.listener.authenticate(c, user, authMethod, nil, nil)
+	if !ok {
+		return false
+	}
+	c.closeCursors()
+
+	if c.User != "" {
+		connCountPerUser.Add(c.User, -1)
+	}
+	c.User = user
+	c.UserData = userData
+	if c.User != "" {
+		connCountPerUser.Add(c.User, 1)
+	}
+	c.CharacterSet = characterSet
+	c.schemaName = schemaName
+
+	handler.ComChangeUser(c)
+	c.PrepareData = make(map[uint32]*PrepareData)
+
+	if c.schemaName != "" {
+		err = handler.ComQuery(c, "use "+sqlescape.EscapeID(c.schemaName), func(result *sqltypes.Result) error {
+			return nil
+		})
+		if err != nil {
+			c.writeErrorPacketFromError(err)
+			return false
+		}
+	}
+
+	if err := c.writeOKPacket(&PacketOK{statusFlags: c.StatusFlags}); err != nil {
+		log.Errorf("Cannot write OK packet to %s: %v", c, err)
+		return false
+	}
+	return true
+}
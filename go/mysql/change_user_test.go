@@ -0,0 +1,148 @@
+/*
+Copyright 2025 The Vitess Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package mysql
+
+import (
+	"sync/atomic"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"vitess.io/vitess/go/mysql/collations"
+	"vitess.io/vitess/go/mysql/sqlerror"
+	"vitess.io/vitess/go/test/utils"
+)
+
+type changeUserHandler struct {
+	testHandler
+	changes atomic.Int32
+}
+
+func (th *changeUserHandler) ComChangeUser(c *Conn) {
+	th.changes.Add(1)
+}
+
+func TestChangeUser(t *testing.T) {
+	ctx := utils.LeakCheckContext(t)
+	th := &changeUserHandler{}
+
+	authServer := NewAuthServerStatic("", "", 0)
+	authServer.entries["change_user1"] = []*AuthServerStaticEntry{{
+		Password: "password1",
+		UserData: "userData1",
+	}}
+	authServer.entries["change_user2"] = []*AuthServerStaticEntry{{
+		Password: "password2",
+		UserData: "userData2",
+	}}
+	defer authServer.close()
+
+	l, err := NewListener("tcp", "127.0.0.1:", authServer, th, 0, 0, false, false, 0, 0)
+	require.NoError(t, err)
+	host, port := getHostPort(t, l.Addr())
+	params := &ConnParams{
+		Host:  host,
+		Port:  port,
+		Uname: "change_user1",
+		Pass:  "password1",
+	}
+	go l.Accept()
+	defer cleanupListener(ctx, l, params)
+
+	c, err := Connect(ctx, params)
+	require.NoError(t, err)
+	defer c.Close()
+
+	echo := func(query string) []string {
+		result, err := c.ExecuteFetch(query, 1, false)
+		require.NoError(t, err)
+		require.Len(t, result.Rows, 1)
+		var values []string
+		for _, value := range result.Rows[0] {
+			values = append(values, value.ToString())
+		}
+		return values
+	}
+	assert.Equal(t, []string{"change_user1", "userData1"}, echo("userData echo"))
+
+	err = c.ChangeUser(&ConnParams{
+		Uname:  "change_user2",
+		Pass:   "password2",
+		DbName: "db2",
+	})
+	require.NoError(t, err)
+	assert.Equal(t, []string{"change_user2", "userData2"}, echo("userData echo"))
+	assert.Equal(t, []string{"db2"}, echo("schema echo"))
+	assert.EqualValues(t, 1, th.changes.Load())
+	assert.Equal(t, "change_user2", c.User)
+	counts := connCountPerUser.Counts()
+	assert.EqualValues(t, 0, counts["change_user1"])
+	assert.EqualValues(t, 1, counts["change_user2"])
+
+	// The connection is closed if the authentication fails.
+	err = c.ChangeUser(&ConnParams{
+		Uname: "change_user1",
+		Pass:  "bad password",
+	})
+	assert.ErrorContains(t, err, "Access denied for user 'change_user1'")
+	assert.EqualValues(t, 1, th.changes.Load())
+	_, err = c.ExecuteFetch("select rows", 1, false)
+	require.Error(t, err)
+	assert.Equal(t, sqlerror.CRServerLost, sqlerror.NewSQLErrorFromError(err).(*sqlerror.SQLError).Number())
+}
+
+func TestParseComChangeUser(t *testing.T) {
+	c := &Conn{
+		Capabilities: CapabilityClientSecureConnection | CapabilityClientPluginAuth,
+		CharacterSet: collations.CollationUtf8mb4ID,
+	}
+
+	// A packet with all the fields, and connection attributes.
+	data := []byte{ComChangeUser}
+	data = append(data, "user1\x00"...)
+	data = append(data, 3, 'a', 'b', 'c')
+	data = append(data, "db1\x00"...)
+	data = append(data, 0x21, 0x00)
+	data = append(data, "caching_sha2_password\x00"...)
+	data = append(data, 0x00)
+	user, authMethod, schemaName, characterSet, err := c.parseComChangeUser(data)
+	require.NoError(t, err)
+	assert.Equal(t, "user1", user)
+	assert.Equal(t, CachingSha2Password, authMethod)
+	assert.Equal(t, "db1", schemaName)
+	assert.Equal(t, collations.ID(0x21), characterSet)
+
+	// A packet without the optional fields keeps the character set.
+	data = []byte{ComChangeUser}
+	data = append(data, "user2\x00"...)
+	data = append(data, 0)
+	data = append(data, "\x00"...)
+	user, authMethod, schemaName, characterSet, err = c.parseComChangeUser(data)
+	require.NoError(t, err)
+	assert.Equal(t, "user2", user)
+	assert.Equal(t, MysqlNativePassword, authMethod)
+	assert.Equal(t, "", schemaName)
+	assert.Equal(t, collations.ID(collations.CollationUtf8mb4ID), characterSet)
+
+	// A truncated auth-response.
+	data = []byte{ComChangeUser}
+	data = append(data, "user3\x00"...)
+	data = append(data, 20, 'a')
+	_, _, _, _, err = c.parseComChangeUser(data)
+	assert.EqualError(t, err, "parseComChangeUser: can't read auth-response")
+}
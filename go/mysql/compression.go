@@ -0,0 +1,375 @@
+/*
+Copyright 2025 The Vitess Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package mysql
+
+import (
+	"bytes"
+	"compress/zlib"
+	"io"
+	"sync"
+
+	"github.com/klauspost/compress/zstd"
+
+	"vitess.io/vitess/go/vt/vterrors"
+
+	vtrpcpb "vitess.io/vitess/go/vt/proto/vtrpc"
+)
+
+// This file implements the compressed client/server protocol, see
+// https://dev.mysql.com/doc/dev/mysql-server/latest/page_protocol_basic_compression.html
+//
+// Once it is negotiated in the handshake, all the packets that follow
+// the OK packet of the authentication are sent within compressed
+// packets, which have their own header and sequence:
+//
+//	int<3> length of the compressed payload
+//	int<1> compressed sequence ID
+//	int<3> length of the payload before compression, 0 if not compressed
+//	string<var> compressed payload
+//
+// A compressed packet can contain many packets, and a packet can span
+// many compressed packets.
+
+// Compression algorithms of the client/server protocol.
+const (
+	// CompressionZlib is negotiated with CapabilityClientCompress.
+	CompressionZlib = "zlib"
+
+	// CompressionZstd is negotiated with CapabilityClientZstdCompressionAlgorithm.
+	CompressionZstd = "zstd"
+)
+
+const (
+	// compressedPacketHeaderSize is the size of the header of a
+	// compressed packet.
+	compressedPacketHeaderSize = 7
+
+	// minCompressLength is the size under which the payloads are sent
+	// uncompressed, as they would not get any smaller. It is the same
+	// as MIN_COMPRESS_LENGTH in MySQL.
+	minCompressLength = 50
+
+	// defaultZstdCompressionLevel is the zstd level used when none is
+	// set. It is the default of zstd, and of the MySQL client.
+	defaultZstdCompressionLevel = 3
+)
+
+var (
+	blankCompressedPacketHeader [compressedPacketHeaderSize]byte
+
+	// zlibWriterPools pool the zlib writers, which are expensive to
+	// allocate, per compression level. They are indexed by level+1 as
+	// the levels go from zlib.DefaultCompression to zlib.BestCompression.
+	zlibWriterPools [zlib.BestCompression + 2]sync.Pool
+
+	zlibReaderPool sync.Pool
+
+	// zstdEncoders are the zstd encoders per level. The encoders are
+	// safe for concurrent use with EncodeAll.
+	zstdEncodersMu sync.Mutex
+	zstdEncoders   = map[zstd.EncoderLevel]*zstd.Encoder{}
+
+	// zstdDecoder is safe for concurrent use with DecodeAll. It never
+	// decodes more than a packet, whatever the frames claim.
+	zstdDecoder = sync.OnceValues(func() (*zstd.Decoder, error) {
+		return zstd.NewReader(nil, zstd.WithDecoderConcurrency(0), zstd.WithDecoderMaxMemory(MaxPacketSize))
+	})
+)
+
+// ValidateCompression returns an error if the compression algorithm is
+// not supported, or if the level is not valid for it. A level of 0 is
+// the default of the algorithm.
+func ValidateCompression(algorithm string, level int) error {
+	switch algorithm {
+	case CompressionZlib:
+		if level < 0 || level > zlib.BestCompression {
+			return vterrors.Errorf(vtrpcpb.Code_INVALID_ARGUMENT, "invalid zlib compression level %d, must be between 1 and %d", level, zlib.BestCompression)
+		}
+	case CompressionZstd:
+		if level < 0 || level > 22 {
+			return vterrors.Errorf(vtrpcpb.Code_INVALID_ARGUMENT, "invalid zstd compression level %d, must be between 1 and 22", level)
+		}
+	default:
+		return vterrors.Errorf(vtrpcpb.Code_INVALID_ARGUMENT, "unsupported compression algorithm %q, must be one of %s or %s", algorithm, CompressionZlib, CompressionZstd)
+	}
+	return nil
+}
+
+// compressionCapability returns the capability flag that negotiates the
+// compression algorithm.
+func compressionCapability(algorithm string) uint32 {
+	switch algorithm {
+	case CompressionZlib:
+		return CapabilityClientCompress
+	case CompressionZstd:
+		return CapabilityClientZstdCompressionAlgorithm
+	}
+	return 0
+}
+
+// compressor reads and writes the packets of a connection within
+// compressed packets.
+type compressor struct {
+	c         *Conn
+	algorithm string
+	level     int
+
+	// r is the reader of the compressed packets, and readBuffer the
+	// payload of the last one, of which the bytes from readPos on
+	// have not been read yet.
+	r          io.Reader
+	readBuffer *[]byte
+	readPos    int
+
+	// w is the writer of the compressed packets, and writeBuffer is
+	// used to build them.
+	w           io.Writer
+	writeBuffer []byte
+}
+
+// enableCompression starts reading and writing the packets within
+// compressed packets. It is called once the handshake is complete, on
+// both sides.
+func (c *Conn) enableCompression(algorithm string, level int) {
+	if level == 0 {
+		switch algorithm {
+		case CompressionZlib:
+			level = zlib.DefaultCompression
+		case CompressionZstd:
+			level = defaultZstdCompressionLevel
+		}
+	}
+	c.compressor = &compressor{
+		c:         c,
+		algorithm: algorithm,
+		level:     level,
+		r:         c.getReader(),
+		w:         c.conn,
+	}
+}
+
+// CompressionAlgorithm returns the compression algorithm that is in use
+// for this connection, or an empty string if it is not compressed.
+func (c *Conn) CompressionAlgorithm() string {
+	if c.compressor == nil {
+		return ""
+	}
+	return c.compressor.algorithm
+}
+
+// Read implements io.Reader. It reads the packets from the payloads of
+// the compressed packets.
+func (cp *compressor) Read(p []byte) (int, error) {
+	for cp.readBuffer == nil || cp.readPos == len(*cp.readBuffer) {
+		if err := cp.readCompressedPacket(); err != nil {
+			return 0, err
+		}
+	}
+	n := copy(p, (*cp.readBuffer)[cp.readPos:])
+	cp.readPos += n
+	return n, nil
+}
+
+// readCompressedPacket reads the next compressed packet, and
+// decompresses its payload into readBuffer.
+func (cp *compressor) readCompressedPacket() error {
+	if cp.readBuffer != nil {
+		bufPool.Put(cp.readBuffer)
+		cp.readBuffer = nil
+	}
+
+	var header [compressedPacketHeaderSize]byte
+	if _, err := io.ReadFull(cp.r, header[:]); err != nil {
+		// The errors are returned as is, see readHeaderFrom.
+		return err
+	}
+	length := int(uint32(header[0]) | uint32(header[1])<<8 | uint32(header[2])<<16)
+	sequence := header[3]
+	uncompressedLength := int(uint32(header[4]) | uint32(header[5])<<8 | uint32(header[6])<<16)
+	if sequence != cp.c.compressedSequence {
+		return vterrors.Errorf(vtrpcpb.Code_INTERNAL, "invalid compressed sequence, expected %v got %v", cp.c.compressedSequence, sequence)
+	}
+	cp.c.compressedSequence++
+
+	payload := bufPool.Get(length)
+	if _, err := io.ReadFull(cp.r, *payload); err != nil {
+		bufPool.Put(payload)
+		return vterrors.Wrapf(err, "io.ReadFull(compressed packet body of length %v) failed", length)
+	}
+	if uncompressedLength == 0 {
+		// The payload was not compressed.
+		cp.readBuffer = payload
+		cp.readPos = 0
+		return nil
+	}
+	defer bufPool.Put(payload)
+
+	cp.readBuffer = bufPool.Get(uncompressedLength)
+	cp.readPos = 0
+	switch cp.algorithm {
+	case CompressionZlib:
+		zr, err := getZlibReader(bytes.NewReader(*payload))
+		if err != nil {
+			return vterrors.Wrapf(err, "cannot decompress packet")
+		}
+		defer zlibReaderPool.Put(zr)
+		if _, err := io.ReadFull(zr, *cp.readBuffer); err != nil {
+			return vterrors.Wrapf(err, "cannot decompress packet")
+		}
+		// The payload must not decompress to more than the header says.
+		var extra [1]byte
+		if n, err := zr.Read(extra[:]); n > 0 || err != io.EOF {
+			return vterrors.Errorf(vtrpcpb.Code_INTERNAL, "decompressed packet is longer than %v", uncompressedLength)
+		}
+	case CompressionZstd:
+		decoder, err := zstdDecoder()
+		if err != nil {
+			return err
+		}
+		decompressed, err := decoder.DecodeAll(*payload, (*cp.readBuffer)[:0])
+		if err != nil {
+			return vterrors.Wrapf(err, "cannot decompress packet")
+		}
+		if len(decompressed) != uncompressedLength {
+			return vterrors.Errorf(vtrpcpb.Code_INTERNAL, "decompressed packet has a length of %v instead of %v", len(decompressed), uncompressedLength)
+		}
+		// DecodeAll allocates a new buffer if a frame claims to be larger
+		// than the packet.
+		if &decompressed[0] != &(*cp.readBuffer)[0] {
+			copy(*cp.readBuffer, decompressed)
+		}
+	}
+	return nil
+}
+
+// Write implements io.Writer. It writes the packets in compressed
+// packets, as many as needed to not exceed their maximum size.
+func (cp *compressor) Write(p []byte) (int, error) {
+	written := 0
+	for written < len(p) {
+		payload := p[written:]
+		if len(payload) > MaxPacketSize {
+			payload = payload[:MaxPacketSize]
+		}
+		if err := cp.writeCompressedPacket(payload); err != nil {
+			return written, err
+		}
+		written += len(payload)
+	}
+	return written, nil
+}
+
+// writeCompressedPacket writes a single compressed packet. The payload
+// is only compressed if it is large enough, and if it gets smaller.
+func (cp *compressor) writeCompressedPacket(payload []byte) error {
+	data := cp.writeBuffer[:0]
+	uncompressedLength := 0
+	if len(payload) >= minCompressLength {
+		var err error
+		data, err = cp.compress(data, payload)
+		if err != nil {
+			return err
+		}
+		uncompressedLength = len(payload)
+	}
+	if uncompressedLength == 0 || len(data)-compressedPacketHeaderSize >= len(payload) {
+		data = append(data[:0], blankCompressedPacketHeader[:]...)
+		data = append(data, payload...)
+		uncompressedLength = 0
+	}
+	length := len(data) - compressedPacketHeaderSize
+
+	data[0] = byte(length)
+	data[1] = byte(length >> 8)
+	data[2] = byte(length >> 16)
+	data[3] = cp.c.compressedSequence
+	data[4] = byte(uncompressedLength)
+	data[5] = byte(uncompressedLength >> 8)
+	data[6] = byte(uncompressedLength >> 16)
+
+	// Keep the buffer for the next packets, unless it grew larger
+	// than the usual writes.
+	if cap(data) <= 4*connBufferSize {
+		cp.writeBuffer = data[:0]
+	} else {
+		cp.writeBuffer = nil
+	}
+
+	if n, err := cp.w.Write(data); err != nil {
+		return vterrors.Wrapf(err, "Write(compressed packet) failed")
+	} else if n != len(data) {
+		return vterrors.Errorf(vtrpcpb.Code_INTERNAL, "Write(compressed packet) returned a short write: %v < %v", n, len(data))
+	}
+	cp.c.compressedSequence++
+	return nil
+}
+
+// compress appends a blank compressed packet header and the compressed
+// payload to data.
+func (cp *compressor) compress(data, payload []byte) ([]byte, error) {
+	data = append(data, blankCompressedPacketHeader[:]...)
+	switch cp.algorithm {
+	case CompressionZlib:
+		buf := bytes.NewBuffer(data)
+		zw := getZlibWriter(buf, cp.level)
+		defer zlibWriterPools[cp.level+1].Put(zw)
+		if _, err := zw.Write(payload); err != nil {
+			return nil, vterrors.Wrapf(err, "cannot compress packet")
+		}
+		if err := zw.Close(); err != nil {
+			return nil, vterrors.Wrapf(err, "cannot compress packet")
+		}
+		return buf.Bytes(), nil
+	case CompressionZstd:
+		return getZstdEncoder(cp.level).EncodeAll(payload, data), nil
+	}
+	return nil, vterrors.Errorf(vtrpcpb.Code_INTERNAL, "unsupported compression algorithm %q", cp.algorithm)
+}
+
+func getZlibWriter(w io.Writer, level int) *zlib.Writer {
+	if zw, ok := zlibWriterPools[level+1].Get().(*zlib.Writer); ok {
+		zw.Reset(w)
+		return zw
+	}
+	// The level was validated, so this cannot fail.
+	zw, _ := zlib.NewWriterLevel(w, level)
+	return zw
+}
+
+func getZlibReader(r io.Reader) (io.ReadCloser, error) {
+	if zr, ok := zlibReaderPool.Get().(io.ReadCloser); ok {
+		if err := zr.(zlib.Resetter).Reset(r, nil); err != nil {
+			return nil, err
+		}
+		return zr, nil
+	}
+	return zlib.NewReader(r)
+}
+
+func getZstdEncoder(level int) *zstd.Encoder {
+	encoderLevel := zstd.EncoderLevelFromZstd(level)
+
+	zstdEncodersMu.Lock()
+	defer zstdEncodersMu.Unlock()
+	encoder, ok := zstdEncoders[encoderLevel]
+	if !ok {
+		// The options are valid, so this cannot fail.
+		encoder, _ = zstd.NewWriter(nil, zstd.WithEncoderLevel(encoderLevel))
+		zstdEncoders[encoderLevel] = encoder
+	}
+	return encoder
+}
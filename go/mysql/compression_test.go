@@ -0,0 +1,181 @@
+/*
+Copyright 2025 The Vitess Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package mysql
+
+import (
+	"bytes"
+	"crypto/rand"
+	"encoding/hex"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"vitess.io/vitess/go/test/utils"
+)
+
+func TestCompression(t *testing.T) {
+	ctx := utils.LeakCheckContext(t)
+	th := &testHandler{}
+
+	// A value that does not compress, and one that is larger than a
+	// packet, which is split in many compressed packets.
+	random := make([]byte, 1000)
+	_, err := rand.Read(random)
+	require.NoError(t, err)
+	queries := []string{
+		"select rows",
+		benchmarkQueryPrefix + hex.EncodeToString(random),
+		benchmarkQueryPrefix + strings.Repeat("x", MaxPacketSize+100),
+	}
+
+	testcases := []struct {
+		name                  string
+		serverAlgorithms      []string
+		compression           string
+		compressionLevel      int
+		wantAlgorithm         string
+		wantZstdCompressLevel int
+	}{{
+		name:             "zlib",
+		serverAlgorithms: []string{CompressionZlib, CompressionZstd},
+		compression:      CompressionZlib,
+		wantAlgorithm:    CompressionZlib,
+	}, {
+		name:             "zlib with level",
+		serverAlgorithms: []string{CompressionZlib},
+		compression:      CompressionZlib,
+		compressionLevel: 9,
+		wantAlgorithm:    CompressionZlib,
+	}, {
+		name:                  "zstd",
+		serverAlgorithms:      []string{CompressionZlib, CompressionZstd},
+		compression:           CompressionZstd,
+		wantAlgorithm:         CompressionZstd,
+		wantZstdCompressLevel: defaultZstdCompressionLevel,
+	}, {
+		name:                  "zstd with level",
+		serverAlgorithms:      []string{CompressionZstd},
+		compression:           CompressionZstd,
+		compressionLevel:      19,
+		wantAlgorithm:         CompressionZstd,
+		wantZstdCompressLevel: 19,
+	}, {
+		name:             "not supported by the server",
+		serverAlgorithms: []string{CompressionZlib},
+		compression:      CompressionZstd,
+	}, {
+		name:             "not configured on the server",
+		serverAlgorithms: nil,
+		compression:      CompressionZlib,
+	}, {
+		name:             "not asked by the client",
+		serverAlgorithms: []string{CompressionZlib, CompressionZstd},
+	}}
+	for _, tcase := range testcases {
+		t.Run(tcase.name, func(t *testing.T) {
+			// The listener reads its compression algorithms while it
+			// accepts connections, so each test case has its own.
+			l, err := NewListener("tcp", "127.0.0.1:", NewAuthServerNone(), th, 0, 0, false, false, 0, 0)
+			require.NoError(t, err)
+			l.CompressionAlgorithms = tcase.serverAlgorithms
+			host, port := getHostPort(t, l.Addr())
+			params := &ConnParams{
+				Host:             host,
+				Port:             port,
+				Compression:      tcase.compression,
+				CompressionLevel: tcase.compressionLevel,
+			}
+			go l.Accept()
+			defer cleanupListener(ctx, l, params)
+
+			c, err := Connect(ctx, params)
+			require.NoError(t, err)
+			defer c.Close()
+			assert.Equal(t, tcase.wantAlgorithm, c.CompressionAlgorithm())
+
+			// The sequences are reset for each query.
+			for _, query := range queries {
+				result, err := c.ExecuteFetch(query, 10, true)
+				require.NoError(t, err)
+				if query == "select rows" {
+					assert.Equal(t, selectRowsResult.Rows, result.Rows)
+					continue
+				}
+				require.Len(t, result.Rows, 1)
+				assert.Equal(t, query, result.Rows[0][0].ToString())
+			}
+			require.NoError(t, c.Ping())
+
+			// The server enables the compression once it sent the OK
+			// packet of the handshake, so its connection is checked once
+			// its handler ran the queries.
+			serverConn := th.LastConn()
+			assert.Equal(t, tcase.wantAlgorithm, serverConn.CompressionAlgorithm())
+			if tcase.wantAlgorithm == CompressionZstd {
+				assert.Equal(t, tcase.wantZstdCompressLevel, serverConn.zstdCompressionLevel)
+			}
+		})
+	}
+
+	_, err = Connect(ctx, &ConnParams{Host: "127.0.0.1", Compression: "lz4"})
+	assert.EqualError(t, err, `unsupported compression algorithm "lz4", must be one of zlib or zstd`)
+}
+
+func TestValidateCompression(t *testing.T) {
+	assert.NoError(t, ValidateCompression(CompressionZlib, 0))
+	assert.NoError(t, ValidateCompression(CompressionZlib, 9))
+	assert.NoError(t, ValidateCompression(CompressionZstd, 22))
+	assert.EqualError(t, ValidateCompression(CompressionZlib, 10), "invalid zlib compression level 10, must be between 1 and 9")
+	assert.EqualError(t, ValidateCompression(CompressionZstd, -1), "invalid zstd compression level -1, must be between 1 and 22")
+	assert.EqualError(t, ValidateCompression("lz4", 0), `unsupported compression algorithm "lz4", must be one of zlib or zstd`)
+}
+
+func TestReadCompressedPacketLength(t *testing.T) {
+	payload := []byte(strings.Repeat("compressible ", 100))
+	for _, algorithm := range []string{CompressionZlib, CompressionZstd} {
+		t.Run(algorithm, func(t *testing.T) {
+			packet, err := (&compressor{algorithm: algorithm, level: 1}).compress(nil, payload)
+			require.NoError(t, err)
+			packet[0] = byte(len(packet) - compressedPacketHeaderSize)
+			packet[1] = byte((len(packet) - compressedPacketHeaderSize) >> 8)
+
+			read := func(uncompressedLength int) ([]byte, error) {
+				packet[4] = byte(uncompressedLength)
+				packet[5] = byte(uncompressedLength >> 8)
+				packet[6] = byte(uncompressedLength >> 16)
+				cp := &compressor{c: &Conn{}, algorithm: algorithm, r: bytes.NewReader(packet)}
+				if err := cp.readCompressedPacket(); err != nil {
+					return nil, err
+				}
+				return *cp.readBuffer, nil
+			}
+
+			data, err := read(len(payload))
+			require.NoError(t, err)
+			assert.Equal(t, payload, data)
+
+			// A payload that decompresses to more or less than its header
+			// says is rejected.
+			_, err = read(len(payload) - 1)
+			assert.Error(t, err)
+			_, err = read(len(payload) + 1)
+			assert.Error(t, err)
+		})
+	}
+}
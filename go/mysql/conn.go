@@ -121,6 +121,12 @@ type Conn struct {
 	// avoid maps indexed by ConnectionID for instance.
 	ClientData any
 
+	// QueryAttributes are the query attributes the client sent with
+	// the current COM_QUERY or COM_STMT_EXECUTE, by name. It is only
+	// set on the server side, if CapabilityClientQueryAttributes was
+	// negotiated.
+	QueryAttributes map[string]*querypb.BindVariable
+
 	// conn is the underlying network connection.
 	// Calling Close() on the Conn will close this connection.
 	// If there are any ongoing reads or writes, they may get interrupted.
@@ -209,6 +215,20 @@ type Conn struct {
 	// Packet encoding variables.
 	sequence uint8
 
+	// compressedSequence is the sequence of the compressed packets,
+	// when the compressed protocol is in use. It is shared by the
+	// reads and the writes, like sequence.
+	compressedSequence uint8
+
+	// compressor is set once the compressed protocol was negotiated
+	// in the handshake. The packets are then read and written
+	// through it.
+	compressor *compressor
+
+	// zstdCompressionLevel is the zstd level requested by the client
+	// in the handshake. It is only used by the server.
+	zstdCompressionLevel int
+
 	// ExpectSemiSyncIndicator is applicable when the connection is used for replication (ComBinlogDump).
 	// When 'true', events are assumed to be padded with 2-byte semi-sync information
 	// See https://dev.mysql.com/doc/internals/en/semi-sync-binlog-event.html
@@ -232,6 +252,14 @@ type Conn struct {
 	closing bool
 
 	truncateErrLen int
+
+	// maxOpenCursors is the number of cursors the client can open on
+	// the statements of a server-side connection, see cursor.go.
+	maxOpenCursors int
+	// cursorPool limits the cursors of all the connections, and
+	// cursorIdleTimeout stops the cursors whose rows are not fetched.
+	cursorPool        *CursorPool
+	cursorIdleTimeout time.Duration
 }
 
 // PrepareData is a buffer used for store prepare statement meta data
@@ -242,6 +270,28 @@ type PrepareData struct {
 	BindVars    map[string]*querypb.BindVariable
 	StatementID uint32
 	ParamsCount uint16
+
+	// CursorType is the type of the cursor the statement is executed
+	// with. With CursorTypeReadOnly, the handler runs in the background
+	// as the client fetches the rows, and the connection can process
+	// other commands meanwhile.
+	CursorType byte
+
+	// cursor is the cursor opened by the last execution of the
+	// statement, until all of its rows are fetched.
+	cursor *cursor
+	// ctx is the context of the execution of the statement by a cursor.
+	ctx context.Context
+}
+
+// Context returns the context the handler must execute the statement with.
+// The context of a statement executed by a cursor is canceled when the
+// cursor is closed.
+func (p *PrepareData) Context() context.Context {
+	if p.ctx == nil {
+		return context.Background()
+	}
+	return p.ctx
 }
 
 // execResult is an enum signifying the result of executing a query
@@ -298,6 +348,10 @@ func newServerConn(conn net.Conn, listener *Listener) *Conn {
 		keepAliveOn:    enabledKeepAlive,
 		flushDelay:     listener.flushDelay,
 		truncateErrLen: listener.truncateErrLen,
+		maxOpenCursors: listener.MaxOpenCursors,
+
+		cursorPool:        listener.CursorPool,
+		cursorIdleTimeout: listener.CursorIdleTimeout,
 	}
 
 	if listener.connReadBufferSize > 0 {
@@ -338,7 +392,7 @@ func (c *Conn) startWriterBuffering() {
 	defer c.bufMu.Unlock()
 
 	c.bufferedWriter = writersPool.Get().(*bufio.Writer)
-	c.bufferedWriter.Reset(c.conn)
+	c.bufferedWriter.Reset(c.getWriter())
 }
 
 // endWriterBuffering must be called to terminate startWriteBuffering.
@@ -386,14 +440,34 @@ func (c *Conn) startFlushTimer() {
 }
 
 // getReader returns reader for connection. It can be *bufio.Reader or net.Conn
-// depending on which buffer size was passed to newServerConn.
+// depending on which buffer size was passed to newServerConn, or the
+// compressor if the compressed protocol is in use.
 func (c *Conn) getReader() io.Reader {
+	if c.compressor != nil {
+		return c.compressor
+	}
 	if c.bufferedReader != nil {
 		return c.bufferedReader
 	}
 	return c.conn
 }
 
+// getWriter returns the unbuffered writer for connection. It is the
+// compressor if the compressed protocol is in use, or net.Conn.
+func (c *Conn) getWriter() io.Writer {
+	if c.compressor != nil {
+		return c.compressor
+	}
+	return c.conn
+}
+
+// resetSequence resets the sequence of the packets, and of the
+// compressed packets, as each new command starts from 0.
+func (c *Conn) resetSequence() {
+	c.sequence = 0
+	c.compressedSequence = 0
+}
+
 func (c *Conn) readHeaderFrom(r io.Reader) (int, error) {
 	// Note io.ReadFull will return two different types of errors:
 	// 1. if the socket is already closed, and the go runtime knows it,
@@ -414,12 +488,18 @@ func (c *Conn) readHeaderFrom(r io.Reader) (int, error) {
 		return 0, vterrors.Wrapf(err, "io.ReadFull(header size) failed")
 	}
 
-	sequence := c.header[3]
-	if sequence != c.sequence {
-		return 0, vterrors.Errorf(vtrpcpb.Code_INTERNAL, "invalid sequence, expected %v got %v", c.sequence, sequence)
-	}
+	if c.compressor != nil {
+		// The compressed packets carry the sequence that is checked.
+		// Like MySQL, the packets we write next follow it.
+		c.sequence = c.compressedSequence
+	} else {
+		sequence := c.header[3]
+		if sequence != c.sequence {
+			return 0, vterrors.Errorf(vtrpcpb.Code_INTERNAL, "invalid sequence, expected %v got %v", c.sequence, sequence)
+		}
 
-	c.sequence++
+		c.sequence++
+	}
 
 	return int(uint32(c.header[0]) | uint32(c.header[1])<<8 | uint32(c.header[2])<<16), nil
 }
@@ -626,7 +706,7 @@ func (c *Conn) writePacket(data []byte) error {
 		}()
 	} else {
 		c.bufMu.Unlock()
-		w = c.conn
+		w = c.getWriter()
 	}
 
 	var header [packetHeaderSize]byte
@@ -730,7 +810,7 @@ func (c *Conn) recycleWritePacket() {
 // Returns SQLError(CRServerGone) if it can't.
 func (c *Conn) writeComQuit() error {
 	// This is a new command, need to reset the sequence.
-	c.sequence = 0
+	c.resetSequence()
 
 	data, pos := c.startEphemeralPacketWithHeader(1)
 	data[pos] = ComQuit
@@ -903,7 +983,7 @@ func (c *Conn) writeEOFPacket(flags uint16, warnings uint16) error {
 // handleNextCommand is called in the server loop to process
 // incoming packets.
 func (c *Conn) handleNextCommand(handler Handler) bool {
-	c.sequence = 0
+	c.resetSequence()
 	data, err := c.readEphemeralPacket()
 	if err != nil {
 		// Don't log EOF errors. They cause too much spam.
@@ -948,13 +1028,18 @@ func (c *Conn) handleNextCommand(handler Handler) bool {
 		stmtID, ok := c.parseComStmtClose(data)
 		c.recycleReadPacket()
 		if ok {
+			c.closeCursor(c.PrepareData[stmtID])
 			delete(c.PrepareData, stmtID)
 		}
+	case ComStmtFetch:
+		return c.handleComStmtFetch(data)
 	case ComStmtReset:
 		return c.handleComStmtReset(data)
 	case ComResetConnection:
 		c.handleComResetConnection(handler)
 		return true
+	case ComChangeUser:
+		return c.handleComChangeUser(handler, data)
 	case ComFieldList:
 		c.recycleReadPacket()
 		if !c.writeErrorAndLog(sqlerror.ERUnknownComError, sqlerror.SSNetError, "command handling not implemented yet: %v", data[0]) {
@@ -1046,6 +1131,7 @@ func (c *Conn) handleComBinlogDumpGTID(handler Handler, data []byte) (kontinue b
 func (c *Conn) handleComResetConnection(handler Handler) {
 	// Clean up and reset the connection
 	c.recycleReadPacket()
+	c.closeCursors()
 	handler.ComResetConnection(c)
 	// Reset prepared statements
 	c.PrepareData = make(map[uint32]*PrepareData)
@@ -1073,6 +1159,7 @@ func (c *Conn) handleComStmtReset(data []byte) bool {
 		}
 	}
 
+	c.closeCursor(prepare)
 	if prepare.BindVars != nil {
 		for k := range prepare.BindVars {
 			prepare.BindVars[k] = nil
@@ -1125,7 +1212,12 @@ func (c *Conn) handleComStmtExecute(handler Handler, data []byte) (kontinue bool
 		}
 	}()
 	queryStart := time.Now()
-	stmtID, _, err := c.parseComStmtExecute(c.PrepareData, data)
+	// Executing the statement again closes its cursor, before its bind
+	// variables are parsed.
+	if stmtID, _, ok := readUint32(data, 1); ok {
+		c.closeCursor(c.PrepareData[stmtID])
+	}
+	stmtID, cursorType, err := c.parseComStmtExecute(c.PrepareData, data)
 	c.recycleReadPacket()
 
 	if stmtID != uint32(0) {
@@ -1140,10 +1232,18 @@ func (c *Conn) handleComStmtExecute(handler Handler, data []byte) (kontinue bool
 		return c.writeErrorPacketFromErrorAndLog(err)
 	}
 
+	prepare := c.PrepareData[stmtID]
+	prepare.CursorType = CursorTypeNoCursor
+	if cursorType&CursorTypeReadOnly != 0 && c.maxOpenCursors > 0 {
+		prepare.CursorType = CursorTypeReadOnly
+		kontinue = c.executeWithCursor(handler, prepare)
+		timings.Record(queryTimingKey, queryStart)
+		return kontinue
+	}
+
 	receivedResult := false
 	// sendFinished is set if the response should just be an OK packet.
 	sendFinished := false
-	prepare := c.PrepareData[stmtID]
 	err = handler.ComStmtExecute(c, prepare, func(qr *sqltypes.Result) error {
 		if sendFinished {
 			// Failsafe: Unreachable if server is well-behaved.
@@ -1311,8 +1411,11 @@ func (c *Conn) handleComQueryMulti(handler Handler, data []byte) (kontinue bool)
 	}()
 
 	queryStart := time.Now()
-	query := c.parseComQuery(data)
+	query, err := c.parseComQuery(data)
 	c.recycleReadPacket()
+	if err != nil {
+		return c.writeErrorPacketFromErrorAndLog(err)
+	}
 
 	res := c.execQueryMulti(query, handler)
 	if res != execSuccess {
@@ -1445,11 +1548,13 @@ func (c *Conn) handleComQuery(handler Handler, data []byte) (kontinue bool) {
 	}()
 
 	queryStart := time.Now()
-	query := c.parseComQuery(data)
+	query, err := c.parseComQuery(data)
 	c.recycleReadPacket()
+	if err != nil {
+		return c.writeErrorPacketFromErrorAndLog(err)
+	}
 
 	var queries []string
-	var err error
 	if c.Capabilities&CapabilityClientMultiStatements != 0 {
 		queries, err = handler.Env().Parser().SplitStatementToPieces(query)
 		if err != nil {
@@ -64,6 +64,16 @@ type ConnParams struct {
 	// FlushDelay is the delay after which buffered response will be flushed to the client.
 	FlushDelay time.Duration
 
+	// Compression is the compression algorithm of the protocol to
+	// negotiate with the server, CompressionZlib or CompressionZstd.
+	// The packets are not compressed if it is empty, or if the server
+	// does not support it.
+	Compression string
+
+	// CompressionLevel is the level of the compression, 0 for the
+	// default of the algorithm.
+	CompressionLevel int
+
 	TruncateErrLen int
 }
 
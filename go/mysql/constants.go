@@ -76,8 +76,11 @@ const (
 	// CLIENT_NO_SCHEMA 1 << 4
 	// Do not permit database.table.column. We do permit it.
 
-	// CLIENT_COMPRESS 1 << 5
-	// We do not support compression. CPU is usually our bottleneck.
+	// CapabilityClientCompress is CLIENT_COMPRESS.
+	// Use the compressed protocol with zlib after the handshake.
+	// It is only negotiated if the compression is configured, as
+	// CPU is usually our bottleneck.
+	CapabilityClientCompress = 1 << 5
 
 	// CLIENT_ODBC 1 << 6
 	// No special behavior since 3.22.
@@ -148,6 +151,19 @@ const (
 	// CapabilityClientDeprecateEOF is CLIENT_DEPRECATE_EOF
 	// Expects an OK (instead of EOF) after the resultset rows of a Text Resultset.
 	CapabilityClientDeprecateEOF = 1 << 24
+
+	// CLIENT_OPTIONAL_RESULTSET_METADATA 1 << 25
+	// Not yet supported.
+
+	// CapabilityClientZstdCompressionAlgorithm is CLIENT_ZSTD_COMPRESSION_ALGORITHM.
+	// Use the compressed protocol with zstd after the handshake, at the
+	// level sent by the client in Protocol::HandshakeResponse41.
+	CapabilityClientZstdCompressionAlgorithm = 1 << 26
+
+	// CapabilityClientQueryAttributes is CLIENT_QUERY_ATTRIBUTES.
+	// Clients can send query attributes, named values that are not
+	// part of the query, with COM_QUERY and COM_STMT_EXECUTE.
+	CapabilityClientQueryAttributes = 1 << 27
 )
 
 // Status flags. They are returned by the server in a few cases.
@@ -179,6 +195,19 @@ const (
 	ServerSessionStateChanged uint16 = 0x4000
 )
 
+// Cursor types of COM_STMT_EXECUTE.
+// Originally found in include/mysql/mysql_com.h
+const (
+	// CursorTypeNoCursor sends the rows with the response.
+	CursorTypeNoCursor byte = 0x00
+	// CursorTypeReadOnly opens a read-only cursor, to fetch the rows
+	// with COM_STMT_FETCH.
+	CursorTypeReadOnly byte = 0x01
+	// ParameterCountAvailable is set with CapabilityClientQueryAttributes
+	// when the number of parameters, query attributes included, is sent.
+	ParameterCountAvailable byte = 0x08
+)
+
 // State Change Information
 const (
 	// one or more system variables changed.
@@ -206,6 +235,9 @@ const (
 	// ComFieldList is COM_Field_List.
 	ComFieldList = 0x04
 
+	// ComChangeUser is COM_CHANGE_USER.
+	ComChangeUser = 0x11
+
 	// ComPing is COM_PING.
 	ComPing = 0x0e
 
@@ -230,7 +262,7 @@ const (
 	// ComStmtReset is COM_STMT_RESET
 	ComStmtReset = 0x1a
 
-	//ComStmtFetch is COM_STMT_FETCH
+	// ComStmtFetch is COM_STMT_FETCH
 	ComStmtFetch = 0x1c
 
 	// ComSetOption is COM_SET_OPTION
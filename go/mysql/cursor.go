@@ -0,0 +1,385 @@
+/*
+Copyright 2025 The Vitess Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package mysql
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"vitess.io/vitess/go/mysql/sqlerror"
+	"vitess.io/vitess/go/sqltypes"
+	"vitess.io/vitess/go/tb"
+	"vitess.io/vitess/go/vt/log"
+	querypb "vitess.io/vitess/go/vt/proto/query"
+	vtrpcpb "vitess.io/vitess/go/vt/proto/vtrpc"
+	"vitess.io/vitess/go/vt/vterrors"
+)
+
+// errCursorClosed is returned to the handler of a cursor that was closed
+// before all of its rows were fetched.
+var errCursorClosed = vterrors.Errorf(vtrpcpb.Code_CANCELED, "cursor closed")
+
+// CursorPool limits the number of cursors that are open at once on the
+// connections of all the listeners that share it.
+type CursorPool struct {
+	max  int64
+	open atomic.Int64
+}
+
+// NewCursorPool returns a pool of max cursors. If max is 0, the number of
+// cursors is not limited.
+func NewCursorPool(max int) *CursorPool {
+	return &CursorPool{max: int64(max)}
+}
+
+// acquire counts a cursor, unless the pool is full.
+func (p *CursorPool) acquire() bool {
+	if p == nil {
+		return true
+	}
+	if p.open.Add(1) > p.max && p.max > 0 {
+		p.open.Add(-1)
+		return false
+	}
+	return true
+}
+
+// release stops counting a cursor.
+func (p *CursorPool) release() {
+	if p != nil {
+		p.open.Add(-1)
+	}
+}
+
+// cursor is the read-only cursor of a statement executed with
+// CursorTypeReadOnly. The handler executes the statement in the background,
+// and each of the results it streams is handed over to the connection when
+// the client fetches the rows of the previous one, so that a cursor holds
+// one result at most. The connection can process other commands meanwhile.
+type cursor struct {
+	fields []*querypb.Field
+
+	// rows are the rows of the current result that were not fetched.
+	rows [][]sqltypes.Value
+
+	// results receives the results of the handler. It is closed when
+	// the handler returned, with err.
+	results chan *sqltypes.Result
+	err     error
+
+	// done is closed to stop the handler when the cursor is closed, and
+	// cancel cancels the context the handler runs the statement with.
+	done   chan struct{}
+	cancel context.CancelFunc
+
+	// pool counts the cursor until it is stopped, once.
+	pool *CursorPool
+	stop sync.Once
+
+	// idleTimer stops the cursor once its rows were not fetched for
+	// idleTimeout. It is nil without a timeout.
+	idleTimer   *time.Timer
+	idleTimeout time.Duration
+	expired     atomic.Bool
+}
+
+// newCursor executes the prepared statement with the handler in the
+// background, under a context of its own that is canceled when the cursor is
+// closed. The cursor is counted in the pool, which must have room for it.
+func newCursor(c *Conn, handler Handler, prepare *PrepareData, pool *CursorPool) *cursor {
+	ctx, cancel := context.WithCancel(context.Background())
+	prepare.ctx = ctx
+	cur := &cursor{
+		results: make(chan *sqltypes.Result),
+		done:    make(chan struct{}),
+		cancel:  cancel,
+		pool:    pool,
+	}
+	go func() {
+		defer close(cur.results)
+		defer cancel()
+		defer func() {
+			if x := recover(); x != nil {
+				log.Errorf("mysql_server caught panic in the cursor of %s:\n%v\n%s", c, x, tb.Stack(4))
+				cur.err = vterrors.Errorf(vtrpcpb.Code_INTERNAL, "%v", x)
+			}
+		}()
+		cur.err = handler.ComStmtExecute(c, prepare, func(qr *sqltypes.Result) error {
+			select {
+			case <-cur.done:
+				return errCursorClosed
+			default:
+			}
+			select {
+			case cur.results <- qr:
+				return nil
+			case <-cur.done:
+				return errCursorClosed
+			}
+		})
+	}()
+	return cur
+}
+
+// startIdleTimer stops the cursor once its rows are not fetched for the
+// timeout, so that it does not hold a stream, nor a place in the pool,
+// for a client that went away. The cursor must be closed still.
+func (cur *cursor) startIdleTimer(timeout time.Duration) {
+	if timeout <= 0 {
+		return
+	}
+	cur.idleTimeout = timeout
+	cur.idleTimer = time.AfterFunc(timeout, func() {
+		cur.expired.Store(true)
+		cur.stopHandler()
+	})
+}
+
+// fetch sends up to n rows of the cursor, and returns whether the last row
+// was sent.
+func (cur *cursor) fetch(n int, send func(rows [][]sqltypes.Value) error) (last bool, err error) {
+	if cur.idleTimer != nil {
+		if !cur.idleTimer.Stop() {
+			return false, sqlerror.NewSQLErrorf(sqlerror.ERQueryInterrupted, sqlerror.SSQueryInterrupted, "cursor closed after its rows were not fetched for %v", cur.idleTimeout)
+		}
+		defer func() {
+			if !last && err == nil {
+				cur.idleTimer.Reset(cur.idleTimeout)
+			}
+		}()
+	}
+	for n > 0 {
+		if len(cur.rows) == 0 {
+			qr, ok := <-cur.results
+			if !ok {
+				return true, cur.err
+			}
+			cur.rows = qr.Rows
+			continue
+		}
+		rows := cur.rows[:min(n, len(cur.rows))]
+		cur.rows = cur.rows[len(rows):]
+		n -= len(rows)
+		if err := send(rows); err != nil {
+			return false, err
+		}
+	}
+	return false, nil
+}
+
+// stopHandler stops the handler, and releases the cursor from the pool.
+func (cur *cursor) stopHandler() {
+	cur.stop.Do(func() {
+		cur.cancel()
+		close(cur.done)
+		cur.pool.release()
+	})
+}
+
+// close stops the handler, and waits for it to return.
+func (cur *cursor) close() {
+	if cur.idleTimer != nil {
+		cur.idleTimer.Stop()
+	}
+	cur.stopHandler()
+	for range cur.results {
+	}
+}
+
+// executeWithCursor executes the prepared statement with a read-only cursor.
+// If the statement returns rows, only its fields are sent, with the
+// ServerStatusCursorExists flag.
+func (c *Conn) executeWithCursor(handler Handler, prepare *PrepareData) bool {
+	if c.openCursors() >= c.maxOpenCursors {
+		return c.writeErrorAndLog(sqlerror.EROutOfResources, sqlerror.SSUnknownSQLState, "too many open cursors, the connection can have %d at most", c.maxOpenCursors)
+	}
+	if !c.cursorPool.acquire() {
+		return c.writeErrorAndLog(sqlerror.EROutOfResources, sqlerror.SSUnknownSQLState, "too many open cursors, the server can have %d at most", c.cursorPool.max)
+	}
+
+	// The handler reads a copy of the statement, whose bind variables
+	// can be set again while the cursor is open.
+	statement := *prepare
+	statement.BindVars = make(map[string]*querypb.BindVariable, len(prepare.BindVars))
+	for name, bv := range prepare.BindVars {
+		statement.BindVars[name] = bv.CloneVT()
+	}
+	cur := newCursor(c, handler, &statement, c.cursorPool)
+	qr, ok := <-cur.results
+	if !ok {
+		cur.close()
+		err := cur.err
+		if err == nil {
+			// This is just a failsafe. Should never happen.
+			err = sqlerror.NewSQLErrorFromError(errors.New("unexpected: query ended without no results and no error"))
+		}
+		return c.writeErrorPacketFromErrorAndLog(err)
+	}
+
+	// No cursor is opened for the statements that return no rows.
+	if len(qr.Fields) == 0 {
+		for range cur.results {
+		}
+		cur.close()
+		if cur.err != nil {
+			log.Errorf("Error after the result of a statement was sent to %s: %v", c, cur.err)
+			return false
+		}
+		if err := c.writeOKPacket(&PacketOK{
+			affectedRows:     qr.RowsAffected,
+			lastInsertID:     qr.InsertID,
+			statusFlags:      c.StatusFlags,
+			sessionStateData: qr.SessionStateChanges,
+		}); err != nil {
+			log.Errorf("Error writing result to %s: %v", c, err)
+			return false
+		}
+		return true
+	}
+
+	cur.fields = qr.Fields
+	cur.rows = qr.Rows
+	prepare.cursor = cur
+	if err := c.writeCursorFields(cur.fields); err != nil {
+		log.Errorf("Error writing fields to %s: %v", c, err)
+		return false
+	}
+	cur.startIdleTimer(c.cursorIdleTimeout)
+	return true
+}
+
+// handleComStmtFetch sends the rows the client fetches from the cursor of a
+// statement. The cursor is closed once its last row is sent.
+func (c *Conn) handleComStmtFetch(data []byte) (kontinue bool) {
+	c.startWriterBuffering()
+	defer func() {
+		if err := c.endWriterBuffering(); err != nil {
+			log.Errorf("conn %v: flush() failed: %v", c.ID(), err)
+			kontinue = false
+		}
+	}()
+
+	stmtID, numRows, ok := c.parseComStmtFetch(data)
+	c.recycleReadPacket()
+	if !ok {
+		return c.writeErrorAndLog(sqlerror.CRMalformedPacket, sqlerror.SSUnknownSQLState, "error parsing COM_STMT_FETCH: %v", data)
+	}
+	prepare, ok := c.PrepareData[stmtID]
+	if !ok {
+		return c.writeErrorAndLog(sqlerror.ERUnknownStmtHandler, sqlerror.SSUnknownSQLState, "Unknown prepared statement handler (%d) given to mysqld_stmt_fetch", stmtID)
+	}
+	cur := prepare.cursor
+	if cur == nil {
+		return c.writeErrorAndLog(sqlerror.ERStmtHasNoOpenCursor, sqlerror.SSUnknownSQLState, "The statement (%d) has no open cursor.", stmtID)
+	}
+
+	sentRows := false
+	last, err := cur.fetch(int(numRows), func(rows [][]sqltypes.Value) error {
+		sentRows = true
+		for _, row := range rows {
+			if err := c.writeBinaryRow(cur.fields, row); err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+	if err != nil {
+		c.closeCursor(prepare)
+		if sentRows {
+			// We can't send an error in the middle of a stream.
+			// All we can do is abort the send, which will cause a 2013.
+			log.Errorf("Error in the middle of a stream to %s: %v", c, err)
+			return false
+		}
+		return c.writeErrorPacketFromErrorAndLog(err)
+	}
+
+	flags := c.StatusFlags | ServerStatusCursorExists
+	if last {
+		c.closeCursor(prepare)
+		flags = c.StatusFlags | ServerStatusLastRowSent
+	}
+	if err := c.writeCursorStatus(flags); err != nil {
+		log.Errorf("Error writing result to %s: %v", c, err)
+		return false
+	}
+	return true
+}
+
+func (c *Conn) parseComStmtFetch(data []byte) (uint32, uint32, bool) {
+	stmtID, pos, ok := readUint32(data, 1)
+	if !ok {
+		return 0, 0, false
+	}
+	numRows, _, ok := readUint32(data, pos)
+	return stmtID, numRows, ok
+}
+
+// writeCursorFields sends the fields of the result of a cursor, ended by the
+// status of the cursor.
+func (c *Conn) writeCursorFields(fields []*querypb.Field) error {
+	if err := c.sendColumnCount(uint64(len(fields))); err != nil {
+		return err
+	}
+	for _, field := range fields {
+		if err := c.writeColumnDefinition(field); err != nil {
+			return err
+		}
+	}
+	return c.writeCursorStatus(c.StatusFlags | ServerStatusCursorExists)
+}
+
+// writeCursorStatus sends the EOF packet, or an OK packet with an EOF header
+// if CapabilityClientDeprecateEOF is set, that tells whether the cursor is
+// still open.
+func (c *Conn) writeCursorStatus(flags uint16) error {
+	if c.Capabilities&CapabilityClientDeprecateEOF == 0 {
+		return c.writeEOFPacket(flags, 0)
+	}
+	return c.writeOKPacketWithEOFHeader(&PacketOK{statusFlags: flags})
+}
+
+// openCursors returns the number of cursors open on the connection. The
+// cursors that were stopped by their idle timeout are not counted.
+func (c *Conn) openCursors() int {
+	count := 0
+	for _, prepare := range c.PrepareData {
+		if prepare.cursor != nil && !prepare.cursor.expired.Load() {
+			count++
+		}
+	}
+	return count
+}
+
+// closeCursor closes the cursor of a statement, if it has one.
+func (c *Conn) closeCursor(prepare *PrepareData) {
+	if prepare == nil || prepare.cursor == nil {
+		return
+	}
+	prepare.cursor.close()
+	prepare.cursor = nil
+}
+
+// closeCursors closes the cursors of all the statements.
+func (c *Conn) closeCursors() {
+	for _, prepare := range c.PrepareData {
+		c.closeCursor(prepare)
+	}
+}
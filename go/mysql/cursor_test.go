@@ -0,0 +1,214 @@
+/*
+Copyright 2025 The Vitess Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package mysql
+
+import (
+	"context"
+	"encoding/binary"
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"vitess.io/vitess/go/mysql/sqlerror"
+	"vitess.io/vitess/go/sqltypes"
+)
+
+// cursorTestHandler streams 3 results of 2 rows for the prepared selects,
+// and reports the error it returned.
+type cursorTestHandler struct {
+	testHandler
+	returned chan error
+}
+
+func (th *cursorTestHandler) ComStmtExecute(c *Conn, prepare *PrepareData, callback func(*sqltypes.Result) error) error {
+	err := th.execute(prepare, callback)
+	th.returned <- err
+	return err
+}
+
+func (th *cursorTestHandler) execute(prepare *PrepareData, callback func(*sqltypes.Result) error) error {
+	if prepare.PrepareStmt == "insert" {
+		return callback(&sqltypes.Result{RowsAffected: 1})
+	}
+	if prepare.PrepareStmt == "blocking" {
+		// The select waits for rows that never come.
+		if err := callback(&sqltypes.Result{Fields: sqltypes.MakeTestFields("id", "int64")}); err != nil {
+			return err
+		}
+		<-prepare.Context().Done()
+		return prepare.Context().Err()
+	}
+	if err := callback(&sqltypes.Result{Fields: sqltypes.MakeTestFields("id", "int64")}); err != nil {
+		return err
+	}
+	for i := range 3 {
+		if err := callback(sqltypes.MakeTestResult(sqltypes.MakeTestFields("id", "int64"), fmt.Sprint(2*i+1), fmt.Sprint(2*i+2))); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func TestCursor(t *testing.T) {
+	listener, sConn, cConn := createSocketPair(t)
+	defer func() {
+		listener.Close()
+		sConn.Close()
+		cConn.Close()
+	}()
+	sConn.maxOpenCursors = 1
+	sConn.PrepareData[1] = &PrepareData{StatementID: 1, PrepareStmt: "select id from t"}
+	sConn.PrepareData[2] = &PrepareData{StatementID: 2, PrepareStmt: "select id from t"}
+	sConn.PrepareData[3] = &PrepareData{StatementID: 3, PrepareStmt: "insert"}
+	sConn.PrepareData[4] = &PrepareData{StatementID: 4, PrepareStmt: "blocking"}
+	sConn.PrepareData[5] = &PrepareData{StatementID: 5, PrepareStmt: "blocking"}
+	th := &cursorTestHandler{returned: make(chan error, 1)}
+
+	command := func(payload ...byte) {
+		cConn.sequence = 0
+		useWritePacket(t, cConn, payload)
+		require.True(t, sConn.handleNextCommand(th))
+	}
+	execute := func(stmtID uint32, cursorType byte) {
+		payload := binary.LittleEndian.AppendUint32([]byte{ComStmtExecute}, stmtID)
+		payload = append(payload, cursorType)
+		payload = binary.LittleEndian.AppendUint32(payload, 1)
+		command(payload...)
+	}
+	fetch := func(stmtID uint32, numRows uint32) {
+		payload := binary.LittleEndian.AppendUint32([]byte{ComStmtFetch}, stmtID)
+		payload = binary.LittleEndian.AppendUint32(payload, numRows)
+		command(payload...)
+	}
+	// readRows reads rows until the EOF packet, and returns its flags.
+	readRows := func() (int, uint16) {
+		rows := 0
+		for {
+			data, err := cConn.ReadPacket()
+			require.NoError(t, err)
+			if data[0] == ErrPacket {
+				require.NoError(t, ParseErrorPacket(data))
+			}
+			if data[0] == EOFPacket {
+				return rows, binary.LittleEndian.Uint16(data[3:])
+			}
+			rows++
+		}
+	}
+	// readFields reads the fields, and returns the flags of their EOF.
+	readFields := func() uint16 {
+		data, err := cConn.ReadPacket()
+		require.NoError(t, err)
+		require.Equal(t, []byte{1}, data)
+		rows, flags := readRows()
+		require.Equal(t, 1, rows)
+		return flags
+	}
+	readError := func() sqlerror.ErrorCode {
+		data, err := cConn.ReadPacket()
+		require.NoError(t, err)
+		require.EqualValues(t, ErrPacket, data[0])
+		return ParseErrorPacket(data).(*sqlerror.SQLError).Number()
+	}
+
+	// The rows are fetched from an open cursor.
+	execute(1, CursorTypeReadOnly)
+	assert.NotZero(t, readFields()&ServerStatusCursorExists)
+	fetch(1, 3)
+	rows, flags := readRows()
+	assert.Equal(t, 3, rows)
+	assert.NotZero(t, flags&ServerStatusCursorExists)
+	fetch(1, 10)
+	rows, flags = readRows()
+	assert.Equal(t, 3, rows)
+	assert.NotZero(t, flags&ServerStatusLastRowSent)
+	assert.Zero(t, flags&ServerStatusCursorExists)
+	assert.NoError(t, <-th.returned)
+	fetch(1, 1)
+	assert.Equal(t, sqlerror.ERStmtHasNoOpenCursor, readError())
+	fetch(6, 1)
+	assert.Equal(t, sqlerror.ERUnknownStmtHandler, readError())
+
+	// The number of open cursors is limited.
+	execute(1, CursorTypeReadOnly)
+	assert.NotZero(t, readFields()&ServerStatusCursorExists)
+	execute(2, CursorTypeReadOnly)
+	assert.Equal(t, sqlerror.EROutOfResources, readError())
+
+	// Closing the statement stops the handler.
+	command(binary.LittleEndian.AppendUint32([]byte{ComStmtClose}, 1)...)
+	assert.ErrorIs(t, <-th.returned, errCursorClosed)
+	assert.Zero(t, sConn.openCursors())
+
+	// Closing the statement cancels the context of a handler that waits
+	// for rows.
+	execute(4, CursorTypeReadOnly)
+	assert.NotZero(t, readFields()&ServerStatusCursorExists)
+	command(binary.LittleEndian.AppendUint32([]byte{ComStmtClose}, 4)...)
+	assert.ErrorIs(t, <-th.returned, context.Canceled)
+	assert.Zero(t, sConn.openCursors())
+
+	// No cursor is opened for the statements that return no rows.
+	execute(3, CursorTypeReadOnly)
+	data, err := cConn.ReadPacket()
+	require.NoError(t, err)
+	assert.EqualValues(t, OKPacket, data[0])
+	assert.NoError(t, <-th.returned)
+	fetch(3, 1)
+	assert.Equal(t, sqlerror.ERStmtHasNoOpenCursor, readError())
+
+	// The cursors of all the connections are limited by their pool.
+	sConn.cursorPool = NewCursorPool(1)
+	sConn.cursorPool.open.Store(1)
+	execute(2, CursorTypeReadOnly)
+	assert.Equal(t, sqlerror.EROutOfResources, readError())
+	sConn.cursorPool.open.Store(0)
+	execute(2, CursorTypeReadOnly)
+	assert.NotZero(t, readFields()&ServerStatusCursorExists)
+	assert.EqualValues(t, 1, sConn.cursorPool.open.Load())
+	fetch(2, 10)
+	rows, flags = readRows()
+	assert.Equal(t, 6, rows)
+	assert.NotZero(t, flags&ServerStatusLastRowSent)
+	assert.NoError(t, <-th.returned)
+	assert.Zero(t, sConn.cursorPool.open.Load())
+
+	// A cursor whose rows are not fetched is stopped after the idle
+	// timeout, and leaves the pool.
+	sConn.cursorIdleTimeout = time.Millisecond
+	execute(5, CursorTypeReadOnly)
+	assert.NotZero(t, readFields()&ServerStatusCursorExists)
+	assert.ErrorIs(t, <-th.returned, context.Canceled)
+	assert.Zero(t, sConn.cursorPool.open.Load())
+	assert.Zero(t, sConn.openCursors())
+	fetch(5, 1)
+	assert.Equal(t, sqlerror.ERQueryInterrupted, readError())
+	fetch(5, 1)
+	assert.Equal(t, sqlerror.ERStmtHasNoOpenCursor, readError())
+	sConn.cursorIdleTimeout = 0
+
+	// Without cursors, the rows are sent with the response.
+	sConn.maxOpenCursors = 0
+	execute(2, CursorTypeReadOnly)
+	assert.Zero(t, readFields()&ServerStatusCursorExists)
+	rows, _ = readRows()
+	assert.Equal(t, 6, rows)
+	assert.NoError(t, <-th.returned)
+}
@@ -0,0 +1,436 @@
+/*
+Copyright 2025 The Vitess Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package jwtauthserver
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"net"
+	"net/http"
+	"os"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/go-jose/go-jose/v4"
+	"github.com/go-jose/go-jose/v4/jwt"
+
+	"vitess.io/vitess/go/mysql"
+	"vitess.io/vitess/go/mysql/sqlerror"
+	"vitess.io/vitess/go/vt/log"
+)
+
+const (
+	// httpTimeout bounds the requests made to the issuer.
+	httpTimeout = 10 * time.Second
+
+	// maxResponseSize bounds the size of the documents fetched from the issuer.
+	maxResponseSize = 1 << 20
+
+	// minReloadInterval rate limits the reloads of the key set that are
+	// triggered by tokens signed with an unknown key, so that clients
+	// cannot make us hammer the issuer.
+	minReloadInterval = time.Minute
+)
+
+// signatureAlgorithms are the accepted token signature algorithms. Only
+// asymmetric algorithms are accepted, as the verification keys are public.
+var signatureAlgorithms = []jose.SignatureAlgorithm{
+	jose.RS256, jose.RS384, jose.RS512,
+	jose.PS256, jose.PS384, jose.PS512,
+	jose.ES256, jose.ES384, jose.ES512,
+	jose.EdDSA,
+}
+
+// Config holds the configuration of AuthServerJWT.
+type Config struct {
+	// Issuer is the expected "iss" claim of the tokens. Unless JWKSURL or
+	// JWKSFile is set, the key set URL is discovered from the OpenID
+	// configuration of the issuer.
+	Issuer string
+	// Audience, if set, must be contained in the "aud" claim of the tokens.
+	Audience string
+	// JWKSURL is the URL the JSON Web Key Set is fetched from.
+	JWKSURL string
+	// JWKSFile is a local file the JSON Web Key Set is read from, for
+	// deployments without network access to the issuer.
+	JWKSFile string
+	// UsernameClaim is the claim holding the username. It must match the
+	// user the client connects as.
+	UsernameClaim string
+	// GroupsClaim is the claim holding the groups of the user. It may be a
+	// string or a list of strings.
+	GroupsClaim string
+	// RefreshInterval is how often the key set is reloaded. Zero disables
+	// periodic reloads.
+	RefreshInterval time.Duration
+}
+
+// AuthServerJWT implements AuthServer by validating a JSON Web Token sent
+// as the password through mysql_clear_password. The username and groups
+// of the caller are taken from the claims of the token.
+type AuthServerJWT struct {
+	methods    []mysql.AuthMethod
+	config     Config
+	httpClient *http.Client
+
+	// reloadMu serializes the reloads of the key set.
+	reloadMu   sync.Mutex
+	lastReload time.Time
+
+	mu      sync.Mutex
+	keys    *jose.JSONWebKeySet
+	jwksURL string
+
+	ticker *time.Ticker
+	done   chan struct{}
+}
+
+// Init is public so it can be called from plugin_auth_jwt.go (go/cmd/vtgate)
+func Init(config Config) {
+	if config.Issuer == "" {
+		log.Infof("Not configuring AuthServerJWT, as --mysql-auth-jwt-issuer is empty.")
+		return
+	}
+	authServerJWT, err := newAuthServerJWT(config)
+	if err != nil {
+		log.Exitf("%s", err)
+	}
+	mysql.RegisterAuthServer("jwt", authServerJWT)
+}
+
+func newAuthServerJWT(config Config) (*AuthServerJWT, error) {
+	if config.Issuer == "" {
+		return nil, fmt.Errorf("--mysql-auth-jwt-issuer is required for the jwt auth server")
+	}
+	if config.JWKSURL != "" && config.JWKSFile != "" {
+		return nil, fmt.Errorf("only one of --mysql-auth-jwt-jwks-url and --mysql-auth-jwt-jwks-file can be set")
+	}
+	if config.UsernameClaim == "" {
+		config.UsernameClaim = "sub"
+	}
+
+	a := &AuthServerJWT{
+		config:     config,
+		httpClient: &http.Client{Timeout: httpTimeout},
+		jwksURL:    config.JWKSURL,
+		done:       make(chan struct{}),
+	}
+	a.methods = []mysql.AuthMethod{mysql.NewMysqlClearAuthMethod(a, a)}
+
+	// If the issuer is unreachable at startup, keep going: the key set is
+	// reloaded when the first token comes in.
+	if err := a.reload(); err != nil {
+		log.Errorf("Error loading JWKS for the jwt auth server, will retry: %v", err)
+	}
+	if config.RefreshInterval > 0 {
+		a.ticker = time.NewTicker(config.RefreshInterval)
+		go a.refreshLoop()
+	}
+	return a, nil
+}
+
+// AuthMethods returns the list of registered auth methods
+// implemented by this auth server.
+func (a *AuthServerJWT) AuthMethods() []mysql.AuthMethod {
+	return a.methods
+}
+
+// DefaultAuthMethodDescription returns MysqlNativePassword as the default
+// authentication method for the auth server implementation.
+func (a *AuthServerJWT) DefaultAuthMethodDescription() mysql.AuthMethodDescription {
+	return mysql.MysqlNativePassword
+}
+
+// HandleUser is part of the UserValidator interface. We
+// handle any user here since we don't check up front.
+func (a *AuthServerJWT) HandleUser(user string) bool {
+	return true
+}
+
+// UserEntryWithPassword is part of the PlaintextStorage interface
+// and called after the token is sent by the client.
+func (a *AuthServerJWT) UserEntryWithPassword(conn *mysql.Conn, user string, password string, remoteAddr net.Addr) (mysql.Getter, error) {
+	userData, err := a.validate(user, password)
+	if err != nil {
+		log.Warningf("Rejecting token of user %s from %v: %v", user, remoteAddr, err)
+		return nil, sqlerror.NewSQLErrorf(sqlerror.ERAccessDeniedError, sqlerror.SSAccessDeniedError, "Access denied for user '%v'", user)
+	}
+	return userData, nil
+}
+
+// Close stops the periodic reloads of the key set.
+func (a *AuthServerJWT) Close() {
+	if a.ticker != nil {
+		a.ticker.Stop()
+		close(a.done)
+	}
+}
+
+func (a *AuthServerJWT) validate(user, token string) (*mysql.StaticUserData, error) {
+	tok, err := jwt.ParseSigned(token, signatureAlgorithms)
+	if err != nil {
+		return nil, fmt.Errorf("malformed token: %v", err)
+	}
+	key, err := a.key(tok.Headers[0].KeyID)
+	if err != nil {
+		return nil, err
+	}
+
+	var claims jwt.Claims
+	var custom map[string]any
+	if err := tok.Claims(key, &claims, &custom); err != nil {
+		return nil, err
+	}
+	expected := jwt.Expected{Issuer: a.config.Issuer}
+	if a.config.Audience != "" {
+		expected.AnyAudience = jwt.Audience{a.config.Audience}
+	}
+	if err := claims.ValidateWithLeeway(expected, jwt.DefaultLeeway); err != nil {
+		return nil, err
+	}
+	// Tokens without an expiry would be valid forever.
+	if claims.Expiry == nil {
+		return nil, fmt.Errorf("token has no exp claim")
+	}
+
+	username, err := stringClaim(custom, a.config.UsernameClaim)
+	if err != nil {
+		return nil, err
+	}
+	if username != user {
+		return nil, fmt.Errorf("MySQL connection username '%v' does not match the token %s claim '%v'", user, a.config.UsernameClaim, username)
+	}
+	var groups []string
+	if a.config.GroupsClaim != "" {
+		if groups, err = stringsClaim(custom, a.config.GroupsClaim); err != nil {
+			return nil, err
+		}
+	}
+	return &mysql.StaticUserData{Username: username, Groups: groups}, nil
+}
+
+// key returns the verification key with the given id. If the key set does
+// not have it, the issuer may have rotated its keys, so the key set is
+// reloaded before giving up.
+func (a *AuthServerJWT) key(kid string) (*jose.JSONWebKey, error) {
+	if key := a.lookup(kid); key != nil {
+		return key, nil
+	}
+	if err := a.reloadIfStale(); err != nil {
+		return nil, fmt.Errorf("no verification key for kid %q: %v", kid, err)
+	}
+	if key := a.lookup(kid); key != nil {
+		return key, nil
+	}
+	return nil, fmt.Errorf("no verification key for kid %q", kid)
+}
+
+// lookup finds the signing key with the given id. A token without a key
+// id can only be verified against a key set with a single key.
+func (a *AuthServerJWT) lookup(kid string) *jose.JSONWebKey {
+	a.mu.Lock()
+	keys := a.keys
+	a.mu.Unlock()
+	if keys == nil {
+		return nil
+	}
+
+	candidates := keys.Keys
+	if kid != "" {
+		candidates = keys.Key(kid)
+	} else if len(candidates) != 1 {
+		return nil
+	}
+	for i := range candidates {
+		if candidates[i].Use == "" || candidates[i].Use == "sig" {
+			return &candidates[i]
+		}
+	}
+	return nil
+}
+
+func (a *AuthServerJWT) refreshLoop() {
+	for {
+		select {
+		case <-a.ticker.C:
+			if err := a.reload(); err != nil {
+				log.Errorf("Error reloading JWKS for the jwt auth server, keeping the previous keys: %v", err)
+			}
+		case <-a.done:
+			return
+		}
+	}
+}
+
+// reloadIfStale reloads the key set unless it was reloaded recently.
+func (a *AuthServerJWT) reloadIfStale() error {
+	a.reloadMu.Lock()
+	defer a.reloadMu.Unlock()
+	if time.Since(a.lastReload) < minReloadInterval {
+		return nil
+	}
+	return a.reloadLocked()
+}
+
+func (a *AuthServerJWT) reload() error {
+	a.reloadMu.Lock()
+	defer a.reloadMu.Unlock()
+	return a.reloadLocked()
+}
+
+func (a *AuthServerJWT) reloadLocked() error {
+	a.lastReload = time.Now()
+	keys, err := a.loadKeys()
+	if err != nil {
+		return err
+	}
+	a.mu.Lock()
+	a.keys = keys
+	a.mu.Unlock()
+	return nil
+}
+
+func (a *AuthServerJWT) loadKeys() (*jose.JSONWebKeySet, error) {
+	var data []byte
+	var err error
+	if a.config.JWKSFile != "" {
+		data, err = os.ReadFile(a.config.JWKSFile)
+	} else {
+		var url string
+		if url, err = a.discoverJWKSURL(); err == nil {
+			data, err = a.fetch(url)
+		}
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	keys := &jose.JSONWebKeySet{}
+	if err := json.Unmarshal(data, keys); err != nil {
+		return nil, fmt.Errorf("error parsing JWKS: %v", err)
+	}
+	if len(keys.Keys) == 0 {
+		return nil, fmt.Errorf("JWKS has no keys")
+	}
+	for _, key := range keys.Keys {
+		if !key.IsPublic() {
+			return nil, fmt.Errorf("JWKS key %q is not a public key", key.KeyID)
+		}
+	}
+	return keys, nil
+}
+
+// discoverJWKSURL returns the key set URL, looking it up in the OpenID
+// configuration of the issuer the first time.
+func (a *AuthServerJWT) discoverJWKSURL() (string, error) {
+	a.mu.Lock()
+	url := a.jwksURL
+	a.mu.Unlock()
+	if url != "" {
+		return url, nil
+	}
+
+	data, err := a.fetch(strings.TrimSuffix(a.config.Issuer, "/") + "/.well-known/openid-configuration")
+	if err != nil {
+		return "", err
+	}
+	var discovery struct {
+		JWKSURI string `json:"jwks_uri"`
+	}
+	if err := json.Unmarshal(data, &discovery); err != nil {
+		return "", fmt.Errorf("error parsing OpenID configuration: %v", err)
+	}
+	if discovery.JWKSURI == "" {
+		return "", fmt.Errorf("OpenID configuration of %s has no jwks_uri", a.config.Issuer)
+	}
+
+	a.mu.Lock()
+	a.jwksURL = discovery.JWKSURI
+	a.mu.Unlock()
+	return discovery.JWKSURI, nil
+}
+
+func (a *AuthServerJWT) fetch(url string) ([]byte, error) {
+	resp, err := a.httpClient.Get(url)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("unexpected status fetching %s: %s", url, resp.Status)
+	}
+	return io.ReadAll(io.LimitReader(resp.Body, maxResponseSize))
+}
+
+// claim returns the value of the named claim. Names that are not top
+// level claims are looked up as dot separated paths into nested claims,
+// e.g. "realm_access.roles".
+func claim(claims map[string]any, name string) (any, bool) {
+	if value, ok := claims[name]; ok {
+		return value, true
+	}
+	var value any = claims
+	for _, part := range strings.Split(name, ".") {
+		nested, ok := value.(map[string]any)
+		if !ok {
+			return nil, false
+		}
+		if value, ok = nested[part]; !ok {
+			return nil, false
+		}
+	}
+	return value, true
+}
+
+func stringClaim(claims map[string]any, name string) (string, error) {
+	value, ok := claim(claims, name)
+	if !ok {
+		return "", fmt.Errorf("token has no %s claim", name)
+	}
+	s, ok := value.(string)
+	if !ok || s == "" {
+		return "", fmt.Errorf("token %s claim is not a non-empty string", name)
+	}
+	return s, nil
+}
+
+// stringsClaim returns the named claim as a list of strings. A missing
+// claim is an empty list.
+func stringsClaim(claims map[string]any, name string) ([]string, error) {
+	value, ok := claim(claims, name)
+	if !ok {
+		return nil, nil
+	}
+	switch value := value.(type) {
+	case string:
+		return []string{value}, nil
+	case []any:
+		strs := make([]string, 0, len(value))
+		for _, v := range value {
+			s, ok := v.(string)
+			if !ok {
+				return nil, fmt.Errorf("token %s claim is not a list of strings", name)
+			}
+			strs = append(strs, s)
+		}
+		return strs, nil
+	default:
+		return nil, fmt.Errorf("token %s claim is not a list of strings", name)
+	}
+}
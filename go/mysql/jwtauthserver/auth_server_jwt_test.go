@@ -0,0 +1,360 @@
+/*
+Copyright 2025 The Vitess Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package jwtauthserver
+
+import (
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/go-jose/go-jose/v4"
+	"github.com/go-jose/go-jose/v4/jwt"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"vitess.io/vitess/go/mysql/sqlerror"
+)
+
+const testIssuer = "https://issuer.example.com"
+
+type testKey struct {
+	kid  string
+	priv *ecdsa.PrivateKey
+}
+
+func newTestKey(t *testing.T, kid string) *testKey {
+	priv, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	require.NoError(t, err)
+	return &testKey{kid: kid, priv: priv}
+}
+
+func (k *testKey) public() jose.JSONWebKey {
+	return jose.JSONWebKey{Key: &k.priv.PublicKey, KeyID: k.kid, Algorithm: string(jose.ES256), Use: "sig"}
+}
+
+func (k *testKey) sign(t *testing.T, claims jwt.Claims, custom map[string]any) string {
+	signer, err := jose.NewSigner(jose.SigningKey{
+		Algorithm: jose.ES256,
+		Key:       jose.JSONWebKey{Key: k.priv, KeyID: k.kid},
+	}, (&jose.SignerOptions{}).WithType("JWT"))
+	require.NoError(t, err)
+	token, err := jwt.Signed(signer).Claims(claims).Claims(custom).Serialize()
+	require.NoError(t, err)
+	return token
+}
+
+func writeJWKS(t *testing.T, path string, keys ...*testKey) {
+	data, err := json.Marshal(jwks(keys...))
+	require.NoError(t, err)
+	require.NoError(t, os.WriteFile(path, data, 0600))
+}
+
+func jwks(keys ...*testKey) *jose.JSONWebKeySet {
+	set := &jose.JSONWebKeySet{}
+	for _, key := range keys {
+		set.Keys = append(set.Keys, key.public())
+	}
+	return set
+}
+
+func validClaims(subject string) jwt.Claims {
+	now := time.Now()
+	return jwt.Claims{
+		Issuer:   testIssuer,
+		Subject:  subject,
+		Audience: jwt.Audience{"vtgate"},
+		IssuedAt: jwt.NewNumericDate(now),
+		Expiry:   jwt.NewNumericDate(now.Add(time.Hour)),
+	}
+}
+
+func TestValidate(t *testing.T) {
+	key := newTestKey(t, "key1")
+	otherKey := newTestKey(t, "key1")
+	jwksFile := filepath.Join(t.TempDir(), "jwks.json")
+	writeJWKS(t, jwksFile, key)
+
+	a, err := newAuthServerJWT(Config{
+		Issuer:        testIssuer,
+		Audience:      "vtgate",
+		JWKSFile:      jwksFile,
+		UsernameClaim: "preferred_username",
+		GroupsClaim:   "groups",
+	})
+	require.NoError(t, err)
+	defer a.Close()
+
+	custom := map[string]any{
+		"preferred_username": "alice",
+		"groups":             []string{"readers", "writers"},
+	}
+
+	userData, err := a.validate("alice", key.sign(t, validClaims("a1"), custom))
+	require.NoError(t, err)
+	assert.Equal(t, "alice", userData.Get().Username)
+	assert.Equal(t, []string{"readers", "writers"}, userData.Get().Groups)
+
+	expired := validClaims("a1")
+	expired.Expiry = jwt.NewNumericDate(time.Now().Add(-time.Hour))
+	noExpiry := validClaims("a1")
+	noExpiry.Expiry = nil
+	wrongIssuer := validClaims("a1")
+	wrongIssuer.Issuer = "https://other.example.com"
+	wrongAudience := validClaims("a1")
+	wrongAudience.Audience = jwt.Audience{"other"}
+
+	testCases := []struct {
+		name  string
+		user  string
+		token string
+		err   string
+	}{{
+		name:  "user mismatch",
+		user:  "bob",
+		token: key.sign(t, validClaims("a1"), custom),
+		err:   "MySQL connection username 'bob' does not match the token preferred_username claim 'alice'",
+	}, {
+		name:  "expired",
+		user:  "alice",
+		token: key.sign(t, expired, custom),
+		err:   "token is expired",
+	}, {
+		name:  "no expiry",
+		user:  "alice",
+		token: key.sign(t, noExpiry, custom),
+		err:   "token has no exp claim",
+	}, {
+		name:  "wrong issuer",
+		user:  "alice",
+		token: key.sign(t, wrongIssuer, custom),
+		err:   "invalid issuer claim",
+	}, {
+		name:  "wrong audience",
+		user:  "alice",
+		token: key.sign(t, wrongAudience, custom),
+		err:   "invalid audience claim",
+	}, {
+		name:  "wrong signing key",
+		user:  "alice",
+		token: otherKey.sign(t, validClaims("a1"), custom),
+		err:   "error in cryptographic primitive",
+	}, {
+		name:  "unknown key",
+		user:  "alice",
+		token: newTestKey(t, "key2").sign(t, validClaims("a1"), custom),
+		err:   `no verification key for kid "key2"`,
+	}, {
+		name:  "no username",
+		user:  "alice",
+		token: key.sign(t, validClaims("a1"), map[string]any{}),
+		err:   "token has no preferred_username claim",
+	}, {
+		name:  "not a token",
+		user:  "alice",
+		token: "hunter2",
+		err:   "malformed token",
+	}}
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			_, err := a.validate(tc.user, tc.token)
+			require.ErrorContains(t, err, tc.err)
+		})
+	}
+}
+
+func TestValidateSymmetricToken(t *testing.T) {
+	key := newTestKey(t, "key1")
+	jwksFile := filepath.Join(t.TempDir(), "jwks.json")
+	writeJWKS(t, jwksFile, key)
+
+	a, err := newAuthServerJWT(Config{Issuer: testIssuer, JWKSFile: jwksFile})
+	require.NoError(t, err)
+	defer a.Close()
+
+	signer, err := jose.NewSigner(jose.SigningKey{Algorithm: jose.HS256, Key: []byte("0123456789abcdef0123456789abcdef")}, nil)
+	require.NoError(t, err)
+	token, err := jwt.Signed(signer).Claims(validClaims("alice")).Serialize()
+	require.NoError(t, err)
+
+	_, err = a.validate("alice", token)
+	require.ErrorContains(t, err, "malformed token")
+}
+
+func TestGroupsClaim(t *testing.T) {
+	key := newTestKey(t, "key1")
+	jwksFile := filepath.Join(t.TempDir(), "jwks.json")
+	writeJWKS(t, jwksFile, key)
+
+	testCases := []struct {
+		name        string
+		groupsClaim string
+		custom      map[string]any
+		groups      []string
+		err         string
+	}{{
+		name:        "single group",
+		groupsClaim: "groups",
+		custom:      map[string]any{"groups": "readers"},
+		groups:      []string{"readers"},
+	}, {
+		name:        "nested claim",
+		groupsClaim: "realm_access.roles",
+		custom:      map[string]any{"realm_access": map[string]any{"roles": []string{"admin"}}},
+		groups:      []string{"admin"},
+	}, {
+		name:        "namespaced claim",
+		groupsClaim: "https://example.com/groups",
+		custom:      map[string]any{"https://example.com/groups": []string{"dba"}},
+		groups:      []string{"dba"},
+	}, {
+		name:        "missing claim",
+		groupsClaim: "groups",
+		custom:      map[string]any{},
+	}, {
+		name:        "no groups claim",
+		groupsClaim: "",
+		custom:      map[string]any{"groups": []string{"readers"}},
+	}, {
+		name:        "invalid claim",
+		groupsClaim: "groups",
+		custom:      map[string]any{"groups": []int{1, 2}},
+		err:         "token groups claim is not a list of strings",
+	}}
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			a, err := newAuthServerJWT(Config{Issuer: testIssuer, JWKSFile: jwksFile, GroupsClaim: tc.groupsClaim})
+			require.NoError(t, err)
+			defer a.Close()
+
+			userData, err := a.validate("alice", key.sign(t, validClaims("alice"), tc.custom))
+			if tc.err != "" {
+				require.ErrorContains(t, err, tc.err)
+				return
+			}
+			require.NoError(t, err)
+			assert.Equal(t, tc.groups, userData.Groups)
+		})
+	}
+}
+
+func TestJWKSDiscovery(t *testing.T) {
+	key1 := newTestKey(t, "key1")
+	key2 := newTestKey(t, "key2")
+
+	var keys atomic.Pointer[jose.JSONWebKeySet]
+	keys.Store(jwks(key1))
+	var jwksRequests atomic.Int32
+
+	mux := http.NewServeMux()
+	server := httptest.NewServer(mux)
+	defer server.Close()
+	mux.HandleFunc("/.well-known/openid-configuration", func(w http.ResponseWriter, r *http.Request) {
+		_ = json.NewEncoder(w).Encode(map[string]string{"issuer": server.URL, "jwks_uri": server.URL + "/keys"})
+	})
+	mux.HandleFunc("/keys", func(w http.ResponseWriter, r *http.Request) {
+		jwksRequests.Add(1)
+		_ = json.NewEncoder(w).Encode(keys.Load())
+	})
+
+	a, err := newAuthServerJWT(Config{Issuer: server.URL})
+	require.NoError(t, err)
+	defer a.Close()
+	assert.Equal(t, server.URL+"/keys", a.jwksURL)
+	assert.EqualValues(t, 1, jwksRequests.Load())
+
+	claims := validClaims("alice")
+	claims.Issuer = server.URL
+	_, err = a.validate("alice", key1.sign(t, claims, nil))
+	require.NoError(t, err)
+
+	// The issuer rotates its keys. Reloads triggered by unknown keys are
+	// rate limited.
+	keys.Store(jwks(key2))
+	token := key2.sign(t, claims, nil)
+	_, err = a.validate("alice", token)
+	require.ErrorContains(t, err, `no verification key for kid "key2"`)
+	assert.EqualValues(t, 1, jwksRequests.Load())
+
+	a.reloadMu.Lock()
+	a.lastReload = time.Time{}
+	a.reloadMu.Unlock()
+	_, err = a.validate("alice", token)
+	require.NoError(t, err)
+	assert.EqualValues(t, 2, jwksRequests.Load())
+}
+
+func TestJWKSRefresh(t *testing.T) {
+	key1 := newTestKey(t, "key1")
+	key2 := newTestKey(t, "key2")
+	jwksFile := filepath.Join(t.TempDir(), "jwks.json")
+	writeJWKS(t, jwksFile, key1)
+
+	a, err := newAuthServerJWT(Config{Issuer: testIssuer, JWKSFile: jwksFile, RefreshInterval: 10 * time.Millisecond})
+	require.NoError(t, err)
+	defer a.Close()
+
+	writeJWKS(t, jwksFile, key2)
+	require.Eventually(t, func() bool {
+		return a.lookup("key2") != nil
+	}, 5*time.Second, 10*time.Millisecond)
+	assert.Nil(t, a.lookup("key1"))
+}
+
+func TestUserEntryWithPassword(t *testing.T) {
+	key := newTestKey(t, "key1")
+	jwksFile := filepath.Join(t.TempDir(), "jwks.json")
+	writeJWKS(t, jwksFile, key)
+
+	a, err := newAuthServerJWT(Config{Issuer: testIssuer, JWKSFile: jwksFile})
+	require.NoError(t, err)
+	defer a.Close()
+
+	getter, err := a.UserEntryWithPassword(nil, "alice", key.sign(t, validClaims("alice"), nil), nil)
+	require.NoError(t, err)
+	assert.Equal(t, "alice", getter.Get().Username)
+
+	_, err = a.UserEntryWithPassword(nil, "alice", "hunter2", nil)
+	require.Error(t, err)
+	assert.Equal(t, sqlerror.ERAccessDeniedError, sqlerror.NewSQLErrorFromError(err).(*sqlerror.SQLError).Number())
+}
+
+func TestNewAuthServerJWTConfig(t *testing.T) {
+	_, err := newAuthServerJWT(Config{})
+	require.ErrorContains(t, err, "--mysql-auth-jwt-issuer is required")
+
+	_, err = newAuthServerJWT(Config{Issuer: testIssuer, JWKSURL: "https://issuer.example.com/keys", JWKSFile: "/tmp/jwks.json"})
+	require.ErrorContains(t, err, "only one of --mysql-auth-jwt-jwks-url and --mysql-auth-jwt-jwks-file can be set")
+
+	jwksFile := filepath.Join(t.TempDir(), "jwks.json")
+	priv := newTestKey(t, "key1")
+	data, err := json.Marshal(&jose.JSONWebKeySet{Keys: []jose.JSONWebKey{{Key: priv.priv, KeyID: "key1"}}})
+	require.NoError(t, err)
+	require.NoError(t, os.WriteFile(jwksFile, data, 0600))
+	a, err := newAuthServerJWT(Config{Issuer: testIssuer, JWKSFile: jwksFile})
+	require.NoError(t, err)
+	defer a.Close()
+	require.ErrorContains(t, a.reload(), `JWKS key "key1" is not a public key`)
+	assert.Nil(t, a.lookup("key1"))
+}
@@ -58,7 +58,7 @@ const (
 // Returns SQLError(CRServerGone) if it can't.
 func (c *Conn) WriteComQuery(query string) error {
 	// This is a new command, need to reset the sequence.
-	c.sequence = 0
+	c.resetSequence()
 
 	data, pos := c.startEphemeralPacketWithHeader(len(query) + 1)
 	data[pos] = ComQuery
@@ -580,8 +580,91 @@ func (c *Conn) readComQueryResponse(packetOk *PacketOK) (int, error) {
 // Server side methods.
 //
 
-func (c *Conn) parseComQuery(data []byte) string {
-	return string(data[1:])
+// parseComQuery parses the COM_QUERY packet, and sets the QueryAttributes
+// of the connection.
+func (c *Conn) parseComQuery(data []byte) (string, error) {
+	c.QueryAttributes = nil
+	pos := 1
+	if c.Capabilities&CapabilityClientQueryAttributes != 0 {
+		var err error
+		c.QueryAttributes, pos, err = c.parseQueryAttributes(data, pos)
+		if err != nil {
+			return "", err
+		}
+	}
+	return string(data[pos:]), nil
+}
+
+// parseQueryAttributes parses the query attributes that precede the query
+// of a COM_QUERY packet.
+func (c *Conn) parseQueryAttributes(data []byte, pos int) (map[string]*querypb.BindVariable, int, error) {
+	count, pos, ok := readLenEncInt(data, pos)
+	if !ok || count > uint64(len(data)) {
+		return nil, 0, sqlerror.NewSQLError(sqlerror.CRMalformedPacket, sqlerror.SSUnknownSQLState, "reading query attribute count failed")
+	}
+	// The parameter set count is always 1.
+	_, pos, ok = readLenEncInt(data, pos)
+	if !ok {
+		return nil, 0, sqlerror.NewSQLError(sqlerror.CRMalformedPacket, sqlerror.SSUnknownSQLState, "reading query attribute set count failed")
+	}
+	if count == 0 {
+		return nil, pos, nil
+	}
+
+	bitMap, pos, ok := readBytes(data, pos, (int(count)+7)/8)
+	if !ok {
+		return nil, 0, sqlerror.NewSQLError(sqlerror.CRMalformedPacket, sqlerror.SSUnknownSQLState, "reading NULL-bitmap failed")
+	}
+	newParamsBoundFlag, pos, ok := readByte(data, pos)
+	if !ok || newParamsBoundFlag != 0x01 {
+		return nil, 0, sqlerror.NewSQLError(sqlerror.CRMalformedPacket, sqlerror.SSUnknownSQLState, "reading query attribute types failed")
+	}
+	types := make([]querypb.Type, count)
+	names := make([]string, count)
+	for i := range types {
+		var err error
+		types[i], pos, err = c.parseParamType(data, pos)
+		if err != nil {
+			return nil, 0, err
+		}
+		names[i], pos, ok = readLenEncString(data, pos)
+		if !ok {
+			return nil, 0, sqlerror.NewSQLError(sqlerror.CRMalformedPacket, sqlerror.SSUnknownSQLState, "reading query attribute name failed")
+		}
+	}
+
+	attributes := make(map[string]*querypb.BindVariable, count)
+	for i, typ := range types {
+		val := sqltypes.NULL
+		if bitMap[i/8]&(1<<uint(i%8)) == 0 {
+			val, pos, ok = c.parseStmtArgs(data, typ, pos)
+			if !ok {
+				return nil, 0, sqlerror.NewSQLErrorf(sqlerror.CRMalformedPacket, sqlerror.SSUnknownSQLState, "decoding query attribute value failed: %v", typ)
+			}
+		}
+		attributes[names[i]] = sqltypes.ValueBindVariable(val)
+	}
+	return attributes, pos, nil
+}
+
+// parseParamType parses the type of a parameter, or of a query attribute.
+func (c *Conn) parseParamType(data []byte, pos int) (querypb.Type, int, error) {
+	mysqlType, pos, ok := readByte(data, pos)
+	if !ok {
+		return 0, 0, sqlerror.NewSQLError(sqlerror.CRMalformedPacket, sqlerror.SSUnknownSQLState, "reading parameter type failed")
+	}
+
+	flags, pos, ok := readByte(data, pos)
+	if !ok {
+		return 0, 0, sqlerror.NewSQLError(sqlerror.CRMalformedPacket, sqlerror.SSUnknownSQLState, "reading parameter flags failed")
+	}
+
+	// convert MySQL type to internal type.
+	valType, err := sqltypes.MySQLToType(mysqlType, int64(flags))
+	if err != nil {
+		return 0, 0, sqlerror.NewSQLErrorf(sqlerror.CRMalformedPacket, sqlerror.SSUnknownSQLState, "MySQLToType(%v,%v) failed: %v", mysqlType, flags, err)
+	}
+	return valType, pos, nil
 }
 
 func (c *Conn) parseComSetOption(data []byte) (uint16, bool) {
@@ -594,6 +677,7 @@ func (c *Conn) parseComPrepare(data []byte) string {
 }
 
 func (c *Conn) parseComStmtExecute(prepareData map[uint32]*PrepareData, data []byte) (uint32, byte, error) {
+	c.QueryAttributes = nil
 	pos := 0
 	payload := data[1:]
 	bitMap := make([]byte, 0)
@@ -623,35 +707,55 @@ func (c *Conn) parseComStmtExecute(prepareData map[uint32]*PrepareData, data []b
 		return stmtID, 0, sqlerror.NewSQLError(sqlerror.CRMalformedPacket, sqlerror.SSUnknownSQLState, "iteration count is not equal to 1")
 	}
 
-	if prepare.ParamsCount > 0 {
-		bitMap, pos, ok = readBytes(payload, pos, (int(prepare.ParamsCount)+7)/8)
+	// With query attributes, the parameters of the statement are
+	// followed by the attributes.
+	queryAttributes := c.Capabilities&CapabilityClientQueryAttributes != 0
+	paramsCount := int(prepare.ParamsCount)
+	if queryAttributes && (prepare.ParamsCount > 0 || cursorType&ParameterCountAvailable != 0) {
+		var count uint64
+		count, pos, ok = readLenEncInt(payload, pos)
+		if !ok || count < uint64(prepare.ParamsCount) || count > uint64(len(payload)) {
+			return stmtID, 0, sqlerror.NewSQLError(sqlerror.CRMalformedPacket, sqlerror.SSUnknownSQLState, "reading parameter count failed")
+		}
+		paramsCount = int(count)
+	}
+
+	if paramsCount > 0 {
+		bitMap, pos, ok = readBytes(payload, pos, (paramsCount+7)/8)
 		if !ok {
 			return stmtID, 0, sqlerror.NewSQLError(sqlerror.CRMalformedPacket, sqlerror.SSUnknownSQLState, "reading NULL-bitmap failed")
 		}
 	}
 
+	var attributeTypes []querypb.Type
+	var attributeNames []string
 	newParamsBoundFlag, pos, ok := readByte(payload, pos)
 	if ok && newParamsBoundFlag == 0x01 {
-		var mysqlType, flags byte
-		for i := range prepare.ParamsCount {
-			mysqlType, pos, ok = readByte(payload, pos)
-			if !ok {
-				return stmtID, 0, sqlerror.NewSQLError(sqlerror.CRMalformedPacket, sqlerror.SSUnknownSQLState, "reading parameter type failed")
+		for i := range paramsCount {
+			var valType querypb.Type
+			var err error
+			valType, pos, err = c.parseParamType(payload, pos)
+			if err != nil {
+				return stmtID, 0, err
 			}
 
-			flags, pos, ok = readByte(payload, pos)
-			if !ok {
-				return stmtID, 0, sqlerror.NewSQLError(sqlerror.CRMalformedPacket, sqlerror.SSUnknownSQLState, "reading parameter flags failed")
+			var name string
+			if queryAttributes {
+				name, pos, ok = readLenEncString(payload, pos)
+				if !ok {
+					return stmtID, 0, sqlerror.NewSQLError(sqlerror.CRMalformedPacket, sqlerror.SSUnknownSQLState, "reading parameter name failed")
+				}
 			}
 
-			// convert MySQL type to internal type.
-			valType, err := sqltypes.MySQLToType(mysqlType, int64(flags))
-			if err != nil {
-				return stmtID, 0, sqlerror.NewSQLErrorf(sqlerror.CRMalformedPacket, sqlerror.SSUnknownSQLState, "MySQLToType(%v,%v) failed: %v", mysqlType, flags, err)
+			if i < int(prepare.ParamsCount) {
+				prepare.ParamsType[i] = int32(valType)
+			} else {
+				attributeTypes = append(attributeTypes, valType)
+				attributeNames = append(attributeNames, name)
 			}
-
-			prepare.ParamsType[i] = int32(valType)
 		}
+	} else if paramsCount > int(prepare.ParamsCount) {
+		return stmtID, 0, sqlerror.NewSQLError(sqlerror.CRMalformedPacket, sqlerror.SSUnknownSQLState, "reading query attribute types failed")
 	}
 
 	for i := range prepare.ParamsCount {
@@ -675,6 +779,21 @@ func (c *Conn) parseComStmtExecute(prepareData map[uint32]*PrepareData, data []b
 		prepare.BindVars[parameterID] = sqltypes.ValueBindVariable(val)
 	}
 
+	for i, typ := range attributeTypes {
+		j := int(prepare.ParamsCount) + i
+		val := sqltypes.NULL
+		if bitMap[j/8]&(1<<uint(j%8)) == 0 {
+			val, pos, ok = c.parseStmtArgs(payload, typ, pos)
+			if !ok {
+				return stmtID, 0, sqlerror.NewSQLErrorf(sqlerror.CRMalformedPacket, sqlerror.SSUnknownSQLState, "decoding query attribute value failed: %v", typ)
+			}
+		}
+		if c.QueryAttributes == nil {
+			c.QueryAttributes = make(map[string]*querypb.BindVariable, len(attributeTypes))
+		}
+		c.QueryAttributes[attributeNames[i]] = sqltypes.ValueBindVariable(val)
+	}
+
 	return stmtID, cursorType, nil
 }
 
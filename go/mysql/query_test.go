@@ -324,6 +324,81 @@ func TestComStmtExecuteUpdStmt(t *testing.T) {
 	assert.EqualValues(t, querypb.Type_CHAR, prepData.ParamsType[28], "got: %s", querypb.Type(prepData.ParamsType[28]))
 }
 
+func TestComQueryAttributes(t *testing.T) {
+	c := &Conn{Capabilities: CapabilityClientQueryAttributes}
+
+	// Two attributes, the second one NULL.
+	data := []byte{ComQuery, 2, 1, 0x02, 0x01}
+	data = append(data, 0xfe, 0x00, 13)
+	data = append(data, "workload_name"...)
+	data = append(data, 0x08, 0x00, 1, 'n')
+	data = append(data, 5)
+	data = append(data, "batch"...)
+	data = append(data, "select 1"...)
+	query, err := c.parseComQuery(data)
+	require.NoError(t, err)
+	assert.Equal(t, "select 1", query)
+	assert.Equal(t, map[string]*querypb.BindVariable{
+		"workload_name": sqltypes.ValueBindVariable(sqltypes.MakeTrusted(querypb.Type_CHAR, []byte("batch"))),
+		"n":             sqltypes.NullBindVariable,
+	}, c.QueryAttributes)
+
+	// No attributes.
+	query, err = c.parseComQuery(append([]byte{ComQuery, 0, 1}, "select 2"...))
+	require.NoError(t, err)
+	assert.Equal(t, "select 2", query)
+	assert.Nil(t, c.QueryAttributes)
+
+	// The attributes must have their types.
+	_, err = c.parseComQuery(append([]byte{ComQuery, 1, 1, 0x00, 0x00}, "select 3"...))
+	assert.ErrorContains(t, err, "reading query attribute types failed")
+
+	// Without the capability, the query is the rest of the packet.
+	c.Capabilities = 0
+	query, err = c.parseComQuery(append([]byte{ComQuery}, "select 4"...))
+	require.NoError(t, err)
+	assert.Equal(t, "select 4", query)
+	assert.Nil(t, c.QueryAttributes)
+}
+
+func TestComStmtExecuteQueryAttributes(t *testing.T) {
+	c := &Conn{Capabilities: CapabilityClientQueryAttributes}
+	prepareData := map[uint32]*PrepareData{
+		1: {
+			StatementID: 1,
+			ParamsCount: 1,
+			ParamsType:  make([]int32, 1),
+			BindVars:    map[string]*querypb.BindVariable{},
+		},
+		2: {
+			StatementID: 2,
+			BindVars:    map[string]*querypb.BindVariable{},
+		},
+	}
+
+	// The parameter of the statement is followed by an attribute.
+	data := []byte{ComStmtExecute, 1, 0, 0, 0, ParameterCountAvailable, 1, 0, 0, 0, 2, 0x00, 0x01}
+	data = append(data, 0x08, 0x00, 0)
+	data = append(data, 0xfe, 0x00, 8)
+	data = append(data, "priority"...)
+	data = append(data, 42, 0, 0, 0, 0, 0, 0, 0)
+	data = append(data, 2, '1', '0')
+	stmtID, _, err := c.parseComStmtExecute(prepareData, data)
+	require.NoError(t, err)
+	require.EqualValues(t, 1, stmtID)
+	assert.Equal(t, sqltypes.Int64BindVariable(42), prepareData[1].BindVars["v1"])
+	assert.Equal(t, map[string]*querypb.BindVariable{
+		"priority": sqltypes.ValueBindVariable(sqltypes.MakeTrusted(querypb.Type_CHAR, []byte("10"))),
+	}, c.QueryAttributes)
+
+	// A statement without parameters only sends the parameter count
+	// when there are attributes.
+	data = []byte{ComStmtExecute, 2, 0, 0, 0, 0, 1, 0, 0, 0}
+	_, _, err = c.parseComStmtExecute(prepareData, data)
+	require.NoError(t, err)
+	assert.Nil(t, c.QueryAttributes)
+}
+
 func TestComStmtClose(t *testing.T) {
 	listener, sConn, cConn := createSocketPair(t)
 	defer func() {
@@ -136,6 +136,12 @@ type Handler interface {
 
 	ComResetConnection(c *Conn)
 
+	// ComChangeUser is called when a connection was authenticated as
+	// another user with COM_CHANGE_USER, to reset the state of its
+	// session as ComResetConnection does. The default database of the
+	// connection is then set with ComQuery.
+	ComChangeUser(c *Conn)
+
 	Env() *vtenv.Environment
 }
 
@@ -149,6 +155,7 @@ func (UnimplementedHandler) NewConnection(*Conn)      {}
 func (UnimplementedHandler) ConnectionReady(*Conn)    {}
 func (UnimplementedHandler) ConnectionClosed(*Conn)   {}
 func (UnimplementedHandler) ComResetConnection(*Conn) {}
+func (UnimplementedHandler) ComChangeUser(*Conn)      {}
 
 // Listener is the MySQL server protocol listener.
 type Listener struct {
@@ -185,6 +192,32 @@ type Listener struct {
 	// beyond which a warning is logged to identify the slow connection
 	SlowConnectWarnThreshold atomic.Int64
 
+	// CompressionAlgorithms are the compression algorithms of the
+	// protocol that clients can negotiate, CompressionZlib and
+	// CompressionZstd. If empty, the packets are never compressed.
+	CompressionAlgorithms []string
+
+	// CompressionLevel is the level of the zlib compression of the
+	// packets sent to the clients, 0 for the default. With zstd, the
+	// level requested by the client is used, as MySQL does.
+	CompressionLevel int
+
+	// MaxOpenCursors is the number of read-only cursors a connection
+	// can have open at once, to fetch the rows of its prepared statements
+	// with COM_STMT_FETCH. If 0, the cursor type of COM_STMT_EXECUTE is
+	// ignored, and the rows are sent with its response.
+	MaxOpenCursors int
+
+	// CursorPool limits the number of cursors open at once on the
+	// connections of all the listeners that share it. If nil, only the
+	// cursors of each connection are limited.
+	CursorPool *CursorPool
+
+	// CursorIdleTimeout is how long the rows of a cursor can go without
+	// being fetched, after which the cursor is stopped. If 0, the cursors
+	// stay open until their statement is closed or executed again.
+	CursorIdleTimeout time.Duration
+
 	// The following parameters are changed by the Accept routine.
 
 	// Incrementing ID for connection id.
@@ -390,11 +423,14 @@ func (l *Listener) handle(conn net.Conn, connectionID uint32, acceptTime time.Ti
 	l.handler.NewConnection(c)
 	defer l.handler.ConnectionClosed(c)
 
+	// The cursors are closed before the handler is told about it.
+	defer c.closeCursors()
+
 	// Adjust the count of open connections
 	defer connCount.Add(-1)
 
 	// First build and send the server handshake packet.
-	serverAuthPluginData, err := c.writeHandshakeV10(l.ServerVersion, l.authServer, uint8(l.charset), l.TLSConfig.Load() != nil)
+	serverAuthPluginData, err := c.writeHandshakeV10(l.ServerVersion, l.authServer, uint8(l.charset), l.TLSConfig.Load() != nil, l.compressionCapabilities())
 	if err != nil {
 		if err != io.EOF {
 			log.Errorf("Cannot send HandshakeV10 packet to %s: %v", c, err)
@@ -453,6 +489,78 @@ func (l *Listener) handle(conn net.Conn, connectionID uint32, acceptTime time.Ti
 		defer connCountByTLSVer.Add(versionNoTLS, -1)
 	}
 
+	userData, ok := l.authenticate(c, user, clientAuthMethod, clientAuthResponse, serverAuthPluginData)
+	if !ok {
+		return
+	}
+
+	c.User = user
+	c.UserData = userData
+
+	// The user can be changed by COM_CHANGE_USER, which moves the
+	// connection to the count of the new user.
+	if c.User != "" {
+		connCountPerUser.Add(c.User, 1)
+	}
+	defer func() {
+		if c.User != "" {
+			connCountPerUser.Add(c.User, -1)
+		}
+	}()
+
+	// Set initial db name.
+	if c.schemaName != "" {
+		err = l.handler.ComQuery(c, "use "+sqlescape.EscapeID(c.schemaName), func(result *sqltypes.Result) error {
+			return nil
+		})
+		if err != nil {
+			c.writeErrorPacketFromError(err)
+			return
+		}
+	}
+
+	// Negotiation worked, send OK packet.
+	if err := c.writeOKPacket(&PacketOK{statusFlags: c.StatusFlags}); err != nil {
+		log.Errorf("Cannot write OK packet to %s: %v", c, err)
+		return
+	}
+
+	// The packets that follow are compressed, if it was negotiated.
+	if c.Capabilities&CapabilityClientZstdCompressionAlgorithm != 0 {
+		c.enableCompression(CompressionZstd, c.zstdCompressionLevel)
+	} else if c.Capabilities&CapabilityClientCompress != 0 {
+		c.enableCompression(CompressionZlib, l.CompressionLevel)
+	}
+
+	// Record how long we took to establish the connection
+	timings.Record(connectTimingKey, acceptTime)
+
+	// Log a warning if it took too long to connect
+	connectTime := time.Since(acceptTime).Nanoseconds()
+	if threshold := l.SlowConnectWarnThreshold.Load(); threshold != 0 && connectTime > threshold {
+		connSlow.Add(1)
+		log.Warningf("Slow connection from %s: %v", c, connectTime)
+	}
+
+	// Tell our handler that we're finished handshake and are ready to
+	// process commands.
+	l.handler.ConnectionReady(c)
+
+	for {
+		kontinue := c.handleNextCommand(l.handler)
+		// before going for next command check if the connection should be closed or not.
+		if !kontinue || c.IsMarkedForClose() {
+			return
+		}
+	}
+}
+
+// authenticate negotiates the auth method of the user with the client, and
+// checks the credentials the client sent for it. If the client sent no
+// credentials, or not for a method the user can use, it asks for them with an
+// AuthSwitchRequest. The errors are sent to the client, and the connection
+// should be closed if it fails.
+func (l *Listener) authenticate(c *Conn, user string, clientAuthMethod AuthMethodDescription, clientAuthResponse, serverAuthPluginData []byte) (Getter, bool) {
 	// See what auth method the AuthServer wants to use for that user.
 	negotiatedAuthMethod, err := negotiateAuthMethod(c, l.authServer, user, clientAuthMethod)
 
@@ -478,86 +586,40 @@ func (l *Listener) handle(conn net.Conn, connectionID uint32, acceptTime time.Ti
 
 		if negotiatedAuthMethod == nil {
 			c.writeErrorPacket(sqlerror.CRServerHandshakeErr, sqlerror.SSUnknownSQLState, "No authentication methods available for authentication.")
-			return
+			return nil, false
 		}
 
 		if !l.AllowClearTextWithoutTLS.Load() && !c.TLSEnabled() && !negotiatedAuthMethod.AllowClearTextWithoutTLS() {
 			c.writeErrorPacket(sqlerror.CRServerHandshakeErr, sqlerror.SSUnknownSQLState, "Cannot use clear text authentication over non-SSL connections.")
-			return
+			return nil, false
 		}
 
 		serverAuthPluginData, err = negotiatedAuthMethod.AuthPluginData()
 		if err != nil {
 			log.Errorf("Error generating auth switch packet for %s: %v", c, err)
-			return
+			return nil, false
 		}
 
 		if err := c.writeAuthSwitchRequest(string(negotiatedAuthMethod.Name()), serverAuthPluginData); err != nil {
 			log.Errorf("Error writing auth switch packet for %s: %v", c, err)
-			return
+			return nil, false
 		}
 
 		clientAuthResponse, err = c.readEphemeralPacket()
 		if err != nil {
 			log.Errorf("Error reading auth switch response for %s: %v", c, err)
-			return
+			return nil, false
 		}
 		c.recycleReadPacket()
 	}
 
-	userData, err := negotiatedAuthMethod.HandleAuthPluginData(c, user, serverAuthPluginData, clientAuthResponse, conn.RemoteAddr())
+	userData, err := negotiatedAuthMethod.HandleAuthPluginData(c, user, serverAuthPluginData, clientAuthResponse, c.RemoteAddr())
 	if err != nil {
 		log.Warningf("Error authenticating user %s using: %s", user, negotiatedAuthMethod.Name())
 		c.writeErrorPacketFromError(err)
-		return
-	}
-
-	c.User = user
-	c.UserData = userData
-
-	if c.User != "" {
-		connCountPerUser.Add(c.User, 1)
-		defer connCountPerUser.Add(c.User, -1)
-	}
-
-	// Set initial db name.
-	if c.schemaName != "" {
-		err = l.handler.ComQuery(c, "use "+sqlescape.EscapeID(c.schemaName), func(result *sqltypes.Result) error {
-			return nil
-		})
-		if err != nil {
-			c.writeErrorPacketFromError(err)
-			return
-		}
-	}
-
-	// Negotiation worked, send OK packet.
-	if err := c.writeOKPacket(&PacketOK{statusFlags: c.StatusFlags}); err != nil {
-		log.Errorf("Cannot write OK packet to %s: %v", c, err)
-		return
-	}
-
-	// Record how long we took to establish the connection
-	timings.Record(connectTimingKey, acceptTime)
-
-	// Log a warning if it took too long to connect
-	connectTime := time.Since(acceptTime).Nanoseconds()
-	if threshold := l.SlowConnectWarnThreshold.Load(); threshold != 0 && connectTime > threshold {
-		connSlow.Add(1)
-		log.Warningf("Slow connection from %s: %v", c, connectTime)
-	}
-
-	// Tell our handler that we're finished handshake and are ready to
-	// process commands.
-	l.handler.ConnectionReady(c)
-
-	for {
-		kontinue := c.handleNextCommand(l.handler)
-		// before going for next command check if the connection should be closed or not.
-		if !kontinue || c.IsMarkedForClose() {
-			return
-		}
+		return nil, false
 	}
+	return userData, true
 }
 
 // Close stops the listener, which prevents accept of any new connections. Existing connections won't be closed.
@@ -573,9 +635,19 @@ func (l *Listener) Shutdown() {
 	}
 }
 
+// compressionCapabilities returns the capability flags of the
+// compression algorithms that clients can negotiate.
+func (l *Listener) compressionCapabilities() uint32 {
+	var capabilities uint32
+	for _, algorithm := range l.CompressionAlgorithms {
+		capabilities |= compressionCapability(algorithm)
+	}
+	return capabilities
+}
+
 // writeHandshakeV10 writes the Initial Handshake Packet, server side.
 // It returns the salt data.
-func (c *Conn) writeHandshakeV10(serverVersion string, authServer AuthServer, charset uint8, enableTLS bool) ([]byte, error) {
+func (c *Conn) writeHandshakeV10(serverVersion string, authServer AuthServer, charset uint8, enableTLS bool, compressionCapabilities uint32) ([]byte, error) {
 	capabilities := CapabilityClientLongPassword |
 		CapabilityClientFoundRows |
 		CapabilityClientLongFlag |
@@ -588,10 +660,12 @@ func (c *Conn) writeHandshakeV10(serverVersion string, authServer AuthServer, ch
 		CapabilityClientPluginAuth |
 		CapabilityClientPluginAuthLenencClientData |
 		CapabilityClientDeprecateEOF |
-		CapabilityClientConnAttr
+		CapabilityClientConnAttr |
+		CapabilityClientQueryAttributes
 	if enableTLS {
 		capabilities |= CapabilityClientSSL
 	}
+	capabilities |= int(compressionCapabilities)
 
 	// Grab the default auth method. This can only be either
 	// mysql_native_password or caching_sha2_password. Both
@@ -709,7 +783,11 @@ func (l *Listener) parseClientHandshakePacket(c *Conn, firstTime bool, data []by
 	// later in the protocol. If we re-received the handshake packet
 	// after SSL negotiation, do not overwrite capabilities.
 	if firstTime {
-		c.Capabilities = clientFlags & (CapabilityClientDeprecateEOF | CapabilityClientFoundRows)
+		c.Capabilities = clientFlags & (CapabilityClientDeprecateEOF | CapabilityClientFoundRows |
+			// The format of COM_CHANGE_USER depends on these.
+			CapabilityClientSecureConnection | CapabilityClientPluginAuth | CapabilityClientConnAttr |
+			// The format of COM_QUERY and COM_STMT_EXECUTE depends on this.
+			CapabilityClientQueryAttributes)
 	}
 
 	// set connection capability for executing multi statements
@@ -809,11 +887,30 @@ func (l *Listener) parseClientHandshakePacket(c *Conn, firstTime bool, data []by
 
 	// Decode connection attributes send by the client
 	if clientFlags&CapabilityClientConnAttr != 0 {
-		if _, _, err := parseConnAttrs(data, pos); err != nil {
+		var err error
+		if _, pos, err = parseConnAttrs(data, pos); err != nil {
 			log.Warningf("Decode connection attributes send by the client: %v", err)
 		}
 	}
 
+	// Negotiate the compression of the protocol, preferring zstd if
+	// the client asks for both.
+	c.Capabilities &^= CapabilityClientCompress | CapabilityClientZstdCompressionAlgorithm
+	compression := clientFlags & l.compressionCapabilities()
+	if compression&CapabilityClientZstdCompressionAlgorithm != 0 {
+		c.Capabilities |= CapabilityClientZstdCompressionAlgorithm
+		// The zstd level follows the connection attributes. The
+		// default is used if it can't be read or is invalid.
+		c.zstdCompressionLevel = 0
+		if pos > 0 {
+			if level, _, ok := readByte(data, pos); ok && level <= 22 {
+				c.zstdCompressionLevel = int(level)
+			}
+		}
+	} else if compression&CapabilityClientCompress != 0 {
+		c.Capabilities |= CapabilityClientCompress
+	}
+
 	return username, AuthMethodDescription(authMethod), authResponse, nil
 }
 
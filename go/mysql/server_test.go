@@ -1245,6 +1245,7 @@ func TestErrorCodes(t *testing.T) {
 
 	client, err := Connect(ctx, params)
 	require.NoError(t, err)
+	defer client.Close()
 
 	// Test that the right mysql errno/sqlstate are returned for various
 	// internal vitess errors
@@ -1427,6 +1428,7 @@ func TestListenerShutdown(t *testing.T) {
 
 	conn, err := Connect(ctx, params)
 	require.NoError(t, err)
+	defer conn.Close()
 
 	err = conn.Ping()
 	require.NoError(t, err)
@@ -62,6 +62,7 @@ func RegisterFlags(fs *pflag.FlagSet) {
 	utils.SetFlagStringVar(fs, &combineDimensions, "stats-combine-dimensions", combineDimensions, `List of dimensions to be combined into a single "all" value in exported stats vars`)
 	utils.SetFlagStringVar(fs, &dropVariables, "stats-drop-variables", dropVariables, `Variables to be dropped from the list of exported variables.`)
 	utils.SetFlagStringSliceVar(fs, &CommonTags, "stats-common-tags", CommonTags, `Comma-separated list of common tags for the stats backend. It provides both label and values. Example: label1:value1,label2:value2`)
+	utils.SetFlagVar(fs, timingsBuckets{}, "stats-timings-buckets", "Comma-separated list of the upper bounds of the buckets of the timings histograms. Example: 1ms,2ms,4ms,8ms")
 }
 
 // StatsAllStr is the consolidated name if a dimension gets combined.
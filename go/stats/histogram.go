@@ -20,6 +20,7 @@ import (
 	"bytes"
 	"fmt"
 	"sync/atomic"
+	"time"
 )
 
 // Histogram tracks counts and totals while
@@ -34,8 +35,17 @@ type Histogram struct {
 	totalLabel string
 	hook       func(int64)
 
-	buckets []atomic.Int64
-	total   atomic.Int64
+	buckets   []atomic.Int64
+	exemplars []atomic.Pointer[Exemplar]
+	total     atomic.Int64
+}
+
+// Exemplar is a measurement added to a Histogram,
+// along with the ID of the trace that measured it.
+type Exemplar struct {
+	Value     int64
+	TraceID   string
+	Timestamp time.Time
 }
 
 // NewHistogram creates a histogram with auto-generated labels
@@ -67,6 +77,7 @@ func NewGenericHistogram(name, help string, cutoffs []int64, labels []string, co
 		countLabel: countLabel,
 		totalLabel: totalLabel,
 		buckets:    make([]atomic.Int64, len(labels)),
+		exemplars:  make([]atomic.Pointer[Exemplar], len(labels)),
 	}
 	if name != "" {
 		publish(name, h)
@@ -81,19 +92,34 @@ func (h *Histogram) AddHook(hook func(int64)) {
 
 // Add adds a new measurement to the Histogram.
 func (h *Histogram) Add(value int64) {
-	for i := range h.labels {
-		if i == len(h.labels)-1 || value <= h.cutoffs[i] {
-			h.buckets[i].Add(1)
-			h.total.Add(value)
+	h.add(value)
+}
+
+// AddWithExemplar adds a new measurement to the Histogram, and keeps
+// it as the exemplar of its bucket along with the given trace ID.
+func (h *Histogram) AddWithExemplar(value int64, traceID string) {
+	i := h.add(value)
+	h.exemplars[i].Store(&Exemplar{Value: value, TraceID: traceID, Timestamp: time.Now()})
+}
+
+// add adds a new measurement to the Histogram and returns the index of its bucket.
+func (h *Histogram) add(value int64) int {
+	i := len(h.cutoffs)
+	for j, cutoff := range h.cutoffs {
+		if value <= cutoff {
+			i = j
 			break
 		}
 	}
+	h.buckets[i].Add(1)
+	h.total.Add(value)
 	if h.hook != nil {
 		h.hook(value)
 	}
 	if defaultStatsdHook.histogramHook != nil && h.name != "" {
 		defaultStatsdHook.histogramHook(h.name, value)
 	}
+	return i
 }
 
 // String returns a string representation of the Histogram.
@@ -173,6 +199,16 @@ func (h *Histogram) Buckets() []int64 {
 	return buckets
 }
 
+// Exemplars returns the latest exemplar of each bucket, which
+// is nil for the buckets that have none.
+func (h *Histogram) Exemplars() []*Exemplar {
+	exemplars := make([]*Exemplar, len(h.exemplars))
+	for i := range h.exemplars {
+		exemplars[i] = h.exemplars[i].Load()
+	}
+	return exemplars
+}
+
 // Help returns the help string.
 func (h *Histogram) Help() string {
 	return h.help
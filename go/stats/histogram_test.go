@@ -53,6 +53,28 @@ func TestHistogram(t *testing.T) {
 	assert.Equal(t, h.Help(), "help")
 }
 
+func TestHistogramExemplars(t *testing.T) {
+	clearStats()
+	h := NewHistogram("", "help", []int64{1, 5})
+	h.Add(0)
+	h.AddWithExemplar(3, "trace1")
+	h.AddWithExemplar(4, "trace2")
+	h.AddWithExemplar(10, "trace3")
+
+	assert.Equal(t, []int64{1, 2, 1}, h.Buckets())
+	assert.EqualValues(t, 17, h.Total())
+
+	// Each bucket keeps its latest exemplar.
+	exemplars := h.Exemplars()
+	assert.Len(t, exemplars, 3)
+	assert.Nil(t, exemplars[0])
+	assert.Equal(t, int64(4), exemplars[1].Value)
+	assert.Equal(t, "trace2", exemplars[1].TraceID)
+	assert.False(t, exemplars[1].Timestamp.IsZero())
+	assert.Equal(t, int64(10), exemplars[2].Value)
+	assert.Equal(t, "trace3", exemplars[2].TraceID)
+}
+
 func TestGenericHistogram(t *testing.T) {
 	clearStats()
 	h := NewGenericHistogram(
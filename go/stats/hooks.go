@@ -16,6 +16,8 @@ limitations under the License.
 
 package stats
 
+import "context"
+
 type statsdHook struct {
 	timerHook     func(string, string, int64, *Timings)
 	histogramHook func(string, int64)
@@ -32,3 +34,11 @@ func RegisterTimerHook(hook func(string, string, int64, *Timings)) {
 func RegisterHistogramHook(hook func(string, int64)) {
 	defaultStatsdHook.histogramHook = hook
 }
+
+var traceIDHook func(context.Context) (string, bool)
+
+// RegisterTraceIDHook registers the hook that returns the ID of the trace
+// of a context, which Timings keep with the exemplars of their histograms.
+func RegisterTraceIDHook(hook func(context.Context) (string, bool)) {
+	traceIDHook = hook
+}
@@ -249,20 +249,13 @@ func (c *metricsFuncWithMultiLabelsCollector) Collect(ch chan<- prometheus.Metri
 }
 
 type timingsCollector struct {
-	t       *stats.Timings
-	cutoffs []float64
-	desc    *prometheus.Desc
+	t    *stats.Timings
+	desc *prometheus.Desc
 }
 
 func newTimingsCollector(t *stats.Timings, name string) {
-	cutoffs := make([]float64, len(t.Cutoffs()))
-	for i, val := range t.Cutoffs() {
-		cutoffs[i] = float64(val) / 1000000000
-	}
-
 	collector := &timingsCollector{
-		t:       t,
-		cutoffs: cutoffs,
+		t: t,
 		desc: prometheus.NewDesc(
 			name,
 			t.Help(),
@@ -281,11 +274,7 @@ func (c *timingsCollector) Describe(ch chan<- *prometheus.Desc) {
 // Collect implements Collector.
 func (c *timingsCollector) Collect(ch chan<- prometheus.Metric) {
 	for cat, his := range c.t.Histograms() {
-		metric, err := prometheus.NewConstHistogram(c.desc,
-			uint64(his.Count()),
-			float64(his.Total())/1000000000,
-			makeCumulativeBuckets(c.cutoffs,
-				his.Buckets()), cat)
+		metric, err := newTimingsHistogram(c.desc, his, cat)
 		if err != nil {
 			log.Errorf("Error adding metric: %s", c.desc)
 		} else {
@@ -294,6 +283,38 @@ func (c *timingsCollector) Collect(ch chan<- prometheus.Metric) {
 	}
 }
 
+// newTimingsHistogram returns the metric of a histogram of Timings, in seconds,
+// with the exemplars of its buckets.
+func newTimingsHistogram(desc *prometheus.Desc, his *stats.Histogram, labelValues ...string) (prometheus.Metric, error) {
+	cutoffs := make([]float64, len(his.Cutoffs()))
+	for i, val := range his.Cutoffs() {
+		cutoffs[i] = float64(val) / 1000000000
+	}
+	metric, err := prometheus.NewConstHistogram(desc,
+		uint64(his.Count()),
+		float64(his.Total())/1000000000,
+		makeCumulativeBuckets(cutoffs, his.Buckets()),
+		labelValues...)
+	if err != nil {
+		return nil, err
+	}
+
+	var exemplars []prometheus.Exemplar
+	for _, ex := range his.Exemplars() {
+		if ex != nil {
+			exemplars = append(exemplars, prometheus.Exemplar{
+				Value:     float64(ex.Value) / 1000000000,
+				Labels:    prometheus.Labels{"trace_id": ex.TraceID},
+				Timestamp: ex.Timestamp,
+			})
+		}
+	}
+	if len(exemplars) == 0 {
+		return metric, nil
+	}
+	return prometheus.NewMetricWithExemplars(metric, exemplars...)
+}
+
 func makeCumulativeBuckets(cutoffs []float64, buckets []int64) map[float64]uint64 {
 	output := make(map[float64]uint64)
 	last := uint64(0)
@@ -306,20 +327,13 @@ func makeCumulativeBuckets(cutoffs []float64, buckets []int64) map[float64]uint6
 }
 
 type multiTimingsCollector struct {
-	mt      *stats.MultiTimings
-	cutoffs []float64
-	desc    *prometheus.Desc
+	mt   *stats.MultiTimings
+	desc *prometheus.Desc
 }
 
 func newMultiTimingsCollector(mt *stats.MultiTimings, name string) {
-	cutoffs := make([]float64, len(mt.Cutoffs()))
-	for i, val := range mt.Cutoffs() {
-		cutoffs[i] = float64(val) / 1000000000
-	}
-
 	collector := &multiTimingsCollector{
-		mt:      mt,
-		cutoffs: cutoffs,
+		mt: mt,
 		desc: prometheus.NewDesc(
 			name,
 			mt.Help(),
@@ -339,12 +353,7 @@ func (c *multiTimingsCollector) Describe(ch chan<- *prometheus.Desc) {
 func (c *multiTimingsCollector) Collect(ch chan<- prometheus.Metric) {
 	for cat, his := range c.mt.Timings.Histograms() {
 		labelValues := strings.Split(cat, ".")
-		metric, err := prometheus.NewConstHistogram(
-			c.desc,
-			uint64(his.Count()),
-			float64(his.Total())/1000000000,
-			makeCumulativeBuckets(c.cutoffs, his.Buckets()),
-			labelValues...)
+		metric, err := newTimingsHistogram(c.desc, his, labelValues...)
 		if err != nil {
 			log.Errorf("Error adding metric: %s", c.desc)
 		} else {
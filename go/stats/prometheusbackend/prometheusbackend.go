@@ -22,8 +22,10 @@ import (
 
 	"github.com/prometheus/client_golang/prometheus"
 	"github.com/prometheus/client_golang/prometheus/promhttp"
+	"github.com/spf13/pflag"
 
 	"vitess.io/vitess/go/stats"
+	"vitess.io/vitess/go/trace"
 	"vitess.io/vitess/go/vt/log"
 	"vitess.io/vitess/go/vt/servenv"
 )
@@ -35,12 +37,29 @@ type PromBackend struct {
 
 var (
 	be PromBackend
+
+	// enableOpenMetrics serves the metrics in the OpenMetrics format to the scrapers
+	// that ask for it. This is required to export the exemplars of the timings, but
+	// it also adds the _total suffix to the names of the counters that lack it.
+	enableOpenMetrics bool
 )
 
+func init() {
+	servenv.OnParse(registerFlags)
+}
+
+func registerFlags(fs *pflag.FlagSet) {
+	fs.BoolVar(&enableOpenMetrics, "prometheus-enable-openmetrics", enableOpenMetrics, "If set, serve the Prometheus metrics in the OpenMetrics format to the scrapers that ask for it, including the exemplars of the timings histograms with the IDs of their traces")
+}
+
 // Init initializes the Prometheus be with the given namespace.
 func Init(namespace string) {
-	servenv.HTTPHandle("/metrics", promhttp.Handler())
+	servenv.HTTPHandle("/metrics", promhttp.InstrumentMetricHandler(
+		prometheus.DefaultRegisterer,
+		promhttp.HandlerFor(prometheus.DefaultGatherer, promhttp.HandlerOpts{EnableOpenMetrics: enableOpenMetrics}),
+	))
 	be.namespace = namespace
+	stats.RegisterTraceIDHook(trace.TraceID)
 	stats.Register(be.publishPrometheusMetric)
 }
 
@@ -17,6 +17,7 @@ limitations under the License.
 package prometheusbackend
 
 import (
+	"context"
 	"fmt"
 	"net/http"
 	"net/http/httptest"
@@ -27,6 +28,7 @@ import (
 
 	"vitess.io/vitess/go/stats"
 
+	"github.com/prometheus/client_golang/prometheus"
 	"github.com/prometheus/client_golang/prometheus/promhttp"
 )
 
@@ -349,6 +351,33 @@ func TestPrometheusMultiTimings_PanicWrongLength(t *testing.T) {
 	c.Add([]string{"label1"}, time.Duration(100000000))
 }
 
+func TestPrometheusTimingsExemplars(t *testing.T) {
+	stats.RegisterTraceIDHook(func(ctx context.Context) (string, bool) {
+		return "0123abcd", true
+	})
+	defer stats.RegisterTraceIDHook(nil)
+
+	name := "blah_exemplar_timings"
+	timing := stats.NewMultiTimings(name, "help", []string{"cat1"})
+	timing.AddContext(context.Background(), []string{"foo"}, 30*time.Millisecond)
+	timing.Add([]string{"foo"}, 200*time.Millisecond)
+
+	// Exemplars are only served in the OpenMetrics format.
+	req, _ := http.NewRequest("GET", "/metrics", nil)
+	req.Header.Set("Accept", "application/openmetrics-text")
+	response := httptest.NewRecorder()
+	promhttp.HandlerFor(prometheus.DefaultGatherer, promhttp.HandlerOpts{EnableOpenMetrics: true}).ServeHTTP(response, req)
+
+	expected := fmt.Sprintf(`%s_%s_bucket{cat1="foo",le="0.05"} 1 # {trace_id="0123abcd"} 0.03 `, namespace, name)
+	if !strings.Contains(response.Body.String(), expected) {
+		t.Fatalf("Expected result to contain %s, got %s", expected, response.Body.String())
+	}
+	expected = fmt.Sprintf(`%s_%s_bucket{cat1="foo",le="0.5"} 2`+"\n", namespace, name)
+	if !strings.Contains(response.Body.String(), expected) {
+		t.Fatalf("Expected result to contain %s, got %s", expected, response.Body.String())
+	}
+}
+
 func TestPrometheusHistogram(t *testing.T) {
 	name := "blah_hist"
 	hist := stats.NewHistogram(name, "help", []int64{1, 5, 10})
@@ -17,8 +17,11 @@ limitations under the License.
 package stats
 
 import (
+	"context"
 	"encoding/json"
 	"fmt"
+	"slices"
+	"strings"
 	"sync"
 	"sync/atomic"
 	"time"
@@ -32,6 +35,10 @@ type Timings struct {
 
 	mu         sync.RWMutex
 	histograms map[string]*Histogram
+	// cutoffs and cutoffLabels are the bucket layout of the histograms,
+	// which is fixed when the first one is created.
+	cutoffs      []int64
+	cutoffLabels []string
 
 	name          string
 	help          string
@@ -52,7 +59,7 @@ func NewTimings(name, help, label string, categories ...string) *Timings {
 		labelCombined: IsDimensionCombined(label),
 	}
 	for _, cat := range categories {
-		t.histograms[cat] = NewGenericHistogram("", "", bucketCutoffs, bucketLabels, "Count", "Time")
+		t.histograms[cat] = t.newHistogram()
 	}
 	if name != "" {
 		publish(name, t)
@@ -72,6 +79,20 @@ func (t *Timings) Reset() {
 
 // Add will add a new value to the named histogram.
 func (t *Timings) Add(name string, elapsed time.Duration) {
+	t.add(name, elapsed, "")
+}
+
+// AddContext will add a new value to the named histogram, and keep it as
+// the exemplar of its bucket if the context belongs to a trace.
+func (t *Timings) AddContext(ctx context.Context, name string, elapsed time.Duration) {
+	var traceID string
+	if traceIDHook != nil {
+		traceID, _ = traceIDHook(ctx)
+	}
+	t.add(name, elapsed, traceID)
+}
+
+func (t *Timings) add(name string, elapsed time.Duration, traceID string) {
 	if t.labelCombined {
 		name = StatsAllStr
 	}
@@ -85,7 +106,7 @@ func (t *Timings) Add(name string, elapsed time.Duration) {
 		t.mu.Lock()
 		hist, ok = t.histograms[name]
 		if !ok {
-			hist = NewGenericHistogram("", "", bucketCutoffs, bucketLabels, "Count", "Time")
+			hist = t.newHistogram()
 			t.histograms[name] = hist
 		}
 		t.mu.Unlock()
@@ -95,11 +116,24 @@ func (t *Timings) Add(name string, elapsed time.Duration) {
 	}
 
 	elapsedNs := int64(elapsed)
-	hist.Add(elapsedNs)
+	if traceID != "" {
+		hist.AddWithExemplar(elapsedNs, traceID)
+	} else {
+		hist.Add(elapsedNs)
+	}
 	t.totalCount.Add(1)
 	t.totalTime.Add(elapsedNs)
 }
 
+// newHistogram creates a histogram with the bucket layout of the Timings.
+// The caller must hold the write lock, unless t is not shared yet.
+func (t *Timings) newHistogram() *Histogram {
+	if t.cutoffs == nil {
+		t.cutoffs, t.cutoffLabels = bucketCutoffs, bucketLabels
+	}
+	return NewGenericHistogram("", "", t.cutoffs, t.cutoffLabels, "Count", "Time")
+}
+
 // Record is a convenience function that records completion
 // timing data based on the provided start time of an event.
 func (t *Timings) Record(name string, startTime time.Time) {
@@ -109,6 +143,12 @@ func (t *Timings) Record(name string, startTime time.Time) {
 	t.Add(name, time.Since(startTime))
 }
 
+// RecordContext is like Record, but also keeps the timing data as an
+// exemplar if the context belongs to a trace.
+func (t *Timings) RecordContext(ctx context.Context, name string, startTime time.Time) {
+	t.AddContext(ctx, name, time.Since(startTime))
+}
+
 // String is for expvar.
 func (t *Timings) String() string {
 	t.mu.RLock()
@@ -168,7 +208,12 @@ func (t *Timings) Counts() map[string]int64 {
 // Cutoffs returns the cutoffs used in the component histograms.
 // Do not change the returned slice.
 func (t *Timings) Cutoffs() []int64 {
-	return bucketCutoffs
+	t.mu.RLock()
+	defer t.mu.RUnlock()
+	if t.cutoffs == nil {
+		return bucketCutoffs
+	}
+	return t.cutoffs
 }
 
 // Help returns the help string.
@@ -186,6 +231,11 @@ var bucketCutoffs = []int64{5e5, 1e6, 5e6, 1e7, 5e7, 1e8, 5e8, 1e9, 5e9, 1e10}
 var bucketLabels []string
 
 func init() {
+	setBucketCutoffs(bucketCutoffs)
+}
+
+func setBucketCutoffs(cutoffs []int64) {
+	bucketCutoffs = cutoffs
 	bucketLabels = make([]string, len(bucketCutoffs)+1)
 	for i, v := range bucketCutoffs {
 		bucketLabels[i] = fmt.Sprintf("%d", v)
@@ -193,6 +243,41 @@ func init() {
 	bucketLabels[len(bucketLabels)-1] = "inf"
 }
 
+// timingsBuckets is the flag value of the bucket layout of the Timings
+// histograms, as a list of the upper bounds of the buckets.
+type timingsBuckets struct{}
+
+// String is part of the pflag.Value interface.
+func (timingsBuckets) String() string {
+	bounds := make([]string, len(bucketCutoffs))
+	for i, cutoff := range bucketCutoffs {
+		bounds[i] = time.Duration(cutoff).String()
+	}
+	return "[" + strings.Join(bounds, ",") + "]"
+}
+
+// Set is part of the pflag.Value interface.
+func (timingsBuckets) Set(value string) error {
+	var cutoffs []int64
+	for bound := range strings.SplitSeq(value, ",") {
+		d, err := time.ParseDuration(strings.TrimSpace(bound))
+		if err != nil {
+			return err
+		}
+		cutoffs = append(cutoffs, int64(d))
+	}
+	if len(cutoffs) == 0 || !slices.IsSorted(cutoffs) || len(slices.Compact(slices.Clone(cutoffs))) != len(cutoffs) {
+		return fmt.Errorf("bucket upper bounds must be increasing: %s", value)
+	}
+	setBucketCutoffs(cutoffs)
+	return nil
+}
+
+// Type is part of the pflag.Value interface.
+func (timingsBuckets) Type() string {
+	return "durationSlice"
+}
+
 // MultiTimings is meant to tracks timing data by categories as well
 // as histograms. The names of the categories are compound names made
 // with joining multiple strings with '.'.
@@ -247,8 +332,20 @@ func (mt *MultiTimings) Record(names []string, startTime time.Time) {
 	mt.Timings.Record(safeJoinLabels(names, mt.combinedLabels), startTime)
 }
 
-// Cutoffs returns the cutoffs used in the component histograms.
-// Do not change the returned slice.
-func (mt *MultiTimings) Cutoffs() []int64 {
-	return bucketCutoffs
+// AddContext will add a new value to the named histogram, and keep it as
+// the exemplar of its bucket if the context belongs to a trace.
+func (mt *MultiTimings) AddContext(ctx context.Context, names []string, elapsed time.Duration) {
+	if len(names) != len(mt.labels) {
+		panic("MultiTimings: wrong number of values in AddContext")
+	}
+	mt.Timings.AddContext(ctx, safeJoinLabels(names, mt.combinedLabels), elapsed)
+}
+
+// RecordContext is like Record, but also keeps the timing data as an
+// exemplar if the context belongs to a trace.
+func (mt *MultiTimings) RecordContext(ctx context.Context, names []string, startTime time.Time) {
+	if len(names) != len(mt.labels) {
+		panic("MultiTimings: wrong number of values in RecordContext")
+	}
+	mt.Timings.RecordContext(ctx, safeJoinLabels(names, mt.combinedLabels), startTime)
 }
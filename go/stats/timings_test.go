@@ -17,12 +17,14 @@ limitations under the License.
 package stats
 
 import (
+	"context"
 	"expvar"
 	"strings"
 	"testing"
 	"time"
 
 	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
 )
 
 func TestTimings(t *testing.T) {
@@ -99,3 +101,66 @@ func TestTimingsCombineDimension(t *testing.T) {
 	want = `{"TotalCount":1,"TotalTime":1,"Histograms":{"all.c2.all":{"500000":1,"1000000":0,"5000000":0,"10000000":0,"50000000":0,"100000000":0,"500000000":0,"1000000000":0,"5000000000":0,"10000000000":0,"inf":0,"Count":1,"Time":1}}}`
 	assert.Equal(t, want, t3.String())
 }
+
+func TestTimingsAddContext(t *testing.T) {
+	clearStats()
+	type traceKey struct{}
+	RegisterTraceIDHook(func(ctx context.Context) (string, bool) {
+		traceID, ok := ctx.Value(traceKey{}).(string)
+		return traceID, ok
+	})
+	defer RegisterTraceIDHook(nil)
+
+	tm := NewTimings("timings_exemplars", "help", "category")
+	tm.AddContext(context.Background(), "tag1", 500*time.Microsecond)
+	tm.AddContext(context.WithValue(context.Background(), traceKey{}, "trace1"), "tag1", 2*time.Millisecond)
+	assert.EqualValues(t, 2, tm.Count())
+
+	exemplars := tm.Histograms()["tag1"].Exemplars()
+	assert.Nil(t, exemplars[0])
+	assert.Nil(t, exemplars[1])
+	require.NotNil(t, exemplars[2])
+	assert.Equal(t, "trace1", exemplars[2].TraceID)
+	assert.Equal(t, int64(2*time.Millisecond), exemplars[2].Value)
+
+	mtm := NewMultiTimings("multitimings_exemplars", "help", []string{"dim1", "dim2"})
+	mtm.RecordContext(context.WithValue(context.Background(), traceKey{}, "trace2"), []string{"a", "b"}, time.Now())
+	exemplars = mtm.Histograms()["a.b"].Exemplars()
+	require.NotNil(t, exemplars[0])
+	assert.Equal(t, "trace2", exemplars[0].TraceID)
+}
+
+func TestTimingsBuckets(t *testing.T) {
+	clearStats()
+	defer setBucketCutoffs(bucketCutoffs)
+
+	// Timings created before the flags are parsed get the new layout, unless they already have histograms.
+	early := NewTimings("timings_buckets_early", "help", "category")
+	initialized := NewTimings("timings_buckets_initialized", "help", "category", "tag1")
+	defaultCutoffs := initialized.Cutoffs()
+
+	var buckets timingsBuckets
+	assert.Equal(t, "[500µs,1ms,5ms,10ms,50ms,100ms,500ms,1s,5s,10s]", buckets.String())
+	assert.Error(t, buckets.Set("1ms,foo"))
+	assert.Error(t, buckets.Set("2ms,1ms"))
+	assert.Error(t, buckets.Set("1ms,1ms"))
+	require.NoError(t, buckets.Set("1ms, 2ms,4ms"))
+	assert.Equal(t, "[1ms,2ms,4ms]", buckets.String())
+
+	tm := NewTimings("timings_buckets", "help", "category")
+	tm.Add("tag1", 3*time.Millisecond)
+	assert.Equal(t, []int64{1e6, 2e6, 4e6}, tm.Cutoffs())
+	want := `{"TotalCount":1,"TotalTime":3000000,"Histograms":{"tag1":{"1000000":0,"2000000":0,"4000000":1,"inf":0,"Count":1,"Time":3000000}}}`
+	assert.Equal(t, want, tm.String())
+
+	early.Add("tag1", 3*time.Millisecond)
+	assert.Equal(t, []int64{1e6, 2e6, 4e6}, early.Cutoffs())
+	assert.Equal(t, early.Cutoffs(), early.Histograms()["tag1"].Cutoffs())
+
+	initialized.Add("tag2", 3*time.Millisecond)
+	assert.Equal(t, defaultCutoffs, initialized.Cutoffs())
+	assert.Equal(t, defaultCutoffs, initialized.Histograms()["tag2"].Cutoffs())
+
+	mtm := NewMultiTimings("multitimings_buckets", "help", []string{"dim1", "dim2"})
+	assert.Equal(t, []int64{1e6, 2e6, 4e6}, mtm.Cutoffs())
+}
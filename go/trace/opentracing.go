@@ -44,6 +44,20 @@ func (js openTracingSpan) Annotate(key string, value any) {
 	js.otSpan.SetTag(key, value)
 }
 
+// TraceID returns the ID of the trace of the span, if the tracer exposes it
+func (js openTracingSpan) TraceID() (string, bool) {
+	for _, traceID := range traceIDExtractors {
+		if id, ok := traceID(js.otSpan.Context()); ok {
+			return id, true
+		}
+	}
+	return "", false
+}
+
+// traceIDExtractors should be added to by a plugin during init() to expose the
+// trace IDs of its span contexts
+var traceIDExtractors []func(opentracing.SpanContext) (string, bool)
+
 var _ tracingService = (*openTracingService)(nil)
 
 type tracer interface {
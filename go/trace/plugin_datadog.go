@@ -4,9 +4,11 @@ import (
 	"fmt"
 	"io"
 	"net"
+	"strconv"
 
 	"github.com/opentracing/opentracing-go"
 	"github.com/spf13/pflag"
+	"gopkg.in/DataDog/dd-trace-go.v1/ddtrace"
 	"gopkg.in/DataDog/dd-trace-go.v1/ddtrace/opentracer"
 	ddtracer "gopkg.in/DataDog/dd-trace-go.v1/ddtrace/tracer"
 
@@ -76,6 +78,15 @@ func (ddCloser) Close() error {
 
 func init() {
 	tracingBackendFactories["opentracing-datadog"] = newDatadogTracer
+	traceIDExtractors = append(traceIDExtractors, datadogTraceID)
+}
+
+func datadogTraceID(sc opentracing.SpanContext) (string, bool) {
+	dsc, ok := sc.(ddtrace.SpanContext)
+	if !ok || dsc.TraceID() == 0 {
+		return "", false
+	}
+	return strconv.FormatUint(dsc.TraceID(), 10), true
 }
 
 var _ tracer = (*datadogTracer)(nil)
@@ -20,6 +20,7 @@ import (
 	"testing"
 
 	"github.com/stretchr/testify/require"
+	"github.com/uber/jaeger-client-go"
 )
 
 func TestNewJaegerTracerFromEnv(t *testing.T) {
@@ -33,3 +34,18 @@ func TestNewJaegerTracerFromEnv(t *testing.T) {
 	require.Empty(t, tracingSvc)
 	require.Empty(t, closer)
 }
+
+func TestJaegerTraceID(t *testing.T) {
+	for _, sampled := range []bool{true, false} {
+		tracer, closer := jaeger.NewTracer("svc", jaeger.NewConstSampler(sampled), jaeger.NewNullReporter())
+		defer closer.Close()
+		svc := openTracingService{Tracer: &jaegerTracer{actual: tracer}}
+
+		span := svc.New(nil, "label").(openTracingSpan)
+		traceID, ok := span.TraceID()
+		require.Equal(t, sampled, ok)
+		if sampled {
+			require.Equal(t, span.otSpan.Context().(jaeger.SpanContext).TraceID().String(), traceID)
+		}
+	}
+}
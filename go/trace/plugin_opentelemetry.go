@@ -0,0 +1,372 @@
+/*
+Copyright 2025 The Vitess Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package trace
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"strings"
+	"sync"
+
+	"github.com/spf13/pflag"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+	"go.opentelemetry.io/otel/exporters/otlp/otlptrace"
+	"go.opentelemetry.io/otel/exporters/otlp/otlptrace/otlptracegrpc"
+	"go.opentelemetry.io/otel/exporters/otlp/otlptrace/otlptracehttp"
+	"go.opentelemetry.io/otel/propagation"
+	"go.opentelemetry.io/otel/sdk/resource"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	semconv "go.opentelemetry.io/otel/semconv/v1.26.0"
+	oteltrace "go.opentelemetry.io/otel/trace"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/metadata"
+
+	"vitess.io/vitess/go/viperutil"
+	"vitess.io/vitess/go/vt/vterrors"
+
+	vtrpcpb "vitess.io/vitess/go/vt/proto/vtrpc"
+)
+
+/*
+This file makes it easy to build Vitess without including the OpenTelemetry
+binaries. All that is needed is to delete this file.
+*/
+
+var (
+	otelConfigKey = viperutil.KeyPrefixFunc(configKey("otel"))
+
+	otelEndpoint = viperutil.Configure(
+		otelConfigKey("endpoint"),
+		viperutil.Options[string]{
+			FlagName: "otel-endpoint",
+		},
+	)
+	otelProtocol = viperutil.Configure(
+		otelConfigKey("protocol"),
+		viperutil.Options[string]{
+			Default:  "grpc",
+			FlagName: "otel-protocol",
+		},
+	)
+	otelInsecure = viperutil.Configure(
+		otelConfigKey("insecure"),
+		viperutil.Options[bool]{
+			FlagName: "otel-insecure",
+		},
+	)
+)
+
+func init() {
+	// If compiled with plugin_opentelemetry, ensure that trace.RegisterFlags
+	// includes the OpenTelemetry tracing flags.
+	pluginFlags = append(pluginFlags, func(fs *pflag.FlagSet) {
+		fs.String("otel-endpoint", otelEndpoint.Default(), "host and port of the OTLP collector to send spans to. if empty, the OTEL_EXPORTER_OTLP_ENDPOINT environment variable or the default endpoint of the protocol is used")
+		fs.String("otel-protocol", otelProtocol.Default(), "protocol to send spans to the OTLP collector with. possible values are 'grpc' or 'http'")
+		fs.Bool("otel-insecure", otelInsecure.Default(), "whether to send spans to the OTLP collector without TLS")
+
+		viperutil.BindFlags(fs, otelEndpoint, otelProtocol, otelInsecure)
+	})
+
+	tracingBackendFactories["opentelemetry"] = newOpenTelemetryTracer
+}
+
+// traceparentPropagator propagates the span contexts in the W3C traceparent format.
+var traceparentPropagator = propagation.TraceContext{}
+
+func newOpenTelemetryTracer(serviceName string) (tracingService, io.Closer, error) {
+	exporter, err := newOTLPExporter()
+	if err != nil {
+		return nil, nil, err
+	}
+
+	res, err := resource.Merge(resource.Default(), resource.NewSchemaless(semconv.ServiceName(serviceName)))
+	if err != nil {
+		return nil, nil, err
+	}
+	provider := sdktrace.NewTracerProvider(
+		sdktrace.WithBatcher(exporter),
+		sdktrace.WithResource(res),
+		sdktrace.WithSampler(sdktrace.ParentBased(sdktrace.TraceIDRatioBased(samplingRate.Get()))),
+	)
+	return &openTelemetryService{tracer: provider.Tracer("vitess.io/vitess")}, &otelCloser{provider: provider}, nil
+}
+
+func newOTLPExporter() (*otlptrace.Exporter, error) {
+	ctx := context.Background()
+	switch otelProtocol.Get() {
+	case "grpc":
+		var opts []otlptracegrpc.Option
+		if endpoint := otelEndpoint.Get(); endpoint != "" {
+			opts = append(opts, otlptracegrpc.WithEndpoint(endpoint))
+		}
+		if otelInsecure.Get() {
+			opts = append(opts, otlptracegrpc.WithInsecure())
+		}
+		return otlptracegrpc.New(ctx, opts...)
+	case "http":
+		var opts []otlptracehttp.Option
+		if endpoint := otelEndpoint.Get(); endpoint != "" {
+			opts = append(opts, otlptracehttp.WithEndpoint(endpoint))
+		}
+		if otelInsecure.Get() {
+			opts = append(opts, otlptracehttp.WithInsecure())
+		}
+		return otlptracehttp.New(ctx, opts...)
+	default:
+		return nil, fmt.Errorf("unknown OTLP protocol %q, expected 'grpc' or 'http'", otelProtocol.Get())
+	}
+}
+
+var _ io.Closer = (*otelCloser)(nil)
+
+type otelCloser struct {
+	provider *sdktrace.TracerProvider
+}
+
+// Close flushes the spans that have not been exported yet.
+func (c *otelCloser) Close() error {
+	return c.provider.Shutdown(context.Background())
+}
+
+var _ Span = (*openTelemetrySpan)(nil)
+
+type openTelemetrySpan struct {
+	otSpan oteltrace.Span
+}
+
+// Finish will mark a span as finished
+func (s openTelemetrySpan) Finish() {
+	s.otSpan.End()
+}
+
+// Annotate will add information to an existing span
+func (s openTelemetrySpan) Annotate(key string, value any) {
+	var kv attribute.KeyValue
+	switch v := value.(type) {
+	case string:
+		kv = attribute.String(key, v)
+	case bool:
+		kv = attribute.Bool(key, v)
+	case int:
+		kv = attribute.Int(key, v)
+	case int64:
+		kv = attribute.Int64(key, v)
+	case float64:
+		kv = attribute.Float64(key, v)
+	default:
+		kv = attribute.String(key, fmt.Sprint(v))
+	}
+	s.otSpan.SetAttributes(kv)
+}
+
+// TraceID returns the ID of the trace of the span, if it is sampled
+func (s openTelemetrySpan) TraceID() (string, bool) {
+	sc := s.otSpan.SpanContext()
+	if !sc.IsSampled() {
+		return "", false
+	}
+	return sc.TraceID().String(), true
+}
+
+// Traceparent returns the span context in the W3C traceparent format
+func (s openTelemetrySpan) Traceparent() (string, bool) {
+	carrier := propagation.MapCarrier{}
+	traceparentPropagator.Inject(oteltrace.ContextWithSpan(context.Background(), s.otSpan), carrier)
+	traceparent := carrier.Get("traceparent")
+	return traceparent, traceparent != ""
+}
+
+var _ tracingService = (*openTelemetryService)(nil)
+
+type openTelemetryService struct {
+	tracer oteltrace.Tracer
+}
+
+// New is part of an interface implementation
+func (ot *openTelemetryService) New(parent Span, label string) Span {
+	ctx := context.Background()
+	if parent, ok := parent.(openTelemetrySpan); ok {
+		ctx = oteltrace.ContextWithSpan(ctx, parent.otSpan)
+	}
+	_, span := ot.tracer.Start(ctx, label)
+	return openTelemetrySpan{otSpan: span}
+}
+
+// NewFromString is part of an interface implementation. The parent is either
+// a W3C traceparent, or the base64 encoded JSON map of the propagated fields.
+func (ot *openTelemetryService) NewFromString(parent, label string) (Span, error) {
+	carrier := propagation.MapCarrier{}
+	if strings.Count(parent, "-") == 3 {
+		carrier.Set("traceparent", parent)
+	} else {
+		fields, err := extractMapFromString(parent)
+		if err != nil {
+			return nil, err
+		}
+		for k, v := range fields {
+			carrier.Set(k, v)
+		}
+	}
+	ctx := traceparentPropagator.Extract(context.Background(), carrier)
+	if !oteltrace.SpanContextFromContext(ctx).IsValid() {
+		return nil, vterrors.Errorf(vtrpcpb.Code_INVALID_ARGUMENT, "failed to deserialize span context: %s", parent)
+	}
+	_, span := ot.tracer.Start(ctx, label)
+	return openTelemetrySpan{otSpan: span}, nil
+}
+
+// FromContext is part of an interface implementation
+func (ot *openTelemetryService) FromContext(ctx context.Context) (Span, bool) {
+	span := oteltrace.SpanFromContext(ctx)
+	if !span.SpanContext().IsValid() {
+		return nil, false
+	}
+	return openTelemetrySpan{otSpan: span}, true
+}
+
+// NewContext is part of an interface implementation
+func (ot *openTelemetryService) NewContext(parent context.Context, s Span) context.Context {
+	span, ok := s.(openTelemetrySpan)
+	if !ok {
+		return nil
+	}
+	return oteltrace.ContextWithSpan(parent, span.otSpan)
+}
+
+// AddGrpcServerOptions is part of an interface implementation
+func (ot *openTelemetryService) AddGrpcServerOptions(addInterceptors func(s grpc.StreamServerInterceptor, u grpc.UnaryServerInterceptor)) {
+	addInterceptors(ot.streamServerInterceptor, ot.unaryServerInterceptor)
+}
+
+// AddGrpcClientOptions is part of an interface implementation
+func (ot *openTelemetryService) AddGrpcClientOptions(addInterceptors func(s grpc.StreamClientInterceptor, u grpc.UnaryClientInterceptor)) {
+	addInterceptors(ot.streamClientInterceptor, ot.unaryClientInterceptor)
+}
+
+// metadataCarrier adapts the gRPC metadata to carry the propagated fields.
+type metadataCarrier metadata.MD
+
+func (mc metadataCarrier) Get(key string) string {
+	if values := metadata.MD(mc).Get(key); len(values) > 0 {
+		return values[0]
+	}
+	return ""
+}
+
+func (mc metadataCarrier) Set(key, value string) {
+	metadata.MD(mc).Set(key, value)
+}
+
+func (mc metadataCarrier) Keys() []string {
+	keys := make([]string, 0, len(mc))
+	for k := range mc {
+		keys = append(keys, k)
+	}
+	return keys
+}
+
+// startServerSpan starts the span of a gRPC call, as a child of the span propagated by the client.
+func (ot *openTelemetryService) startServerSpan(ctx context.Context, method string) (context.Context, oteltrace.Span) {
+	md, _ := metadata.FromIncomingContext(ctx)
+	ctx = traceparentPropagator.Extract(ctx, metadataCarrier(md))
+	return ot.tracer.Start(ctx, method, oteltrace.WithSpanKind(oteltrace.SpanKindServer))
+}
+
+// startClientSpan starts the span of a gRPC call, and propagates it to the server.
+func (ot *openTelemetryService) startClientSpan(ctx context.Context, method string) (context.Context, oteltrace.Span) {
+	ctx, span := ot.tracer.Start(ctx, method, oteltrace.WithSpanKind(oteltrace.SpanKindClient))
+	md, ok := metadata.FromOutgoingContext(ctx)
+	if ok {
+		md = md.Copy()
+	} else {
+		md = metadata.MD{}
+	}
+	traceparentPropagator.Inject(ctx, metadataCarrier(md))
+	return metadata.NewOutgoingContext(ctx, md), span
+}
+
+func endSpan(span oteltrace.Span, err error) {
+	if err != nil {
+		span.SetStatus(codes.Error, err.Error())
+	}
+	span.End()
+}
+
+func (ot *openTelemetryService) unaryServerInterceptor(ctx context.Context, req any, info *grpc.UnaryServerInfo, handler grpc.UnaryHandler) (any, error) {
+	ctx, span := ot.startServerSpan(ctx, info.FullMethod)
+	resp, err := handler(ctx, req)
+	endSpan(span, err)
+	return resp, err
+}
+
+func (ot *openTelemetryService) streamServerInterceptor(srv any, ss grpc.ServerStream, info *grpc.StreamServerInfo, handler grpc.StreamHandler) error {
+	ctx, span := ot.startServerSpan(ss.Context(), info.FullMethod)
+	err := handler(srv, &serverStreamWithContext{ServerStream: ss, ctx: ctx})
+	endSpan(span, err)
+	return err
+}
+
+func (ot *openTelemetryService) unaryClientInterceptor(ctx context.Context, method string, req, reply any, cc *grpc.ClientConn, invoker grpc.UnaryInvoker, opts ...grpc.CallOption) error {
+	ctx, span := ot.startClientSpan(ctx, method)
+	err := invoker(ctx, method, req, reply, cc, opts...)
+	endSpan(span, err)
+	return err
+}
+
+func (ot *openTelemetryService) streamClientInterceptor(ctx context.Context, desc *grpc.StreamDesc, cc *grpc.ClientConn, method string, streamer grpc.Streamer, opts ...grpc.CallOption) (grpc.ClientStream, error) {
+	ctx, span := ot.startClientSpan(ctx, method)
+	stream, err := streamer(ctx, desc, cc, method, opts...)
+	if err != nil {
+		endSpan(span, err)
+		return nil, err
+	}
+	return &clientStreamWithSpan{ClientStream: stream, span: span}, nil
+}
+
+// clientStreamWithSpan ends the span of a stream once it has been fully received.
+type clientStreamWithSpan struct {
+	grpc.ClientStream
+	span oteltrace.Span
+	once sync.Once
+}
+
+func (cs *clientStreamWithSpan) RecvMsg(m any) error {
+	err := cs.ClientStream.RecvMsg(m)
+	if err != nil {
+		cs.once.Do(func() {
+			// The end of the stream is not a failure of the span, but the
+			// caller must still see it.
+			spanErr := err
+			if spanErr == io.EOF {
+				spanErr = nil
+			}
+			endSpan(cs.span, spanErr)
+		})
+	}
+	return err
+}
+
+type serverStreamWithContext struct {
+	grpc.ServerStream
+	ctx context.Context
+}
+
+func (ss *serverStreamWithContext) Context() context.Context {
+	return ss.ctx
+}
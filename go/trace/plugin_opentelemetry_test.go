@@ -0,0 +1,178 @@
+/*
+Copyright 2025 The Vitess Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package trace
+
+import (
+	"context"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"io"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	"go.opentelemetry.io/otel/sdk/trace/tracetest"
+	oteltrace "go.opentelemetry.io/otel/trace"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/metadata"
+)
+
+func newTestOpenTelemetryService() (*openTelemetryService, *tracetest.SpanRecorder) {
+	recorder := tracetest.NewSpanRecorder()
+	provider := sdktrace.NewTracerProvider(sdktrace.WithSpanProcessor(recorder))
+	return &openTelemetryService{tracer: provider.Tracer("test")}, recorder
+}
+
+func TestOpenTelemetrySpans(t *testing.T) {
+	svc, recorder := newTestOpenTelemetryService()
+
+	parent := svc.New(nil, "parent")
+	ctx := svc.NewContext(context.Background(), parent)
+	fromCtx, ok := svc.FromContext(ctx)
+	require.True(t, ok)
+	assert.Equal(t, parent, fromCtx)
+
+	child := svc.New(fromCtx, "child")
+	child.Annotate("key", "value")
+	child.Annotate("count", 42)
+	child.Finish()
+	parent.Finish()
+
+	spans := recorder.Ended()
+	require.Len(t, spans, 2)
+	assert.Equal(t, "child", spans[0].Name())
+	assert.Equal(t, spans[1].SpanContext().SpanID(), spans[0].Parent().SpanID())
+	assert.Equal(t, spans[1].SpanContext().TraceID(), spans[0].SpanContext().TraceID())
+	assert.ElementsMatch(t, []attribute.KeyValue{attribute.String("key", "value"), attribute.Int("count", 42)}, spans[0].Attributes())
+
+	traceID, ok := parent.(openTelemetrySpan).TraceID()
+	require.True(t, ok)
+	assert.Equal(t, spans[1].SpanContext().TraceID().String(), traceID)
+
+	_, ok = svc.FromContext(context.Background())
+	assert.False(t, ok)
+}
+
+func TestOpenTelemetryNewFromString(t *testing.T) {
+	svc, _ := newTestOpenTelemetryService()
+	const traceparent = "00-0af7651916cd43dd8448eb211c80319c-b7ad6b7169203331-01"
+
+	// A W3C traceparent.
+	span, err := svc.NewFromString(traceparent, "label")
+	require.NoError(t, err)
+	sc := span.(openTelemetrySpan).otSpan.SpanContext()
+	assert.Equal(t, "0af7651916cd43dd8448eb211c80319c", sc.TraceID().String())
+	got, ok := span.(openTelemetrySpan).Traceparent()
+	require.True(t, ok)
+	assert.Equal(t, "00-0af7651916cd43dd8448eb211c80319c-"+sc.SpanID().String()+"-01", got)
+
+	// The base64 encoded JSON map of the propagated fields.
+	data, err := json.Marshal(map[string]string{"traceparent": traceparent})
+	require.NoError(t, err)
+	span, err = svc.NewFromString(base64.StdEncoding.EncodeToString(data), "label")
+	require.NoError(t, err)
+	assert.Equal(t, "0af7651916cd43dd8448eb211c80319c", span.(openTelemetrySpan).otSpan.SpanContext().TraceID().String())
+
+	_, err = svc.NewFromString("00-invalid-trace-parent", "label")
+	assert.ErrorContains(t, err, "failed to deserialize span context")
+	_, err = svc.NewFromString("not base64", "label")
+	assert.Error(t, err)
+}
+
+func TestOpenTelemetryGrpcPropagation(t *testing.T) {
+	svc, recorder := newTestOpenTelemetryService()
+
+	parent := svc.New(nil, "parent")
+	ctx := svc.NewContext(context.Background(), parent)
+
+	// The client propagates its span in the outgoing metadata.
+	var md metadata.MD
+	err := svc.unaryClientInterceptor(ctx, "/test/Method", nil, nil, nil, func(ctx context.Context, method string, req, reply any, cc *grpc.ClientConn, opts ...grpc.CallOption) error {
+		md, _ = metadata.FromOutgoingContext(ctx)
+		return nil
+	})
+	require.NoError(t, err)
+	require.Len(t, md.Get("traceparent"), 1)
+
+	// The server continues the trace from the incoming metadata.
+	var serverSpan oteltrace.SpanContext
+	_, err = svc.unaryServerInterceptor(metadata.NewIncomingContext(context.Background(), md), nil, &grpc.UnaryServerInfo{FullMethod: "/test/Method"}, func(ctx context.Context, req any) (any, error) {
+		serverSpan = oteltrace.SpanContextFromContext(ctx)
+		return nil, nil
+	})
+	require.NoError(t, err)
+	parent.Finish()
+
+	spans := recorder.Ended()
+	require.Len(t, spans, 3)
+	client, server := spans[0], spans[1]
+	assert.Equal(t, oteltrace.SpanKindClient, client.SpanKind())
+	assert.Equal(t, oteltrace.SpanKindServer, server.SpanKind())
+	assert.Equal(t, server.SpanContext(), serverSpan)
+	assert.Equal(t, client.SpanContext().SpanID(), server.Parent().SpanID())
+	assert.Equal(t, parent.(openTelemetrySpan).otSpan.SpanContext().TraceID(), server.SpanContext().TraceID())
+}
+
+// fakeClientStream receives the given number of messages, then fails with err.
+type fakeClientStream struct {
+	grpc.ClientStream
+	msgs int
+	err  error
+}
+
+func (cs *fakeClientStream) RecvMsg(m any) error {
+	if cs.msgs == 0 {
+		return cs.err
+	}
+	cs.msgs--
+	return nil
+}
+
+func TestOpenTelemetryGrpcStream(t *testing.T) {
+	for _, tcase := range []struct {
+		name   string
+		err    error
+		status codes.Code
+	}{
+		{name: "eof", err: io.EOF, status: codes.Unset},
+		{name: "error", err: errors.New("stream failed"), status: codes.Error},
+	} {
+		t.Run(tcase.name, func(t *testing.T) {
+			svc, recorder := newTestOpenTelemetryService()
+			stream, err := svc.streamClientInterceptor(context.Background(), &grpc.StreamDesc{ServerStreams: true}, nil, "/test/Stream", func(ctx context.Context, desc *grpc.StreamDesc, cc *grpc.ClientConn, method string, opts ...grpc.CallOption) (grpc.ClientStream, error) {
+				return &fakeClientStream{msgs: 2, err: tcase.err}, nil
+			})
+			require.NoError(t, err)
+
+			// The span ends with the stream, and the caller sees how the stream ended.
+			require.NoError(t, stream.RecvMsg(nil))
+			require.NoError(t, stream.RecvMsg(nil))
+			assert.Empty(t, recorder.Ended())
+			assert.Equal(t, tcase.err, stream.RecvMsg(nil))
+			assert.Equal(t, tcase.err, stream.RecvMsg(nil))
+
+			spans := recorder.Ended()
+			require.Len(t, spans, 1)
+			assert.Equal(t, "/test/Stream", spans[0].Name())
+			assert.Equal(t, tcase.status, spans[0].Status().Code)
+		})
+	}
+}
@@ -77,6 +77,34 @@ func FromContext(ctx context.Context) (Span, bool) {
 	return currentTracer.FromContext(ctx)
 }
 
+// TraceID returns the ID of the trace of the Span in a Context. The bool return
+// value indicates whether a Span was present in the Context and the installed
+// tracing plugin exposes the ID of its trace.
+func TraceID(ctx context.Context) (string, bool) {
+	span, ok := FromContext(ctx)
+	if !ok {
+		return "", false
+	}
+	if s, ok := span.(interface{ TraceID() (string, bool) }); ok {
+		return s.TraceID()
+	}
+	return "", false
+}
+
+// Traceparent returns the Span in a Context in the W3C traceparent format. The
+// bool return value indicates whether a Span was present in the Context and the
+// installed tracing plugin supports the format.
+func Traceparent(ctx context.Context) (string, bool) {
+	span, ok := FromContext(ctx)
+	if !ok {
+		return "", false
+	}
+	if s, ok := span.(interface{ Traceparent() (string, bool) }); ok {
+		return s.Traceparent()
+	}
+	return "", false
+}
+
 // NewContext returns a context based on parent with a new Span value.
 func NewContext(parent context.Context, span Span) context.Context {
 	return currentTracer.NewContext(parent, span)
@@ -46,6 +46,17 @@ func TestFakeSpan(t *testing.T) {
 	span3.Finish()
 }
 
+func TestTraceIDWithoutSpan(t *testing.T) {
+	_, ok := TraceID(context.Background())
+	require.False(t, ok)
+
+	// The spans of the fake tracer have no trace ID.
+	span, ctx := NewSpan(context.Background(), "label")
+	defer span.Finish()
+	_, ok = TraceID(ctx)
+	require.False(t, ok)
+}
+
 func TestRegisterService(t *testing.T) {
 	fakeName := "test"
 	tracingBackendFactories[fakeName] = func(serviceName string) (tracingService, io.Closer, error) {
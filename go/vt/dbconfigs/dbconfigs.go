@@ -90,6 +90,8 @@ type DBConfigs struct {
 	ConnectTimeoutMilliseconds int           `json:"connectTimeoutMilliseconds,omitempty"`
 	DBName                     string        `json:"dbName,omitempty"`
 	EnableQueryInfo            bool          `json:"enableQueryInfo,omitempty"`
+	Compression                string        `json:"compression,omitempty"`
+	CompressionLevel           int           `json:"compressionLevel,omitempty"`
 
 	App          UserConfig `json:"app,omitempty"`
 	Dba          UserConfig `json:"dba,omitempty"`
@@ -147,6 +149,8 @@ func registerBaseFlags(fs *pflag.FlagSet) {
 	utils.SetFlagStringVar(fs, &GlobalDBConfigs.ServerName, "db-server-name", "", "server name of the DB we are connecting to.")
 	utils.SetFlagIntVar(fs, &GlobalDBConfigs.ConnectTimeoutMilliseconds, "db-connect-timeout-ms", 0, "connection timeout to mysqld in milliseconds (0 for no timeout)")
 	utils.SetFlagBoolVar(fs, &GlobalDBConfigs.EnableQueryInfo, "db-conn-query-info", false, "enable parsing and processing of QUERY_OK info fields")
+	utils.SetFlagStringVar(fs, &GlobalDBConfigs.Compression, "db-compression", "", "Compression algorithm of the protocol to request from mysqld. Options: zlib, zstd. Packets are not compressed by default.")
+	utils.SetFlagIntVar(fs, &GlobalDBConfigs.CompressionLevel, "db-compression-level", 0, "Level of the compression requested with --db-compression, 0 for the default of the algorithm.")
 }
 
 // The flags will change the global singleton
@@ -360,6 +364,8 @@ func (dbcfgs *DBConfigs) InitWithSocket(defaultSocketFile string, collationEnv *
 		}
 		cp.ConnectTimeoutMs = uint64(dbcfgs.ConnectTimeoutMilliseconds)
 		cp.EnableQueryInfo = dbcfgs.EnableQueryInfo
+		cp.Compression = dbcfgs.Compression
+		cp.CompressionLevel = dbcfgs.CompressionLevel
 
 		cp.Uname = uc.User
 		cp.Pass = uc.Password
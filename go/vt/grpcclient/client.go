@@ -59,6 +59,7 @@ var (
 		"vtadmin",
 		"vtbackup",
 		"vtbench",
+		"vtcdc",
 		"vtclient",
 		"vtctl",
 		"vtctlclient",
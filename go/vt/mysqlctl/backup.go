@@ -149,14 +149,24 @@ func Backup(ctx context.Context, params BackupParams) error {
 		Stats:  bsStats,
 	})
 
+	encryption, dataKey, err := newBackupEncryption(ctx)
+	if err != nil {
+		return err
+	}
+
 	bh, err := bs.StartBackup(ctx, backupDir, name)
 	if err != nil {
 		return vterrors.Wrap(err, "StartBackup failed")
 	}
 	params.Logger.Infof("Starting backup %v", bh.Name())
+	if encryption != nil {
+		params.Logger.Infof("Encrypting backup with key %v of key provider %q", encryption.KeyID, encryption.KeyProvider)
+		bh = newEncryptedBackupHandle(bh, dataKey)
+	}
 
 	// Scope stats to selected backup engine.
 	beParams := params.Copy()
+	beParams.Encryption = encryption
 	beParams.Stats = params.Stats.Scope(
 		backupstats.Component(backupstats.BackupEngine),
 		backupstats.Implementation(textutil.Title(backupEngineImplementation)),
@@ -435,6 +445,10 @@ func Restore(ctx context.Context, params RestoreParams) (*BackupManifest, error)
 	if err != nil {
 		return nil, vterrors.Wrap(err, "Failed to find restore engine")
 	}
+	bh, err = decryptedBackupHandle(ctx, bh, restorePath.manifests[0])
+	if err != nil {
+		return nil, err
+	}
 	params.Logger.Infof("Restore: %v", restorePath.String())
 	if params.DryRun {
 		return nil, nil
@@ -488,7 +502,11 @@ func Restore(ctx context.Context, params RestoreParams) (*BackupManifest, error)
 		// Incremental restores are always done via 'builtin' engine, which copies
 		// appropriate binlog files.
 		builtInRE := BackupRestoreEngineMap[builtinBackupEngineName]
-		for _, bh := range handles {
+		for i, bh := range handles {
+			bh, err := decryptedBackupHandle(ctx, bh, restorePath.manifests[i+1])
+			if err != nil {
+				return nil, err
+			}
 			manifest, err := builtInRE.ExecuteRestore(ctx, params, bh)
 			if err != nil {
 				return nil, err
@@ -0,0 +1,478 @@
+/*
+Copyright 2025 The Vitess Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package mysqlctl
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"crypto/aes"
+	"crypto/cipher"
+	"crypto/hkdf"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/binary"
+	"encoding/hex"
+	"errors"
+	"io"
+	"os"
+	"strings"
+
+	"github.com/spf13/pflag"
+
+	"vitess.io/vitess/go/vt/mysqlctl/backupstorage"
+	"vitess.io/vitess/go/vt/proto/vtrpc"
+	"vitess.io/vitess/go/vt/servenv"
+	"vitess.io/vitess/go/vt/utils"
+	"vitess.io/vitess/go/vt/vterrors"
+)
+
+// This file implements the client-side envelope encryption of backups: every
+// backup is encrypted with its own random data key, which is stored in the
+// MANIFEST wrapped by a key provider. Files are encrypted by the backup handle,
+// so that all the engines and storages that write through it are covered.
+
+const (
+	// BackupEncryptionAlgorithm is the algorithm used to encrypt backup files.
+	BackupEncryptionAlgorithm = "aes-256-gcm-stream"
+
+	// fileBackupKeyProviderName is the name of the key provider that reads
+	// the key encryption key from a local file.
+	fileBackupKeyProviderName = "file"
+
+	backupDataKeySize = 32
+	// encryptedFileMagic starts every encrypted backup file, and identifies
+	// the version of the stream format.
+	encryptedFileMagic    = "VTBKENC1"
+	encryptedFileSaltSize = 32
+	// encryptedChunkSize is the size of the plaintext chunks that are sealed
+	// independently. Every chunk but the last one is full.
+	encryptedChunkSize = 64 * 1024
+	// encryptedChunkOverhead is the size of the AES-GCM tag appended to every
+	// sealed chunk. The nonces are derived from the chunk counters, and are not
+	// stored.
+	encryptedChunkOverhead = 16
+)
+
+var (
+	// backupEncryptionKeyProvider is the name of the key provider used to wrap
+	// the data key of new backups. New backups are not encrypted if empty.
+	backupEncryptionKeyProvider string
+	// backupEncryptionKeyFile is the file holding the key of the "file" key
+	// provider.
+	backupEncryptionKeyFile string
+
+	// BackupKeyProviderMap contains the registered backup key providers.
+	// Restores use the key provider recorded in the MANIFEST of the backup.
+	BackupKeyProviderMap = make(map[string]BackupKeyProvider)
+)
+
+func init() {
+	for _, cmd := range []string{"vtbackup", "vtcombo", "vttablet", "vttestserver"} {
+		servenv.OnParseFor(cmd, registerBackupEncryptionFlags)
+	}
+	BackupKeyProviderMap[fileBackupKeyProviderName] = &fileBackupKeyProvider{}
+}
+
+func registerBackupEncryptionFlags(fs *pflag.FlagSet) {
+	utils.SetFlagStringVar(fs, &backupEncryptionKeyProvider, "backup-encryption-key-provider", backupEncryptionKeyProvider, "Key provider used to wrap the data key of new backups, which are not encrypted if empty. Supported values: 'file'. Restores always use the key provider recorded in the backup MANIFEST.")
+	utils.SetFlagStringVar(fs, &backupEncryptionKeyFile, "backup-encryption-key-file", backupEncryptionKeyFile, "Path to the file holding the hex-encoded 256-bit key used by the 'file' backup key provider to wrap and unwrap backup data keys.")
+}
+
+// BackupEncryption is recorded in the MANIFEST of encrypted backups, and holds
+// what is needed to decrypt their files.
+type BackupEncryption struct {
+	// Algorithm is the algorithm used to encrypt the files of the backup.
+	Algorithm string
+	// KeyProvider is the name of the key provider that wrapped the data key.
+	KeyProvider string
+	// KeyID identifies the key of the provider that wrapped the data key.
+	KeyID string
+	// WrappedKey is the data key of the backup, wrapped by the key provider.
+	WrappedKey []byte
+}
+
+// BackupKeyProvider wraps and unwraps the data keys of backups with a key
+// encryption key that it holds, or that it can access.
+type BackupKeyProvider interface {
+	// WrapKey wraps the data key, and returns the wrapped key along with the
+	// ID of the key that wrapped it.
+	WrapKey(ctx context.Context, dataKey []byte) (keyID string, wrappedKey []byte, err error)
+	// UnwrapKey unwraps a data key that was wrapped by the key with the given ID.
+	UnwrapKey(ctx context.Context, keyID string, wrappedKey []byte) ([]byte, error)
+}
+
+// newBackupEncryption generates the data key of a new backup if backup
+// encryption is enabled, and returns it along with the encryption details to
+// record in the MANIFEST. It returns nil if backups are not encrypted.
+func newBackupEncryption(ctx context.Context) (*BackupEncryption, []byte, error) {
+	if backupEncryptionKeyProvider == "" {
+		return nil, nil, nil
+	}
+	kp, ok := BackupKeyProviderMap[backupEncryptionKeyProvider]
+	if !ok {
+		return nil, nil, vterrors.Errorf(vtrpc.Code_INVALID_ARGUMENT, "unknown backup encryption key provider %q", backupEncryptionKeyProvider)
+	}
+	dataKey := make([]byte, backupDataKeySize)
+	if _, err := rand.Read(dataKey); err != nil {
+		return nil, nil, vterrors.Wrap(err, "cannot generate backup data key")
+	}
+	keyID, wrappedKey, err := kp.WrapKey(ctx, dataKey)
+	if err != nil {
+		return nil, nil, vterrors.Wrapf(err, "cannot wrap backup data key with key provider %q", backupEncryptionKeyProvider)
+	}
+	return &BackupEncryption{
+		Algorithm:   BackupEncryptionAlgorithm,
+		KeyProvider: backupEncryptionKeyProvider,
+		KeyID:       keyID,
+		WrappedKey:  wrappedKey,
+	}, dataKey, nil
+}
+
+// dataKey unwraps the data key of the backup with the key provider that
+// wrapped it.
+func (e *BackupEncryption) dataKey(ctx context.Context) ([]byte, error) {
+	if e.Algorithm != BackupEncryptionAlgorithm {
+		return nil, vterrors.Errorf(vtrpc.Code_FAILED_PRECONDITION, "unsupported backup encryption algorithm %q", e.Algorithm)
+	}
+	kp, ok := BackupKeyProviderMap[e.KeyProvider]
+	if !ok {
+		return nil, vterrors.Errorf(vtrpc.Code_FAILED_PRECONDITION, "unknown backup encryption key provider %q", e.KeyProvider)
+	}
+	dataKey, err := kp.UnwrapKey(ctx, e.KeyID, e.WrappedKey)
+	if err != nil {
+		return nil, vterrors.Wrapf(err, "cannot unwrap backup data key with key provider %q", e.KeyProvider)
+	}
+	if len(dataKey) != backupDataKeySize {
+		return nil, vterrors.Errorf(vtrpc.Code_FAILED_PRECONDITION, "invalid backup data key size %d", len(dataKey))
+	}
+	return dataKey, nil
+}
+
+// fileBackupKeyProvider wraps data keys with AES-GCM, using a 256-bit key read
+// from --backup-encryption-key-file. The ID of the key is derived from its
+// hash, so that a restore with the wrong key file fails with a clear error.
+type fileBackupKeyProvider struct{}
+
+func (fileBackupKeyProvider) readKey() (key []byte, keyID string, err error) {
+	if backupEncryptionKeyFile == "" {
+		return nil, "", vterrors.Errorf(vtrpc.Code_FAILED_PRECONDITION, "--backup-encryption-key-file is required by the %q backup key provider", fileBackupKeyProviderName)
+	}
+	data, err := os.ReadFile(backupEncryptionKeyFile)
+	if err != nil {
+		return nil, "", vterrors.Wrap(err, "cannot read backup encryption key file")
+	}
+	key, err = hex.DecodeString(strings.TrimSpace(string(data)))
+	if err != nil || len(key) != 32 {
+		return nil, "", vterrors.Errorf(vtrpc.Code_FAILED_PRECONDITION, "backup encryption key file %v must hold a hex-encoded 256-bit key", backupEncryptionKeyFile)
+	}
+	sum := sha256.Sum256(key)
+	return key, hex.EncodeToString(sum[:8]), nil
+}
+
+// WrapKey is part of the BackupKeyProvider interface.
+func (p fileBackupKeyProvider) WrapKey(ctx context.Context, dataKey []byte) (string, []byte, error) {
+	key, keyID, err := p.readKey()
+	if err != nil {
+		return "", nil, err
+	}
+	aead, err := newAESGCM(key)
+	if err != nil {
+		return "", nil, err
+	}
+	nonce := make([]byte, aead.NonceSize())
+	if _, err := rand.Read(nonce); err != nil {
+		return "", nil, err
+	}
+	return keyID, aead.Seal(nonce, nonce, dataKey, []byte(keyID)), nil
+}
+
+// UnwrapKey is part of the BackupKeyProvider interface.
+func (p fileBackupKeyProvider) UnwrapKey(ctx context.Context, keyID string, wrappedKey []byte) ([]byte, error) {
+	key, fileKeyID, err := p.readKey()
+	if err != nil {
+		return nil, err
+	}
+	if keyID != fileKeyID {
+		return nil, vterrors.Errorf(vtrpc.Code_FAILED_PRECONDITION, "backup data key was wrapped by key %v, but the backup encryption key file holds key %v", keyID, fileKeyID)
+	}
+	aead, err := newAESGCM(key)
+	if err != nil {
+		return nil, err
+	}
+	if len(wrappedKey) < aead.NonceSize() {
+		return nil, vterrors.Errorf(vtrpc.Code_FAILED_PRECONDITION, "wrapped backup data key is too short")
+	}
+	nonce, sealed := wrappedKey[:aead.NonceSize()], wrappedKey[aead.NonceSize():]
+	dataKey, err := aead.Open(nil, nonce, sealed, []byte(keyID))
+	if err != nil {
+		return nil, vterrors.Wrap(err, "cannot unwrap backup data key")
+	}
+	return dataKey, nil
+}
+
+func newAESGCM(key []byte) (cipher.AEAD, error) {
+	block, err := aes.NewCipher(key)
+	if err != nil {
+		return nil, err
+	}
+	return cipher.NewGCM(block)
+}
+
+// newBackupFileAEAD derives the key of a backup file from the data key of the
+// backup, the random salt of the file and its name, so that files can neither
+// share a key nor be swapped with each other.
+func newBackupFileAEAD(dataKey []byte, salt []byte, filename string) (cipher.AEAD, error) {
+	fileKey, err := hkdf.Key(sha256.New, dataKey, salt, "vitess backup file "+filename, 32)
+	if err != nil {
+		return nil, err
+	}
+	return newAESGCM(fileKey)
+}
+
+// chunkNonce returns the nonce of the given chunk of a file. The last chunk is
+// flagged, so that a truncated file cannot pass for a complete one.
+func chunkNonce(nonce []byte, counter uint64, last bool) []byte {
+	clear(nonce)
+	binary.BigEndian.PutUint64(nonce[3:11], counter)
+	if last {
+		nonce[11] = 1
+	}
+	return nonce
+}
+
+// encryptingWriter encrypts a backup file as a header holding the magic and
+// the salt of the file, followed by chunks sealed with AES-GCM.
+type encryptingWriter struct {
+	w       io.WriteCloser
+	aead    cipher.AEAD
+	nonce   []byte
+	counter uint64
+	buf     []byte
+	sealed  []byte
+}
+
+func newEncryptingWriter(w io.WriteCloser, dataKey []byte, filename string) (*encryptingWriter, error) {
+	salt := make([]byte, encryptedFileSaltSize)
+	if _, err := rand.Read(salt); err != nil {
+		return nil, err
+	}
+	aead, err := newBackupFileAEAD(dataKey, salt, filename)
+	if err != nil {
+		return nil, err
+	}
+	if _, err := w.Write(append([]byte(encryptedFileMagic), salt...)); err != nil {
+		return nil, err
+	}
+	return &encryptingWriter{
+		w:      w,
+		aead:   aead,
+		nonce:  make([]byte, aead.NonceSize()),
+		buf:    make([]byte, 0, encryptedChunkSize),
+		sealed: make([]byte, 0, encryptedChunkSize+aead.Overhead()),
+	}, nil
+}
+
+func (ew *encryptingWriter) seal(last bool) error {
+	ew.sealed = ew.aead.Seal(ew.sealed[:0], chunkNonce(ew.nonce, ew.counter, last), ew.buf, nil)
+	ew.counter++
+	ew.buf = ew.buf[:0]
+	_, err := ew.w.Write(ew.sealed)
+	return err
+}
+
+// Write is part of the io.Writer interface. A full chunk is only sealed once
+// more data follows it, since the last chunk is sealed on Close.
+func (ew *encryptingWriter) Write(p []byte) (int, error) {
+	n := len(p)
+	for len(p) > 0 {
+		if len(ew.buf) == encryptedChunkSize {
+			if err := ew.seal(false); err != nil {
+				return n - len(p), err
+			}
+		}
+		copied := copy(ew.buf[len(ew.buf):encryptedChunkSize], p)
+		ew.buf = ew.buf[:len(ew.buf)+copied]
+		p = p[copied:]
+	}
+	return n, nil
+}
+
+// Close is part of the io.Closer interface.
+func (ew *encryptingWriter) Close() error {
+	err := ew.seal(true)
+	return errors.Join(err, ew.w.Close())
+}
+
+// decryptingReader decrypts a backup file written by an encryptingWriter.
+type decryptingReader struct {
+	r       io.ReadCloser
+	br      *bufio.Reader
+	aead    cipher.AEAD
+	nonce   []byte
+	counter uint64
+	sealed  []byte
+	buf     []byte
+	done    bool
+	err     error
+}
+
+func newDecryptingReader(r io.ReadCloser, dataKey []byte, filename string) (*decryptingReader, error) {
+	br := bufio.NewReader(r)
+	header := make([]byte, len(encryptedFileMagic)+encryptedFileSaltSize)
+	if _, err := io.ReadFull(br, header); err != nil {
+		return nil, vterrors.Wrapf(err, "cannot read encryption header of backup file %v", filename)
+	}
+	if !bytes.HasPrefix(header, []byte(encryptedFileMagic)) {
+		return nil, vterrors.Errorf(vtrpc.Code_FAILED_PRECONDITION, "backup file %v is not encrypted", filename)
+	}
+	aead, err := newBackupFileAEAD(dataKey, header[len(encryptedFileMagic):], filename)
+	if err != nil {
+		return nil, err
+	}
+	return &decryptingReader{
+		r:      r,
+		br:     br,
+		aead:   aead,
+		nonce:  make([]byte, aead.NonceSize()),
+		sealed: make([]byte, encryptedChunkSize+aead.Overhead()),
+	}, nil
+}
+
+// open reads and decrypts the next chunk of the file.
+func (dr *decryptingReader) open() error {
+	n, err := io.ReadFull(dr.br, dr.sealed)
+	switch {
+	case err == io.EOF:
+		return io.ErrUnexpectedEOF
+	case err == io.ErrUnexpectedEOF:
+		// A partial chunk is the last one.
+		dr.done = true
+	case err != nil:
+		return err
+	default:
+		// A full chunk is the last one if nothing follows it.
+		if _, err := dr.br.Peek(1); err == io.EOF {
+			dr.done = true
+		} else if err != nil {
+			return err
+		}
+	}
+	dr.buf, err = dr.aead.Open(dr.sealed[:0], chunkNonce(dr.nonce, dr.counter, dr.done), dr.sealed[:n], nil)
+	if err != nil {
+		return vterrors.Errorf(vtrpc.Code_DATA_LOSS, "backup file chunk %d failed authentication, the file is corrupt or truncated", dr.counter)
+	}
+	dr.counter++
+	return nil
+}
+
+// Read is part of the io.Reader interface.
+func (dr *decryptingReader) Read(p []byte) (int, error) {
+	for len(dr.buf) == 0 {
+		if dr.err != nil {
+			return 0, dr.err
+		}
+		if dr.done {
+			return 0, io.EOF
+		}
+		dr.err = dr.open()
+	}
+	n := copy(p, dr.buf)
+	dr.buf = dr.buf[n:]
+	return n, nil
+}
+
+// Close is part of the io.Closer interface.
+func (dr *decryptingReader) Close() error {
+	return dr.r.Close()
+}
+
+// encryptedBackupHandle encrypts the files added to a backup, and decrypts the
+// files read from it. The MANIFEST is kept in clear, since it holds what is
+// needed to decrypt the other files.
+type encryptedBackupHandle struct {
+	backupstorage.BackupHandle
+	dataKey []byte
+}
+
+// newEncryptedBackupHandle wraps the handle of a new backup so that its files
+// are encrypted with the data key, and returns the handle as is if the data key
+// is nil.
+func newEncryptedBackupHandle(bh backupstorage.BackupHandle, dataKey []byte) backupstorage.BackupHandle {
+	if dataKey == nil {
+		return bh
+	}
+	return &encryptedBackupHandle{BackupHandle: bh, dataKey: dataKey}
+}
+
+// decryptedBackupHandle unwraps the data key of the backup described by the
+// manifest, and wraps its handle so that its files are decrypted. The handle
+// is returned as is if the backup is not encrypted.
+func decryptedBackupHandle(ctx context.Context, bh backupstorage.BackupHandle, manifest *BackupManifest) (backupstorage.BackupHandle, error) {
+	if manifest == nil || manifest.Encryption == nil {
+		return bh, nil
+	}
+	dataKey, err := manifest.Encryption.dataKey(ctx)
+	if err != nil {
+		return nil, vterrors.Wrapf(err, "cannot decrypt backup %v", bh.Name())
+	}
+	return &encryptedBackupHandle{BackupHandle: bh, dataKey: dataKey}, nil
+}
+
+// encryptedFileSize returns the size of a backup file of the given size once
+// encrypted: its header, followed by its chunks with their tags. A file holds
+// at least one chunk, since the last chunk is always sealed, even if empty.
+func encryptedFileSize(filesize int64) int64 {
+	if filesize < 0 {
+		return filesize
+	}
+	chunks := max((filesize+encryptedChunkSize-1)/encryptedChunkSize, 1)
+	return int64(len(encryptedFileMagic)+encryptedFileSaltSize) + filesize + chunks*encryptedChunkOverhead
+}
+
+// AddFile is part of the BackupHandle interface.
+func (bh *encryptedBackupHandle) AddFile(ctx context.Context, filename string, filesize int64) (io.WriteCloser, error) {
+	if filename == backupManifestFileName {
+		return bh.BackupHandle.AddFile(ctx, filename, filesize)
+	}
+	// Storages such as S3 size their uploads from the file size, which must
+	// account for the encryption overhead.
+	wc, err := bh.BackupHandle.AddFile(ctx, filename, encryptedFileSize(filesize))
+	if err != nil {
+		return nil, err
+	}
+	ew, err := newEncryptingWriter(wc, bh.dataKey, filename)
+	if err != nil {
+		return nil, errors.Join(vterrors.Wrapf(err, "cannot encrypt backup file %v", filename), wc.Close())
+	}
+	return ew, nil
+}
+
+// ReadFile is part of the BackupHandle interface.
+func (bh *encryptedBackupHandle) ReadFile(ctx context.Context, filename string) (io.ReadCloser, error) {
+	rc, err := bh.BackupHandle.ReadFile(ctx, filename)
+	if err != nil || filename == backupManifestFileName {
+		return rc, err
+	}
+	dr, err := newDecryptingReader(rc, bh.dataKey, filename)
+	if err != nil {
+		return nil, errors.Join(err, rc.Close())
+	}
+	return dr, nil
+}
+
+var _ backupstorage.BackupHandle = (*encryptedBackupHandle)(nil)
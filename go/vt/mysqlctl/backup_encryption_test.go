@@ -0,0 +1,223 @@
+/*
+Copyright 2025 The Vitess Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package mysqlctl
+
+import (
+	"bytes"
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"encoding/json"
+	"io"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// memoryFile is a backup file written to memory.
+type memoryFile struct {
+	bytes.Buffer
+	closed bool
+}
+
+func (f *memoryFile) Close() error {
+	f.closed = true
+	return nil
+}
+
+// newMemoryBackupHandle returns a fake backup handle that keeps the files
+// added to it in memory, and serves them back on ReadFile.
+func newMemoryBackupHandle(files map[string]*memoryFile) *FakeBackupHandle {
+	return &FakeBackupHandle{
+		AddFileReturnF: func(filename string) FakeBackupHandleAddFileReturn {
+			files[filename] = &memoryFile{}
+			return FakeBackupHandleAddFileReturn{WriteCloser: files[filename]}
+		},
+		ReadFileReturnF: func(ctx context.Context, filename string) (io.ReadCloser, error) {
+			return io.NopCloser(bytes.NewReader(files[filename].Bytes())), nil
+		},
+	}
+}
+
+// setTestBackupEncryptionKey writes a random key file, and enables backup
+// encryption with the file key provider for the duration of the test.
+func setTestBackupEncryptionKey(t *testing.T) string {
+	key := make([]byte, 32)
+	_, err := rand.Read(key)
+	require.NoError(t, err)
+	keyFile := filepath.Join(t.TempDir(), "backup.key")
+	require.NoError(t, os.WriteFile(keyFile, []byte(hex.EncodeToString(key)+"\n"), 0o600))
+
+	previousKeyProvider, previousKeyFile := backupEncryptionKeyProvider, backupEncryptionKeyFile
+	backupEncryptionKeyProvider, backupEncryptionKeyFile = fileBackupKeyProviderName, keyFile
+	t.Cleanup(func() {
+		backupEncryptionKeyProvider, backupEncryptionKeyFile = previousKeyProvider, previousKeyFile
+	})
+	return keyFile
+}
+
+func TestNewBackupEncryption(t *testing.T) {
+	ctx := context.Background()
+
+	encryption, dataKey, err := newBackupEncryption(ctx)
+	require.NoError(t, err)
+	assert.Nil(t, encryption)
+	assert.Nil(t, dataKey)
+
+	setTestBackupEncryptionKey(t)
+	encryption, dataKey, err = newBackupEncryption(ctx)
+	require.NoError(t, err)
+	require.NotNil(t, encryption)
+	assert.Equal(t, BackupEncryptionAlgorithm, encryption.Algorithm)
+	assert.Equal(t, fileBackupKeyProviderName, encryption.KeyProvider)
+	assert.Len(t, dataKey, backupDataKeySize)
+	assert.NotContains(t, string(encryption.WrappedKey), string(dataKey))
+
+	// The encryption details survive the MANIFEST encoding.
+	data, err := json.Marshal(&BackupManifest{Encryption: encryption})
+	require.NoError(t, err)
+	manifest := &BackupManifest{}
+	require.NoError(t, json.Unmarshal(data, manifest))
+	unwrapped, err := manifest.Encryption.dataKey(ctx)
+	require.NoError(t, err)
+	assert.Equal(t, dataKey, unwrapped)
+
+	// Unencrypted backups leave no trace of encryption in their MANIFEST.
+	data, err = json.Marshal(&BackupManifest{})
+	require.NoError(t, err)
+	assert.NotContains(t, string(data), "Encryption")
+
+	// Restoring with another key fails.
+	setTestBackupEncryptionKey(t)
+	_, err = manifest.Encryption.dataKey(ctx)
+	assert.ErrorContains(t, err, "backup encryption key file holds key")
+
+	backupEncryptionKeyProvider = "unknown"
+	_, _, err = newBackupEncryption(ctx)
+	assert.ErrorContains(t, err, `unknown backup encryption key provider "unknown"`)
+}
+
+func TestEncryptedBackupHandle(t *testing.T) {
+	ctx := context.Background()
+	setTestBackupEncryptionKey(t)
+	encryption, dataKey, err := newBackupEncryption(ctx)
+	require.NoError(t, err)
+
+	// Sizes around the chunk size exercise full, partial and empty last chunks.
+	contents := map[string][]byte{}
+	for name, size := range map[string]int{
+		"empty":      0,
+		"small":      100,
+		"one-chunk":  encryptedChunkSize,
+		"two-chunks": 2 * encryptedChunkSize,
+		"large":      3*encryptedChunkSize + 17,
+	} {
+		contents[name] = make([]byte, size)
+		_, err := rand.Read(contents[name])
+		require.NoError(t, err)
+	}
+
+	files := map[string]*memoryFile{}
+	fbh := newMemoryBackupHandle(files)
+	bh := newEncryptedBackupHandle(fbh, dataKey)
+	for name, content := range contents {
+		wc, err := bh.AddFile(ctx, name, int64(len(content)))
+		require.NoError(t, err)
+		// Write in uneven pieces.
+		for remaining := content; len(remaining) > 0; {
+			n := min(len(remaining), 1000+len(remaining)/3)
+			_, err := wc.Write(remaining[:n])
+			require.NoError(t, err)
+			remaining = remaining[n:]
+		}
+		require.NoError(t, wc.Close())
+		assert.True(t, files[name].closed)
+		if len(content) > 0 {
+			assert.NotContains(t, files[name].String(), string(content[:16]))
+		}
+		// The storage is given the size of the encrypted file.
+		call := fbh.AddFileCalls[len(fbh.AddFileCalls)-1]
+		assert.EqualValues(t, files[name].Len(), call.Filesize, name)
+	}
+	wc, err := bh.AddFile(ctx, backupManifestFileName, -1)
+	require.NoError(t, err)
+	_, err = wc.Write([]byte(`{"BackupMethod":"builtin"}`))
+	require.NoError(t, err)
+	require.NoError(t, wc.Close())
+	assert.Equal(t, `{"BackupMethod":"builtin"}`, files[backupManifestFileName].String(), "the MANIFEST is not encrypted")
+
+	rbh, err := decryptedBackupHandle(ctx, newMemoryBackupHandle(files), &BackupManifest{Encryption: encryption})
+	require.NoError(t, err)
+	for name, content := range contents {
+		rc, err := rbh.ReadFile(ctx, name)
+		require.NoError(t, err)
+		data, err := io.ReadAll(rc)
+		require.NoError(t, err, name)
+		assert.Equal(t, content, data, name)
+		require.NoError(t, rc.Close())
+	}
+	manifest, err := GetBackupManifest(ctx, rbh)
+	require.NoError(t, err)
+	assert.Equal(t, builtinBackupEngineName, manifest.BackupMethod)
+
+	t.Run("unencrypted backups are read as is", func(t *testing.T) {
+		mbh := newMemoryBackupHandle(files)
+		rbh, err := decryptedBackupHandle(ctx, mbh, &BackupManifest{})
+		require.NoError(t, err)
+		assert.Same(t, mbh, rbh)
+	})
+
+	readAll := func(filename string, data []byte) error {
+		files := map[string]*memoryFile{filename: {}}
+		files[filename].Write(data)
+		rbh, err := decryptedBackupHandle(ctx, newMemoryBackupHandle(files), &BackupManifest{Encryption: encryption})
+		require.NoError(t, err)
+		rc, err := rbh.ReadFile(ctx, filename)
+		if err != nil {
+			return err
+		}
+		defer rc.Close()
+		_, err = io.ReadAll(rc)
+		return err
+	}
+
+	t.Run("tampered file", func(t *testing.T) {
+		data := bytes.Clone(files["large"].Bytes())
+		data[len(data)/2] ^= 1
+		assert.ErrorContains(t, readAll("large", data), "failed authentication")
+	})
+
+	t.Run("truncated file", func(t *testing.T) {
+		data := files["two-chunks"].Bytes()
+		// Dropping the last chunk of a file leaves a file of full chunks,
+		// whose last one is not flagged as such.
+		sealedChunkSize := encryptedChunkSize + 16
+		assert.ErrorContains(t, readAll("two-chunks", data[:len(data)-sealedChunkSize]), "failed authentication")
+		assert.ErrorContains(t, readAll("large", files["large"].Bytes()[:100]), "failed authentication")
+	})
+
+	t.Run("swapped files", func(t *testing.T) {
+		assert.ErrorContains(t, readAll("small", files["large"].Bytes()), "failed authentication")
+	})
+
+	t.Run("unencrypted file", func(t *testing.T) {
+		assert.ErrorContains(t, readAll("small", contents["large"]), "is not encrypted")
+	})
+}
@@ -0,0 +1,254 @@
+/*
+Copyright 2025 The Vitess Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package mysqlctl
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"strings"
+	"time"
+
+	"vitess.io/vitess/go/mysql/replication"
+	"vitess.io/vitess/go/vt/mysqlctl/backupstorage"
+	"vitess.io/vitess/go/vt/proto/vtrpc"
+	"vitess.io/vitess/go/vt/vterrors"
+)
+
+// This file computes which backups of a shard can be restored, and which of
+// them a retention policy keeps.
+
+// BackupRetentionRule retains one backup per Interval, among the backups taken
+// within Window of the time the policy is applied.
+type BackupRetentionRule struct {
+	Interval time.Duration
+	Window   time.Duration
+}
+
+// ParseBackupRetentionRule parses a rule in the <interval>:<window> format,
+// e.g. "1h:48h" retains hourly backups for two days.
+func ParseBackupRetentionRule(s string) (BackupRetentionRule, error) {
+	intervalStr, windowStr, ok := strings.Cut(s, ":")
+	if !ok {
+		return BackupRetentionRule{}, vterrors.Errorf(vtrpc.Code_INVALID_ARGUMENT, "invalid backup retention rule %q, expected <interval>:<window>", s)
+	}
+	interval, err := time.ParseDuration(intervalStr)
+	if err != nil {
+		return BackupRetentionRule{}, vterrors.Wrapf(err, "invalid interval in backup retention rule %q", s)
+	}
+	window, err := time.ParseDuration(windowStr)
+	if err != nil {
+		return BackupRetentionRule{}, vterrors.Wrapf(err, "invalid window in backup retention rule %q", s)
+	}
+	rule := BackupRetentionRule{Interval: interval, Window: window}
+	if err := rule.Validate(); err != nil {
+		return BackupRetentionRule{}, err
+	}
+	return rule, nil
+}
+
+// Validate returns an error if the rule cannot retain any backup.
+func (r BackupRetentionRule) Validate() error {
+	if r.Interval <= 0 {
+		return vterrors.Errorf(vtrpc.Code_INVALID_ARGUMENT, "backup retention interval must be positive, got %v", r.Interval)
+	}
+	if r.Window < r.Interval {
+		return vterrors.Errorf(vtrpc.Code_INVALID_ARGUMENT, "backup retention window %v must not be shorter than its interval %v", r.Window, r.Interval)
+	}
+	return nil
+}
+
+func (r BackupRetentionRule) String() string {
+	return fmt.Sprintf("%v:%v", r.Interval, r.Window)
+}
+
+// BackupRetentionPolicy is a set of time-window rules, e.g. hourly backups for
+// two days and daily backups for thirty days. A backup is retained if any of
+// the rules retains it.
+type BackupRetentionPolicy []BackupRetentionRule
+
+// ExpireBackups applies the policy at the given time, and splits the manifests
+// into the backups to retain and the backups to expire.
+//
+// Only backups that can be restored are candidates for the rules, and each rule
+// keeps the oldest candidate of every interval, so that a retained backup stays
+// retained until it falls out of the window. The most recent full backup and
+// the most recent restorable backup are always retained. Retaining a backup
+// also retains the full and incremental backups of its restore chain, so that
+// expiring backups never breaks the restore path of a retained one.
+func (p BackupRetentionPolicy) ExpireBackups(now time.Time, manifests []*BackupManifest) (retained []*BackupManifest, expired []*BackupManifest, err error) {
+	for _, rule := range p {
+		if err := rule.Validate(); err != nil {
+			return nil, nil, err
+		}
+	}
+	sorted, times, err := sortManifestsByBackupTime(manifests)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	keep := make(map[*BackupManifest]bool, len(sorted))
+	retain := func(manifest *BackupManifest, chain []*BackupManifest) {
+		keep[manifest] = true
+		for _, m := range chain {
+			keep[m] = true
+		}
+	}
+
+	// chains maps each restorable backup to its restore chain.
+	chains := make(map[*BackupManifest][]*BackupManifest, len(sorted))
+	var latestFull, latestRestorable *BackupManifest
+	for _, manifest := range sorted {
+		chain, err := backupRestoreChain(manifest, sorted)
+		if err != nil {
+			continue
+		}
+		chains[manifest] = chain
+		latestRestorable = manifest
+		if !manifest.Incremental {
+			latestFull = manifest
+		}
+	}
+	if latestFull != nil {
+		retain(latestFull, nil)
+	}
+	if latestRestorable != nil {
+		retain(latestRestorable, chains[latestRestorable])
+	}
+
+	for _, rule := range p {
+		buckets := make(map[int64]bool)
+		for i, manifest := range sorted {
+			chain, ok := chains[manifest]
+			if !ok || now.Sub(times[i]) >= rule.Window {
+				continue
+			}
+			bucket := times[i].UnixNano() / int64(rule.Interval)
+			if buckets[bucket] {
+				continue
+			}
+			buckets[bucket] = true
+			retain(manifest, chain)
+		}
+	}
+
+	for _, manifest := range sorted {
+		if keep[manifest] {
+			retained = append(retained, manifest)
+		} else {
+			expired = append(expired, manifest)
+		}
+	}
+	return retained, expired, nil
+}
+
+// BackupChainGap describes an incremental backup that cannot be restored,
+// because no chain of backups taken before it leads to its FromPosition.
+type BackupChainGap struct {
+	Manifest *BackupManifest
+	// MissingGTIDSet holds the GTIDs of the FromPosition of the backup that no
+	// restorable backup taken before it contains. It is nil if the flavor of the
+	// backup does not support computing it.
+	MissingGTIDSet replication.GTIDSet
+}
+
+// FindBackupChainGaps validates the continuity of the incremental backup
+// chains, and returns the incremental backups that cannot be restored, in the
+// order they were taken.
+func FindBackupChainGaps(manifests []*BackupManifest) ([]*BackupChainGap, error) {
+	sorted, _, err := sortManifestsByBackupTime(manifests)
+	if err != nil {
+		return nil, err
+	}
+
+	var (
+		gaps    []*BackupChainGap
+		covered replication.GTIDSet
+	)
+	for _, manifest := range sorted {
+		if _, err := backupRestoreChain(manifest, sorted); err == nil {
+			if covered == nil {
+				covered = manifest.Position.GTIDSet
+			} else {
+				covered = covered.Union(manifest.Position.GTIDSet)
+			}
+			continue
+		}
+		gap := &BackupChainGap{Manifest: manifest}
+		if from, ok := manifest.FromPosition.GTIDSet.(replication.Mysql56GTIDSet); ok {
+			gap.MissingGTIDSet = from
+			if covered, ok := covered.(replication.Mysql56GTIDSet); ok {
+				gap.MissingGTIDSet = from.Difference(covered)
+			}
+		}
+		gaps = append(gaps, gap)
+	}
+	return gaps, nil
+}
+
+// ReadBackupManifests reads the manifests of the given backups. Backups whose
+// MANIFEST cannot be read, typically because they are still in progress or
+// failed, are returned as incomplete.
+func ReadBackupManifests(ctx context.Context, bhs []backupstorage.BackupHandle) (manifests []*BackupManifest, manifestHandleMap *ManifestHandleMap, incomplete []backupstorage.BackupHandle) {
+	manifestHandleMap = NewManifestHandleMap()
+	for _, bh := range bhs {
+		manifest, err := GetBackupManifest(ctx, bh)
+		if err != nil {
+			incomplete = append(incomplete, bh)
+			continue
+		}
+		manifests = append(manifests, manifest)
+		manifestHandleMap.Map(manifest, bh)
+	}
+	return manifests, manifestHandleMap, incomplete
+}
+
+// backupRestoreChain returns the chain of backups to restore in order to
+// restore the given backup: the backup itself if it is a full backup, or the
+// shortest path from a full backup to its position if it is incremental.
+func backupRestoreChain(manifest *BackupManifest, manifests []*BackupManifest) ([]*BackupManifest, error) {
+	if !manifest.Incremental {
+		return []*BackupManifest{manifest}, nil
+	}
+	return FindPITRPath(manifest.Position.GTIDSet, manifests)
+}
+
+// sortManifestsByBackupTime returns the manifests sorted by the time their
+// backup was taken, along with these times.
+func sortManifestsByBackupTime(manifests []*BackupManifest) ([]*BackupManifest, []time.Time, error) {
+	sorted := make([]*BackupManifest, 0, len(manifests))
+	backupTimes := make(map[*BackupManifest]time.Time, len(manifests))
+	for _, manifest := range manifests {
+		if manifest == nil {
+			continue
+		}
+		backupTime, err := ParseRFC3339(manifest.BackupTime)
+		if err != nil {
+			return nil, nil, vterrors.Wrapf(err, "parsing manifest BackupTime %s", manifest.BackupTime)
+		}
+		sorted = append(sorted, manifest)
+		backupTimes[manifest] = backupTime
+	}
+	sort.SliceStable(sorted, func(i, j int) bool {
+		return backupTimes[sorted[i]].Before(backupTimes[sorted[j]])
+	})
+	times := make([]time.Time, len(sorted))
+	for i, manifest := range sorted {
+		times[i] = backupTimes[manifest]
+	}
+	return sorted, times, nil
+}
@@ -0,0 +1,203 @@
+/*
+Copyright 2025 The Vitess Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package mysqlctl
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"vitess.io/vitess/go/mysql/replication"
+)
+
+func TestParseBackupRetentionRule(t *testing.T) {
+	tcases := []struct {
+		rule   string
+		expect BackupRetentionRule
+		err    string
+	}{
+		{rule: "1h:48h", expect: BackupRetentionRule{Interval: time.Hour, Window: 48 * time.Hour}},
+		{rule: "24h:720h", expect: BackupRetentionRule{Interval: 24 * time.Hour, Window: 720 * time.Hour}},
+		{rule: "1h", err: "expected <interval>:<window>"},
+		{rule: "1x:48h", err: "invalid interval"},
+		{rule: "1h:48x", err: "invalid window"},
+		{rule: "0s:48h", err: "must be positive"},
+		{rule: "48h:1h", err: "must not be shorter than its interval"},
+	}
+	for _, tcase := range tcases {
+		t.Run(tcase.rule, func(t *testing.T) {
+			rule, err := ParseBackupRetentionRule(tcase.rule)
+			if tcase.err != "" {
+				assert.ErrorContains(t, err, tcase.err)
+				return
+			}
+			require.NoError(t, err)
+			assert.Equal(t, tcase.expect, rule)
+		})
+	}
+}
+
+// retentionTestBackups builds the manifests of backups taken at given ages
+// relative to now, and keeps track of their names.
+type retentionTestBackups struct {
+	now       time.Time
+	manifests []*BackupManifest
+	names     map[*BackupManifest]string
+}
+
+func newRetentionTestBackups(now time.Time) *retentionTestBackups {
+	return &retentionTestBackups{now: now, names: map[*BackupManifest]string{}}
+}
+
+func (b *retentionTestBackups) position(posRange string) replication.Position {
+	return replication.MustParsePosition(replication.Mysql56FlavorID, "16b1039f-22b6-11ed-b765-0a43f95f28a3:"+posRange)
+}
+
+func (b *retentionTestBackups) full(name string, age time.Duration, pos string) {
+	m := &BackupManifest{
+		BackupMethod: builtinBackupEngineName,
+		Position:     b.position(pos),
+		BackupTime:   FormatRFC3339(b.now.Add(-age)),
+	}
+	b.manifests = append(b.manifests, m)
+	b.names[m] = name
+}
+
+func (b *retentionTestBackups) incremental(name string, age time.Duration, fromPos string, pos string) {
+	m := &BackupManifest{
+		BackupMethod: builtinBackupEngineName,
+		Incremental:  true,
+		FromPosition: b.position(fromPos),
+		Position:     b.position(pos),
+		BackupTime:   FormatRFC3339(b.now.Add(-age)),
+	}
+	b.manifests = append(b.manifests, m)
+	b.names[m] = name
+}
+
+func (b *retentionTestBackups) namesOf(manifests []*BackupManifest) []string {
+	names := make([]string, 0, len(manifests))
+	for _, m := range manifests {
+		names = append(names, b.names[m])
+	}
+	return names
+}
+
+func TestExpireBackups(t *testing.T) {
+	now := time.Date(2025, 3, 10, 12, 30, 0, 0, time.UTC)
+	day := 24 * time.Hour
+
+	t.Run("daily window", func(t *testing.T) {
+		b := newRetentionTestBackups(now)
+		b.full("full-10d", 10*day, "1-100")
+		b.full("full-5d", 5*day+time.Hour, "1-500")
+		b.full("full-5d-later", 5*day, "1-510")
+		b.full("full-1d", day, "1-900")
+		b.full("full-now", time.Minute, "1-1000")
+
+		retained, expired, err := BackupRetentionPolicy{{Interval: day, Window: 7 * day}}.ExpireBackups(now, b.manifests)
+		require.NoError(t, err)
+		// The oldest backup of each day within the window is retained.
+		assert.Equal(t, []string{"full-5d", "full-1d", "full-now"}, b.namesOf(retained))
+		assert.Equal(t, []string{"full-10d", "full-5d-later"}, b.namesOf(expired))
+	})
+
+	t.Run("latest full backup is always retained", func(t *testing.T) {
+		b := newRetentionTestBackups(now)
+		b.full("full-20d", 20*day, "1-100")
+		b.full("full-10d", 10*day, "1-200")
+
+		retained, expired, err := BackupRetentionPolicy{{Interval: time.Hour, Window: day}}.ExpireBackups(now, b.manifests)
+		require.NoError(t, err)
+		assert.Equal(t, []string{"full-10d"}, b.namesOf(retained))
+		assert.Equal(t, []string{"full-20d"}, b.namesOf(expired))
+	})
+
+	t.Run("incremental chains are kept whole", func(t *testing.T) {
+		b := newRetentionTestBackups(now)
+		b.full("full-3d", 3*day, "1-100")
+		b.incremental("inc-3d-1", 3*day-time.Hour, "1-100", "1-150")
+		b.incremental("inc-3d-2", 3*day-2*time.Hour, "1-150", "1-200")
+		b.full("full-2d", 2*day, "1-300")
+		b.incremental("inc-2d-1", 2*day-time.Hour, "1-300", "1-350")
+		b.incremental("inc-1h", time.Hour, "1-350", "1-400")
+
+		// Hourly backups for the last two hours only retain the latest
+		// incremental backup, which depends on the chain that starts with the
+		// full backup taken two days ago.
+		retained, expired, err := BackupRetentionPolicy{{Interval: time.Hour, Window: 2 * time.Hour}}.ExpireBackups(now, b.manifests)
+		require.NoError(t, err)
+		assert.Equal(t, []string{"full-2d", "inc-2d-1", "inc-1h"}, b.namesOf(retained))
+		assert.Equal(t, []string{"full-3d", "inc-3d-1", "inc-3d-2"}, b.namesOf(expired))
+
+		// Retaining an incremental backup of the older chain retains its full
+		// backup and the incremental backups it depends on.
+		retained, expired, err = BackupRetentionPolicy{{Interval: time.Hour, Window: 3*day - 90*time.Minute}}.ExpireBackups(now, b.manifests)
+		require.NoError(t, err)
+		assert.Equal(t, []string{"full-3d", "inc-3d-1", "inc-3d-2", "full-2d", "inc-2d-1", "inc-1h"}, b.namesOf(retained))
+		assert.Empty(t, expired)
+	})
+
+	t.Run("broken incremental backups are not retained", func(t *testing.T) {
+		b := newRetentionTestBackups(now)
+		b.full("full-1d", day, "1-100")
+		b.incremental("inc-ok", 3*time.Hour, "1-100", "1-150")
+		b.incremental("inc-gap", 2*time.Hour, "1-170", "1-200")
+
+		retained, expired, err := BackupRetentionPolicy{{Interval: time.Hour, Window: 2 * day}}.ExpireBackups(now, b.manifests)
+		require.NoError(t, err)
+		assert.Equal(t, []string{"full-1d", "inc-ok"}, b.namesOf(retained))
+		assert.Equal(t, []string{"inc-gap"}, b.namesOf(expired))
+	})
+
+	t.Run("invalid rule", func(t *testing.T) {
+		_, _, err := BackupRetentionPolicy{{Interval: day, Window: time.Hour}}.ExpireBackups(now, nil)
+		assert.ErrorContains(t, err, "must not be shorter than its interval")
+	})
+
+	t.Run("invalid backup time", func(t *testing.T) {
+		_, _, err := BackupRetentionPolicy{{Interval: time.Hour, Window: day}}.ExpireBackups(now, []*BackupManifest{{BackupTime: "yesterday"}})
+		assert.ErrorContains(t, err, "parsing manifest BackupTime yesterday")
+	})
+}
+
+func TestFindBackupChainGaps(t *testing.T) {
+	now := time.Date(2025, 3, 10, 12, 30, 0, 0, time.UTC)
+
+	b := newRetentionTestBackups(now)
+	b.full("full", 10*time.Hour, "1-100")
+	b.incremental("inc-1", 9*time.Hour, "1-100", "1-150")
+	b.incremental("inc-2", 8*time.Hour, "1-150", "1-200")
+	b.incremental("inc-gap", 7*time.Hour, "1-230", "1-300")
+	b.incremental("inc-after-gap", 6*time.Hour, "1-300", "1-350")
+	b.full("full-later", 5*time.Hour, "1-400")
+	b.incremental("inc-3", 4*time.Hour, "1-400", "1-450")
+
+	gaps, err := FindBackupChainGaps(b.manifests)
+	require.NoError(t, err)
+	require.Len(t, gaps, 2)
+	assert.Equal(t, "inc-gap", b.names[gaps[0].Manifest])
+	assert.Equal(t, "16b1039f-22b6-11ed-b765-0a43f95f28a3:201-230", gaps[0].MissingGTIDSet.String())
+	assert.Equal(t, "inc-after-gap", b.names[gaps[1].Manifest])
+	assert.Equal(t, "16b1039f-22b6-11ed-b765-0a43f95f28a3:201-300", gaps[1].MissingGTIDSet.String())
+
+	gaps, err = FindBackupChainGaps(b.manifests[:3])
+	require.NoError(t, err)
+	assert.Empty(t, gaps)
+}
@@ -93,6 +93,8 @@ type BackupParams struct {
 	MysqlShutdownTimeout time.Duration
 	// BackupEngine allows us to override which backup engine should be used for a request
 	BackupEngine string
+	// Encryption is set when the backup is encrypted, and is recorded in its MANIFEST
+	Encryption *BackupEncryption
 }
 
 func (b *BackupParams) Copy() BackupParams {
@@ -111,6 +113,7 @@ func (b *BackupParams) Copy() BackupParams {
 		Stats:                b.Stats,
 		UpgradeSafe:          b.UpgradeSafe,
 		MysqlShutdownTimeout: b.MysqlShutdownTimeout,
+		Encryption:           b.Encryption,
 	}
 }
 
@@ -339,6 +342,9 @@ type BackupManifest struct {
 
 	// IncrementalDetails is nil for non-incremental backups
 	IncrementalDetails *IncrementalBackupDetails
+
+	// Encryption is nil for backups that are not encrypted
+	Encryption *BackupEncryption `json:",omitempty"`
 }
 
 func (m *BackupManifest) HashKey() string {
@@ -1021,6 +1021,7 @@ func (be *BuiltinBackupEngine) backupManifest(
 				MySQLVersion:       mysqlVersion,
 				UpgradeSafe:        params.UpgradeSafe,
 				IncrementalDetails: incrDetails,
+				Encryption:         params.Encryption,
 			},
 
 			// Builtin-specific fields
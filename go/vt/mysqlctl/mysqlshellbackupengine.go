@@ -119,6 +119,12 @@ func (be *MySQLShellBackupEngine) ExecuteBackup(ctx context.Context, params Back
 
 	location := path.Join(mysqlShellBackupLocation, bh.Directory(), bh.Name())
 
+	// mysqlsh writes the dump to the location by itself, bypassing the backup
+	// handle that encrypts the backup files.
+	if params.Encryption != nil {
+		return BackupUnusable, fmt.Errorf("%w: backup encryption is not supported by the %s backup engine", ErrMySQLShellPreCheck, mysqlShellBackupEngineName)
+	}
+
 	err := be.backupPreCheck(location)
 	if err != nil {
 		return BackupUnusable, vterrors.Wrap(err, "failed backup precheck")
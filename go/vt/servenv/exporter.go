@@ -17,6 +17,7 @@ limitations under the License.
 package servenv
 
 import (
+	"context"
 	"expvar"
 	"net/http"
 	"net/url"
@@ -646,6 +647,24 @@ func (tw *TimingsWrapper) Record(name string, startTime time.Time) {
 	tw.timings.Record([]string{tw.name, name}, startTime)
 }
 
+// AddContext behaves like Timings.AddContext.
+func (tw *TimingsWrapper) AddContext(ctx context.Context, name string, elapsed time.Duration) {
+	if tw.name == "" {
+		tw.timings.AddContext(ctx, []string{name}, elapsed)
+		return
+	}
+	tw.timings.AddContext(ctx, []string{tw.name, name}, elapsed)
+}
+
+// RecordContext behaves like Timings.RecordContext.
+func (tw *TimingsWrapper) RecordContext(ctx context.Context, name string, startTime time.Time) {
+	if tw.name == "" {
+		tw.timings.RecordContext(ctx, []string{name}, startTime)
+		return
+	}
+	tw.timings.RecordContext(ctx, []string{tw.name, name}, startTime)
+}
+
 // Counts behaves like Timings.Counts.
 func (tw *TimingsWrapper) Counts() map[string]int64 {
 	return tw.timings.Counts()
@@ -684,6 +703,26 @@ func (tw *MultiTimingsWrapper) Record(names []string, startTime time.Time) {
 	tw.timings.Record(newlabels, startTime)
 }
 
+// AddContext behaves like MultiTimings.AddContext.
+func (tw *MultiTimingsWrapper) AddContext(ctx context.Context, names []string, elapsed time.Duration) {
+	if tw.name == "" {
+		tw.timings.AddContext(ctx, names, elapsed)
+		return
+	}
+	newlabels := combineLabels(tw.name, names)
+	tw.timings.AddContext(ctx, newlabels, elapsed)
+}
+
+// RecordContext behaves like MultiTimings.RecordContext.
+func (tw *MultiTimingsWrapper) RecordContext(ctx context.Context, names []string, startTime time.Time) {
+	if tw.name == "" {
+		tw.timings.RecordContext(ctx, names, startTime)
+		return
+	}
+	newlabels := combineLabels(tw.name, names)
+	tw.timings.RecordContext(ctx, newlabels, startTime)
+}
+
 // Counts behaves lie MultiTimings.Counts.
 func (tw *MultiTimingsWrapper) Counts() map[string]int64 {
 	return tw.timings.Counts()
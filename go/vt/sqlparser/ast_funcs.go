@@ -2438,6 +2438,84 @@ func ContainsAggregation(e SQLNode) bool {
 	return hasAggregates
 }
 
+// GetOverClause returns the OVER clause of a window function call. Aggregate functions
+// only act as window functions when they are used with an OVER clause, so nil is returned
+// both for plain aggregations and for expressions that are not window functions at all.
+func GetOverClause(e SQLNode) *OverClause {
+	switch node := e.(type) {
+	case *Count:
+		return node.OverClause
+	case *CountStar:
+		return node.OverClause
+	case *Avg:
+		return node.OverClause
+	case *Max:
+		return node.OverClause
+	case *Min:
+		return node.OverClause
+	case *Sum:
+		return node.OverClause
+	case *BitAnd:
+		return node.OverClause
+	case *BitOr:
+		return node.OverClause
+	case *BitXor:
+		return node.OverClause
+	case *Std:
+		return node.OverClause
+	case *StdDev:
+		return node.OverClause
+	case *StdPop:
+		return node.OverClause
+	case *StdSamp:
+		return node.OverClause
+	case *VarPop:
+		return node.OverClause
+	case *VarSamp:
+		return node.OverClause
+	case *Variance:
+		return node.OverClause
+	case *JSONArrayAgg:
+		return node.OverClause
+	case *JSONObjectAgg:
+		return node.OverClause
+	case *ArgumentLessWindowExpr:
+		return node.OverClause
+	case *FirstOrLastValueExpr:
+		return node.OverClause
+	case *NtileExpr:
+		return node.OverClause
+	case *NTHValueExpr:
+		return node.OverClause
+	case *LagLeadExpr:
+		return node.OverClause
+	}
+	return nil
+}
+
+// IsWindowFunction returns true if the expression is a window function call
+func IsWindowFunction(e SQLNode) bool {
+	return GetOverClause(e) != nil
+}
+
+// ContainsWindowFunction returns true if the expression contains a window function call.
+// Subqueries are not searched, since their window functions are evaluated on their own.
+func ContainsWindowFunction(e SQLNode) bool {
+	hasWindow := false
+	_ = Walk(func(node SQLNode) (kontinue bool, err error) {
+		switch node.(type) {
+		case *Offset, *Subquery:
+			return false, nil
+		}
+		if IsWindowFunction(node) {
+			hasWindow = true
+			return false, io.EOF
+		}
+		return true, nil
+	}, e)
+	return hasWindow
+}
+
 // setFuncArgs sets the arguments for the aggregation function, while checking that there is only one argument
 func setFuncArgs(aggr AggrFunc, exprs []Expr, name string) error {
 	if len(exprs) != 1 {
@@ -3747,6 +3747,8 @@ func (cached *QueryHints) CachedSize(alloc bool) int64 {
 	size += hack.RuntimeAllocSize(int64(len(cached.Priority)))
 	// field Timeout *int
 	size += hack.RuntimeAllocSize(int64(8))
+	// field ResultCacheTTL *int
+	size += hack.RuntimeAllocSize(int64(8))
 	return size
 }
 func (cached *ReferenceDefinition) CachedSize(alloc bool) int64 {
@@ -58,6 +58,8 @@ const (
 	// DirectivePriority specifies the priority of a workload. It should be an integer between 0 and MaxPriorityValue,
 	// where 0 is the highest priority, and MaxPriorityValue is the lowest one.
 	DirectivePriority = "PRIORITY"
+	// DirectiveResultCacheTTL caches the results of a SELECT in vtgate for the given number of milliseconds.
+	DirectiveResultCacheTTL = "RESULT_CACHE_TTL_MS"
 
 	// MaxPriorityValue specifies the maximum value allowed for the priority query directive. Valid priority values are
 	// between zero and MaxPriorityValue.
@@ -569,6 +571,7 @@ type QueryHints struct {
 	ForeignKeyChecks    *bool
 	Priority            string
 	Timeout             *int
+	ResultCacheTTL      *int
 }
 
 func BuildQueryHints(stmt Statement) (qh QueryHints, err error) {
@@ -590,10 +593,39 @@ func BuildQueryHints(stmt Statement) (qh QueryHints, err error) {
 	qh.Workload = getWorkload(directives)
 	qh.ForeignKeyChecks = getForeignKeyChecksState(comment)
 	qh.Timeout = getQueryTimeout(directives)
+	qh.ResultCacheTTL = getResultCacheTTL(stmt, directives)
 
 	return qh, nil
 }
 
+// ApplyQueryAttributes sets the hints that the comment directives did not
+// set from the query attributes of the same names, which a MySQL client can
+// send along with the query. Only WORKLOAD_NAME, PRIORITY and QUERY_TIMEOUT_MS
+// are supported, as the other directives change the plan of the query, and
+// QUERY_TIMEOUT_MS only applies to SELECTs, like the directive.
+// The names of the attributes are case-insensitive.
+func (qh *QueryHints) ApplyQueryAttributes(stmtType StatementType, attributes map[string]string) error {
+	directives := &CommentDirectives{m: make(map[string]string, len(attributes))}
+	for name, value := range attributes {
+		directives.m[strings.ToLower(name)] = value
+	}
+
+	if qh.Priority == "" {
+		priority, err := getPriority(directives)
+		if err != nil {
+			return err
+		}
+		qh.Priority = priority
+	}
+	if qh.Workload == "" {
+		qh.Workload = getWorkload(directives)
+	}
+	if qh.Timeout == nil && stmtType == StmtSelect {
+		qh.Timeout = getQueryTimeout(directives)
+	}
+	return nil
+}
+
 // getConsolidator returns the consolidator option.
 func getConsolidator(stmt Statement, directives *CommentDirectives) querypb.ExecuteOptions_Consolidator {
 	if _, isSelect := stmt.(SelectStatement); !isSelect {
@@ -662,3 +694,20 @@ func getQueryTimeout(directives *CommentDirectives) *int {
 	}
 	return &timeout
 }
+
+// getResultCacheTTL gets the result cache TTL of a SELECT, using DirectiveResultCacheTTL
+func getResultCacheTTL(stmt Statement, directives *CommentDirectives) *int {
+	if _, isSelect := stmt.(SelectStatement); !isSelect {
+		return nil
+	}
+	ttlString, ok := directives.GetString(DirectiveResultCacheTTL, "")
+	if !ok || ttlString == "" {
+		return nil
+	}
+
+	ttl, err := strconv.Atoi(ttlString)
+	if err != nil || ttl < 0 {
+		return nil
+	}
+	return &ttl
+}
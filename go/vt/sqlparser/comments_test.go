@@ -698,3 +698,76 @@ func TestQueryTimeout(t *testing.T) {
 		})
 	}
 }
+
+// TestResultCacheTTL tests the extraction of RESULT_CACHE_TTL_MS from the comments.
+func TestResultCacheTTL(t *testing.T) {
+	testCases := []struct {
+		query  string
+		expTTL int
+		noTTL  bool
+	}{{
+		query: "select * from a_table",
+		noTTL: true,
+	}, {
+		query:  "select /*vt+ RESULT_CACHE_TTL_MS=1000 */ * from another_table",
+		expTTL: 1000,
+	}, {
+		query:  "select /*vt+ RESULT_CACHE_TTL_MS=0 */ * from another_table",
+		expTTL: 0,
+	}, {
+		query: "select /*vt+ RESULT_CACHE_TTL_MS=-1 */ * from another_table",
+		noTTL: true,
+	}, {
+		query: "update /*vt+ RESULT_CACHE_TTL_MS=1000 */ a_table set a = 1",
+		noTTL: true,
+	}}
+
+	parser := NewTestParser()
+	for _, tc := range testCases {
+		t.Run(tc.query, func(t *testing.T) {
+			stmt, err := parser.Parse(tc.query)
+			assert.NoError(t, err)
+			qh, _ := BuildQueryHints(stmt)
+			if tc.noTTL {
+				assert.Nil(t, qh.ResultCacheTTL)
+			} else {
+				assert.Equal(t, tc.expTTL, *qh.ResultCacheTTL)
+			}
+		})
+	}
+}
+
+// TestApplyQueryAttributes tests that the query attributes set the hints the directives did not set.
+func TestApplyQueryAttributes(t *testing.T) {
+	parser := NewTestParser()
+	stmt, err := parser.Parse("select /*vt+ PRIORITY=10 */ * from a_table")
+	require.NoError(t, err)
+	qh, err := BuildQueryHints(stmt)
+	require.NoError(t, err)
+
+	err = qh.ApplyQueryAttributes(StmtSelect, map[string]string{
+		"priority":         "20",
+		"WORKLOAD_NAME":    "batch",
+		"query_timeout_ms": "100",
+		"other":            "value",
+	})
+	require.NoError(t, err)
+	assert.Equal(t, "10", qh.Priority)
+	assert.Equal(t, "batch", qh.Workload)
+	require.NotNil(t, qh.Timeout)
+	assert.Equal(t, 100, *qh.Timeout)
+
+	// The timeout only applies to selects, like the directive.
+	qh = QueryHints{}
+	err = qh.ApplyQueryAttributes(StmtInsert, map[string]string{
+		"workload_name":    "batch",
+		"query_timeout_ms": "100",
+	})
+	require.NoError(t, err)
+	assert.Equal(t, "batch", qh.Workload)
+	assert.Nil(t, qh.Timeout)
+
+	qh = QueryHints{}
+	err = qh.ApplyQueryAttributes(StmtSelect, map[string]string{"priority": "high"})
+	assert.ErrorIs(t, err, ErrInvalidPriority)
+}
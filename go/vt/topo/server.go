@@ -98,6 +98,7 @@ const (
 	RoutingRulesPath         = "routing_rules"
 	KeyspaceRoutingRulesPath = "keyspace"
 	NamedLocksPath           = "internal/named_locks"
+	VStreamSubscriptionsPath = "vstream_subscriptions"
 )
 
 // Factory is a factory interface to create Conn objects.
@@ -0,0 +1,132 @@
+/*
+Copyright 2025 The Vitess Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package topo
+
+import (
+	"context"
+	"path"
+
+	"vitess.io/vitess/go/vt/vterrors"
+
+	binlogdatapb "vitess.io/vitess/go/vt/proto/binlogdata"
+)
+
+// VStreamSubscriptionInfo is a meta struct that contains the version of a
+// VStream subscription stored in the topo.
+type VStreamSubscriptionInfo struct {
+	version Version
+	*binlogdatapb.VStreamSubscription
+}
+
+// ValidateVStreamSubscriptionName checks if the provided name is a valid name
+// for a VStream subscription.
+func ValidateVStreamSubscriptionName(name string) error {
+	return validateObjectName(name)
+}
+
+// GetVStreamSubscriptionPath returns the node path of a VStream subscription.
+func GetVStreamSubscriptionPath(name string) string {
+	return path.Join(VStreamSubscriptionsPath, name)
+}
+
+// CreateVStreamSubscription creates the topo record of a VStream subscription.
+func (ts *Server) CreateVStreamSubscription(ctx context.Context, value *binlogdatapb.VStreamSubscription) (*VStreamSubscriptionInfo, error) {
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+	if err := ValidateVStreamSubscriptionName(value.Name); err != nil {
+		return nil, err
+	}
+	data, err := value.MarshalVT()
+	if err != nil {
+		return nil, err
+	}
+	version, err := ts.globalCell.Create(ctx, GetVStreamSubscriptionPath(value.Name), data)
+	if err != nil {
+		return nil, err
+	}
+	return &VStreamSubscriptionInfo{version: version, VStreamSubscription: value}, nil
+}
+
+// GetVStreamSubscription returns the topo record of the named VStream
+// subscription, or a NoNode error if it does not exist.
+func (ts *Server) GetVStreamSubscription(ctx context.Context, name string) (*VStreamSubscriptionInfo, error) {
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+	data, version, err := ts.globalCell.Get(ctx, GetVStreamSubscriptionPath(name))
+	if err != nil {
+		return nil, err
+	}
+	value := &binlogdatapb.VStreamSubscription{}
+	if err := value.UnmarshalVT(data); err != nil {
+		return nil, vterrors.Wrapf(err, "bad VStream subscription data for %s", name)
+	}
+	return &VStreamSubscriptionInfo{version: version, VStreamSubscription: value}, nil
+}
+
+// UpdateVStreamSubscriptionFields reads a VStream subscription, updates it
+// with the update function, and writes it back. If the subscription was
+// updated in the meantime, it does it again.
+func (ts *Server) UpdateVStreamSubscriptionFields(ctx context.Context, name string, update func(*binlogdatapb.VStreamSubscription) error) (*VStreamSubscriptionInfo, error) {
+	for {
+		si, err := ts.GetVStreamSubscription(ctx, name)
+		if err != nil {
+			return nil, err
+		}
+		if err := update(si.VStreamSubscription); err != nil {
+			return nil, err
+		}
+		data, err := si.MarshalVT()
+		if err != nil {
+			return nil, err
+		}
+		version, err := ts.globalCell.Update(ctx, GetVStreamSubscriptionPath(name), data, si.version)
+		if IsErrType(err, BadVersion) {
+			continue
+		}
+		if err != nil {
+			return nil, err
+		}
+		si.version = version
+		return si, nil
+	}
+}
+
+// DeleteVStreamSubscription deletes the topo record of a VStream subscription.
+func (ts *Server) DeleteVStreamSubscription(ctx context.Context, name string) error {
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+	return ts.globalCell.Delete(ctx, GetVStreamSubscriptionPath(name), nil)
+}
+
+// GetVStreamSubscriptionNames returns the names of the VStream subscriptions.
+func (ts *Server) GetVStreamSubscriptionNames(ctx context.Context) ([]string, error) {
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+	children, err := ts.globalCell.ListDir(ctx, VStreamSubscriptionsPath, false /*full*/)
+	switch {
+	case err == nil:
+		return DirEntriesToStringArray(children), nil
+	case IsErrType(err, NoNode):
+		return nil, nil
+	default:
+		return nil, err
+	}
+}
@@ -0,0 +1,77 @@
+/*
+Copyright 2025 The Vitess Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package topo_test
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"vitess.io/vitess/go/vt/topo"
+	"vitess.io/vitess/go/vt/topo/memorytopo"
+
+	binlogdatapb "vitess.io/vitess/go/vt/proto/binlogdata"
+)
+
+func TestVStreamSubscriptions(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	ts := memorytopo.NewServer(ctx, "zone1")
+	defer ts.Close()
+
+	names, err := ts.GetVStreamSubscriptionNames(ctx)
+	require.NoError(t, err)
+	assert.Empty(t, names)
+
+	_, err = ts.CreateVStreamSubscription(ctx, &binlogdatapb.VStreamSubscription{Name: "bad/name"})
+	assert.ErrorContains(t, err, "invalid character / in name bad/name")
+
+	for _, name := range []string{"orders", "customers"} {
+		_, err := ts.CreateVStreamSubscription(ctx, &binlogdatapb.VStreamSubscription{Name: name, CreatedAt: 1})
+		require.NoError(t, err)
+	}
+	_, err = ts.CreateVStreamSubscription(ctx, &binlogdatapb.VStreamSubscription{Name: "orders"})
+	assert.True(t, topo.IsErrType(err, topo.NodeExists), err)
+
+	names, err = ts.GetVStreamSubscriptionNames(ctx)
+	require.NoError(t, err)
+	assert.Equal(t, []string{"customers", "orders"}, names)
+
+	vgtid := &binlogdatapb.VGtid{ShardGtids: []*binlogdatapb.ShardGtid{{Keyspace: "commerce", Shard: "0", Gtid: "MySQL56/a:1-5"}}}
+	si, err := ts.UpdateVStreamSubscriptionFields(ctx, "orders", func(sub *binlogdatapb.VStreamSubscription) error {
+		sub.Vgtid = vgtid
+		return nil
+	})
+	require.NoError(t, err)
+	assert.Equal(t, vgtid, si.Vgtid)
+	si, err = ts.GetVStreamSubscription(ctx, "orders")
+	require.NoError(t, err)
+	assert.Equal(t, vgtid, si.Vgtid)
+	assert.EqualValues(t, 1, si.CreatedAt)
+
+	_, err = ts.UpdateVStreamSubscriptionFields(ctx, "unknown", func(sub *binlogdatapb.VStreamSubscription) error { return nil })
+	assert.True(t, topo.IsErrType(err, topo.NoNode), err)
+
+	require.NoError(t, ts.DeleteVStreamSubscription(ctx, "orders"))
+	_, err = ts.GetVStreamSubscription(ctx, "orders")
+	assert.True(t, topo.IsErrType(err, topo.NoNode), err)
+	names, err = ts.GetVStreamSubscriptionNames(ctx)
+	require.NoError(t, err)
+	assert.Equal(t, []string{"customers"}, names)
+}
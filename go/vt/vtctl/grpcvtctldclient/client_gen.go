@@ -101,6 +101,24 @@ func (client *gRPCVtctldClient) Backup(ctx context.Context, in *vtctldatapb.Back
 	return client.c.Backup(ctx, in, opts...)
 }
 
+// BackupExpire is part of the vtctlservicepb.VtctldClient interface.
+func (client *gRPCVtctldClient) BackupExpire(ctx context.Context, in *vtctldatapb.BackupExpireRequest, opts ...grpc.CallOption) (*vtctldatapb.BackupExpireResponse, error) {
+	if client.c == nil {
+		return nil, status.Error(codes.Unavailable, connClosedMsg)
+	}
+
+	return client.c.BackupExpire(ctx, in, opts...)
+}
+
+// BackupRestorePlan is part of the vtctlservicepb.VtctldClient interface.
+func (client *gRPCVtctldClient) BackupRestorePlan(ctx context.Context, in *vtctldatapb.BackupRestorePlanRequest, opts ...grpc.CallOption) (*vtctldatapb.BackupRestorePlanResponse, error) {
+	if client.c == nil {
+		return nil, status.Error(codes.Unavailable, connClosedMsg)
+	}
+
+	return client.c.BackupRestorePlan(ctx, in, opts...)
+}
+
 // BackupShard is part of the vtctlservicepb.VtctldClient interface.
 func (client *gRPCVtctldClient) BackupShard(ctx context.Context, in *vtctldatapb.BackupShardRequest, opts ...grpc.CallOption) (vtctlservicepb.Vtctld_BackupShardClient, error) {
 	if client.c == nil {
@@ -110,6 +128,15 @@ func (client *gRPCVtctldClient) BackupShard(ctx context.Context, in *vtctldatapb
 	return client.c.BackupShard(ctx, in, opts...)
 }
 
+// BackupValidate is part of the vtctlservicepb.VtctldClient interface.
+func (client *gRPCVtctldClient) BackupValidate(ctx context.Context, in *vtctldatapb.BackupValidateRequest, opts ...grpc.CallOption) (*vtctldatapb.BackupValidateResponse, error) {
+	if client.c == nil {
+		return nil, status.Error(codes.Unavailable, connClosedMsg)
+	}
+
+	return client.c.BackupValidate(ctx, in, opts...)
+}
+
 // CancelSchemaMigration is part of the vtctlservicepb.VtctldClient interface.
 func (client *gRPCVtctldClient) CancelSchemaMigration(ctx context.Context, in *vtctldatapb.CancelSchemaMigrationRequest, opts ...grpc.CallOption) (*vtctldatapb.CancelSchemaMigrationResponse, error) {
 	if client.c == nil {
@@ -254,6 +281,15 @@ func (client *gRPCVtctldClient) DeleteTablets(ctx context.Context, in *vtctldata
 	return client.c.DeleteTablets(ctx, in, opts...)
 }
 
+// DeleteVStreamSubscription is part of the vtctlservicepb.VtctldClient interface.
+func (client *gRPCVtctldClient) DeleteVStreamSubscription(ctx context.Context, in *vtctldatapb.DeleteVStreamSubscriptionRequest, opts ...grpc.CallOption) (*vtctldatapb.DeleteVStreamSubscriptionResponse, error) {
+	if client.c == nil {
+		return nil, status.Error(codes.Unavailable, connClosedMsg)
+	}
+
+	return client.c.DeleteVStreamSubscription(ctx, in, opts...)
+}
+
 // EmergencyReparentShard is part of the vtctlservicepb.VtctldClient interface.
 func (client *gRPCVtctldClient) EmergencyReparentShard(ctx context.Context, in *vtctldatapb.EmergencyReparentShardRequest, opts ...grpc.CallOption) (*vtctldatapb.EmergencyReparentShardResponse, error) {
 	if client.c == nil {
@@ -560,6 +596,15 @@ func (client *gRPCVtctldClient) GetVSchema(ctx context.Context, in *vtctldatapb.
 	return client.c.GetVSchema(ctx, in, opts...)
 }
 
+// GetVStreamSubscriptions is part of the vtctlservicepb.VtctldClient interface.
+func (client *gRPCVtctldClient) GetVStreamSubscriptions(ctx context.Context, in *vtctldatapb.GetVStreamSubscriptionsRequest, opts ...grpc.CallOption) (*vtctldatapb.GetVStreamSubscriptionsResponse, error) {
+	if client.c == nil {
+		return nil, status.Error(codes.Unavailable, connClosedMsg)
+	}
+
+	return client.c.GetVStreamSubscriptions(ctx, in, opts...)
+}
+
 // GetVersion is part of the vtctlservicepb.VtctldClient interface.
 func (client *gRPCVtctldClient) GetVersion(ctx context.Context, in *vtctldatapb.GetVersionRequest, opts ...grpc.CallOption) (*vtctldatapb.GetVersionResponse, error) {
 	if client.c == nil {
@@ -830,6 +875,15 @@ func (client *gRPCVtctldClient) ReshardCreate(ctx context.Context, in *vtctldata
 	return client.c.ReshardCreate(ctx, in, opts...)
 }
 
+// ReshardPlan is part of the vtctlservicepb.VtctldClient interface.
+func (client *gRPCVtctldClient) ReshardPlan(ctx context.Context, in *vtctldatapb.ReshardPlanRequest, opts ...grpc.CallOption) (*vtctldatapb.ReshardPlanResponse, error) {
+	if client.c == nil {
+		return nil, status.Error(codes.Unavailable, connClosedMsg)
+	}
+
+	return client.c.ReshardPlan(ctx, in, opts...)
+}
+
 // RestoreFromBackup is part of the vtctlservicepb.VtctldClient interface.
 func (client *gRPCVtctldClient) RestoreFromBackup(ctx context.Context, in *vtctldatapb.RestoreFromBackupRequest, opts ...grpc.CallOption) (vtctlservicepb.Vtctld_RestoreFromBackupClient, error) {
 	if client.c == nil {
@@ -1028,6 +1082,15 @@ func (client *gRPCVtctldClient) VDiffDelete(ctx context.Context, in *vtctldatapb
 	return client.c.VDiffDelete(ctx, in, opts...)
 }
 
+// VDiffRepair is part of the vtctlservicepb.VtctldClient interface.
+func (client *gRPCVtctldClient) VDiffRepair(ctx context.Context, in *vtctldatapb.VDiffRepairRequest, opts ...grpc.CallOption) (*vtctldatapb.VDiffRepairResponse, error) {
+	if client.c == nil {
+		return nil, status.Error(codes.Unavailable, connClosedMsg)
+	}
+
+	return client.c.VDiffRepair(ctx, in, opts...)
+}
+
 // VDiffResume is part of the vtctlservicepb.VtctldClient interface.
 func (client *gRPCVtctldClient) VDiffResume(ctx context.Context, in *vtctldatapb.VDiffResumeRequest, opts ...grpc.CallOption) (*vtctldatapb.VDiffResumeResponse, error) {
 	if client.c == nil {
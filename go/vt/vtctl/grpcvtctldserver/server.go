@@ -37,6 +37,7 @@ import (
 	"google.golang.org/grpc"
 
 	"vitess.io/vitess/go/event"
+	"vitess.io/vitess/go/mysql/replication"
 	"vitess.io/vitess/go/netutil"
 	"vitess.io/vitess/go/protoutil"
 	"vitess.io/vitess/go/sets"
@@ -569,6 +570,202 @@ func (s *VtctldServer) backupTablet(ctx context.Context, tablet *topodatapb.Tabl
 	}
 }
 
+// BackupExpire is part of the vtctlservicepb.VtctldServer interface.
+func (s *VtctldServer) BackupExpire(ctx context.Context, req *vtctldatapb.BackupExpireRequest) (resp *vtctldatapb.BackupExpireResponse, err error) {
+	span, ctx := trace.NewSpan(ctx, "VtctldServer.BackupExpire")
+	defer span.Finish()
+
+	defer panicHandler(&err)
+
+	bucket := mysqlctl.GetBackupDir(req.Keyspace, req.Shard)
+
+	span.Annotate("keyspace", req.Keyspace)
+	span.Annotate("shard", req.Shard)
+	span.Annotate("bucket", bucket)
+	span.Annotate("dry_run", req.DryRun)
+
+	if len(req.Rules) == 0 {
+		return nil, vterrors.Errorf(vtrpcpb.Code_INVALID_ARGUMENT, "at least one backup retention rule is required")
+	}
+
+	policy := make(mysqlctl.BackupRetentionPolicy, 0, len(req.Rules))
+	for _, rule := range req.Rules {
+		interval, _, err := protoutil.DurationFromProto(rule.Interval)
+		if err != nil {
+			return nil, err
+		}
+		window, _, err := protoutil.DurationFromProto(rule.Window)
+		if err != nil {
+			return nil, err
+		}
+		policy = append(policy, mysqlctl.BackupRetentionRule{Interval: interval, Window: window})
+	}
+
+	bs, err := backupstorage.GetBackupStorage()
+	if err != nil {
+		return nil, err
+	}
+	defer bs.Close()
+
+	bhs, err := bs.ListBackups(ctx, bucket)
+	if err != nil {
+		return nil, err
+	}
+
+	manifests, manifestHandleMap, _ := mysqlctl.ReadBackupManifests(ctx, bhs)
+	retained, expired, err := policy.ExpireBackups(time.Now(), manifests)
+	if err != nil {
+		return nil, err
+	}
+
+	resp = &vtctldatapb.BackupExpireResponse{
+		Retained: backupManifestsToProto(req.Keyspace, req.Shard, retained, manifestHandleMap),
+		Expired:  backupManifestsToProto(req.Keyspace, req.Shard, expired, manifestHandleMap),
+	}
+
+	if req.DryRun {
+		return resp, nil
+	}
+
+	for _, bi := range resp.Expired {
+		log.Infof("Removing backup %s/%s expired by the retention policy", bucket, bi.Name)
+		if err := bs.RemoveBackup(ctx, bucket, bi.Name); err != nil {
+			return nil, vterrors.Wrapf(err, "failed to remove backup %s/%s", bucket, bi.Name)
+		}
+	}
+
+	return resp, nil
+}
+
+// BackupRestorePlan is part of the vtctlservicepb.VtctldServer interface.
+func (s *VtctldServer) BackupRestorePlan(ctx context.Context, req *vtctldatapb.BackupRestorePlanRequest) (resp *vtctldatapb.BackupRestorePlanResponse, err error) {
+	span, ctx := trace.NewSpan(ctx, "VtctldServer.BackupRestorePlan")
+	defer span.Finish()
+
+	defer panicHandler(&err)
+
+	bucket := mysqlctl.GetBackupDir(req.Keyspace, req.Shard)
+
+	span.Annotate("keyspace", req.Keyspace)
+	span.Annotate("shard", req.Shard)
+	span.Annotate("bucket", bucket)
+	span.Annotate("restore_to_pos", req.RestoreToPos)
+
+	restoreToTimestamp := protoutil.TimeFromProto(req.RestoreToTimestamp).UTC()
+	if !restoreToTimestamp.IsZero() {
+		span.Annotate("restore_to_timestamp", mysqlctl.FormatRFC3339(restoreToTimestamp))
+	}
+
+	switch {
+	case req.RestoreToPos != "" && !restoreToTimestamp.IsZero():
+		return nil, vterrors.Errorf(vtrpcpb.Code_INVALID_ARGUMENT, "RestoreToPos and RestoreToTimestamp are mutually exclusive")
+	case req.RestoreToPos == "" && restoreToTimestamp.IsZero():
+		return nil, vterrors.Errorf(vtrpcpb.Code_INVALID_ARGUMENT, "one of RestoreToPos or RestoreToTimestamp is required")
+	}
+
+	bs, err := backupstorage.GetBackupStorage()
+	if err != nil {
+		return nil, err
+	}
+	defer bs.Close()
+
+	bhs, err := bs.ListBackups(ctx, bucket)
+	if err != nil {
+		return nil, err
+	}
+
+	manifests, manifestHandleMap, _ := mysqlctl.ReadBackupManifests(ctx, bhs)
+
+	var path []*mysqlctl.BackupManifest
+	if req.RestoreToPos != "" {
+		pos, err := replication.DecodePosition(req.RestoreToPos)
+		if err != nil {
+			return nil, vterrors.Wrapf(err, "failed to decode RestoreToPos %q", req.RestoreToPos)
+		}
+		path, err = mysqlctl.FindPITRPath(pos.GTIDSet, manifests)
+		if err != nil {
+			return nil, err
+		}
+	} else {
+		path, err = mysqlctl.FindPITRToTimePath(restoreToTimestamp, manifests)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	return &vtctldatapb.BackupRestorePlanResponse{
+		Backups: backupManifestsToProto(req.Keyspace, req.Shard, path, manifestHandleMap),
+	}, nil
+}
+
+// BackupValidate is part of the vtctlservicepb.VtctldServer interface.
+func (s *VtctldServer) BackupValidate(ctx context.Context, req *vtctldatapb.BackupValidateRequest) (resp *vtctldatapb.BackupValidateResponse, err error) {
+	span, ctx := trace.NewSpan(ctx, "VtctldServer.BackupValidate")
+	defer span.Finish()
+
+	defer panicHandler(&err)
+
+	bucket := mysqlctl.GetBackupDir(req.Keyspace, req.Shard)
+
+	span.Annotate("keyspace", req.Keyspace)
+	span.Annotate("shard", req.Shard)
+	span.Annotate("bucket", bucket)
+
+	bs, err := backupstorage.GetBackupStorage()
+	if err != nil {
+		return nil, err
+	}
+	defer bs.Close()
+
+	bhs, err := bs.ListBackups(ctx, bucket)
+	if err != nil {
+		return nil, err
+	}
+
+	manifests, manifestHandleMap, incomplete := mysqlctl.ReadBackupManifests(ctx, bhs)
+	gaps, err := mysqlctl.FindBackupChainGaps(manifests)
+	if err != nil {
+		return nil, err
+	}
+
+	resp = &vtctldatapb.BackupValidateResponse{
+		Gaps:              make([]*vtctldatapb.BackupChainGap, 0, len(gaps)),
+		IncompleteBackups: make([]*mysqlctlpb.BackupInfo, 0, len(incomplete)),
+	}
+	for _, gap := range gaps {
+		pbGap := &vtctldatapb.BackupChainGap{
+			Backup:       backupManifestsToProto(req.Keyspace, req.Shard, []*mysqlctl.BackupManifest{gap.Manifest}, manifestHandleMap)[0],
+			FromPosition: replication.EncodePosition(gap.Manifest.FromPosition),
+		}
+		if gap.MissingGTIDSet != nil {
+			pbGap.MissingGtids = gap.MissingGTIDSet.String()
+		}
+		resp.Gaps = append(resp.Gaps, pbGap)
+	}
+	for _, bh := range incomplete {
+		bi := mysqlctlproto.BackupHandleToProto(bh)
+		bi.Keyspace = req.Keyspace
+		bi.Shard = req.Shard
+		resp.IncompleteBackups = append(resp.IncompleteBackups, bi)
+	}
+
+	return resp, nil
+}
+
+// backupManifestsToProto returns the BackupInfo protos of the backups of the
+// given manifests.
+func backupManifestsToProto(keyspace string, shard string, manifests []*mysqlctl.BackupManifest, manifestHandleMap *mysqlctl.ManifestHandleMap) []*mysqlctlpb.BackupInfo {
+	backups := make([]*mysqlctlpb.BackupInfo, 0, len(manifests))
+	for _, manifest := range manifests {
+		bi := mysqlctlproto.BackupHandleToProto(manifestHandleMap.Handle(manifest))
+		bi.Keyspace = keyspace
+		bi.Shard = shard
+		bi.Engine = manifest.BackupMethod
+		backups = append(backups, bi)
+	}
+	return backups
+}
+
 // CancelSchemaMigration is part of the vtctlservicepb.VtctldServer interface.
 func (s *VtctldServer) CancelSchemaMigration(ctx context.Context, req *vtctldatapb.CancelSchemaMigrationRequest) (resp *vtctldatapb.CancelSchemaMigrationResponse, err error) {
 	span, ctx := trace.NewSpan(ctx, "VtctldServer.CancelSchemaMigration")
@@ -1274,6 +1471,25 @@ func (s *VtctldServer) DeleteTablets(ctx context.Context, req *vtctldatapb.Delet
 	return &vtctldatapb.DeleteTabletsResponse{}, nil
 }
 
+// DeleteVStreamSubscription is part of the vtctlservicepb.VtctldServer interface.
+func (s *VtctldServer) DeleteVStreamSubscription(ctx context.Context, req *vtctldatapb.DeleteVStreamSubscriptionRequest) (resp *vtctldatapb.DeleteVStreamSubscriptionResponse, err error) {
+	span, ctx := trace.NewSpan(ctx, "VtctldServer.DeleteVStreamSubscription")
+	defer span.Finish()
+
+	defer panicHandler(&err)
+
+	span.Annotate("name", req.Name)
+
+	ctx, cancel := context.WithTimeout(ctx, topo.RemoteOperationTimeout)
+	defer cancel()
+
+	if err = s.ts.DeleteVStreamSubscription(ctx, req.Name); err != nil {
+		return nil, err
+	}
+
+	return &vtctldatapb.DeleteVStreamSubscriptionResponse{}, nil
+}
+
 // EmergencyReparentShard is part of the vtctldservicepb.VtctldServer interface.
 func (s *VtctldServer) EmergencyReparentShard(ctx context.Context, req *vtctldatapb.EmergencyReparentShardRequest) (resp *vtctldatapb.EmergencyReparentShardResponse, err error) {
 	span, ctx := trace.NewSpan(ctx, "VtctldServer.EmergencyReparentShard")
@@ -2717,6 +2933,41 @@ func (s *VtctldServer) GetVSchema(ctx context.Context, req *vtctldatapb.GetVSche
 	}, nil
 }
 
+// GetVStreamSubscriptions is part of the vtctlservicepb.VtctldServer interface.
+func (s *VtctldServer) GetVStreamSubscriptions(ctx context.Context, req *vtctldatapb.GetVStreamSubscriptionsRequest) (resp *vtctldatapb.GetVStreamSubscriptionsResponse, err error) {
+	span, ctx := trace.NewSpan(ctx, "VtctldServer.GetVStreamSubscriptions")
+	defer span.Finish()
+
+	defer panicHandler(&err)
+
+	span.Annotate("names", strings.Join(req.Names, ","))
+
+	names := req.Names
+	if len(names) == 0 {
+		names, err = s.ts.GetVStreamSubscriptionNames(ctx)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	now := time.Now()
+	purged := make(map[string]replication.Position)
+	resp = &vtctldatapb.GetVStreamSubscriptionsResponse{}
+	for _, name := range names {
+		si, err := s.ts.GetVStreamSubscription(ctx, name)
+		if err != nil {
+			return nil, err
+		}
+		status, err := s.vstreamSubscriptionStatus(ctx, si.VStreamSubscription, now, purged)
+		if err != nil {
+			return nil, err
+		}
+		resp.Subscriptions = append(resp.Subscriptions, status)
+	}
+
+	return resp, nil
+}
+
 // GetWorkflows is part of the vtctlservicepb.VtctldServer interface.
 func (s *VtctldServer) GetWorkflows(ctx context.Context, req *vtctldatapb.GetWorkflowsRequest) (resp *vtctldatapb.GetWorkflowsResponse, err error) {
 	span, ctx := trace.NewSpan(ctx, "VtctldServer.GetWorkflows")
@@ -3730,6 +3981,23 @@ func (s *VtctldServer) ReshardCreate(ctx context.Context, req *vtctldatapb.Resha
 	return resp, err
 }
 
+// ReshardPlan is part of the vtctlservicepb.VtctldServer interface.
+func (s *VtctldServer) ReshardPlan(ctx context.Context, req *vtctldatapb.ReshardPlanRequest) (resp *vtctldatapb.ReshardPlanResponse, err error) {
+	span, ctx := trace.NewSpan(ctx, "VtctldServer.ReshardPlan")
+	defer span.Finish()
+
+	defer panicHandler(&err)
+
+	span.Annotate("keyspace", req.Keyspace)
+	span.Annotate("source_shards", req.SourceShards)
+	span.Annotate("target_shards", req.TargetShards)
+	span.Annotate("target_shard_count", req.TargetShardCount)
+	span.Annotate("sample_size", req.SampleSize)
+
+	resp, err = s.ws.ReshardPlan(ctx, req)
+	return resp, err
+}
+
 func (s *VtctldServer) RestoreFromBackup(req *vtctldatapb.RestoreFromBackupRequest, stream vtctlservicepb.Vtctld_RestoreFromBackupServer) (err error) {
 	span, ctx := trace.NewSpan(stream.Context(), "VtctldServer.RestoreFromBackup")
 	defer span.Finish()
@@ -5496,6 +5764,22 @@ func (s *VtctldServer) VDiffDelete(ctx context.Context, req *vtctldatapb.VDiffDe
 	return resp, err
 }
 
+// VDiffRepair is part of the vtctlservicepb.VtctldServer interface.
+func (s *VtctldServer) VDiffRepair(ctx context.Context, req *vtctldatapb.VDiffRepairRequest) (resp *vtctldatapb.VDiffRepairResponse, err error) {
+	span, ctx := trace.NewSpan(ctx, "VtctldServer.VDiffRepair")
+	defer span.Finish()
+
+	defer panicHandler(&err)
+
+	span.Annotate("keyspace", req.TargetKeyspace)
+	span.Annotate("workflow", req.Workflow)
+	span.Annotate("uuid", req.Uuid)
+	span.Annotate("shards", req.TargetShards)
+
+	resp, err = s.ws.VDiffRepair(ctx, req)
+	return resp, err
+}
+
 // VDiffResume is part of the vtctlservicepb.VtctldServer interface.
 func (s *VtctldServer) VDiffResume(ctx context.Context, req *vtctldatapb.VDiffResumeRequest) (resp *vtctldatapb.VDiffResumeResponse, err error) {
 	span, ctx := trace.NewSpan(ctx, "VtctldServer.VDiffResume")
@@ -18,6 +18,7 @@ package grpcvtctldserver
 
 import (
 	"context"
+	"encoding/json"
 	"errors"
 	"fmt"
 	"io"
@@ -45,6 +46,7 @@ import (
 	"vitess.io/vitess/go/test/utils"
 	"vitess.io/vitess/go/vt/callerid"
 	hk "vitess.io/vitess/go/vt/hook"
+	"vitess.io/vitess/go/vt/mysqlctl"
 	"vitess.io/vitess/go/vt/mysqlctl/backupstorage"
 	"vitess.io/vitess/go/vt/proto/vtrpc"
 	"vitess.io/vitess/go/vt/proto/vttime"
@@ -59,6 +61,7 @@ import (
 	"vitess.io/vitess/go/vt/vttablet/tmclient"
 	"vitess.io/vitess/go/vt/vttablet/tmclienttest"
 
+	binlogdatapb "vitess.io/vitess/go/vt/proto/binlogdata"
 	logutilpb "vitess.io/vitess/go/vt/proto/logutil"
 	mysqlctlpb "vitess.io/vitess/go/vt/proto/mysqlctl"
 	querypb "vitess.io/vitess/go/vt/proto/query"
@@ -1300,6 +1303,231 @@ func (tc *requireCallerIDTMClient) ExecuteQuery(ctx context.Context, tablet *top
 	return tc.TabletManagerClient.ExecuteQuery(ctx, tablet, req)
 }
 
+// setBackupManifests sets the backups of testkeyspace/- in the test backup
+// storage, along with the manifests of the complete ones.
+func setBackupManifests(t *testing.T, backups []string, manifests map[string]*mysqlctl.BackupManifest) {
+	t.Helper()
+
+	testutil.BackupStorage.Backups = map[string][]string{
+		"testkeyspace/-": backups,
+	}
+	testutil.BackupStorage.Manifests = map[string]string{}
+	for name, manifest := range manifests {
+		data, err := json.Marshal(manifest)
+		require.NoError(t, err)
+		testutil.BackupStorage.Manifests["testkeyspace/-/"+name] = string(data)
+	}
+}
+
+func backupTestManifest(age time.Duration, fromPos string, pos string) *mysqlctl.BackupManifest {
+	manifest := &mysqlctl.BackupManifest{
+		BackupMethod: "builtin",
+		Position:     replication.MustParsePosition(replication.Mysql56FlavorID, "16b1039f-22b6-11ed-b765-0a43f95f28a3:"+pos),
+		BackupTime:   mysqlctl.FormatRFC3339(time.Now().Add(-age)),
+	}
+	if fromPos != "" {
+		manifest.Incremental = true
+		manifest.FromPosition = replication.MustParsePosition(replication.Mysql56FlavorID, "16b1039f-22b6-11ed-b765-0a43f95f28a3:"+fromPos)
+	}
+	return manifest
+}
+
+func backupInfoNames(backups []*mysqlctlpb.BackupInfo) []string {
+	names := make([]string, 0, len(backups))
+	for _, bi := range backups {
+		names = append(names, bi.Name)
+	}
+	return names
+}
+
+func TestBackupExpire(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	ts := memorytopo.NewServer(ctx)
+	vtctld := testutil.NewVtctldServerWithTabletManagerClient(t, ts, nil, func(ts *topo.Server) vtctlservicepb.VtctldServer {
+		return NewVtctldServer(vtenv.NewTestEnv(), ts)
+	})
+	defer func() { testutil.BackupStorage.Manifests = nil }()
+
+	setup := func() {
+		setBackupManifests(t, []string{"full-3d", "inc-3d", "full-1d", "inc-1h", "in-progress"}, map[string]*mysqlctl.BackupManifest{
+			"full-3d": backupTestManifest(72*time.Hour, "", "1-100"),
+			"inc-3d":  backupTestManifest(71*time.Hour, "1-100", "1-150"),
+			"full-1d": backupTestManifest(24*time.Hour, "", "1-300"),
+			"inc-1h":  backupTestManifest(time.Hour, "1-300", "1-400"),
+		})
+	}
+	rules := []*vtctldatapb.BackupRetentionRule{{
+		Interval: protoutil.DurationToProto(time.Hour),
+		Window:   protoutil.DurationToProto(48 * time.Hour),
+	}}
+
+	t.Run("dry run", func(t *testing.T) {
+		setup()
+		resp, err := vtctld.BackupExpire(ctx, &vtctldatapb.BackupExpireRequest{
+			Keyspace: "testkeyspace",
+			Shard:    "-",
+			Rules:    rules,
+			DryRun:   true,
+		})
+		require.NoError(t, err)
+		assert.Equal(t, []string{"full-1d", "inc-1h"}, backupInfoNames(resp.Retained))
+		assert.Equal(t, []string{"full-3d", "inc-3d"}, backupInfoNames(resp.Expired))
+		assert.Equal(t, "builtin", resp.Expired[0].Engine)
+		assert.Len(t, testutil.BackupStorage.Backups["testkeyspace/-"], 5)
+	})
+
+	t.Run("ok", func(t *testing.T) {
+		setup()
+		_, err := vtctld.BackupExpire(ctx, &vtctldatapb.BackupExpireRequest{
+			Keyspace: "testkeyspace",
+			Shard:    "-",
+			Rules:    rules,
+		})
+		require.NoError(t, err)
+		// The in-progress backup has no manifest and is never expired.
+		assert.Equal(t, []string{"full-1d", "inc-1h", "in-progress"}, testutil.BackupStorage.Backups["testkeyspace/-"])
+	})
+
+	t.Run("no rules", func(t *testing.T) {
+		setup()
+		_, err := vtctld.BackupExpire(ctx, &vtctldatapb.BackupExpireRequest{
+			Keyspace: "testkeyspace",
+			Shard:    "-",
+		})
+		assert.ErrorContains(t, err, "at least one backup retention rule is required")
+	})
+
+	t.Run("invalid rule", func(t *testing.T) {
+		setup()
+		_, err := vtctld.BackupExpire(ctx, &vtctldatapb.BackupExpireRequest{
+			Keyspace: "testkeyspace",
+			Shard:    "-",
+			Rules: []*vtctldatapb.BackupRetentionRule{{
+				Interval: protoutil.DurationToProto(48 * time.Hour),
+				Window:   protoutil.DurationToProto(time.Hour),
+			}},
+		})
+		assert.ErrorContains(t, err, "must not be shorter than its interval")
+		assert.Len(t, testutil.BackupStorage.Backups["testkeyspace/-"], 5)
+	})
+}
+
+func TestBackupRestorePlan(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	ts := memorytopo.NewServer(ctx)
+	vtctld := testutil.NewVtctldServerWithTabletManagerClient(t, ts, nil, func(ts *topo.Server) vtctlservicepb.VtctldServer {
+		return NewVtctldServer(vtenv.NewTestEnv(), ts)
+	})
+	defer func() { testutil.BackupStorage.Manifests = nil }()
+
+	setBackupManifests(t, []string{"full-1", "inc-1", "inc-2", "full-2", "inc-3"}, map[string]*mysqlctl.BackupManifest{
+		"full-1": backupTestManifest(5*time.Hour, "", "1-100"),
+		"inc-1":  backupTestManifest(4*time.Hour, "1-100", "1-150"),
+		"inc-2":  backupTestManifest(3*time.Hour, "1-150", "1-200"),
+		"full-2": backupTestManifest(2*time.Hour, "", "1-250"),
+		"inc-3":  backupTestManifest(time.Hour, "1-250", "1-300"),
+	})
+
+	tcases := []struct {
+		name   string
+		req    *vtctldatapb.BackupRestorePlanRequest
+		expect []string
+		err    string
+	}{
+		{
+			name:   "full backup",
+			req:    &vtctldatapb.BackupRestorePlanRequest{RestoreToPos: "MySQL56/16b1039f-22b6-11ed-b765-0a43f95f28a3:1-100"},
+			expect: []string{"full-1"},
+		},
+		{
+			name:   "incremental chain",
+			req:    &vtctldatapb.BackupRestorePlanRequest{RestoreToPos: "MySQL56/16b1039f-22b6-11ed-b765-0a43f95f28a3:1-180"},
+			expect: []string{"full-1", "inc-1", "inc-2"},
+		},
+		{
+			name:   "latest full backup",
+			req:    &vtctldatapb.BackupRestorePlanRequest{RestoreToPos: "MySQL56/16b1039f-22b6-11ed-b765-0a43f95f28a3:1-280"},
+			expect: []string{"full-2", "inc-3"},
+		},
+		{
+			name: "no backup",
+			req:  &vtctldatapb.BackupRestorePlanRequest{RestoreToPos: "MySQL56/16b1039f-22b6-11ed-b765-0a43f95f28a3:1-50"},
+			err:  "no full backup found before GTID",
+		},
+		{
+			name: "invalid position",
+			req:  &vtctldatapb.BackupRestorePlanRequest{RestoreToPos: "invalid"},
+			err:  "failed to decode RestoreToPos",
+		},
+		{
+			name: "no restore point",
+			req:  &vtctldatapb.BackupRestorePlanRequest{},
+			err:  "one of RestoreToPos or RestoreToTimestamp is required",
+		},
+		{
+			name: "both restore points",
+			req: &vtctldatapb.BackupRestorePlanRequest{
+				RestoreToPos:       "MySQL56/16b1039f-22b6-11ed-b765-0a43f95f28a3:1-100",
+				RestoreToTimestamp: protoutil.TimeToProto(time.Now()),
+			},
+			err: "mutually exclusive",
+		},
+	}
+	for _, tcase := range tcases {
+		t.Run(tcase.name, func(t *testing.T) {
+			tcase.req.Keyspace = "testkeyspace"
+			tcase.req.Shard = "-"
+			resp, err := vtctld.BackupRestorePlan(ctx, tcase.req)
+			if tcase.err != "" {
+				assert.ErrorContains(t, err, tcase.err)
+				return
+			}
+			require.NoError(t, err)
+			assert.Equal(t, tcase.expect, backupInfoNames(resp.Backups))
+		})
+	}
+}
+
+func TestBackupValidate(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	ts := memorytopo.NewServer(ctx)
+	vtctld := testutil.NewVtctldServerWithTabletManagerClient(t, ts, nil, func(ts *topo.Server) vtctlservicepb.VtctldServer {
+		return NewVtctldServer(vtenv.NewTestEnv(), ts)
+	})
+	defer func() { testutil.BackupStorage.Manifests = nil }()
+
+	setBackupManifests(t, []string{"full", "inc-1", "inc-gap", "in-progress"}, map[string]*mysqlctl.BackupManifest{
+		"full":    backupTestManifest(3*time.Hour, "", "1-100"),
+		"inc-1":   backupTestManifest(2*time.Hour, "1-100", "1-150"),
+		"inc-gap": backupTestManifest(time.Hour, "1-180", "1-200"),
+	})
+
+	resp, err := vtctld.BackupValidate(ctx, &vtctldatapb.BackupValidateRequest{
+		Keyspace: "testkeyspace",
+		Shard:    "-",
+	})
+	require.NoError(t, err)
+	require.Len(t, resp.Gaps, 1)
+	assert.Equal(t, "inc-gap", resp.Gaps[0].Backup.Name)
+	assert.Equal(t, "MySQL56/16b1039f-22b6-11ed-b765-0a43f95f28a3:1-180", resp.Gaps[0].FromPosition)
+	assert.Equal(t, "16b1039f-22b6-11ed-b765-0a43f95f28a3:151-180", resp.Gaps[0].MissingGtids)
+	assert.Equal(t, []string{"in-progress"}, backupInfoNames(resp.IncompleteBackups))
+
+	t.Run("listbackups error", func(t *testing.T) {
+		testutil.BackupStorage.ListBackupsError = assert.AnError
+		defer func() { testutil.BackupStorage.ListBackupsError = nil }()
+
+		_, err := vtctld.BackupValidate(ctx, &vtctldatapb.BackupValidateRequest{
+			Keyspace: "testkeyspace",
+			Shard:    "-",
+		})
+		assert.Error(t, err)
+	})
+}
+
 func TestCancelSchemaMigration(t *testing.T) {
 	t.Parallel()
 
@@ -4691,6 +4919,28 @@ func TestDeleteTablets(t *testing.T) {
 	}
 }
 
+func TestDeleteVStreamSubscription(t *testing.T) {
+	t.Parallel()
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	ts := memorytopo.NewServer(ctx, "zone1")
+	vtctld := testutil.NewVtctldServerWithTabletManagerClient(t, ts, nil, func(ts *topo.Server) vtctlservicepb.VtctldServer {
+		return NewVtctldServer(vtenv.NewTestEnv(), ts)
+	})
+
+	_, err := ts.CreateVStreamSubscription(ctx, &binlogdatapb.VStreamSubscription{Name: "orders"})
+	require.NoError(t, err)
+
+	_, err = vtctld.DeleteVStreamSubscription(ctx, &vtctldatapb.DeleteVStreamSubscriptionRequest{Name: "orders"})
+	require.NoError(t, err)
+	_, err = ts.GetVStreamSubscription(ctx, "orders")
+	assert.True(t, topo.IsErrType(err, topo.NoNode), err)
+
+	_, err = vtctld.DeleteVStreamSubscription(ctx, &vtctldatapb.DeleteVStreamSubscriptionRequest{Name: "orders"})
+	assert.True(t, topo.IsErrType(err, topo.NoNode), err)
+}
+
 func TestEmergencyReparentShard(t *testing.T) {
 	t.Parallel()
 
@@ -8510,6 +8760,75 @@ func TestGetVSchema(t *testing.T) {
 	})
 }
 
+func TestGetVStreamSubscriptions(t *testing.T) {
+	t.Parallel()
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	ts := memorytopo.NewServer(ctx, "zone1")
+	vtctld := testutil.NewVtctldServerWithTabletManagerClient(t, ts, &testutil.TabletManagerClient{
+		TopoServer: ts,
+		FullStatusResult: &replicationdatapb.FullStatus{
+			GtidPurged: "MySQL56/3e11fa47-71ca-11e1-9e33-c80aa9429562:1-10",
+		},
+	}, func(ts *topo.Server) vtctlservicepb.VtctldServer {
+		return NewVtctldServer(vtenv.NewTestEnv(), ts)
+	})
+	testutil.AddTablets(ctx, t, ts, &testutil.AddTabletOptions{
+		AlsoSetShardPrimary: true,
+	}, &topodatapb.Tablet{
+		Alias:    &topodatapb.TabletAlias{Cell: "zone1", Uid: 100},
+		Keyspace: "ks",
+		Shard:    "0",
+		Type:     topodatapb.TabletType_PRIMARY,
+	})
+
+	now := time.Now().Unix()
+	vgtid := func(gtid string) *binlogdatapb.VGtid {
+		return &binlogdatapb.VGtid{ShardGtids: []*binlogdatapb.ShardGtid{{Keyspace: "ks", Shard: "0", Gtid: gtid}}}
+	}
+	subs := []*binlogdatapb.VStreamSubscription{{
+		Name:           "behind",
+		Vgtid:          vgtid("MySQL56/3e11fa47-71ca-11e1-9e33-c80aa9429562:1-5"),
+		CreatedAt:      now - 3600,
+		AckedTimestamp: now - 600,
+	}, {
+		Name:           "caught-up",
+		Vgtid:          vgtid("MySQL56/3e11fa47-71ca-11e1-9e33-c80aa9429562:1-20"),
+		CreatedAt:      now - 3600,
+		AckedTimestamp: now,
+	}, {
+		Name:      "new",
+		Vgtid:     vgtid("current"),
+		CreatedAt: now - 60,
+	}}
+	for _, sub := range subs {
+		_, err := ts.CreateVStreamSubscription(ctx, sub)
+		require.NoError(t, err)
+	}
+
+	resp, err := vtctld.GetVStreamSubscriptions(ctx, &vtctldatapb.GetVStreamSubscriptionsRequest{})
+	require.NoError(t, err)
+	require.Len(t, resp.Subscriptions, 3)
+	for i, sub := range subs {
+		utils.MustMatch(t, sub, resp.Subscriptions[i].Subscription)
+	}
+	assert.InDelta(t, 600, resp.Subscriptions[0].LagSeconds, 5)
+	assert.Equal(t, []string{"ks/0"}, resp.Subscriptions[0].BehindRetentionShards)
+	assert.InDelta(t, 0, resp.Subscriptions[1].LagSeconds, 5)
+	assert.Empty(t, resp.Subscriptions[1].BehindRetentionShards)
+	assert.InDelta(t, 60, resp.Subscriptions[2].LagSeconds, 5)
+	assert.Empty(t, resp.Subscriptions[2].BehindRetentionShards)
+
+	resp, err = vtctld.GetVStreamSubscriptions(ctx, &vtctldatapb.GetVStreamSubscriptionsRequest{Names: []string{"new"}})
+	require.NoError(t, err)
+	require.Len(t, resp.Subscriptions, 1)
+	assert.Equal(t, "new", resp.Subscriptions[0].Subscription.Name)
+
+	_, err = vtctld.GetVStreamSubscriptions(ctx, &vtctldatapb.GetVStreamSubscriptionsRequest{Names: []string{"unknown"}})
+	assert.True(t, topo.IsErrType(err, topo.NoNode), err)
+}
+
 func TestLaunchSchemaMigration(t *testing.T) {
 	t.Parallel()
 
@@ -0,0 +1,90 @@
+/*
+Copyright 2025 The Vitess Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package grpcvtctldserver
+
+import (
+	"context"
+	"time"
+
+	"vitess.io/vitess/go/mysql/replication"
+	"vitess.io/vitess/go/vt/topo/topoproto"
+	"vitess.io/vitess/go/vt/vterrors"
+
+	binlogdatapb "vitess.io/vitess/go/vt/proto/binlogdata"
+	vtctldatapb "vitess.io/vitess/go/vt/proto/vtctldata"
+	vtrpcpb "vitess.io/vitess/go/vt/proto/vtrpc"
+)
+
+// vstreamSubscriptionStatus returns the lag of a VStream subscription, and the
+// shards whose primary purged binary logs that the subscription did not
+// acknowledge yet. The purged positions of the shards are cached in purged.
+func (s *VtctldServer) vstreamSubscriptionStatus(ctx context.Context, sub *binlogdatapb.VStreamSubscription, now time.Time,
+	purged map[string]replication.Position) (*vtctldatapb.VStreamSubscriptionStatus, error) {
+	status := &vtctldatapb.VStreamSubscriptionStatus{Subscription: sub}
+
+	// The lag is measured from the timestamp of the last acknowledged event,
+	// or from the creation of the subscription if it never acknowledged one.
+	since := sub.AckedTimestamp
+	if since == 0 {
+		since = sub.CreatedAt
+	}
+	status.LagSeconds = max(now.Unix()-since, 0)
+
+	for _, sgtid := range sub.GetVgtid().GetShardGtids() {
+		if sgtid.Gtid == "" || sgtid.Gtid == "current" {
+			continue
+		}
+		pos, err := replication.DecodePosition(sgtid.Gtid)
+		if err != nil {
+			return nil, vterrors.Wrapf(err, "invalid position of shard %s/%s in subscription %s", sgtid.Keyspace, sgtid.Shard, sub.Name)
+		}
+		key := topoproto.KeyspaceShardString(sgtid.Keyspace, sgtid.Shard)
+		purgedPos, ok := purged[key]
+		if !ok {
+			purgedPos, err = s.getPurgedPosition(ctx, sgtid.Keyspace, sgtid.Shard)
+			if err != nil {
+				return nil, err
+			}
+			purged[key] = purgedPos
+		}
+		if !pos.AtLeast(purgedPos) {
+			status.BehindRetentionShards = append(status.BehindRetentionShards, key)
+		}
+	}
+	return status, nil
+}
+
+// getPurgedPosition returns the GTIDs that the primary of a shard purged from
+// its binary logs.
+func (s *VtctldServer) getPurgedPosition(ctx context.Context, keyspace, shard string) (replication.Position, error) {
+	si, err := s.ts.GetShard(ctx, keyspace, shard)
+	if err != nil {
+		return replication.Position{}, err
+	}
+	if si.PrimaryAlias == nil {
+		return replication.Position{}, vterrors.Errorf(vtrpcpb.Code_FAILED_PRECONDITION, "shard %s/%s has no primary", keyspace, shard)
+	}
+	ti, err := s.ts.GetTablet(ctx, si.PrimaryAlias)
+	if err != nil {
+		return replication.Position{}, err
+	}
+	fs, err := s.tmc.FullStatus(ctx, ti.Tablet)
+	if err != nil {
+		return replication.Position{}, vterrors.Wrapf(err, "failed to get the status of %s", topoproto.TabletAliasString(si.PrimaryAlias))
+	}
+	return replication.DecodePosition(fs.GtidPurged)
+}
@@ -115,6 +115,16 @@ func (client *localVtctldClient) Backup(ctx context.Context, in *vtctldatapb.Bac
 	return stream, nil
 }
 
+// BackupExpire is part of the vtctlservicepb.VtctldClient interface.
+func (client *localVtctldClient) BackupExpire(ctx context.Context, in *vtctldatapb.BackupExpireRequest, opts ...grpc.CallOption) (*vtctldatapb.BackupExpireResponse, error) {
+	return client.s.BackupExpire(ctx, in)
+}
+
+// BackupRestorePlan is part of the vtctlservicepb.VtctldClient interface.
+func (client *localVtctldClient) BackupRestorePlan(ctx context.Context, in *vtctldatapb.BackupRestorePlanRequest, opts ...grpc.CallOption) (*vtctldatapb.BackupRestorePlanResponse, error) {
+	return client.s.BackupRestorePlan(ctx, in)
+}
+
 type backupShardStreamAdapter struct {
 	*grpcshim.BidiStream
 	ch chan *vtctldatapb.BackupResponse
@@ -166,6 +176,11 @@ func (client *localVtctldClient) BackupShard(ctx context.Context, in *vtctldatap
 	return stream, nil
 }
 
+// BackupValidate is part of the vtctlservicepb.VtctldClient interface.
+func (client *localVtctldClient) BackupValidate(ctx context.Context, in *vtctldatapb.BackupValidateRequest, opts ...grpc.CallOption) (*vtctldatapb.BackupValidateResponse, error) {
+	return client.s.BackupValidate(ctx, in)
+}
+
 // CancelSchemaMigration is part of the vtctlservicepb.VtctldClient interface.
 func (client *localVtctldClient) CancelSchemaMigration(ctx context.Context, in *vtctldatapb.CancelSchemaMigrationRequest, opts ...grpc.CallOption) (*vtctldatapb.CancelSchemaMigrationResponse, error) {
 	return client.s.CancelSchemaMigration(ctx, in)
@@ -246,6 +261,11 @@ func (client *localVtctldClient) DeleteTablets(ctx context.Context, in *vtctldat
 	return client.s.DeleteTablets(ctx, in)
 }
 
+// DeleteVStreamSubscription is part of the vtctlservicepb.VtctldClient interface.
+func (client *localVtctldClient) DeleteVStreamSubscription(ctx context.Context, in *vtctldatapb.DeleteVStreamSubscriptionRequest, opts ...grpc.CallOption) (*vtctldatapb.DeleteVStreamSubscriptionResponse, error) {
+	return client.s.DeleteVStreamSubscription(ctx, in)
+}
+
 // EmergencyReparentShard is part of the vtctlservicepb.VtctldClient interface.
 func (client *localVtctldClient) EmergencyReparentShard(ctx context.Context, in *vtctldatapb.EmergencyReparentShardRequest, opts ...grpc.CallOption) (*vtctldatapb.EmergencyReparentShardResponse, error) {
 	return client.s.EmergencyReparentShard(ctx, in)
@@ -416,6 +436,11 @@ func (client *localVtctldClient) GetVSchema(ctx context.Context, in *vtctldatapb
 	return client.s.GetVSchema(ctx, in)
 }
 
+// GetVStreamSubscriptions is part of the vtctlservicepb.VtctldClient interface.
+func (client *localVtctldClient) GetVStreamSubscriptions(ctx context.Context, in *vtctldatapb.GetVStreamSubscriptionsRequest, opts ...grpc.CallOption) (*vtctldatapb.GetVStreamSubscriptionsResponse, error) {
+	return client.s.GetVStreamSubscriptions(ctx, in)
+}
+
 // GetVersion is part of the vtctlservicepb.VtctldClient interface.
 func (client *localVtctldClient) GetVersion(ctx context.Context, in *vtctldatapb.GetVersionRequest, opts ...grpc.CallOption) (*vtctldatapb.GetVersionResponse, error) {
 	return client.s.GetVersion(ctx, in)
@@ -566,6 +591,11 @@ func (client *localVtctldClient) ReshardCreate(ctx context.Context, in *vtctldat
 	return client.s.ReshardCreate(ctx, in)
 }
 
+// ReshardPlan is part of the vtctlservicepb.VtctldClient interface.
+func (client *localVtctldClient) ReshardPlan(ctx context.Context, in *vtctldatapb.ReshardPlanRequest, opts ...grpc.CallOption) (*vtctldatapb.ReshardPlanResponse, error) {
+	return client.s.ReshardPlan(ctx, in)
+}
+
 type restoreFromBackupStreamAdapter struct {
 	*grpcshim.BidiStream
 	ch chan *vtctldatapb.RestoreFromBackupResponse
@@ -722,6 +752,11 @@ func (client *localVtctldClient) VDiffDelete(ctx context.Context, in *vtctldatap
 	return client.s.VDiffDelete(ctx, in)
 }
 
+// VDiffRepair is part of the vtctlservicepb.VtctldClient interface.
+func (client *localVtctldClient) VDiffRepair(ctx context.Context, in *vtctldatapb.VDiffRepairRequest, opts ...grpc.CallOption) (*vtctldatapb.VDiffRepairResponse, error) {
+	return client.s.VDiffRepair(ctx, in)
+}
+
 // VDiffResume is part of the vtctlservicepb.VtctldClient interface.
 func (client *localVtctldClient) VDiffResume(ctx context.Context, in *vtctldatapb.VDiffResumeRequest, opts ...grpc.CallOption) (*vtctldatapb.VDiffResumeResponse, error) {
 	return client.s.VDiffResume(ctx, in)
@@ -212,7 +212,8 @@ func (mz *materializer) generateBinlogSources(targetShard *topo.ShardInfo, sourc
 
 func (mz *materializer) generateRule(ts *vtctldatapb.TableMaterializeSettings, targetShard *topo.ShardInfo, tenantClause *sqlparser.Expr, keyRangesEqual bool) (*binlogdatapb.Rule, error) {
 	rule := &binlogdatapb.Rule{
-		Match: ts.TargetTable,
+		Match:            ts.TargetTable,
+		ColumnTransforms: ts.ColumnTransforms,
 	}
 
 	if ts.SourceExpression == "" {
@@ -3529,3 +3529,22 @@ func TestValidateEmptyTables(t *testing.T) {
 	// Check if the error message doesn't include duplicate tables
 	assert.Equal(t, strings.Count(err.Error(), "table3"), 1)
 }
+
+func TestGenerateRuleColumnTransforms(t *testing.T) {
+	mz := &materializer{
+		ms:  &vtctldatapb.MaterializeSettings{SourceKeyspace: "ks", TargetKeyspace: "ks"},
+		env: vtenv.NewTestEnv(),
+	}
+	transforms := map[string]*binlogdatapb.ColumnTransform{
+		"email": {Type: binlogdatapb.ColumnTransform_HASH},
+	}
+	for _, sourceExpression := range []string{"", "select id, email from t1"} {
+		rule, err := mz.generateRule(&vtctldatapb.TableMaterializeSettings{
+			TargetTable:      "t1",
+			SourceExpression: sourceExpression,
+			ColumnTransforms: transforms,
+		}, nil, nil, true)
+		require.NoError(t, err)
+		utils.MustMatch(t, transforms, rule.ColumnTransforms)
+	}
+}
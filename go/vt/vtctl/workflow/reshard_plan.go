@@ -0,0 +1,531 @@
+/*
+Copyright 2025 The Vitess Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+	http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package workflow
+
+import (
+	"bytes"
+	"context"
+	"encoding/binary"
+	"encoding/hex"
+	"fmt"
+	"math"
+	"slices"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+
+	"vitess.io/vitess/go/sqlescape"
+	"vitess.io/vitess/go/sqltypes"
+	"vitess.io/vitess/go/trace"
+	"vitess.io/vitess/go/vt/key"
+	"vitess.io/vitess/go/vt/mysqlctl/tmutils"
+	"vitess.io/vitess/go/vt/topo"
+	"vitess.io/vitess/go/vt/topo/topoproto"
+	"vitess.io/vitess/go/vt/vterrors"
+	"vitess.io/vitess/go/vt/vtgate/vindexes"
+
+	tabletmanagerdatapb "vitess.io/vitess/go/vt/proto/tabletmanagerdata"
+	topodatapb "vitess.io/vitess/go/vt/proto/topodata"
+	vtctldatapb "vitess.io/vitess/go/vt/proto/vtctldata"
+	vtrpcpb "vitess.io/vitess/go/vt/proto/vtrpc"
+)
+
+const (
+	// defaultReshardPlanSampleSize is the default maximum number of rows
+	// sampled per table and source shard.
+	defaultReshardPlanSampleSize = 10000
+	// defaultHotKeyspaceIDThreshold is the default share of the rows that a
+	// single keyspace ID must hold to be reported as hot.
+	defaultHotKeyspaceIDThreshold = 0.01
+	// splitPointBytes is the number of leading bytes of the keyspace IDs
+	// that recommended split points are rounded to.
+	splitPointBytes = 2
+)
+
+// reshardPlanTable is how a table is sampled for a reshard plan.
+type reshardPlanTable struct {
+	vindex        vindexes.Vindex
+	vindexColumns []string
+	// skipReason is set when the table cannot be sampled.
+	skipReason string
+}
+
+// ksidSample holds the estimated number of rows, and bytes, that map to a
+// keyspace ID.
+type ksidSample struct {
+	keyspaceID []byte
+	rows       float64
+	bytes      float64
+}
+
+// reshardPlanner accumulates the samples and table sizes of the source
+// shards of a reshard plan.
+type reshardPlanner struct {
+	s          *Server
+	sampleSize int64
+	tables     map[string]*reshardPlanTable
+
+	mu           sync.Mutex
+	samples      map[string]*ksidSample
+	tableStats   map[string]*vtctldatapb.ReshardPlanResponse_Table
+	sourceShards []*vtctldatapb.ReshardPlanResponse_Shard
+}
+
+// ReshardPlan is part of the vtctlservicepb.VtctldServer interface.
+// It samples the keyspace IDs of the rows of the source shards, from their
+// RDONLY or REPLICA tablets, using the
+// primary vindex of each table, and weighs them by the table sizes of the
+// shards to project how the data would be distributed over the proposed
+// target shards. Balanced split points are recommended from the same
+// samples, and keyspace IDs that hold a large share of the rows, and so
+// cannot be split up, are reported.
+func (s *Server) ReshardPlan(ctx context.Context, req *vtctldatapb.ReshardPlanRequest) (*vtctldatapb.ReshardPlanResponse, error) {
+	span, ctx := trace.NewSpan(ctx, "workflow.Server.ReshardPlan")
+	defer span.Finish()
+
+	span.Annotate("keyspace", req.Keyspace)
+	span.Annotate("source_shards", req.SourceShards)
+	span.Annotate("target_shards", req.TargetShards)
+	span.Annotate("target_shard_count", req.TargetShardCount)
+	span.Annotate("sample_size", req.SampleSize)
+
+	sampleSize := req.SampleSize
+	if sampleSize <= 0 {
+		sampleSize = defaultReshardPlanSampleSize
+	}
+	threshold := req.HotKeyspaceIdThreshold
+	if threshold <= 0 {
+		threshold = defaultHotKeyspaceIDThreshold
+	}
+	if threshold > 1 {
+		return nil, vterrors.Errorf(vtrpcpb.Code_INVALID_ARGUMENT, "invalid hot keyspace ID threshold %v: it must be between 0 and 1", threshold)
+	}
+	shardCount := int(req.TargetShardCount)
+	if shardCount <= 0 {
+		shardCount = len(req.TargetShards)
+	}
+	if shardCount == 0 {
+		return nil, vterrors.Errorf(vtrpcpb.Code_INVALID_ARGUMENT, "either target shards or a target shard count must be provided")
+	}
+
+	var sourceShards []*topo.ShardInfo
+	if len(req.SourceShards) == 0 {
+		shards, err := s.ts.GetServingShards(ctx, req.Keyspace)
+		if err != nil {
+			return nil, err
+		}
+		sourceShards = shards
+	}
+	for _, shard := range req.SourceShards {
+		si, err := s.ts.GetShard(ctx, req.Keyspace, shard)
+		if err != nil {
+			return nil, vterrors.Wrapf(err, "GetShard(%s) failed", shard)
+		}
+		sourceShards = append(sourceShards, si)
+	}
+	for _, si := range sourceShards {
+		if si.PrimaryAlias == nil {
+			return nil, fmt.Errorf("source shard %v has no primary tablet", si.ShardName())
+		}
+	}
+	sourceRanges := make([]*topodatapb.KeyRange, 0, len(sourceShards))
+	for _, si := range sourceShards {
+		sourceRanges = append(sourceRanges, si.GetKeyRange())
+	}
+	sourceRange, err := keyRangeUnion(sourceRanges)
+	if err != nil {
+		return nil, vterrors.Wrap(err, "invalid source shards")
+	}
+	targetRanges := make([]*topodatapb.KeyRange, 0, len(req.TargetShards))
+	for _, shard := range req.TargetShards {
+		krs, err := key.ParseShardingSpec(shard)
+		if err != nil || len(krs) != 1 {
+			return nil, vterrors.Errorf(vtrpcpb.Code_INVALID_ARGUMENT, "invalid target shard %s", shard)
+		}
+		targetRanges = append(targetRanges, krs[0])
+	}
+	if len(targetRanges) > 0 {
+		targetRange, err := keyRangeUnion(targetRanges)
+		if err != nil {
+			return nil, vterrors.Wrap(err, "invalid target shards")
+		}
+		if !key.KeyRangeEqual(sourceRange, targetRange) {
+			return nil, vterrors.Errorf(vtrpcpb.Code_INVALID_ARGUMENT, "the target shards cover the key range %s while the source shards cover %s",
+				key.KeyRangeString(targetRange), key.KeyRangeString(sourceRange))
+		}
+	}
+
+	tables, err := s.reshardPlanTables(ctx, req.Keyspace)
+	if err != nil {
+		return nil, err
+	}
+	rp := &reshardPlanner{
+		s:          s,
+		sampleSize: sampleSize,
+		tables:     tables,
+		samples:    make(map[string]*ksidSample),
+		tableStats: make(map[string]*vtctldatapb.ReshardPlanResponse_Table),
+	}
+	if err := forAllShards(sourceShards, func(si *topo.ShardInfo) error {
+		return rp.sampleShard(ctx, si)
+	}); err != nil {
+		return nil, err
+	}
+
+	samples := make([]*ksidSample, 0, len(rp.samples))
+	var totalRows float64
+	for _, sample := range rp.samples {
+		samples = append(samples, sample)
+		totalRows += sample.rows
+	}
+	sort.Slice(samples, func(i, j int) bool {
+		return bytes.Compare(samples[i].keyspaceID, samples[j].keyspaceID) < 0
+	})
+
+	resp := &vtctldatapb.ReshardPlanResponse{
+		SourceShards:      rp.sourceShards,
+		TargetShards:      projectShards(targetRanges, samples),
+		RecommendedShards: projectShards(recommendSplitPoints(sourceRange, samples, shardCount), samples),
+	}
+	sort.Slice(resp.SourceShards, func(i, j int) bool {
+		return resp.SourceShards[i].Name < resp.SourceShards[j].Name
+	})
+	for _, table := range rp.tableStats {
+		resp.Tables = append(resp.Tables, table)
+	}
+	sort.Slice(resp.Tables, func(i, j int) bool {
+		return resp.Tables[i].Name < resp.Tables[j].Name
+	})
+	for _, sample := range samples {
+		share := sample.rows / totalRows
+		if share < threshold {
+			continue
+		}
+		resp.HotKeyspaceIds = append(resp.HotKeyspaceIds, &vtctldatapb.ReshardPlanResponse_HotKeyspaceId{
+			KeyspaceId: hex.EncodeToString(sample.keyspaceID),
+			RowCount:   uint64(math.Round(sample.rows)),
+			DataLength: uint64(math.Round(sample.bytes)),
+			Share:      share,
+		})
+	}
+	sort.SliceStable(resp.HotKeyspaceIds, func(i, j int) bool {
+		return resp.HotKeyspaceIds[i].Share > resp.HotKeyspaceIds[j].Share
+	})
+	return resp, nil
+}
+
+// reshardPlanTables returns how each table of the keyspace's vschema is
+// sampled. Only tables with a primary vindex that maps values to keyspace
+// IDs on its own can be sampled.
+func (s *Server) reshardPlanTables(ctx context.Context, keyspace string) (map[string]*reshardPlanTable, error) {
+	vschema, err := s.ts.GetVSchema(ctx, keyspace)
+	if err != nil {
+		return nil, vterrors.Wrap(err, "GetVSchema")
+	}
+	if !vschema.Sharded {
+		return nil, vterrors.Errorf(vtrpcpb.Code_FAILED_PRECONDITION, "keyspace %s is not sharded in its vschema", keyspace)
+	}
+	ksSchema, err := vindexes.BuildKeyspaceSchema(vschema.Keyspace, keyspace, s.env.Parser())
+	if err != nil {
+		return nil, vterrors.Wrap(err, "BuildKeyspaceSchema")
+	}
+	tables := make(map[string]*reshardPlanTable, len(ksSchema.Tables))
+	for name, table := range ksSchema.Tables {
+		pt := &reshardPlanTable{}
+		tables[name] = pt
+		switch {
+		case table.Type != "":
+			pt.skipReason = fmt.Sprintf("%s table", table.Type)
+		case len(table.ColumnVindexes) == 0:
+			pt.skipReason = "no primary vindex"
+		case table.ColumnVindexes[0].Vindex.NeedsVCursor():
+			pt.skipReason = fmt.Sprintf("primary vindex %s needs a lookup to map keyspace IDs", table.ColumnVindexes[0].Name)
+		default:
+			pt.vindex = table.ColumnVindexes[0].Vindex
+			for _, col := range table.ColumnVindexes[0].Columns {
+				pt.vindexColumns = append(pt.vindexColumns, col.String())
+			}
+		}
+	}
+	return tables, nil
+}
+
+// sampleShard samples the keyspace IDs of the tables of a source shard
+// from one of its RDONLY or REPLICA tablets.
+func (rp *reshardPlanner) sampleShard(ctx context.Context, si *topo.ShardInfo) error {
+	tablet, err := rp.sampleTablet(ctx, si)
+	if err != nil {
+		return err
+	}
+	schema, err := rp.s.tmc.GetSchema(ctx, tablet, &tabletmanagerdatapb.GetSchemaRequest{Tables: []string{"/.*/"}})
+	if err != nil {
+		return vterrors.Wrapf(err, "GetSchema(%v)", tablet)
+	}
+	shardStats := &vtctldatapb.ReshardPlanResponse_Shard{Name: si.ShardName()}
+	for _, td := range schema.TableDefinitions {
+		if td.Type != tmutils.TableBaseTable {
+			continue
+		}
+		shardStats.RowCount += td.RowCount
+		shardStats.DataLength += td.DataLength
+
+		table := rp.tables[td.Name]
+		var samples map[string]*ksidSample
+		var sampled int
+		if table != nil && table.skipReason == "" {
+			if samples, sampled, err = rp.sampleTable(ctx, tablet, table, td); err != nil {
+				return vterrors.Wrapf(err, "failed to sample table %s on %v", td.Name, tablet)
+			}
+		}
+		rp.addTable(td, table, samples, sampled)
+	}
+	rp.mu.Lock()
+	defer rp.mu.Unlock()
+	rp.sourceShards = append(rp.sourceShards, shardStats)
+	return nil
+}
+
+// sampleTablet returns the tablet that the tables of a source shard are
+// sampled from. Sampling a table scans it, so an RDONLY tablet is preferred,
+// then a REPLICA tablet, and the primary tablet is never used.
+func (rp *reshardPlanner) sampleTablet(ctx context.Context, si *topo.ShardInfo) (*topodatapb.Tablet, error) {
+	tablets, err := rp.s.ts.GetTabletMapForShard(ctx, si.Keyspace(), si.ShardName())
+	if err != nil {
+		return nil, vterrors.Wrapf(err, "GetTabletMapForShard(%s) failed", si.ShardName())
+	}
+	var candidates []*topodatapb.Tablet
+	for _, ti := range tablets {
+		if ti.Type == topodatapb.TabletType_RDONLY || ti.Type == topodatapb.TabletType_REPLICA {
+			candidates = append(candidates, ti.Tablet)
+		}
+	}
+	if len(candidates) == 0 {
+		return nil, vterrors.Errorf(vtrpcpb.Code_FAILED_PRECONDITION, "source shard %s has no RDONLY or REPLICA tablet to sample its tables from", si.ShardName())
+	}
+	sort.Slice(candidates, func(i, j int) bool {
+		if candidates[i].Type != candidates[j].Type {
+			return candidates[i].Type == topodatapb.TabletType_RDONLY
+		}
+		return topoproto.TabletAliasString(candidates[i].Alias) < topoproto.TabletAliasString(candidates[j].Alias)
+	})
+	return candidates[0], nil
+}
+
+// sampleTable reads a random sample of the primary vindex columns of a
+// table and maps them to keyspace IDs. Each sampled row stands for its
+// share of the table's estimated rows and bytes.
+func (rp *reshardPlanner) sampleTable(ctx context.Context, tablet *topodatapb.Tablet, table *reshardPlanTable, td *tabletmanagerdatapb.TableDefinition) (map[string]*ksidSample, int, error) {
+	query := fmt.Sprintf("select %s from %s", strings.Join(sqlescape.EscapeIDs(table.vindexColumns), ", "), sqlescape.EscapeID(td.Name))
+	sampling := td.RowCount > uint64(rp.sampleSize)
+	if sampling {
+		fraction := float64(rp.sampleSize) / float64(td.RowCount)
+		query += " where rand() <= " + strconv.FormatFloat(fraction, 'g', -1, 64)
+	}
+	query += fmt.Sprintf(" limit %d", rp.sampleSize)
+	qr, err := rp.s.tmc.ExecuteFetchAsApp(ctx, tablet, true, &tabletmanagerdatapb.ExecuteFetchAsAppRequest{
+		Query:   []byte(query),
+		MaxRows: uint64(rp.sampleSize),
+	})
+	if err != nil {
+		return nil, 0, err
+	}
+	res := sqltypes.Proto3ToResult(qr)
+	if len(res.Rows) == 0 {
+		return nil, 0, nil
+	}
+	rows := float64(td.RowCount)
+	if !sampling || rows < float64(len(res.Rows)) {
+		// The whole table was read, which is more accurate than the
+		// estimated row count.
+		rows = float64(len(res.Rows))
+	}
+	rowsPerSample := rows / float64(len(res.Rows))
+	bytesPerSample := float64(td.DataLength) / float64(len(res.Rows))
+	samples := make(map[string]*ksidSample)
+	for _, row := range res.Rows {
+		destinations, err := vindexes.Map(ctx, table.vindex, nil, [][]sqltypes.Value{row})
+		if err != nil {
+			return nil, 0, err
+		}
+		ksid, ok := destinations[0].(key.DestinationKeyspaceID)
+		if !ok || len(ksid) == 0 {
+			return nil, 0, fmt.Errorf("could not map %v to a keyspace id, got destination %v", row, destinations[0])
+		}
+		sample := samples[string(ksid)]
+		if sample == nil {
+			sample = &ksidSample{keyspaceID: ksid}
+			samples[string(ksid)] = sample
+		}
+		sample.rows += rowsPerSample
+		sample.bytes += bytesPerSample
+	}
+	return samples, len(res.Rows), nil
+}
+
+// addTable adds the size and the samples of a table of a source shard to
+// the plan.
+func (rp *reshardPlanner) addTable(td *tabletmanagerdatapb.TableDefinition, table *reshardPlanTable, samples map[string]*ksidSample, sampled int) {
+	rp.mu.Lock()
+	defer rp.mu.Unlock()
+	stats := rp.tableStats[td.Name]
+	if stats == nil {
+		stats = &vtctldatapb.ReshardPlanResponse_Table{Name: td.Name}
+		switch {
+		case table == nil:
+			stats.SkipReason = "not in the vschema"
+		default:
+			stats.SkipReason = table.skipReason
+		}
+		rp.tableStats[td.Name] = stats
+	}
+	stats.RowCount += td.RowCount
+	stats.DataLength += td.DataLength
+	stats.SampledRows += uint64(sampled)
+	for ksid, sample := range samples {
+		if existing := rp.samples[ksid]; existing != nil {
+			existing.rows += sample.rows
+			existing.bytes += sample.bytes
+			continue
+		}
+		rp.samples[ksid] = sample
+	}
+}
+
+// projectShards returns the projected size of each key range, given the
+// samples sorted by keyspace ID.
+func projectShards(ranges []*topodatapb.KeyRange, samples []*ksidSample) []*vtctldatapb.ReshardPlanResponse_Shard {
+	ranges = slices.Clone(ranges)
+	slices.SortFunc(ranges, key.KeyRangeCompare)
+	shards := make([]*vtctldatapb.ReshardPlanResponse_Shard, 0, len(ranges))
+	i := 0
+	for _, kr := range ranges {
+		var rows, bytes float64
+		for ; i < len(samples) && key.KeyRangeContains(kr, samples[i].keyspaceID); i++ {
+			rows += samples[i].rows
+			bytes += samples[i].bytes
+		}
+		shards = append(shards, &vtctldatapb.ReshardPlanResponse_Shard{
+			Name:       key.KeyRangeString(kr),
+			RowCount:   uint64(math.Round(rows)),
+			DataLength: uint64(math.Round(bytes)),
+		})
+	}
+	return shards
+}
+
+// recommendSplitPoints splits keyRange into count key ranges holding about
+// the same amount of data, given the samples sorted by keyspace ID. The
+// split points are rounded to splitPointBytes bytes, so fewer key ranges
+// are returned when the data is too concentrated to be split count ways.
+// Nothing is returned when there are no samples.
+func recommendSplitPoints(keyRange *topodatapb.KeyRange, samples []*ksidSample, count int) []*topodatapb.KeyRange {
+	var totalRows, totalBytes float64
+	for _, sample := range samples {
+		totalRows += sample.rows
+		totalBytes += sample.bytes
+	}
+	weight := func(sample *ksidSample) float64 {
+		if totalBytes > 0 {
+			return sample.bytes
+		}
+		return sample.rows
+	}
+	total := totalBytes
+	if total == 0 {
+		total = totalRows
+	}
+	if total == 0 {
+		return nil
+	}
+
+	const maxPoint = 1 << (8 * splitPointBytes)
+	start := splitPoint(keyRange.GetStart(), true)
+	end := uint64(maxPoint)
+	if !key.Empty(keyRange.GetEnd()) {
+		end = splitPoint(keyRange.GetEnd(), false)
+	}
+	var points []uint64
+	prev := start
+	var cumulative float64
+	i, last := 0, 0
+	for n := 1; n < count; n++ {
+		target := total * float64(n) / float64(count)
+		for ; i < len(samples) && cumulative < target; i++ {
+			cumulative += weight(samples[i])
+		}
+		if i == last {
+			// The last split point already holds more than this target.
+			continue
+		}
+		last = i
+		// Split right after the prefix of the last sample that is needed
+		// to reach the target, so that it stays on the left.
+		point := splitPoint(samples[i-1].keyspaceID, false) + 1
+		point = max(point, prev+1)
+		if point >= end {
+			break
+		}
+		points = append(points, point)
+		prev = point
+	}
+
+	ranges := make([]*topodatapb.KeyRange, 0, len(points)+1)
+	rangeStart := keyRange.GetStart()
+	for _, point := range points {
+		buf := make([]byte, 8)
+		binary.BigEndian.PutUint64(buf, point)
+		boundary := bytes.TrimRight(buf[8-splitPointBytes:], "\x00")
+		ranges = append(ranges, &topodatapb.KeyRange{Start: rangeStart, End: boundary})
+		rangeStart = boundary
+	}
+	return append(ranges, &topodatapb.KeyRange{Start: rangeStart, End: keyRange.GetEnd()})
+}
+
+// splitPoint returns the first splitPointBytes bytes of a keyspace ID as a
+// number, rounded up when roundUp is set and any of the other bytes are set.
+func splitPoint(keyspaceID []byte, roundUp bool) uint64 {
+	buf := make([]byte, splitPointBytes)
+	copy(buf, keyspaceID)
+	var point uint64
+	for _, b := range buf {
+		point = point<<8 | uint64(b)
+	}
+	if roundUp && len(keyspaceID) > splitPointBytes && len(bytes.TrimRight(keyspaceID[splitPointBytes:], "\x00")) > 0 {
+		point++
+	}
+	return point
+}
+
+// keyRangeUnion returns the key range covered by the given key ranges,
+// which must be contiguous and must not overlap.
+func keyRangeUnion(ranges []*topodatapb.KeyRange) (*topodatapb.KeyRange, error) {
+	if len(ranges) == 0 {
+		return nil, vterrors.Errorf(vtrpcpb.Code_INVALID_ARGUMENT, "no shards")
+	}
+	ranges = slices.Clone(ranges)
+	slices.SortFunc(ranges, key.KeyRangeCompare)
+	union := ranges[0]
+	for _, kr := range ranges[1:] {
+		if !key.KeyRangeContiguous(union, kr) {
+			return nil, vterrors.Errorf(vtrpcpb.Code_INVALID_ARGUMENT, "key ranges %s and %s are not contiguous",
+				key.KeyRangeString(union), key.KeyRangeString(kr))
+		}
+		union = &topodatapb.KeyRange{Start: union.Start, End: kr.End}
+	}
+	return union, nil
+}
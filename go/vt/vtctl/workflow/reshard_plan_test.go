@@ -0,0 +1,173 @@
+/*
+Copyright 2025 The Vitess Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package workflow
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"vitess.io/vitess/go/sqltypes"
+	"vitess.io/vitess/go/vt/key"
+	"vitess.io/vitess/go/vt/mysqlctl/tmutils"
+	"vitess.io/vitess/go/vt/topo"
+
+	tabletmanagerdatapb "vitess.io/vitess/go/vt/proto/tabletmanagerdata"
+	topodatapb "vitess.io/vitess/go/vt/proto/topodata"
+	vschemapb "vitess.io/vitess/go/vt/proto/vschema"
+	vtctldatapb "vitess.io/vitess/go/vt/proto/vtctldata"
+)
+
+func TestReshardPlan(t *testing.T) {
+	ctx, cancel := context.WithTimeout(context.Background(), 60*time.Second)
+	defer cancel()
+
+	keyspace := &testKeyspace{
+		KeyspaceName: "ks",
+		ShardNames:   []string{"0"},
+	}
+	// The target shards are not serving, so only shard 0 is sampled.
+	env := newTestEnv(t, ctx, defaultCellName, keyspace, &testKeyspace{
+		KeyspaceName: "ks",
+		ShardNames:   []string{"-80", "80-"},
+	})
+	defer env.close()
+
+	err := env.ts.SaveVSchema(ctx, &topo.KeyspaceVSchemaInfo{
+		Name: keyspace.KeyspaceName,
+		Keyspace: &vschemapb.Keyspace{
+			Sharded: true,
+			Vindexes: map[string]*vschemapb.Vindex{
+				"hash": {Type: "hash"},
+			},
+			Tables: map[string]*vschemapb.Table{
+				"t1": {
+					ColumnVindexes: []*vschemapb.ColumnVindex{{Column: "customer_id", Name: "hash"}},
+				},
+			},
+		},
+	})
+	require.NoError(t, err)
+	env.tmc.schema = map[string]*tabletmanagerdatapb.SchemaDefinition{
+		"ks.t1": {
+			TableDefinitions: []*tabletmanagerdatapb.TableDefinition{
+				{Name: "t1", Type: tmutils.TableBaseTable, RowCount: 5, DataLength: 500},
+			},
+		},
+		"ks.t2": {
+			TableDefinitions: []*tabletmanagerdatapb.TableDefinition{
+				{Name: "t2", Type: tmutils.TableBaseTable, RowCount: 10, DataLength: 100},
+			},
+		},
+	}
+	// The keyspace IDs of 1 and 2 are below 0x80, and those of 3 and 4 are
+	// 4eb190c9a2fa169c and d2fd8867d50d2dfe.
+
+	// The tables are never sampled from the primary tablet.
+	_, err = env.ws.ReshardPlan(ctx, &vtctldatapb.ReshardPlanRequest{
+		Keyspace:     keyspace.KeyspaceName,
+		TargetShards: []string{"80-", "-80"},
+	})
+	require.ErrorContains(t, err, "source shard 0 has no RDONLY or REPLICA tablet to sample its tables from")
+
+	// An RDONLY tablet is preferred over a REPLICA tablet.
+	env.addTablet(t, ctx, startingSourceTabletUID+1, keyspace.KeyspaceName, "0", topodatapb.TabletType_REPLICA, true)
+	env.addTablet(t, ctx, startingSourceTabletUID+2, keyspace.KeyspaceName, "0", topodatapb.TabletType_RDONLY, true)
+	env.tmc.expectVRQuery(startingSourceTabletUID+2, "select `customer_id` from `t1` limit 10000",
+		sqltypes.MakeTestResult(sqltypes.MakeTestFields("customer_id", "int64"), "1", "1", "2", "3", "4"))
+
+	_, err = env.ws.ReshardPlan(ctx, &vtctldatapb.ReshardPlanRequest{
+		Keyspace:     keyspace.KeyspaceName,
+		TargetShards: []string{"-80"},
+	})
+	require.ErrorContains(t, err, "the target shards cover the key range -80 while the source shards cover -")
+
+	resp, err := env.ws.ReshardPlan(ctx, &vtctldatapb.ReshardPlanRequest{
+		Keyspace:               keyspace.KeyspaceName,
+		TargetShards:           []string{"80-", "-80"},
+		HotKeyspaceIdThreshold: 0.3,
+	})
+	require.NoError(t, err)
+	want := &vtctldatapb.ReshardPlanResponse{
+		Tables: []*vtctldatapb.ReshardPlanResponse_Table{
+			{Name: "t1", RowCount: 5, DataLength: 500, SampledRows: 5},
+			{Name: "t2", RowCount: 10, DataLength: 100, SkipReason: "not in the vschema"},
+		},
+		SourceShards: []*vtctldatapb.ReshardPlanResponse_Shard{
+			{Name: "0", RowCount: 15, DataLength: 600},
+		},
+		TargetShards: []*vtctldatapb.ReshardPlanResponse_Shard{
+			{Name: "-80", RowCount: 4, DataLength: 400},
+			{Name: "80-", RowCount: 1, DataLength: 100},
+		},
+		RecommendedShards: []*vtctldatapb.ReshardPlanResponse_Shard{
+			{Name: "-166c", RowCount: 3, DataLength: 300},
+			{Name: "166c-", RowCount: 2, DataLength: 200},
+		},
+		HotKeyspaceIds: []*vtctldatapb.ReshardPlanResponse_HotKeyspaceId{
+			{KeyspaceId: "166b40b44aba4bd6", RowCount: 2, DataLength: 200, Share: 0.4},
+		},
+	}
+	assert.EqualValues(t, want, resp)
+
+	_, err = env.ts.UpdateShardFields(ctx, keyspace.KeyspaceName, "0", func(si *topo.ShardInfo) error {
+		si.PrimaryAlias = nil
+		return nil
+	})
+	require.NoError(t, err)
+	_, err = env.ws.ReshardPlan(ctx, &vtctldatapb.ReshardPlanRequest{
+		Keyspace:     keyspace.KeyspaceName,
+		TargetShards: []string{"80-", "-80"},
+	})
+	require.ErrorContains(t, err, "source shard 0 has no primary tablet")
+}
+
+func TestRecommendSplitPoints(t *testing.T) {
+	// One sample at the start of each 1/16th of the key range, the last
+	// ones holding twice as much data.
+	var samples []*ksidSample
+	for i := range 16 {
+		sample := &ksidSample{keyspaceID: []byte{byte(i << 4)}, rows: 1, bytes: 100}
+		if i >= 12 {
+			sample.bytes = 200
+		}
+		samples = append(samples, sample)
+	}
+	shardNames := func(ranges []*topodatapb.KeyRange) []string {
+		var names []string
+		for _, kr := range ranges {
+			names = append(names, key.KeyRangeString(kr))
+		}
+		return names
+	}
+
+	complete := &topodatapb.KeyRange{}
+	assert.Equal(t, []string{"-4001", "4001-9001", "9001-d001", "d001-"}, shardNames(recommendSplitPoints(complete, samples, 4)))
+	assert.Equal(t, []string{"-"}, shardNames(recommendSplitPoints(complete, samples, 1)))
+	assert.Nil(t, recommendSplitPoints(complete, nil, 4))
+
+	// The split points are limited to the key range being split.
+	upper := &topodatapb.KeyRange{Start: []byte{0x80}}
+	assert.Equal(t, []string{"80-a001", "a001-c001", "c001-e001", "e001-"}, shardNames(recommendSplitPoints(upper, samples[8:], 4)))
+
+	// A single keyspace ID cannot be split up.
+	hot := []*ksidSample{{keyspaceID: []byte{0x40}, rows: 1, bytes: 100}}
+	assert.Equal(t, []string{"-4001", "4001-"}, shardNames(recommendSplitPoints(complete, hot, 4)))
+}
@@ -362,12 +362,16 @@ func (s *Server) VDiffCreate(ctx context.Context, req *vtctldatapb.VDiffCreateRe
 			UpdateTableStats:      req.UpdateTableStats,
 			MaxDiffSeconds:        req.MaxDiffDuration.Seconds,
 			AutoStart:             &autoStart,
+			ChunkRows:             req.ChunkRows,
+			MaxConcurrentChunks:   req.MaxConcurrentChunks,
 		},
 		ReportOptions: &tabletmanagerdatapb.VDiffReportOptions{
 			OnlyPks:                 req.OnlyPKs,
 			DebugQuery:              req.DebugQuery,
 			MaxSampleRows:           req.MaxReportSampleRows,
 			RowDiffColumnTruncateAt: req.RowDiffColumnTruncateAt,
+			ExportPath:              req.ExportPath,
+			ExportRepairSql:         req.ExportRepairSql,
 		},
 	}
 
@@ -445,6 +449,56 @@ func (s *Server) VDiffDelete(ctx context.Context, req *vtctldatapb.VDiffDeleteRe
 	return &vtctldatapb.VDiffDeleteResponse{}, nil
 }
 
+// VDiffRepair is part of the vtctlservicepb.VtctldServer interface.
+func (s *Server) VDiffRepair(ctx context.Context, req *vtctldatapb.VDiffRepairRequest) (*vtctldatapb.VDiffRepairResponse, error) {
+	span, ctx := trace.NewSpan(ctx, "workflow.Server.VDiffRepair")
+	defer span.Finish()
+
+	targetShards := req.GetTargetShards()
+
+	span.Annotate("keyspace", req.TargetKeyspace)
+	span.Annotate("workflow", req.Workflow)
+	span.Annotate("uuid", req.Uuid)
+	span.Annotate("target_shards", targetShards)
+
+	tabletreq := &tabletmanagerdatapb.VDiffRequest{
+		Keyspace:  req.TargetKeyspace,
+		Workflow:  req.Workflow,
+		Action:    string(vdiff.RepairAction),
+		VdiffUuid: req.Uuid,
+	}
+
+	ts, err := s.buildTrafficSwitcher(ctx, req.TargetKeyspace, req.Workflow)
+	if err != nil {
+		return nil, err
+	}
+
+	if len(targetShards) > 0 {
+		if err := applyTargetShards(ts, targetShards); err != nil {
+			return nil, err
+		}
+	}
+
+	output := &vdiffOutput{
+		responses: make(map[string]*tabletmanagerdatapb.VDiffResponse, len(ts.targets)),
+		err:       nil,
+	}
+	output.err = ts.ForAllTargets(func(target *MigrationTarget) error {
+		resp, err := s.tmc.VDiff(ctx, target.GetPrimary().Tablet, tabletreq)
+		output.mu.Lock()
+		defer output.mu.Unlock()
+		output.responses[target.GetShard().ShardName()] = resp
+		return err
+	})
+	if output.err != nil {
+		s.Logger().Errorf("Error executing vdiff repair action: %v", output.err)
+		return nil, output.err
+	}
+	return &vtctldatapb.VDiffRepairResponse{
+		TabletResponses: output.responses,
+	}, nil
+}
+
 // VDiffResume is part of the vtctlservicepb.VtctldServer interface.
 func (s *Server) VDiffResume(ctx context.Context, req *vtctldatapb.VDiffResumeRequest) (*vtctldatapb.VDiffResumeResponse, error) {
 	span, ctx := trace.NewSpan(ctx, "workflow.Server.VDiffResume")
@@ -25,7 +25,9 @@ import (
 
 	"github.com/google/uuid"
 	"github.com/stretchr/testify/require"
+	"google.golang.org/protobuf/proto"
 
+	"vitess.io/vitess/go/sqltypes"
 	"vitess.io/vitess/go/vt/vttablet/tabletmanager/vdiff"
 
 	querypb "vitess.io/vitess/go/vt/proto/query"
@@ -200,6 +202,100 @@ func TestVDiffCreate(t *testing.T) {
 	}
 }
 
+func TestVDiffRepair(t *testing.T) {
+	ctx := context.Background()
+	sourceKeyspace := &testKeyspace{
+		KeyspaceName: "sourceks",
+		ShardNames:   []string{"0"},
+	}
+	targetKeyspace := &testKeyspace{
+		KeyspaceName: "targetks",
+		ShardNames:   []string{"-80", "80-"},
+	}
+	workflow := "testwf"
+	uuid := uuid.New().String()
+	env := newTestEnv(t, ctx, defaultCellName, sourceKeyspace, targetKeyspace)
+	defer env.close()
+
+	env.tmc.strict = true
+	tabletReq := &tabletmanagerdatapb.VDiffRequest{
+		Keyspace:  targetKeyspace.KeyspaceName,
+		Workflow:  workflow,
+		Action:    string(vdiff.RepairAction),
+		VdiffUuid: uuid,
+	}
+	tabletResp := &tabletmanagerdatapb.VDiffResponse{
+		VdiffUuid: uuid,
+		Output: sqltypes.ResultToProto3(sqltypes.MakeTestResult(
+			sqltypes.MakeTestFields("table_name|repaired_keys|deleted_rows|inserted_rows|differing_keys", "varchar|int64|int64|int64|int64"),
+			"t1|2|1|2|0",
+		)),
+	}
+
+	tests := []struct {
+		name                  string
+		req                   *vtctldatapb.VDiffRepairRequest              // vtctld requests
+		expectedVDiffRequests map[*topodatapb.Tablet]*vdiffRequestResponse // tablet requests
+		wantShards            []string
+		wantErr               string
+	}{
+		{
+			name: "basic repair", // Both target shards
+			req: &vtctldatapb.VDiffRepairRequest{
+				TargetKeyspace: targetKeyspace.KeyspaceName,
+				Workflow:       workflow,
+				Uuid:           uuid,
+			},
+			expectedVDiffRequests: map[*topodatapb.Tablet]*vdiffRequestResponse{
+				env.tablets[targetKeyspace.KeyspaceName][startingTargetTabletUID]:               {req: tabletReq, res: tabletResp},
+				env.tablets[targetKeyspace.KeyspaceName][startingTargetTabletUID+tabletUIDStep]: {req: tabletReq, res: tabletResp},
+			},
+			wantShards: targetKeyspace.ShardNames,
+		},
+		{
+			name: "repair on first shard",
+			req: &vtctldatapb.VDiffRepairRequest{
+				TargetKeyspace: targetKeyspace.KeyspaceName,
+				TargetShards:   targetKeyspace.ShardNames[:1],
+				Workflow:       workflow,
+				Uuid:           uuid,
+			},
+			expectedVDiffRequests: map[*topodatapb.Tablet]*vdiffRequestResponse{
+				env.tablets[targetKeyspace.KeyspaceName][startingTargetTabletUID]: {req: tabletReq, res: tabletResp},
+			},
+			wantShards: targetKeyspace.ShardNames[:1],
+		},
+		{
+			name: "repair on invalid shard",
+			req: &vtctldatapb.VDiffRepairRequest{
+				TargetKeyspace: targetKeyspace.KeyspaceName,
+				TargetShards:   []string{"0"},
+				Workflow:       workflow,
+				Uuid:           uuid,
+			},
+			wantErr: fmt.Sprintf("specified target shard 0 not a valid target for workflow %s", workflow),
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			for tab, vdr := range tt.expectedVDiffRequests {
+				env.tmc.expectVDiffRequest(tab, vdr)
+			}
+			got, err := env.ws.VDiffRepair(ctx, tt.req)
+			if tt.wantErr != "" {
+				require.EqualError(t, err, tt.wantErr)
+			} else {
+				require.NoError(t, err)
+				require.Len(t, got.TabletResponses, len(tt.wantShards))
+				for _, shard := range tt.wantShards {
+					require.True(t, proto.Equal(tabletResp, got.TabletResponses[shard]), "shard %s", shard)
+				}
+			}
+			env.tmc.confirmVDiffRequests(t)
+		})
+	}
+}
+
 func TestVDiffResume(t *testing.T) {
 	ctx := context.Background()
 	sourceKeyspace := &testKeyspace{
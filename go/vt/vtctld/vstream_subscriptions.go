@@ -0,0 +1,105 @@
+/*
+Copyright 2025 The Vitess Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package vtctld
+
+import (
+	"context"
+	"strings"
+	"time"
+
+	"github.com/spf13/pflag"
+
+	"vitess.io/vitess/go/stats"
+	"vitess.io/vitess/go/vt/log"
+	"vitess.io/vitess/go/vt/servenv"
+	"vitess.io/vitess/go/vt/topo"
+	"vitess.io/vitess/go/vt/utils"
+
+	vtctldatapb "vitess.io/vitess/go/vt/proto/vtctldata"
+	vtctlservicepb "vitess.io/vitess/go/vt/proto/vtctlservice"
+)
+
+var (
+	vstreamSubscriptionsCheckInterval = time.Minute
+
+	vstreamSubscriptionLag = stats.NewGaugesWithSingleLabel(
+		"VStreamSubscriptionLagSeconds",
+		"Seconds since the timestamp of the last event acknowledged by a VStream subscription",
+		"Subscription")
+	vstreamSubscriptionBehindRetention = stats.NewGaugesWithSingleLabel(
+		"VStreamSubscriptionShardsBehindRetention",
+		"Number of shards whose primary purged binary logs that a VStream subscription did not acknowledge yet",
+		"Subscription")
+)
+
+func init() {
+	for _, cmd := range []string{"vtcombo", "vtctld"} {
+		servenv.OnParseFor(cmd, registerVStreamSubscriptionsFlags)
+	}
+}
+
+func registerVStreamSubscriptionsFlags(fs *pflag.FlagSet) {
+	utils.SetFlagDurationVar(fs, &vstreamSubscriptionsCheckInterval, "vstream-subscriptions-check-interval", vstreamSubscriptionsCheckInterval, "Interval at which the lag and the binary log retention of the VStream subscriptions are checked. 0 disables the checks.")
+}
+
+// startVStreamSubscriptionsCheck periodically checks the VStream subscriptions
+// until vtctld shuts down.
+func startVStreamSubscriptionsCheck(vtctld vtctlservicepb.VtctldServer) {
+	if vstreamSubscriptionsCheckInterval <= 0 {
+		return
+	}
+	ctx, cancel := context.WithCancel(context.Background())
+	servenv.OnClose(cancel)
+	go func() {
+		ticker := time.NewTicker(vstreamSubscriptionsCheckInterval)
+		defer ticker.Stop()
+		for {
+			select {
+			case <-ctx.Done():
+				return
+			case <-ticker.C:
+			}
+			if err := checkVStreamSubscriptions(ctx, vtctld); err != nil {
+				log.Warningf("Failed to check the VStream subscriptions: %v", err)
+			}
+		}
+	}()
+}
+
+// checkVStreamSubscriptions exports the lag of the VStream subscriptions, and
+// warns about the ones that fell behind the binary log retention of a shard
+// and can no longer be resumed.
+func checkVStreamSubscriptions(ctx context.Context, vtctld vtctlservicepb.VtctldServer) error {
+	ctx, cancel := context.WithTimeout(ctx, topo.RemoteOperationTimeout)
+	defer cancel()
+
+	resp, err := vtctld.GetVStreamSubscriptions(ctx, &vtctldatapb.GetVStreamSubscriptionsRequest{})
+	if err != nil {
+		return err
+	}
+	vstreamSubscriptionLag.ResetAll()
+	vstreamSubscriptionBehindRetention.ResetAll()
+	for _, status := range resp.Subscriptions {
+		name := status.Subscription.Name
+		vstreamSubscriptionLag.Set(name, status.LagSeconds)
+		vstreamSubscriptionBehindRetention.Set(name, int64(len(status.BehindRetentionShards)))
+		if len(status.BehindRetentionShards) > 0 {
+			log.Warningf("VStream subscription %s is behind the binary log retention of shards %s", name, strings.Join(status.BehindRetentionShards, ", "))
+		}
+	}
+	return nil
+}
@@ -0,0 +1,75 @@
+/*
+Copyright 2025 The Vitess Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package vtctld
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"vitess.io/vitess/go/vt/topo/memorytopo"
+	"vitess.io/vitess/go/vt/vtctl/grpcvtctldserver"
+	"vitess.io/vitess/go/vt/vtctl/grpcvtctldserver/testutil"
+
+	binlogdatapb "vitess.io/vitess/go/vt/proto/binlogdata"
+	replicationdatapb "vitess.io/vitess/go/vt/proto/replicationdata"
+	topodatapb "vitess.io/vitess/go/vt/proto/topodata"
+)
+
+func TestCheckVStreamSubscriptions(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	ts := memorytopo.NewServer(ctx, "zone1")
+	defer ts.Close()
+	vtctld := grpcvtctldserver.NewTestVtctldServer(ts, &testutil.TabletManagerClient{
+		TopoServer: ts,
+		FullStatusResult: &replicationdatapb.FullStatus{
+			GtidPurged: "MySQL56/3e11fa47-71ca-11e1-9e33-c80aa9429562:1-10",
+		},
+	})
+	testutil.AddTablets(ctx, t, ts, &testutil.AddTabletOptions{
+		AlsoSetShardPrimary: true,
+	}, &topodatapb.Tablet{
+		Alias:    &topodatapb.TabletAlias{Cell: "zone1", Uid: 100},
+		Keyspace: "ks",
+		Shard:    "0",
+		Type:     topodatapb.TabletType_PRIMARY,
+	})
+
+	now := time.Now().Unix()
+	for name, gtid := range map[string]string{
+		"behind":    "MySQL56/3e11fa47-71ca-11e1-9e33-c80aa9429562:1-5",
+		"caught-up": "MySQL56/3e11fa47-71ca-11e1-9e33-c80aa9429562:1-20",
+	} {
+		_, err := ts.CreateVStreamSubscription(ctx, &binlogdatapb.VStreamSubscription{
+			Name:           name,
+			Vgtid:          &binlogdatapb.VGtid{ShardGtids: []*binlogdatapb.ShardGtid{{Keyspace: "ks", Shard: "0", Gtid: gtid}}},
+			AckedTimestamp: now - 600,
+		})
+		require.NoError(t, err)
+	}
+	vstreamSubscriptionLag.Set("deleted", 1)
+
+	require.NoError(t, checkVStreamSubscriptions(ctx, vtctld))
+	lags := vstreamSubscriptionLag.Counts()
+	assert.Len(t, lags, 2)
+	assert.InDelta(t, 600, lags["behind"], 5)
+	assert.Equal(t, map[string]int64{"behind": 1, "caught-up": 0}, vstreamSubscriptionBehindRetention.Counts())
+}
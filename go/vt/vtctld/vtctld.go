@@ -30,6 +30,7 @@ import (
 
 	"vitess.io/vitess/go/acl"
 	"vitess.io/vitess/go/vt/topo"
+	"vitess.io/vitess/go/vt/vtctl/grpcvtctldserver"
 	"vitess.io/vitess/go/vt/wrangler"
 
 	topodatapb "vitess.io/vitess/go/vt/proto/topodata"
@@ -134,5 +135,7 @@ func InitVtctld(env *vtenv.Environment, ts *topo.Server) error {
 	// Serve the topology endpoint in the REST API at /topodata
 	initExplorer(ts)
 
+	startVStreamSubscriptionsCheck(grpcvtctldserver.NewVtctldServer(env, ts))
+
 	return nil
 }
@@ -0,0 +1,315 @@
+/*
+Copyright 2024 The Vitess Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package balancer
+
+import (
+	"encoding/json"
+	"fmt"
+	"math"
+	"math/rand/v2"
+	"net/http"
+	"sort"
+	"sync"
+	"time"
+
+	"vitess.io/vitess/go/vt/discovery"
+	"vitess.io/vitess/go/vt/topo/topoproto"
+
+	querypb "vitess.io/vitess/go/vt/proto/query"
+	topodatapb "vitess.io/vitess/go/vt/proto/topodata"
+)
+
+/*
+
+The latencyBalancer picks tablets based on how fast they have been answering queries
+from this vtgate, rather than on a static model of the topology.
+
+For every tablet it keeps a "peak EWMA" of the observed query latency together with
+the number of queries that are currently outstanding against it. The cost of sending
+a query to a tablet is then:
+
+  cost = latency * (outstanding + 1) * (1 + cpu usage reported by the health check)
+
+Pick uses the "power of two choices": it samples two distinct tablets at random and
+routes the query to the cheaper one. This avoids herding all the vtgates onto the
+single fastest tablet while still steering the traffic away from slow ones.
+
+The latency average is a peak EWMA: a sample higher than the current average replaces
+it immediately, so a tablet that suddenly stalls is penalized on its very next query,
+while lower samples are folded in with a weight that depends on the time elapsed since
+the previous sample. When a tablet is not being picked its average decays towards zero
+over the configured decay window, so it is probed again eventually and can win back its
+share of the traffic once it has recovered.
+
+*/
+
+// QueryTracker is implemented by balancers that need to observe the queries sent to the
+// tablets they pick.
+type QueryTracker interface {
+	// QueryStarted is called right before a query is sent to the tablet.
+	QueryStarted(th *discovery.TabletHealth)
+
+	// QueryFinished is called once the query sent to the tablet has completed.
+	QueryFinished(th *discovery.TabletHealth)
+
+	// ObserveLatency records how long the tablet took to answer a query. Failed
+	// is set if the query failed because of the tablet rather than the query itself.
+	ObserveLatency(th *discovery.TabletHealth, latency time.Duration, failed bool)
+}
+
+const (
+	// ModeCell balances the query flow across the cells that contain vtgates.
+	ModeCell = "cell"
+
+	// ModeLatency prefers the tablets with the lowest observed latency and the
+	// fewest outstanding queries.
+	ModeLatency = "latency"
+)
+
+// unobservedPenalty is the latency assumed for a tablet we have no samples for
+// yet but which already has queries in flight, so that a burst of queries does
+// not pile up on a tablet before we know anything about it.
+const unobservedPenalty = float64(time.Second)
+
+// failedPenalty is the latency recorded for a query that failed because of the
+// tablet, so that a tablet which fails fast does not attract more traffic.
+const failedPenalty = float64(time.Second)
+
+// pruneInterval is how often the balancer drops the tablets that are no longer
+// in the health check.
+const pruneInterval = time.Minute
+
+// NewLatencyBalancer returns a TabletBalancer which routes queries using the
+// observed latency and outstanding queries of each tablet. tabletExists reports
+// whether a tablet is still in the health check, so that the balancer can forget
+// the tablets that have been removed.
+func NewLatencyBalancer(decay time.Duration, tabletExists func(alias *topodatapb.TabletAlias) bool) TabletBalancer {
+	return &latencyBalancer{
+		decay:        decay,
+		tabletExists: tabletExists,
+		now:          time.Now,
+		tablets:      map[tabletKey]*tabletLatency{},
+	}
+}
+
+type tabletKey struct {
+	cell string
+	uid  uint32
+}
+
+type latencyBalancer struct {
+	// decay is the time window over which the latency samples decay
+	decay time.Duration
+
+	// tabletExists reports whether a tablet is still in the health check
+	tabletExists func(alias *topodatapb.TabletAlias) bool
+
+	// now returns the current time, and is overridden in tests
+	now func() time.Time
+
+	// mu protects the tablets map, its entries and prunedAt
+	mu      sync.Mutex
+	tablets map[tabletKey]*tabletLatency
+
+	// prunedAt is the time at which the removed tablets were last dropped
+	prunedAt time.Time
+}
+
+type tabletLatency struct {
+	alias string
+
+	// latency is the peak EWMA of the observed latency in nanoseconds
+	latency float64
+
+	// observedAt is the time at which latency was last updated
+	observedAt time.Time
+
+	// outstanding is the number of queries currently in flight
+	outstanding int64
+
+	// cpuUsage is the last cpu usage reported by the health check
+	cpuUsage float64
+
+	queries  uint64
+	failures uint64
+}
+
+// Pick implements the TabletBalancer interface.
+func (b *latencyBalancer) Pick(_ *querypb.Target, tablets []*discovery.TabletHealth) *discovery.TabletHealth {
+	numTablets := len(tablets)
+	switch numTablets {
+	case 0:
+		return nil
+	case 1:
+		return tablets[0]
+	}
+
+	i := rand.IntN(numTablets)
+	j := rand.IntN(numTablets - 1)
+	if j >= i {
+		j++
+	}
+
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	now := b.now()
+	if b.tabletFor(tablets[j]).cost(now, b.decay) < b.tabletFor(tablets[i]).cost(now, b.decay) {
+		return tablets[j]
+	}
+	return tablets[i]
+}
+
+// QueryStarted implements the QueryTracker interface.
+func (b *latencyBalancer) QueryStarted(th *discovery.TabletHealth) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	b.tabletFor(th).outstanding++
+}
+
+// QueryFinished implements the QueryTracker interface.
+func (b *latencyBalancer) QueryFinished(th *discovery.TabletHealth) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	tl := b.tabletFor(th)
+	if tl.outstanding > 0 {
+		tl.outstanding--
+	}
+}
+
+// ObserveLatency implements the QueryTracker interface.
+func (b *latencyBalancer) ObserveLatency(th *discovery.TabletHealth, latency time.Duration, failed bool) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	tl := b.tabletFor(th)
+	tl.queries++
+	sample := float64(latency)
+	if failed {
+		tl.failures++
+		sample = max(sample, failedPenalty)
+	}
+	now := b.now()
+	tl.observe(now, b.decay, sample)
+	b.prune(now)
+}
+
+// tabletFor returns the latency tracking for the given tablet, creating it if needed.
+// It also refreshes the health check stats of the tablet. The caller must hold b.mu.
+func (b *latencyBalancer) tabletFor(th *discovery.TabletHealth) *tabletLatency {
+	key := tabletKey{cell: th.Tablet.Alias.Cell, uid: th.Tablet.Alias.Uid}
+	tl, ok := b.tablets[key]
+	if !ok {
+		tl = &tabletLatency{alias: topoproto.TabletAliasString(th.Tablet.Alias)}
+		b.tablets[key] = tl
+	}
+	if th.Stats != nil {
+		tl.cpuUsage = th.Stats.CpuUsage
+	}
+	return tl
+}
+
+// prune drops the tablets that are no longer in the health check, unless
+// they still have queries in flight. It runs at most once per pruneInterval.
+// The caller must hold b.mu.
+func (b *latencyBalancer) prune(now time.Time) {
+	if b.tabletExists == nil || now.Sub(b.prunedAt) < pruneInterval {
+		return
+	}
+	b.prunedAt = now
+	for key, tl := range b.tablets {
+		if tl.outstanding == 0 && !b.tabletExists(&topodatapb.TabletAlias{Cell: key.cell, Uid: key.uid}) {
+			delete(b.tablets, key)
+		}
+	}
+}
+
+// observe folds a new latency sample into the peak EWMA.
+func (tl *tabletLatency) observe(now time.Time, decay time.Duration, sample float64) {
+	if tl.observedAt.IsZero() || sample > tl.latency {
+		tl.latency = sample
+	} else {
+		w := tl.weight(now, decay)
+		tl.latency = tl.latency*w + sample*(1-w)
+	}
+	tl.observedAt = now
+}
+
+// weight returns how much of the current average survives given the time
+// elapsed since the last sample.
+func (tl *tabletLatency) weight(now time.Time, decay time.Duration) float64 {
+	elapsed := now.Sub(tl.observedAt)
+	if elapsed <= 0 {
+		return 1
+	}
+	if decay <= 0 {
+		return 0
+	}
+	return math.Exp(-float64(elapsed) / float64(decay))
+}
+
+// cost returns the expected cost of sending one more query to the tablet.
+func (tl *tabletLatency) cost(now time.Time, decay time.Duration) float64 {
+	if tl.observedAt.IsZero() {
+		if tl.outstanding == 0 {
+			return 0
+		}
+		return unobservedPenalty * float64(tl.outstanding+1)
+	}
+	// Let the average decay while the tablet is idle so that it gets probed again.
+	tl.observe(now, decay, 0)
+	return tl.latency * float64(tl.outstanding+1) * (1 + tl.cpuUsage)
+}
+
+type tabletLatencyStatus struct {
+	Tablet      string
+	LatencyMs   float64
+	Outstanding int64
+	CPUUsage    float64
+	Queries     uint64
+	Failures    uint64
+}
+
+func (b *latencyBalancer) status() []tabletLatencyStatus {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	res := make([]tabletLatencyStatus, 0, len(b.tablets))
+	for _, tl := range b.tablets {
+		res = append(res, tabletLatencyStatus{
+			Tablet:      tl.alias,
+			LatencyMs:   tl.latency / float64(time.Millisecond),
+			Outstanding: tl.outstanding,
+			CPUUsage:    tl.cpuUsage,
+			Queries:     tl.queries,
+			Failures:    tl.failures,
+		})
+	}
+	sort.Slice(res, func(i, j int) bool {
+		return res[i].Tablet < res[j].Tablet
+	})
+	return res
+}
+
+// DebugHandler implements the TabletBalancer interface.
+func (b *latencyBalancer) DebugHandler(w http.ResponseWriter, _ *http.Request) {
+	w.Header().Set("Content-Type", "text/plain")
+	fmt.Fprintf(w, "Mode: %v\r\n", ModeLatency)
+	fmt.Fprintf(w, "Decay: %v\r\n", b.decay)
+
+	tablets, _ := json.MarshalIndent(b.status(), "", "  ")
+	fmt.Fprintf(w, "Tablets: %v\r\n", string(tablets))
+}
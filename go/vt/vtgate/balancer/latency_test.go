@@ -0,0 +1,216 @@
+/*
+Copyright 2024 The Vitess Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package balancer
+
+import (
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"vitess.io/vitess/go/vt/discovery"
+	querypb "vitess.io/vitess/go/vt/proto/query"
+	topodatapb "vitess.io/vitess/go/vt/proto/topodata"
+)
+
+func newTestLatencyBalancer(decay time.Duration) (*latencyBalancer, *time.Time) {
+	now := time.Unix(1000, 0)
+	b := NewLatencyBalancer(decay, nil).(*latencyBalancer)
+	b.now = func() time.Time { return now }
+	return b, &now
+}
+
+func pickCounts(b TabletBalancer, target *querypb.Target, tablets []*discovery.TabletHealth, n int) map[uint32]int {
+	counts := map[uint32]int{}
+	for i := 0; i < n; i++ {
+		th := b.Pick(target, tablets)
+		counts[th.Tablet.Alias.Uid]++
+	}
+	return counts
+}
+
+func TestLatencyBalancerPick(t *testing.T) {
+	b, _ := newTestLatencyBalancer(10 * time.Second)
+	target := &querypb.Target{Keyspace: "k", Shard: "s"}
+
+	assert.Nil(t, b.Pick(target, nil))
+
+	single := []*discovery.TabletHealth{createTestTablet("a")}
+	assert.Equal(t, single[0], b.Pick(target, single))
+
+	tablets := []*discovery.TabletHealth{
+		createTestTablet("a"),
+		createTestTablet("a"),
+		createTestTablet("b"),
+	}
+	for _, th := range tablets {
+		b.ObserveLatency(th, time.Millisecond, false)
+	}
+	slow := tablets[1]
+	b.ObserveLatency(slow, 500*time.Millisecond, false)
+
+	// The slow tablet only wins when it is not sampled at all, which cannot
+	// happen with three tablets, so it should never be picked.
+	counts := pickCounts(b, target, tablets, 1000)
+	assert.Zero(t, counts[slow.Tablet.Alias.Uid])
+	assert.Greater(t, counts[tablets[0].Tablet.Alias.Uid], 300)
+	assert.Greater(t, counts[tablets[2].Tablet.Alias.Uid], 300)
+}
+
+func TestLatencyBalancerOutstanding(t *testing.T) {
+	b, _ := newTestLatencyBalancer(10 * time.Second)
+	target := &querypb.Target{Keyspace: "k", Shard: "s"}
+
+	tablets := []*discovery.TabletHealth{
+		createTestTablet("a"),
+		createTestTablet("a"),
+	}
+	for _, th := range tablets {
+		b.ObserveLatency(th, 10*time.Millisecond, false)
+	}
+
+	busy := tablets[0]
+	b.QueryStarted(busy)
+	b.QueryStarted(busy)
+	counts := pickCounts(b, target, tablets, 100)
+	assert.Equal(t, 100, counts[tablets[1].Tablet.Alias.Uid])
+
+	b.QueryFinished(busy)
+	b.QueryFinished(busy)
+	b.QueryFinished(busy)
+	assert.Zero(t, b.tabletFor(busy).outstanding)
+}
+
+func TestLatencyBalancerUnobserved(t *testing.T) {
+	b, _ := newTestLatencyBalancer(10 * time.Second)
+	target := &querypb.Target{Keyspace: "k", Shard: "s"}
+
+	tablets := []*discovery.TabletHealth{
+		createTestTablet("a"),
+		createTestTablet("a"),
+	}
+	b.ObserveLatency(tablets[0], 10*time.Millisecond, false)
+
+	// A tablet we know nothing about is probed first...
+	assert.Equal(t, tablets[1], b.Pick(target, tablets))
+
+	// ...but queries do not pile up on it before it has answered.
+	b.QueryStarted(tablets[1])
+	assert.Equal(t, tablets[0], b.Pick(target, tablets))
+}
+
+func TestLatencyBalancerEWMA(t *testing.T) {
+	decay := 10 * time.Second
+	b, now := newTestLatencyBalancer(decay)
+	th := createTestTablet("a")
+
+	b.ObserveLatency(th, 100*time.Millisecond, false)
+	tl := b.tabletFor(th)
+	assert.Equal(t, float64(100*time.Millisecond), tl.latency)
+
+	// A higher sample replaces the average right away.
+	*now = now.Add(time.Second)
+	b.ObserveLatency(th, 300*time.Millisecond, false)
+	assert.Equal(t, float64(300*time.Millisecond), tl.latency)
+
+	// A lower sample is folded in according to the elapsed time.
+	*now = now.Add(decay)
+	b.ObserveLatency(th, 100*time.Millisecond, false)
+	assert.InDelta(t, 173.58, tl.latency/float64(time.Millisecond), 0.01)
+
+	// Failures are recorded as a slow query.
+	*now = now.Add(time.Second)
+	b.ObserveLatency(th, time.Millisecond, true)
+	assert.Equal(t, failedPenalty, tl.latency)
+	assert.EqualValues(t, 4, tl.queries)
+	assert.EqualValues(t, 1, tl.failures)
+
+	// An idle tablet decays towards zero so that it is eventually probed again.
+	*now = now.Add(10 * decay)
+	assert.Less(t, tl.cost(*now, decay), float64(time.Millisecond))
+}
+
+func TestLatencyBalancerCPUUsage(t *testing.T) {
+	b, _ := newTestLatencyBalancer(10 * time.Second)
+	target := &querypb.Target{Keyspace: "k", Shard: "s"}
+
+	tablets := []*discovery.TabletHealth{
+		createTestTablet("a"),
+		createTestTablet("a"),
+	}
+	tablets[0].Stats = &querypb.RealtimeStats{CpuUsage: 0.9}
+	for _, th := range tablets {
+		b.ObserveLatency(th, 10*time.Millisecond, false)
+	}
+
+	counts := pickCounts(b, target, tablets, 100)
+	assert.Equal(t, 100, counts[tablets[1].Tablet.Alias.Uid])
+}
+
+func TestLatencyBalancerPrune(t *testing.T) {
+	b, now := newTestLatencyBalancer(10 * time.Second)
+	removed := map[uint32]bool{}
+	b.tabletExists = func(alias *topodatapb.TabletAlias) bool {
+		return !removed[alias.Uid]
+	}
+
+	tablets := []*discovery.TabletHealth{
+		createTestTablet("a"),
+		createTestTablet("a"),
+		createTestTablet("a"),
+	}
+	for _, th := range tablets {
+		b.ObserveLatency(th, 10*time.Millisecond, false)
+	}
+	b.QueryStarted(tablets[2])
+	removed[tablets[1].Tablet.Alias.Uid] = true
+	removed[tablets[2].Tablet.Alias.Uid] = true
+
+	// The removed tablets are only dropped once per prune interval...
+	b.ObserveLatency(tablets[0], 10*time.Millisecond, false)
+	assert.Len(t, b.tablets, 3)
+
+	// ...and not while they still have queries in flight.
+	*now = now.Add(pruneInterval)
+	b.ObserveLatency(tablets[0], 10*time.Millisecond, false)
+	assert.Len(t, b.tablets, 2)
+	assert.NotContains(t, b.tablets, tabletKey{cell: "a", uid: tablets[1].Tablet.Alias.Uid})
+
+	b.QueryFinished(tablets[2])
+	*now = now.Add(pruneInterval)
+	b.ObserveLatency(tablets[0], 10*time.Millisecond, false)
+	require.Len(t, b.tablets, 1)
+	assert.Contains(t, b.tablets, tabletKey{cell: "a", uid: tablets[0].Tablet.Alias.Uid})
+}
+
+func TestLatencyBalancerDebugHandler(t *testing.T) {
+	b, _ := newTestLatencyBalancer(10 * time.Second)
+	th := createTestTablet("a")
+	b.ObserveLatency(th, 2*time.Millisecond, false)
+	b.QueryStarted(th)
+
+	w := httptest.NewRecorder()
+	b.DebugHandler(w, httptest.NewRequest("GET", "/debug/balancer", nil))
+	body := w.Body.String()
+	require.Contains(t, body, "Mode: latency\r\n")
+	require.Contains(t, body, "Decay: 10s\r\n")
+	require.Contains(t, body, `"Tablet": "a-`)
+	require.Contains(t, body, `"LatencyMs": 2`)
+	require.Contains(t, body, `"Outstanding": 1`)
+}
@@ -0,0 +1,345 @@
+/*
+Copyright 2025 The Vitess Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package cdc
+
+import (
+	"crypto/sha256"
+	"encoding/binary"
+	"encoding/json"
+	"fmt"
+	"math"
+	"strconv"
+	"strings"
+
+	"vitess.io/vitess/go/sqltypes"
+
+	querypb "vitess.io/vitess/go/vt/proto/query"
+)
+
+// avroMagic starts the Avro object container files.
+const avroMagic = "Obj\x01"
+
+// avroNull is the null default of the optional fields.
+var avroNull = json.RawMessage("null")
+
+// avroRecord is the schema of an Avro record.
+type avroRecord struct {
+	Type      string      `json:"type"`
+	Name      string      `json:"name"`
+	Namespace string      `json:"namespace,omitempty"`
+	Fields    []avroField `json:"fields"`
+}
+
+// avroField is a field of an avroRecord.
+type avroField struct {
+	Name    string          `json:"name"`
+	Type    any             `json:"type"`
+	Default json.RawMessage `json:"default,omitempty"`
+}
+
+// avroSourceSchema is the schema of the source of the change events, named like
+// the one of the Debezium connector for Vitess.
+var avroSourceSchema = avroRecord{
+	Type:      "record",
+	Name:      "Source",
+	Namespace: "io.debezium.connector.vitess",
+	Fields: []avroField{
+		{Name: "version", Type: "string"},
+		{Name: "connector", Type: "string"},
+		{Name: "name", Type: "string"},
+		{Name: "ts_ms", Type: "long"},
+		{Name: "snapshot", Type: "string"},
+		{Name: "db", Type: "string"},
+		{Name: "keyspace", Type: "string"},
+		{Name: "table", Type: "string"},
+		{Name: "shard", Type: "string"},
+		{Name: "vgtid", Type: "string"},
+	},
+}
+
+// avroEncoder serializes the keys and the change events of the rows in Avro,
+// without a schema registry: every message is an Avro object container file
+// that holds a single record along with its schema, so that any Avro reader
+// can decode it on its own. The sync marker of the files of a schema is derived
+// from the schema, so that the messages of the same key are the same bytes, and
+// go to the same partition.
+type avroEncoder struct {
+	topicPrefix string
+}
+
+// avroSchemas are the container file headers of the keys and the change events
+// of a table.
+type avroSchemas struct {
+	key, value avroContainer
+	// keyColumns and valueColumns are the indexes of the columns of the records.
+	keyColumns, valueColumns []int
+}
+
+// avroContainer is the header of the object container files of a schema.
+type avroContainer struct {
+	header []byte
+	sync   []byte
+}
+
+// schemas returns the Avro schemas of a table, which are built on first use.
+func (e *avroEncoder) schemas(ts *tableSchema) (*avroSchemas, error) {
+	if ts.avro != nil {
+		return ts.avro, nil
+	}
+	namespace := avroName(e.topicPrefix) + "." + avroName(ts.keyspace) + "." + avroName(ts.name)
+	schemas := &avroSchemas{keyColumns: ts.pkColumns}
+	valueRecord := avroRecord{Type: "record", Name: "Value", Namespace: namespace}
+	for i, field := range ts.fields {
+		schemas.valueColumns = append(schemas.valueColumns, i)
+		valueRecord.Fields = append(valueRecord.Fields, avroColumn(field))
+	}
+	envelope := avroRecord{
+		Type:      "record",
+		Name:      "Envelope",
+		Namespace: namespace,
+		Fields: []avroField{
+			{Name: "before", Type: []any{"null", valueRecord}, Default: avroNull},
+			{Name: "after", Type: []any{"null", "Value"}, Default: avroNull},
+			{Name: "source", Type: avroSourceSchema},
+			{Name: "op", Type: "string"},
+			{Name: "ts_ms", Type: []any{"null", "long"}, Default: avroNull},
+		},
+	}
+	var err error
+	if schemas.value, err = newAvroContainer(envelope); err != nil {
+		return nil, err
+	}
+	keyRecord := avroRecord{Type: "record", Name: "Key", Namespace: namespace}
+	for _, i := range ts.pkColumns {
+		keyRecord.Fields = append(keyRecord.Fields, avroColumn(ts.fields[i]))
+	}
+	if schemas.key, err = newAvroContainer(keyRecord); err != nil {
+		return nil, err
+	}
+	ts.avro = schemas
+	return schemas, nil
+}
+
+// key implements encoder.
+func (e *avroEncoder) key(ts *tableSchema, row map[string]any) ([]byte, error) {
+	if len(ts.pkColumns) == 0 {
+		return nil, nil
+	}
+	schemas, err := e.schemas(ts)
+	if err != nil {
+		return nil, err
+	}
+	datum, err := appendAvroColumns(nil, ts, schemas.keyColumns, row)
+	if err != nil {
+		return nil, err
+	}
+	return schemas.key.file(datum), nil
+}
+
+// value implements encoder.
+func (e *avroEncoder) value(ts *tableSchema, envelope *Envelope) ([]byte, error) {
+	schemas, err := e.schemas(ts)
+	if err != nil {
+		return nil, err
+	}
+	var datum []byte
+	for _, row := range []map[string]any{envelope.Before, envelope.After} {
+		if row == nil {
+			datum = binary.AppendVarint(datum, 0)
+			continue
+		}
+		datum = binary.AppendVarint(datum, 1)
+		if datum, err = appendAvroColumns(datum, ts, schemas.valueColumns, row); err != nil {
+			return nil, err
+		}
+	}
+	source := envelope.Source
+	datum = appendAvroString(datum, source.Version)
+	datum = appendAvroString(datum, source.Connector)
+	datum = appendAvroString(datum, source.Name)
+	datum = binary.AppendVarint(datum, source.TsMs)
+	for _, s := range []string{source.Snapshot, source.Db, source.Keyspace, source.Table, source.Shard, source.Vgtid} {
+		datum = appendAvroString(datum, s)
+	}
+	datum = appendAvroString(datum, envelope.Op)
+	datum = binary.AppendVarint(datum, 1)
+	datum = binary.AppendVarint(datum, envelope.TsMs)
+	return schemas.value.file(datum), nil
+}
+
+// avroColumn returns the field of a column, which is optional unless the
+// column is not nullable.
+func avroColumn(field *querypb.Field) avroField {
+	typ := avroType(field.Type)
+	if field.Flags&uint32(querypb.MySqlFlag_NOT_NULL_FLAG) != 0 {
+		return avroField{Name: avroName(field.Name), Type: typ}
+	}
+	return avroField{Name: avroName(field.Name), Type: []any{"null", typ}, Default: avroNull}
+}
+
+// avroType returns the Avro type of the values of a column, which follows
+// jsonValue: decimals, and unsigned bigints that do not fit a long, are strings.
+func avroType(typ querypb.Type) string {
+	switch {
+	case typ == sqltypes.Uint64:
+		return "string"
+	case sqltypes.IsIntegral(typ):
+		return "long"
+	case typ == sqltypes.Float32:
+		return "float"
+	case sqltypes.IsFloat(typ):
+		return "double"
+	case sqltypes.IsBinary(typ) || typ == sqltypes.Bit:
+		return "bytes"
+	default:
+		return "string"
+	}
+}
+
+// appendAvroColumns appends the values of the given columns of a row, as
+// returned by tableSchema.row.
+func appendAvroColumns(datum []byte, ts *tableSchema, columns []int, row map[string]any) ([]byte, error) {
+	for _, i := range columns {
+		field := ts.fields[i]
+		value := row[field.Name]
+		if field.Flags&uint32(querypb.MySqlFlag_NOT_NULL_FLAG) == 0 {
+			if value == nil {
+				datum = binary.AppendVarint(datum, 0)
+				continue
+			}
+			datum = binary.AppendVarint(datum, 1)
+		}
+		var err error
+		if datum, err = appendAvroValue(datum, field.Type, value); err != nil {
+			return nil, fmt.Errorf("failed to encode column %s of table %s.%s: %w", field.Name, ts.keyspace, ts.name, err)
+		}
+	}
+	return datum, nil
+}
+
+// appendAvroValue appends a value, as returned by jsonValue, in the Avro type of
+// its column.
+func appendAvroValue(datum []byte, typ querypb.Type, value any) ([]byte, error) {
+	switch avroType(typ) {
+	case "long":
+		n, ok := value.(json.Number)
+		if !ok {
+			return nil, fmt.Errorf("unexpected %T value for a long", value)
+		}
+		v, err := strconv.ParseInt(string(n), 10, 64)
+		if err != nil {
+			return nil, err
+		}
+		return binary.AppendVarint(datum, v), nil
+	case "float", "double":
+		n, ok := value.(json.Number)
+		if !ok {
+			return nil, fmt.Errorf("unexpected %T value for a float", value)
+		}
+		if typ == sqltypes.Float32 {
+			v, err := strconv.ParseFloat(string(n), 32)
+			if err != nil {
+				return nil, err
+			}
+			return binary.LittleEndian.AppendUint32(datum, math.Float32bits(float32(v))), nil
+		}
+		v, err := strconv.ParseFloat(string(n), 64)
+		if err != nil {
+			return nil, err
+		}
+		return binary.LittleEndian.AppendUint64(datum, math.Float64bits(v)), nil
+	case "bytes":
+		v, ok := value.([]byte)
+		if !ok {
+			return nil, fmt.Errorf("unexpected %T value for bytes", value)
+		}
+		return appendAvroBytes(datum, v), nil
+	default:
+		switch v := value.(type) {
+		case string:
+			return appendAvroString(datum, v), nil
+		case json.Number:
+			return appendAvroString(datum, string(v)), nil
+		default:
+			return nil, fmt.Errorf("unexpected %T value for a string", value)
+		}
+	}
+}
+
+func appendAvroBytes(datum []byte, v []byte) []byte {
+	datum = binary.AppendVarint(datum, int64(len(v)))
+	return append(datum, v...)
+}
+
+func appendAvroString(datum []byte, v string) []byte {
+	datum = binary.AppendVarint(datum, int64(len(v)))
+	return append(datum, v...)
+}
+
+// newAvroContainer returns the header of the object container files of a
+// schema, with a sync marker derived from the schema.
+func newAvroContainer(schema avroRecord) (avroContainer, error) {
+	data, err := json.Marshal(schema)
+	if err != nil {
+		return avroContainer{}, err
+	}
+	sum := sha256.Sum256(data)
+	sync := sum[:16]
+
+	header := []byte(avroMagic)
+	// The metadata is a map with a single block of two entries.
+	header = binary.AppendVarint(header, 2)
+	header = appendAvroString(header, "avro.codec")
+	header = appendAvroBytes(header, []byte("null"))
+	header = appendAvroString(header, "avro.schema")
+	header = appendAvroBytes(header, data)
+	header = binary.AppendVarint(header, 0)
+	header = append(header, sync...)
+	return avroContainer{header: header, sync: sync}, nil
+}
+
+// file returns the object container file of a single record.
+func (c avroContainer) file(datum []byte) []byte {
+	file := make([]byte, 0, len(c.header)+len(datum)+2*binary.MaxVarintLen64+len(c.sync))
+	file = append(file, c.header...)
+	file = binary.AppendVarint(file, 1)
+	file = binary.AppendVarint(file, int64(len(datum)))
+	file = append(file, datum...)
+	return append(file, c.sync...)
+}
+
+// avroName returns a valid Avro name for a name, whose characters that are not
+// letters, digits or underscores are replaced with underscores.
+func avroName(name string) string {
+	var b strings.Builder
+	for i, r := range name {
+		switch {
+		case r >= 'a' && r <= 'z', r >= 'A' && r <= 'Z', r == '_':
+		case r >= '0' && r <= '9':
+			if i == 0 {
+				b.WriteByte('_')
+			}
+		default:
+			r = '_'
+		}
+		b.WriteRune(r)
+	}
+	if b.Len() == 0 {
+		return "_"
+	}
+	return b.String()
+}
@@ -0,0 +1,196 @@
+/*
+Copyright 2025 The Vitess Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package cdc
+
+import (
+	"bytes"
+	"context"
+	"encoding/binary"
+	"encoding/json"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/twmb/franz-go/pkg/kfake"
+
+	binlogdatapb "vitess.io/vitess/go/vt/proto/binlogdata"
+)
+
+// avroReader decodes the Avro binary encoding.
+type avroReader struct {
+	t    *testing.T
+	data []byte
+}
+
+func (r *avroReader) long() int64 {
+	v, n := binary.Varint(r.data)
+	require.Positive(r.t, n)
+	r.data = r.data[n:]
+	return v
+}
+
+func (r *avroReader) bytes() []byte {
+	n := int(r.long())
+	require.LessOrEqual(r.t, n, len(r.data))
+	v := r.data[:n]
+	r.data = r.data[n:]
+	return v
+}
+
+func (r *avroReader) string() string {
+	return string(r.bytes())
+}
+
+// readAvroFile reads an object container file of a single record, and returns
+// its schema and the reader of its record.
+func readAvroFile(t *testing.T, file []byte) (map[string]any, *avroReader) {
+	require.True(t, bytes.HasPrefix(file, []byte(avroMagic)))
+	r := &avroReader{t: t, data: file[len(avroMagic):]}
+	meta := map[string]string{}
+	for count := r.long(); count != 0; count = r.long() {
+		for range count {
+			key := r.string()
+			meta[key] = r.string()
+		}
+	}
+	assert.Equal(t, "null", meta["avro.codec"])
+	var schema map[string]any
+	require.NoError(t, json.Unmarshal([]byte(meta["avro.schema"]), &schema))
+	sync := r.data[:16]
+	r.data = r.data[16:]
+
+	require.EqualValues(t, 1, r.long())
+	datum := r.bytes()
+	assert.Equal(t, sync, r.data, "the block ends with the sync marker")
+	return schema, &avroReader{t: t, data: datum}
+}
+
+func TestSinkAvro(t *testing.T) {
+	ctx := context.Background()
+	cluster, err := kfake.NewCluster(kfake.AllowAutoTopicCreation(), kfake.DefaultNumPartitions(1))
+	require.NoError(t, err)
+	defer cluster.Close()
+
+	sink, err := NewSink(ctx, Config{
+		Name:            "cdc",
+		Brokers:         cluster.ListenAddrs(),
+		CheckpointTopic: "cdc-checkpoints",
+		Format:          FormatAvro,
+		MaxBatchEvents:  2,
+		CommitInterval:  time.Hour,
+	})
+	require.NoError(t, err)
+	defer sink.Close()
+	sink.now = func() time.Time { return time.UnixMilli(1700000000123) }
+
+	reader := &fakeVStreamReader{events: [][]*binlogdatapb.VEvent{{
+		{Type: binlogdatapb.VEventType_FIELD, FieldEvent: &binlogdatapb.FieldEvent{
+			TableName: "commerce.customer",
+			Keyspace:  "commerce",
+			Shard:     "-80",
+			Fields:    testFields(),
+		}},
+		rowEvent(rowChange("", "1|alice|10.50|ab")),
+		rowEvent(rowChange("1|alice|10.50|ab", "1|alice|10.50|null")),
+		vgtidEvent("MySQL56/a:1-5"),
+	}}}
+	require.NoError(t, sink.Run(ctx, vgtidEvent("MySQL56/a:1-4").Vgtid, reader))
+
+	records := readTopic(t, cluster, "cdc.commerce.customer", 2)
+	require.Len(t, records, 2)
+	// The keys of a row are the same bytes, so that its changes stay ordered.
+	assert.Equal(t, records[0].Key, records[1].Key)
+
+	schema, key := readAvroFile(t, records[0].Key)
+	assert.Equal(t, "Key", schema["name"])
+	assert.Equal(t, "cdc.commerce.customer", schema["namespace"])
+	assert.Equal(t, []any{map[string]any{"name": "id", "type": "long"}}, schema["fields"])
+	assert.EqualValues(t, 1, key.long())
+	assert.Empty(t, key.data)
+
+	schema, value := readAvroFile(t, records[0].Value)
+	assert.Equal(t, "Envelope", schema["name"])
+	fields := schema["fields"].([]any)
+	require.Len(t, fields, 5)
+	assert.Equal(t, []any{"null", "Value"}, fields[1].(map[string]any)["type"])
+	valueSchema := fields[0].(map[string]any)["type"].([]any)[1].(map[string]any)
+	assert.Equal(t, []any{
+		map[string]any{"name": "id", "type": "long"},
+		map[string]any{"name": "name", "type": []any{"null", "string"}, "default": nil},
+		map[string]any{"name": "balance", "type": []any{"null", "string"}, "default": nil},
+		map[string]any{"name": "photo", "type": []any{"null", "bytes"}, "default": nil},
+	}, valueSchema["fields"])
+
+	// before is null, and after holds the row.
+	assert.EqualValues(t, 0, value.long())
+	assert.EqualValues(t, 1, value.long())
+	assert.EqualValues(t, 1, value.long())
+	assert.EqualValues(t, 1, value.long())
+	assert.Equal(t, "alice", value.string())
+	assert.EqualValues(t, 1, value.long())
+	assert.Equal(t, "10.50", value.string())
+	assert.EqualValues(t, 1, value.long())
+	assert.Equal(t, []byte("ab"), value.bytes())
+	// The source.
+	assert.Equal(t, sink.version, value.string())
+	assert.Equal(t, "vitess", value.string())
+	assert.Equal(t, "cdc", value.string())
+	assert.EqualValues(t, 1700000000000, value.long())
+	for _, want := range []string{"false", "commerce", "commerce", "customer", "-80", `[{"keyspace":"commerce","shard":"-80","gtid":"MySQL56/a:1-4"}]`} {
+		assert.Equal(t, want, value.string())
+	}
+	assert.Equal(t, "c", value.string())
+	assert.EqualValues(t, 1, value.long())
+	assert.EqualValues(t, 1700000000123, value.long())
+	assert.Empty(t, value.data)
+
+	// The update has both images, and a null photo after.
+	_, value = readAvroFile(t, records[1].Value)
+	assert.EqualValues(t, 1, value.long())
+	value.long()
+	value.long()
+	assert.Equal(t, "alice", value.string())
+	value.long()
+	value.string()
+	value.long()
+	value.bytes()
+	assert.EqualValues(t, 1, value.long())
+	value.long()
+	value.long()
+	value.string()
+	value.long()
+	value.string()
+	assert.EqualValues(t, 0, value.long(), "the photo is null")
+
+	_, err = NewSink(ctx, Config{Name: "cdc", CheckpointTopic: "cdc-checkpoints", Format: "xml"})
+	assert.ErrorContains(t, err, `unknown format "xml"`)
+}
+
+func TestAvroName(t *testing.T) {
+	for name, want := range map[string]string{
+		"customer":  "customer",
+		"my-table":  "my_table",
+		"1st":       "_1st",
+		"col1":      "col1",
+		"naïve":     "na_ve",
+		"":          "_",
+		"with.dots": "with_dots",
+	} {
+		assert.Equal(t, want, avroName(name), name)
+	}
+}
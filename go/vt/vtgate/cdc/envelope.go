@@ -0,0 +1,181 @@
+/*
+Copyright 2025 The Vitess Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package cdc
+
+import (
+	"encoding/json"
+	"fmt"
+	"strings"
+
+	"vitess.io/vitess/go/sqltypes"
+
+	binlogdatapb "vitess.io/vitess/go/vt/proto/binlogdata"
+	querypb "vitess.io/vitess/go/vt/proto/query"
+)
+
+// The operations of the Debezium change events.
+const (
+	opCreate = "c"
+	opUpdate = "u"
+	opDelete = "d"
+	opRead   = "r"
+)
+
+// connectorName is the name of the connector reported in the source of the
+// change events, which is the one of the Debezium connector for Vitess.
+const connectorName = "vitess"
+
+// Envelope is the Debezium change event of a row, as serialized by the
+// Debezium JSON converter with schemas disabled, or in Avro.
+type Envelope struct {
+	Before map[string]any `json:"before"`
+	After  map[string]any `json:"after"`
+	Source Source         `json:"source"`
+	Op     string         `json:"op"`
+	TsMs   int64          `json:"ts_ms"`
+}
+
+// Source describes where a change event comes from.
+type Source struct {
+	Version   string `json:"version"`
+	Connector string `json:"connector"`
+	Name      string `json:"name"`
+	TsMs      int64  `json:"ts_ms"`
+	Snapshot  string `json:"snapshot"`
+	Db        string `json:"db"`
+	Keyspace  string `json:"keyspace"`
+	Table     string `json:"table"`
+	Shard     string `json:"shard"`
+	Vgtid     string `json:"vgtid"`
+}
+
+// ShardGtid is the position of a shard, as reported in the vgtid of the
+// source of the change events.
+type ShardGtid struct {
+	Keyspace string `json:"keyspace"`
+	Shard    string `json:"shard"`
+	Gtid     string `json:"gtid"`
+}
+
+// tableSchema is the schema of a table, as sent by the FIELD events.
+type tableSchema struct {
+	keyspace string
+	name     string
+	fields   []*querypb.Field
+	// pkColumns are the indexes of the primary key columns, which make the
+	// key of the messages.
+	pkColumns []int
+	// avro are the Avro schemas of the table, once they are built.
+	avro *avroSchemas
+}
+
+func newTableSchema(fe *binlogdatapb.FieldEvent) *tableSchema {
+	ts := &tableSchema{
+		keyspace: fe.Keyspace,
+		// The keyspace prefixes the table name, unless the stream is
+		// asked to exclude it.
+		name:   strings.TrimPrefix(fe.TableName, fe.Keyspace+"."),
+		fields: fe.Fields,
+	}
+	for i, field := range fe.Fields {
+		if field.Flags&uint32(querypb.MySqlFlag_PRI_KEY_FLAG) != 0 {
+			ts.pkColumns = append(ts.pkColumns, i)
+		}
+	}
+	return ts
+}
+
+// row returns the columns of a row image, or nil if there is no image.
+func (ts *tableSchema) row(row *querypb.Row) (map[string]any, error) {
+	if row == nil {
+		return nil, nil
+	}
+	values := sqltypes.MakeRowTrusted(ts.fields, row)
+	if len(values) != len(ts.fields) {
+		return nil, fmt.Errorf("row of table %s.%s has %d columns, expected %d", ts.keyspace, ts.name, len(values), len(ts.fields))
+	}
+	columns := make(map[string]any, len(values))
+	for i, value := range values {
+		columns[ts.fields[i].Name] = jsonValue(value)
+	}
+	return columns, nil
+}
+
+// encoder serializes the keys and the change events of the rows.
+type encoder interface {
+	// key returns the message key of a row, which holds its primary key
+	// columns, or nil if the table has no primary key.
+	key(ts *tableSchema, row map[string]any) ([]byte, error)
+	// value returns the message value of a change event.
+	value(ts *tableSchema, envelope *Envelope) ([]byte, error)
+}
+
+// jsonEncoder serializes the keys and the change events of the rows like the
+// Debezium JSON converter with schemas disabled.
+type jsonEncoder struct{}
+
+// key implements encoder.
+func (jsonEncoder) key(ts *tableSchema, row map[string]any) ([]byte, error) {
+	if len(ts.pkColumns) == 0 {
+		return nil, nil
+	}
+	key := make(map[string]any, len(ts.pkColumns))
+	for _, i := range ts.pkColumns {
+		key[ts.fields[i].Name] = row[ts.fields[i].Name]
+	}
+	return json.Marshal(key)
+}
+
+// value implements encoder.
+func (jsonEncoder) value(ts *tableSchema, envelope *Envelope) ([]byte, error) {
+	return json.Marshal(envelope)
+}
+
+// jsonValue returns the value the way the Debezium connector for Vitess
+// encodes it with its default settings: numbers as JSON numbers, except for
+// decimals which are strings, binary values as base64 strings, and any other
+// value as a string.
+func jsonValue(value sqltypes.Value) any {
+	switch typ := value.Type(); {
+	case value.IsNull():
+		return nil
+	case sqltypes.IsIntegral(typ) || sqltypes.IsFloat(typ):
+		return json.Number(value.ToString())
+	case sqltypes.IsBinary(typ) || typ == sqltypes.Bit:
+		return value.Raw()
+	default:
+		return value.ToString()
+	}
+}
+
+// vgtidString returns the vgtid of the source of the change events, which
+// lists the positions of the shards, without the progress of the copy.
+func vgtidString(vgtid *binlogdatapb.VGtid) (string, error) {
+	shardGtids := make([]ShardGtid, 0, len(vgtid.GetShardGtids()))
+	for _, sgtid := range vgtid.GetShardGtids() {
+		shardGtids = append(shardGtids, ShardGtid{
+			Keyspace: sgtid.Keyspace,
+			Shard:    sgtid.Shard,
+			Gtid:     sgtid.Gtid,
+		})
+	}
+	data, err := json.Marshal(shardGtids)
+	if err != nil {
+		return "", err
+	}
+	return string(data), nil
+}
@@ -0,0 +1,515 @@
+/*
+Copyright 2025 The Vitess Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+/*
+Package cdc implements a change data capture sink, which writes the row
+changes of a VStream to a Kafka-protocol broker, as the change events of the
+Debezium connector for Vitess. They are serialized like the JSON converter
+with schemas disabled, or in Avro. The sink does not talk to a schema registry:
+an Avro message is an object container file that embeds the schema of the
+record it holds, so that consumers can decode it without a registry.
+
+The changes of a table are written to the '<prefix>.<keyspace>.<table>' topic,
+keyed by the primary key of their row, so that the changes of a row are
+ordered. The vgtid the stream would have to resume from is written, as a
+checkpoint, to a compacted topic keyed by the name of the sink, in the same
+Kafka transaction as the changes it covers. The changes are therefore
+delivered at least once: a sink that stops in the middle of a batch aborts
+its transaction, and resumes from the last checkpoint, so that consumers that
+read committed messages only see every change once.
+
+Brokers that do not support transactions can be used by disabling them, in
+which case the checkpoint is written once the changes it covers are
+acknowledged, and consumers may see the changes of an interrupted batch twice.
+
+The stream handles resharding itself: when the shards of a keyspace are
+resharded, it carries on from the new shards, and the vgtid events that
+follow, and so the checkpoints, name them.
+*/
+package cdc
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/twmb/franz-go/pkg/kerr"
+	"github.com/twmb/franz-go/pkg/kgo"
+	"github.com/twmb/franz-go/pkg/kmsg"
+
+	"vitess.io/vitess/go/json2"
+	"vitess.io/vitess/go/vt/log"
+	"vitess.io/vitess/go/vt/servenv"
+	"vitess.io/vitess/go/vt/vtgate/vtgateconn"
+
+	binlogdatapb "vitess.io/vitess/go/vt/proto/binlogdata"
+)
+
+// The formats of the messages.
+const (
+	// FormatJSON serializes the messages like the Debezium JSON converter with
+	// schemas disabled.
+	FormatJSON = "json"
+	// FormatAvro serializes every message as an Avro object container file
+	// holding a single record, along with its schema.
+	FormatAvro = "avro"
+)
+
+// Config configures a Sink.
+type Config struct {
+	// Name is the name of the sink. It is the logical name of the server in
+	// the change events, the transactional id of the producer and the key
+	// of the checkpoints, so it has to be unique across the sinks that share
+	// brokers.
+	Name string
+	// Brokers are the addresses of the brokers to bootstrap from.
+	Brokers []string
+	// TopicPrefix prefixes the topics of the tables. It defaults to Name.
+	TopicPrefix string
+	// CheckpointTopic is the topic that holds the checkpoints. It is created,
+	// compacted, if it does not exist.
+	CheckpointTopic string
+	// Transactional writes the changes and their checkpoint in Kafka
+	// transactions.
+	Transactional bool
+	// Tombstones writes a tombstone, a message with no value, after the
+	// deletion of a row, so that compaction can drop its messages.
+	Tombstones bool
+	// Format is the format of the keys and the change events, FormatJSON if
+	// empty.
+	Format string
+	// MaxBatchEvents and CommitInterval bound the batches of changes that are
+	// committed with a checkpoint: a batch is committed, at the end of a
+	// transaction of the stream, once it holds MaxBatchEvents changes or once
+	// it has been open for CommitInterval.
+	MaxBatchEvents int
+	CommitInterval time.Duration
+}
+
+// Sink writes the row changes of a VStream to a Kafka-protocol broker.
+type Sink struct {
+	config Config
+	client *kgo.Client
+	// opts are the options of the client, which the checkpoint readers
+	// share, but for the producer ones.
+	opts    []kgo.Opt
+	encoder encoder
+	version string
+	now     func() time.Time
+
+	// tables are the schemas of the tables, keyed by '<keyspace>.<table>'.
+	tables map[string]*tableSchema
+	// vgtid is the last vgtid of the stream, and pending is set if it has not
+	// been checkpointed yet.
+	vgtid   *binlogdatapb.VGtid
+	pending bool
+
+	inTransaction bool
+	batchEvents   int
+	batchStart    time.Time
+
+	mu         sync.Mutex
+	produceErr error
+}
+
+// NewSink connects to the brokers, and creates the checkpoint topic if it does
+// not exist. Extra options can be passed to the Kafka client, e.g. for TLS or
+// SASL.
+func NewSink(ctx context.Context, config Config, opts ...kgo.Opt) (*Sink, error) {
+	if config.Name == "" {
+		return nil, errors.New("a sink needs a name")
+	}
+	if config.CheckpointTopic == "" {
+		return nil, errors.New("a sink needs a checkpoint topic")
+	}
+	if config.TopicPrefix == "" {
+		config.TopicPrefix = config.Name
+	}
+	var enc encoder
+	switch config.Format {
+	case "", FormatJSON:
+		enc = jsonEncoder{}
+	case FormatAvro:
+		enc = &avroEncoder{topicPrefix: config.TopicPrefix}
+	default:
+		return nil, fmt.Errorf("unknown format %q", config.Format)
+	}
+	opts = append([]kgo.Opt{
+		kgo.SeedBrokers(config.Brokers...),
+		kgo.AllowAutoTopicCreation(),
+		kgo.ProducerLinger(0),
+	}, opts...)
+	clientOpts := opts
+	if config.Transactional {
+		clientOpts = append(clientOpts[:len(clientOpts):len(clientOpts)], kgo.TransactionalID(config.Name))
+	}
+	client, err := kgo.NewClient(clientOpts...)
+	if err != nil {
+		return nil, err
+	}
+	s := &Sink{
+		config:  config,
+		client:  client,
+		opts:    opts,
+		encoder: enc,
+		version: servenv.AppVersion.ToStringMap()["version"],
+		now:     time.Now,
+		tables:  make(map[string]*tableSchema),
+	}
+	if err := s.createCheckpointTopic(ctx); err != nil {
+		client.Close()
+		return nil, err
+	}
+	return s, nil
+}
+
+// Close closes the connections to the brokers.
+func (s *Sink) Close() {
+	s.client.Close()
+}
+
+func (s *Sink) createCheckpointTopic(ctx context.Context) error {
+	req := kmsg.NewPtrCreateTopicsRequest()
+	topic := kmsg.NewCreateTopicsRequestTopic()
+	topic.Topic = s.config.CheckpointTopic
+	// The checkpoints of a sink are read back in order, so they all live in
+	// a single partition.
+	topic.NumPartitions = 1
+	topic.ReplicationFactor = -1
+	config := kmsg.NewCreateTopicsRequestTopicConfig()
+	config.Name = "cleanup.policy"
+	config.Value = kmsg.StringPtr("compact")
+	topic.Configs = append(topic.Configs, config)
+	req.Topics = append(req.Topics, topic)
+	resp, err := req.RequestWith(ctx, s.client)
+	if err != nil {
+		return fmt.Errorf("failed to create checkpoint topic %s: %w", s.config.CheckpointTopic, err)
+	}
+	for _, topic := range resp.Topics {
+		if err := kerr.ErrorForCode(topic.ErrorCode); err != nil && !errors.Is(err, kerr.TopicAlreadyExists) {
+			return fmt.Errorf("failed to create checkpoint topic %s: %w", s.config.CheckpointTopic, err)
+		}
+	}
+	return nil
+}
+
+// Checkpoint returns the last vgtid checkpointed by the sink, to resume the
+// stream from, or nil if there is none.
+func (s *Sink) Checkpoint(ctx context.Context) (*binlogdatapb.VGtid, error) {
+	req := kmsg.NewPtrListOffsetsRequest()
+	// Only the committed checkpoints are read.
+	req.IsolationLevel = 1
+	topic := kmsg.NewListOffsetsRequestTopic()
+	topic.Topic = s.config.CheckpointTopic
+	partition := kmsg.NewListOffsetsRequestTopicPartition()
+	partition.Timestamp = -1
+	topic.Partitions = append(topic.Partitions, partition)
+	req.Topics = append(req.Topics, topic)
+	resp, err := req.RequestWith(ctx, s.client)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list the offsets of checkpoint topic %s: %w", s.config.CheckpointTopic, err)
+	}
+	if len(resp.Topics) != 1 || len(resp.Topics[0].Partitions) != 1 {
+		return nil, fmt.Errorf("unexpected offsets of checkpoint topic %s", s.config.CheckpointTopic)
+	}
+	if err := kerr.ErrorForCode(resp.Topics[0].Partitions[0].ErrorCode); err != nil {
+		return nil, fmt.Errorf("failed to list the offsets of checkpoint topic %s: %w", s.config.CheckpointTopic, err)
+	}
+	end := resp.Topics[0].Partitions[0].Offset
+
+	consumer, err := kgo.NewClient(append(s.opts[:len(s.opts):len(s.opts)],
+		kgo.ConsumePartitions(map[string]map[int32]kgo.Offset{
+			s.config.CheckpointTopic: {0: kgo.NewOffset().AtStart()},
+		}),
+		kgo.FetchIsolationLevel(kgo.ReadCommitted()),
+		// The markers of the transactions are kept, to know when the end of
+		// the topic is reached when it ends with one.
+		kgo.KeepControlRecords(),
+	)...)
+	if err != nil {
+		return nil, err
+	}
+	defer consumer.Close()
+
+	var checkpoint []byte
+	for offset := int64(0); offset < end; {
+		fetches := consumer.PollFetches(ctx)
+		if err := fetches.Err(); err != nil {
+			return nil, fmt.Errorf("failed to read checkpoint topic %s: %w", s.config.CheckpointTopic, err)
+		}
+		fetches.EachRecord(func(record *kgo.Record) {
+			offset = record.Offset + 1
+			if !record.Attrs.IsControl() && string(record.Key) == s.config.Name {
+				checkpoint = record.Value
+			}
+		})
+	}
+	if checkpoint == nil {
+		return nil, nil
+	}
+	vgtid := &binlogdatapb.VGtid{}
+	if err := json2.UnmarshalPB(checkpoint, vgtid); err != nil {
+		return nil, fmt.Errorf("failed to decode checkpoint %s: %w", checkpoint, err)
+	}
+	return vgtid, nil
+}
+
+// Run writes the changes read from the stream, which was started from vgtid,
+// until the stream ends, and commits them. It aborts the batch in progress and
+// returns the error if the stream or the brokers fail.
+func (s *Sink) Run(ctx context.Context, vgtid *binlogdatapb.VGtid, reader vtgateconn.VStreamReader) error {
+	s.vgtid = vgtid
+	s.batchStart = s.now()
+	for {
+		events, err := reader.Recv()
+		if err == io.EOF {
+			return s.commit(ctx)
+		}
+		if err == nil {
+			err = s.handleEvents(ctx, events)
+		}
+		if err != nil {
+			s.abort(ctx)
+			return err
+		}
+	}
+}
+
+func (s *Sink) handleEvents(ctx context.Context, events []*binlogdatapb.VEvent) error {
+	for _, event := range events {
+		switch event.Type {
+		case binlogdatapb.VEventType_FIELD:
+			ts := newTableSchema(event.FieldEvent)
+			s.tables[ts.keyspace+"."+ts.name] = ts
+		case binlogdatapb.VEventType_ROW:
+			if err := s.writeRows(event); err != nil {
+				return err
+			}
+		case binlogdatapb.VEventType_VGTID:
+			// The vgtid comes last in a transaction of the stream, so the
+			// batch is committed at its boundary.
+			s.vgtid = event.Vgtid
+			s.pending = true
+		case binlogdatapb.VEventType_HEARTBEAT:
+			// Heartbeats let an idle stream commit its last vgtid.
+		default:
+			// The stream carries on across resharding by itself, so the
+			// journal events need no handling.
+			continue
+		}
+		if s.pending && (s.batchEvents >= s.config.MaxBatchEvents || s.now().Sub(s.batchStart) >= s.config.CommitInterval) {
+			if err := s.commit(ctx); err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
+
+// writeRows writes the change events of the changes of a row event.
+func (s *Sink) writeRows(event *binlogdatapb.VEvent) error {
+	re := event.RowEvent
+	ts, ok := s.tables[re.Keyspace+"."+strings.TrimPrefix(re.TableName, re.Keyspace+".")]
+	if !ok {
+		return fmt.Errorf("no fields received for table %s", re.TableName)
+	}
+	vgtid, err := vgtidString(s.vgtid)
+	if err != nil {
+		return err
+	}
+	source := Source{
+		Version:   s.version,
+		Connector: connectorName,
+		Name:      s.config.Name,
+		TsMs:      event.Timestamp * 1000,
+		Snapshot:  "false",
+		Db:        ts.keyspace,
+		Keyspace:  ts.keyspace,
+		Table:     ts.name,
+		Shard:     re.Shard,
+		Vgtid:     vgtid,
+	}
+	snapshot := s.copying(re.Keyspace, re.Shard)
+	if snapshot {
+		source.Snapshot = "true"
+	}
+	topic := s.config.TopicPrefix + "." + ts.keyspace + "." + ts.name
+	for _, change := range re.RowChanges {
+		before, err := ts.row(change.Before)
+		if err != nil {
+			return err
+		}
+		after, err := ts.row(change.After)
+		if err != nil {
+			return err
+		}
+		switch {
+		case before == nil && snapshot:
+			err = s.writeChange(topic, ts, after, &Envelope{After: after, Source: source, Op: opRead})
+		case before == nil:
+			err = s.writeChange(topic, ts, after, &Envelope{After: after, Source: source, Op: opCreate})
+		case after == nil:
+			err = s.writeDelete(topic, ts, before, source)
+		default:
+			var beforeKey, afterKey []byte
+			if beforeKey, err = s.encoder.key(ts, before); err != nil {
+				return err
+			}
+			if afterKey, err = s.encoder.key(ts, after); err != nil {
+				return err
+			}
+			if string(beforeKey) == string(afterKey) {
+				err = s.writeChange(topic, ts, after, &Envelope{Before: before, After: after, Source: source, Op: opUpdate})
+				break
+			}
+			// Like Debezium, an update of the primary key deletes the row
+			// with the old key, and creates it with the new one.
+			if err = s.writeDelete(topic, ts, before, source); err == nil {
+				err = s.writeChange(topic, ts, after, &Envelope{After: after, Source: source, Op: opCreate})
+			}
+		}
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (s *Sink) writeDelete(topic string, ts *tableSchema, before map[string]any, source Source) error {
+	if err := s.writeChange(topic, ts, before, &Envelope{Before: before, Source: source, Op: opDelete}); err != nil {
+		return err
+	}
+	if !s.config.Tombstones {
+		return nil
+	}
+	key, err := s.encoder.key(ts, before)
+	if err != nil || key == nil {
+		return err
+	}
+	return s.produce(&kgo.Record{Topic: topic, Key: key})
+}
+
+func (s *Sink) writeChange(topic string, ts *tableSchema, row map[string]any, envelope *Envelope) error {
+	key, err := s.encoder.key(ts, row)
+	if err != nil {
+		return err
+	}
+	envelope.TsMs = s.now().UnixMilli()
+	value, err := s.encoder.value(ts, envelope)
+	if err != nil {
+		return err
+	}
+	s.batchEvents++
+	return s.produce(&kgo.Record{Topic: topic, Key: key, Value: value})
+}
+
+// copying returns whether the stream is copying the rows of the shard, whose
+// rows are then reported as read by the snapshot rather than created.
+func (s *Sink) copying(keyspace, shard string) bool {
+	for _, sgtid := range s.vgtid.GetShardGtids() {
+		if sgtid.Keyspace == keyspace && sgtid.Shard == shard {
+			return sgtid.Gtid == "" || len(sgtid.TablePKs) > 0
+		}
+	}
+	return false
+}
+
+func (s *Sink) produce(record *kgo.Record) error {
+	if s.config.Transactional && !s.inTransaction {
+		if err := s.client.BeginTransaction(); err != nil {
+			return err
+		}
+		s.inTransaction = true
+	}
+	s.client.Produce(context.Background(), record, func(record *kgo.Record, err error) {
+		if err == nil {
+			return
+		}
+		s.mu.Lock()
+		defer s.mu.Unlock()
+		if s.produceErr == nil {
+			s.produceErr = fmt.Errorf("failed to write to topic %s: %w", record.Topic, err)
+		}
+	})
+	return nil
+}
+
+// flush waits for the messages in flight to be written, and returns the first
+// error any of them got.
+func (s *Sink) flush(ctx context.Context) error {
+	if err := s.client.Flush(ctx); err != nil {
+		return err
+	}
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.produceErr
+}
+
+// commit commits the batch in progress, with the last vgtid as its checkpoint.
+func (s *Sink) commit(ctx context.Context) error {
+	if !s.pending {
+		return nil
+	}
+	checkpoint, err := json2.MarshalPB(s.vgtid)
+	if err != nil {
+		return err
+	}
+	record := &kgo.Record{Topic: s.config.CheckpointTopic, Key: []byte(s.config.Name), Value: checkpoint}
+	if s.config.Transactional {
+		if err := s.produce(record); err != nil {
+			return err
+		}
+		if err := s.flush(ctx); err != nil {
+			return err
+		}
+		if err := s.client.EndTransaction(ctx, kgo.TryCommit); err != nil {
+			return fmt.Errorf("failed to commit the batch: %w", err)
+		}
+		s.inTransaction = false
+	} else {
+		// The checkpoint is only written once the changes it covers are.
+		if err := s.flush(ctx); err != nil {
+			return err
+		}
+		if err := s.client.ProduceSync(ctx, record).FirstErr(); err != nil {
+			return fmt.Errorf("failed to write checkpoint to topic %s: %w", s.config.CheckpointTopic, err)
+		}
+	}
+	log.Infof("Sink %s committed %d changes up to %v", s.config.Name, s.batchEvents, s.vgtid)
+	s.pending = false
+	s.batchEvents = 0
+	s.batchStart = s.now()
+	return nil
+}
+
+// abort aborts the batch in progress, if it is written in a transaction.
+func (s *Sink) abort(ctx context.Context) {
+	if !s.inTransaction {
+		return
+	}
+	s.inTransaction = false
+	if err := s.client.AbortBufferedRecords(ctx); err != nil {
+		log.Warningf("Sink %s failed to abort its buffered changes: %v", s.config.Name, err)
+		return
+	}
+	if err := s.client.EndTransaction(ctx, kgo.TryAbort); err != nil {
+		log.Warningf("Sink %s failed to abort its transaction: %v", s.config.Name, err)
+	}
+}
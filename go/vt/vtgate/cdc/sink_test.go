@@ -0,0 +1,297 @@
+/*
+Copyright 2025 The Vitess Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package cdc
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"io"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/twmb/franz-go/pkg/kfake"
+	"github.com/twmb/franz-go/pkg/kgo"
+
+	"vitess.io/vitess/go/sqltypes"
+
+	binlogdatapb "vitess.io/vitess/go/vt/proto/binlogdata"
+	querypb "vitess.io/vitess/go/vt/proto/query"
+)
+
+// fakeVStreamReader returns its events, and then its error, or io.EOF.
+type fakeVStreamReader struct {
+	events [][]*binlogdatapb.VEvent
+	err    error
+}
+
+func (r *fakeVStreamReader) Recv() ([]*binlogdatapb.VEvent, error) {
+	if len(r.events) == 0 {
+		if r.err != nil {
+			return nil, r.err
+		}
+		return nil, io.EOF
+	}
+	events := r.events[0]
+	r.events = r.events[1:]
+	return events, nil
+}
+
+func testFields() []*querypb.Field {
+	fields := sqltypes.MakeTestFields("id|name|balance|photo", "int64|varchar|decimal|blob")
+	fields[0].Flags = uint32(querypb.MySqlFlag_PRI_KEY_FLAG | querypb.MySqlFlag_NOT_NULL_FLAG)
+	return fields
+}
+
+func rowChange(before, after string) *binlogdatapb.RowChange {
+	change := &binlogdatapb.RowChange{}
+	if before != "" {
+		change.Before = sqltypes.RowToProto3(sqltypes.MakeTestResult(testFields(), before).Rows[0])
+	}
+	if after != "" {
+		change.After = sqltypes.RowToProto3(sqltypes.MakeTestResult(testFields(), after).Rows[0])
+	}
+	return change
+}
+
+func rowEvent(changes ...*binlogdatapb.RowChange) *binlogdatapb.VEvent {
+	return &binlogdatapb.VEvent{
+		Type:      binlogdatapb.VEventType_ROW,
+		Timestamp: 1700000000,
+		RowEvent: &binlogdatapb.RowEvent{
+			TableName:  "commerce.customer",
+			Keyspace:   "commerce",
+			Shard:      "-80",
+			RowChanges: changes,
+		},
+	}
+}
+
+func vgtidEvent(gtid string, tablePKs ...*binlogdatapb.TableLastPK) *binlogdatapb.VEvent {
+	return &binlogdatapb.VEvent{
+		Type: binlogdatapb.VEventType_VGTID,
+		Vgtid: &binlogdatapb.VGtid{ShardGtids: []*binlogdatapb.ShardGtid{{
+			Keyspace: "commerce",
+			Shard:    "-80",
+			Gtid:     gtid,
+			TablePKs: tablePKs,
+		}}},
+	}
+}
+
+func newTestSink(t *testing.T, cluster *kfake.Cluster) *Sink {
+	sink, err := NewSink(context.Background(), Config{
+		Name:            "cdc",
+		Brokers:         cluster.ListenAddrs(),
+		CheckpointTopic: "cdc-checkpoints",
+		Tombstones:      true,
+		MaxBatchEvents:  2,
+		CommitInterval:  time.Hour,
+	})
+	require.NoError(t, err)
+	t.Cleanup(sink.Close)
+	sink.now = func() time.Time { return time.UnixMilli(1700000000123) }
+	return sink
+}
+
+// readTopic reads the records of a topic, until it has read count of them.
+func readTopic(t *testing.T, cluster *kfake.Cluster, topic string, count int) []*kgo.Record {
+	consumer, err := kgo.NewClient(
+		kgo.SeedBrokers(cluster.ListenAddrs()...),
+		kgo.ConsumeTopics(topic),
+		kgo.ConsumeResetOffset(kgo.NewOffset().AtStart()),
+	)
+	require.NoError(t, err)
+	defer consumer.Close()
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+	var records []*kgo.Record
+	for len(records) < count {
+		fetches := consumer.PollFetches(ctx)
+		require.NoError(t, fetches.Err())
+		records = append(records, fetches.Records()...)
+	}
+	return records
+}
+
+func TestSink(t *testing.T) {
+	ctx := context.Background()
+	cluster, err := kfake.NewCluster(kfake.AllowAutoTopicCreation(), kfake.DefaultNumPartitions(1))
+	require.NoError(t, err)
+	defer cluster.Close()
+
+	sink := newTestSink(t, cluster)
+	vgtid, err := sink.Checkpoint(ctx)
+	require.NoError(t, err)
+	assert.Nil(t, vgtid)
+
+	startVgtid := &binlogdatapb.VGtid{ShardGtids: []*binlogdatapb.ShardGtid{{Keyspace: "commerce", Shard: "-80"}}}
+	lastPK := &binlogdatapb.TableLastPK{TableName: "customer"}
+	reader := &fakeVStreamReader{events: [][]*binlogdatapb.VEvent{{
+		{Type: binlogdatapb.VEventType_FIELD, FieldEvent: &binlogdatapb.FieldEvent{
+			TableName: "commerce.customer",
+			Keyspace:  "commerce",
+			Shard:     "-80",
+			Fields:    testFields(),
+		}},
+		rowEvent(rowChange("", "1|alice|10.50|ab")),
+		vgtidEvent("MySQL56/a:1-5", lastPK),
+		{Type: binlogdatapb.VEventType_COPY_COMPLETED},
+		vgtidEvent("MySQL56/a:1-5"),
+	}, {
+		{Type: binlogdatapb.VEventType_BEGIN},
+		rowEvent(rowChange("1|alice|10.50|ab", "1|alicia|10.50|ab")),
+		rowEvent(rowChange("1|alicia|10.50|ab", "")),
+		vgtidEvent("MySQL56/a:1-6"),
+		{Type: binlogdatapb.VEventType_COMMIT},
+	}}}
+	require.NoError(t, sink.Run(ctx, startVgtid, reader))
+
+	records := readTopic(t, cluster, "cdc.commerce.customer", 4)
+	require.Len(t, records, 4)
+	for _, record := range records {
+		assert.Equal(t, `{"id":1}`, string(record.Key))
+	}
+	assert.Nil(t, records[3].Value, "a tombstone follows the deletion")
+
+	var envelopes []map[string]any
+	for _, record := range records[:3] {
+		var envelope map[string]any
+		require.NoError(t, json.Unmarshal(record.Value, &envelope))
+		envelopes = append(envelopes, envelope)
+	}
+	read := envelopes[0]
+	assert.Equal(t, "r", read["op"])
+	assert.Nil(t, read["before"])
+	assert.Equal(t, map[string]any{"id": float64(1), "name": "alice", "balance": "10.50", "photo": "YWI="}, read["after"])
+	assert.Equal(t, float64(1700000000123), read["ts_ms"])
+	assert.Equal(t, map[string]any{
+		"version":   sink.version,
+		"connector": "vitess",
+		"name":      "cdc",
+		"ts_ms":     float64(1700000000000),
+		"snapshot":  "true",
+		"db":        "commerce",
+		"keyspace":  "commerce",
+		"table":     "customer",
+		"shard":     "-80",
+		"vgtid":     `[{"keyspace":"commerce","shard":"-80","gtid":""}]`,
+	}, read["source"])
+
+	update := envelopes[1]
+	assert.Equal(t, "u", update["op"])
+	assert.Equal(t, "alice", update["before"].(map[string]any)["name"])
+	assert.Equal(t, "alicia", update["after"].(map[string]any)["name"])
+	assert.Equal(t, "false", update["source"].(map[string]any)["snapshot"])
+	assert.Equal(t, `[{"keyspace":"commerce","shard":"-80","gtid":"MySQL56/a:1-5"}]`, update["source"].(map[string]any)["vgtid"])
+
+	deletion := envelopes[2]
+	assert.Equal(t, "d", deletion["op"])
+	assert.Equal(t, "alicia", deletion["before"].(map[string]any)["name"])
+	assert.Nil(t, deletion["after"])
+
+	// A new sink resumes from the last checkpoint.
+	sink = newTestSink(t, cluster)
+	vgtid, err = sink.Checkpoint(ctx)
+	require.NoError(t, err)
+	assert.Equal(t, "MySQL56/a:1-6", vgtid.ShardGtids[0].Gtid)
+	assert.Empty(t, vgtid.ShardGtids[0].TablePKs)
+
+	// A stream that fails in the middle of a batch leaves the checkpoint as
+	// it was.
+	reader = &fakeVStreamReader{
+		events: [][]*binlogdatapb.VEvent{{
+			{Type: binlogdatapb.VEventType_FIELD, FieldEvent: &binlogdatapb.FieldEvent{
+				TableName: "commerce.customer",
+				Keyspace:  "commerce",
+				Shard:     "-80",
+				Fields:    testFields(),
+			}},
+			rowEvent(rowChange("", "2|bob|1.00|")),
+			vgtidEvent("MySQL56/a:1-7"),
+		}},
+		err: errors.New("stream failed"),
+	}
+	assert.ErrorContains(t, sink.Run(ctx, vgtid, reader), "stream failed")
+	vgtid, err = sink.Checkpoint(ctx)
+	require.NoError(t, err)
+	assert.Equal(t, "MySQL56/a:1-6", vgtid.ShardGtids[0].Gtid)
+}
+
+func TestSinkPrimaryKeyUpdate(t *testing.T) {
+	ctx := context.Background()
+	cluster, err := kfake.NewCluster(kfake.AllowAutoTopicCreation(), kfake.DefaultNumPartitions(1))
+	require.NoError(t, err)
+	defer cluster.Close()
+
+	sink := newTestSink(t, cluster)
+	reader := &fakeVStreamReader{events: [][]*binlogdatapb.VEvent{{
+		{Type: binlogdatapb.VEventType_FIELD, FieldEvent: &binlogdatapb.FieldEvent{
+			TableName: "commerce.customer",
+			Keyspace:  "commerce",
+			Shard:     "-80",
+			Fields:    testFields(),
+		}},
+		rowEvent(rowChange("1|alice|10.50|", "2|alice|10.50|")),
+		vgtidEvent("MySQL56/a:1-5"),
+	}}}
+	require.NoError(t, sink.Run(ctx, vgtidEvent("MySQL56/a:1-4").Vgtid, reader))
+
+	// The row is deleted with its old key, and created with its new one.
+	records := readTopic(t, cluster, "cdc.commerce.customer", 3)
+	require.Len(t, records, 3)
+	assert.Equal(t, `{"id":1}`, string(records[0].Key))
+	assert.Contains(t, string(records[0].Value), `"op":"d"`)
+	assert.Equal(t, `{"id":1}`, string(records[1].Key))
+	assert.Nil(t, records[1].Value)
+	assert.Equal(t, `{"id":2}`, string(records[2].Key))
+	assert.Contains(t, string(records[2].Value), `"op":"c"`)
+
+	// Rows of unknown tables are refused.
+	reader = &fakeVStreamReader{events: [][]*binlogdatapb.VEvent{{{
+		Type:     binlogdatapb.VEventType_ROW,
+		RowEvent: &binlogdatapb.RowEvent{TableName: "commerce.corder", Keyspace: "commerce", Shard: "-80"},
+	}}}}
+	assert.ErrorContains(t, sink.Run(ctx, nil, reader), "no fields received for table commerce.corder")
+}
+
+func TestJSONValue(t *testing.T) {
+	testcases := []struct {
+		value sqltypes.Value
+		want  string
+	}{
+		{sqltypes.NULL, `null`},
+		{sqltypes.NewInt64(-3), `-3`},
+		{sqltypes.NewUint64(18446744073709551615), `18446744073709551615`},
+		{sqltypes.NewFloat64(1.5), `1.5`},
+		{sqltypes.NewDecimal("12345678901234567890.123"), `"12345678901234567890.123"`},
+		{sqltypes.NewVarChar("héllo"), `"héllo"`},
+		{sqltypes.NewVarBinary("\x00\xff"), `"AP8="`},
+		{sqltypes.MakeTrusted(sqltypes.Bit, []byte{0x05}), `"BQ=="`},
+		{sqltypes.NewDatetime("2025-01-02 03:04:05"), `"2025-01-02 03:04:05"`},
+		{sqltypes.MakeTrusted(sqltypes.TypeJSON, []byte(`{"a":1}`)), `"{\"a\":1}"`},
+	}
+	for _, tcase := range testcases {
+		t.Run(tcase.value.String(), func(t *testing.T) {
+			data, err := json.Marshal(jsonValue(tcase.value))
+			require.NoError(t, err)
+			assert.Equal(t, tcase.want, string(data))
+		})
+	}
+}
@@ -362,90 +362,9 @@ func newAggregation(fields []*querypb.Field, aggregates []*AggregateParams) (agg
 		sourceType := fields[aggr.Col].Type
 		targetType := aggr.typ(sourceType)
 
-		var ag aggregator
-		var distinct = -1
-
-		if aggr.Opcode.IsDistinct() {
-			distinct = aggr.KeyCol
-			if aggr.WAssigned() && !isComparable(sourceType) {
-				distinct = aggr.WCol
-			}
-		}
-
-		if aggr.Opcode == opcode.AggregateMin || aggr.Opcode == opcode.AggregateMax {
-			if aggr.WAssigned() && !isComparable(sourceType) {
-				return nil, nil, vterrors.VT12001("min/max on types that are not comparable is not supported")
-			}
-		}
-
-		switch aggr.Opcode {
-		case opcode.AggregateCountStar:
-			ag = &aggregatorCountStar{}
-
-		case opcode.AggregateCount, opcode.AggregateCountDistinct:
-			ag = &aggregatorCount{
-				from: aggr.Col,
-				distinct: aggregatorDistinct{
-					column:       distinct,
-					coll:         aggr.Type.Collation(),
-					collationEnv: aggr.CollationEnv,
-					values:       aggr.Type.Values(),
-				},
-			}
-
-		case opcode.AggregateSum, opcode.AggregateSumDistinct:
-			var sum evalengine.Sum
-			switch aggr.OrigOpcode {
-			case opcode.AggregateCount, opcode.AggregateCountStar, opcode.AggregateCountDistinct:
-				sum = evalengine.NewSumOfCounts()
-			default:
-				sum = evalengine.NewAggregationSum(sourceType)
-			}
-
-			ag = &aggregatorSum{
-				from: aggr.Col,
-				sum:  sum,
-				distinct: aggregatorDistinct{
-					column:       distinct,
-					coll:         aggr.Type.Collation(),
-					collationEnv: aggr.CollationEnv,
-					values:       aggr.Type.Values(),
-				},
-			}
-
-		case opcode.AggregateMin:
-			ag = &aggregatorMin{
-				aggregatorMinMax{
-					from:   aggr.Col,
-					minmax: evalengine.NewAggregationMinMax(sourceType, aggr.CollationEnv, aggr.Type.Collation(), aggr.Type.Values()),
-				},
-			}
-
-		case opcode.AggregateMax:
-			ag = &aggregatorMax{
-				aggregatorMinMax{
-					from:   aggr.Col,
-					minmax: evalengine.NewAggregationMinMax(sourceType, aggr.CollationEnv, aggr.Type.Collation(), aggr.Type.Values()),
-				},
-			}
-
-		case opcode.AggregateGtid:
-			ag = &aggregatorGtid{from: aggr.Col}
-
-		case opcode.AggregateAnyValue:
-			ag = &aggregatorScalar{from: aggr.Col}
-
-		case opcode.AggregateGroupConcat:
-			gcFunc := aggr.Func.(*sqlparser.GroupConcatExpr)
-			separator := []byte(gcFunc.Separator)
-			ag = &aggregatorGroupConcat{
-				from:      aggr.Col,
-				type_:     targetType,
-				separator: separator,
-			}
-
-		default:
-			panic("BUG: unexpected Aggregation opcode")
+		ag, err := newAggregator(aggr, sourceType, targetType)
+		if err != nil {
+			return nil, nil, err
 		}
 
 		agstate[aggr.Col] = ag
@@ -463,3 +382,91 @@ func newAggregation(fields []*querypb.Field, aggregates []*AggregateParams) (agg
 
 	return agstate, fields, nil
 }
+
+// newAggregator creates the aggregator for a single aggregation, reading its input from aggr.Col
+func newAggregator(aggr *AggregateParams, sourceType, targetType querypb.Type) (aggregator, error) {
+	var distinct = -1
+
+	if aggr.Opcode.IsDistinct() {
+		distinct = aggr.KeyCol
+		if aggr.WAssigned() && !isComparable(sourceType) {
+			distinct = aggr.WCol
+		}
+	}
+
+	if aggr.Opcode == opcode.AggregateMin || aggr.Opcode == opcode.AggregateMax {
+		if aggr.WAssigned() && !isComparable(sourceType) {
+			return nil, vterrors.VT12001("min/max on types that are not comparable is not supported")
+		}
+	}
+
+	switch aggr.Opcode {
+	case opcode.AggregateCountStar:
+		return &aggregatorCountStar{}, nil
+
+	case opcode.AggregateCount, opcode.AggregateCountDistinct:
+		return &aggregatorCount{
+			from: aggr.Col,
+			distinct: aggregatorDistinct{
+				column:       distinct,
+				coll:         aggr.Type.Collation(),
+				collationEnv: aggr.CollationEnv,
+				values:       aggr.Type.Values(),
+			},
+		}, nil
+
+	case opcode.AggregateSum, opcode.AggregateSumDistinct:
+		var sum evalengine.Sum
+		switch aggr.OrigOpcode {
+		case opcode.AggregateCount, opcode.AggregateCountStar, opcode.AggregateCountDistinct:
+			sum = evalengine.NewSumOfCounts()
+		default:
+			sum = evalengine.NewAggregationSum(sourceType)
+		}
+
+		return &aggregatorSum{
+			from: aggr.Col,
+			sum:  sum,
+			distinct: aggregatorDistinct{
+				column:       distinct,
+				coll:         aggr.Type.Collation(),
+				collationEnv: aggr.CollationEnv,
+				values:       aggr.Type.Values(),
+			},
+		}, nil
+
+	case opcode.AggregateMin:
+		return &aggregatorMin{
+			aggregatorMinMax{
+				from:   aggr.Col,
+				minmax: evalengine.NewAggregationMinMax(sourceType, aggr.CollationEnv, aggr.Type.Collation(), aggr.Type.Values()),
+			},
+		}, nil
+
+	case opcode.AggregateMax:
+		return &aggregatorMax{
+			aggregatorMinMax{
+				from:   aggr.Col,
+				minmax: evalengine.NewAggregationMinMax(sourceType, aggr.CollationEnv, aggr.Type.Collation(), aggr.Type.Values()),
+			},
+		}, nil
+
+	case opcode.AggregateGtid:
+		return &aggregatorGtid{from: aggr.Col}, nil
+
+	case opcode.AggregateAnyValue:
+		return &aggregatorScalar{from: aggr.Col}, nil
+
+	case opcode.AggregateGroupConcat:
+		gcFunc := aggr.Func.(*sqlparser.GroupConcatExpr)
+		separator := []byte(gcFunc.Separator)
+		return &aggregatorGroupConcat{
+			from:      aggr.Col,
+			type_:     targetType,
+			separator: separator,
+		}, nil
+
+	default:
+		panic("BUG: unexpected Aggregation opcode")
+	}
+}
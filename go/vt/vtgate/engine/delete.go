@@ -55,7 +55,7 @@ func (del *Delete) TryExecute(ctx context.Context, vcursor VCursor, bindVars map
 		return &sqltypes.Result{}, nil
 	case Unsharded:
 		return del.execUnsharded(ctx, del, vcursor, bindVars, rss)
-	case Equal, IN, Scatter, ByDestination, SubShard, EqualUnique, MultiEqual:
+	case Equal, IN, Range, Scatter, ByDestination, SubShard, EqualUnique, MultiEqual:
 		return del.execMultiDestination(ctx, del, vcursor, bindVars, rss, del.deleteVindexEntries, bvs)
 	default:
 		// Unreachable.
@@ -422,6 +422,21 @@ func (t *noopVCursor) GetLogs() ([]ExecuteEntry, error) {
 func (t *noopVCursor) RecordMirrorStats(sourceExecTime, targetExecTime time.Duration, targetErr error) {
 }
 
+// GetCachedResults implements VCursor.
+func (t *noopVCursor) GetCachedResults() CachedResults {
+	return nil
+}
+
+// TabletType implements VCursor.
+func (t *noopVCursor) TabletType() topodatapb.TabletType {
+	return topodatapb.TabletType_PRIMARY
+}
+
+// ShardDestination implements VCursor.
+func (t *noopVCursor) ShardDestination() key.ShardDestination {
+	return nil
+}
+
 var (
 	_ VCursor        = (*loggingVCursor)(nil)
 	_ SessionActions = (*loggingVCursor)(nil)
@@ -472,6 +487,11 @@ type loggingVCursor struct {
 	onStreamExecuteMultiFn func(context.Context, Primitive, string, []*srvtopo.ResolvedShard, []map[string]*querypb.BindVariable, bool, bool, func(*sqltypes.Result) error)
 	onRecordMirrorStatsFn  func(time.Duration, time.Duration, error)
 
+	cachedResults    CachedResults
+	tabletType       topodatapb.TabletType
+	shardDestination key.ShardDestination
+	collation        collations.ID
+
 	metrics *Metrics
 }
 
@@ -521,8 +541,10 @@ func (f *loggingVCursor) HasSystemVariables() bool {
 	return len(f.systemVariables) > 0
 }
 
-func (f *loggingVCursor) GetSystemVariables(func(k string, v string)) {
-	panic("implement me")
+func (f *loggingVCursor) GetSystemVariables(fn func(k string, v string)) {
+	for k, v := range f.systemVariables {
+		fn(k, v)
+	}
 }
 
 func (f *loggingVCursor) SetFoundRows(u uint64) {
@@ -925,6 +947,25 @@ func (t *loggingVCursor) RecordMirrorStats(sourceExecTime, targetExecTime time.D
 	}
 }
 
+func (t *loggingVCursor) GetCachedResults() CachedResults {
+	return t.cachedResults
+}
+
+func (t *loggingVCursor) TabletType() topodatapb.TabletType {
+	return t.tabletType
+}
+
+func (t *loggingVCursor) ShardDestination() key.ShardDestination {
+	return t.shardDestination
+}
+
+func (t *loggingVCursor) ConnCollation() collations.ID {
+	if t.collation != collations.Unknown {
+		return t.collation
+	}
+	return t.noopVCursor.ConnCollation()
+}
+
 func expectResult(t *testing.T, result, want *sqltypes.Result) {
 	t.Helper()
 	fieldsResult := fmt.Sprintf("%v", result.Fields)
@@ -232,7 +232,7 @@ func getPlanTypeFromRoutingParams(rp *RoutingParameters) PlanType {
 			return PlanLookup
 		}
 		return PlanPassthrough
-	case Equal, IN, Between, MultiEqual, SubShard, ByDestination:
+	case Equal, IN, Between, Range, MultiEqual, SubShard, ByDestination:
 		if rp.Vindex != nil && rp.Vindex.NeedsVCursor() {
 			return PlanLookup
 		}
@@ -148,6 +148,15 @@ type (
 		// RecordMirrorStats is used to record stats about a mirror query.
 		RecordMirrorStats(time.Duration, time.Duration, error)
 
+		// GetCachedResults returns the cache of query results, or nil if the results
+		// of the current statement cannot be served from the cache.
+		GetCachedResults() CachedResults
+
+		// TabletType returns the tablet type targeted by the session.
+		TabletType() topodatapb.TabletType
+		// ShardDestination returns the shard destination targeted by the session, if any.
+		ShardDestination() key.ShardDestination
+
 		SetLastInsertID(uint64)
 
 		GetExecutionMetrics() *Metrics
@@ -0,0 +1,198 @@
+/*
+Copyright 2025 The Vitess Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package engine
+
+import (
+	"context"
+	"slices"
+	"strconv"
+	"strings"
+	"time"
+
+	"google.golang.org/protobuf/proto"
+
+	"vitess.io/vitess/go/sqltypes"
+	"vitess.io/vitess/go/vt/callerid"
+	querypb "vitess.io/vitess/go/vt/proto/query"
+	"vitess.io/vitess/go/vt/topo/topoproto"
+)
+
+var _ Primitive = (*ResultCache)(nil)
+
+type (
+	// CachedResults stores the results of read-only queries for the ResultCache primitive.
+	CachedResults interface {
+		// Get returns the result cached under the given key, if it has not expired.
+		Get(key string) (*sqltypes.Result, bool)
+		// Set caches a result for the given TTL. The result is dropped earlier if the schema
+		// of one of the tables it was read from changes, or if the vtgate writes to one of them,
+		// but writes made elsewhere are only seen once the TTL expires.
+		Set(key string, tables []string, ttl time.Duration, result *sqltypes.Result)
+	}
+
+	// ResultCache is a primitive that serves the results of its read-only input from
+	// the cache of the vtgate, when the session allows it, and caches them on a miss.
+	ResultCache struct {
+		noTxNeeded
+
+		// Query is the normalized query, which together with the bind variables
+		// identifies the results.
+		Query string
+		// Tables are the tables read by the query, used to drop its results when the
+		// vtgate alters or writes to them.
+		Tables []string
+		// TTL is how long the results are cached for, which bounds how stale they can be.
+		TTL time.Duration
+
+		Input Primitive
+	}
+)
+
+// TryExecute implements the Primitive interface
+func (rc *ResultCache) TryExecute(ctx context.Context, vcursor VCursor, bindVars map[string]*querypb.BindVariable, wantfields bool) (*sqltypes.Result, error) {
+	cache := vcursor.GetCachedResults()
+	if cache == nil {
+		return vcursor.ExecutePrimitive(ctx, rc.Input, bindVars, wantfields)
+	}
+	key, err := rc.cacheKey(ctx, vcursor, bindVars)
+	if err != nil {
+		return nil, err
+	}
+	if qr, ok := cache.Get(key); ok {
+		return qr, nil
+	}
+
+	// Always fetch the fields, since the result can be served to any later execution.
+	qr, err := vcursor.ExecutePrimitive(ctx, rc.Input, bindVars, true)
+	if err != nil {
+		return nil, err
+	}
+	cache.Set(key, rc.Tables, rc.TTL, qr)
+	return qr, nil
+}
+
+// TryStreamExecute implements the Primitive interface
+func (rc *ResultCache) TryStreamExecute(ctx context.Context, vcursor VCursor, bindVars map[string]*querypb.BindVariable, wantfields bool, callback func(*sqltypes.Result) error) error {
+	cache := vcursor.GetCachedResults()
+	if cache == nil {
+		return vcursor.StreamExecutePrimitive(ctx, rc.Input, bindVars, wantfields, callback)
+	}
+	key, err := rc.cacheKey(ctx, vcursor, bindVars)
+	if err != nil {
+		return err
+	}
+	if qr, ok := cache.Get(key); ok {
+		return callback(qr)
+	}
+
+	// The streamed results are only cached if they fit in memory.
+	full := &sqltypes.Result{}
+	cacheable := true
+	err = vcursor.StreamExecutePrimitive(ctx, rc.Input, bindVars, true, func(qr *sqltypes.Result) error {
+		if cacheable {
+			if full.Fields == nil {
+				full.Fields = qr.Fields
+			}
+			full.Rows = append(full.Rows, qr.Rows...)
+			cacheable = !vcursor.ExceedsMaxMemoryRows(len(full.Rows))
+		}
+		return callback(qr)
+	})
+	if err != nil {
+		return err
+	}
+	if cacheable {
+		cache.Set(key, rc.Tables, rc.TTL, full)
+	}
+	return nil
+}
+
+// cacheKey returns the key of the results of the query for the given bind variables.
+// Besides the query, the key includes everything of the session that can change its
+// results: the target of the session, the connection collation and the system
+// settings, which are passed down to the tablets. It also includes the immediate
+// caller, since the table ACLs of the tablets may give different callers access to
+// different tables, and the results of one caller must never be served to another.
+func (rc *ResultCache) cacheKey(ctx context.Context, vcursor VCursor, bindVars map[string]*querypb.BindVariable) (string, error) {
+	var sb strings.Builder
+	// Every part is prefixed with its length, so that parts cannot run into each other.
+	writePart := func(part string) {
+		sb.WriteString(strconv.Itoa(len(part)))
+		sb.WriteByte(':')
+		sb.WriteString(part)
+	}
+
+	writePart(vcursor.GetKeyspace())
+	if dest := vcursor.ShardDestination(); dest != nil {
+		writePart(dest.String())
+	} else {
+		writePart("")
+	}
+	writePart(topoproto.TabletTypeLString(vcursor.TabletType()))
+	writePart(strconv.Itoa(int(vcursor.ConnCollation())))
+
+	var settings []string
+	vcursor.Session().GetSystemVariables(func(k, v string) {
+		settings = append(settings, k+"="+v)
+	})
+	slices.Sort(settings)
+	writePart(strconv.Itoa(len(settings)))
+	for _, setting := range settings {
+		writePart(setting)
+	}
+
+	marshal := proto.MarshalOptions{Deterministic: true}
+	var caller []byte
+	if im := callerid.ImmediateCallerIDFromContext(ctx); im != nil {
+		var err error
+		caller, err = marshal.Marshal(im)
+		if err != nil {
+			return "", err
+		}
+	}
+	writePart(string(caller))
+
+	bq, err := marshal.Marshal(&querypb.BoundQuery{
+		Sql:           rc.Query,
+		BindVariables: bindVars,
+	})
+	if err != nil {
+		return "", err
+	}
+	sb.Write(bq)
+	return sb.String(), nil
+}
+
+// GetFields implements the Primitive interface
+func (rc *ResultCache) GetFields(ctx context.Context, vcursor VCursor, bindVars map[string]*querypb.BindVariable) (*sqltypes.Result, error) {
+	return rc.Input.GetFields(ctx, vcursor, bindVars)
+}
+
+// Inputs implements the Primitive interface
+func (rc *ResultCache) Inputs() ([]Primitive, []map[string]any) {
+	return []Primitive{rc.Input}, nil
+}
+
+func (rc *ResultCache) description() PrimitiveDescription {
+	return PrimitiveDescription{
+		OperatorType: "ResultCache",
+		Other: map[string]any{
+			"TTL":    rc.TTL.String(),
+			"Tables": strings.Join(rc.Tables, ", "),
+		},
+	}
+}
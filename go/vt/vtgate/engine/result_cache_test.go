@@ -0,0 +1,181 @@
+/*
+Copyright 2025 The Vitess Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package engine
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"vitess.io/vitess/go/mysql/collations"
+	"vitess.io/vitess/go/sqltypes"
+	"vitess.io/vitess/go/vt/callerid"
+	"vitess.io/vitess/go/vt/key"
+	querypb "vitess.io/vitess/go/vt/proto/query"
+	topodatapb "vitess.io/vitess/go/vt/proto/topodata"
+)
+
+type fakeCachedResults struct {
+	results map[string]*sqltypes.Result
+	tables  map[string][]string
+}
+
+func (f *fakeCachedResults) Get(key string) (*sqltypes.Result, bool) {
+	qr, ok := f.results[key]
+	return qr, ok
+}
+
+func (f *fakeCachedResults) Set(key string, tables []string, ttl time.Duration, result *sqltypes.Result) {
+	f.results[key] = result
+	f.tables[key] = tables
+}
+
+func TestResultCache(t *testing.T) {
+	result := sqltypes.MakeTestResult(sqltypes.MakeTestFields("id|name", "int64|varchar"), "1|a", "2|b")
+	input := &fakePrimitive{results: []*sqltypes.Result{result}}
+	rc := &ResultCache{
+		Query:  "select id, `name` from config where id > :id",
+		Tables: []string{"ks.config"},
+		TTL:    time.Second,
+		Input:  input,
+	}
+	cache := &fakeCachedResults{results: map[string]*sqltypes.Result{}, tables: map[string][]string{}}
+	vc := &loggingVCursor{cachedResults: cache}
+	bv := map[string]*querypb.BindVariable{"id": sqltypes.Int64BindVariable(0)}
+
+	// The first execution is a miss, which is cached with its tables.
+	qr, err := rc.TryExecute(context.Background(), vc, bv, false)
+	require.NoError(t, err)
+	expectResult(t, qr, result)
+	input.ExpectLog(t, []string{`Execute id: type:INT64 value:"0" true`})
+	require.Len(t, cache.results, 1)
+	for _, tables := range cache.tables {
+		assert.Equal(t, []string{"ks.config"}, tables)
+	}
+
+	// The same query with the same bind variables is served from the cache.
+	input.rewind()
+	qr, err = rc.TryExecute(context.Background(), vc, bv, true)
+	require.NoError(t, err)
+	expectResult(t, qr, result)
+	input.ExpectLog(t, nil)
+
+	qr, err = wrapStreamExecute(rc, vc, bv, true)
+	require.NoError(t, err)
+	expectResult(t, qr, result)
+	input.ExpectLog(t, nil)
+
+	// Other bind variables are not.
+	input.rewind()
+	_, err = rc.TryExecute(context.Background(), vc, map[string]*querypb.BindVariable{"id": sqltypes.Int64BindVariable(1)}, true)
+	require.NoError(t, err)
+	input.ExpectLog(t, []string{`Execute id: type:INT64 value:"1" true`})
+	assert.Len(t, cache.results, 2)
+
+	// Nor are the results of another caller, which may not be allowed to read them.
+	for _, im := range []*querypb.VTGateCallerID{
+		callerid.NewImmediateCallerID("alice"),
+		callerid.NewImmediateCallerID("bob"),
+		{Username: "bob", Groups: []string{"admin"}},
+	} {
+		ctx := callerid.NewContext(context.Background(), nil, im)
+		input.rewind()
+		_, err = rc.TryExecute(ctx, vc, bv, true)
+		require.NoError(t, err)
+		input.ExpectLog(t, []string{`Execute id: type:INT64 value:"0" true`})
+
+		input.rewind()
+		_, err = rc.TryExecute(ctx, vc, bv, true)
+		require.NoError(t, err)
+		input.ExpectLog(t, nil)
+	}
+	assert.Len(t, cache.results, 5)
+
+	// Nor are the results read with other session settings.
+	for _, vc := range []*loggingVCursor{
+		{cachedResults: cache, tabletType: topodatapb.TabletType_RDONLY},
+		{cachedResults: cache, shardDestination: key.DestinationShard("-80")},
+		{cachedResults: cache, collation: collations.CollationBinaryID},
+		{cachedResults: cache, systemVariables: map[string]string{"sql_mode": "''"}},
+	} {
+		input.rewind()
+		_, err = rc.TryExecute(context.Background(), vc, bv, true)
+		require.NoError(t, err)
+		input.ExpectLog(t, []string{`Execute id: type:INT64 value:"0" true`})
+
+		input.rewind()
+		_, err = rc.TryExecute(context.Background(), vc, bv, true)
+		require.NoError(t, err)
+		input.ExpectLog(t, nil)
+	}
+	assert.Len(t, cache.results, 9)
+}
+
+func TestResultCacheStreamExecute(t *testing.T) {
+	result := sqltypes.MakeTestResult(sqltypes.MakeTestFields("id", "int64"), "1", "2", "3")
+	input := &fakePrimitive{results: []*sqltypes.Result{result}}
+	rc := &ResultCache{
+		Query: "select id from config",
+		TTL:   time.Second,
+		Input: input,
+	}
+	cache := &fakeCachedResults{results: map[string]*sqltypes.Result{}, tables: map[string][]string{}}
+	vc := &loggingVCursor{cachedResults: cache}
+
+	qr, err := wrapStreamExecute(rc, vc, nil, true)
+	require.NoError(t, err)
+	expectResult(t, qr, result)
+	require.Len(t, cache.results, 1)
+	for _, cached := range cache.results {
+		expectResult(t, cached, result)
+	}
+
+	// Results that do not fit in memory are not cached.
+	cache.results = map[string]*sqltypes.Result{}
+	input.rewind()
+	testMaxMemoryRows = 2
+	defer func() {
+		testMaxMemoryRows = 100
+	}()
+	qr, err = wrapStreamExecute(rc, vc, nil, true)
+	require.NoError(t, err)
+	expectResult(t, qr, result)
+	assert.Empty(t, cache.results)
+}
+
+func TestResultCacheDisabled(t *testing.T) {
+	result := sqltypes.MakeTestResult(sqltypes.MakeTestFields("id", "int64"), "1")
+	input := &fakePrimitive{results: []*sqltypes.Result{result}}
+	rc := &ResultCache{
+		Query: "select id from config",
+		TTL:   time.Second,
+		Input: input,
+	}
+
+	// Without a cache, every execution runs the input.
+	vc := &loggingVCursor{}
+	for range 2 {
+		input.rewind()
+		qr, err := rc.TryExecute(context.Background(), vc, nil, false)
+		require.NoError(t, err)
+		expectResult(t, qr, result)
+		input.ExpectLog(t, []string{`Execute  false`})
+	}
+}
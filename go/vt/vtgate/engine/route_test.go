@@ -261,6 +261,67 @@ func TestSelectEqualUnique(t *testing.T) {
 	expectResult(t, result, defaultSelectResult)
 }
 
+func TestSelectBetween(t *testing.T) {
+	vindex, err := vindexes.CreateVindex("numeric_range", "", map[string]string{
+		"json": `[{"from": 1, "to": 100, "key_range": "-20"}, {"from": 101, "to": 200, "key_range": "40-"}]`,
+	})
+	require.NoError(t, err)
+	sel := NewRoute(
+		Between,
+		&vindexes.Keyspace{
+			Name:    "ks",
+			Sharded: true,
+		},
+		"dummy_select",
+		"dummy_select_field",
+	)
+	sel.Vindex = vindex.(vindexes.SingleColumn)
+
+	sel.Values = []evalengine.Expr{
+		evalengine.TupleExpr{evalengine.NewLiteralInt(50), evalengine.NewLiteralInt(150)},
+	}
+	vc := &loggingVCursor{
+		shardForKsid: []string{"-20", "40-"},
+		results:      []*sqltypes.Result{defaultSelectResult},
+	}
+	result, err := sel.TryExecute(context.Background(), vc, map[string]*querypb.BindVariable{}, false)
+	require.NoError(t, err)
+	vc.ExpectLog(t, []string{
+		`ResolveDestinations ks [] Destinations:DestinationKeyRange(0fae147ae147ae14-2000000000000000),DestinationKeyRange(4000000000000000-a000000000000000)`,
+		`ExecuteMultiShard ks.-20: dummy_select {__vals: type:TUPLE values:{type:INT64 value:"50"} values:{type:INT64 value:"150"}} ks.40-: dummy_select {__vals: type:TUPLE values:{type:INT64 value:"50"} values:{type:INT64 value:"150"}} false false`,
+	})
+	expectResult(t, result, defaultSelectResult)
+}
+
+func TestSelectRange(t *testing.T) {
+	vindex, _ := vindexes.CreateVindex("numeric", "", nil)
+	sel := NewRoute(
+		Range,
+		&vindexes.Keyspace{
+			Name:    "ks",
+			Sharded: true,
+		},
+		"dummy_select",
+		"dummy_select_field",
+	)
+	sel.Vindex = vindex.(vindexes.SingleColumn)
+
+	sel.Values = []evalengine.Expr{
+		evalengine.TupleExpr{evalengine.NewLiteralInt(5), evalengine.NullExpr},
+	}
+	vc := &loggingVCursor{
+		shardForKsid: []string{"-20", "20-"},
+		results:      []*sqltypes.Result{defaultSelectResult},
+	}
+	result, err := sel.TryExecute(context.Background(), vc, map[string]*querypb.BindVariable{}, false)
+	require.NoError(t, err)
+	vc.ExpectLog(t, []string{
+		`ResolveDestinations ks [] Destinations:DestinationKeyRange(0000000000000005-)`,
+		`ExecuteMultiShard ks.-20: dummy_select {__vals: type:TUPLE values:{type:INT64 value:"5"} values:{}} ks.20-: dummy_select {__vals: type:TUPLE values:{type:INT64 value:"5"} values:{}} false false`,
+	})
+	expectResult(t, result, defaultSelectResult)
+}
+
 func TestSelectNone(t *testing.T) {
 	vindex, _ := vindexes.CreateVindex("hash", "", nil)
 	sel := NewRoute(
@@ -74,6 +74,10 @@ const (
 	// Is used when the query explicitly sets a target destination:
 	// in the clause e.g: UPDATE `keyspace[-]`.x1 SET foo=1
 	ByDestination
+	// Range is for routing a statement with an inequality predicate to the shards
+	// covering the range of values it selects.
+	// Requires: A RangeMappable Vindex, and start and end Value, either of which can be NULL.
+	Range
 )
 
 var opName = map[Opcode]string{
@@ -90,6 +94,7 @@ var opName = map[Opcode]string{
 	None:          "None",
 	ByDestination: "ByDestination",
 	SubShard:      "SubShard",
+	Range:         "Range",
 }
 
 // MarshalJSON serializes the Opcode as a JSON string.
@@ -169,6 +174,13 @@ func (rp *RoutingParameters) findRoute(ctx context.Context, vcursor VCursor, bin
 			// Only SingleColumn vindex supported.
 			return nil, nil, vterrors.VT13001("between supported on SingleColumn vindex only")
 		}
+	case Range:
+		switch rp.Vindex.(type) {
+		case vindexes.RangeMappable:
+			return rp.rangeOp(ctx, vcursor, bindVars)
+		default:
+			return nil, nil, vterrors.VT13001("range supported on RangeMappable vindex only")
+		}
 	case MultiEqual:
 		switch rp.Vindex.(type) {
 		case vindexes.MultiColumn:
@@ -421,6 +433,24 @@ func (rp *RoutingParameters) between(ctx context.Context, vcursor VCursor, bindV
 	return rss, shardVars(bindVars, values), nil
 }
 
+func (rp *RoutingParameters) rangeOp(ctx context.Context, vcursor VCursor, bindVars map[string]*querypb.BindVariable) ([]*srvtopo.ResolvedShard, []map[string]*querypb.BindVariable, error) {
+	env := evalengine.NewExpressionEnv(ctx, bindVars, vcursor)
+	value, err := env.Evaluate(rp.Values[0])
+	if err != nil {
+		return nil, nil, err
+	}
+	bounds := value.TupleValues()
+	destinations, err := rp.Vindex.(vindexes.RangeMappable).RangeMapOpen(ctx, vcursor, bounds[0], bounds[1])
+	if err != nil {
+		return nil, nil, err
+	}
+	rss, values, err := resolveRangeDestinations(ctx, vcursor, rp.Keyspace, bounds, destinations)
+	if err != nil {
+		return nil, nil, err
+	}
+	return rss, shardVars(bindVars, values), nil
+}
+
 func (rp *RoutingParameters) multiEqual(ctx context.Context, vcursor VCursor, bindVars map[string]*querypb.BindVariable) ([]*srvtopo.ResolvedShard, []map[string]*querypb.BindVariable, error) {
 	env := evalengine.NewExpressionEnv(ctx, bindVars, vcursor)
 	value, err := env.Evaluate(rp.Values[0])
@@ -553,21 +583,34 @@ func buildMultiColumnVindexValues(shardsValues [][][]sqltypes.Value) [][][]*quer
 }
 
 func resolveShardsBetween(ctx context.Context, vcursor VCursor, vindex vindexes.Sequential, keyspace *vindexes.Keyspace, vindexKeys []sqltypes.Value) ([]*srvtopo.ResolvedShard, [][]*querypb.Value, error) {
-	// Convert vindexKeys to []*querypb.Value
-	ids := make([]*querypb.Value, len(vindexKeys))
-	for i, vik := range vindexKeys {
-		ids[i] = sqltypes.ValueToProto(vik)
-	}
-
 	// RangeMap using the Vindex
 	destinations, err := vindex.RangeMap(ctx, vcursor, vindexKeys[0], vindexKeys[1])
 	if err != nil {
 		return nil, nil, err
 
 	}
+	return resolveRangeDestinations(ctx, vcursor, keyspace, vindexKeys, destinations)
+}
+
+// resolveRangeDestinations resolves the destinations a vindex mapped a range of values to.
+func resolveRangeDestinations(ctx context.Context, vcursor VCursor, keyspace *vindexes.Keyspace, bounds []sqltypes.Value, destinations []key.ShardDestination) ([]*srvtopo.ResolvedShard, [][]*querypb.Value, error) {
+	// Convert bounds to []*querypb.Value
+	ids := make([]*querypb.Value, len(bounds))
+	for i, bound := range bounds {
+		ids[i] = sqltypes.ValueToProto(bound)
+	}
 
-	// And use the Resolver to map to ResolvedShards.
-	return vcursor.ResolveDestinations(ctx, keyspace.Name, ids, destinations)
+	// And use the Resolver to map to ResolvedShards. The vindex can return any number
+	// of destinations for the range, so every shard gets both of its bounds.
+	rss, _, err := vcursor.ResolveDestinations(ctx, keyspace.Name, nil, destinations)
+	if err != nil {
+		return nil, nil, err
+	}
+	values := make([][]*querypb.Value, len(rss))
+	for i := range values {
+		values[i] = ids
+	}
+	return rss, values, nil
 }
 
 func shardVars(bv map[string]*querypb.BindVariable, mapVals [][]*querypb.Value) []map[string]*querypb.BindVariable {
@@ -65,7 +65,7 @@ func (upd *Update) TryExecute(ctx context.Context, vcursor VCursor, bindVars map
 		return &sqltypes.Result{}, nil
 	case Unsharded:
 		return upd.execUnsharded(ctx, upd, vcursor, bindVars, rss)
-	case Equal, EqualUnique, IN, Scatter, ByDestination, SubShard, MultiEqual:
+	case Equal, EqualUnique, IN, Range, Scatter, ByDestination, SubShard, MultiEqual:
 		return upd.execMultiDestination(ctx, upd, vcursor, bindVars, rss, upd.updateVindexEntries, bvs)
 	default:
 		// Unreachable.
@@ -0,0 +1,528 @@
+/*
+Copyright 2024 The Vitess Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package engine
+
+import (
+	"context"
+	"fmt"
+	"math"
+	"strconv"
+	"sync"
+
+	"vitess.io/vitess/go/slice"
+	"vitess.io/vitess/go/sqltypes"
+	querypb "vitess.io/vitess/go/vt/proto/query"
+	"vitess.io/vitess/go/vt/vtgate/engine/opcode"
+	"vitess.io/vitess/go/vt/vtgate/evalengine"
+)
+
+var _ Primitive = (*Window)(nil)
+
+// Window is a primitive that evaluates window functions at the vtgate level.
+// It expects the underlying primitive to feed results sorted by the PartitionBy
+// keys followed by the OrderBy keys. The window function results are added in front
+// of the input columns, so that columns added to the input later on don't shift them.
+type Window struct {
+	// PartitionBy are the keys of the PARTITION BY clause of the window.
+	PartitionBy []*GroupByParams
+	// OrderBy is used to find the peers of a row - rows that are equal according to the ORDER BY of the window.
+	OrderBy evalengine.Comparison
+	// Functions are the window functions evaluated over this window.
+	Functions []*WindowFunction
+
+	Input Primitive
+}
+
+// WindowFunction specifies a single window function evaluated by the Window primitive.
+type WindowFunction struct {
+	Opcode opcode.WindowOpcode
+	// Col is the offset of the argument of the function on the input. It is -1 for functions without arguments.
+	Col int
+	// N is the row count argument used by NTILE, LAG, LEAD and NTH_VALUE.
+	N evalengine.Expr
+	// Default is the value used by LAG and LEAD when the target row is outside the partition.
+	Default evalengine.Expr
+	// Aggregate is set for aggregate functions used as window functions.
+	Aggregate *AggregateParams
+	// Frame is the frame the function is evaluated over. It is only used by functions that respect frames.
+	Frame *WindowFrame
+
+	Alias string
+}
+
+// WindowFrame is the set of rows of a partition a frame aware window function is evaluated over.
+// Start and End are offsets relative to the current row. math.MinInt64 and math.MaxInt64 mean
+// UNBOUNDED PRECEDING and UNBOUNDED FOLLOWING respectively.
+type WindowFrame struct {
+	// Range frames treat all peers of the current row as the current row.
+	// Only unbounded or CURRENT ROW frame points are supported for range frames.
+	Range bool
+	Start int64
+	End   int64
+}
+
+func (wf *WindowFrame) String() string {
+	unit := "ROWS"
+	if wf.Range {
+		unit = "RANGE"
+	}
+	return fmt.Sprintf("%s BETWEEN %s AND %s", unit, framePointToString(wf.Start), framePointToString(wf.End))
+}
+
+func framePointToString(p int64) string {
+	switch {
+	case p == math.MinInt64:
+		return "UNBOUNDED PRECEDING"
+	case p == math.MaxInt64:
+		return "UNBOUNDED FOLLOWING"
+	case p < 0:
+		return strconv.FormatInt(-p, 10) + " PRECEDING"
+	case p > 0:
+		return strconv.FormatInt(p, 10) + " FOLLOWING"
+	default:
+		return "CURRENT ROW"
+	}
+}
+
+func (wf *WindowFunction) String() string {
+	var out string
+	switch {
+	case wf.Aggregate != nil:
+		out = wf.Aggregate.String()
+	case wf.Col >= 0:
+		out = fmt.Sprintf("%s(%d)", wf.Opcode.String(), wf.Col)
+	default:
+		out = wf.Opcode.String()
+	}
+	if wf.Frame != nil && wf.Opcode.RespectsFrame() {
+		out += " " + wf.Frame.String()
+	}
+	return out
+}
+
+func (wf *WindowFunction) typ(fields []*querypb.Field) querypb.Type {
+	if wf.Aggregate != nil {
+		return wf.Aggregate.typ(fields[wf.Aggregate.Col].Type)
+	}
+	if wf.Col >= 0 {
+		return wf.Opcode.SQLType(fields[wf.Col].Type)
+	}
+	return wf.Opcode.SQLType(sqltypes.Null)
+}
+
+// TryExecute is a Primitive function.
+func (w *Window) TryExecute(ctx context.Context, vcursor VCursor, bindVars map[string]*querypb.BindVariable, _ bool) (*sqltypes.Result, error) {
+	result, err := vcursor.ExecutePrimitive(ctx, w.Input, bindVars, true)
+	if err != nil {
+		return nil, err
+	}
+
+	state, err := w.newWindowState(ctx, vcursor, bindVars, result.Fields)
+	if err != nil {
+		return nil, err
+	}
+
+	out := &sqltypes.Result{Fields: w.fields(result.Fields)}
+	start := 0
+	for i := 1; i <= len(result.Rows); i++ {
+		if i < len(result.Rows) {
+			next, err := w.nextPartition(result.Rows[i-1], result.Rows[i])
+			if err != nil {
+				return nil, err
+			}
+			if !next {
+				continue
+			}
+		}
+		rows, err := state.evaluate(result.Rows[start:i])
+		if err != nil {
+			return nil, err
+		}
+		out.Rows = append(out.Rows, rows...)
+		start = i
+	}
+	return out, nil
+}
+
+// TryStreamExecute is a Primitive function.
+func (w *Window) TryStreamExecute(ctx context.Context, vcursor VCursor, bindVars map[string]*querypb.BindVariable, _ bool, callback func(*sqltypes.Result) error) error {
+	var (
+		mu        sync.Mutex
+		state     *windowState
+		partition []sqltypes.Row
+	)
+
+	flush := func() error {
+		if len(partition) == 0 {
+			return nil
+		}
+		rows, err := state.evaluate(partition)
+		if err != nil {
+			return err
+		}
+		partition = nil
+		return callback(&sqltypes.Result{Rows: rows})
+	}
+
+	visitor := func(qr *sqltypes.Result) error {
+		mu.Lock()
+		defer mu.Unlock()
+
+		var err error
+		if state == nil && len(qr.Fields) != 0 {
+			state, err = w.newWindowState(ctx, vcursor, bindVars, qr.Fields)
+			if err != nil {
+				return err
+			}
+			if err = callback(&sqltypes.Result{Fields: w.fields(qr.Fields)}); err != nil {
+				return err
+			}
+		}
+
+		for _, row := range qr.Rows {
+			if len(partition) > 0 {
+				next, err := w.nextPartition(partition[len(partition)-1], row)
+				if err != nil {
+					return err
+				}
+				if next {
+					if err := flush(); err != nil {
+						return err
+					}
+				}
+			}
+			partition = append(partition, row)
+		}
+		if vcursor.ExceedsMaxMemoryRows(len(partition)) {
+			return fmt.Errorf("in-memory row count exceeded allowed limit of %d", vcursor.MaxMemoryRows())
+		}
+		return nil
+	}
+
+	// we need the input fields types to correctly calculate the output types
+	if err := vcursor.StreamExecutePrimitive(ctx, w.Input, bindVars, true, visitor); err != nil {
+		return err
+	}
+	return flush()
+}
+
+// GetFields is a Primitive function.
+func (w *Window) GetFields(ctx context.Context, vcursor VCursor, bindVars map[string]*querypb.BindVariable) (*sqltypes.Result, error) {
+	qr, err := w.Input.GetFields(ctx, vcursor, bindVars)
+	if err != nil {
+		return nil, err
+	}
+	return &sqltypes.Result{Fields: w.fields(qr.Fields)}, nil
+}
+
+// Inputs returns the Primitive input for this window
+func (w *Window) Inputs() ([]Primitive, []map[string]any) {
+	return []Primitive{w.Input}, nil
+}
+
+// NeedsTransaction implements the Primitive interface
+func (w *Window) NeedsTransaction() bool {
+	return w.Input.NeedsTransaction()
+}
+
+func (w *Window) description() PrimitiveDescription {
+	other := map[string]any{
+		"Functions": GenericJoin(w.Functions, windowFunctionToString),
+	}
+	if len(w.PartitionBy) > 0 {
+		other["PartitionBy"] = GenericJoin(w.PartitionBy, groupByParamsToString)
+	}
+	if len(w.OrderBy) > 0 {
+		other["OrderBy"] = GenericJoin(w.OrderBy, orderByParamsToString)
+	}
+	return PrimitiveDescription{
+		OperatorType: "Window",
+		Other:        other,
+	}
+}
+
+func windowFunctionToString(i any) string {
+	return i.(*WindowFunction).String()
+}
+
+func (w *Window) fields(input []*querypb.Field) []*querypb.Field {
+	if input == nil {
+		return nil
+	}
+	fields := make([]*querypb.Field, 0, len(w.Functions)+len(input))
+	for _, f := range w.Functions {
+		fields = append(fields, &querypb.Field{
+			Name: f.Alias,
+			Type: f.typ(input),
+		})
+	}
+	return append(fields, input...)
+}
+
+// nextPartition returns true if the two rows belong to different partitions
+func (w *Window) nextPartition(prev, next sqltypes.Row) (bool, error) {
+	for _, pb := range w.PartitionBy {
+		cmp, err := compareGroupByKey(pb, prev, next)
+		if err != nil || cmp != 0 {
+			return true, err
+		}
+	}
+	return false, nil
+}
+
+func compareGroupByKey(gb *GroupByParams, r1, r2 sqltypes.Row) (int, error) {
+	v1, v2 := r1[gb.KeyCol], r2[gb.KeyCol]
+	if cmp := v1.TinyWeightCmp(v2); cmp != 0 {
+		return cmp, nil
+	}
+	cmp, err := evalengine.NullsafeCompare(v1, v2, gb.CollationEnv, gb.Type.Collation(), gb.Type.Values())
+	if err != nil {
+		_, isCollationErr := err.(evalengine.UnsupportedCollationError)
+		if !isCollationErr || gb.WeightStringCol == -1 {
+			return 0, err
+		}
+		return evalengine.NullsafeCompare(r1[gb.WeightStringCol], r2[gb.WeightStringCol], gb.CollationEnv, gb.Type.Collation(), gb.Type.Values())
+	}
+	return cmp, nil
+}
+
+// windowState holds the per execution state needed to evaluate the window functions
+type windowState struct {
+	w           *Window
+	n           []int64
+	defaults    []sqltypes.Value
+	aggregators []aggregator
+}
+
+func (w *Window) newWindowState(ctx context.Context, vcursor VCursor, bindVars map[string]*querypb.BindVariable, fields []*querypb.Field) (*windowState, error) {
+	state := &windowState{
+		w:           w,
+		n:           make([]int64, len(w.Functions)),
+		defaults:    make([]sqltypes.Value, len(w.Functions)),
+		aggregators: make([]aggregator, len(w.Functions)),
+	}
+	env := evalengine.NewExpressionEnv(ctx, bindVars, vcursor)
+	for i, f := range w.Functions {
+		if f.N != nil {
+			n, err := evaluateRowCount(env, vcursor, f)
+			if err != nil {
+				return nil, err
+			}
+			state.n[i] = n
+		}
+		if f.Default != nil {
+			resolved, err := env.Evaluate(f.Default)
+			if err != nil {
+				return nil, err
+			}
+			state.defaults[i] = resolved.Value(vcursor.ConnCollation())
+		}
+		if f.Aggregate != nil {
+			sourceType := fields[f.Aggregate.Col].Type
+			ag, err := newAggregator(f.Aggregate, sourceType, f.Aggregate.typ(sourceType))
+			if err != nil {
+				return nil, err
+			}
+			state.aggregators[i] = ag
+		}
+	}
+	return state, nil
+}
+
+func evaluateRowCount(env *evalengine.ExpressionEnv, vcursor VCursor, f *WindowFunction) (int64, error) {
+	resolved, err := env.Evaluate(f.N)
+	if err != nil {
+		return 0, err
+	}
+	value := resolved.Value(vcursor.ConnCollation())
+	if !value.IsIntegral() {
+		return 0, sqltypes.ErrIncompatibleTypeCast
+	}
+	n, err := strconv.ParseInt(value.RawStr(), 10, 64)
+	if err != nil || n < 0 || (n == 0 && f.Opcode != opcode.WindowLag && f.Opcode != opcode.WindowLead) {
+		return 0, fmt.Errorf("incorrect arguments to %s: %v", f.Opcode.String(), value.RawStr())
+	}
+	return n, nil
+}
+
+// evaluate calculates the window functions for all rows of a single partition
+func (s *windowState) evaluate(partition []sqltypes.Row) (rows []sqltypes.Row, err error) {
+	defer evalengine.PanicHandler(&err)
+
+	size := len(partition)
+	peerStart, peerEnd, denseRank := s.peers(partition)
+
+	rows = slice.Map(partition, func(row sqltypes.Row) sqltypes.Row {
+		out := make(sqltypes.Row, len(s.w.Functions), len(s.w.Functions)+len(row))
+		return append(out, row...)
+	})
+
+	for idx, f := range s.w.Functions {
+		var frame frameState
+		for i := range partition {
+			var val sqltypes.Value
+			switch f.Opcode {
+			case opcode.WindowRowNumber:
+				val = sqltypes.NewUint64(uint64(i + 1))
+			case opcode.WindowRank:
+				val = sqltypes.NewUint64(uint64(peerStart[i] + 1))
+			case opcode.WindowDenseRank:
+				val = sqltypes.NewUint64(uint64(denseRank[i]))
+			case opcode.WindowPercentRank:
+				var pr float64
+				if size > 1 {
+					pr = float64(peerStart[i]) / float64(size-1)
+				}
+				val = sqltypes.NewFloat64(pr)
+			case opcode.WindowCumeDist:
+				val = sqltypes.NewFloat64(float64(peerEnd[i]+1) / float64(size))
+			case opcode.WindowNtile:
+				val = sqltypes.NewUint64(ntile(s.n[idx], i, size))
+			case opcode.WindowLag, opcode.WindowLead:
+				target := int64(i) - s.n[idx]
+				if f.Opcode == opcode.WindowLead {
+					// N is compared with the size of the partition first, since i + N could overflow
+					target = int64(size)
+					if s.n[idx] < int64(size) {
+						target = int64(i) + s.n[idx]
+					}
+				}
+				if target >= 0 && target < int64(size) {
+					val = partition[target][f.Col]
+				} else {
+					val = s.defaults[idx]
+				}
+			case opcode.WindowFirstValue, opcode.WindowLastValue, opcode.WindowNthValue:
+				lo, hi := f.Frame.bounds(i, size, peerStart, peerEnd)
+				target := hi + 1
+				switch f.Opcode {
+				case opcode.WindowFirstValue:
+					target = lo
+				case opcode.WindowLastValue:
+					target = hi
+				default:
+					// N is compared with the size of the frame before it is converted, since a large N
+					// would overflow the offset of the row
+					if s.n[idx] <= int64(hi-lo+1) {
+						target = lo + int(s.n[idx]) - 1
+					}
+				}
+				if lo <= hi && target <= hi {
+					val = partition[target][f.Col]
+				}
+			case opcode.WindowAggregate:
+				lo, hi := f.Frame.bounds(i, size, peerStart, peerEnd)
+				val, err = frame.aggregate(s.aggregators[idx], partition, lo, hi)
+				if err != nil {
+					return nil, err
+				}
+			default:
+				return nil, fmt.Errorf("BUG: unexpected window function %s", f.Opcode.String())
+			}
+			rows[i][idx] = val
+		}
+	}
+	return rows, nil
+}
+
+// peers returns, for every row of the partition, the first and last row that are equal to it according to
+// the ORDER BY of the window, and the dense rank of the row
+func (s *windowState) peers(partition []sqltypes.Row) (peerStart, peerEnd, denseRank []int) {
+	size := len(partition)
+	peerStart = make([]int, size)
+	peerEnd = make([]int, size)
+	denseRank = make([]int, size)
+
+	start, rank := 0, 1
+	for i := 1; i <= size; i++ {
+		if i < size && s.w.OrderBy.Compare(partition[i-1], partition[i]) == 0 {
+			continue
+		}
+		for j := start; j < i; j++ {
+			peerStart[j] = start
+			peerEnd[j] = i - 1
+			denseRank[j] = rank
+		}
+		start = i
+		rank++
+	}
+	return
+}
+
+// ntile returns the bucket the row at offset i of a partition of the given size falls into
+func ntile(buckets int64, i, size int) uint64 {
+	n := int64(size)
+	if buckets > n {
+		buckets = n
+	}
+	perBucket := n / buckets
+	remainder := n % buckets
+	row := int64(i)
+	// the first `remainder` buckets get one extra row
+	if row < remainder*(perBucket+1) {
+		return uint64(row/(perBucket+1) + 1)
+	}
+	return uint64(remainder + (row-remainder*(perBucket+1))/perBucket + 1)
+}
+
+// bounds returns the first and last offset of the frame for the row at offset i.
+// The frame is empty when lo > hi.
+func (wf *WindowFrame) bounds(i, size int, peerStart, peerEnd []int) (lo, hi int) {
+	if wf.Range {
+		lo, hi = 0, size-1
+		if wf.Start == 0 {
+			lo = peerStart[i]
+		}
+		if wf.End == 0 {
+			hi = peerEnd[i]
+		}
+		return lo, hi
+	}
+	lo, hi = 0, size-1
+	if wf.Start != math.MinInt64 {
+		lo = int(min(max(int64(i)+wf.Start, 0), int64(size)))
+	}
+	if wf.End != math.MaxInt64 {
+		hi = int(min(max(int64(i)+wf.End, -1), int64(size-1)))
+	}
+	return lo, hi
+}
+
+// frameState keeps track of the rows added to an aggregator, so that growing frames
+// can be evaluated incrementally instead of being recalculated for every row
+type frameState struct {
+	lo, hi int
+	init   bool
+}
+
+func (fs *frameState) aggregate(ag aggregator, partition []sqltypes.Row, lo, hi int) (sqltypes.Value, error) {
+	from := lo
+	if !fs.init || lo != fs.lo || hi < fs.hi {
+		ag.reset()
+		fs.init = true
+	} else {
+		from = max(fs.hi+1, lo)
+	}
+	fs.lo, fs.hi = lo, hi
+
+	for j := from; j <= hi; j++ {
+		if err := ag.add(partition[j]); err != nil {
+			return sqltypes.NULL, err
+		}
+	}
+	return ag.finish(), nil
+}
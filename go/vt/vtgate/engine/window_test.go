@@ -0,0 +1,205 @@
+/*
+Copyright 2024 The Vitess Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package engine
+
+import (
+	"context"
+	"math"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"vitess.io/vitess/go/mysql/collations"
+	"vitess.io/vitess/go/sqltypes"
+	"vitess.io/vitess/go/vt/vtgate/engine/opcode"
+	"vitess.io/vitess/go/vt/vtgate/evalengine"
+)
+
+func windowTestInput() *fakePrimitive {
+	fields := sqltypes.MakeTestFields(
+		"grp|val",
+		"int64|int64",
+	)
+	fp := &fakePrimitive{
+		results: []*sqltypes.Result{sqltypes.MakeTestResult(
+			fields,
+			"1|10",
+			"1|20",
+			"1|20",
+			"1|30",
+			"2|5",
+		)},
+	}
+	return fp
+}
+
+func TestWindowRanking(t *testing.T) {
+	fp := windowTestInput()
+	w := &Window{
+		PartitionBy: []*GroupByParams{{KeyCol: 0, WeightStringCol: -1, CollationEnv: collations.MySQL8()}},
+		OrderBy:     evalengine.Comparison{{Col: 1, WeightStringCol: -1, CollationEnv: collations.MySQL8()}},
+		Functions: []*WindowFunction{
+			{Opcode: opcode.WindowRowNumber, Col: -1, Alias: "rn"},
+			{Opcode: opcode.WindowRank, Col: -1, Alias: "r"},
+			{Opcode: opcode.WindowDenseRank, Col: -1, Alias: "dr"},
+			{Opcode: opcode.WindowNtile, Col: -1, N: evalengine.NewLiteralInt(3), Alias: "nt"},
+			{Opcode: opcode.WindowLag, Col: 1, N: evalengine.NewLiteralInt(1), Default: evalengine.NewLiteralInt(0), Alias: "lg"},
+		},
+		Input: fp,
+	}
+
+	result, err := w.TryExecute(context.Background(), &noopVCursor{}, nil, true)
+	require.NoError(t, err)
+
+	want := sqltypes.MakeTestResult(
+		sqltypes.MakeTestFields("rn|r|dr|nt|lg|grp|val", "uint64|uint64|uint64|uint64|int64|int64|int64"),
+		"1|1|1|1|0|1|10",
+		"2|2|2|1|10|1|20",
+		"3|2|2|2|20|1|20",
+		"4|4|3|3|20|1|30",
+		"1|1|1|1|0|2|5",
+	)
+	assert.Equal(t, want.Rows, result.Rows)
+	assert.Equal(t, "rn", result.Fields[0].Name)
+	assert.Equal(t, sqltypes.Uint64, result.Fields[0].Type)
+}
+
+func TestWindowAggregateFrames(t *testing.T) {
+	sum := NewAggregateParam(opcode.AggregateSum, 1, "", collations.MySQL8())
+	tcases := []struct {
+		name  string
+		frame *WindowFrame
+		want  []string
+	}{{
+		name:  "default frame with order by",
+		frame: &WindowFrame{Range: true, Start: math.MinInt64, End: 0},
+		want:  []string{"10", "50", "50", "80", "5"},
+	}, {
+		name:  "whole partition",
+		frame: &WindowFrame{Range: true, Start: math.MinInt64, End: math.MaxInt64},
+		want:  []string{"80", "80", "80", "80", "5"},
+	}, {
+		name:  "sliding rows",
+		frame: &WindowFrame{Start: -1, End: 1},
+		want:  []string{"30", "50", "70", "50", "5"},
+	}, {
+		name:  "empty frame",
+		frame: &WindowFrame{Start: math.MinInt64, End: -1},
+		want:  []string{"NULL", "10", "30", "50", "NULL"},
+	}}
+
+	for _, tc := range tcases {
+		t.Run(tc.name, func(t *testing.T) {
+			fp := windowTestInput()
+			w := &Window{
+				PartitionBy: []*GroupByParams{{KeyCol: 0, WeightStringCol: -1, CollationEnv: collations.MySQL8()}},
+				OrderBy:     evalengine.Comparison{{Col: 1, WeightStringCol: -1, CollationEnv: collations.MySQL8()}},
+				Functions: []*WindowFunction{
+					{Opcode: opcode.WindowAggregate, Col: 1, Aggregate: sum, Frame: tc.frame},
+				},
+				Input: fp,
+			}
+
+			var got []string
+			err := w.TryStreamExecute(context.Background(), &noopVCursor{}, nil, true, func(qr *sqltypes.Result) error {
+				for _, row := range qr.Rows {
+					if row[0].IsNull() {
+						got = append(got, "NULL")
+						continue
+					}
+					got = append(got, row[0].ToString())
+				}
+				return nil
+			})
+			require.NoError(t, err)
+			assert.Equal(t, tc.want, got)
+		})
+	}
+}
+
+func TestWindowFrameValues(t *testing.T) {
+	fp := windowTestInput()
+	frame := &WindowFrame{Range: true, Start: math.MinInt64, End: math.MaxInt64}
+	w := &Window{
+		PartitionBy: []*GroupByParams{{KeyCol: 0, WeightStringCol: -1, CollationEnv: collations.MySQL8()}},
+		OrderBy:     evalengine.Comparison{{Col: 1, WeightStringCol: -1, CollationEnv: collations.MySQL8()}},
+		Functions: []*WindowFunction{
+			{Opcode: opcode.WindowFirstValue, Col: 1, Frame: frame},
+			{Opcode: opcode.WindowLastValue, Col: 1, Frame: frame},
+			{Opcode: opcode.WindowNthValue, Col: 1, N: evalengine.NewLiteralInt(2), Frame: frame},
+			{Opcode: opcode.WindowCumeDist, Col: -1},
+			{Opcode: opcode.WindowPercentRank, Col: -1},
+		},
+		Input: fp,
+	}
+
+	result, err := w.TryExecute(context.Background(), &noopVCursor{}, nil, true)
+	require.NoError(t, err)
+
+	want := sqltypes.MakeTestResult(
+		sqltypes.MakeTestFields("a|b|c|d|e|grp|val", "int64|int64|int64|float64|float64|int64|int64"),
+		"10|30|20|0.25|0|1|10",
+		"10|30|20|0.75|0.3333333333333333|1|20",
+		"10|30|20|0.75|0.3333333333333333|1|20",
+		"10|30|20|1|1|1|30",
+		"5|5|NULL|1|0|2|5",
+	)
+	assert.Equal(t, want.Rows, result.Rows)
+}
+
+func TestWindowLargeN(t *testing.T) {
+	fp := windowTestInput()
+	frame := &WindowFrame{Range: true, Start: math.MinInt64, End: math.MaxInt64}
+	w := &Window{
+		PartitionBy: []*GroupByParams{{KeyCol: 0, WeightStringCol: -1, CollationEnv: collations.MySQL8()}},
+		OrderBy:     evalengine.Comparison{{Col: 1, WeightStringCol: -1, CollationEnv: collations.MySQL8()}},
+		Functions: []*WindowFunction{
+			{Opcode: opcode.WindowNthValue, Col: 1, N: evalengine.NewLiteralInt(math.MaxInt64), Frame: frame},
+			{Opcode: opcode.WindowLead, Col: 1, N: evalengine.NewLiteralInt(math.MaxInt64), Default: evalengine.NewLiteralInt(0)},
+			{Opcode: opcode.WindowLag, Col: 1, N: evalengine.NewLiteralInt(math.MaxInt64), Default: evalengine.NewLiteralInt(0)},
+		},
+		Input: fp,
+	}
+
+	result, err := w.TryExecute(context.Background(), &noopVCursor{}, nil, true)
+	require.NoError(t, err)
+
+	want := sqltypes.MakeTestResult(
+		sqltypes.MakeTestFields("a|b|c|grp|val", "int64|int64|int64|int64|int64"),
+		"NULL|0|0|1|10",
+		"NULL|0|0|1|20",
+		"NULL|0|0|1|20",
+		"NULL|0|0|1|30",
+		"NULL|0|0|2|5",
+	)
+	assert.Equal(t, want.Rows, result.Rows)
+}
+
+func TestWindowDescription(t *testing.T) {
+	w := &Window{
+		PartitionBy: []*GroupByParams{{KeyCol: 0, WeightStringCol: -1}},
+		Functions: []*WindowFunction{
+			{Opcode: opcode.WindowRowNumber, Col: -1},
+			{Opcode: opcode.WindowFirstValue, Col: 1, Frame: &WindowFrame{Start: -2, End: math.MaxInt64}},
+		},
+	}
+	desc := w.description()
+	assert.Equal(t, "Window", desc.OperatorType)
+	assert.Equal(t, "row_number, first_value(1) ROWS BETWEEN 2 PRECEDING AND UNBOUNDED FOLLOWING", desc.Other["Functions"])
+	assert.Equal(t, "0", desc.Other["PartitionBy"])
+}
@@ -113,6 +113,20 @@ type (
 		AllowScatter        bool
 		WarmingReadsPercent int
 		QueryLogToFile      string
+
+		// PlanCacheSnapshot is where the hot set of the plan cache is persisted to warm it
+		// up on startup: a local file, or "topo:<name>" to store it in the topo of the cell.
+		PlanCacheSnapshot         string
+		PlanCacheSnapshotInterval time.Duration
+		PlanCacheSnapshotSize     int
+
+		// ResultCacheSize is the memory budget of the cache of query results, in bytes.
+		// The cache is disabled when it is zero.
+		ResultCacheSize int64
+		// ResultCacheTTL is how long the results of queries that only read from
+		// ResultCacheTables are cached for.
+		ResultCacheTTL    time.Duration
+		ResultCacheTables []string
 	}
 
 	Executor struct {
@@ -134,6 +148,12 @@ type (
 		plans *PlanCache
 		epoch atomic.Uint32
 
+		// planSnapshots persists the hot set of the plan cache, if enabled.
+		planSnapshots *planCacheSnapshotter
+
+		// resultCache holds the results of read-only queries, if enabled.
+		resultCache *resultCache
+
 		vm            *VSchemaManager
 		schemaTracker SchemaInfo
 
@@ -195,12 +215,19 @@ func NewExecutor(
 		warmingReadsChannel: make(chan bool, warmingReadsConcurrency),
 		ddlConfig:           ddlConfig,
 	}
+	if eConfig.ResultCacheSize > 0 {
+		e.resultCache = newResultCache(eConfig.ResultCacheSize)
+	}
 	// setting the vcursor config.
 	e.initVConfig(warnOnShardedOnly, pv)
 	e.metrics = &Metrics{
 		engineMetrics: engine.InitMetrics(e.exporter),
 	}
 
+	if eConfig.PlanCacheSnapshot != "" {
+		e.setupPlanCacheSnapshots(ctx)
+	}
+
 	// we subscribe to update from the VSchemaManager
 	e.vm = &VSchemaManager{
 		subscriber: e.SaveVSchema,
@@ -1082,6 +1109,9 @@ func (e *Executor) SaveVSchema(vschema *vindexes.VSchema, stats *VSchemaStats) {
 	}
 	e.vschemaStats = stats
 	e.ClearPlans()
+	if e.planSnapshots != nil {
+		e.planSnapshots.vschemaUpdated(e.vschema)
+	}
 
 	if vschemaCounters != nil {
 		vschemaCounters.Add("Reload", 1)
@@ -1112,6 +1142,9 @@ func (e *Executor) fetchOrCreatePlan(
 	isExecutePath bool, // this means we are trying to execute the query - this is not a PREPARE call
 ) (
 	plan *engine.Plan, vcursor *econtext.VCursorImpl, stmt sqlparser.Statement, err error) {
+	span, ctx := trace.NewSpan(ctx, "executor.fetchOrCreatePlan")
+	defer span.Finish()
+
 	if e.VSchema() == nil {
 		return nil, nil, nil, vterrors.VT13001("vschema not initialized")
 	}
@@ -1119,6 +1152,15 @@ func (e *Executor) fetchOrCreatePlan(
 	query, comments := sqlparser.SplitMarginComments(queryString)
 	vcursor, _ = e.newVCursor(safeSession, comments, logStats)
 
+	attributes := queryAttributesFromContext(ctx)
+	logStats.QueryAttributes = attributes
+	attributeValues := queryAttributeValues(attributes)
+	if target, ok := attributeValues[QueryAttributeTarget]; ok {
+		if err := vcursor.SetQueryTarget(target); err != nil {
+			return nil, nil, nil, err
+		}
+	}
+
 	var setVarComment string
 	if e.vConfig.SetVarEnabled {
 		setVarComment = vcursor.PrepareSetVarComment()
@@ -1154,11 +1196,16 @@ func (e *Executor) fetchOrCreatePlan(
 		}
 	}
 
-	// Apply query hints
-	e.applyQueryHints(vcursor, plan)
+	// Apply query hints, and the ones of the query attributes
+	qh := plan.QueryHints
+	if err := qh.ApplyQueryAttributes(plan.QueryType, attributeValues); err != nil {
+		return nil, nil, stmt, err
+	}
+	e.applyQueryHints(vcursor, qh)
 
 	logStats.SQL = comments.Leading + plan.Original + comments.Trailing
 	logStats.BindVariables = sqltypes.CopyBindVariables(bindVars)
+	span.Annotate("cached_plan", logStats.CachedPlan)
 
 	return plan, vcursor, stmt, nil
 }
@@ -1205,8 +1252,7 @@ func shouldOptimizePlan(preparedPlan, isExecutePath bool, plan *engine.Plan) boo
 }
 
 // applyQueryHints applies query hints to the vcursor
-func (e *Executor) applyQueryHints(vcursor *econtext.VCursorImpl, plan *engine.Plan) {
-	qh := plan.QueryHints
+func (e *Executor) applyQueryHints(vcursor *econtext.VCursorImpl, qh sqlparser.QueryHints) {
 	vcursor.SetIgnoreMaxMemoryRows(qh.IgnoreMaxMemoryRows)
 	vcursor.SetConsolidator(qh.Consolidator)
 	vcursor.SetWorkloadName(qh.Workload)
@@ -1228,7 +1274,21 @@ func (e *Executor) getCachedOrBuildPlan(
 	if err != nil {
 		return nil, false, nil, err
 	}
+	return e.getCachedOrBuildPlanForStmt(ctx, vcursor, query, stmt, reservedVars, bindVars, setVarComment, parameterize, planKey, ignoreCache)
+}
 
+func (e *Executor) getCachedOrBuildPlanForStmt(
+	ctx context.Context,
+	vcursor *econtext.VCursorImpl,
+	query string,
+	stmt sqlparser.Statement,
+	reservedVars *sqlparser.ReservedVars,
+	bindVars map[string]*querypb.BindVariable,
+	setVarComment string,
+	parameterize bool,
+	planKey engine.PlanKey,
+	ignoreCache bool,
+) (plan *engine.Plan, cached bool, _ sqlparser.Statement, err error) {
 	defer func() {
 		if err == nil {
 			vcursor.CheckForReservedConnection(setVarComment, stmt)
@@ -1283,9 +1343,13 @@ func (e *Executor) getCachedOrBuildPlan(
 			// build Plan key
 			planKey = buildPlanKey(ctx, vcursor, query, setVarComment)
 		}
-		plan, cached, err = e.plans.GetOrLoad(planKey.Hash(), e.epoch.Load(), func() (*engine.Plan, error) {
+		hash := planKey.Hash()
+		plan, cached, err = e.plans.GetOrLoad(hash, e.epoch.Load(), func() (*engine.Plan, error) {
 			return e.buildStatement(ctx, vcursor, query, stmt, reservedVars, bindVarNeeds, qh, paramsCount)
 		})
+		if err == nil && !cached && e.planSnapshots != nil {
+			e.planSnapshots.planCached(hash, planKey, preparedPlan)
+		}
 		return plan, cached, stmt, err
 	}
 	plan, err = e.buildStatement(ctx, vcursor, query, stmt, reservedVars, bindVarNeeds, qh, paramsCount)
@@ -1351,6 +1415,17 @@ func (e *Executor) buildStatement(
 	plan.Warnings = vcursor.GetAndEmptyWarnings()
 	plan.QueryHints = qh
 
+	// A prepared plan wrapped in a result cache is not replaced by an optimized plan,
+	// since most of its executions will not run it anyway.
+	if ttl := e.resultCacheTTL(stmt, plan, qh, bindVarNeeds); ttl > 0 {
+		plan.Instructions = &engine.ResultCache{
+			Query:  query,
+			Tables: plan.TablesUsed,
+			TTL:    ttl,
+			Input:  plan.Instructions,
+		}
+	}
+
 	err = e.checkThatPlanIsValid(stmt, plan)
 	return plan, err
 }
@@ -1410,6 +1485,24 @@ func (e *Executor) ClearPlans() {
 	e.epoch.Add(1)
 }
 
+// setupPlanCacheSnapshots loads the last snapshot of the plan cache, to warm it up once
+// the vschema is known, and starts taking periodic snapshots.
+func (e *Executor) setupPlanCacheSnapshots(ctx context.Context) {
+	ts, err := e.serv.GetTopoServer()
+	if err != nil {
+		log.Errorf("Plan cache snapshots are disabled: %v", err)
+		return
+	}
+	store := newPlanCacheStore(e.config.PlanCacheSnapshot, ts, e.cell)
+	e.planSnapshots = newPlanCacheSnapshotter(e, store, e.config.PlanCacheSnapshotSize)
+	if err := e.planSnapshots.load(ctx); err != nil {
+		log.Errorf("Failed to load the plan cache snapshot: %v", err)
+	}
+	if e.config.PlanCacheSnapshotInterval > 0 {
+		go e.planSnapshots.run(ctx, e.config.PlanCacheSnapshotInterval)
+	}
+}
+
 func (e *Executor) updateQueryStats(queryType, planType, tabletType string, shards int64, tables []string) {
 	queryExecutions.Add([]string{queryType, planType, tabletType}, 1)
 	queryRoutes.Add([]string{queryType, planType, tabletType}, shards)
@@ -1547,6 +1640,9 @@ func (e *Executor) initVConfig(warnOnShardedOnly bool, pv plancontext.PlannerVer
 		WarmingReadsTimeout: warmingReadsQueryTimeout,
 		WarmingReadsChannel: e.warmingReadsChannel,
 	}
+	if e.resultCache != nil {
+		e.vConfig.ResultCache = e.resultCache
+	}
 }
 
 func countArguments(statement sqlparser.Statement) (paramsCount uint16) {
@@ -1780,6 +1876,9 @@ func (e *Executor) PlanPrepareStmt(ctx context.Context, safeSession *econtext.Sa
 
 func (e *Executor) Close() {
 	e.scatterConn.Close()
+	if e.planSnapshots != nil {
+		e.planSnapshots.close(context.Background())
+	}
 	topo, err := e.serv.GetTopoServer()
 	if err != nil {
 		panic(err)
@@ -19,6 +19,7 @@ package executorcontext
 import (
 	"context"
 	"fmt"
+	"reflect"
 	"sort"
 	"strings"
 	"sync"
@@ -33,6 +34,7 @@ import (
 	"vitess.io/vitess/go/mysql/sqlerror"
 	"vitess.io/vitess/go/protoutil"
 	"vitess.io/vitess/go/sqltypes"
+	"vitess.io/vitess/go/trace"
 	"vitess.io/vitess/go/vt/callerid"
 	"vitess.io/vitess/go/vt/discovery"
 	"vitess.io/vitess/go/vt/key"
@@ -91,6 +93,9 @@ type (
 		WarmingReadsPercent int
 		WarmingReadsTimeout time.Duration
 		WarmingReadsChannel chan bool
+
+		// ResultCache is the cache of query results, if enabled.
+		ResultCache engine.CachedResults
 	}
 
 	// vcursor_impl needs these facilities to be able to be able to execute queries for vindexes
@@ -708,6 +713,9 @@ func (vc *VCursorImpl) TargetString() string {
 const MaxBufferingRetries = 3
 
 func (vc *VCursorImpl) ExecutePrimitive(ctx context.Context, primitive engine.Primitive, bindVars map[string]*querypb.BindVariable, wantfields bool) (*sqltypes.Result, error) {
+	span, ctx := startPrimitiveSpan(ctx, primitive)
+	defer span.Finish()
+
 	for try := 0; try < MaxBufferingRetries; try++ {
 		res, err := primitive.TryExecute(ctx, vc, bindVars, wantfields)
 		if err != nil && vterrors.RootCause(err) == buffer.ShardMissingError {
@@ -719,6 +727,11 @@ func (vc *VCursorImpl) ExecutePrimitive(ctx context.Context, primitive engine.Pr
 	return nil, vterrors.New(vtrpcpb.Code_UNAVAILABLE, "upstream shards are not available")
 }
 
+// startPrimitiveSpan starts the span of the execution of a primitive, named after its type.
+func startPrimitiveSpan(ctx context.Context, primitive engine.Primitive) (trace.Span, context.Context) {
+	return trace.NewSpan(ctx, "Primitive."+reflect.Indirect(reflect.ValueOf(primitive)).Type().Name())
+}
+
 func (vc *VCursorImpl) logOpTraffic(primitive engine.Primitive, res *sqltypes.Result) {
 	if vc.interOpStats == nil {
 		return
@@ -746,6 +759,9 @@ func (vc *VCursorImpl) logShardsQueried(primitive engine.Primitive, shardsNb int
 }
 
 func (vc *VCursorImpl) ExecutePrimitiveStandalone(ctx context.Context, primitive engine.Primitive, bindVars map[string]*querypb.BindVariable, wantfields bool) (*sqltypes.Result, error) {
+	span, ctx := startPrimitiveSpan(ctx, primitive)
+	defer span.Finish()
+
 	// clone the VCursorImpl with a new session.
 	newVC := vc.cloneWithAutocommitSession()
 	for try := 0; try < MaxBufferingRetries; try++ {
@@ -779,6 +795,9 @@ func (vc *VCursorImpl) wrapCallback(callback func(*sqltypes.Result) error, primi
 }
 
 func (vc *VCursorImpl) StreamExecutePrimitive(ctx context.Context, primitive engine.Primitive, bindVars map[string]*querypb.BindVariable, wantfields bool, callback func(*sqltypes.Result) error) error {
+	span, ctx := startPrimitiveSpan(ctx, primitive)
+	defer span.Finish()
+
 	callback = vc.wrapCallback(callback, primitive)
 
 	for try := 0; try < MaxBufferingRetries; try++ {
@@ -792,6 +811,9 @@ func (vc *VCursorImpl) StreamExecutePrimitive(ctx context.Context, primitive eng
 }
 
 func (vc *VCursorImpl) StreamExecutePrimitiveStandalone(ctx context.Context, primitive engine.Primitive, bindVars map[string]*querypb.BindVariable, wantfields bool, callback func(result *sqltypes.Result) error) error {
+	span, ctx := startPrimitiveSpan(ctx, primitive)
+	defer span.Finish()
+
 	callback = vc.wrapCallback(callback, primitive)
 
 	// clone the VCursorImpl with a new session.
@@ -1040,6 +1062,26 @@ func (vc *VCursorImpl) SetTarget(target string) error {
 	return nil
 }
 
+// SetQueryTarget routes the current query to the target, as a USE statement
+// would, without changing the target of the session.
+func (vc *VCursorImpl) SetQueryTarget(target string) error {
+	keyspace, tabletType, destination, err := ParseDestinationTarget(target, vc.config.DefaultTabletType, vc.vschema)
+	if err != nil {
+		return err
+	}
+	if _, ok := vc.vschema.Keyspaces[keyspace]; !ignoreKeyspace(keyspace) && !ok {
+		return vterrors.VT05003(keyspace)
+	}
+
+	if vc.SafeSession.InTransaction() && tabletType != topodatapb.TabletType_PRIMARY {
+		return vterrors.NewErrorf(vtrpcpb.Code_INVALID_ARGUMENT, vterrors.LockOrActiveTransaction, "can't execute the given command because you have an active transaction")
+	}
+	vc.keyspace = keyspace
+	vc.tabletType = tabletType
+	vc.destination = destination
+	return nil
+}
+
 func ignoreKeyspace(keyspace string) bool {
 	return keyspace == "" || sqlparser.SystemSchema(keyspace)
 }
@@ -1642,6 +1684,21 @@ func (vc *VCursorImpl) RecordMirrorStats(sourceExecTime, targetExecTime time.Dur
 	vc.logStats.MirrorTargetError = targetErr
 }
 
+// GetCachedResults implements the VCursor interface. Only the results of reads from
+// replicas outside of transactions are cached.
+func (vc *VCursorImpl) GetCachedResults() engine.CachedResults {
+	if vc.config.ResultCache == nil {
+		return nil
+	}
+	if vc.tabletType != topodatapb.TabletType_REPLICA && vc.tabletType != topodatapb.TabletType_RDONLY {
+		return nil
+	}
+	if vc.SafeSession.InTransaction() || vc.SafeSession.InReservedConn() {
+		return nil
+	}
+	return vc.config.ResultCache
+}
+
 func (vc *VCursorImpl) GetMarginComments() sqlparser.MarginComments {
 	return vc.marginComments
 }
@@ -205,6 +205,11 @@ func (conn *FakeVTGateConn) VStream(ctx context.Context, tabletType topodatapb.T
 	return nil, fmt.Errorf("NYI")
 }
 
+// VStreamAck acknowledges the position of a VStream subscription.
+func (conn *FakeVTGateConn) VStreamAck(ctx context.Context, subscription string, vgtid *binlogdatapb.VGtid, timestamp int64) error {
+	return fmt.Errorf("NYI")
+}
+
 // Close please see vtgateconn.Impl.Close
 func (conn *FakeVTGateConn) Close() {
 }
@@ -51,6 +51,7 @@ func init() {
 
 	for _, cmd := range []string{
 		"vtbench",
+		"vtcdc",
 		"vtclient",
 		"vtcombo",
 		"vtctl",
@@ -335,6 +336,19 @@ func (conn *vtgateConn) VStream(ctx context.Context, tabletType topodatapb.Table
 	}, nil
 }
 
+func (conn *vtgateConn) VStreamAck(ctx context.Context, subscription string, vgtid *binlogdatapb.VGtid, timestamp int64) error {
+	req := &vtgatepb.VStreamAckRequest{
+		CallerId:     callerid.EffectiveCallerIDFromContext(ctx),
+		Subscription: subscription,
+		Vgtid:        vgtid,
+		Timestamp:    timestamp,
+	}
+	if _, err := conn.c.VStreamAck(ctx, req); err != nil {
+		return vterrors.FromGRPC(err)
+	}
+	return nil
+}
+
 func (conn *vtgateConn) Close() {
 	conn.cc.Close()
 }
@@ -283,6 +283,21 @@ func (f *fakeVTGateService) VStream(ctx context.Context, tabletType topodatapb.T
 	panic("unimplemented")
 }
 
+// VStreamAck is part of the VTGateService interface
+func (f *fakeVTGateService) VStreamAck(ctx context.Context, subscription string, vgtid *binlogdatapb.VGtid, timestamp int64) error {
+	if f.hasError {
+		return errTestVtGateError
+	}
+	if f.panics {
+		panic(fmt.Errorf("test forced panic"))
+	}
+	f.checkCallerID(ctx, "VStreamAck")
+	if subscription != ackSubscription || !proto.Equal(vgtid, ackVgtid) || timestamp != ackTimestamp {
+		f.t.Errorf("VStreamAck: %s %v %d, want %s %v %d", subscription, vgtid, timestamp, ackSubscription, ackVgtid, ackTimestamp)
+	}
+	return nil
+}
+
 // CreateFakeServer returns the fake server for the tests
 func CreateFakeServer(t *testing.T) vtgateservice.VTGateService {
 	return &fakeVTGateService{
@@ -327,6 +342,7 @@ func RunTests(t *testing.T, impl vtgateconn.Impl, fakeServer vtgateservice.VTGat
 	testStreamExecuteMulti(t, session)
 	testExecuteBatch(t, session)
 	testPrepare(t, session)
+	testVStreamAck(t, conn)
 
 	// force a panic at every call, then test that works
 	fs.panics = true
@@ -336,6 +352,7 @@ func RunTests(t *testing.T, impl vtgateconn.Impl, fakeServer vtgateservice.VTGat
 	testStreamExecutePanic(t, session)
 	testStreamExecuteMultiPanic(t, session)
 	testPreparePanic(t, session)
+	testVStreamAckPanic(t, conn)
 	fs.panics = false
 }
 
@@ -355,6 +372,7 @@ func RunErrorTests(t *testing.T, fakeServer vtgateservice.VTGateService) {
 	testExecuteBatchError(t, session, fs)
 	testStreamExecuteError(t, session, fs)
 	testPrepareError(t, session, fs)
+	testVStreamAckError(t, conn)
 	fs.hasError = false
 }
 
@@ -668,6 +686,31 @@ func testPreparePanic(t *testing.T, session *vtgateconn.VTGateSession) {
 	expectPanic(t, err)
 }
 
+const (
+	ackSubscription = "orders"
+	ackTimestamp    = int64(1700000000)
+)
+
+var ackVgtid = &binlogdatapb.VGtid{ShardGtids: []*binlogdatapb.ShardGtid{{Keyspace: "ks", Shard: "-80", Gtid: "MySQL56/a:1-5"}}}
+
+func testVStreamAck(t *testing.T, conn *vtgateconn.VTGateConn) {
+	ctx := newContext()
+	err := conn.VStreamAck(ctx, ackSubscription, ackVgtid, ackTimestamp)
+	require.NoError(t, err)
+}
+
+func testVStreamAckError(t *testing.T, conn *vtgateconn.VTGateConn) {
+	ctx := newContext()
+	err := conn.VStreamAck(ctx, ackSubscription, ackVgtid, ackTimestamp)
+	verifyError(t, err, "VStreamAck")
+}
+
+func testVStreamAckPanic(t *testing.T, conn *vtgateconn.VTGateConn) {
+	ctx := newContext()
+	err := conn.VStreamAck(ctx, ackSubscription, ackVgtid, ackTimestamp)
+	expectPanic(t, err)
+}
+
 var testCallerID = &vtrpcpb.CallerID{
 	Principal:    "test_principal",
 	Component:    "test_component",
@@ -334,6 +334,16 @@ func (vtg *VTGate) VStream(request *vtgatepb.VStreamRequest, stream vtgateservic
 	return vterrors.ToGRPC(vtgErr)
 }
 
+// VStreamAck is the RPC version of vtgateservice.VTGateService method
+func (vtg *VTGate) VStreamAck(ctx context.Context, request *vtgatepb.VStreamAckRequest) (response *vtgatepb.VStreamAckResponse, err error) {
+	defer vtg.server.HandlePanic(&err)
+	ctx = withCallerIDContext(ctx, request.CallerId)
+	if err := vtg.server.VStreamAck(ctx, request.Subscription, request.Vgtid, request.Timestamp); err != nil {
+		return nil, vterrors.ToGRPC(err)
+	}
+	return &vtgatepb.VStreamAckResponse{}, nil
+}
+
 func init() {
 	vtgate.RegisterVTGates = append(vtgate.RegisterVTGates, func(vtGate vtgateservice.VTGateService) {
 		if servenv.GRPCCheckServiceMap("vtgateservice") {
@@ -0,0 +1,488 @@
+/*
+Copyright 2025 The Vitess Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package vtgate
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"os"
+	"path"
+	"path/filepath"
+	"regexp"
+	"slices"
+	"strconv"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"vitess.io/vitess/go/mysql/collations"
+	"vitess.io/vitess/go/stats"
+	"vitess.io/vitess/go/streamlog"
+	"vitess.io/vitess/go/vt/log"
+	"vitess.io/vitess/go/vt/sqlparser"
+	"vitess.io/vitess/go/vt/topo"
+	"vitess.io/vitess/go/vt/topo/topoproto"
+	"vitess.io/vitess/go/vt/vtgate/engine"
+	econtext "vitess.io/vitess/go/vt/vtgate/executorcontext"
+	"vitess.io/vitess/go/vt/vtgate/logstats"
+	"vitess.io/vitess/go/vt/vtgate/vindexes"
+
+	querypb "vitess.io/vitess/go/vt/proto/query"
+	topodatapb "vitess.io/vitess/go/vt/proto/topodata"
+	vtgatepb "vitess.io/vitess/go/vt/proto/vtgate"
+)
+
+// The plan cache snapshot persists the hot set of the plan cache, so that a restarted
+// vtgate does not have to plan all of its queries from scratch while it is serving traffic.
+//
+// The plans themselves are not persisted: the snapshot only holds the keys of the most
+// executed plans, together with a fingerprint of the vschema they were planned against.
+// On startup, the snapshot is loaded and its queries are planned again in the background
+// as soon as the executor receives a vschema with the same fingerprint. If no such vschema
+// shows up before the next snapshot is taken, the snapshot is considered stale and dropped.
+
+const (
+	// planCacheSnapshotTopoPrefix selects the topo server as the snapshot storage.
+	planCacheSnapshotTopoPrefix = "topo:"
+
+	// planCacheSnapshotTopoDir is the directory of the local cell where snapshots are stored.
+	planCacheSnapshotTopoDir = "vtgate/plan_cache"
+)
+
+var (
+	planCacheWarmupPlans = stats.NewCountersWithSingleLabel("PlanCacheWarmupPlans", "Plans built from the plan cache snapshot on startup", "Result")
+	planCacheSnapshots   = stats.NewCountersWithSingleLabel("PlanCacheSnapshots", "Snapshots of the plan cache taken or loaded", "Operation")
+)
+
+type (
+	planCacheSnapshot struct {
+		VSchemaFingerprint string                    `json:"vschema_fingerprint"`
+		Entries            []*planCacheSnapshotEntry `json:"entries"`
+	}
+
+	planCacheSnapshotEntry struct {
+		Keyspace   string                `json:"keyspace,omitempty"`
+		TabletType topodatapb.TabletType `json:"tablet_type"`
+		Collation  collations.ID         `json:"collation"`
+		Query      string                `json:"query"`
+		Prepared   bool                  `json:"prepared,omitempty"`
+		ExecCount  uint64                `json:"exec_count"`
+	}
+
+	// planCacheStore persists the plan cache snapshot.
+	planCacheStore interface {
+		// load returns the last saved snapshot, or nil if there is none.
+		load(ctx context.Context) ([]byte, error)
+		save(ctx context.Context, data []byte) error
+	}
+
+	filePlanCacheStore struct {
+		path string
+	}
+
+	topoPlanCacheStore struct {
+		ts   *topo.Server
+		cell string
+		path string
+	}
+
+	// planCacheSnapshotter takes periodic snapshots of the plan cache of an executor,
+	// and warms it up from the last snapshot on startup.
+	planCacheSnapshotter struct {
+		e     *Executor
+		store planCacheStore
+		size  int
+
+		mu sync.Mutex
+		// pending is the loaded snapshot, until the plan cache has been warmed up from it.
+		pending *planCacheSnapshot
+		// cancelWarmup cancels the warm-up in progress, if any.
+		cancelWarmup context.CancelFunc
+		// warmups counts the started warm-ups, to tell whether a warm-up was superseded.
+		warmups int
+
+		closed atomic.Bool
+
+		// keysMu protects keys.
+		keysMu sync.Mutex
+		// keys maps the hashes of the plan cache to the keys they were built from.
+		keys map[PlanCacheKey]planCacheKeyInfo
+	}
+
+	planCacheKeyInfo struct {
+		key      engine.PlanKey
+		prepared bool
+	}
+)
+
+func newPlanCacheStore(location string, ts *topo.Server, cell string) planCacheStore {
+	if name, ok := strings.CutPrefix(location, planCacheSnapshotTopoPrefix); ok {
+		return &topoPlanCacheStore{ts: ts, cell: cell, path: path.Join(planCacheSnapshotTopoDir, name)}
+	}
+	return &filePlanCacheStore{path: location}
+}
+
+func (s *filePlanCacheStore) load(context.Context) ([]byte, error) {
+	data, err := os.ReadFile(s.path)
+	if errors.Is(err, os.ErrNotExist) {
+		return nil, nil
+	}
+	return data, err
+}
+
+func (s *filePlanCacheStore) save(_ context.Context, data []byte) error {
+	// Write to a temporary file first, so that a crash never leaves a truncated snapshot behind.
+	tmp, err := os.CreateTemp(filepath.Dir(s.path), filepath.Base(s.path)+".*.tmp")
+	if err != nil {
+		return err
+	}
+	defer os.Remove(tmp.Name())
+
+	if _, err := tmp.Write(data); err != nil {
+		tmp.Close()
+		return err
+	}
+	if err := tmp.Close(); err != nil {
+		return err
+	}
+	return os.Rename(tmp.Name(), s.path)
+}
+
+func (s *topoPlanCacheStore) load(ctx context.Context) ([]byte, error) {
+	conn, err := s.ts.ConnForCell(ctx, s.cell)
+	if err != nil {
+		return nil, err
+	}
+	data, _, err := conn.Get(ctx, s.path)
+	if topo.IsErrType(err, topo.NoNode) {
+		return nil, nil
+	}
+	return data, err
+}
+
+func (s *topoPlanCacheStore) save(ctx context.Context, data []byte) error {
+	conn, err := s.ts.ConnForCell(ctx, s.cell)
+	if err != nil {
+		return err
+	}
+	_, err = conn.Update(ctx, s.path, data, nil)
+	return err
+}
+
+// vschemaFingerprint returns a hash of everything the planner knows about the vschema,
+// including the schema learnt from the tablets.
+func vschemaFingerprint(vschema *vindexes.VSchema) string {
+	if vschema == nil {
+		return ""
+	}
+	data, err := json.Marshal(vschema)
+	if err != nil {
+		return ""
+	}
+	sum := sha256.Sum256(data)
+	return hex.EncodeToString(sum[:])
+}
+
+func newPlanCacheSnapshotter(e *Executor, store planCacheStore, size int) *planCacheSnapshotter {
+	return &planCacheSnapshotter{e: e, store: store, size: size, keys: map[PlanCacheKey]planCacheKeyInfo{}}
+}
+
+// planCached records the key of a plan that was just added to the plan cache, as the
+// cache itself only holds its hash.
+func (s *planCacheSnapshotter) planCached(hash PlanCacheKey, key engine.PlanKey, prepared bool) {
+	s.keysMu.Lock()
+	defer s.keysMu.Unlock()
+	s.keys[hash] = planCacheKeyInfo{key: key, prepared: prepared}
+	// The snapshots drop the keys of the evicted plans, but they may be disabled or the
+	// plan cache may churn in between, so bound the keys to the size of the plan cache.
+	if len(s.keys) > 2*max(s.e.plans.Len(), s.size) {
+		s.pruneKeys()
+	}
+}
+
+// pruneKeys drops the keys of the plans that are no longer cached. The caller must hold keysMu.
+func (s *planCacheSnapshotter) pruneKeys() {
+	keys := make(map[PlanCacheKey]planCacheKeyInfo, s.e.plans.Len())
+	s.e.plans.Range(s.e.epoch.Load(), func(hash PlanCacheKey, _ *engine.Plan) bool {
+		if info, ok := s.keys[hash]; ok {
+			keys[hash] = info
+		}
+		return true
+	})
+	s.keys = keys
+}
+
+// load reads the last snapshot, which is then used to warm up the plan cache once
+// the matching vschema is received.
+func (s *planCacheSnapshotter) load(ctx context.Context) error {
+	data, err := s.store.load(ctx)
+	if err != nil || data == nil {
+		return err
+	}
+	snapshot := &planCacheSnapshot{}
+	if err := json.Unmarshal(data, snapshot); err != nil {
+		return err
+	}
+	planCacheSnapshots.Add("Load", 1)
+	log.Infof("Loaded plan cache snapshot with %d entries", len(snapshot.Entries))
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.pending = snapshot
+	return nil
+}
+
+// vschemaUpdated is called every time the executor receives a new vschema. The plan cache
+// has just been cleared, so if the snapshot matches the new vschema we warm it up again.
+func (s *planCacheSnapshotter) vschemaUpdated(vschema *vindexes.VSchema) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if s.pending == nil || s.closed.Load() {
+		return
+	}
+	if s.cancelWarmup != nil {
+		s.cancelWarmup()
+		s.cancelWarmup = nil
+	}
+	if vschemaFingerprint(vschema) != s.pending.VSchemaFingerprint {
+		return
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	s.cancelWarmup = cancel
+	s.warmups++
+	go s.warmup(ctx, s.warmups, s.pending.Entries)
+}
+
+func (s *planCacheSnapshotter) warmup(ctx context.Context, warmup int, entries []*planCacheSnapshotEntry) {
+	start := time.Now()
+	var warmed, failed int
+	for _, entry := range entries {
+		if ctx.Err() != nil {
+			return
+		}
+		if err := s.e.warmPlan(ctx, entry); err != nil {
+			failed++
+			planCacheWarmupPlans.Add("Failed", 1)
+			log.V(2).Infof("Failed to warm up the plan cache with %q: %v", entry.Query, err)
+			continue
+		}
+		warmed++
+		planCacheWarmupPlans.Add("Warmed", 1)
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.warmups != warmup || ctx.Err() != nil {
+		// A newer vschema came in while we were warming up.
+		return
+	}
+	s.pending = nil
+	s.cancelWarmup = nil
+	log.Infof("Warmed up the plan cache with %d plans in %v, %d failed", warmed, time.Since(start), failed)
+}
+
+// snapshot builds a snapshot of the hottest plans currently in the plan cache.
+func (s *planCacheSnapshotter) snapshot() *planCacheSnapshot {
+	s.keysMu.Lock()
+	keys := make(map[PlanCacheKey]planCacheKeyInfo, len(s.keys))
+	var entries []*planCacheSnapshotEntry
+	s.e.plans.Range(s.e.epoch.Load(), func(hash PlanCacheKey, plan *engine.Plan) bool {
+		info, ok := s.keys[hash]
+		if !ok {
+			return true
+		}
+		// Only keep the keys of the plans that are still cached, so that the map
+		// does not grow past the size of the plan cache.
+		keys[hash] = info
+		key := info.key
+		// Plans targeting specific shards or depending on session settings cannot be rebuilt
+		// outside of their session.
+		if key.Destination != "" || key.SetVarComment != "" {
+			return true
+		}
+		entries = append(entries, &planCacheSnapshotEntry{
+			Keyspace:   key.CurrentKeyspace,
+			TabletType: key.TabletType,
+			Collation:  key.Collation,
+			Query:      key.Query,
+			Prepared:   info.prepared,
+			ExecCount:  atomic.LoadUint64(&plan.ExecCount),
+		})
+		return true
+	})
+	s.keys = keys
+	s.keysMu.Unlock()
+
+	slices.SortFunc(entries, func(a, b *planCacheSnapshotEntry) int {
+		switch {
+		case a.ExecCount > b.ExecCount:
+			return -1
+		case a.ExecCount < b.ExecCount:
+			return 1
+		}
+		return strings.Compare(a.Query, b.Query)
+	})
+	if len(entries) > s.size {
+		entries = entries[:s.size]
+	}
+
+	return &planCacheSnapshot{
+		VSchemaFingerprint: vschemaFingerprint(s.e.VSchema()),
+		Entries:            entries,
+	}
+}
+
+// save persists a snapshot of the plan cache, unless we are still waiting to warm it up
+// from the previous one.
+func (s *planCacheSnapshotter) save(ctx context.Context) error {
+	s.mu.Lock()
+	if s.pending != nil {
+		if s.cancelWarmup != nil {
+			// Still warming up, the plan cache does not hold the hot set yet.
+			s.mu.Unlock()
+			return nil
+		}
+		log.Warningf("Dropping the plan cache snapshot: no matching vschema was received")
+		planCacheSnapshots.Add("Stale", 1)
+		s.pending = nil
+	}
+	s.mu.Unlock()
+
+	data, err := json.Marshal(s.snapshot())
+	if err != nil {
+		return err
+	}
+	if err := s.store.save(ctx, data); err != nil {
+		return err
+	}
+	planCacheSnapshots.Add("Save", 1)
+	return nil
+}
+
+// run takes a snapshot of the plan cache at every interval until the context is done.
+func (s *planCacheSnapshotter) run(ctx context.Context, interval time.Duration) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			if err := s.save(ctx); err != nil {
+				log.Errorf("Failed to save the plan cache snapshot: %v", err)
+			}
+		}
+	}
+}
+
+// close stops any warm-up in progress and takes a final snapshot.
+func (s *planCacheSnapshotter) close(ctx context.Context) {
+	if s.closed.Swap(true) {
+		return
+	}
+	s.mu.Lock()
+	if s.cancelWarmup != nil {
+		s.cancelWarmup()
+		s.cancelWarmup = nil
+	}
+	// If we never got to warm up, keep the previous snapshot around for the next start.
+	pending := s.pending != nil
+	s.mu.Unlock()
+
+	if pending {
+		return
+	}
+	if err := s.save(ctx); err != nil {
+		log.Errorf("Failed to save the plan cache snapshot: %v", err)
+	}
+}
+
+// warmPlan plans the query of a snapshot entry and stores it in the plan cache, as if
+// it had been received from a new session with the same target.
+func (e *Executor) warmPlan(ctx context.Context, entry *planCacheSnapshotEntry) error {
+	if entry.Collation != e.vConfig.Collation {
+		return errors.New("collation mismatch")
+	}
+
+	target := entry.Keyspace + "@" + topoproto.TabletTypeLString(entry.TabletType)
+	safeSession := econtext.NewAutocommitSession(&vtgatepb.Session{TargetString: target})
+	logStats := logstats.NewLogStats(ctx, "PlanCacheWarmup", entry.Query, "", nil, streamlog.GetQueryLogConfig())
+	vcursor, err := e.newVCursor(safeSession, sqlparser.MarginComments{}, logStats)
+	if err != nil {
+		return err
+	}
+
+	stmt, reservedVars, err := parseAndValidateQuery(entry.Query, e.env.Parser())
+	if err != nil {
+		return err
+	}
+	// The normalized query in the plan key carries the types of its bind variables
+	// as comments, which the parser drops: put them back on the arguments so that
+	// the plan is built, and cached, exactly as it was originally.
+	restoreArgumentTypes(stmt, entry.Query)
+
+	var planKey engine.PlanKey
+	if entry.Prepared {
+		planKey = buildPlanKey(ctx, vcursor, entry.Query, "")
+	}
+	parameterize := e.config.Normalize && !entry.Prepared
+	_, _, _, err = e.getCachedOrBuildPlanForStmt(ctx, vcursor, entry.Query, stmt, reservedVars, map[string]*querypb.BindVariable{}, "", parameterize, planKey, false)
+	return err
+}
+
+// argumentTypeComment matches the type comment that sqlparser.Argument emits
+// for statically typed bind variables, e.g. `:id /* INT64 */` or `:v /* DECIMAL(10,2) */`.
+var argumentTypeComment = regexp.MustCompile(`:([\w.]+) /\* ([A-Z0-9_]+)(?:\((\d+)(?:,(\d+))?\))? \*/`)
+
+// restoreArgumentTypes sets the types found in the comments of the query
+// on the matching arguments of the statement.
+func restoreArgumentTypes(stmt sqlparser.Statement, query string) {
+	types := map[string]*sqlparser.Argument{}
+	for _, m := range argumentTypeComment.FindAllStringSubmatch(query, -1) {
+		typ, ok := querypb.Type_value[m[2]]
+		if !ok {
+			continue
+		}
+		arg := &sqlparser.Argument{Name: m[1], Type: querypb.Type(typ)}
+		if m[3] != "" {
+			size, _ := strconv.ParseInt(m[3], 10, 32)
+			arg.Size = int32(size)
+		}
+		if m[4] != "" {
+			scale, _ := strconv.ParseInt(m[4], 10, 32)
+			arg.Scale = int32(scale)
+		}
+		types[arg.Name] = arg
+	}
+	if len(types) == 0 {
+		return
+	}
+	_ = sqlparser.Walk(func(node sqlparser.SQLNode) (bool, error) {
+		if arg, ok := node.(*sqlparser.Argument); ok {
+			if typed, ok := types[arg.Name]; ok && arg.Type < 0 {
+				arg.Type, arg.Size, arg.Scale = typed.Type, typed.Size, typed.Scale
+			}
+		}
+		return true, nil
+	}, stmt)
+}
@@ -0,0 +1,192 @@
+/*
+Copyright 2025 The Vitess Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package vtgate
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"vitess.io/vitess/go/vt/topo/memorytopo"
+	"vitess.io/vitess/go/vt/vtgate/engine"
+	"vitess.io/vitess/go/vt/vtgate/vindexes"
+
+	topodatapb "vitess.io/vitess/go/vt/proto/topodata"
+	vschemapb "vitess.io/vitess/go/vt/proto/vschema"
+	vtgatepb "vitess.io/vitess/go/vt/proto/vtgate"
+)
+
+func planCacheQueries(e *Executor) map[string]bool {
+	queries := map[string]bool{}
+	e.ForEachPlan(func(plan *engine.Plan) bool {
+		queries[plan.Original] = true
+		return true
+	})
+	return queries
+}
+
+func waitForWarmup(t *testing.T, s *planCacheSnapshotter) {
+	t.Helper()
+	require.Eventually(t, func() bool {
+		s.mu.Lock()
+		defer s.mu.Unlock()
+		return s.pending == nil
+	}, 10*time.Second, 10*time.Millisecond)
+}
+
+func TestPlanCacheSnapshot(t *testing.T) {
+	executor, _, _, _, ctx := createExecutorEnvWithConfig(t, createExecutorConfigWithNormalizer())
+	path := filepath.Join(t.TempDir(), "plans.json")
+	s := newPlanCacheSnapshotter(executor, &filePlanCacheStore{path: path}, 10)
+	executor.planSnapshots = s
+
+	session := &vtgatepb.Session{TargetString: "@primary"}
+	for i := 0; i < 3; i++ {
+		_, err := executorExec(ctx, executor, session, "select id from user where id = 1", nil)
+		require.NoError(t, err)
+	}
+	_, err := executorExec(ctx, executor, session, "select id from music_user_map where music_id = 2", nil)
+	require.NoError(t, err)
+	// plans targeting a shard are not part of the snapshot
+	_, err = executorExec(ctx, executor, &vtgatepb.Session{TargetString: KsTestSharded + "/-20"}, "select id from user", nil)
+	require.NoError(t, err)
+	require.NoError(t, s.save(ctx))
+
+	data, err := os.ReadFile(path)
+	require.NoError(t, err)
+	snapshot := &planCacheSnapshot{}
+	require.NoError(t, json.Unmarshal(data, snapshot))
+	assert.Equal(t, vschemaFingerprint(executor.VSchema()), snapshot.VSchemaFingerprint)
+	require.Len(t, snapshot.Entries, 2)
+	assert.EqualValues(t, 3, snapshot.Entries[0].ExecCount)
+	assert.Contains(t, snapshot.Entries[0].Query, "from `user` where id = :id")
+	assert.EqualValues(t, 1, snapshot.Entries[1].ExecCount)
+	assert.Equal(t, topodatapb.TabletType_PRIMARY, snapshot.Entries[0].TabletType)
+
+	// A new vschema clears the plans, and brings them back from the snapshot.
+	s = newPlanCacheSnapshotter(executor, &filePlanCacheStore{path: path}, 10)
+	require.NoError(t, s.load(ctx))
+	executor.planSnapshots = s
+	executor.SaveVSchema(executor.VSchema(), executor.vschemaStats)
+	waitForWarmup(t, s)
+
+	cached := planCacheQueries(executor)
+	for _, entry := range snapshot.Entries {
+		assert.Truef(t, cached[entry.Query], "plan not warmed up: %s", entry.Query)
+	}
+	assert.Len(t, cached, 2)
+
+	// The warmed up plans are used by the queries.
+	before := executor.plans.Metrics.Hits()
+	_, err = executorExec(ctx, executor, session, "select id from user where id = 5", nil)
+	require.NoError(t, err)
+	assert.Equal(t, before+1, executor.plans.Metrics.Hits())
+}
+
+func TestPlanCacheSnapshotStale(t *testing.T) {
+	executor, _, _, _, ctx := createExecutorEnvWithConfig(t, createExecutorConfigWithNormalizer())
+	path := filepath.Join(t.TempDir(), "plans.json")
+	s := newPlanCacheSnapshotter(executor, &filePlanCacheStore{path: path}, 10)
+	executor.planSnapshots = s
+
+	session := &vtgatepb.Session{TargetString: "@primary"}
+	_, err := executorExec(ctx, executor, session, "select id from user where id = 1", nil)
+	require.NoError(t, err)
+	require.NoError(t, s.save(ctx))
+
+	s = newPlanCacheSnapshotter(executor, &filePlanCacheStore{path: path}, 10)
+	require.NoError(t, s.load(ctx))
+	executor.planSnapshots = s
+
+	// A different vschema does not warm up the plan cache...
+	vschema := vindexes.BuildVSchema(&vschemapb.SrvVSchema{}, executor.env.Parser())
+	executor.SaveVSchema(vschema, executor.vschemaStats)
+	time.Sleep(100 * time.Millisecond)
+	assert.Empty(t, planCacheQueries(executor))
+	s.mu.Lock()
+	assert.NotNil(t, s.pending)
+	s.mu.Unlock()
+
+	// ...and the snapshot is replaced on the next save.
+	require.NoError(t, s.save(ctx))
+	s.mu.Lock()
+	assert.Nil(t, s.pending)
+	s.mu.Unlock()
+
+	data, err := os.ReadFile(path)
+	require.NoError(t, err)
+	snapshot := &planCacheSnapshot{}
+	require.NoError(t, json.Unmarshal(data, snapshot))
+	assert.Equal(t, vschemaFingerprint(vschema), snapshot.VSchemaFingerprint)
+	assert.Empty(t, snapshot.Entries)
+}
+
+func TestPlanCacheSnapshotKeysBounded(t *testing.T) {
+	executor, _, _, _, ctx := createExecutorEnvWithConfig(t, createExecutorConfigWithNormalizer())
+	s := newPlanCacheSnapshotter(executor, &filePlanCacheStore{path: filepath.Join(t.TempDir(), "plans.json")}, 10)
+	executor.planSnapshots = s
+
+	session := &vtgatepb.Session{TargetString: "@primary"}
+	_, err := executorExec(ctx, executor, session, "select id from user where id = 1", nil)
+	require.NoError(t, err)
+	require.Len(t, s.keys, 1)
+
+	// Without snapshots, the keys of the plans that are no longer cached are dropped
+	// once there are more keys than twice the size of the plan cache.
+	for i := 0; i < 100; i++ {
+		key := engine.PlanKey{Query: fmt.Sprintf("select %d from dual", i)}
+		s.planCached(key.Hash(), key, false)
+		assert.LessOrEqual(t, len(s.keys), 2*max(executor.plans.Len(), 10))
+	}
+	var queries []string
+	for _, info := range s.keys {
+		queries = append(queries, info.key.Query)
+	}
+	assert.Contains(t, queries, "select id from `user` where id = :id /* INT64 */")
+}
+
+func TestPlanCacheStore(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	ts := memorytopo.NewServer(ctx, "cell")
+	defer ts.Close()
+
+	stores := map[string]planCacheStore{
+		"file": newPlanCacheStore(filepath.Join(t.TempDir(), "plans.json"), ts, "cell"),
+		"topo": newPlanCacheStore("topo:plans", ts, "cell"),
+	}
+	for name, store := range stores {
+		t.Run(name, func(t *testing.T) {
+			data, err := store.load(ctx)
+			require.NoError(t, err)
+			assert.Nil(t, data)
+
+			require.NoError(t, store.save(ctx, []byte("v1")))
+			require.NoError(t, store.save(ctx, []byte("v2")))
+			data, err = store.load(ctx)
+			require.NoError(t, err)
+			assert.Equal(t, "v2", string(data))
+		})
+	}
+}
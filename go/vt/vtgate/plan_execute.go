@@ -334,6 +334,11 @@ func (e *Executor) executePlan(
 	// 5: Log and add statistics
 	e.setLogStats(logStats, plan, vcursor, execStart, err, qr)
 
+	// Even a failed write can have modified some of its tables.
+	if e.resultCache != nil && !plan.QueryType.IsReadStatement() {
+		e.resultCache.invalidateTables(plan.TablesUsed)
+	}
+
 	// Check if there was partial DML execution. If so, rollback the effect of the partially executed query.
 	if err != nil {
 		return nil, e.rollbackExecIfNeeded(ctx, safeSession, bindVars, logStats, err)
@@ -71,6 +71,8 @@ func recursiveTransform(ctx *plancontext.PlanningContext, op operators.Operator)
 		return transformLimit(ctx, op)
 	case *operators.Ordering:
 		return transformOrdering(ctx, op)
+	case *operators.Window:
+		return transformWindow(ctx, op)
 	case *operators.Aggregator:
 		return transformAggregator(ctx, op)
 	case *operators.Distinct:
@@ -425,6 +427,113 @@ func createMemorySort(ctx *plancontext.PlanningContext, src engine.Primitive, or
 	return prim, nil
 }
 
+func transformWindow(ctx *plancontext.PlanningContext, op *operators.Window) (engine.Primitive, error) {
+	src, err := transformToPrimitive(ctx, op.Source)
+	if err != nil {
+		return nil, err
+	}
+
+	collationEnv := ctx.VSchema.Environment().CollationEnv()
+	prim := &engine.Window{Input: src}
+	for idx, expr := range op.Spec.PartitionClause {
+		typ, _ := ctx.TypeForExpr(expr)
+		prim.PartitionBy = append(prim.PartitionBy, &engine.GroupByParams{
+			KeyCol:          op.PartitionOffsets[idx],
+			WeightStringCol: op.PartitionWOffsets[idx],
+			Expr:            expr,
+			Type:            typ,
+			CollationEnv:    collationEnv,
+		})
+	}
+	for idx, order := range op.Spec.OrderClause {
+		typ, _ := ctx.TypeForExpr(order.Expr)
+		prim.OrderBy = append(prim.OrderBy, evalengine.OrderByParams{
+			Col:             op.OrderOffsets[idx],
+			WeightStringCol: op.OrderWOffsets[idx],
+			Desc:            order.Direction == sqlparser.DescOrder,
+			Type:            typ,
+			CollationEnv:    collationEnv,
+		})
+	}
+
+	cfg := &evalengine.Config{
+		Collation:   ctx.VSchema.ConnCollation(),
+		Environment: ctx.VSchema.Environment(),
+	}
+	translate := func(e sqlparser.Expr) (evalengine.Expr, error) {
+		if e == nil {
+			return nil, nil
+		}
+		return evalengine.Translate(e, cfg)
+	}
+
+	for idx, fn := range op.Functions {
+		wf := &engine.WindowFunction{
+			Col:   op.ArgOffsets[idx],
+			Alias: sqlparser.String(fn),
+		}
+		switch fn := fn.(type) {
+		case *sqlparser.ArgumentLessWindowExpr:
+			wf.Opcode = argumentLessWindowOpcodes[fn.Type]
+		case *sqlparser.NtileExpr:
+			wf.Opcode = opcode.WindowNtile
+			wf.N, err = translate(fn.N)
+		case *sqlparser.FirstOrLastValueExpr:
+			wf.Opcode = opcode.WindowFirstValue
+			if fn.Type == sqlparser.LastValueExprType {
+				wf.Opcode = opcode.WindowLastValue
+			}
+		case *sqlparser.NTHValueExpr:
+			wf.Opcode = opcode.WindowNthValue
+			wf.N, err = translate(fn.N)
+		case *sqlparser.LagLeadExpr:
+			wf.Opcode = opcode.WindowLag
+			if fn.Type == sqlparser.LeadExprType {
+				wf.Opcode = opcode.WindowLead
+			}
+			n := fn.N
+			if n == nil {
+				n = sqlparser.NewIntLiteral("1")
+			}
+			if wf.N, err = translate(n); err == nil {
+				wf.Default, err = translate(fn.Default)
+			}
+		case sqlparser.AggrFunc:
+			code, ok := opcode.SupportedAggregates[fn.AggrName()]
+			if _, isCountStar := fn.(*sqlparser.CountStar); isCountStar {
+				code = opcode.AggregateCountStar
+			}
+			if !ok {
+				return nil, vterrors.VT12001(fmt.Sprintf("window function %s", sqlparser.String(fn)))
+			}
+			wf.Opcode = opcode.WindowAggregate
+			wf.Aggregate = engine.NewAggregateParam(code, wf.Col, "", collationEnv)
+			if arg := fn.GetArg(); arg != nil {
+				wf.Aggregate.Type, _ = ctx.TypeForExpr(arg)
+			}
+		default:
+			return nil, vterrors.VT12001(fmt.Sprintf("window function %s", sqlparser.String(fn)))
+		}
+		if err != nil {
+			return nil, vterrors.Wrapf(err, "unexpected argument in %s", sqlparser.String(fn))
+		}
+		if wf.Opcode.RespectsFrame() {
+			wf.Frame = op.Frame
+		}
+		prim.Functions = append(prim.Functions, wf)
+	}
+
+	return prim, nil
+}
+
+var argumentLessWindowOpcodes = map[sqlparser.ArgumentLessWindowExprType]opcode.WindowOpcode{
+	sqlparser.CumeDistExprType:    opcode.WindowCumeDist,
+	sqlparser.DenseRankExprType:   opcode.WindowDenseRank,
+	sqlparser.PercentRankExprType: opcode.WindowPercentRank,
+	sqlparser.RankExprType:        opcode.WindowRank,
+	sqlparser.RowNumberExprType:   opcode.WindowRowNumber,
+}
+
 func transformProjection(ctx *plancontext.PlanningContext, op *operators.Projection) (engine.Primitive, error) {
 	src, err := transformToPrimitive(ctx, op.Source)
 	if err != nil {
@@ -248,6 +248,10 @@ func (aj *ApplyJoin) AddColumn(
 	col := aj.getJoinColumnFor(ctx, expr, expr.Expr, groupBy)
 	offset := len(aj.JoinColumns.columns)
 	aj.JoinColumns.add(col)
+	if len(aj.Columns) > 0 {
+		// offsets have already been planned, so we need to plan the offset for this column as well
+		aj.planOffsetFor(ctx, col)
+	}
 	return offset
 }
 
@@ -266,24 +270,27 @@ func (aj *ApplyJoin) AddWSColumn(ctx *plancontext.PlanningContext, offset int, u
 		return index
 	}
 
+	// JoinColumns and Columns have to stay aligned, so offsets returned by FindCol and AddColumn stay valid
 	i := aj.Columns[offset]
-	out := 0
+	var out int
 	if i < 0 {
 		out = aj.LHS.AddWSColumn(ctx, FromLeftOffset(i), underRoute)
-		out = ToLeftOffset(out)
-		aj.JoinColumns.addLeft(wsExpr)
 	} else {
 		out = aj.RHS.AddWSColumn(ctx, FromRightOffset(i), underRoute)
-		out = ToRightOffset(out)
-		aj.JoinColumns.addRight(wsExpr)
 	}
 
-	if out >= 0 {
-		aj.addOffset(out)
-	} else {
+	switch {
+	case out < 0:
+		// the input could not add the weight_string, so we plan it like any other column
 		col := aj.getJoinColumnFor(ctx, aeWrap(wsExpr), wsExpr, !ctx.ContainsAggr(wsExpr))
 		aj.JoinColumns.add(col)
 		aj.planOffsetFor(ctx, col)
+	case i < 0:
+		aj.JoinColumns.addLeft(wsExpr)
+		aj.addOffset(ToLeftOffset(out))
+	default:
+		aj.JoinColumns.addRight(wsExpr)
+		aj.addOffset(ToRightOffset(out))
 	}
 
 	return len(aj.Columns) - 1
@@ -0,0 +1,62 @@
+/*
+Copyright 2025 The Vitess Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package operators
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"vitess.io/vitess/go/vt/sqlparser"
+	"vitess.io/vitess/go/vt/vtgate/planbuilder/plancontext"
+	"vitess.io/vitess/go/vt/vtgate/semantics"
+)
+
+func TestApplyJoinAddWSColumn(t *testing.T) {
+	lcol := sqlparser.NewColName("lhs")
+	rcol := sqlparser.NewColName("rhs")
+	ctx := &plancontext.PlanningContext{SemTable: semantics.EmptySemTable()}
+	lid := semantics.SingleTableSet(0)
+	rid := semantics.SingleTableSet(1)
+	ctx.SemTable.Recursive[lcol] = lid
+	ctx.SemTable.Recursive[rcol] = rid
+	lhs := &fakeOp{id: lid}
+	rhs := &fakeOp{id: rid, noWeightString: true}
+	aj := &ApplyJoin{
+		binaryOperator: newBinaryOp(lhs, rhs),
+		Vars:           map[string]int{},
+		JoinColumns:    &applyJoinColumns{},
+		JoinPredicates: &applyJoinColumns{},
+	}
+	aj.AddColumn(ctx, true, false, aeWrap(lcol))
+	aj.AddColumn(ctx, true, false, aeWrap(rcol))
+
+	// the LHS adds the weight_string itself
+	assert.Equal(t, 2, aj.AddWSColumn(ctx, 0, false))
+	assert.Equal(t, ToLeftOffset(1), aj.Columns[2])
+
+	// the RHS cannot add it, so it is planned as a column of the join
+	assert.Equal(t, 3, aj.AddWSColumn(ctx, 1, false))
+	assert.Equal(t, ToRightOffset(1), aj.Columns[3])
+	require.Len(t, rhs.cols, 2)
+	assert.Equal(t, "weight_string(rhs)", sqlparser.String(rhs.cols[1].Expr))
+
+	// columns and offsets stay aligned
+	require.Len(t, aj.JoinColumns.columns, len(aj.Columns))
+	assert.Equal(t, 3, aj.FindCol(ctx, weightStringFor(rcol), false))
+}
@@ -193,12 +193,22 @@ func createProjectionFromSelect(ctx *plancontext.PlanningContext, horizon *Horiz
 		}
 	}
 
+	sel, isSel := horizon.selectStatement().(*sqlparser.Select)
+	windows := isSel && needsWindowOperators(ctx, sel, horizon.src())
+
 	if qp.NeedsAggregation() {
+		if windows {
+			// window functions over aggregated rows are only supported when the query goes to a single shard
+			markWindowsUnsupported(ctx, vterrors.VT12001("window functions in aggregated queries"))
+		}
 		return createProjectionWithAggr(ctx, qp, dt, horizon)
 	}
 
 	projX := createProjectionWithoutAggr(ctx, qp, horizon.src())
 	projX.DT = dt
+	if windows {
+		planWindows(ctx, sel, projX)
+	}
 	return projX
 }
 
@@ -280,7 +280,8 @@ func pushOrExpandHorizon(ctx *plancontext.PlanningContext, in *Horizon) (Operato
 		!needsOrdering &&
 		!qp.NeedsAggregation() &&
 		!isDistinctAST(in.selectStatement()) &&
-		in.selectStatement().GetLimit() == nil
+		in.selectStatement().GetLimit() == nil &&
+		!(isSel && needsWindowOperators(ctx, sel, rb))
 
 	if canPush {
 		return Swap(in, rb, "push horizon into route")
@@ -557,6 +558,9 @@ func setUpperLimit(in *Limit) (Operator, *ApplyResult) {
 		case *Join, *ApplyJoin, *SubQueryContainer, *SubQuery:
 			// we can't push limits down on either side
 			return SkipChildren
+		case *Window:
+			// window functions need to see all the rows of their partitions
+			return SkipChildren
 		case *Aggregator:
 			if len(op.Grouping) > 0 {
 				// we can't push limits down if we have a group by
@@ -25,6 +25,7 @@ import (
 
 	"vitess.io/vitess/go/mysql/collations"
 	"vitess.io/vitess/go/slice"
+	"vitess.io/vitess/go/sqltypes"
 	"vitess.io/vitess/go/vt/sqlparser"
 	"vitess.io/vitess/go/vt/vtgate/engine"
 	"vitess.io/vitess/go/vt/vtgate/evalengine"
@@ -284,11 +285,104 @@ func (tr *ShardedRouting) planComparison(ctx *plancontext.PlanningContext, cmp *
 	case sqlparser.LikeOp:
 		found := tr.planLikeOp(ctx, cmp)
 		return nil, found
+	case sqlparser.LessThanOp, sqlparser.LessEqualOp, sqlparser.GreaterThanOp, sqlparser.GreaterEqualOp:
+		found := tr.planRangeOp(ctx, cmp)
+		return nil, found
 
 	}
 	return nil, false
 }
 
+// planRangeOp plans inequality predicates on vindexes that can map a range of values
+// with a single bound. The bound is always treated as inclusive, since we are only
+// looking for the shards that can hold matching rows.
+func (tr *ShardedRouting) planRangeOp(ctx *plancontext.PlanningContext, cmp *sqlparser.ComparisonExpr) bool {
+	operator := cmp.Operator
+	column, ok := cmp.Left.(*sqlparser.ColName)
+	bound := cmp.Right
+	if !ok {
+		column, ok = cmp.Right.(*sqlparser.ColName)
+		if !ok {
+			// either the LHS or RHS have to be a column to be useful for the vindex
+			return false
+		}
+		bound = cmp.Left
+		operator, _ = operator.SwitchSides()
+	}
+	var vdValue sqlparser.ValTuple
+	switch operator {
+	case sqlparser.GreaterThanOp, sqlparser.GreaterEqualOp:
+		vdValue = sqlparser.ValTuple{bound, &sqlparser.NullVal{}}
+	default:
+		vdValue = sqlparser.ValTuple{&sqlparser.NullVal{}, bound}
+	}
+	val := makeEvalEngineExpr(ctx, vdValue)
+	if val == nil {
+		return false
+	}
+
+	opcode := func(vindex *vindexes.ColumnVindex) engine.Opcode {
+		if _, ok := vindex.Vindex.(vindexes.RangeMappable); ok && rangeComparable(ctx, column, bound, vindex.Vindex) {
+			return engine.Range
+		}
+		return engine.Scatter
+	}
+	if !tr.haveMatchingVindex(ctx, cmp, vdValue, column, val, opcode, justTheVindex) {
+		return false
+	}
+	tr.mergeRangeOptions(ctx)
+	return true
+}
+
+// mergeRangeOptions combines the bound of a range option that was just added with the
+// other bound of the previous range option on the same vindex, so that predicates like
+// 'id >= 10 and id < 20' are routed to the shards covering the range between them.
+func (tr *ShardedRouting) mergeRangeOptions(ctx *plancontext.PlanningContext) {
+	for _, vpp := range tr.VindexPreds {
+		n := len(vpp.Options)
+		if n < 2 || vpp.Options[n-1].OpCode != engine.Range {
+			continue
+		}
+		last := vpp.Options[n-1]
+		for _, prev := range vpp.Options[:n-1] {
+			if prev.OpCode != engine.Range || !prev.Ready {
+				continue
+			}
+			// All the predicates must hold, so any lower bound can be combined with any upper bound.
+			bounds := slices.Clone(prev.ValueExprs[0].(sqlparser.ValTuple))
+			for i, bound := range last.ValueExprs[0].(sqlparser.ValTuple) {
+				if _, isNull := bound.(*sqlparser.NullVal); !isNull {
+					bounds[i] = bound
+				}
+			}
+			val := makeEvalEngineExpr(ctx, bounds)
+			if val == nil {
+				continue
+			}
+			last.Values = []evalengine.Expr{val}
+			last.ValueExprs = []sqlparser.Expr{bounds}
+			last.Predicates = append(slices.Clone(prev.Predicates), last.Predicates...)
+		}
+	}
+}
+
+// rangeComparable returns true if the column is compared to the bound in the order in
+// which the vindex maps the values to keyspace ids, so that a range of values maps to a
+// range of keyspace ids.
+func rangeComparable(ctx *plancontext.PlanningContext, column *sqlparser.ColName, bound sqlparser.Expr, vindex vindexes.Vindex) bool {
+	typ, found := ctx.TypeForExpr(column)
+	known := found && typ.Type() != sqltypes.Unknown
+	if _, ok := vindex.(*vindexes.Binary); ok {
+		// The binary vindex orders the values by their bytes. Only binary strings compare
+		// that way, and only to other strings: a string is compared to a number as a number.
+		boundType, found := ctx.TypeForExpr(bound)
+		return known && sqltypes.IsTextOrBinary(typ.Type()) && typ.Collation() == collations.CollationBinaryID &&
+			found && sqltypes.IsTextOrBinary(boundType.Type())
+	}
+	// The other vindexes order the values as integers.
+	return !known || sqltypes.IsIntegral(typ.Type())
+}
+
 func (tr *ShardedRouting) planIsExpr(ctx *plancontext.PlanningContext, node *sqlparser.IsExpr) bool {
 	// we only handle IS NULL correct. IsExpr can contain other expressions as well
 	if node.Right != sqlparser.IsNullOp {
@@ -371,7 +465,7 @@ func (tr *ShardedRouting) Cost() int {
 		return 5
 	case engine.IN:
 		return 10
-	case engine.Between:
+	case engine.Between, engine.Range:
 		return 10
 	case engine.MultiEqual:
 		return 10
@@ -28,6 +28,9 @@ type fakeOp struct {
 	id     semantics.TableSet
 	inputs []Operator
 	cols   []*sqlparser.AliasedExpr
+
+	// noWeightString makes AddWSColumn fail, like inputs that cannot add a weight_string
+	noWeightString bool
 }
 
 var _ Operator = (*fakeOp)(nil)
@@ -58,8 +61,11 @@ func (f *fakeOp) AddColumn(ctx *plancontext.PlanningContext, reuseExisting bool,
 	return len(f.cols) - 1
 }
 
-func (*fakeOp) AddWSColumn(*plancontext.PlanningContext, int, bool) int {
-	panic("implement me")
+func (f *fakeOp) AddWSColumn(ctx *plancontext.PlanningContext, offset int, _ bool) int {
+	if f.noWeightString {
+		return -1
+	}
+	return f.AddColumn(ctx, true, false, aeWrap(weightStringFor(f.cols[offset].Expr)))
 }
 
 func (f *fakeOp) FindCol(ctx *plancontext.PlanningContext, a sqlparser.Expr, underRoute bool) int {
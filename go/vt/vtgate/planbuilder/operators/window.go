@@ -0,0 +1,480 @@
+/*
+Copyright 2024 The Vitess Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package operators
+
+import (
+	"fmt"
+	"math"
+	"slices"
+	"strconv"
+	"strings"
+
+	"vitess.io/vitess/go/slice"
+	"vitess.io/vitess/go/vt/sqlparser"
+	"vitess.io/vitess/go/vt/vterrors"
+	"vitess.io/vitess/go/vt/vtgate/engine"
+	"vitess.io/vitess/go/vt/vtgate/planbuilder/plancontext"
+)
+
+// Window evaluates window functions that share the same window specification at the vtgate level.
+// The input is expected to be sorted by the PARTITION BY and ORDER BY expressions of the window.
+// The results of the window functions are the first columns of the output, followed by the input columns.
+type Window struct {
+	unaryOperator
+
+	// Spec is the window specification, with any named window resolved
+	Spec *sqlparser.WindowSpecification
+	// Frame is the frame used by functions that respect frames
+	Frame *engine.WindowFrame
+	// Functions are the window functions evaluated by this operator
+	Functions []sqlparser.Expr
+
+	// These are only filled in during offset planning
+	PartitionOffsets  []int
+	PartitionWOffsets []int
+	OrderOffsets      []int
+	OrderWOffsets     []int
+	ArgOffsets        []int
+}
+
+func newWindow(src Operator, spec *sqlparser.WindowSpecification, frame *engine.WindowFrame) *Window {
+	return &Window{
+		unaryOperator: newUnaryOp(src),
+		Spec:          spec,
+		Frame:         frame,
+	}
+}
+
+func (w *Window) Clone(inputs []Operator) Operator {
+	klone := *w
+	klone.Source = inputs[0]
+	klone.Functions = slices.Clone(w.Functions)
+	klone.PartitionOffsets = slices.Clone(w.PartitionOffsets)
+	klone.PartitionWOffsets = slices.Clone(w.PartitionWOffsets)
+	klone.OrderOffsets = slices.Clone(w.OrderOffsets)
+	klone.OrderWOffsets = slices.Clone(w.OrderWOffsets)
+	klone.ArgOffsets = slices.Clone(w.ArgOffsets)
+	return &klone
+}
+
+func (w *Window) AddPredicate(_ *plancontext.PlanningContext, expr sqlparser.Expr) Operator {
+	// predicates can't be pushed below the window - they would change the rows the functions are evaluated over
+	return newFilter(w, expr)
+}
+
+func (w *Window) AddColumn(ctx *plancontext.PlanningContext, reuse bool, gb bool, ae *sqlparser.AliasedExpr) int {
+	if offset := w.findFunction(ctx, ae.Expr); offset >= 0 {
+		return offset
+	}
+	if sqlparser.ContainsWindowFunction(ae.Expr) {
+		panic(vterrors.VT13001(fmt.Sprintf("window function not found in window operator: %s", sqlparser.String(ae.Expr))))
+	}
+	return len(w.Functions) + w.Source.AddColumn(ctx, reuse, gb, ae)
+}
+
+func (w *Window) AddWSColumn(ctx *plancontext.PlanningContext, offset int, underRoute bool) int {
+	if offset < len(w.Functions) {
+		panic(vterrors.VT12001(fmt.Sprintf("weight_string of window function result: %s", sqlparser.String(w.Functions[offset]))))
+	}
+	return len(w.Functions) + w.Source.AddWSColumn(ctx, offset-len(w.Functions), underRoute)
+}
+
+func (w *Window) FindCol(ctx *plancontext.PlanningContext, expr sqlparser.Expr, underRoute bool) int {
+	if offset := w.findFunction(ctx, expr); offset >= 0 {
+		return offset
+	}
+	offset := w.Source.FindCol(ctx, expr, underRoute)
+	if offset < 0 {
+		return offset
+	}
+	return len(w.Functions) + offset
+}
+
+func (w *Window) findFunction(ctx *plancontext.PlanningContext, expr sqlparser.Expr) int {
+	for idx, fn := range w.Functions {
+		if ctx.SemTable.EqualsExprWithDeps(fn, expr) {
+			return idx
+		}
+	}
+	return -1
+}
+
+func (w *Window) GetColumns(ctx *plancontext.PlanningContext) []*sqlparser.AliasedExpr {
+	cols := slice.Map(w.Functions, func(fn sqlparser.Expr) *sqlparser.AliasedExpr {
+		return aeWrap(fn)
+	})
+	return append(cols, w.Source.GetColumns(ctx)...)
+}
+
+func (w *Window) GetSelectExprs(ctx *plancontext.PlanningContext) []sqlparser.SelectExpr {
+	return transformColumnsToSelectExprs(ctx, w)
+}
+
+func (w *Window) GetOrdering(ctx *plancontext.PlanningContext) []OrderBy {
+	return w.Source.GetOrdering(ctx)
+}
+
+func (w *Window) planOffsets(ctx *plancontext.PlanningContext) Operator {
+	addKey := func(expr sqlparser.Expr) (int, int) {
+		offset := w.Source.AddColumn(ctx, true, false, aeWrap(expr))
+		if !ctx.NeedsWeightString(expr) {
+			return offset, -1
+		}
+		return offset, w.Source.AddWSColumn(ctx, offset, false)
+	}
+
+	for _, expr := range w.Spec.PartitionClause {
+		offset, wOffset := addKey(expr)
+		w.PartitionOffsets = append(w.PartitionOffsets, offset)
+		w.PartitionWOffsets = append(w.PartitionWOffsets, wOffset)
+	}
+	for _, order := range w.Spec.OrderClause {
+		offset, wOffset := addKey(order.Expr)
+		w.OrderOffsets = append(w.OrderOffsets, offset)
+		w.OrderWOffsets = append(w.OrderWOffsets, wOffset)
+	}
+	for _, fn := range w.Functions {
+		arg := windowFunctionArg(fn)
+		if arg == nil {
+			w.ArgOffsets = append(w.ArgOffsets, -1)
+			continue
+		}
+		w.ArgOffsets = append(w.ArgOffsets, w.Source.AddColumn(ctx, true, false, aeWrap(arg)))
+	}
+	return nil
+}
+
+func (w *Window) ShortDescription() string {
+	return strings.Join(slice.Map(w.Functions, func(fn sqlparser.Expr) string {
+		return sqlparser.String(fn)
+	}), ", ")
+}
+
+// windowFunctionArg returns the expression the window function reads from its input rows, if any
+func windowFunctionArg(fn sqlparser.Expr) sqlparser.Expr {
+	switch fn := fn.(type) {
+	case *sqlparser.FirstOrLastValueExpr:
+		return fn.Expr
+	case *sqlparser.NTHValueExpr:
+		return fn.Expr
+	case *sqlparser.LagLeadExpr:
+		return fn.Expr
+	case *sqlparser.Count:
+		return fn.Args[0]
+	case *sqlparser.Sum, *sqlparser.Min, *sqlparser.Max:
+		return fn.(sqlparser.AggrFunc).GetArg()
+	}
+	return nil
+}
+
+// checkWindowFunction returns an error if the window function can't be evaluated at the vtgate level
+func checkWindowFunction(fn sqlparser.Expr) error {
+	switch fn := fn.(type) {
+	case *sqlparser.ArgumentLessWindowExpr, *sqlparser.CountStar:
+		return nil
+	case *sqlparser.NtileExpr:
+		return checkWindowArgument(fn.N)
+	case *sqlparser.FirstOrLastValueExpr:
+		return checkNullTreatment(fn.NullTreatmentClause)
+	case *sqlparser.NTHValueExpr:
+		if fn.FromFirstLastClause != nil && fn.FromFirstLastClause.Type == sqlparser.FromLastType {
+			return vterrors.VT12001("NTH_VALUE ... FROM LAST")
+		}
+		if err := checkNullTreatment(fn.NullTreatmentClause); err != nil {
+			return err
+		}
+		return checkWindowArgument(fn.N)
+	case *sqlparser.LagLeadExpr:
+		if err := checkNullTreatment(fn.NullTreatmentClause); err != nil {
+			return err
+		}
+		if err := checkWindowArgument(fn.N); err != nil {
+			return err
+		}
+		return checkWindowArgument(fn.Default)
+	case *sqlparser.Count:
+		if fn.Distinct || len(fn.Args) != 1 {
+			return vterrors.VT12001(fmt.Sprintf("window function %s", sqlparser.String(fn)))
+		}
+		return nil
+	case *sqlparser.Sum:
+		if fn.Distinct {
+			return vterrors.VT12001(fmt.Sprintf("window function %s", sqlparser.String(fn)))
+		}
+		return nil
+	case *sqlparser.Min, *sqlparser.Max:
+		return nil
+	}
+	return vterrors.VT12001(fmt.Sprintf("window function %s", sqlparser.String(fn)))
+}
+
+func checkNullTreatment(clause *sqlparser.NullTreatmentClause) error {
+	if clause != nil && clause.Type == sqlparser.IgnoreNullsType {
+		return vterrors.VT12001("IGNORE NULLS")
+	}
+	return nil
+}
+
+// checkWindowArgument makes sure that row counts and default values don't depend on the input rows
+func checkWindowArgument(e sqlparser.Expr) error {
+	if e == nil {
+		return nil
+	}
+	return sqlparser.Walk(func(node sqlparser.SQLNode) (bool, error) {
+		switch node.(type) {
+		case *sqlparser.ColName, *sqlparser.Subquery:
+			return false, vterrors.VT12001(fmt.Sprintf("window function argument %s", sqlparser.String(e)))
+		}
+		return true, nil
+	}, e)
+}
+
+// windowFrame returns the frame of the window, using the MySQL defaults when no frame clause is given
+func windowFrame(spec *sqlparser.WindowSpecification) (*engine.WindowFrame, error) {
+	fc := spec.FrameClause
+	if fc == nil {
+		frame := &engine.WindowFrame{Range: true, Start: math.MinInt64, End: math.MaxInt64}
+		if len(spec.OrderClause) > 0 {
+			// with ORDER BY, the default frame is RANGE BETWEEN UNBOUNDED PRECEDING AND CURRENT ROW
+			frame.End = 0
+		}
+		return frame, nil
+	}
+
+	frame := &engine.WindowFrame{Range: fc.Unit == sqlparser.FrameRangeType}
+	var err error
+	if frame.Start, err = framePoint(frame, fc.Start); err != nil {
+		return nil, err
+	}
+	if fc.End == nil {
+		// a frame with only a start point ends at the current row
+		return frame, nil
+	}
+	if frame.End, err = framePoint(frame, fc.End); err != nil {
+		return nil, err
+	}
+	return frame, nil
+}
+
+func framePoint(frame *engine.WindowFrame, fp *sqlparser.FramePoint) (int64, error) {
+	switch fp.Type {
+	case sqlparser.CurrentRowType:
+		return 0, nil
+	case sqlparser.UnboundedPrecedingType:
+		return math.MinInt64, nil
+	case sqlparser.UnboundedFollowingType:
+		return math.MaxInt64, nil
+	}
+
+	lit, ok := fp.Expr.(*sqlparser.Literal)
+	if frame.Range || !ok || lit.Type != sqlparser.IntVal || fp.Unit != sqlparser.IntervalNone {
+		return 0, vterrors.VT12001(fmt.Sprintf("window frame point: %s", strings.TrimSpace(sqlparser.String(fp))))
+	}
+	n, err := strconv.ParseInt(lit.Val, 10, 64)
+	if err != nil {
+		return 0, err
+	}
+	if fp.Type == sqlparser.ExprPrecedingType {
+		return -n, nil
+	}
+	return n, nil
+}
+
+// resolveWindowSpec returns the window specification of an OVER clause, with references to named windows resolved
+func resolveWindowSpec(windows sqlparser.NamedWindows, over *sqlparser.OverClause) (*sqlparser.WindowSpecification, error) {
+	if over.WindowName.NotEmpty() {
+		return findNamedWindow(windows, over.WindowName, 0)
+	}
+	return inheritWindowSpec(windows, over.WindowSpec, 0)
+}
+
+func findNamedWindow(windows sqlparser.NamedWindows, name sqlparser.IdentifierCI, depth int) (*sqlparser.WindowSpecification, error) {
+	if depth > len(windows) {
+		return nil, vterrors.VT12001(fmt.Sprintf("circular window reference: %s", name.String()))
+	}
+	for _, nw := range windows {
+		for _, def := range nw.Windows {
+			if def.Name.Equal(name) {
+				return inheritWindowSpec(windows, def.WindowSpec, depth+1)
+			}
+		}
+	}
+	return nil, vterrors.VT12001(fmt.Sprintf("undefined window: %s", name.String()))
+}
+
+// inheritWindowSpec fills in the parts of a window specification that are inherited from the window it names
+func inheritWindowSpec(windows sqlparser.NamedWindows, spec *sqlparser.WindowSpecification, depth int) (*sqlparser.WindowSpecification, error) {
+	if spec == nil || spec.Name.IsEmpty() {
+		return spec, nil
+	}
+	base, err := findNamedWindow(windows, spec.Name, depth)
+	if err != nil {
+		return nil, err
+	}
+	resolved := &sqlparser.WindowSpecification{
+		PartitionClause: base.PartitionClause,
+		OrderClause:     spec.OrderClause,
+		FrameClause:     spec.FrameClause,
+	}
+	if resolved.OrderClause == nil {
+		resolved.OrderClause = base.OrderClause
+	}
+	if resolved.FrameClause == nil {
+		resolved.FrameClause = base.FrameClause
+	}
+	return resolved, nil
+}
+
+// windowFunctions returns the window functions used in the expression, not looking inside subqueries
+func windowFunctions(e sqlparser.SQLNode) (fns []sqlparser.Expr) {
+	_ = sqlparser.Walk(func(node sqlparser.SQLNode) (bool, error) {
+		switch node := node.(type) {
+		case *sqlparser.Subquery:
+			return false, nil
+		case sqlparser.Expr:
+			if sqlparser.IsWindowFunction(node) {
+				fns = append(fns, node)
+				return false, nil
+			}
+		}
+		return true, nil
+	}, e)
+	return fns
+}
+
+// windowsAreShardLocal returns true if all the window functions in the select expressions are
+// partitioned by a column with a unique vindex, which means that every partition lives on a single shard
+// and the window functions can be evaluated by the shards.
+func windowsAreShardLocal(ctx *plancontext.PlanningContext, sel *sqlparser.Select) bool {
+	for _, fn := range windowFunctions(sel.SelectExprs) {
+		spec, err := resolveWindowSpec(sel.Windows, sqlparser.GetOverClause(fn))
+		if err != nil || spec == nil {
+			return false
+		}
+		if !slices.ContainsFunc(spec.PartitionClause, func(e sqlparser.Expr) bool {
+			return exprHasUniqueVindex(ctx, e)
+		}) {
+			return false
+		}
+	}
+	return true
+}
+
+// splitAvgWindowFunction rewrites AVG used as a window function into SUM / COUNT,
+// the same way we split AVG aggregations, so the engine only needs to know about the latter
+func splitAvgWindowFunction(ctx *plancontext.PlanningContext, e sqlparser.Expr) sqlparser.Expr {
+	return sqlparser.CopyOnRewrite(e, nil, func(cursor *sqlparser.CopyOnWriteCursor) {
+		avg, ok := cursor.Node().(*sqlparser.Avg)
+		if !ok || avg.OverClause == nil || avg.Distinct {
+			return
+		}
+		cursor.Replace(&sqlparser.BinaryExpr{
+			Operator: sqlparser.DivOp,
+			Left:     &sqlparser.Sum{Arg: avg.Arg, OverClause: avg.OverClause},
+			Right:    &sqlparser.Count{Args: []sqlparser.Expr{avg.Arg}, OverClause: avg.OverClause},
+		})
+	}, ctx.SemTable.CopySemanticInfo).(sqlparser.Expr)
+}
+
+// planWindows places Window operators between the projection and its input, so the window
+// functions in the projection can be evaluated at the vtgate level. Each distinct window
+// specification gets its own Window operator, on top of an Ordering sorting the rows by the window.
+// If the window functions can't be evaluated by the vtgate, the query is only valid if it
+// ends up being sent to a single shard.
+func planWindows(ctx *plancontext.PlanningContext, sel *sqlparser.Select, proj *Projection) {
+	ap, err := proj.GetAliasedProjections()
+	if err != nil {
+		markWindowsUnsupported(ctx, err)
+		return
+	}
+
+	var windows []*Window
+	var keys []string
+	evalExprs := make([]sqlparser.Expr, len(ap))
+	for i, pe := range ap {
+		evalExprs[i] = splitAvgWindowFunction(ctx, pe.EvalExpr)
+		for _, fn := range windowFunctions(evalExprs[i]) {
+			if err := checkWindowFunction(fn); err != nil {
+				markWindowsUnsupported(ctx, err)
+				return
+			}
+			spec, err := resolveWindowSpec(sel.Windows, sqlparser.GetOverClause(fn))
+			if err != nil {
+				markWindowsUnsupported(ctx, err)
+				return
+			}
+			if spec == nil {
+				spec = &sqlparser.WindowSpecification{}
+			}
+
+			key := sqlparser.String(spec)
+			idx := slices.Index(keys, key)
+			if idx < 0 {
+				frame, err := windowFrame(spec)
+				if err != nil {
+					markWindowsUnsupported(ctx, err)
+					return
+				}
+				keys = append(keys, key)
+				windows = append(windows, newWindow(nil, spec, frame))
+				idx = len(windows) - 1
+			}
+			if windows[idx].findFunction(ctx, fn) < 0 {
+				windows[idx].Functions = append(windows[idx].Functions, fn)
+			}
+		}
+	}
+
+	for i, pe := range ap {
+		pe.EvalExpr = evalExprs[i]
+	}
+
+	src := proj.Source
+	for _, w := range windows {
+		var order []OrderBy
+		for _, expr := range w.Spec.PartitionClause {
+			order = append(order, OrderBy{
+				Inner:          &sqlparser.Order{Expr: expr, Direction: sqlparser.AscOrder},
+				SimplifiedExpr: expr,
+			})
+		}
+		for _, o := range w.Spec.OrderClause {
+			order = append(order, OrderBy{Inner: o, SimplifiedExpr: o.Expr})
+		}
+		if len(order) > 0 {
+			src = newOrdering(src, order)
+		}
+		w.Source = src
+		src = w
+	}
+	proj.Source = src
+}
+
+// needsWindowOperators returns true if the window functions of the query have to be evaluated at the vtgate level
+func needsWindowOperators(ctx *plancontext.PlanningContext, sel *sqlparser.Select, src Operator) bool {
+	if len(windowFunctions(sel.SelectExprs)) == 0 {
+		return false
+	}
+	_, isRoute := src.(*Route)
+	return !isRoute || !windowsAreShardLocal(ctx, sel)
+}
+
+// markWindowsUnsupported records why the window functions can't be evaluated at the vtgate level.
+// The error is returned unless the query can be sent to a single shard.
+func markWindowsUnsupported(ctx *plancontext.PlanningContext, err error) {
+	ctx.SemTable.NotSingleShardErr = err
+}
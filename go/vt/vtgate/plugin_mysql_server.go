@@ -79,6 +79,13 @@ var (
 	mysqlDrainOnTerm         bool
 
 	mysqlServerFlushDelay = 100 * time.Millisecond
+
+	mysqlServerCompressionAlgorithms []string
+	mysqlServerCompressionLevel      int
+
+	mysqlServerMaxOpenCursors      = 10
+	mysqlServerMaxTotalOpenCursors = 1000
+	mysqlServerCursorIdleTimeout   = 10 * time.Minute
 )
 
 func registerPluginFlags(fs *pflag.FlagSet) {
@@ -86,7 +93,7 @@ func registerPluginFlags(fs *pflag.FlagSet) {
 	utils.SetFlagStringVar(fs, &mysqlServerBindAddress, "mysql-server-bind-address", mysqlServerBindAddress, "Binds on this address when listening to MySQL binary protocol. Useful to restrict listening to 'localhost' only for instance.")
 	utils.SetFlagStringVar(fs, &mysqlServerSocketPath, "mysql-server-socket-path", mysqlServerSocketPath, "This option specifies the Unix socket file to use when listening for local connections. By default it will be empty and it won't listen to a unix socket")
 	utils.SetFlagStringVar(fs, &mysqlTCPVersion, "mysql-tcp-version", mysqlTCPVersion, "Select tcp, tcp4, or tcp6 to control the socket type.")
-	utils.SetFlagStringVar(fs, &mysqlAuthServerImpl, "mysql-auth-server-impl", mysqlAuthServerImpl, "Which auth server implementation to use. Options: none, ldap, clientcert, static, vault.")
+	utils.SetFlagStringVar(fs, &mysqlAuthServerImpl, "mysql-auth-server-impl", mysqlAuthServerImpl, "Which auth server implementation to use. Options: none, ldap, clientcert, static, vault, jwt.")
 	utils.SetFlagBoolVar(fs, &mysqlAllowClearTextWithoutTLS, "mysql-allow-clear-text-without-tls", mysqlAllowClearTextWithoutTLS, "If set, the server will allow the use of a clear text password over non-SSL connections.")
 	utils.SetFlagBoolVar(fs, &mysqlProxyProtocol, "proxy-protocol", mysqlProxyProtocol, "Enable HAProxy PROXY protocol on MySQL listener socket")
 	utils.SetFlagBoolVar(fs, &mysqlServerRequireSecureTransport, "mysql-server-require-secure-transport", mysqlServerRequireSecureTransport, "Reject insecure connections but only if mysql-server-ssl-cert and mysql-server-ssl-key are provided")
@@ -103,6 +110,11 @@ func registerPluginFlags(fs *pflag.FlagSet) {
 	fs.BoolVar(&mysqlConnBufferPooling, "mysql-server-pool-conn-read-buffers", mysqlConnBufferPooling, "If set, the server will pool incoming connection read buffers")
 	fs.DurationVar(&mysqlKeepAlivePeriod, "mysql-server-keepalive-period", mysqlKeepAlivePeriod, "TCP period between keep-alives")
 	utils.SetFlagDurationVar(fs, &mysqlServerFlushDelay, "mysql-server-flush-delay", mysqlServerFlushDelay, "Delay after which buffered response will be flushed to the client.")
+	utils.SetFlagStringSliceVar(fs, &mysqlServerCompressionAlgorithms, "mysql-server-compression-algorithms", mysqlServerCompressionAlgorithms, "Compression algorithms of the protocol that clients can negotiate over TCP. Options: zlib, zstd. Packets are not compressed by default.")
+	utils.SetFlagIntVar(fs, &mysqlServerCompressionLevel, "mysql-server-compression-level", mysqlServerCompressionLevel, "Level of the zlib compression of the packets sent to the clients, 0 for the default. With zstd, the level requested by the client is used.")
+	utils.SetFlagIntVar(fs, &mysqlServerMaxOpenCursors, "mysql-server-max-open-cursors", mysqlServerMaxOpenCursors, "Maximum number of read-only cursors a connection can have open at once, to fetch the rows of its prepared selects from a stream. 0 disables the cursors, and the rows are sent with the response of the statement.")
+	utils.SetFlagIntVar(fs, &mysqlServerMaxTotalOpenCursors, "mysql-server-max-total-open-cursors", mysqlServerMaxTotalOpenCursors, "Maximum number of read-only cursors the connections of vtgate can have open at once, each of which holds a stream. 0 means no limit.")
+	utils.SetFlagDurationVar(fs, &mysqlServerCursorIdleTimeout, "mysql-server-cursor-idle-timeout", mysqlServerCursorIdleTimeout, "Time after which a read-only cursor whose rows are not fetched is closed. 0 means no timeout.")
 	utils.SetFlagStringVar(fs, &mysqlDefaultWorkloadName, "mysql-default-workload", mysqlDefaultWorkloadName, "Default session workload (OLTP, OLAP, DBA)")
 	fs.BoolVar(&mysqlDrainOnTerm, "mysql-server-drain-onterm", mysqlDrainOnTerm, "If set, the server waits for --onterm-timeout for already connected clients to complete their in flight work")
 }
@@ -150,6 +162,15 @@ func (vh *vtgateHandler) ComResetConnection(c *mysql.Conn) {
 	}
 }
 
+// ComChangeUser releases the session of the previous user, and starts a new
+// one for the new user. The caller ID of the queries is derived from the
+// user of the connection.
+func (vh *vtgateHandler) ComChangeUser(c *mysql.Conn) {
+	vh.ComResetConnection(c)
+	c.ClientData = nil
+	fillInTxStatusFlags(c, vh.session(c))
+}
+
 func (vh *vtgateHandler) ConnectionClosed(c *mysql.Conn) {
 	// Rollback if there is an ongoing transaction. Ignore error.
 	defer func() {
@@ -176,12 +197,18 @@ func (vh *vtgateHandler) ConnectionClosed(c *mysql.Conn) {
 // Regexp to extract parent span id over the sql query
 var r = regexp.MustCompile(`/\*VT_SPAN_CONTEXT=(.*)\*/`)
 
+// Regexp to extract the W3C traceparent that sqlcommenter adds to the comments of the query
+var traceparentRegexp = regexp.MustCompile(`traceparent='([0-9a-f]{2}-[0-9a-f]{32}-[0-9a-f]{16}-[0-9a-f]{2})'`)
+
 // this function is here to make this logic easy to test by decoupling the logic from the `trace.NewSpan` and `trace.NewFromString` functions
 func startSpanTestable(ctx context.Context, query, label string,
 	newSpan func(context.Context, string) (trace.Span, context.Context),
 	newSpanFromString func(context.Context, string, string) (trace.Span, context.Context, error)) (trace.Span, context.Context, error) {
 	_, comments := sqlparser.SplitMarginComments(query)
 	match := r.FindStringSubmatch(comments.Leading)
+	if len(match) == 0 {
+		match = traceparentRegexp.FindStringSubmatch(comments.Leading + comments.Trailing)
+	}
 	span, ctx := getSpan(ctx, match, newSpan, label, newSpanFromString)
 
 	trace.AnnotateSQL(span, sqlparser.Preview(query))
@@ -197,7 +224,7 @@ func getSpan(ctx context.Context, match []string, newSpan func(context.Context,
 		if err == nil {
 			return span, ctx
 		}
-		log.Warningf("Unable to parse VT_SPAN_CONTEXT: %s", err.Error())
+		log.Warningf("Unable to parse the parent span context: %s", err.Error())
 	}
 	span, ctx = newSpan(ctx, label)
 	return span, ctx
@@ -229,6 +256,7 @@ func (vh *vtgateHandler) ComQuery(c *mysql.Conn, query string, callback func(*sq
 	defer span.Finish()
 
 	ctx = callinfo.MysqlCallInfo(ctx, c)
+	ctx = withQueryAttributes(ctx, c.QueryAttributes)
 
 	// Fill in the ImmediateCallerID with the UserData returned by
 	// the AuthServer plugin for that user. If nothing was
@@ -286,6 +314,7 @@ func (vh *vtgateHandler) ComQueryMulti(c *mysql.Conn, sql string, callback func(
 	defer span.Finish()
 
 	ctx = callinfo.MysqlCallInfo(ctx, c)
+	ctx = withQueryAttributes(ctx, c.QueryAttributes)
 
 	// Fill in the ImmediateCallerID with the UserData returned by
 	// the AuthServer plugin for that user. If nothing was
@@ -408,8 +437,15 @@ func (vh *vtgateHandler) ComPrepare(c *mysql.Conn, query string) ([]*querypb.Fie
 }
 
 func (vh *vtgateHandler) ComStmtExecute(c *mysql.Conn, prepare *mysql.PrepareData, callback func(*sqltypes.Result) error) error {
-	ctx, cancel := context.WithCancel(context.Background())
-	c.UpdateCancelCtx(cancel)
+	ctx, cancel := context.WithCancel(prepare.Context())
+	if prepare.CursorType&mysql.CursorTypeReadOnly == 0 {
+		c.UpdateCancelCtx(cancel)
+	} else {
+		// A cursor runs in the background, while the connection runs other
+		// statements, which KILL QUERY would cancel instead. It is canceled
+		// when the cursor is closed.
+		defer cancel()
+	}
 
 	if mysqlQueryTimeout != 0 {
 		ctx, cancel = context.WithTimeout(ctx, mysqlQueryTimeout)
@@ -417,6 +453,7 @@ func (vh *vtgateHandler) ComStmtExecute(c *mysql.Conn, prepare *mysql.PrepareDat
 	}
 
 	ctx = callinfo.MysqlCallInfo(ctx, c)
+	ctx = withQueryAttributes(ctx, c.QueryAttributes)
 
 	// Fill in the ImmediateCallerID with the UserData returned by
 	// the AuthServer plugin for that user. If nothing was
@@ -440,6 +477,19 @@ func (vh *vtgateHandler) ComStmtExecute(c *mysql.Conn, prepare *mysql.PrepareDat
 		}
 	}()
 
+	if prepare.CursorType&mysql.CursorTypeReadOnly != 0 && sqlparser.Preview(prepare.PrepareStmt) == sqlparser.StmtSelect {
+		// The rows of a cursor are streamed as the client fetches them,
+		// while it runs other statements on the connection. So the select
+		// runs with a copy of the session, that they don't share, which
+		// cannot be done with the connections of a transaction.
+		if session.InTransaction {
+			return sqlerror.NewSQLErrorFromError(vterrors.VT12001("read-only cursor in a transaction, the rows of a select can only be fetched with a cursor in autocommit mode"))
+		}
+		session = session.CloneVT()
+		_, err := vh.vtg.StreamExecute(ctx, vh, session, prepare.PrepareStmt, prepare.BindVars, callback)
+		return sqlerror.NewSQLErrorFromError(err)
+	}
+
 	if session.Options.Workload == querypb.ExecuteOptions_OLAP {
 		_, err := vh.vtg.StreamExecute(ctx, vh, session, prepare.PrepareStmt, prepare.BindVars, callback)
 		if err != nil {
@@ -541,6 +591,8 @@ type mysqlServer struct {
 	unixListener *mysql.Listener
 	sigChan      chan os.Signal
 	vtgateHandle *vtgateHandler
+	// cursorPool limits the cursors of both listeners.
+	cursorPool *mysql.CursorPool
 }
 
 // initTLSConfig inits tls config for the given mysql listener
@@ -605,9 +657,18 @@ func initMySQLProtocol(vtgate *VTGate) *mysqlServer {
 		log.Exitf("-mysql-tcp-version must be one of [tcp, tcp4, tcp6]")
 	}
 
+	for _, algorithm := range mysqlServerCompressionAlgorithms {
+		if err := mysql.ValidateCompression(algorithm, 0); err != nil {
+			log.Exitf("--mysql-server-compression-algorithms: %v", err)
+		}
+	}
+	if err := mysql.ValidateCompression(mysql.CompressionZlib, mysqlServerCompressionLevel); err != nil {
+		log.Exitf("--mysql-server-compression-level: %v", err)
+	}
+
 	// Create a Listener.
 	var err error
-	srv := &mysqlServer{}
+	srv := &mysqlServer{cursorPool: mysql.NewCursorPool(mysqlServerMaxTotalOpenCursors)}
 	srv.vtgateHandle = newVtgateHandler(vtgate)
 	if mysqlServerPort >= 0 {
 		srv.tcpListener, err = mysql.NewListener(
@@ -634,6 +695,11 @@ func initMySQLProtocol(vtgate *VTGate) *mysqlServer {
 			_ = initTLSConfig(context.Background(), srv, mysqlSslCert, mysqlSslKey, mysqlSslCa, mysqlSslCrl, mysqlSslServerCA, mysqlServerRequireSecureTransport, tlsVersion)
 		}
 		srv.tcpListener.AllowClearTextWithoutTLS.Store(mysqlAllowClearTextWithoutTLS)
+		srv.tcpListener.CompressionAlgorithms = mysqlServerCompressionAlgorithms
+		srv.tcpListener.CompressionLevel = mysqlServerCompressionLevel
+		srv.tcpListener.MaxOpenCursors = mysqlServerMaxOpenCursors
+		srv.tcpListener.CursorPool = srv.cursorPool
+		srv.tcpListener.CursorIdleTimeout = mysqlServerCursorIdleTimeout
 		// Check for the connection threshold
 		if mysqlSlowConnectWarnThreshold != 0 {
 			log.Infof("setting mysql slow connection threshold to %v", mysqlSlowConnectWarnThreshold)
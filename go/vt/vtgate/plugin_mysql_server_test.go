@@ -255,6 +255,19 @@ func TestSpanContextPassedInEvenAroundOtherComments(t *testing.T) {
 	assert.NoError(t, err)
 }
 
+func TestTraceparentPassedIn(t *testing.T) {
+	const traceparent = "00-0af7651916cd43dd8448eb211c80319c-b7ad6b7169203331-01"
+	_, _, err := startSpanTestable(context.Background(), "SELECT col1 FROM TABLE /*traceparent='"+traceparent+"'*/", "someLabel",
+		newSpanFail(t),
+		newFromStringExpect(t, traceparent))
+	assert.NoError(t, err)
+
+	_, _, err = startSpanTestable(context.Background(), "/*action='list',traceparent='"+traceparent+"'*/ SELECT col1 FROM TABLE", "someLabel",
+		newSpanFail(t),
+		newFromStringExpect(t, traceparent))
+	assert.NoError(t, err)
+}
+
 func TestSpanContextNotParsable(t *testing.T) {
 	hasRun := false
 	_, _, err := startSpanTestable(context.Background(), "/*VT_SPAN_CONTEXT=123*/SQL QUERY", "someLabel",
@@ -904,3 +917,133 @@ func TestGracefulShutdownWithTransaction(t *testing.T) {
 
 	require.True(t, mysqlConn.IsMarkedForClose())
 }
+
+func TestComChangeUser(t *testing.T) {
+	executor, _, _, _, _ := createExecutorEnv(t)
+
+	vh := newVtgateHandler(&VTGate{executor: executor, timings: timings, rowsReturned: rowsReturned, rowsAffected: rowsAffected, queryTextCharsProcessed: queryTextCharsProcessed})
+	th := &testHandler{}
+	listener, err := mysql.NewListener("tcp", "127.0.0.1:", mysql.NewAuthServerNone(), th, 0, 0, false, false, 0, 0)
+	require.NoError(t, err)
+	defer listener.Close()
+
+	mysqlConn := mysql.GetTestServerConn(listener)
+	mysqlConn.ConnectionID = 1
+	mysqlConn.UserData = &mysql.StaticUserData{}
+	vh.connections[1] = mysqlConn
+
+	err = vh.ComQuery(mysqlConn, "use TestExecutor", func(result *sqltypes.Result) error {
+		return nil
+	})
+	require.NoError(t, err)
+	err = vh.ComQuery(mysqlConn, "BEGIN", func(result *sqltypes.Result) error {
+		return nil
+	})
+	require.NoError(t, err)
+	session := vh.session(mysqlConn)
+	require.True(t, session.InTransaction)
+	require.NotZero(t, mysqlConn.StatusFlags&mysql.ServerStatusInTrans)
+	require.EqualValues(t, 1, vh.busyConnections.Load())
+
+	// The new user gets a new session, outside of the transaction.
+	vh.ComChangeUser(mysqlConn)
+	newSession := vh.session(mysqlConn)
+	assert.NotEqual(t, session.SessionUUID, newSession.SessionUUID)
+	assert.False(t, newSession.InTransaction)
+	assert.Empty(t, newSession.TargetString)
+	assert.Zero(t, mysqlConn.StatusFlags&mysql.ServerStatusInTrans)
+	assert.NotZero(t, mysqlConn.StatusFlags&mysql.ServerStatusAutocommit)
+	assert.EqualValues(t, 0, vh.busyConnections.Load())
+}
+
+func TestComStmtExecuteWithCursor(t *testing.T) {
+	executor, _, _, _, _ := createExecutorEnv(t)
+
+	vh := newVtgateHandler(&VTGate{executor: executor, timings: timings, rowsReturned: rowsReturned, rowsAffected: rowsAffected, queryTextCharsProcessed: queryTextCharsProcessed})
+	th := &testHandler{}
+	listener, err := mysql.NewListener("tcp", "127.0.0.1:", mysql.NewAuthServerNone(), th, 0, 0, false, false, 0, 0)
+	require.NoError(t, err)
+	defer listener.Close()
+
+	mysqlDefaultWorkload = int32(querypb.ExecuteOptions_OLTP)
+	mysqlConn := mysql.GetTestServerConn(listener)
+	mysqlConn.ConnectionID = 1
+	mysqlConn.UserData = &mysql.StaticUserData{}
+	vh.connections[1] = mysqlConn
+
+	err = vh.ComQuery(mysqlConn, "use TestExecutor", func(result *sqltypes.Result) error {
+		return nil
+	})
+	require.NoError(t, err)
+	prepare := &mysql.PrepareData{
+		PrepareStmt: "select id from user",
+		CursorType:  mysql.CursorTypeReadOnly,
+	}
+	execute := func() []*sqltypes.Result {
+		var results []*sqltypes.Result
+		err := vh.ComStmtExecute(mysqlConn, prepare, func(result *sqltypes.Result) error {
+			results = append(results, result)
+			return nil
+		})
+		require.NoError(t, err)
+		return results
+	}
+
+	// The rows of a cursor are streamed after the fields.
+	results := execute()
+	require.Greater(t, len(results), 1)
+	assert.NotEmpty(t, results[0].Fields)
+	assert.Empty(t, results[0].Rows)
+	assert.EqualValues(t, 0, vh.busyConnections.Load())
+
+	// In a transaction, the rows of a select cannot be fetched with a
+	// cursor.
+	err = vh.ComQuery(mysqlConn, "BEGIN", func(result *sqltypes.Result) error {
+		return nil
+	})
+	require.NoError(t, err)
+	err = vh.ComStmtExecute(mysqlConn, prepare, func(result *sqltypes.Result) error {
+		return nil
+	})
+	require.ErrorContains(t, err, "VT12001: unsupported: read-only cursor in a transaction")
+	var sqlErr *sqlerror.SQLError
+	require.ErrorAs(t, err, &sqlErr)
+	assert.Equal(t, sqlerror.ERNotSupportedYet, sqlErr.Number())
+
+	// Other statements are executed in the transaction.
+	prepare.PrepareStmt = "update user set a = 1 where id = 1"
+	results = execute()
+	require.Len(t, results, 1)
+	assert.Empty(t, results[0].Fields)
+}
+
+func TestComQueryAttributes(t *testing.T) {
+	executor, sbc1, _, _, _ := createExecutorEnv(t)
+
+	vh := newVtgateHandler(&VTGate{executor: executor, timings: timings, rowsReturned: rowsReturned, rowsAffected: rowsAffected, queryTextCharsProcessed: queryTextCharsProcessed})
+	th := &testHandler{}
+	listener, err := mysql.NewListener("tcp", "127.0.0.1:", mysql.NewAuthServerNone(), th, 0, 0, false, false, 0, 0)
+	require.NoError(t, err)
+	defer listener.Close()
+
+	mysqlConn := mysql.GetTestServerConn(listener)
+	mysqlConn.ConnectionID = 1
+	mysqlConn.UserData = &mysql.StaticUserData{}
+	vh.connections[1] = mysqlConn
+
+	err = vh.ComQuery(mysqlConn, "use TestExecutor", func(result *sqltypes.Result) error {
+		return nil
+	})
+	require.NoError(t, err)
+
+	// The attributes the client sent with the query are applied to it.
+	mysqlConn.QueryAttributes = map[string]*querypb.BindVariable{
+		"workload_name": sqltypes.StringBindVariable("batch"),
+	}
+	err = vh.ComQuery(mysqlConn, "select id from user where id = 1", func(result *sqltypes.Result) error {
+		return nil
+	})
+	require.NoError(t, err)
+	require.NotEmpty(t, sbc1.Options)
+	assert.Equal(t, "batch", sbc1.Options[len(sbc1.Options)-1].WorkloadName)
+}
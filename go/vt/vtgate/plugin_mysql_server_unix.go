@@ -34,6 +34,9 @@ func setupUnixSocket(srv *mysqlServer, authServer mysql.AuthServer, path string)
 	if err != nil {
 		return err
 	}
+	srv.unixListener.MaxOpenCursors = mysqlServerMaxOpenCursors
+	srv.unixListener.CursorPool = srv.cursorPool
+	srv.unixListener.CursorIdleTimeout = mysqlServerCursorIdleTimeout
 	// Listen for unix socket
 	go srv.unixListener.Accept()
 	return nil
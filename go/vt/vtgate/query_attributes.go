@@ -0,0 +1,61 @@
+/*
+Copyright 2025 The Vitess Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package vtgate
+
+import (
+	"context"
+	"strings"
+
+	querypb "vitess.io/vitess/go/vt/proto/query"
+)
+
+// QueryAttributeTarget is the query attribute that routes a query to a
+// keyspace, shard or tablet type, in the format of the target of a USE
+// statement, e.g. ks@replica. The target of the session is not changed.
+const QueryAttributeTarget = "target"
+
+type queryAttributesKey struct{}
+
+// withQueryAttributes returns a context that carries the query attributes
+// a MySQL client sent along with the query.
+func withQueryAttributes(ctx context.Context, attributes map[string]*querypb.BindVariable) context.Context {
+	if len(attributes) == 0 {
+		return ctx
+	}
+	return context.WithValue(ctx, queryAttributesKey{}, attributes)
+}
+
+// queryAttributesFromContext returns the query attributes of the context.
+func queryAttributesFromContext(ctx context.Context) map[string]*querypb.BindVariable {
+	attributes, _ := ctx.Value(queryAttributesKey{}).(map[string]*querypb.BindVariable)
+	return attributes
+}
+
+// queryAttributeValues returns the values of the query attributes that are
+// not NULL, by lower case name.
+func queryAttributeValues(attributes map[string]*querypb.BindVariable) map[string]string {
+	if len(attributes) == 0 {
+		return nil
+	}
+	values := make(map[string]string, len(attributes))
+	for name, bv := range attributes {
+		if bv.Type != querypb.Type_NULL_TYPE {
+			values[strings.ToLower(name)] = string(bv.Value)
+		}
+	}
+	return values
+}
@@ -0,0 +1,79 @@
+/*
+Copyright 2025 The Vitess Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package vtgate
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"vitess.io/vitess/go/sqltypes"
+	"vitess.io/vitess/go/test/utils"
+	querypb "vitess.io/vitess/go/vt/proto/query"
+	vtgatepb "vitess.io/vitess/go/vt/proto/vtgate"
+	"vitess.io/vitess/go/vt/sqlparser"
+	econtext "vitess.io/vitess/go/vt/vtgate/executorcontext"
+)
+
+func TestQueryAttributes(t *testing.T) {
+	ctx := utils.LeakCheckContext(t)
+	executor, primary, replica := createExecutorEnvWithPrimaryReplicaConn(t, ctx, 0)
+	session := econtext.NewSafeSession(&vtgatepb.Session{TargetString: KsTestUnsharded, Autocommit: true})
+
+	// The attributes route the query, and set the options sent to the tablet.
+	attrCtx := withQueryAttributes(ctx, map[string]*querypb.BindVariable{
+		"target":        sqltypes.StringBindVariable(KsTestUnsharded + "@replica"),
+		"WORKLOAD_NAME": sqltypes.StringBindVariable("batch"),
+		"priority":      sqltypes.Int64BindVariable(10),
+		"other":         sqltypes.NullBindVariable,
+	})
+	_, err := executor.Execute(attrCtx, nil, "TestQueryAttributes", session, "select age, city from user", nil, false)
+	require.NoError(t, err)
+	assert.EqualValues(t, 0, primary.ExecCount.Load())
+	assert.EqualValues(t, 1, replica.ExecCount.Load())
+	require.Len(t, replica.Options, 1)
+	assert.Equal(t, "batch", replica.Options[0].WorkloadName)
+	assert.Equal(t, "10", replica.Options[0].Priority)
+	assert.Equal(t, KsTestUnsharded, session.TargetString)
+
+	// The directives take precedence over the attributes.
+	_, err = executor.Execute(attrCtx, nil, "TestQueryAttributes", session, "select /*vt+ PRIORITY=20 */ age, city from user", nil, false)
+	require.NoError(t, err)
+	require.Len(t, replica.Options, 2)
+	assert.Equal(t, "20", replica.Options[1].Priority)
+
+	// Without attributes, the query goes to the target of the session.
+	_, err = executor.Execute(ctx, nil, "TestQueryAttributes", session, "select age, city from user", nil, false)
+	require.NoError(t, err)
+	assert.EqualValues(t, 1, primary.ExecCount.Load())
+
+	_, err = executor.Execute(withQueryAttributes(ctx, map[string]*querypb.BindVariable{
+		"priority": sqltypes.StringBindVariable("high"),
+	}), nil, "TestQueryAttributes", session, "select age, city from user", nil, false)
+	assert.ErrorIs(t, err, sqlparser.ErrInvalidPriority)
+
+	_, err = executor.Execute(withQueryAttributes(ctx, map[string]*querypb.BindVariable{
+		"target": sqltypes.StringBindVariable("unknown_ks"),
+	}), nil, "TestQueryAttributes", session, "select age, city from user", nil, false)
+	assert.ErrorContains(t, err, "VT05003: unknown database 'unknown_ks' in vschema")
+
+	// A transaction can't be routed to a replica.
+	session = econtext.NewSafeSession(&vtgatepb.Session{TargetString: KsTestUnsharded, InTransaction: true})
+	_, err = executor.Execute(attrCtx, nil, "TestQueryAttributes", session, "select age, city from user", nil, false)
+	assert.ErrorContains(t, err, "can't execute the given command because you have an active transaction")
+}
@@ -0,0 +1,217 @@
+/*
+Copyright 2025 The Vitess Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package vtgate
+
+import (
+	"container/list"
+	"slices"
+	"strings"
+	"sync"
+	"time"
+
+	"vitess.io/vitess/go/sqltypes"
+	"vitess.io/vitess/go/stats"
+	"vitess.io/vitess/go/vt/sqlparser"
+	"vitess.io/vitess/go/vt/vtgate/engine"
+)
+
+// The result cache keeps the results of read-only queries against replicas in vtgate, for
+// hot tables that are read much more often than they change, like configuration tables.
+//
+// Caching is opt-in: either for all the queries that only read from the tables listed in
+// --result-cache-tables, or per query with the RESULT_CACHE_TTL_MS directive. Results are
+// evicted when they expire, when the cache is full, when the schema tracker reports a
+// change to one of their tables, or when this vtgate executes a DML against one of them.
+// The cache does not follow the row changes of the tables, so writes made through other
+// vtgates, or directly on the tablets, are only seen once the cached results expire: the
+// TTL is the bound on how stale a result can be. Results are cached per immediate caller,
+// so that the table ACLs of the tablets apply to them.
+
+var (
+	resultCacheHits      = stats.NewCounter("ResultCacheHits", "Result cache hits")
+	resultCacheMisses    = stats.NewCounter("ResultCacheMisses", "Result cache misses")
+	resultCacheEvictions = stats.NewCounter("ResultCacheEvictions", "Result cache evictions")
+	resultCacheInvalids  = stats.NewCounter("ResultCacheInvalidations", "Result cache entries invalidated by table changes")
+)
+
+var _ engine.CachedResults = (*resultCache)(nil)
+
+type (
+	// resultCache is an LRU cache of query results bounded by their memory size.
+	resultCache struct {
+		mu      sync.Mutex
+		maxSize int64
+		size    int64
+		entries map[string]*list.Element
+		// lru holds the entries, most recently used first.
+		lru *list.List
+		// tables indexes the keys of the entries by the tables they were read from.
+		tables map[string]map[string]struct{}
+
+		now func() time.Time
+	}
+
+	resultCacheEntry struct {
+		key     string
+		tables  []string
+		result  *sqltypes.Result
+		expires time.Time
+		size    int64
+	}
+)
+
+func newResultCache(maxSize int64) *resultCache {
+	return &resultCache{
+		maxSize: maxSize,
+		entries: make(map[string]*list.Element),
+		lru:     list.New(),
+		tables:  make(map[string]map[string]struct{}),
+		now:     time.Now,
+	}
+}
+
+// Get implements the engine.CachedResults interface.
+func (rc *resultCache) Get(key string) (*sqltypes.Result, bool) {
+	rc.mu.Lock()
+	defer rc.mu.Unlock()
+
+	elem, ok := rc.entries[key]
+	if !ok {
+		resultCacheMisses.Add(1)
+		return nil, false
+	}
+	entry := elem.Value.(*resultCacheEntry)
+	if !rc.now().Before(entry.expires) {
+		rc.remove(elem)
+		resultCacheMisses.Add(1)
+		return nil, false
+	}
+	rc.lru.MoveToFront(elem)
+	resultCacheHits.Add(1)
+	// The cached result is shared between executions, so it is never modified.
+	return entry.result.ShallowCopy(), true
+}
+
+// Set implements the engine.CachedResults interface.
+func (rc *resultCache) Set(key string, tables []string, ttl time.Duration, result *sqltypes.Result) {
+	entry := &resultCacheEntry{
+		key:     key,
+		tables:  tables,
+		result:  result.Copy(),
+		expires: rc.now().Add(ttl),
+	}
+	entry.size = int64(len(key)) + entry.result.CachedSize(true)
+	if entry.size > rc.maxSize {
+		return
+	}
+
+	rc.mu.Lock()
+	defer rc.mu.Unlock()
+
+	if elem, ok := rc.entries[key]; ok {
+		rc.remove(elem)
+	}
+	rc.entries[key] = rc.lru.PushFront(entry)
+	rc.size += entry.size
+	for _, table := range tables {
+		keys, ok := rc.tables[table]
+		if !ok {
+			keys = make(map[string]struct{})
+			rc.tables[table] = keys
+		}
+		keys[key] = struct{}{}
+	}
+
+	for rc.size > rc.maxSize {
+		rc.remove(rc.lru.Back())
+		resultCacheEvictions.Add(1)
+	}
+}
+
+// invalidate evicts the results read from any of the given tables of a keyspace.
+func (rc *resultCache) invalidate(keyspace string, tables []string) {
+	rc.mu.Lock()
+	defer rc.mu.Unlock()
+
+	for _, table := range tables {
+		for key := range rc.tables[keyspace+"."+table] {
+			rc.remove(rc.entries[key])
+			resultCacheInvalids.Add(1)
+		}
+	}
+}
+
+// invalidateTables evicts the results read from any of the given qualified tables.
+func (rc *resultCache) invalidateTables(tables []string) {
+	for _, table := range tables {
+		keyspace, name, _ := strings.Cut(table, ".")
+		rc.invalidate(keyspace, []string{name})
+	}
+}
+
+func (rc *resultCache) remove(elem *list.Element) {
+	entry := rc.lru.Remove(elem).(*resultCacheEntry)
+	delete(rc.entries, entry.key)
+	rc.size -= entry.size
+	for _, table := range entry.tables {
+		keys := rc.tables[table]
+		delete(keys, entry.key)
+		if len(keys) == 0 {
+			delete(rc.tables, table)
+		}
+	}
+}
+
+// len returns the number of cached results.
+func (rc *resultCache) len() int {
+	rc.mu.Lock()
+	defer rc.mu.Unlock()
+	return len(rc.entries)
+}
+
+// resultCacheTTL returns how long the results of a statement can be cached for, or zero
+// if they cannot be cached.
+func (e *Executor) resultCacheTTL(stmt sqlparser.Statement, plan *engine.Plan, qh sqlparser.QueryHints, bindVarNeeds *sqlparser.BindVarNeeds) time.Duration {
+	if e.resultCache == nil || len(plan.TablesUsed) == 0 {
+		return 0
+	}
+	sel, ok := stmt.(sqlparser.SelectStatement)
+	if !ok || sel.GetLock() != sqlparser.NoLock {
+		return 0
+	}
+	// The results of queries depending on the session, like the ones using variables
+	// or functions like NOW(), cannot be shared.
+	if bindVarNeeds != nil && bindVarNeeds.NumberOfRewrites() > 0 {
+		return 0
+	}
+	if qh.ResultCacheTTL != nil {
+		return time.Duration(*qh.ResultCacheTTL) * time.Millisecond
+	}
+	for _, table := range plan.TablesUsed {
+		if !e.isResultCacheTable(table) {
+			return 0
+		}
+	}
+	return e.config.ResultCacheTTL
+}
+
+// isResultCacheTable returns true if the results of the given qualified table are cached
+// by default. Tables can be configured with or without their keyspace.
+func (e *Executor) isResultCacheTable(table string) bool {
+	_, name, _ := strings.Cut(table, ".")
+	return slices.Contains(e.config.ResultCacheTables, table) || slices.Contains(e.config.ResultCacheTables, name)
+}
@@ -0,0 +1,152 @@
+/*
+Copyright 2025 The Vitess Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package vtgate
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"vitess.io/vitess/go/sqltypes"
+	"vitess.io/vitess/go/vt/vttablet/sandboxconn"
+
+	topodatapb "vitess.io/vitess/go/vt/proto/topodata"
+	vtgatepb "vitess.io/vitess/go/vt/proto/vtgate"
+)
+
+func TestResultCacheGetSet(t *testing.T) {
+	rc := newResultCache(1 << 20)
+	now := time.Now()
+	rc.now = func() time.Time { return now }
+
+	result := sqltypes.MakeTestResult(sqltypes.MakeTestFields("id", "int64"), "1")
+	rc.Set("a", []string{"ks.t1"}, time.Second, result)
+
+	got, ok := rc.Get("a")
+	require.True(t, ok)
+	assert.Equal(t, result, got)
+	_, ok = rc.Get("b")
+	assert.False(t, ok)
+
+	// Results expire after their TTL.
+	now = now.Add(time.Second)
+	_, ok = rc.Get("a")
+	assert.False(t, ok)
+	assert.Zero(t, rc.len())
+	assert.Zero(t, rc.size)
+	assert.Empty(t, rc.tables)
+}
+
+func TestResultCacheEviction(t *testing.T) {
+	result := sqltypes.MakeTestResult(sqltypes.MakeTestFields("id", "int64"), "1")
+	entrySize := int64(len("a")) + result.Copy().CachedSize(true)
+	rc := newResultCache(2 * entrySize)
+
+	rc.Set("a", nil, time.Minute, result)
+	rc.Set("b", nil, time.Minute, result)
+	// Using "a" makes "b" the least recently used result.
+	_, ok := rc.Get("a")
+	require.True(t, ok)
+	rc.Set("c", nil, time.Minute, result)
+
+	_, ok = rc.Get("b")
+	assert.False(t, ok)
+	_, ok = rc.Get("a")
+	assert.True(t, ok)
+	_, ok = rc.Get("c")
+	assert.True(t, ok)
+	assert.Equal(t, 2*entrySize, rc.size)
+
+	// Results larger than the cache are never cached.
+	rc = newResultCache(entrySize - 1)
+	rc.Set("a", nil, time.Minute, result)
+	assert.Zero(t, rc.len())
+}
+
+func TestResultCacheInvalidate(t *testing.T) {
+	rc := newResultCache(1 << 20)
+	result := sqltypes.MakeTestResult(sqltypes.MakeTestFields("id", "int64"), "1")
+	rc.Set("a", []string{"ks.t1"}, time.Minute, result)
+	rc.Set("b", []string{"ks.t1", "ks.t2"}, time.Minute, result)
+	rc.Set("c", []string{"ks.t2"}, time.Minute, result)
+	rc.Set("d", []string{"other.t1"}, time.Minute, result)
+
+	rc.invalidate("ks", []string{"t1"})
+	_, ok := rc.Get("a")
+	assert.False(t, ok)
+	_, ok = rc.Get("b")
+	assert.False(t, ok)
+	_, ok = rc.Get("c")
+	assert.True(t, ok)
+	_, ok = rc.Get("d")
+	assert.True(t, ok)
+
+	rc.invalidateTables([]string{"ks.t2", "other.t1"})
+	assert.Zero(t, rc.len())
+	assert.Empty(t, rc.tables)
+}
+
+func TestExecutorResultCache(t *testing.T) {
+	eConfig := createExecutorConfigWithNormalizer()
+	eConfig.ResultCacheSize = 1 << 20
+	eConfig.ResultCacheTTL = time.Minute
+	eConfig.ResultCacheTables = []string{"main1"}
+
+	var replica *sandboxconn.SandboxConn
+	executor, ctx := createExecutorEnvCallback(t, eConfig, func(shard, ks string, tabletType topodatapb.TabletType, conn *sandboxconn.SandboxConn) {
+		if ks == KsTestUnsharded && tabletType == topodatapb.TabletType_REPLICA {
+			replica = conn
+		}
+	})
+	replicaSession := &vtgatepb.Session{TargetString: KsTestUnsharded + "@replica", Autocommit: true}
+
+	exec := func(session *vtgatepb.Session, sql string) {
+		t.Helper()
+		_, err := executorExec(ctx, executor, session, sql, nil)
+		require.NoError(t, err)
+	}
+
+	// The results of a configured table are cached for every value.
+	exec(replicaSession, "select id from main1 where id = 1")
+	exec(replicaSession, "select id from main1 where id = 1")
+	assert.EqualValues(t, 1, replica.ExecCount.Load())
+	exec(replicaSession, "select id from main1 where id = 2")
+	assert.EqualValues(t, 2, replica.ExecCount.Load())
+
+	// Other tables are only cached when asked to.
+	exec(replicaSession, "select id from simple")
+	exec(replicaSession, "select id from simple")
+	assert.EqualValues(t, 4, replica.ExecCount.Load())
+	exec(replicaSession, "select /*vt+ RESULT_CACHE_TTL_MS=60000 */ id from simple")
+	exec(replicaSession, "select /*vt+ RESULT_CACHE_TTL_MS=60000 */ id from simple")
+	assert.EqualValues(t, 5, replica.ExecCount.Load())
+
+	// Reads from the primary are never cached.
+	primarySession := &vtgatepb.Session{TargetString: KsTestUnsharded, Autocommit: true}
+	exec(primarySession, "select id from main1 where id = 1")
+	exec(primarySession, "select id from main1 where id = 1")
+	assert.EqualValues(t, 5, replica.ExecCount.Load())
+
+	// Writes invalidate the results of their tables.
+	exec(primarySession, "update main1 set id = 3 where id = 1")
+	exec(replicaSession, "select id from main1 where id = 1")
+	assert.EqualValues(t, 6, replica.ExecCount.Load())
+	exec(replicaSession, "select /*vt+ RESULT_CACHE_TTL_MS=60000 */ id from simple")
+	assert.EqualValues(t, 6, replica.ExecCount.Load())
+}
@@ -50,6 +50,9 @@ type (
 		ctx    context.Context
 		signal func() // a function that we'll call whenever we have new schema data
 
+		// tablesChanged is called with the tables and views of a keyspace whose schema changed.
+		tablesChanged func(keyspace string, tables []string)
+
 		// map of keyspace currently tracked
 		trackedMu    sync.Mutex
 		tracked      map[keyspaceStr]*updateController
@@ -318,6 +321,13 @@ func (t *Tracker) UDFs(ks string) []string {
 }
 
 func (t *Tracker) updateSchema(th *discovery.TabletHealth) bool {
+	t.trackedMu.Lock()
+	tablesChanged := t.tablesChanged
+	t.trackedMu.Unlock()
+	if tablesChanged != nil && (len(th.Stats.TableSchemaChanged) > 0 || len(th.Stats.ViewSchemaChanged) > 0) {
+		tablesChanged(th.Target.Keyspace, slices.Concat(th.Stats.TableSchemaChanged, th.Stats.ViewSchemaChanged))
+	}
+
 	success := true
 	if th.Stats.TableSchemaChanged != nil {
 		success = t.updatedTableSchema(th)
@@ -487,6 +497,14 @@ func (t *Tracker) RegisterSignalReceiver(f func()) {
 	t.signal = f
 }
 
+// RegisterTableChangeReceiver allows a function to register to be called with the tables
+// and views of a keyspace, whenever their schema changes
+func (t *Tracker) RegisterTableChangeReceiver(f func(keyspace string, tables []string)) {
+	t.trackedMu.Lock()
+	defer t.trackedMu.Unlock()
+	t.tablesChanged = f
+}
+
 // AddNewKeyspace adds keyspace to the tracker.
 func (t *Tracker) AddNewKeyspace(conn queryservice.QueryService, target *querypb.Target) error {
 	updateController := t.newUpdateController()
@@ -53,9 +53,7 @@ func (a *analyzer) checkForInvalidConstructs(cursor *sqlparser.Cursor) error {
 			return ShardedError{Inner: &UnsupportedConstruct{errString: "REPLACE INTO with sharded keyspace"}}
 		}
 	case *sqlparser.OverClause:
-		if !a.singleUnshardedKeyspace {
-			return NotSingleShardError{Inner: &UnsupportedConstruct{errString: "OVER CLAUSE with sharded keyspace"}}
-		}
+		return a.checkOverClause()
 	}
 
 	return nil
@@ -94,6 +92,19 @@ func (a *analyzer) checkSubqueryColumns(parent sqlparser.SQLNode, subq *sqlparse
 	return nil
 }
 
+// checkOverClause checks where window functions are used. Window functions in the SELECT
+// expressions of the outermost query can be evaluated at the vtgate level if they can't be
+// pushed down, everything else has to be sent to a single shard.
+func (a *analyzer) checkOverClause() error {
+	if a.singleUnshardedKeyspace {
+		return nil
+	}
+	if a.inProjection > 0 && a.scoper.currentScope().parent == nil {
+		return nil
+	}
+	return NotSingleShardError{Inner: &UnsupportedConstruct{errString: "OVER CLAUSE with sharded keyspace"}}
+}
+
 func checkDerived(node *sqlparser.DerivedTable) error {
 	if node.Lateral {
 		return vterrors.VT12001("lateral derived tables")
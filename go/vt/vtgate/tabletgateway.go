@@ -58,9 +58,11 @@ var (
 	retryCount = 2
 
 	// configuration flags for the tablet balancer
-	balancerEnabled     bool
-	balancerVtgateCells []string
-	balancerKeyspaces   []string
+	balancerEnabled      bool
+	balancerMode         = balancer.ModeCell
+	balancerVtgateCells  []string
+	balancerKeyspaces    []string
+	balancerLatencyDecay = 10 * time.Second
 
 	logCollations = logutil.NewThrottledLogger("CollationInconsistent", 1*time.Minute)
 )
@@ -71,8 +73,10 @@ func init() {
 		fs.DurationVar(&initialTabletTimeout, "gateway_initial_tablet_timeout", 30*time.Second, "At startup, the tabletGateway will wait up to this duration to get at least one tablet per keyspace/shard/tablet type")
 		fs.IntVar(&retryCount, "retry-count", 2, "retry count")
 		fs.BoolVar(&balancerEnabled, "enable-balancer", false, "Enable the tablet balancer to evenly spread query load for a given tablet type")
+		fs.StringVar(&balancerMode, "balancer-mode", balancerMode, "When in balanced mode, how to pick the tablets: 'cell' evenly spreads the load across the cells with vtgates, 'latency' prefers the tablets with the lowest observed latency and fewest outstanding queries")
 		fs.StringSliceVar(&balancerVtgateCells, "balancer-vtgate-cells", []string{}, "When in balanced mode, a comma-separated list of cells that contain vtgates (required)")
 		fs.StringSliceVar(&balancerKeyspaces, "balancer-keyspaces", []string{}, "When in balanced mode, a comma-separated list of keyspaces for which to use the balancer (optional)")
+		fs.DurationVar(&balancerLatencyDecay, "balancer-latency-decay", balancerLatencyDecay, "When in latency balancer mode, the time window over which the observed latency of a tablet decays")
 	})
 }
 
@@ -167,10 +171,23 @@ func (gw *TabletGateway) setupBuffering(ctx context.Context) {
 }
 
 func (gw *TabletGateway) setupBalancer(ctx context.Context) {
-	if len(balancerVtgateCells) == 0 {
-		log.Exitf("balancer-vtgate-cells is required for balanced mode")
+	switch balancerMode {
+	case balancer.ModeCell:
+		if len(balancerVtgateCells) == 0 {
+			log.Exitf("balancer-vtgate-cells is required for balanced mode")
+		}
+		gw.balancer = balancer.NewTabletBalancer(gw.localCell, balancerVtgateCells)
+	case balancer.ModeLatency:
+		if balancerLatencyDecay <= 0 {
+			log.Exitf("balancer-latency-decay must be positive for the latency balancer mode")
+		}
+		gw.balancer = balancer.NewLatencyBalancer(balancerLatencyDecay, func(alias *topodatapb.TabletAlias) bool {
+			_, err := gw.hc.GetTabletHealthByAlias(alias)
+			return err == nil
+		})
+	default:
+		log.Exitf("unknown balancer-mode %q, expected %q or %q", balancerMode, balancer.ModeCell, balancer.ModeLatency)
 	}
-	gw.balancer = balancer.NewTabletBalancer(gw.localCell, balancerVtgateCells)
 }
 
 // QueryServiceByAlias satisfies the Gateway interface
@@ -279,7 +296,7 @@ func (gw *TabletGateway) DebugBalancerHandler(w http.ResponseWriter, r *http.Req
 // withRetry also adds shard information to errors returned from the inner QueryService, so
 // withShardError should not be combined with withRetry.
 func (gw *TabletGateway) withRetry(ctx context.Context, target *querypb.Target, _ queryservice.QueryService,
-	_ string, inTransaction bool, inner func(ctx context.Context, target *querypb.Target, conn queryservice.QueryService) (bool, error)) error {
+	name string, inTransaction bool, streaming bool, inner func(ctx context.Context, target *querypb.Target, conn queryservice.QueryService) (bool, error)) error {
 
 	// for transactions, we connect to a specific tablet instead of letting gateway choose one
 	if inTransaction && target.TabletType != topodatapb.TabletType_PRIMARY {
@@ -360,6 +377,7 @@ func (gw *TabletGateway) withRetry(ctx context.Context, target *querypb.Target,
 		}
 
 		var th *discovery.TabletHealth
+		var tracker balancer.QueryTracker
 
 		useBalancer := balancerEnabled
 		if balancerEnabled && len(balancerKeyspaces) > 0 {
@@ -375,6 +393,7 @@ func (gw *TabletGateway) withRetry(ctx context.Context, target *querypb.Target,
 			}
 
 			th = gw.balancer.Pick(target, tablets)
+			tracker, _ = gw.balancer.(balancer.QueryTracker)
 
 		} else {
 			gw.shuffleTablets(gw.localCell, tablets)
@@ -408,7 +427,17 @@ func (gw *TabletGateway) withRetry(ctx context.Context, target *querypb.Target,
 
 		startTime := time.Now()
 		var canRetry bool
+		if tracker != nil {
+			tracker.QueryStarted(th)
+		}
 		canRetry, err = inner(ctx, target, th.Conn)
+		if tracker != nil {
+			tracker.QueryFinished(th)
+			// The duration of a stream depends on how much it returns, not on the tablet.
+			if canRetry || !streaming {
+				tracker.ObserveLatency(th, time.Since(startTime), canRetry)
+			}
+		}
 		gw.updateStats(target, startTime, err)
 		if canRetry {
 			invalidTablets[topoproto.TabletAliasString(tabletLastUsed.Alias)] = true
@@ -421,7 +450,7 @@ func (gw *TabletGateway) withRetry(ctx context.Context, target *querypb.Target,
 
 // withShardError adds shard information to errors returned from the inner QueryService.
 func (gw *TabletGateway) withShardError(ctx context.Context, target *querypb.Target, conn queryservice.QueryService,
-	_ string, _ bool, inner func(ctx context.Context, target *querypb.Target, conn queryservice.QueryService) (bool, error)) error {
+	_ string, _ bool, _ bool, inner func(ctx context.Context, target *querypb.Target, conn queryservice.QueryService) (bool, error)) error {
 	_, err := inner(ctx, target, conn)
 	return NewShardError(err, target)
 }
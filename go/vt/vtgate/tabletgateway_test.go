@@ -19,6 +19,7 @@ package vtgate
 import (
 	"context"
 	"fmt"
+	"net/http/httptest"
 	"strings"
 	"testing"
 
@@ -37,6 +38,7 @@ import (
 	vtrpcpb "vitess.io/vitess/go/vt/proto/vtrpc"
 	"vitess.io/vitess/go/vt/topo"
 	"vitess.io/vitess/go/vt/vterrors"
+	"vitess.io/vitess/go/vt/vtgate/balancer"
 	"vitess.io/vitess/go/vt/vttablet/sandboxconn"
 )
 
@@ -194,6 +196,81 @@ func TestTabletGatewayReplicaTransactionError(t *testing.T) {
 	verifyContainsError(t, err, "query service can only be used for non-transactional queries on replicas", vtrpcpb.Code_INTERNAL)
 }
 
+func TestTabletGatewayLatencyBalancer(t *testing.T) {
+	ctx := utils.LeakCheckContext(t)
+
+	balancerEnabled = true
+	balancerMode = balancer.ModeLatency
+	defer func() {
+		balancerEnabled = false
+		balancerMode = balancer.ModeCell
+	}()
+
+	target := &querypb.Target{Keyspace: "ks", Shard: "0", TabletType: topodatapb.TabletType_REPLICA}
+	hc := discovery.NewFakeHealthCheck(nil)
+	ts := &econtext.FakeTopoServer{}
+	tg := NewTabletGateway(ctx, hc, ts, "cell")
+	defer tg.Close(ctx)
+
+	sc1 := hc.AddTestTablet("cell", "1.1.1.1", 1001, "ks", "0", topodatapb.TabletType_REPLICA, true, 10, nil)
+	sc2 := hc.AddTestTablet("cell2", "1.1.1.1", 1002, "ks", "0", topodatapb.TabletType_REPLICA, true, 10, nil)
+
+	for i := 0; i < 10; i++ {
+		_, err := tg.Execute(ctx, target, "query", nil, 0, 0, nil)
+		require.NoError(t, err)
+	}
+	assert.EqualValues(t, 10, sc1.ExecCount.Load()+sc2.ExecCount.Load())
+
+	// a tablet failure is retried on the other tablet and penalized
+	sc1.MustFailCodes[vtrpcpb.Code_FAILED_PRECONDITION] = 1
+	sc2.MustFailCodes[vtrpcpb.Code_FAILED_PRECONDITION] = 1
+	_, err := tg.Execute(ctx, target, "query", nil, 0, 0, nil)
+	verifyContainsError(t, err, "target: ks.0.replica", vtrpcpb.Code_FAILED_PRECONDITION)
+
+	w := httptest.NewRecorder()
+	tg.DebugBalancerHandler(w, httptest.NewRequest("GET", "/debug/balancer", nil))
+	body := w.Body.String()
+	assert.Contains(t, body, "Mode: latency")
+	assert.Contains(t, body, `"Outstanding": 0`)
+	assert.NotContains(t, body, `"Outstanding": 1`)
+	assert.Contains(t, body, `"Failures": 1`)
+}
+
+func TestTabletGatewayLatencyBalancerStreams(t *testing.T) {
+	ctx := utils.LeakCheckContext(t)
+
+	balancerEnabled = true
+	balancerMode = balancer.ModeLatency
+	defer func() {
+		balancerEnabled = false
+		balancerMode = balancer.ModeCell
+	}()
+
+	target := &querypb.Target{Keyspace: "ks", Shard: "0", TabletType: topodatapb.TabletType_REPLICA}
+	hc := discovery.NewFakeHealthCheck(nil)
+	ts := &econtext.FakeTopoServer{}
+	tg := NewTabletGateway(ctx, hc, ts, "cell")
+	defer tg.Close(ctx)
+	hc.AddTestTablet("cell", "1.1.1.1", 1001, "ks", "0", topodatapb.TabletType_REPLICA, true, 10, nil)
+
+	queries := func() string {
+		w := httptest.NewRecorder()
+		tg.DebugBalancerHandler(w, httptest.NewRequest("GET", "/debug/balancer", nil))
+		return w.Body.String()
+	}
+
+	// The duration of a stream is not the latency of the tablet.
+	err := tg.StreamExecute(ctx, target, "query", nil, 0, 0, nil, func(qr *sqltypes.Result) error {
+		return nil
+	})
+	require.NoError(t, err)
+	assert.NotContains(t, queries(), `"Queries": 1`)
+
+	_, err = tg.Execute(ctx, target, "query", nil, 0, 0, nil)
+	require.NoError(t, err)
+	assert.Contains(t, queries(), `"Queries": 1`)
+}
+
 func testTabletGatewayGeneric(t *testing.T, ctx context.Context, f func(ctx context.Context, tg *TabletGateway, target *querypb.Target) error, verifyExpectedCount func(t *testing.T, sc *sandboxconn.SandboxConn, want int64)) {
 	t.Helper()
 	testTabletGatewayGenericHelper(t, ctx, f, verifyExpectedCount)
@@ -392,7 +469,7 @@ func TestWithRetry(t *testing.T) {
 	}
 	for _, tt := range testcases {
 		t.Run(tt.name, func(t *testing.T) {
-			err := tg.withRetry(ctx, tt.target, nil, "", tt.inTransaction, tt.inner)
+			err := tg.withRetry(ctx, tt.target, nil, "", tt.inTransaction, false, tt.inner)
 			if tt.expectedErr == "" {
 				require.NoError(t, err)
 			} else {
@@ -31,6 +31,7 @@ var (
 	_ Hashing         = (*Binary)(nil)
 	_ ParamValidating = (*Binary)(nil)
 	_ Sequential      = (*Binary)(nil)
+	_ RangeMappable   = (*Binary)(nil)
 )
 
 // Binary is a vindex that converts binary bits to a keyspace id.
@@ -123,6 +124,28 @@ func (vind *Binary) RangeMap(ctx context.Context, vcursor VCursor, startId sqlty
 	return out, nil
 }
 
+// RangeMapOpen implements RangeMappable.
+func (vind *Binary) RangeMapOpen(ctx context.Context, vcursor VCursor, startId sqltypes.Value, endId sqltypes.Value) ([]key.ShardDestination, error) {
+	var startKsId, endKsId []byte
+	var err error
+	if !startId.IsNull() {
+		startKsId, err = vind.Hash(startId)
+		if err != nil {
+			return nil, err
+		}
+	}
+	if !endId.IsNull() {
+		endKsId, err = vind.Hash(endId)
+		if err != nil {
+			return nil, err
+		}
+		// The end of a key range is exclusive, and keyspace ids are compared without
+		// their trailing zeros: this is the closest end that still includes endId.
+		endKsId = append(endKsId, 0x01)
+	}
+	return []key.ShardDestination{key.DestinationKeyRange{KeyRange: key.NewKeyRange(startKsId, endKsId)}}, nil
+}
+
 // UnknownParams implements the ParamValidating interface.
 func (vind *Binary) UnknownParams() []string {
 	return vind.unknownParams
@@ -161,3 +161,14 @@ func TestBinaryRangeMap(t *testing.T) {
 	assert.Equal(t, want, got[0].String())
 
 }
+
+func TestBinaryRangeMapOpen(t *testing.T) {
+	got, err := binOnlyVindex.(RangeMappable).RangeMapOpen(context.Background(), nil, sqltypes.NewVarBinary("\x40"), sqltypes.NULL)
+	require.NoError(t, err)
+	assert.Equal(t, "DestinationKeyRange(40-)", got[0].String())
+
+	// The upper bound is inclusive.
+	got, err = binOnlyVindex.(RangeMappable).RangeMapOpen(context.Background(), nil, sqltypes.NULL, sqltypes.NewVarBinary("\x40"))
+	require.NoError(t, err)
+	assert.Equal(t, "DestinationKeyRange(-4001)", got[0].String())
+}
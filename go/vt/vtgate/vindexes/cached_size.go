@@ -383,6 +383,29 @@ func (cached *NumericLookupTable) CachedSize(alloc bool) int64 {
 	size += hack.RuntimeMapSize(*cached)
 	return size
 }
+func (cached *NumericRange) CachedSize(alloc bool) int64 {
+	if cached == nil {
+		return int64(0)
+	}
+	size := int64(0)
+	if alloc {
+		size += int64(64)
+	}
+	// field name string
+	size += hack.RuntimeAllocSize(int64(len(cached.name)))
+	// field ranges []vitess.io/vitess/go/vt/vtgate/vindexes.numericRange
+	{
+		size += hack.RuntimeAllocSize(int64(cap(cached.ranges)) * int64(40))
+	}
+	// field unknownParams []string
+	{
+		size += hack.RuntimeAllocSize(int64(cap(cached.unknownParams)) * int64(16))
+		for _, elem := range cached.unknownParams {
+			size += hack.RuntimeAllocSize(int64(len(elem)))
+		}
+	}
+	return size
+}
 
 //go:nocheckptr
 func (cached *NumericStaticMap) CachedSize(alloc bool) int64 {
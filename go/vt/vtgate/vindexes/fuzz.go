@@ -87,6 +87,7 @@ var availableVindexes = []string{"binary",
 	"cfc",
 	"numeric",
 	"numeric_static_map",
+	"numeric_range",
 	"xxhash",
 	"unicode_loose_xxhash",
 	"reverse_bits",
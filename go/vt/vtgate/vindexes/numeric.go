@@ -21,6 +21,9 @@ import (
 	"context"
 	"encoding/binary"
 	"fmt"
+	"math"
+	"strconv"
+	"strings"
 
 	"vitess.io/vitess/go/sqltypes"
 	"vitess.io/vitess/go/vt/key"
@@ -32,6 +35,7 @@ var (
 	_ Hashing         = (*Numeric)(nil)
 	_ ParamValidating = (*Numeric)(nil)
 	_ Sequential      = (*Numeric)(nil)
+	_ RangeMappable   = (*Numeric)(nil)
 )
 
 // Numeric defines a bit-pattern mapping of a uint64 to the KeyspaceId.
@@ -123,6 +127,101 @@ func (vind *Numeric) RangeMap(ctx context.Context, vcursor VCursor, startId sqlt
 	return out, nil
 }
 
+// RangeMapOpen implements RangeMappable. The ids are compared as unsigned integers.
+func (vind *Numeric) RangeMapOpen(ctx context.Context, vcursor VCursor, startId sqltypes.Value, endId sqltypes.Value) ([]key.ShardDestination, error) {
+	var out []key.ShardDestination
+	for _, r := range uint64OpenRanges(startId, endId) {
+		var startKsId, endKsId []byte
+		if r.from != 0 {
+			startKsId = key.Uint64Key(r.from).Bytes()
+		}
+		// The end of a key range is exclusive.
+		if r.to != math.MaxUint64 {
+			endKsId = key.Uint64Key(r.to + 1).Bytes()
+		}
+		out = append(out, key.DestinationKeyRange{KeyRange: key.NewKeyRange(startKsId, endKsId)})
+	}
+	if len(out) == 0 {
+		return []key.ShardDestination{key.DestinationNone{}}, nil
+	}
+	return out, nil
+}
+
+// uint64Range is a range of unsigned ids, both inclusive.
+type uint64Range struct {
+	from, to uint64
+}
+
+// uint64OpenRanges returns the ranges of unsigned ids that hold the ids from startId
+// to endId. Negative ids are cast to the upper half of the unsigned ids, so a range
+// without a lower bound also covers that half.
+func uint64OpenRanges(startId, endId sqltypes.Value) []uint64Range {
+	from, fromBounded := uint64RangeBound(startId, false)
+	to, toBounded := uint64RangeBound(endId, true)
+	if !toBounded {
+		return []uint64Range{{from: from, to: math.MaxUint64}}
+	}
+	var ranges []uint64Range
+	if from <= to {
+		ranges = append(ranges, uint64Range{from: from, to: to})
+	}
+	if !fromBounded && to < 1<<63 {
+		ranges = append(ranges, uint64Range{from: 1 << 63, to: math.MaxUint64})
+	}
+	return ranges
+}
+
+// uint64RangeBound returns the unsigned value of a range bound, rounded down for the
+// start of the range and up for its end, so that the range still holds all the ids
+// it matches. A bound that cannot be mapped to an id, like NULL, a negative number or
+// a float too large to be exact, leaves the range unbounded on its side.
+func uint64RangeBound(v sqltypes.Value, end bool) (uint64, bool) {
+	switch {
+	case v.IsNull():
+		return 0, false
+	case v.IsSigned():
+		n, err := v.ToInt64()
+		if err != nil || n < 0 {
+			return 0, false
+		}
+		return uint64(n), true
+	case v.IsUnsigned():
+		n, err := v.ToUint64()
+		return n, err == nil
+	case v.Type() == sqltypes.Decimal:
+		return decimalRangeBound(v.RawStr(), end)
+	case v.IsFloat():
+		f, err := v.ToFloat64()
+		if err != nil || f < 0 || f >= 1<<53 {
+			return 0, false
+		}
+		if end {
+			return uint64(math.Ceil(f)), true
+		}
+		return uint64(math.Floor(f)), true
+	}
+	n, err := v.ToCastUint64()
+	return n, err == nil
+}
+
+// decimalRangeBound is uint64RangeBound for the text of a decimal, which is rounded
+// without going through a float so that large values stay exact.
+func decimalRangeBound(s string, end bool) (uint64, bool) {
+	integral, fractional, _ := strings.Cut(s, ".")
+	n, err := strconv.ParseUint(integral, 10, 64)
+	if err != nil {
+		// negative, or too large for an id
+		return 0, false
+	}
+	if end && strings.Trim(fractional, "0") != "" {
+		if n == math.MaxUint64 {
+			return 0, false
+		}
+		n++
+	}
+	return n, true
+}
+
 // UnknownParams implements the ParamValidating interface.
 func (vind *Numeric) UnknownParams() []string {
 	return vind.unknownParams
@@ -0,0 +1,339 @@
+/*
+Copyright 2025 The Vitess Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package vindexes
+
+import (
+	"bytes"
+	"context"
+	"encoding/binary"
+	"encoding/json"
+	"math"
+	"math/bits"
+	"os"
+	"slices"
+	"strings"
+
+	"vitess.io/vitess/go/sqltypes"
+	"vitess.io/vitess/go/vt/key"
+	"vitess.io/vitess/go/vt/proto/vtrpc"
+	"vitess.io/vitess/go/vt/vterrors"
+
+	topodatapb "vitess.io/vitess/go/vt/proto/topodata"
+)
+
+const (
+	numericRangeParamJSON     = "json"
+	numericRangeParamJSONPath = "json_path"
+)
+
+var (
+	_ SingleColumn    = (*NumericRange)(nil)
+	_ Hashing         = (*NumericRange)(nil)
+	_ Sequential      = (*NumericRange)(nil)
+	_ RangeMappable   = (*NumericRange)(nil)
+	_ ParamValidating = (*NumericRange)(nil)
+
+	numericRangeParams = []string{
+		numericRangeParamJSON,
+		numericRangeParamJSONPath,
+	}
+)
+
+// NumericRangeEntry maps an inclusive range of ids to a key range.
+type NumericRangeEntry struct {
+	From     uint64 `json:"from"`
+	To       uint64 `json:"to"`
+	KeyRange string `json:"key_range"`
+}
+
+type numericRange struct {
+	from, to   uint64
+	start, end uint64
+	// open is set if the key range extends to the end of the keyspace.
+	open bool
+}
+
+// NumericRange is a functional vindex that maps ordered ranges of numeric ids
+// to key ranges, as defined by an explicit table of split points:
+//
+//	[{"from": 1, "to": 10000, "key_range": "-40"}, {"from": 10001, "to": 20000, "key_range": "40-80"}]
+//
+// The ids of a range are spread evenly and in order over the 8 byte keyspace ids
+// of its key range, so that a range of ids always maps to a range of keyspace ids
+// and range predicates only need to be sent to the shards that cover them.
+// Ids that do not belong to any range do not map to any shard.
+type NumericRange struct {
+	name          string
+	ranges        []numericRange
+	unknownParams []string
+}
+
+func init() {
+	Register("numeric_range", newNumericRange)
+}
+
+// newNumericRange creates a NumericRange vindex.
+func newNumericRange(name string, params map[string]string) (Vindex, error) {
+	jsonStr, jsok := params[numericRangeParamJSON]
+	jsonPath, jpok := params[numericRangeParamJSONPath]
+
+	if !jsok && !jpok {
+		return nil, vterrors.Errorf(vtrpc.Code_INVALID_ARGUMENT, "NumericRange: Could not find either `json_path` or `json` params in vschema")
+	}
+	if jsok && jpok {
+		return nil, vterrors.Errorf(vtrpc.Code_INVALID_ARGUMENT, "NumericRange: Found both `json` and `json_path` params in vschema")
+	}
+
+	data := []byte(jsonStr)
+	if jpok {
+		var err error
+		data, err = os.ReadFile(jsonPath)
+		if err != nil {
+			return nil, err
+		}
+	}
+	ranges, err := parseNumericRanges(data)
+	if err != nil {
+		return nil, err
+	}
+
+	return &NumericRange{
+		name:          name,
+		ranges:        ranges,
+		unknownParams: FindUnknownParams(params, numericRangeParams),
+	}, nil
+}
+
+func parseNumericRanges(data []byte) ([]numericRange, error) {
+	var entries []NumericRangeEntry
+	if err := json.Unmarshal(data, &entries); err != nil {
+		return nil, vterrors.Errorf(vtrpc.Code_INVALID_ARGUMENT, "NumericRange: invalid ranges: %v", err)
+	}
+	if len(entries) == 0 {
+		return nil, vterrors.Errorf(vtrpc.Code_INVALID_ARGUMENT, "NumericRange: no ranges defined")
+	}
+
+	ranges := make([]numericRange, 0, len(entries))
+	for _, entry := range entries {
+		if entry.From > entry.To {
+			return nil, vterrors.Errorf(vtrpc.Code_INVALID_ARGUMENT, "NumericRange: range %d-%d is empty", entry.From, entry.To)
+		}
+		kr, err := parseNumericRangeKeyRange(entry.KeyRange)
+		if err != nil {
+			return nil, err
+		}
+		r := numericRange{from: entry.From, to: entry.To, open: len(kr.End) == 0}
+		r.start = binary.BigEndian.Uint64(padKeyspaceID(kr.Start))
+		r.end = binary.BigEndian.Uint64(padKeyspaceID(kr.End))
+		if !r.open && r.end <= r.start {
+			return nil, vterrors.Errorf(vtrpc.Code_INVALID_ARGUMENT, "NumericRange: key range %s is empty", entry.KeyRange)
+		}
+		ranges = append(ranges, r)
+	}
+
+	slices.SortFunc(ranges, func(a, b numericRange) int {
+		switch {
+		case a.from < b.from:
+			return -1
+		case a.from > b.from:
+			return 1
+		}
+		return 0
+	})
+	for i := 1; i < len(ranges); i++ {
+		if ranges[i].from <= ranges[i-1].to {
+			return nil, vterrors.Errorf(vtrpc.Code_INVALID_ARGUMENT, "NumericRange: ranges %d-%d and %d-%d overlap",
+				ranges[i-1].from, ranges[i-1].to, ranges[i].from, ranges[i].to)
+		}
+	}
+	return ranges, nil
+}
+
+func parseNumericRangeKeyRange(keyRange string) (*topodatapb.KeyRange, error) {
+	start, end, ok := strings.Cut(keyRange, "-")
+	if !ok || !key.IsValidKeyRange(keyRange) {
+		return nil, vterrors.Errorf(vtrpc.Code_INVALID_ARGUMENT, "NumericRange: invalid key range %q", keyRange)
+	}
+	kr, err := key.ParseKeyRangeParts(start, end)
+	if err != nil || len(kr.Start) > 8 || len(kr.End) > 8 {
+		return nil, vterrors.Errorf(vtrpc.Code_INVALID_ARGUMENT, "NumericRange: invalid key range %q", keyRange)
+	}
+	return kr, nil
+}
+
+// padKeyspaceID right-pads a key range boundary to 8 bytes.
+func padKeyspaceID(ksid []byte) []byte {
+	var padded [8]byte
+	copy(padded[:], ksid)
+	return padded[:]
+}
+
+// String returns the name of the vindex.
+func (vind *NumericRange) String() string {
+	return vind.name
+}
+
+// Cost returns the cost of this vindex as 1.
+func (*NumericRange) Cost() int {
+	return 1
+}
+
+// IsUnique returns true since the Vindex is unique.
+func (*NumericRange) IsUnique() bool {
+	return true
+}
+
+// NeedsVCursor satisfies the Vindex interface.
+func (*NumericRange) NeedsVCursor() bool {
+	return false
+}
+
+// Verify returns true if ids and ksids match.
+func (vind *NumericRange) Verify(ctx context.Context, vcursor VCursor, ids []sqltypes.Value, ksids [][]byte) ([]bool, error) {
+	out := make([]bool, 0, len(ids))
+	for i, id := range ids {
+		ksid, err := vind.Hash(id)
+		if err != nil {
+			out = append(out, false)
+			continue
+		}
+		out = append(out, bytes.Equal(ksid, ksids[i]))
+	}
+	return out, nil
+}
+
+// Map can map ids to key.ShardDestination objects.
+func (vind *NumericRange) Map(ctx context.Context, vcursor VCursor, ids []sqltypes.Value) ([]key.ShardDestination, error) {
+	out := make([]key.ShardDestination, 0, len(ids))
+	for _, id := range ids {
+		ksid, err := vind.Hash(id)
+		if err != nil {
+			out = append(out, key.DestinationNone{})
+			continue
+		}
+		out = append(out, key.DestinationKeyspaceID(ksid))
+	}
+	return out, nil
+}
+
+// RangeMap implements Between.
+func (vind *NumericRange) RangeMap(ctx context.Context, vcursor VCursor, startId sqltypes.Value, endId sqltypes.Value) ([]key.ShardDestination, error) {
+	from, err := startId.ToCastUint64()
+	if err != nil {
+		return nil, err
+	}
+	to, err := endId.ToCastUint64()
+	if err != nil {
+		return nil, err
+	}
+	return vind.mapRange(from, to), nil
+}
+
+// RangeMapOpen implements RangeMappable.
+func (vind *NumericRange) RangeMapOpen(ctx context.Context, vcursor VCursor, startId sqltypes.Value, endId sqltypes.Value) ([]key.ShardDestination, error) {
+	var out []key.ShardDestination
+	for _, r := range uint64OpenRanges(startId, endId) {
+		for _, dest := range vind.mapRange(r.from, r.to) {
+			if _, none := dest.(key.DestinationNone); !none {
+				out = append(out, dest)
+			}
+		}
+	}
+	if len(out) == 0 {
+		return []key.ShardDestination{key.DestinationNone{}}, nil
+	}
+	return out, nil
+}
+
+// mapRange returns the key ranges covering the ids between from and to, inclusive.
+func (vind *NumericRange) mapRange(from, to uint64) []key.ShardDestination {
+	var out []key.ShardDestination
+	for _, r := range vind.ranges {
+		if r.to < from || r.from > to {
+			continue
+		}
+		start := r.keyspaceID(max(from, r.from))
+		var end []byte
+		if last := min(to, r.to); last != r.to {
+			// last is below r.to, so last+1 cannot wrap. When there are more ids
+			// than keyspace ids, consecutive ids can share a keyspace id: the end
+			// must always be past the start, and stays open when the start is
+			// already the last keyspace id.
+			if next := r.keyspaceID(last + 1); next > start {
+				end = key.Uint64Key(next).Bytes()
+			} else if start < math.MaxUint64 {
+				end = key.Uint64Key(start + 1).Bytes()
+			}
+		} else if !r.open {
+			end = key.Uint64Key(r.end).Bytes()
+		}
+		out = append(out, key.DestinationKeyRange{KeyRange: key.NewKeyRange(key.Uint64Key(start).Bytes(), end)})
+	}
+	if len(out) == 0 {
+		return []key.ShardDestination{key.DestinationNone{}}
+	}
+	return out
+}
+
+// Hash returns the keyspace id of the given id.
+func (vind *NumericRange) Hash(id sqltypes.Value) ([]byte, error) {
+	num, err := id.ToCastUint64()
+	if err != nil {
+		return nil, err
+	}
+	i, found := slices.BinarySearchFunc(vind.ranges, num, func(r numericRange, num uint64) int {
+		switch {
+		case r.to < num:
+			return -1
+		case r.from > num:
+			return 1
+		}
+		return 0
+	})
+	if !found {
+		return nil, vterrors.Errorf(vtrpc.Code_INVALID_ARGUMENT, "NumericRange: id %d is not in any range", num)
+	}
+	return key.Uint64Key(vind.ranges[i].keyspaceID(num)).Bytes(), nil
+}
+
+// keyspaceID spreads the ids of the range linearly over its key range.
+func (r numericRange) keyspaceID(id uint64) uint64 {
+	offset := id - r.from
+	// Both the number of ids and the width of the key range can be 2^64, which
+	// we represent as 0.
+	ids := r.to - r.from + 1
+	width := r.end - r.start
+
+	var scaled uint64
+	switch {
+	case ids == 0 && width == 0:
+		scaled = offset
+	case ids == 0:
+		scaled, _ = bits.Mul64(offset, width)
+	case width == 0:
+		scaled, _ = bits.Div64(offset, 0, ids)
+	default:
+		hi, lo := bits.Mul64(offset, width)
+		scaled, _ = bits.Div64(hi, lo, ids)
+	}
+	return r.start + scaled
+}
+
+// UnknownParams implements the ParamValidating interface.
+func (vind *NumericRange) UnknownParams() []string {
+	return vind.unknownParams
+}
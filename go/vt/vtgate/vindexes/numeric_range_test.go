@@ -0,0 +1,270 @@
+/*
+Copyright 2025 The Vitess Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package vindexes
+
+import (
+	"context"
+	"math"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"vitess.io/vitess/go/sqltypes"
+	"vitess.io/vitess/go/vt/key"
+	"vitess.io/vitess/go/vt/proto/vtrpc"
+	"vitess.io/vitess/go/vt/vterrors"
+)
+
+const numericRangeTestJSON = `[
+	{"from": 1, "to": 1000, "key_range": "-40"},
+	{"from": 1001, "to": 2000, "key_range": "40-80"},
+	{"from": 3001, "to": 4000, "key_range": "c0-"},
+	{"from": 2001, "to": 3000, "key_range": "80-c0"}
+]`
+
+func createNumericRange(t *testing.T, ranges string) *NumericRange {
+	t.Helper()
+	vindex, err := CreateVindex("numeric_range", "numeric_range", map[string]string{"json": ranges})
+	require.NoError(t, err)
+	return vindex.(*NumericRange)
+}
+
+func numericRangeCreateVindexTestCase(
+	testName string,
+	vindexParams map[string]string,
+	expectErr error,
+	expectUnknownParams []string,
+) createVindexTestCase {
+	return createVindexTestCase{
+		testName: testName,
+
+		vindexType:   "numeric_range",
+		vindexName:   "numeric_range",
+		vindexParams: vindexParams,
+
+		expectCost:          1,
+		expectErr:           expectErr,
+		expectIsUnique:      true,
+		expectNeedsVCursor:  false,
+		expectString:        "numeric_range",
+		expectUnknownParams: expectUnknownParams,
+	}
+}
+
+func TestNumericRangeCreateVindex(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "ranges.json")
+	require.NoError(t, os.WriteFile(path, []byte(numericRangeTestJSON), 0o644))
+
+	cases := []createVindexTestCase{
+		numericRangeCreateVindexTestCase(
+			"no params invalid, require either json_path or json",
+			nil,
+			vterrors.Errorf(vtrpc.Code_INVALID_ARGUMENT, "NumericRange: Could not find either `json_path` or `json` params in vschema"),
+			nil,
+		),
+		numericRangeCreateVindexTestCase(
+			"json and json_path invalid",
+			map[string]string{"json": numericRangeTestJSON, "json_path": path},
+			vterrors.Errorf(vtrpc.Code_INVALID_ARGUMENT, "NumericRange: Found both `json` and `json_path` params in vschema"),
+			nil,
+		),
+		numericRangeCreateVindexTestCase(
+			"json ok",
+			map[string]string{"json": numericRangeTestJSON},
+			nil,
+			nil,
+		),
+		numericRangeCreateVindexTestCase(
+			"json_path ok",
+			map[string]string{"json_path": path},
+			nil,
+			nil,
+		),
+		numericRangeCreateVindexTestCase(
+			"unknown params",
+			map[string]string{"json": numericRangeTestJSON, "hello": "world"},
+			nil,
+			[]string{"hello"},
+		),
+		numericRangeCreateVindexTestCase(
+			"no ranges",
+			map[string]string{"json": "[]"},
+			vterrors.Errorf(vtrpc.Code_INVALID_ARGUMENT, "NumericRange: no ranges defined"),
+			nil,
+		),
+		numericRangeCreateVindexTestCase(
+			"overlapping ranges",
+			map[string]string{"json": `[{"from": 1, "to": 10, "key_range": "-80"}, {"from": 10, "to": 20, "key_range": "80-"}]`},
+			vterrors.Errorf(vtrpc.Code_INVALID_ARGUMENT, "NumericRange: ranges 1-10 and 10-20 overlap"),
+			nil,
+		),
+		numericRangeCreateVindexTestCase(
+			"empty range",
+			map[string]string{"json": `[{"from": 10, "to": 1, "key_range": "-80"}]`},
+			vterrors.Errorf(vtrpc.Code_INVALID_ARGUMENT, "NumericRange: range 10-1 is empty"),
+			nil,
+		),
+		numericRangeCreateVindexTestCase(
+			"invalid key range",
+			map[string]string{"json": `[{"from": 1, "to": 10, "key_range": "80"}]`},
+			vterrors.Errorf(vtrpc.Code_INVALID_ARGUMENT, "NumericRange: invalid key range \"80\""),
+			nil,
+		),
+		numericRangeCreateVindexTestCase(
+			"empty key range",
+			map[string]string{"json": `[{"from": 1, "to": 10, "key_range": "80-40"}]`},
+			vterrors.Errorf(vtrpc.Code_INVALID_ARGUMENT, "NumericRange: key range 80-40 is empty"),
+			nil,
+		),
+	}
+
+	testCreateVindexes(t, cases)
+}
+
+func TestNumericRangeMap(t *testing.T) {
+	vind := createNumericRange(t, numericRangeTestJSON)
+	got, err := vind.Map(context.Background(), nil, []sqltypes.Value{
+		sqltypes.NewInt64(1),
+		sqltypes.NewInt64(1000),
+		sqltypes.NewInt64(1001),
+		sqltypes.NewInt64(2500),
+		sqltypes.NewInt64(4000),
+		sqltypes.NewInt64(0),
+		sqltypes.NewInt64(4001),
+		sqltypes.NewVarChar("abcd"),
+	})
+	require.NoError(t, err)
+
+	want := []key.ShardDestination{
+		key.DestinationKeyspaceID([]byte("\x00\x00\x00\x00\x00\x00\x00\x00")),
+		key.DestinationKeyspaceID([]byte("\x3f\xef\x9d\xb2\x2d\x0e\x56\x04")),
+		key.DestinationKeyspaceID([]byte("\x40\x00\x00\x00\x00\x00\x00\x00")),
+		key.DestinationKeyspaceID([]byte("\x9f\xef\x9d\xb2\x2d\x0e\x56\x04")),
+		key.DestinationKeyspaceID([]byte("\xff\xef\x9d\xb2\x2d\x0e\x56\x04")),
+		key.DestinationNone{},
+		key.DestinationNone{},
+		key.DestinationNone{},
+	}
+	assert.Equal(t, want, got)
+
+	// The ids of a range are mapped to keyspace ids within its key range, in order.
+	var prev []byte
+	for id := int64(1001); id <= 2000; id++ {
+		ksid, err := vind.Hash(sqltypes.NewInt64(id))
+		require.NoError(t, err)
+		assert.True(t, key.KeyRangeContains(key.NewKeyRange([]byte{0x40}, []byte{0x80}), ksid))
+		assert.True(t, key.Less(prev, ksid))
+		prev = ksid
+	}
+}
+
+func TestNumericRangeVerify(t *testing.T) {
+	vind := createNumericRange(t, numericRangeTestJSON)
+	ksid, err := vind.Hash(sqltypes.NewInt64(1500))
+	require.NoError(t, err)
+
+	got, err := vind.Verify(context.Background(), nil,
+		[]sqltypes.Value{sqltypes.NewInt64(1500), sqltypes.NewInt64(1501), sqltypes.NewInt64(5000)},
+		[][]byte{ksid, ksid, ksid})
+	require.NoError(t, err)
+	assert.Equal(t, []bool{true, false, false}, got)
+}
+
+func TestNumericRangeRangeMap(t *testing.T) {
+	vind := createNumericRange(t, numericRangeTestJSON)
+	tcases := []struct {
+		from, to int64
+		want     []string
+	}{{
+		from: 1,
+		to:   1000,
+		want: []string{"DestinationKeyRange(0000000000000000-4000000000000000)"},
+	}, {
+		from: 501,
+		to:   1500,
+		want: []string{
+			"DestinationKeyRange(2000000000000000-4000000000000000)",
+			"DestinationKeyRange(4000000000000000-6000000000000000)",
+		},
+	}, {
+		from: 3500,
+		to:   5000,
+		want: []string{"DestinationKeyRange(dfef9db22d0e5604-)"},
+	}, {
+		from: 5000,
+		to:   6000,
+		want: []string{"DestinationNone()"},
+	}}
+	for _, tcase := range tcases {
+		got, err := vind.RangeMap(context.Background(), nil, sqltypes.NewInt64(tcase.from), sqltypes.NewInt64(tcase.to))
+		require.NoError(t, err)
+		var gotStr []string
+		for _, dest := range got {
+			gotStr = append(gotStr, dest.String())
+		}
+		assert.Equal(t, tcase.want, gotStr, "%d-%d", tcase.from, tcase.to)
+	}
+}
+
+func TestNumericRangeFullRange(t *testing.T) {
+	// All the possible ids spread over the whole keyspace map to themselves.
+	vind := createNumericRange(t, `[{"from": 0, "to": 18446744073709551615, "key_range": "-"}]`)
+	for _, id := range []uint64{0, 1, 1 << 63, math.MaxUint64} {
+		ksid, err := vind.Hash(sqltypes.NewUint64(id))
+		require.NoError(t, err)
+		assert.Equal(t, key.Uint64Key(id).Bytes(), ksid)
+	}
+
+	// A small key range holding more ids than keyspace ids.
+	vind = createNumericRange(t, `[{"from": 0, "to": 18446744073709551615, "key_range": "00000000000000ff-0000000000000100"}]`)
+	got, err := vind.RangeMap(context.Background(), nil, sqltypes.NewUint64(5), sqltypes.NewUint64(10))
+	require.NoError(t, err)
+	assert.Equal(t, "DestinationKeyRange(00000000000000ff-0000000000000100)", got[0].String())
+
+	// The same at the very top of the keyspace: the range must not wrap to empty.
+	vind = createNumericRange(t, `[{"from": 0, "to": 18446744073709551615, "key_range": "ffffffffffffffff-"}]`)
+	got, err = vind.RangeMap(context.Background(), nil, sqltypes.NewUint64(5), sqltypes.NewUint64(10))
+	require.NoError(t, err)
+	assert.Equal(t, "DestinationKeyRange(ffffffffffffffff-)", got[0].String())
+}
+
+func TestNumericRangeRangeMapOpen(t *testing.T) {
+	vind := createNumericRange(t, numericRangeTestJSON)
+	got, err := vind.RangeMapOpen(context.Background(), nil, sqltypes.NewInt64(2500), sqltypes.NULL)
+	require.NoError(t, err)
+	var gotStr []string
+	for _, dest := range got {
+		gotStr = append(gotStr, dest.String())
+	}
+	assert.Equal(t, []string{"DestinationKeyRange(9fef9db22d0e5604-c000000000000000)", "DestinationKeyRange(c000000000000000-)"}, gotStr)
+
+	got, err = vind.RangeMapOpen(context.Background(), nil, sqltypes.NULL, sqltypes.NewInt64(1000))
+	require.NoError(t, err)
+	assert.Equal(t, []key.ShardDestination{key.DestinationKeyRange{KeyRange: key.NewKeyRange(key.Uint64Key(0).Bytes(), key.Uint64Key(0x4000000000000000).Bytes())}}, got)
+
+	// non-integral and negative bounds do not fail the query
+	got, err = vind.RangeMapOpen(context.Background(), nil, sqltypes.NewDecimal("-1.5"), sqltypes.NewDecimal("999.5"))
+	require.NoError(t, err)
+	assert.Equal(t, []key.ShardDestination{key.DestinationKeyRange{KeyRange: key.NewKeyRange(key.Uint64Key(0).Bytes(), key.Uint64Key(0x4000000000000000).Bytes())}}, got)
+
+	got, err = vind.RangeMapOpen(context.Background(), nil, sqltypes.NewDecimal("5000.5"), sqltypes.NewDecimal("4000"))
+	require.NoError(t, err)
+	assert.Equal(t, []key.ShardDestination{key.DestinationNone{}}, got)
+}
@@ -18,9 +18,11 @@ package vindexes
 
 import (
 	"context"
+	"math"
 	"reflect"
 	"testing"
 
+	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
 
 	"vitess.io/vitess/go/sqltypes"
@@ -146,3 +148,66 @@ func TestNumericReverseMapBadData(t *testing.T) {
 		t.Errorf("numeric.Map: %v, want %v", err, want)
 	}
 }
+
+func TestNumericRangeMapOpen(t *testing.T) {
+	tcases := []struct {
+		start, end sqltypes.Value
+		want       []string
+	}{{
+		start: sqltypes.NewInt64(5),
+		end:   sqltypes.NULL,
+		want:  []string{"DestinationKeyRange(0000000000000005-)"},
+	}, {
+		// negative ids are cast to the upper half of the keyspace ids
+		start: sqltypes.NULL,
+		end:   sqltypes.NewInt64(5),
+		want:  []string{"DestinationKeyRange(-0000000000000006)", "DestinationKeyRange(8000000000000000-)"},
+	}, {
+		start: sqltypes.NewInt64(1),
+		end:   sqltypes.NewUint64(math.MaxUint64),
+		want:  []string{"DestinationKeyRange(0000000000000001-)"},
+	}, {
+		start: sqltypes.NewInt64(-1),
+		end:   sqltypes.NewInt64(-5),
+		want:  []string{"DestinationKeyRange(-)"},
+	}, {
+		start: sqltypes.NewInt64(10),
+		end:   sqltypes.NewInt64(5),
+		want:  []string{"DestinationNone()"},
+	}, {
+		// non-integral bounds are rounded to cover all the ids they match
+		start: sqltypes.NewDecimal("5.5"),
+		end:   sqltypes.NewDecimal("7.5"),
+		want:  []string{"DestinationKeyRange(0000000000000005-0000000000000009)"},
+	}, {
+		start: sqltypes.NewDecimal("18446744073709551614.5"),
+		end:   sqltypes.NULL,
+		want:  []string{"DestinationKeyRange(fffffffffffffffe-)"},
+	}, {
+		start: sqltypes.NewFloat64(5.5),
+		end:   sqltypes.NewFloat64(7.5),
+		want:  []string{"DestinationKeyRange(0000000000000005-0000000000000009)"},
+	}, {
+		// negative and inexact bounds leave the range unbounded
+		start: sqltypes.NewDecimal("-1.5"),
+		end:   sqltypes.NewDecimal("-1.5"),
+		want:  []string{"DestinationKeyRange(-)"},
+	}, {
+		start: sqltypes.NewFloat64(1e19),
+		end:   sqltypes.NULL,
+		want:  []string{"DestinationKeyRange(-)"},
+	}, {
+		start: sqltypes.NewVarChar("abc"),
+		end:   sqltypes.NewDecimal("18446744073709551616"),
+		want:  []string{"DestinationKeyRange(-)"},
+	}}
+	for _, tcase := range tcases {
+		got, err := numeric.(RangeMappable).RangeMapOpen(context.Background(), nil, tcase.start, tcase.end)
+		require.NoError(t, err)
+		var gotStr []string
+		for _, dest := range got {
+			gotStr = append(gotStr, dest.String())
+		}
+		assert.Equal(t, tcase.want, gotStr, "%v-%v", tcase.start, tcase.end)
+	}
+}
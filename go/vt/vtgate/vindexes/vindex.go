@@ -137,6 +137,16 @@ type (
 		RangeMap(ctx context.Context, vcursor VCursor, startId sqltypes.Value, endId sqltypes.Value) ([]key.ShardDestination, error)
 	}
 
+	// A RangeMappable vindex is a Sequential vindex that can also map a range with
+	// a single bound. It's being used to reduce the fan out for inequality expressions
+	// like 'id > 10'.
+	RangeMappable interface {
+		Sequential
+		// RangeMapOpen maps the ids from startId to endId, both inclusive. A NULL
+		// startId or endId means that the range is unbounded on that side.
+		RangeMapOpen(ctx context.Context, vcursor VCursor, startId sqltypes.Value, endId sqltypes.Value) ([]key.ShardDestination, error)
+	}
+
 	// A Prefixable vindex is one that maps the prefix of a id to a keyspace range
 	// instead of a single keyspace id. It's being used to reduced the fan out for
 	// 'LIKE' expressions.
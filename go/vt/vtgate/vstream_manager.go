@@ -178,6 +178,13 @@ func newVStreamManager(resolver *srvtopo.Resolver, serv srvtopo.Server, cell str
 
 func (vsm *vstreamManager) VStream(ctx context.Context, tabletType topodatapb.TabletType, vgtid *binlogdatapb.VGtid,
 	filter *binlogdatapb.Filter, flags *vtgatepb.VStreamFlags, send func(events []*binlogdatapb.VEvent) error) error {
+	if name := flags.GetSubscription(); name != "" {
+		var err error
+		tabletType, vgtid, filter, err = vsm.resolveSubscription(ctx, name, tabletType, vgtid, filter)
+		if err != nil {
+			return vterrors.Wrapf(err, "failed to resolve vstream subscription %s", name)
+		}
+	}
 	vgtid, filter, flags, err := vsm.resolveParams(ctx, tabletType, vgtid, filter, flags)
 	if err != nil {
 		return vterrors.Wrap(err, "failed to resolve vstream parameters")
@@ -0,0 +1,91 @@
+/*
+Copyright 2025 The Vitess Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package vtgate
+
+import (
+	"context"
+	"time"
+
+	"vitess.io/vitess/go/vt/log"
+	"vitess.io/vitess/go/vt/topo"
+	"vitess.io/vitess/go/vt/vterrors"
+
+	binlogdatapb "vitess.io/vitess/go/vt/proto/binlogdata"
+	topodatapb "vitess.io/vitess/go/vt/proto/topodata"
+	vtrpcpb "vitess.io/vitess/go/vt/proto/vtrpc"
+)
+
+// resolveSubscription returns the tablet type, the position and the filter
+// to stream the named subscription with: the ones it was created with, and
+// its last acknowledged position. A subscription that does not exist is
+// created from the request.
+func (vsm *vstreamManager) resolveSubscription(ctx context.Context, name string, tabletType topodatapb.TabletType, vgtid *binlogdatapb.VGtid,
+	filter *binlogdatapb.Filter) (topodatapb.TabletType, *binlogdatapb.VGtid, *binlogdatapb.Filter, error) {
+	ts, err := vsm.toposerv.GetTopoServer()
+	if err != nil {
+		return tabletType, nil, nil, err
+	}
+	si, err := ts.GetVStreamSubscription(ctx, name)
+	if err == nil {
+		log.Infof("Resuming VStream subscription %s from %v", name, si.Vgtid)
+		return si.TabletType, si.Vgtid, si.Filter, nil
+	}
+	if !topo.IsErrType(err, topo.NoNode) {
+		return tabletType, nil, nil, err
+	}
+	if len(vgtid.GetShardGtids()) == 0 {
+		return tabletType, nil, nil, vterrors.Errorf(vtrpcpb.Code_INVALID_ARGUMENT, "vgtid must have at least one value with a starting position in ShardGtids to create subscription %s", name)
+	}
+	_, err = ts.CreateVStreamSubscription(ctx, &binlogdatapb.VStreamSubscription{
+		Name:       name,
+		TabletType: tabletType,
+		Filter:     filter,
+		Vgtid:      vgtid,
+		CreatedAt:  time.Now().Unix(),
+	})
+	if topo.IsErrType(err, topo.NodeExists) {
+		// It was created concurrently, which is the one to stream.
+		return vsm.resolveSubscription(ctx, name, tabletType, vgtid, filter)
+	}
+	if err != nil {
+		return tabletType, nil, nil, err
+	}
+	log.Infof("Created VStream subscription %s from %v", name, vgtid)
+	return tabletType, vgtid, filter, nil
+}
+
+// AckSubscription records the position a subscription processed its events
+// up to, which it resumes from when it is streamed again.
+func (vsm *vstreamManager) AckSubscription(ctx context.Context, name string, vgtid *binlogdatapb.VGtid, timestamp int64) error {
+	if len(vgtid.GetShardGtids()) == 0 {
+		return vterrors.Errorf(vtrpcpb.Code_INVALID_ARGUMENT, "vgtid must have at least one value to acknowledge subscription %s", name)
+	}
+	ts, err := vsm.toposerv.GetTopoServer()
+	if err != nil {
+		return err
+	}
+	_, err = ts.UpdateVStreamSubscriptionFields(ctx, name, func(sub *binlogdatapb.VStreamSubscription) error {
+		sub.Vgtid = vgtid
+		sub.AckedAt = time.Now().Unix()
+		sub.AckedTimestamp = timestamp
+		return nil
+	})
+	if topo.IsErrType(err, topo.NoNode) {
+		return vterrors.Errorf(vtrpcpb.Code_NOT_FOUND, "VStream subscription %s does not exist", name)
+	}
+	return err
+}
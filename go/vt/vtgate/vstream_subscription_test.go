@@ -0,0 +1,79 @@
+/*
+Copyright 2025 The Vitess Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package vtgate
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"vitess.io/vitess/go/test/utils"
+	"vitess.io/vitess/go/vt/discovery"
+	"vitess.io/vitess/go/vt/vterrors"
+
+	binlogdatapb "vitess.io/vitess/go/vt/proto/binlogdata"
+	topodatapb "vitess.io/vitess/go/vt/proto/topodata"
+	vtrpcpb "vitess.io/vitess/go/vt/proto/vtrpc"
+)
+
+func TestVStreamSubscription(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	ks := "TestVStream"
+	cell := "aa"
+	_ = createSandbox(ks)
+	hc := discovery.NewFakeHealthCheck(nil)
+	st := getSandboxTopo(ctx, cell, ks, []string{"-20"})
+	vsm := newTestVStreamManager(ctx, hc, st, cell)
+
+	filter := &binlogdatapb.Filter{Rules: []*binlogdatapb.Rule{{Match: "/.*"}}}
+	start := &binlogdatapb.VGtid{ShardGtids: []*binlogdatapb.ShardGtid{{Keyspace: ks, Shard: "-20", Gtid: "current"}}}
+
+	// A new subscription needs a starting position.
+	_, _, _, err := vsm.resolveSubscription(ctx, "orders", topodatapb.TabletType_REPLICA, nil, filter)
+	assert.ErrorContains(t, err, "vgtid must have at least one value with a starting position in ShardGtids to create subscription orders")
+
+	tabletType, vgtid, gotFilter, err := vsm.resolveSubscription(ctx, "orders", topodatapb.TabletType_REPLICA, start, filter)
+	require.NoError(t, err)
+	assert.Equal(t, topodatapb.TabletType_REPLICA, tabletType)
+	utils.MustMatch(t, start, vgtid)
+	utils.MustMatch(t, filter, gotFilter)
+
+	acked := &binlogdatapb.VGtid{ShardGtids: []*binlogdatapb.ShardGtid{{Keyspace: ks, Shard: "-20", Gtid: "MySQL56/a:1-5"}}}
+	require.NoError(t, vsm.AckSubscription(ctx, "orders", acked, 1700000000))
+
+	// An existing subscription resumes from its acknowledged position, with
+	// the tablet type and the filter it was created with.
+	tabletType, vgtid, gotFilter, err = vsm.resolveSubscription(ctx, "orders", topodatapb.TabletType_PRIMARY, start, nil)
+	require.NoError(t, err)
+	assert.Equal(t, topodatapb.TabletType_REPLICA, tabletType)
+	utils.MustMatch(t, acked, vgtid)
+	utils.MustMatch(t, filter, gotFilter)
+
+	sub, err := st.topoServer.GetVStreamSubscription(ctx, "orders")
+	require.NoError(t, err)
+	assert.EqualValues(t, 1700000000, sub.AckedTimestamp)
+	assert.NotZero(t, sub.AckedAt)
+
+	err = vsm.AckSubscription(ctx, "unknown", acked, 0)
+	assert.Equal(t, vtrpcpb.Code_NOT_FOUND, vterrors.Code(err), err)
+	err = vsm.AckSubscription(ctx, "orders", nil, 0)
+	assert.Equal(t, vtrpcpb.Code_INVALID_ARGUMENT, vterrors.Code(err), err)
+}
@@ -76,6 +76,14 @@ var (
 	// plan cache related flag
 	queryPlanCacheMemory int64 = 32 * 1024 * 1024 // 32mb
 
+	planCacheSnapshotLocation string
+	planCacheSnapshotInterval = 5 * time.Minute
+	planCacheSnapshotSize     = 10000
+
+	resultCacheSize   int64
+	resultCacheTTL    = 1 * time.Second
+	resultCacheTables []string
+
 	maxMemoryRows   = 300000
 	warnMemoryRows  = 30000
 	maxPayloadSize  int
@@ -205,6 +213,12 @@ func registerFlags(fs *pflag.FlagSet) {
 	fs.IntVar(&warmingReadsPercent, "warming-reads-percent", 0, "Percentage of reads on the primary to forward to replicas. Useful for keeping buffer pools warm")
 	fs.IntVar(&warmingReadsConcurrency, "warming-reads-concurrency", 500, "Number of concurrent warming reads allowed")
 	fs.DurationVar(&warmingReadsQueryTimeout, "warming-reads-query-timeout", 5*time.Second, "Timeout of warming read queries")
+	fs.StringVar(&planCacheSnapshotLocation, "plan-cache-snapshot", planCacheSnapshotLocation, "Where to persist the most executed queries of the plan cache, so that their plans are rebuilt on startup: a local file, or topo:<name> to share the snapshot between the vtgates of the cell")
+	fs.DurationVar(&planCacheSnapshotInterval, "plan-cache-snapshot-interval", planCacheSnapshotInterval, "How often to take a snapshot of the plan cache, when --plan-cache-snapshot is set. A snapshot is also taken on shutdown")
+	fs.IntVar(&planCacheSnapshotSize, "plan-cache-snapshot-size", planCacheSnapshotSize, "Maximum number of queries kept in the plan cache snapshot")
+	fs.Int64Var(&resultCacheSize, "result-cache-size", resultCacheSize, "Memory budget, in bytes, of the cache of the results of SELECTs against replicas. The cache is disabled when set to 0")
+	fs.DurationVar(&resultCacheTTL, "result-cache-ttl", resultCacheTTL, "How long the results of queries that only read from --result-cache-tables are cached for. Writes made through other vtgates are only seen once the results expire")
+	fs.StringSliceVar(&resultCacheTables, "result-cache-tables", resultCacheTables, "Comma separated list of tables, optionally qualified with their keyspace, whose query results are cached. Other queries can be cached with the RESULT_CACHE_TTL_MS directive")
 
 	viperutil.BindFlags(fs,
 		enableOnlineDDL,
@@ -381,6 +395,14 @@ func Init(
 		AllowScatter:        !noScatter,
 		WarmingReadsPercent: warmingReadsPercent,
 		QueryLogToFile:      queryLogToFile,
+
+		PlanCacheSnapshot:         planCacheSnapshotLocation,
+		PlanCacheSnapshotInterval: planCacheSnapshotInterval,
+		PlanCacheSnapshotSize:     planCacheSnapshotSize,
+
+		ResultCacheSize:   resultCacheSize,
+		ResultCacheTTL:    resultCacheTTL,
+		ResultCacheTables: resultCacheTables,
 	}
 
 	executor := NewExecutor(ctx, env, serv, cell, resolver, eConfig, warnShardedOnly, plans, si, pv, dynamicConfig)
@@ -392,6 +414,9 @@ func Init(
 	// connect the schema tracker with the vschema manager
 	if enableSchemaChangeSignal {
 		st.RegisterSignalReceiver(executor.vm.Rebuild)
+		if executor.resultCache != nil {
+			st.RegisterTableChangeReceiver(executor.resultCache.invalidate)
+		}
 	}
 
 	vtgateInst := newVTGate(executor, resolver, vsm, tc, gw)
@@ -424,6 +449,13 @@ func Init(
 		}
 		tr.Stop()
 	})
+	servenv.OnClose(func() {
+		if executor.planSnapshots != nil {
+			ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+			defer cancel()
+			executor.planSnapshots.close(ctx)
+		}
+	})
 	vtgateInst.registerDebugHealthHandler()
 	vtgateInst.registerDebugEnvHandler()
 	vtgateInst.registerDebugBalancerHandler()
@@ -560,7 +592,7 @@ func (vtg *VTGate) Execute(
 	// In this context, we don't care if we can't fully parse destination
 	destKeyspace, destTabletType, _, _ := vtg.executor.ParseDestinationTarget(session.TargetString)
 	statsKey := []string{"Execute", destKeyspace, topoproto.TabletTypeLString(destTabletType)}
-	defer vtg.timings.Record(statsKey, time.Now())
+	defer vtg.timings.RecordContext(ctx, statsKey, time.Now())
 
 	if bvErr := sqltypes.ValidateBindVariables(bindVariables); bvErr != nil {
 		err = vterrors.Errorf(vtrpcpb.Code_INVALID_ARGUMENT, "%v", bvErr)
@@ -622,7 +654,7 @@ func (vtg *VTGate) ExecuteBatch(ctx context.Context, session *vtgatepb.Session,
 	// In this context, we don't care if we can't fully parse destination
 	destKeyspace, destTabletType, _, _ := vtg.executor.ParseDestinationTarget(session.TargetString)
 	statsKey := []string{"ExecuteBatch", destKeyspace, topoproto.TabletTypeLString(destTabletType)}
-	defer vtg.timings.Record(statsKey, time.Now())
+	defer vtg.timings.RecordContext(ctx, statsKey, time.Now())
 
 	for _, bindVariables := range bindVariablesList {
 		if bvErr := sqltypes.ValidateBindVariables(bindVariables); bvErr != nil {
@@ -652,7 +684,7 @@ func (vtg *VTGate) StreamExecute(ctx context.Context, mysqlCtx vtgateservice.MyS
 	destKeyspace, destTabletType, _, _ := vtg.executor.ParseDestinationTarget(session.TargetString)
 	statsKey := []string{"StreamExecute", destKeyspace, topoproto.TabletTypeLString(destTabletType)}
 
-	defer vtg.timings.Record(statsKey, time.Now())
+	defer vtg.timings.RecordContext(ctx, statsKey, time.Now())
 
 	safeSession := econtext.NewSafeSession(session)
 	var err error
@@ -737,7 +769,7 @@ func (vtg *VTGate) Prepare(ctx context.Context, session *vtgatepb.Session, sql s
 	// In this context, we don't care if we can't fully parse destination
 	destKeyspace, destTabletType, _, _ := vtg.executor.ParseDestinationTarget(session.TargetString)
 	statsKey := []string{"Prepare", destKeyspace, topoproto.TabletTypeLString(destTabletType)}
-	defer vtg.timings.Record(statsKey, time.Now())
+	defer vtg.timings.RecordContext(ctx, statsKey, time.Now())
 
 	fld, paramsCount, err = vtg.executor.Prepare(ctx, "Prepare", econtext.NewSafeSession(session), sql)
 	if err == nil {
@@ -757,6 +789,11 @@ func (vtg *VTGate) VStream(ctx context.Context, tabletType topodatapb.TabletType
 	return vtg.vsm.VStream(ctx, tabletType, vgtid, filter, flags, send)
 }
 
+// VStreamAck acknowledges the position of a VStream subscription.
+func (vtg *VTGate) VStreamAck(ctx context.Context, subscription string, vgtid *binlogdatapb.VGtid, timestamp int64) error {
+	return vtg.vsm.AckSubscription(ctx, subscription, vgtid, timestamp)
+}
+
 // GetGatewayCacheStatus returns a displayable version of the Gateway cache.
 func (vtg *VTGate) GetGatewayCacheStatus() TabletCacheStatusList {
 	return vtg.gw.CacheStatus()
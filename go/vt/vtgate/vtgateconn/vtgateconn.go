@@ -41,6 +41,7 @@ func registerFlags(fs *pflag.FlagSet) {
 }
 
 func init() {
+	servenv.OnParseFor("vtcdc", registerFlags)
 	servenv.OnParseFor("vttablet", registerFlags)
 	servenv.OnParseFor("vtclient", registerFlags)
 }
@@ -106,6 +107,12 @@ func (conn *VTGateConn) VStream(ctx context.Context, tabletType topodatapb.Table
 	return conn.impl.VStream(ctx, tabletType, vgtid, filter, flags)
 }
 
+// VStreamAck acknowledges the position of a VStream subscription, given the
+// timestamp of the last processed event.
+func (conn *VTGateConn) VStreamAck(ctx context.Context, subscription string, vgtid *binlogdatapb.VGtid, timestamp int64) error {
+	return conn.impl.VStreamAck(ctx, subscription, vgtid, timestamp)
+}
+
 // VTGateSession exposes the Vitess Execution API to the clients.
 // The object maintains client-side state and is comparable to a native MySQL connection.
 // For example, if you enable autocommit on a Session object, all subsequent calls will respect this.
@@ -202,6 +209,9 @@ type Impl interface {
 	// VStream streams binlogevents
 	VStream(ctx context.Context, tabletType topodatapb.TabletType, vgtid *binlogdatapb.VGtid, filter *binlogdatapb.Filter, flags *vtgatepb.VStreamFlags) (VStreamReader, error)
 
+	// VStreamAck acknowledges the position of a VStream subscription.
+	VStreamAck(ctx context.Context, subscription string, vgtid *binlogdatapb.VGtid, timestamp int64) error
+
 	// Close must be called for releasing resources.
 	Close()
 }
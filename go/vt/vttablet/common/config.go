@@ -51,6 +51,9 @@ type VReplicationConfig struct {
 	ParallelInsertWorkers   int
 	TabletTypesStr          string
 	EnableHttpLog           bool // Enable the /debug/vrlog endpoint
+	// CopyPhaseMaxConcurrentTables is the maximum number of tables copied
+	// concurrently during the copy phase.
+	CopyPhaseMaxConcurrentTables int
 
 	// Config parameters applicable to the source side (vstreamer)
 	// The coresponding Override fields are used to determine if the user has provided a value for the parameter so
@@ -97,6 +100,8 @@ func GetVReplicationConfigDefaults(useCached bool) *VReplicationConfig {
 		TabletTypesStr:          vreplicationTabletTypesStr,
 		EnableHttpLog:           vreplicationEnableHttpLog,
 
+		CopyPhaseMaxConcurrentTables: vreplicationCopyPhaseMaxConcurrentTables,
+
 		VStreamPacketSizeOverride:              false,
 		VStreamPacketSize:                      VStreamerDefaultPacketSize,
 		VStreamDynamicPacketSizeOverride:       false,
@@ -220,6 +225,13 @@ func NewVReplicationConfig(overrides map[string]string) (*VReplicationConfig, er
 			} else {
 				c.ParallelInsertWorkers = value
 			}
+		case "vreplication-copy-phase-max-concurrent-tables":
+			value, err := strconv.Atoi(v)
+			if err != nil {
+				errors = append(errors, getError(k, v))
+			} else {
+				c.CopyPhaseMaxConcurrentTables = value
+			}
 		case "vstream-packet-size", "vstream_packet_size":
 			value, err := strconv.Atoi(v)
 			if err != nil {
@@ -259,23 +271,24 @@ func NewVReplicationConfig(overrides map[string]string) (*VReplicationConfig, er
 // keys are one of those that are supported.
 func (c VReplicationConfig) Map() map[string]string {
 	return map[string]string{
-		"vreplication-experimental-flags":         strconv.FormatInt(c.ExperimentalFlags, 10),
-		"vreplication-net-read-timeout":           strconv.Itoa(c.NetReadTimeout),
-		"vreplication-net-write-timeout":          strconv.Itoa(c.NetWriteTimeout),
-		"vreplication-copy-phase-duration":        c.CopyPhaseDuration.String(),
-		"vreplication-retry-delay":                c.RetryDelay.String(),
-		"vreplication-max-time-to-retry-on-error": c.MaxTimeToRetryError.String(),
-		"relay_log_max_size":                      strconv.Itoa(c.RelayLogMaxSize),
-		"relay_log_max_items":                     strconv.Itoa(c.RelayLogMaxItems),
-		"vreplication-replica-lag-tolerance":      c.ReplicaLagTolerance.String(),
-		"vreplication-heartbeat-update-interval":  strconv.Itoa(c.HeartbeatUpdateInterval),
-		"vreplication-store-compressed-gtid":      strconv.FormatBool(c.StoreCompressedGTID),
-		"vreplication-parallel-insert-workers":    strconv.Itoa(c.ParallelInsertWorkers),
-		"vstream-packet-size":                     strconv.Itoa(c.VStreamPacketSize),
-		"vstream_packet_size":                     strconv.Itoa(c.VStreamPacketSize),
-		"vstream-dynamic-packet-size":             strconv.FormatBool(c.VStreamDynamicPacketSize),
-		"vstream_dynamic_packet_size":             strconv.FormatBool(c.VStreamDynamicPacketSize),
-		"vstream_binlog_rotation_threshold":       strconv.FormatInt(c.VStreamBinlogRotationThreshold, 10),
+		"vreplication-experimental-flags":               strconv.FormatInt(c.ExperimentalFlags, 10),
+		"vreplication-net-read-timeout":                 strconv.Itoa(c.NetReadTimeout),
+		"vreplication-net-write-timeout":                strconv.Itoa(c.NetWriteTimeout),
+		"vreplication-copy-phase-duration":              c.CopyPhaseDuration.String(),
+		"vreplication-retry-delay":                      c.RetryDelay.String(),
+		"vreplication-max-time-to-retry-on-error":       c.MaxTimeToRetryError.String(),
+		"relay_log_max_size":                            strconv.Itoa(c.RelayLogMaxSize),
+		"relay_log_max_items":                           strconv.Itoa(c.RelayLogMaxItems),
+		"vreplication-replica-lag-tolerance":            c.ReplicaLagTolerance.String(),
+		"vreplication-heartbeat-update-interval":        strconv.Itoa(c.HeartbeatUpdateInterval),
+		"vreplication-store-compressed-gtid":            strconv.FormatBool(c.StoreCompressedGTID),
+		"vreplication-parallel-insert-workers":          strconv.Itoa(c.ParallelInsertWorkers),
+		"vreplication-copy-phase-max-concurrent-tables": strconv.Itoa(c.CopyPhaseMaxConcurrentTables),
+		"vstream-packet-size":                           strconv.Itoa(c.VStreamPacketSize),
+		"vstream_packet_size":                           strconv.Itoa(c.VStreamPacketSize),
+		"vstream-dynamic-packet-size":                   strconv.FormatBool(c.VStreamDynamicPacketSize),
+		"vstream_dynamic_packet_size":                   strconv.FormatBool(c.VStreamDynamicPacketSize),
+		"vstream_binlog_rotation_threshold":             strconv.FormatInt(c.VStreamBinlogRotationThreshold, 10),
 	}
 }
 
@@ -36,23 +36,24 @@ func TestNewVReplicationConfig(t *testing.T) {
 		{
 			name: "Valid values",
 			config: map[string]string{
-				"vreplication-experimental-flags":         "3",
-				"vreplication-net-read-timeout":           "100",
-				"vreplication-net-write-timeout":          "200",
-				"vreplication-copy-phase-duration":        "2h",
-				"vreplication-retry-delay":                "10s",
-				"vreplication-max-time-to-retry-on-error": "1h",
-				"relay_log_max_size":                      "500000",
-				"relay_log_max_items":                     "10000",
-				"vreplication-replica-lag-tolerance":      "2m",
-				"vreplication-heartbeat-update-interval":  "2",
-				"vreplication-store-compressed-gtid":      "true",
-				"vreplication-parallel-insert-workers":    "4",
-				"vstream-packet-size":                     "1024",
-				"vstream_packet_size":                     "1024",
-				"vstream-dynamic-packet-size":             "false",
-				"vstream_dynamic_packet_size":             "false",
-				"vstream_binlog_rotation_threshold":       "2048",
+				"vreplication-experimental-flags":               "3",
+				"vreplication-net-read-timeout":                 "100",
+				"vreplication-net-write-timeout":                "200",
+				"vreplication-copy-phase-duration":              "2h",
+				"vreplication-retry-delay":                      "10s",
+				"vreplication-max-time-to-retry-on-error":       "1h",
+				"relay_log_max_size":                            "500000",
+				"relay_log_max_items":                           "10000",
+				"vreplication-replica-lag-tolerance":            "2m",
+				"vreplication-heartbeat-update-interval":        "2",
+				"vreplication-store-compressed-gtid":            "true",
+				"vreplication-parallel-insert-workers":          "4",
+				"vreplication-copy-phase-max-concurrent-tables": "8",
+				"vstream-packet-size":                           "1024",
+				"vstream_packet_size":                           "1024",
+				"vstream-dynamic-packet-size":                   "false",
+				"vstream_dynamic_packet_size":                   "false",
+				"vstream_binlog_rotation_threshold":             "2048",
 			},
 			wantErr: 0,
 			want: &VReplicationConfig{
@@ -68,6 +69,7 @@ func TestNewVReplicationConfig(t *testing.T) {
 				HeartbeatUpdateInterval:                2,
 				StoreCompressedGTID:                    true,
 				ParallelInsertWorkers:                  4,
+				CopyPhaseMaxConcurrentTables:           8,
 				VStreamPacketSize:                      1024,
 				VStreamDynamicPacketSize:               false,
 				VStreamBinlogRotationThreshold:         2048,
@@ -80,25 +82,26 @@ func TestNewVReplicationConfig(t *testing.T) {
 		{
 			name: "Invalid values",
 			config: map[string]string{
-				"vreplication-experimental-flags":         "invalid",
-				"vreplication-net-read-timeout":           "100.0",
-				"vreplication-net-write-timeout":          "invalid",
-				"vreplication-copy-phase-duration":        "invalid",
-				"vreplication-retry-delay":                "invalid",
-				"vreplication-max-time-to-retry-on-error": "invalid",
-				"relay_log_max_size":                      "invalid",
-				"relay_log_max_items":                     "invalid",
-				"vreplication-replica-lag-tolerance":      "invalid",
-				"vreplication-heartbeat-update-interval":  "invalid",
-				"vreplication-store-compressed-gtid":      "nottrue",
-				"vreplication-parallel-insert-workers":    "invalid",
-				"vstream-packet-size":                     "invalid",
-				"vstream_packet_size":                     "invalid",
-				"vstream-dynamic-packet-size":             "waar",
-				"vstream_dynamic_packet_size":             "waar",
-				"vstream_binlog_rotation_threshold":       "invalid",
+				"vreplication-experimental-flags":               "invalid",
+				"vreplication-net-read-timeout":                 "100.0",
+				"vreplication-net-write-timeout":                "invalid",
+				"vreplication-copy-phase-duration":              "invalid",
+				"vreplication-retry-delay":                      "invalid",
+				"vreplication-max-time-to-retry-on-error":       "invalid",
+				"relay_log_max_size":                            "invalid",
+				"relay_log_max_items":                           "invalid",
+				"vreplication-replica-lag-tolerance":            "invalid",
+				"vreplication-heartbeat-update-interval":        "invalid",
+				"vreplication-store-compressed-gtid":            "nottrue",
+				"vreplication-parallel-insert-workers":          "invalid",
+				"vreplication-copy-phase-max-concurrent-tables": "invalid",
+				"vstream-packet-size":                           "invalid",
+				"vstream_packet_size":                           "invalid",
+				"vstream-dynamic-packet-size":                   "waar",
+				"vstream_dynamic_packet_size":                   "waar",
+				"vstream_binlog_rotation_threshold":             "invalid",
 			},
-			wantErr: 17,
+			wantErr: 18,
 		},
 		{
 			name: "Partial values",
@@ -122,6 +125,7 @@ func TestNewVReplicationConfig(t *testing.T) {
 				HeartbeatUpdateInterval:          DefaultVReplicationConfig.HeartbeatUpdateInterval,
 				StoreCompressedGTID:              !DefaultVReplicationConfig.StoreCompressedGTID,
 				ParallelInsertWorkers:            DefaultVReplicationConfig.ParallelInsertWorkers,
+				CopyPhaseMaxConcurrentTables:     DefaultVReplicationConfig.CopyPhaseMaxConcurrentTables,
 				VStreamPacketSize:                DefaultVReplicationConfig.VStreamPacketSize,
 				VStreamDynamicPacketSize:         !DefaultVReplicationConfig.VStreamDynamicPacketSize,
 				VStreamBinlogRotationThreshold:   DefaultVReplicationConfig.VStreamBinlogRotationThreshold,
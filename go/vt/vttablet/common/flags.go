@@ -53,6 +53,8 @@ var (
 	vreplicationStoreCompressedGTID   = false
 	vreplicationParallelInsertWorkers = 1
 
+	vreplicationCopyPhaseMaxConcurrentTables = 1
+
 	// VStreamerBinlogRotationThreshold is the threshold, above which we rotate binlogs, before taking a GTID snapshot
 	VStreamerBinlogRotationThreshold = int64(64 * 1024 * 1024) // 64MiB
 	VStreamerDefaultPacketSize       = 250000
@@ -60,6 +62,9 @@ var (
 
 	// Enable the /debug/vrlog HTTP endpoint.
 	vreplicationEnableHttpLog = false
+
+	// The directory of the keys of the TOKENIZE column transforms.
+	vreplicationColumnTransformKeysDir = ""
 )
 
 func GetVReplicationNetReadTimeout() int {
@@ -69,6 +74,12 @@ func GetVReplicationNetWriteTimeout() int {
 	return vreplicationNetWriteTimeout
 }
 
+// GetVReplicationColumnTransformKeysDir returns the directory that holds the
+// keys of the TOKENIZE column transforms, one per file.
+func GetVReplicationColumnTransformKeysDir() string {
+	return vreplicationColumnTransformKeysDir
+}
+
 func init() {
 	servenv.OnParseFor("vttablet", registerFlags)
 	servenv.OnParseFor("vtcombo", registerFlags)
@@ -98,8 +109,10 @@ func registerFlags(fs *pflag.FlagSet) {
 	utils.SetFlagBoolVar(fs, &vreplicationStoreCompressedGTID, "vreplication-store-compressed-gtid", vreplicationStoreCompressedGTID, "Store compressed gtids in the pos column of the sidecar database's vreplication table")
 
 	fs.IntVar(&vreplicationParallelInsertWorkers, "vreplication-parallel-insert-workers", vreplicationParallelInsertWorkers, "Number of parallel insertion workers to use during copy phase. Set <= 1 to disable parallelism, or > 1 to enable concurrent insertion during copy phase.")
+	fs.IntVar(&vreplicationCopyPhaseMaxConcurrentTables, "vreplication-copy-phase-max-concurrent-tables", vreplicationCopyPhaseMaxConcurrentTables, "Maximum number of tables a workflow copies concurrently during copy phase, each from its own snapshot. Set <= 1 to copy one table at a time.")
 
 	fs.Uint64Var(&mysql.ZstdInMemoryDecompressorMaxSize, "binlog-in-memory-decompressor-max-size", mysql.ZstdInMemoryDecompressorMaxSize, "This value sets the uncompressed transaction payload size at which we switch from in-memory buffer based decompression to the slower streaming mode.")
 
 	fs.BoolVar(&vreplicationEnableHttpLog, "vreplication-enable-http-log", vreplicationEnableHttpLog, "Enable the /debug/vrlog HTTP endpoint, which will produce a log of the events replicated on primary tablets in the target keyspace by all VReplication workflows that are in the running/replicating phase.")
+	fs.StringVar(&vreplicationColumnTransformKeysDir, "vreplication-column-transform-keys-dir", vreplicationColumnTransformKeysDir, "Directory of the keys of the TOKENIZE column transforms of vreplication workflows, each in the file named by the key_name of its transforms.")
 }
@@ -45,6 +45,7 @@ const (
 	StopAction    VDiffAction = "stop"
 	ResumeAction  VDiffAction = "resume"
 	DeleteAction  VDiffAction = "delete"
+	RepairAction  VDiffAction = "repair"
 	AllActionArg              = "all"
 	LastActionArg             = "last"
 
@@ -52,7 +53,7 @@ const (
 )
 
 var (
-	Actions    = []VDiffAction{CreateAction, ShowAction, StopAction, ResumeAction, DeleteAction}
+	Actions    = []VDiffAction{CreateAction, ShowAction, StopAction, ResumeAction, DeleteAction, RepairAction}
 	ActionArgs = []string{AllActionArg, LastActionArg}
 
 	// The real zero value has nested nil pointers.
@@ -108,6 +109,10 @@ func (vde *Engine) PerformVDiffAction(ctx context.Context, req *tabletmanagerdat
 		if err := vde.handleDeleteAction(ctx, dbClient, req, resp); err != nil {
 			return nil, err
 		}
+	case RepairAction:
+		if err := vde.handleRepairAction(ctx, dbClient, req, resp); err != nil {
+			return nil, err
+		}
 	default:
 		return nil, fmt.Errorf("action %s not supported", action)
 	}
@@ -0,0 +1,262 @@
+/*
+Copyright 2025 The Vitess Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package vdiff
+
+import (
+	"bufio"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"path"
+	"strings"
+	"time"
+
+	"vitess.io/vitess/go/sqlescape"
+	"vitess.io/vitess/go/sqltypes"
+	"vitess.io/vitess/go/vt/log"
+	"vitess.io/vitess/go/vt/mysqlctl/backupstorage"
+	"vitess.io/vitess/go/vt/vterrors"
+
+	tabletmanagerdatapb "vitess.io/vitess/go/vt/proto/tabletmanagerdata"
+)
+
+// rowDiffType is the kind of difference an exported row has.
+type rowDiffType string
+
+const (
+	mismatchedRow  = rowDiffType("mismatch")
+	extraSourceRow = rowDiffType("extra_source")
+	extraTargetRow = rowDiffType("extra_target")
+)
+
+const (
+	// exportKeysFile has a JSON line with the primary key of every row that
+	// differs.
+	exportKeysFile = "keys"
+	// exportRepairFile has the statements that make the target rows match
+	// the source rows.
+	exportRepairFile = "repair"
+)
+
+// exportedKey is a line of the keys file of an export.
+type exportedKey struct {
+	Type rowDiffType
+	PK   map[string]exportedValue
+}
+
+// exportedValue is the value of a primary key column in the keys file, along
+// with its type. The value is base64 encoded, so that the values of binary
+// columns are exported as they are.
+type exportedValue struct {
+	Type  string
+	Value []byte
+}
+
+// chunkExport writes the rows of a chunk that differ to the backup storage.
+// The rows of every chunk are exported to a backup of their own, named
+// <keyspace>.<shard>.<table>.<chunk> in the <export path>/<vdiff uuid>
+// directory. A nil chunkExport exports nothing.
+type chunkExport struct {
+	td *tableDiffer
+
+	handle     backupstorage.BackupHandle
+	keysFile   io.WriteCloser
+	keys       *bufio.Writer
+	repairFile io.WriteCloser
+	repair     *bufio.Writer
+}
+
+// diffAndExport diffs the table, and exports the rows that differ when an
+// export is requested.
+func (td *tableDiffer) diffAndExport(ctx context.Context, coreOpts *tabletmanagerdatapb.VDiffCoreOptions, reportOpts *tabletmanagerdatapb.VDiffReportOptions, stop <-chan time.Time) (*DiffReport, error) {
+	if td.chunk == nil || reportOpts.GetExportPath() == "" {
+		return td.diff(ctx, coreOpts, reportOpts, stop)
+	}
+	export, err := td.startExport(ctx, reportOpts)
+	if err != nil {
+		return nil, vterrors.Wrapf(err, "failed to export the diff of table %s", td.table.Name)
+	}
+	td.export = export
+	defer func() {
+		td.export = nil
+	}()
+	dr, err := td.diff(ctx, coreOpts, reportOpts, stop)
+	if err != nil {
+		export.abort(ctx)
+		return nil, err
+	}
+	if err := export.end(ctx); err != nil {
+		return nil, vterrors.Wrapf(err, "failed to export the diff of table %s", td.table.Name)
+	}
+	return dr, nil
+}
+
+// startExport starts the backup the rows of the chunk are exported to,
+// replacing the one of a previous attempt if any.
+func (td *tableDiffer) startExport(ctx context.Context, reportOpts *tabletmanagerdatapb.VDiffReportOptions) (*chunkExport, error) {
+	bs, err := backupstorage.GetBackupStorage()
+	if err != nil {
+		return nil, err
+	}
+	bs = bs.WithParams(backupstorage.NoParams())
+	thisTablet := td.wd.ct.vde.thisTablet
+	dir := path.Join(reportOpts.GetExportPath(), td.wd.ct.uuid)
+	name := fmt.Sprintf("%s.%s.%s.%d", thisTablet.Keyspace, thisTablet.Shard, td.table.Name, td.chunk.id)
+	if err := bs.RemoveBackup(ctx, dir, name); err != nil {
+		log.Infof("No previous export of chunk %d of table %s to remove: %v", td.chunk.id, td.table.Name, err)
+	}
+	handle, err := bs.StartBackup(ctx, dir, name)
+	if err != nil {
+		return nil, err
+	}
+	export := &chunkExport{td: td, handle: handle}
+	if export.keysFile, err = handle.AddFile(ctx, exportKeysFile, -1); err != nil {
+		export.abort(ctx)
+		return nil, err
+	}
+	export.keys = bufio.NewWriter(export.keysFile)
+	if reportOpts.GetExportRepairSql() && td.canRepair() {
+		if export.repairFile, err = handle.AddFile(ctx, exportRepairFile, -1); err != nil {
+			export.abort(ctx)
+			return nil, err
+		}
+		export.repair = bufio.NewWriter(export.repairFile)
+	}
+	return export, nil
+}
+
+// canRepair returns true if the rows of the target can be repaired with the
+// values of the source rows, which is not the case when the source rows are
+// aggregated or converted to another time zone.
+func (td *tableDiffer) canRepair() bool {
+	return len(td.tablePlan.aggregates) == 0 && td.wd.ct.sourceTimeZone == ""
+}
+
+// add exports a row that differs. The source row is exported for mismatched
+// and extra source rows, and the target row for extra target rows.
+func (ce *chunkExport) add(typ rowDiffType, row []sqltypes.Value) error {
+	if ce == nil {
+		return nil
+	}
+	line, err := ce.td.exportedKey(typ, row)
+	if err != nil {
+		return err
+	}
+	if _, err := ce.keys.Write(line); err != nil {
+		return err
+	}
+	if ce.repair == nil {
+		return nil
+	}
+	_, err = ce.repair.WriteString(ce.td.repairStatement(typ, row))
+	return err
+}
+
+// end flushes the files of the export and completes its backup.
+func (ce *chunkExport) end(ctx context.Context) error {
+	if err := ce.closeFiles(); err != nil {
+		ce.abort(ctx)
+		return err
+	}
+	return ce.handle.EndBackup(ctx)
+}
+
+// abort discards the export.
+func (ce *chunkExport) abort(ctx context.Context) {
+	if ce.keysFile != nil {
+		ce.keysFile.Close()
+	}
+	if ce.repairFile != nil {
+		ce.repairFile.Close()
+	}
+	if err := ce.handle.AbortBackup(ctx); err != nil {
+		log.Errorf("Failed to abort the export of chunk %d of table %s: %v", ce.td.chunk.id, ce.td.table.Name, err)
+	}
+}
+
+func (ce *chunkExport) closeFiles() error {
+	if err := ce.keys.Flush(); err != nil {
+		return err
+	}
+	err := ce.keysFile.Close()
+	ce.keysFile = nil
+	if err != nil {
+		return err
+	}
+	if ce.repair == nil {
+		return nil
+	}
+	if err := ce.repair.Flush(); err != nil {
+		return err
+	}
+	err = ce.repairFile.Close()
+	ce.repairFile = nil
+	return err
+}
+
+// exportedKey returns the line of the keys file of a row.
+func (td *tableDiffer) exportedKey(typ rowDiffType, row []sqltypes.Value) ([]byte, error) {
+	key := exportedKey{Type: typ, PK: make(map[string]exportedValue, len(td.tablePlan.pkCols))}
+	for _, pkCol := range td.tablePlan.pkCols {
+		key.PK[td.tablePlan.compareCols[pkCol].colName] = exportedValue{
+			Type:  row[pkCol].Type().String(),
+			Value: row[pkCol].Raw(),
+		}
+	}
+	line, err := json.Marshal(key)
+	if err != nil {
+		return nil, err
+	}
+	return append(line, '\n'), nil
+}
+
+// repairStatement returns the statement that makes the target row match the
+// source row: mismatched and extra source rows are replaced, while extra
+// target rows are deleted.
+func (td *tableDiffer) repairStatement(typ rowDiffType, row []sqltypes.Value) string {
+	buf := &strings.Builder{}
+	switch typ {
+	case extraTargetRow:
+		fmt.Fprintf(buf, "delete from %s where ", sqlescape.EscapeID(td.table.Name))
+		for i, pkCol := range td.tablePlan.pkCols {
+			if i > 0 {
+				buf.WriteString(" and ")
+			}
+			fmt.Fprintf(buf, "%s = ", sqlescape.EscapeID(td.tablePlan.compareCols[pkCol].colName))
+			row[pkCol].EncodeSQLStringBuilder(buf)
+		}
+	default:
+		fmt.Fprintf(buf, "replace into %s(", sqlescape.EscapeID(td.table.Name))
+		for i, col := range td.tablePlan.compareCols {
+			if i > 0 {
+				buf.WriteString(", ")
+			}
+			buf.WriteString(sqlescape.EscapeID(col.colName))
+		}
+		buf.WriteString(") values (")
+		for i := range td.tablePlan.compareCols {
+			if i > 0 {
+				buf.WriteString(", ")
+			}
+			row[i].EncodeSQLStringBuilder(buf)
+		}
+		buf.WriteString(")")
+	}
+	buf.WriteString(";\n")
+	return buf.String()
+}
@@ -48,6 +48,13 @@ type primitiveExecutor struct {
 	resultch chan *sqltypes.Result
 	err      error
 
+	// pastEnd, if set, reports the rows that are past the end of the chunk
+	// being diffed, which end the stream.
+	pastEnd func(row []sqltypes.Value) (bool, error)
+	ended   bool
+	// fields are the fields of the rows, once they are received.
+	fields []*querypb.Field
+
 	name string // for debug purposes only
 }
 
@@ -78,16 +85,33 @@ func newPrimitiveExecutor(ctx context.Context, prim vtgateEngine.Primitive, name
 // next gets the next row in the stream for this shard, if there's currently no rows to process in the stream then wait on the
 // result channel for the shard streamer to produce them.
 func (pe *primitiveExecutor) next() ([]sqltypes.Value, error) {
+	if pe.ended {
+		return nil, nil
+	}
 	for len(pe.rows) == 0 {
 		qr, ok := <-pe.resultch
 		if !ok {
 			return nil, pe.err
 		}
+		if len(qr.Fields) != 0 {
+			pe.fields = qr.Fields
+		}
 		pe.rows = qr.Rows
 	}
 
 	row := pe.rows[0]
 	pe.rows = pe.rows[1:]
+	if pe.pastEnd != nil {
+		past, err := pe.pastEnd(row)
+		if err != nil {
+			return nil, err
+		}
+		if past {
+			pe.ended = true
+			pe.rows = nil
+			return nil, nil
+		}
+	}
 	return row, nil
 }
 
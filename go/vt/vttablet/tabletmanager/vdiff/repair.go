@@ -0,0 +1,628 @@
+/*
+Copyright 2025 The Vitess Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package vdiff
+
+import (
+	"bufio"
+	"context"
+	"encoding/json"
+	"fmt"
+	"path"
+	"slices"
+	"sort"
+	"strings"
+
+	"google.golang.org/protobuf/encoding/protojson"
+
+	"vitess.io/vitess/go/sqltypes"
+	"vitess.io/vitess/go/vt/binlog/binlogplayer"
+	"vitess.io/vitess/go/vt/log"
+	"vitess.io/vitess/go/vt/mysqlctl/backupstorage"
+	"vitess.io/vitess/go/vt/sqlparser"
+	"vitess.io/vitess/go/vt/topo/topoproto"
+	"vitess.io/vitess/go/vt/vtctl/schematools"
+	"vitess.io/vitess/go/vt/vterrors"
+
+	querypb "vitess.io/vitess/go/vt/proto/query"
+	tabletmanagerdatapb "vitess.io/vitess/go/vt/proto/tabletmanagerdata"
+	vtrpcpb "vitess.io/vitess/go/vt/proto/vtrpc"
+)
+
+// repairBatchSize is the number of keys of a table that are repaired with
+// the same snapshot.
+const repairBatchSize = 1000
+
+// tableRepair is the result of the repair of a table.
+type tableRepair struct {
+	table    string
+	keys     int64
+	deleted  int64
+	inserted int64
+	// differing is the number of keys whose rows still differ after the
+	// repair.
+	differing int64
+	// unrepaired is the number of rows that differ but were not repaired,
+	// as they were not sampled in the report of a vdiff that was not
+	// exported.
+	unrepaired int64
+}
+
+// handleRepairAction repairs the rows that a completed vdiff found to differ.
+// The rows of the source are read again for their keys, and applied to the
+// target the way the vreplication streams of the workflow apply them, while
+// the streams are stopped at the position of the source snapshot. The keys
+// are then diffed again.
+func (vde *Engine) handleRepairAction(ctx context.Context, dbClient binlogplayer.DBClient, req *tabletmanagerdatapb.VDiffRequest, resp *tabletmanagerdatapb.VDiffResponse) error {
+	query, err := sqlparser.ParseAndBind(sqlGetVDiffID, sqltypes.StringBindVariable(req.VdiffUuid))
+	if err != nil {
+		return err
+	}
+	qr, err := dbClient.ExecuteFetch(query, 1)
+	if err != nil {
+		return err
+	}
+	if len(qr.Rows) != 1 {
+		return vterrors.Errorf(vtrpcpb.Code_NOT_FOUND, "vdiff with UUID %s not found on tablet %s",
+			req.VdiffUuid, topoproto.TabletAliasString(vde.thisTablet.Alias))
+	}
+	resp.VdiffUuid = req.VdiffUuid
+	if resp.Id, err = qr.Named().Row().ToInt64("id"); err != nil {
+		return err
+	}
+	if qr, err = vde.getVDiffByID(ctx, dbClient, resp.Id); err != nil {
+		return err
+	}
+	vdiffRecord := qr.Named().Row()
+	if state := VDiffState(strings.ToLower(vdiffRecord.AsString("state", ""))); state != CompletedState {
+		return vterrors.Errorf(vtrpcpb.Code_FAILED_PRECONDITION, "vdiff with UUID %s is %s on tablet %s, only completed vdiffs can be repaired",
+			req.VdiffUuid, state, topoproto.TabletAliasString(vde.thisTablet.Alias))
+	}
+	options := optionsZeroVal.CloneVT()
+	if err := protojson.Unmarshal(vdiffRecord.AsBytes("options", []byte("{}")), options); err != nil {
+		return err
+	}
+
+	ct, err := newController(vdiffRecord, vde.dbClientFactoryDba, vde.ts, vde, options)
+	if err != nil {
+		return err
+	}
+	if err := ct.loadSources(ctx, dbClient); err != nil {
+		return err
+	}
+	wd, err := newWorkflowDiffer(ct, options, vde.collationEnv)
+	if err != nil {
+		return err
+	}
+	repairs, err := wd.repair(ctx)
+	if err != nil {
+		return err
+	}
+
+	result := &sqltypes.Result{
+		Fields: []*querypb.Field{
+			{Name: "table_name", Type: sqltypes.VarChar},
+			{Name: "repaired_keys", Type: sqltypes.Int64},
+			{Name: "deleted_rows", Type: sqltypes.Int64},
+			{Name: "inserted_rows", Type: sqltypes.Int64},
+			{Name: "differing_keys", Type: sqltypes.Int64},
+			{Name: "unrepaired_rows", Type: sqltypes.Int64},
+		},
+	}
+	for _, repair := range repairs {
+		result.Rows = append(result.Rows, []sqltypes.Value{
+			sqltypes.NewVarChar(repair.table),
+			sqltypes.NewInt64(repair.keys),
+			sqltypes.NewInt64(repair.deleted),
+			sqltypes.NewInt64(repair.inserted),
+			sqltypes.NewInt64(repair.differing),
+			sqltypes.NewInt64(repair.unrepaired),
+		})
+		insertVDiffLog(ctx, dbClient, ct.id, fmt.Sprintf("Repaired %d keys of table %s: %d rows deleted, %d rows inserted, %d keys still differ, %d differing rows not repaired",
+			repair.keys, repair.table, repair.deleted, repair.inserted, repair.differing, repair.unrepaired))
+	}
+	resp.Output = sqltypes.ResultToProto3(result)
+	return nil
+}
+
+// repair repairs the rows of the tables that were found to differ.
+func (wd *workflowDiffer) repair(ctx context.Context) ([]*tableRepair, error) {
+	dbClient := wd.ct.dbClientFactory()
+	if err := dbClient.Connect(); err != nil {
+		return nil, err
+	}
+	defer dbClient.Close()
+
+	schm, err := schematools.GetSchema(ctx, wd.ct.ts, wd.ct.tmc, wd.ct.vde.thisTablet.Alias, &tabletmanagerdatapb.GetSchemaRequest{})
+	if err != nil {
+		return nil, vterrors.Wrap(err, "GetSchema")
+	}
+	if err := wd.buildPlan(dbClient, wd.ct.filter, schm); err != nil {
+		return nil, vterrors.Wrap(err, "buildPlan")
+	}
+	query, err := sqlparser.ParseAndBind(sqlGetVDiffMismatchedTables, sqltypes.Int64BindVariable(wd.ct.id))
+	if err != nil {
+		return nil, err
+	}
+	qr, err := dbClient.ExecuteFetch(query, -1)
+	if err != nil {
+		return nil, err
+	}
+	var repairs []*tableRepair
+	for _, row := range qr.Named().Rows {
+		table := row.AsString("table_name", "")
+		td := wd.tableDiffers[table]
+		if td == nil {
+			return nil, vterrors.Errorf(vtrpcpb.Code_FAILED_PRECONDITION, "table %s is no longer part of workflow %s", table, wd.ct.workflow)
+		}
+		if !td.canRepair() || len(td.tablePlan.pkCols) == 0 {
+			return nil, vterrors.Errorf(vtrpcpb.Code_FAILED_PRECONDITION, "rows of table %s cannot be repaired", table)
+		}
+		report := &DiffReport{}
+		if err := json.Unmarshal(row.AsBytes("report", []byte("{}")), report); err != nil {
+			return nil, err
+		}
+		keys, unrepaired, err := td.repairKeys(ctx, report)
+		if err != nil {
+			return nil, vterrors.Wrapf(err, "failed to get the keys of table %s to repair", table)
+		}
+		if unrepaired > 0 {
+			log.Warningf("Only the %d sampled keys of table %s are repaired for vdiff %s, %d differing rows are not: the vdiff was not exported",
+				len(keys), table, wd.ct.uuid, unrepaired)
+		} else {
+			log.Infof("Repairing %d keys of table %s for vdiff %s", len(keys), table, wd.ct.uuid)
+		}
+		repair := &tableRepair{table: table, unrepaired: unrepaired}
+		for len(keys) > 0 {
+			batch := keys[:min(len(keys), repairBatchSize)]
+			keys = keys[len(batch):]
+			if err := td.repairBatch(ctx, batch, repair); err != nil {
+				return nil, vterrors.Wrapf(err, "failed to repair table %s", table)
+			}
+		}
+		repairs = append(repairs, repair)
+	}
+	return repairs, nil
+}
+
+// repairKeys returns the sorted primary keys of the rows of the table that
+// differ: those of the exported rows when the diff was exported, and those of
+// the sample rows of its report otherwise. A key is a row whose primary key
+// columns are set. It also returns the number of differing rows whose keys
+// were not sampled, and so cannot be repaired.
+func (td *tableDiffer) repairKeys(ctx context.Context, report *DiffReport) ([][]sqltypes.Value, int64, error) {
+	var (
+		pks        []map[string][]byte
+		unrepaired int64
+	)
+	if exportPath := td.wd.opts.ReportOptions.GetExportPath(); exportPath != "" {
+		var err error
+		if pks, err = td.exportedPKs(ctx, exportPath); err != nil {
+			return nil, 0, err
+		}
+	} else {
+		reportPK := func(row map[string]string) map[string][]byte {
+			pk := make(map[string][]byte, len(row))
+			for name, val := range row {
+				pk[name] = []byte(val)
+			}
+			return pk
+		}
+		for _, rd := range report.MismatchedRowsDiffs {
+			if rd.Source != nil {
+				pks = append(pks, reportPK(rd.Source.Row))
+			}
+		}
+		for _, rd := range report.ExtraRowsSourceDiffs {
+			pks = append(pks, reportPK(rd.Row))
+		}
+		for _, rd := range report.ExtraRowsTargetDiffs {
+			pks = append(pks, reportPK(rd.Row))
+		}
+		sampled := int64(len(report.MismatchedRowsDiffs) + len(report.ExtraRowsSourceDiffs) + len(report.ExtraRowsTargetDiffs))
+		unrepaired = max(report.MismatchedRows+report.ExtraRowsSource+report.ExtraRowsTarget-sampled, 0)
+	}
+
+	names, err := td.pkNames()
+	if err != nil {
+		return nil, 0, err
+	}
+	keys := make([][]sqltypes.Value, 0, len(pks))
+	for _, pk := range pks {
+		key := make([]sqltypes.Value, len(td.tablePlan.compareCols))
+		for i, pkCol := range td.tablePlan.pkCols {
+			var (
+				val []byte
+				ok  bool
+			)
+			for _, name := range names[i] {
+				if val, ok = pk[name]; ok {
+					break
+				}
+			}
+			if !ok {
+				return nil, 0, fmt.Errorf("primary key column %s is missing from a row", td.tablePlan.compareCols[pkCol].colName)
+			}
+			key[pkCol] = sqltypes.MakeTrusted(td.tablePlan.table.Fields[pkCol].Type, val)
+		}
+		keys = append(keys, key)
+	}
+
+	var sortErr error
+	sort.SliceStable(keys, func(i, j int) bool {
+		c, err := td.comparePKs(keys[i], keys[j])
+		if err != nil {
+			sortErr = err
+		}
+		return c < 0
+	})
+	if sortErr != nil {
+		return nil, 0, sortErr
+	}
+	return slices.CompactFunc(keys, func(a, b []sqltypes.Value) bool {
+		c, _ := td.comparePKs(a, b)
+		return c == 0
+	}), unrepaired, nil
+}
+
+// pkNames returns the names a primary key column can have in the rows of
+// reports and exports, for every primary key column.
+func (td *tableDiffer) pkNames() ([][]string, error) {
+	sourceSel, err := td.parseSelect(td.tablePlan.sourceQuery)
+	if err != nil {
+		return nil, err
+	}
+	targetSel, err := td.parseSelect(td.tablePlan.targetQuery)
+	if err != nil {
+		return nil, err
+	}
+	names := make([][]string, len(td.tablePlan.pkCols))
+	for i, pkCol := range td.tablePlan.pkCols {
+		names[i] = []string{
+			td.tablePlan.compareCols[pkCol].colName,
+			sqlparser.String(targetSel.GetColumns()[pkCol]),
+			sqlparser.String(sourceSel.GetColumns()[pkCol]),
+		}
+	}
+	return names, nil
+}
+
+// exportedPKs returns the primary keys of the rows of the table that were
+// exported by the chunks of every shard.
+func (td *tableDiffer) exportedPKs(ctx context.Context, exportPath string) ([]map[string][]byte, error) {
+	bs, err := backupstorage.GetBackupStorage()
+	if err != nil {
+		return nil, err
+	}
+	bs = bs.WithParams(backupstorage.NoParams())
+	handles, err := bs.ListBackups(ctx, path.Join(exportPath, td.wd.ct.uuid))
+	if err != nil {
+		return nil, err
+	}
+	prefix := fmt.Sprintf("%s.%s.%s.", td.wd.ct.vde.thisTablet.Keyspace, td.wd.ct.vde.thisTablet.Shard, td.table.Name)
+	var pks []map[string][]byte
+	for _, handle := range handles {
+		if !strings.HasPrefix(handle.Name(), prefix) {
+			continue
+		}
+		if err := func() error {
+			keysFile, err := handle.ReadFile(ctx, exportKeysFile)
+			if err != nil {
+				return err
+			}
+			defer keysFile.Close()
+			scanner := bufio.NewScanner(keysFile)
+			for scanner.Scan() {
+				var key exportedKey
+				if err := json.Unmarshal(scanner.Bytes(), &key); err != nil {
+					return err
+				}
+				pk := make(map[string][]byte, len(key.PK))
+				for name, val := range key.PK {
+					pk[name] = val.Value
+				}
+				pks = append(pks, pk)
+			}
+			return scanner.Err()
+		}(); err != nil {
+			return nil, vterrors.Wrapf(err, "failed to read the keys exported to %s", handle.Name())
+		}
+	}
+	return pks, nil
+}
+
+// repairBatch repairs the rows of a batch of keys, and diffs them again.
+func (td *tableDiffer) repairBatch(ctx context.Context, keys [][]sqltypes.Value, repair *tableRepair) error {
+	rtd, err := td.newRepairDiffer(keys)
+	if err != nil {
+		return err
+	}
+	defer func() {
+		if rtd.shardStreamsCancel != nil {
+			rtd.shardStreamsCancel()
+		}
+		rtd.wgShardStreamers.Wait()
+	}()
+
+	return rtd.whileTargetStreamsStopped(ctx, func(ctx context.Context) error {
+		rtd.shardStreamsCtx, rtd.shardStreamsCancel = context.WithCancel(ctx)
+
+		// The rows of the source are read from a snapshot that the streams
+		// are then synchronized with, so that the rows of the target no
+		// longer change until the streams are restarted.
+		if err := rtd.selectTablets(ctx); err != nil {
+			return err
+		}
+		if err := rtd.syncSourceStreams(ctx); err != nil {
+			return err
+		}
+		if err := rtd.startSourceDataStreams(rtd.shardStreamsCtx); err != nil {
+			return err
+		}
+		if err := rtd.syncTargetStreams(ctx); err != nil {
+			return err
+		}
+		rtd.setupRowSorters()
+
+		sourceExecutor := newPrimitiveExecutor(rtd.shardStreamsCtx, rtd.sourcePrimitive, "source")
+		var sourceRows [][]sqltypes.Value
+		for {
+			row, err := sourceExecutor.next()
+			if err != nil {
+				return err
+			}
+			if row == nil {
+				break
+			}
+			sourceRows = append(sourceRows, row)
+		}
+		if sourceRows, err = rtd.filterKeys(sourceRows, keys); err != nil {
+			return err
+		}
+		targetRows, err := rtd.readTargetRows(keys)
+		if err != nil {
+			return err
+		}
+		deletes, inserts, _, err := rtd.repairChanges(sourceRows, targetRows)
+		if err != nil {
+			return err
+		}
+		if len(deletes) > 0 || len(inserts) > 0 {
+			if err := rtd.applyRepair(ctx, sourceExecutor.fields, deletes, inserts); err != nil {
+				return err
+			}
+		}
+
+		// Verify that the rows of the target now match those of the source.
+		if targetRows, err = rtd.readTargetRows(keys); err != nil {
+			return err
+		}
+		_, _, differing, err := rtd.repairChanges(sourceRows, targetRows)
+		if err != nil {
+			return err
+		}
+		repair.keys += int64(len(keys))
+		repair.deleted += int64(len(deletes))
+		repair.inserted += int64(len(inserts))
+		repair.differing += differing
+		return nil
+	})
+}
+
+// newRepairDiffer returns a differ of the rows of the table with the given
+// keys. It streams the rows of the source from tablets of its own, and reads
+// those of the target from this tablet.
+func (td *tableDiffer) newRepairDiffer(keys [][]sqltypes.Value) (*tableDiffer, error) {
+	ct := *td.wd.ct
+	ct.sources = make(map[string]*migrationSource, len(td.wd.ct.sources))
+	for shard, source := range td.wd.ct.sources {
+		ct.sources[shard] = &migrationSource{
+			shardStreamer: &shardStreamer{shard: source.shard},
+			vrID:          source.vrID,
+			position:      source.position,
+		}
+	}
+	ct.targetShardStreamer = nil
+	wd := *td.wd
+	wd.ct = &ct
+
+	rtd := newTableDiffer(&wd, td.table, td.sourceQuery)
+	tp := *td.tablePlan
+	rtd.tablePlan = &tp
+	sourceSel, err := td.parseSelect(tp.sourceQuery)
+	if err != nil {
+		return nil, err
+	}
+	targetSel, err := td.parseSelect(tp.targetQuery)
+	if err != nil {
+		return nil, err
+	}
+	for _, pkCol := range tp.pkCols {
+		sourceCol, ok := sourceSel.GetColumns()[pkCol].(*sqlparser.AliasedExpr)
+		if !ok {
+			return nil, fmt.Errorf("unexpected primary key expression in %s", tp.sourceQuery)
+		}
+		sourceColName, ok := sourceCol.Expr.(*sqlparser.ColName)
+		if !ok {
+			return nil, fmt.Errorf("primary key column %s is not a column of the source", tp.compareCols[pkCol].colName)
+		}
+		values := keyValues(keys, pkCol)
+		sourceIn, err := td.wd.ct.vde.parser.ParseExpr(fmt.Sprintf("%s in (%s)", sqlparser.String(sourceColName), values))
+		if err != nil {
+			return nil, err
+		}
+		sourceSel.AddWhere(sourceIn)
+		targetIn, err := td.wd.ct.vde.parser.ParseExpr(fmt.Sprintf("%s in (%s)",
+			sqlparser.String(sqlparser.NewColName(tp.compareCols[pkCol].colName)), values))
+		if err != nil {
+			return nil, err
+		}
+		targetSel.AddWhere(targetIn)
+	}
+	targetSel.From = sqlparser.TableExprs{
+		&sqlparser.AliasedTableExpr{
+			Expr: sqlparser.TableName{
+				Name:      sqlparser.NewIdentifierCS(td.table.Name),
+				Qualifier: sqlparser.NewIdentifierCS(tp.dbName),
+			},
+		},
+	}
+	tp.sourceQuery = sqlparser.String(sourceSel)
+	tp.targetQuery = sqlparser.String(targetSel)
+	return rtd, nil
+}
+
+// keyValues returns the distinct values of a primary key column of the keys,
+// as a list of SQL literals.
+func keyValues(keys [][]sqltypes.Value, pkCol int) string {
+	seen := make(map[string]bool, len(keys))
+	values := make([]string, 0, len(keys))
+	for _, key := range keys {
+		buf := &strings.Builder{}
+		key[pkCol].EncodeSQLStringBuilder(buf)
+		if value := buf.String(); !seen[value] {
+			seen[value] = true
+			values = append(values, value)
+		}
+	}
+	return strings.Join(values, ", ")
+}
+
+func (td *tableDiffer) parseSelect(query string) (*sqlparser.Select, error) {
+	statement, err := td.wd.ct.vde.parser.Parse(query)
+	if err != nil {
+		return nil, err
+	}
+	sel, ok := statement.(*sqlparser.Select)
+	if !ok {
+		return nil, fmt.Errorf("unexpected: %v", sqlparser.String(statement))
+	}
+	return sel, nil
+}
+
+// readTargetRows reads the rows of the target with the keys.
+func (td *tableDiffer) readTargetRows(keys [][]sqltypes.Value) ([][]sqltypes.Value, error) {
+	dbClient := td.wd.ct.dbClientFactory()
+	if err := dbClient.Connect(); err != nil {
+		return nil, err
+	}
+	defer dbClient.Close()
+	qr, err := dbClient.ExecuteFetch(td.tablePlan.targetQuery, -1)
+	if err != nil {
+		return nil, err
+	}
+	return td.filterKeys(qr.Rows, keys)
+}
+
+// filterKeys returns the rows with the keys. The rows and the keys are sorted
+// by primary key. The rows of a primary key of several columns can have keys
+// that are not requested, as every column is filtered on its own.
+func (td *tableDiffer) filterKeys(rows, keys [][]sqltypes.Value) ([][]sqltypes.Value, error) {
+	filtered := rows[:0]
+	for i, j := 0, 0; i < len(rows) && j < len(keys); {
+		c, err := td.comparePKs(rows[i], keys[j])
+		if err != nil {
+			return nil, err
+		}
+		switch {
+		case c < 0:
+			i++
+		case c > 0:
+			j++
+		default:
+			filtered = append(filtered, rows[i])
+			i++
+		}
+	}
+	return filtered, nil
+}
+
+// repairChanges diffs the rows of the source and the target, which are sorted
+// by primary key, and returns the rows to delete from the target and those to
+// insert, along with the number of keys whose rows differ. The rows to delete
+// are the target rows with no source row, and the source rows that mismatch.
+func (td *tableDiffer) repairChanges(sourceRows, targetRows [][]sqltypes.Value) (deletes, inserts [][]sqltypes.Value, differing int64, err error) {
+	for i, j := 0, 0; i < len(sourceRows) || j < len(targetRows); {
+		var sourceRow, targetRow []sqltypes.Value
+		if i < len(sourceRows) {
+			sourceRow = sourceRows[i]
+		}
+		if j < len(targetRows) {
+			targetRow = targetRows[j]
+		}
+		c, err := td.comparePKs(sourceRow, targetRow)
+		if err != nil {
+			return nil, nil, 0, err
+		}
+		switch {
+		case c < 0:
+			inserts = append(inserts, sourceRow)
+			differing++
+			i++
+		case c > 0:
+			deletes = append(deletes, targetRow)
+			differing++
+			j++
+		default:
+			c, err = td.compare(sourceRow, targetRow, td.tablePlan.compareCols, true)
+			if err != nil {
+				return nil, nil, 0, err
+			}
+			if c != 0 {
+				deletes = append(deletes, sourceRow)
+				inserts = append(inserts, sourceRow)
+				differing++
+			}
+			i++
+			j++
+		}
+	}
+	return deletes, inserts, differing, nil
+}
+
+// applyRepair applies the changes with the plan of the first stream of the
+// workflow on this tablet.
+func (td *tableDiffer) applyRepair(ctx context.Context, fields []*querypb.Field, deletes, inserts [][]sqltypes.Value) error {
+	if len(fields) == 0 {
+		return fmt.Errorf("no fields received for the rows of table %s", td.table.Name)
+	}
+	sourceSel, err := td.parseSelect(td.tablePlan.sourceQuery)
+	if err != nil {
+		return err
+	}
+	sourceTable, ok := sourceSel.From[0].(*sqlparser.AliasedTableExpr)
+	if !ok {
+		return fmt.Errorf("unexpected: %v", sqlparser.String(sourceSel))
+	}
+	sourceTableName, err := sourceTable.TableName()
+	if err != nil {
+		return err
+	}
+	var vrID int32
+	for _, source := range td.wd.ct.sources {
+		if vrID == 0 || source.vrID < vrID {
+			vrID = source.vrID
+		}
+	}
+	toProto := func(rows [][]sqltypes.Value) []*querypb.Row {
+		protoRows := make([]*querypb.Row, 0, len(rows))
+		for _, row := range rows {
+			protoRows = append(protoRows, sqltypes.RowToProto3(row))
+		}
+		return protoRows
+	}
+	return td.wd.ct.vde.vre.RepairRows(ctx, vrID, sourceTableName.Name.String(), fields, toProto(deletes), toProto(inserts))
+}
@@ -0,0 +1,95 @@
+/*
+Copyright 2025 The Vitess Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package vdiff
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"vitess.io/vitess/go/sqltypes"
+	"vitess.io/vitess/go/vt/sqlparser"
+
+	tabletmanagerdatapb "vitess.io/vitess/go/vt/proto/tabletmanagerdata"
+)
+
+func newTestRepairDiffer() *tableDiffer {
+	td := newTestChunkDiffer(&tabletmanagerdatapb.VDiffOptions{})
+	td.wd.ct.vde.parser = sqlparser.NewTestParser()
+	td.tablePlan.sourceQuery = "select c1, c2 from t1 order by c1 asc"
+	td.tablePlan.targetQuery = "select c1, c2 from t1 order by c1 asc"
+	td.tablePlan.dbName = "vt_customer"
+	return td
+}
+
+func TestRepairKeys(t *testing.T) {
+	td := newTestRepairDiffer()
+	rowDiff := func(c1 string) *RowDiff {
+		return &RowDiff{Row: map[string]string{"c1": c1, "c2": "a"}}
+	}
+	keys, unrepaired, err := td.repairKeys(context.Background(), &DiffReport{
+		MismatchedRows:       1,
+		ExtraRowsSource:      2,
+		ExtraRowsTarget:      5,
+		MismatchedRowsDiffs:  []*DiffMismatch{{Source: rowDiff("3"), Target: rowDiff("3")}},
+		ExtraRowsSourceDiffs: []*RowDiff{rowDiff("10"), rowDiff("3")},
+		ExtraRowsTargetDiffs: []*RowDiff{rowDiff("1")},
+	})
+	require.NoError(t, err)
+	key := func(c1 int64) []sqltypes.Value {
+		return []sqltypes.Value{sqltypes.NewInt64(c1), {}}
+	}
+	assert.Equal(t, [][]sqltypes.Value{key(1), key(3), key(10)}, keys)
+	// Only one of the five extra target rows was sampled.
+	assert.EqualValues(t, 4, unrepaired)
+
+	_, _, err = td.repairKeys(context.Background(), &DiffReport{
+		ExtraRowsSourceDiffs: []*RowDiff{{Row: map[string]string{"c2": "a"}}},
+	})
+	assert.ErrorContains(t, err, "primary key column c1 is missing")
+
+	rtd, err := td.newRepairDiffer(keys)
+	require.NoError(t, err)
+	assert.Equal(t, "select c1, c2 from t1 where c1 in (1, 3, 10) order by c1 asc", rtd.tablePlan.sourceQuery)
+	assert.Equal(t, "select c1, c2 from vt_customer.t1 where c1 in (1, 3, 10) order by c1 asc", rtd.tablePlan.targetQuery)
+	assert.Equal(t, "select c1, c2 from t1 order by c1 asc", td.tablePlan.sourceQuery)
+}
+
+func TestRepairChanges(t *testing.T) {
+	td := newTestRepairDiffer()
+	row := func(c1 int64, c2 string) []sqltypes.Value {
+		return []sqltypes.Value{sqltypes.NewInt64(c1), sqltypes.NewVarChar(c2)}
+	}
+	sourceRows := [][]sqltypes.Value{row(1, "a"), row(2, "b"), row(4, "d"), row(5, "e")}
+	targetRows := [][]sqltypes.Value{row(1, "a"), row(2, "x"), row(3, "c")}
+
+	filtered, err := td.filterKeys(sourceRows, [][]sqltypes.Value{row(1, ""), row(2, ""), row(4, "")})
+	require.NoError(t, err)
+	assert.Equal(t, [][]sqltypes.Value{row(1, "a"), row(2, "b"), row(4, "d")}, filtered)
+
+	deletes, inserts, differing, err := td.repairChanges(filtered, targetRows)
+	require.NoError(t, err)
+	assert.Equal(t, [][]sqltypes.Value{row(2, "b"), row(3, "c")}, deletes)
+	assert.Equal(t, [][]sqltypes.Value{row(2, "b"), row(4, "d")}, inserts)
+	assert.EqualValues(t, 3, differing)
+
+	_, _, differing, err = td.repairChanges(filtered, filtered)
+	require.NoError(t, err)
+	assert.Zero(t, differing)
+}
@@ -30,10 +30,12 @@ const (
 	sqlGetVDiffByKeyspaceWorkflowUUID       = "select * from _vt.vdiff where keyspace = %a and workflow = %a and vdiff_uuid = %a"
 	sqlGetMostRecentVDiffByKeyspaceWorkflow = "select * from _vt.vdiff where keyspace = %a and workflow = %a order by id desc limit %a"
 	sqlGetVDiffByID                         = "select * from _vt.vdiff where id = %a"
-	sqlDeleteVDiffs                         = `delete from vd, vdt, vdl using _vt.vdiff as vd left join _vt.vdiff_table as vdt on (vd.id = vdt.vdiff_id)
+	sqlDeleteVDiffs                         = `delete from vd, vdt, vdc, vdl using _vt.vdiff as vd left join _vt.vdiff_table as vdt on (vd.id = vdt.vdiff_id)
+										left join _vt.vdiff_chunk as vdc on (vd.id = vdc.vdiff_id)
 										left join _vt.vdiff_log as vdl on (vd.id = vdl.vdiff_id)
 										where vd.keyspace = %a and vd.workflow = %a`
-	sqlDeleteVDiffByUUID = `delete from vd, vdt using _vt.vdiff as vd left join _vt.vdiff_table as vdt on (vd.id = vdt.vdiff_id)
+	sqlDeleteVDiffByUUID = `delete from vd, vdt, vdc using _vt.vdiff as vd left join _vt.vdiff_table as vdt on (vd.id = vdt.vdiff_id)
+							left join _vt.vdiff_chunk as vdc on (vd.id = vdc.vdiff_id)
 							where vd.vdiff_uuid = %a`
 	sqlVDiffSummary = `select vd.state as vdiff_state, vd.last_error as last_error, vdt.table_name as table_name,
 						vd.vdiff_uuid as 'uuid', vdt.state as table_state, vdt.table_rows as table_rows,
@@ -64,6 +66,17 @@ const (
 	sqlUpdateTableState          = "update _vt.vdiff_table set state = %a where vdiff_id = %a and table_name = %a"
 	sqlUpdateTableStateAndReport = "update _vt.vdiff_table set state = %a, rows_compared = %a, report = %a where vdiff_id = %a and table_name = %a"
 	sqlUpdateTableMismatch       = "update _vt.vdiff_table set mismatch = true where vdiff_id = %a and table_name = %a"
+	sqlGetVDiffMismatchedTables  = "select table_name as table_name, report as report from _vt.vdiff_table where vdiff_id = %a and mismatch = 1 order by table_name"
 
 	sqlGetIncompleteTables = "select table_name as table_name from _vt.vdiff_table where vdiff_id = %a and state != 'completed' order by table_name"
+
+	sqlNewVDiffChunk  = "insert into _vt.vdiff_chunk(vdiff_id, table_name, chunk, state, lower_pk, upper_pk) values(%a, %a, %a, 'pending', %a, %a)"
+	sqlGetVDiffChunks = `select chunk as chunk, state as state, lower_pk as lower_pk, upper_pk as upper_pk, report as report
+						from _vt.vdiff_chunk where vdiff_id = %a and table_name = %a order by chunk`
+	sqlUpdateChunkState          = "update _vt.vdiff_chunk set state = %a where vdiff_id = %a and table_name = %a and chunk = %a"
+	sqlUpdateChunkProgress       = "update _vt.vdiff_chunk set rows_compared = %a where vdiff_id = %a and table_name = %a and chunk = %a"
+	sqlUpdateChunkStateAndReport = "update _vt.vdiff_chunk set state = %a, rows_compared = %a, report = %a where vdiff_id = %a and table_name = %a and chunk = %a"
+	// sqlUpdateTableChunksProgress sets the rows compared of a table to the sum of those of its chunks.
+	sqlUpdateTableChunksProgress = `update _vt.vdiff_table set rows_compared = (select ifnull(sum(rows_compared), 0) from _vt.vdiff_chunk
+									where vdiff_id = %a and table_name = %a) where vdiff_id = %a and table_name = %a`
 )
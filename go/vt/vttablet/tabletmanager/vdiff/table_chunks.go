@@ -0,0 +1,439 @@
+/*
+Copyright 2025 The Vitess Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package vdiff
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"slices"
+	"strings"
+
+	"golang.org/x/sync/errgroup"
+	"google.golang.org/protobuf/encoding/prototext"
+
+	"vitess.io/vitess/go/sqlescape"
+	"vitess.io/vitess/go/sqltypes"
+	"vitess.io/vitess/go/vt/binlog/binlogplayer"
+	"vitess.io/vitess/go/vt/log"
+	"vitess.io/vitess/go/vt/sqlparser"
+	"vitess.io/vitess/go/vt/vterrors"
+
+	querypb "vitess.io/vitess/go/vt/proto/query"
+	tabletmanagerdatapb "vitess.io/vitess/go/vt/proto/tabletmanagerdata"
+)
+
+/*
+A table is diffed in chunks when chunk rows or an export path are specified.
+The table is split into ranges of primary keys of about chunk rows each, which
+are saved in _vt.vdiff_chunk before they are diffed. Each chunk is diffed from
+snapshots of its own, so that up to max concurrent chunks are diffed at a time,
+and a vdiff that is resumed only diffs the chunks that were not completed. The
+reports of the chunks are merged into the report of the table.
+*/
+
+// tableChunk is a range of the primary keys of a table.
+type tableChunk struct {
+	id    int64
+	state VDiffState
+	// lower is the last primary key before the chunk, or nil for the first
+	// chunk. upper is the last primary key of the chunk, or nil for the last
+	// chunk.
+	lower, upper *tabletmanagerdatapb.VDiffTableLastPK
+	report       *DiffReport
+	// end is a row with the values of upper at the primary key columns, which
+	// rows are compared with to know when the chunk ends.
+	end []sqltypes.Value
+}
+
+// diffInChunks returns true if the tables are diffed in chunks.
+func (wd *workflowDiffer) diffInChunks() bool {
+	return wd.opts.CoreOptions.GetChunkRows() > 0 || wd.opts.ReportOptions.GetExportPath() != ""
+}
+
+// diffTableChunks diffs the chunks of the table that are not completed yet,
+// and returns the merged report of all its chunks.
+func (wd *workflowDiffer) diffTableChunks(ctx context.Context, dbClient binlogplayer.DBClient, td *tableDiffer) (*DiffReport, error) {
+	chunks, err := td.getChunks(dbClient)
+	if err != nil {
+		return nil, err
+	}
+	if len(chunks) == 0 {
+		if chunks, err = td.createChunks(dbClient); err != nil {
+			return nil, err
+		}
+	}
+	log.Infof("Diffing table %s for vdiff %s in %d chunks", td.table.Name, wd.ct.uuid, len(chunks))
+
+	g, gctx := errgroup.WithContext(ctx)
+	g.SetLimit(int(max(1, wd.opts.CoreOptions.GetMaxConcurrentChunks())))
+	for _, chunk := range chunks {
+		if chunk.state == CompletedState {
+			continue
+		}
+		if gctx.Err() != nil {
+			break
+		}
+		g.Go(func() error {
+			return td.diffChunk(gctx, chunk)
+		})
+	}
+	if err := g.Wait(); err != nil {
+		return nil, err
+	}
+	return td.mergeChunkReports(chunks), nil
+}
+
+// diffChunk diffs a chunk of the table with a table differ of its own, and
+// saves its report.
+func (td *tableDiffer) diffChunk(ctx context.Context, chunk *tableChunk) (err error) {
+	ctx, cancel := context.WithCancel(ctx)
+	defer cancel()
+	dbClient := td.wd.ct.dbClientFactory()
+	if err := dbClient.Connect(); err != nil {
+		return err
+	}
+	defer dbClient.Close()
+
+	defer func() {
+		if err != nil {
+			if err := td.updateChunkState(dbClient, chunk, ErrorState); err != nil {
+				log.Errorf("Failed to update the state of chunk %d of table %s: %v", chunk.id, td.table.Name, err)
+			}
+		}
+	}()
+	if err := td.updateChunkState(dbClient, chunk, StartedState); err != nil {
+		return err
+	}
+	ctd := td.newChunkDiffer(chunk)
+	dr, err := ctd.wd.runTableDiff(ctx, ctd)
+	if err != nil {
+		return err
+	}
+	rpt, err := json.Marshal(dr)
+	if err != nil {
+		return err
+	}
+	query, err := sqlparser.ParseAndBind(sqlUpdateChunkStateAndReport,
+		sqltypes.StringBindVariable(string(CompletedState)),
+		sqltypes.Int64BindVariable(dr.ProcessedRows),
+		sqltypes.StringBindVariable(string(rpt)),
+		sqltypes.Int64BindVariable(td.wd.ct.id),
+		sqltypes.StringBindVariable(td.table.Name),
+		sqltypes.Int64BindVariable(chunk.id),
+	)
+	if err != nil {
+		return err
+	}
+	if _, err := dbClient.ExecuteFetch(query, 1); err != nil {
+		return err
+	}
+	chunk.state = CompletedState
+	chunk.report = dr
+	return td.updateTableChunksProgress(dbClient)
+}
+
+// newChunkDiffer returns a differ of a chunk of the table. It shares the plan
+// of the table, but streams from source and target tablets of its own.
+func (td *tableDiffer) newChunkDiffer(chunk *tableChunk) *tableDiffer {
+	ct := *td.wd.ct
+	ct.sources = make(map[string]*migrationSource, len(td.wd.ct.sources))
+	for shard, source := range td.wd.ct.sources {
+		ct.sources[shard] = &migrationSource{
+			shardStreamer: &shardStreamer{shard: source.shard},
+			vrID:          source.vrID,
+			position:      source.position,
+		}
+	}
+	ct.targetShardStreamer = nil
+	wd := *td.wd
+	wd.ct = &ct
+
+	ctd := newTableDiffer(&wd, td.table, td.sourceQuery)
+	ctd.tablePlan = td.tablePlan
+	ctd.chunk = chunk
+	if chunk.lower != nil {
+		ctd.lastTargetPK = chunk.lower.Target
+		ctd.lastSourcePK = chunk.lower.Target
+		if chunk.lower.Source != nil {
+			ctd.lastSourcePK = chunk.lower.Source
+		}
+	}
+	return ctd
+}
+
+// canSplit returns true if the table can be split into chunks, which needs
+// the rows of the source and the target to be sorted by the same primary key.
+func (tp *tablePlan) canSplit() bool {
+	return len(tp.pkCols) > 0 && len(tp.aggregates) == 0 && slices.Equal(tp.pkCols, tp.sourcePkCols)
+}
+
+// createChunks splits the table into chunks of the primary keys of the
+// target, and saves them. Tables that cannot be split are diffed in a single
+// chunk.
+func (td *tableDiffer) createChunks(dbClient binlogplayer.DBClient) ([]*tableChunk, error) {
+	var bounds []*tabletmanagerdatapb.VDiffTableLastPK
+	if chunkRows := td.wd.opts.CoreOptions.GetChunkRows(); chunkRows > 0 && td.tablePlan.canSplit() {
+		var lastPK *tabletmanagerdatapb.VDiffTableLastPK
+		for {
+			qr, err := dbClient.ExecuteFetch(td.chunkBoundQuery(lastPK, chunkRows), 1)
+			if err != nil {
+				return nil, err
+			}
+			if len(qr.Rows) == 0 {
+				break
+			}
+			row := make([]sqltypes.Value, len(td.tablePlan.compareCols))
+			for i, pkCol := range td.tablePlan.pkCols {
+				row[pkCol] = qr.Rows[0][i]
+			}
+			lastPK = td.lastPKFromRow(row)
+			bounds = append(bounds, lastPK)
+		}
+	}
+
+	chunks := make([]*tableChunk, 0, len(bounds)+1)
+	var lower *tabletmanagerdatapb.VDiffTableLastPK
+	for i := 0; i <= len(bounds); i++ {
+		chunk := &tableChunk{id: int64(i), state: PendingState, lower: lower}
+		if i < len(bounds) {
+			chunk.upper = bounds[i]
+		}
+		if err := td.insertChunk(dbClient, chunk); err != nil {
+			return nil, err
+		}
+		td.setChunkEnd(chunk)
+		chunks = append(chunks, chunk)
+		lower = chunk.upper
+	}
+	return chunks, nil
+}
+
+// chunkBoundQuery returns the query that selects the primary key of the last
+// row of the chunk that follows lastPK.
+func (td *tableDiffer) chunkBoundQuery(lastPK *tabletmanagerdatapb.VDiffTableLastPK, chunkRows int64) string {
+	cols := make([]string, len(td.tablePlan.pkCols))
+	for i, pkCol := range td.tablePlan.pkCols {
+		cols[i] = sqlescape.EscapeID(td.tablePlan.compareCols[pkCol].colName)
+	}
+	colList := strings.Join(cols, ", ")
+	buf := &strings.Builder{}
+	fmt.Fprintf(buf, "select %s from %s.%s", colList, sqlescape.EscapeID(td.wd.ct.vde.dbName), sqlescape.EscapeID(td.table.Name))
+	if lastPK != nil {
+		fmt.Fprintf(buf, " where (%s) > (", colList)
+		for i, val := range sqltypes.Proto3ToResult(lastPK.Target).Rows[0] {
+			if i > 0 {
+				buf.WriteString(", ")
+			}
+			val.EncodeSQLStringBuilder(buf)
+		}
+		buf.WriteString(")")
+	}
+	fmt.Fprintf(buf, " order by %s limit 1 offset %d", colList, chunkRows-1)
+	return buf.String()
+}
+
+// setChunkEnd sets the row that marks the end of the chunk.
+func (td *tableDiffer) setChunkEnd(chunk *tableChunk) {
+	if chunk.upper == nil {
+		return
+	}
+	upper := sqltypes.Proto3ToResult(chunk.upper.Target)
+	chunk.end = make([]sqltypes.Value, len(td.tablePlan.compareCols))
+	for i, pkCol := range td.tablePlan.pkCols {
+		chunk.end[pkCol] = upper.Rows[0][i]
+	}
+}
+
+// pastChunkEnd returns true if the row comes after the end of the chunk.
+func (td *tableDiffer) pastChunkEnd(row []sqltypes.Value) (bool, error) {
+	c, err := td.compare(row, td.chunk.end, td.tablePlan.comparePKs, false)
+	return c > 0, err
+}
+
+func (td *tableDiffer) insertChunk(dbClient binlogplayer.DBClient, chunk *tableChunk) error {
+	pkBindVar := func(lastPK *tabletmanagerdatapb.VDiffTableLastPK) (*querypb.BindVariable, error) {
+		if lastPK == nil {
+			return sqltypes.NullBindVariable, nil
+		}
+		lastPKTxt, err := prototext.Marshal(lastPK)
+		if err != nil {
+			return nil, vterrors.Wrapf(err, "failed to marshal chunk bound %+v for table %s", lastPK, td.table.Name)
+		}
+		return sqltypes.StringBindVariable(string(lastPKTxt)), nil
+	}
+	lower, err := pkBindVar(chunk.lower)
+	if err != nil {
+		return err
+	}
+	upper, err := pkBindVar(chunk.upper)
+	if err != nil {
+		return err
+	}
+	query, err := sqlparser.ParseAndBind(sqlNewVDiffChunk,
+		sqltypes.Int64BindVariable(td.wd.ct.id),
+		sqltypes.StringBindVariable(td.table.Name),
+		sqltypes.Int64BindVariable(chunk.id),
+		lower,
+		upper,
+	)
+	if err != nil {
+		return err
+	}
+	_, err = dbClient.ExecuteFetch(query, 1)
+	return err
+}
+
+// getChunks returns the saved chunks of the table.
+func (td *tableDiffer) getChunks(dbClient binlogplayer.DBClient) ([]*tableChunk, error) {
+	query, err := sqlparser.ParseAndBind(sqlGetVDiffChunks,
+		sqltypes.Int64BindVariable(td.wd.ct.id),
+		sqltypes.StringBindVariable(td.table.Name),
+	)
+	if err != nil {
+		return nil, err
+	}
+	qr, err := dbClient.ExecuteFetch(query, -1)
+	if err != nil {
+		return nil, err
+	}
+	unmarshalPK := func(row sqltypes.RowNamedValues, col string) (*tabletmanagerdatapb.VDiffTableLastPK, error) {
+		txt := row.AsBytes(col, nil)
+		if len(txt) == 0 {
+			return nil, nil
+		}
+		lastPK := &tabletmanagerdatapb.VDiffTableLastPK{}
+		if err := prototext.Unmarshal(txt, lastPK); err != nil {
+			return nil, vterrors.Wrapf(err, "failed to unmarshal chunk bound %s for table %s", string(txt), td.table.Name)
+		}
+		return lastPK, nil
+	}
+	chunks := make([]*tableChunk, 0, len(qr.Rows))
+	for _, row := range qr.Named().Rows {
+		chunk := &tableChunk{
+			id:    row.AsInt64("chunk", 0),
+			state: VDiffState(row.AsString("state", "")),
+		}
+		if chunk.lower, err = unmarshalPK(row, "lower_pk"); err != nil {
+			return nil, err
+		}
+		if chunk.upper, err = unmarshalPK(row, "upper_pk"); err != nil {
+			return nil, err
+		}
+		if chunk.state == CompletedState {
+			chunk.report = &DiffReport{}
+			if err := json.Unmarshal(row.AsBytes("report", []byte("{}")), chunk.report); err != nil {
+				return nil, err
+			}
+		}
+		td.setChunkEnd(chunk)
+		chunks = append(chunks, chunk)
+	}
+	return chunks, nil
+}
+
+func (td *tableDiffer) updateChunkState(dbClient binlogplayer.DBClient, chunk *tableChunk, state VDiffState) error {
+	query, err := sqlparser.ParseAndBind(sqlUpdateChunkState,
+		sqltypes.StringBindVariable(string(state)),
+		sqltypes.Int64BindVariable(td.wd.ct.id),
+		sqltypes.StringBindVariable(td.table.Name),
+		sqltypes.Int64BindVariable(chunk.id),
+	)
+	if err != nil {
+		return err
+	}
+	if _, err := dbClient.ExecuteFetch(query, 1); err != nil {
+		return err
+	}
+	chunk.state = state
+	return nil
+}
+
+// updateChunkProgress saves the rows compared of the chunk, and updates
+// those of the table.
+func (td *tableDiffer) updateChunkProgress(dbClient binlogplayer.DBClient, dr *DiffReport) error {
+	query, err := sqlparser.ParseAndBind(sqlUpdateChunkProgress,
+		sqltypes.Int64BindVariable(dr.ProcessedRows),
+		sqltypes.Int64BindVariable(td.wd.ct.id),
+		sqltypes.StringBindVariable(td.table.Name),
+		sqltypes.Int64BindVariable(td.chunk.id),
+	)
+	if err != nil {
+		return err
+	}
+	if _, err := dbClient.ExecuteFetch(query, 1); err != nil {
+		return err
+	}
+	if err := td.updateTableChunksProgress(dbClient); err != nil {
+		return err
+	}
+	td.wd.ct.TableDiffRowCounts.Add(td.table.Name, dr.ProcessedRows)
+	return nil
+}
+
+func (td *tableDiffer) updateTableChunksProgress(dbClient binlogplayer.DBClient) error {
+	query, err := sqlparser.ParseAndBind(sqlUpdateTableChunksProgress,
+		sqltypes.Int64BindVariable(td.wd.ct.id),
+		sqltypes.StringBindVariable(td.table.Name),
+		sqltypes.Int64BindVariable(td.wd.ct.id),
+		sqltypes.StringBindVariable(td.table.Name),
+	)
+	if err != nil {
+		return err
+	}
+	_, err = dbClient.ExecuteFetch(query, 1)
+	return err
+}
+
+// mergeChunkReports merges the reports of the chunks into a report of the
+// table, which keeps as many samples as the report of a table that is not
+// diffed in chunks.
+func (td *tableDiffer) mergeChunkReports(chunks []*tableChunk) *DiffReport {
+	maxExtraRowsToCompare := td.wd.opts.CoreOptions.GetMaxExtraRowsToCompare()
+	maxReportSampleRows := td.wd.opts.ReportOptions.GetMaxSampleRows()
+	dr := &DiffReport{TableName: td.table.Name}
+	for _, chunk := range chunks {
+		cdr := chunk.report
+		if cdr == nil {
+			continue
+		}
+		dr.ProcessedRows += cdr.ProcessedRows
+		dr.MatchingRows += cdr.MatchingRows
+		dr.MismatchedRows += cdr.MismatchedRows
+		dr.ExtraRowsSource += cdr.ExtraRowsSource
+		dr.ExtraRowsTarget += cdr.ExtraRowsTarget
+		for _, diff := range cdr.MismatchedRowsDiffs {
+			if maxReportSampleRows > 0 && int64(len(dr.MismatchedRowsDiffs)) >= maxReportSampleRows {
+				break
+			}
+			dr.MismatchedRowsDiffs = append(dr.MismatchedRowsDiffs, diff)
+		}
+		for _, diff := range cdr.ExtraRowsSourceDiffs {
+			if int64(len(dr.ExtraRowsSourceDiffs)) >= maxExtraRowsToCompare {
+				break
+			}
+			dr.ExtraRowsSourceDiffs = append(dr.ExtraRowsSourceDiffs, diff)
+		}
+		for _, diff := range cdr.ExtraRowsTargetDiffs {
+			if int64(len(dr.ExtraRowsTargetDiffs)) >= maxExtraRowsToCompare {
+				break
+			}
+			dr.ExtraRowsTargetDiffs = append(dr.ExtraRowsTargetDiffs, diff)
+		}
+	}
+	return dr
+}
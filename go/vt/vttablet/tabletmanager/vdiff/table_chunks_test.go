@@ -0,0 +1,177 @@
+/*
+Copyright 2025 The Vitess Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package vdiff
+
+import (
+	"encoding/json"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"vitess.io/vitess/go/mysql/collations"
+	"vitess.io/vitess/go/sqltypes"
+
+	tabletmanagerdatapb "vitess.io/vitess/go/vt/proto/tabletmanagerdata"
+)
+
+// newTestChunkDiffer returns a table differ of t1(c1, c2), with primary key
+// c1.
+func newTestChunkDiffer(opts *tabletmanagerdatapb.VDiffOptions) *tableDiffer {
+	cols := []compareColInfo{{0, collations.CollationBinaryID, true, "c1"}, {1, collations.CollationBinaryID, false, "c2"}}
+	table := &tabletmanagerdatapb.TableDefinition{
+		Name:   "t1",
+		Fields: sqltypes.MakeTestFields("c1|c2", "int64|varchar"),
+	}
+	wd := &workflowDiffer{
+		ct:           &controller{vde: &Engine{dbName: "vt_customer"}},
+		opts:         opts,
+		collationEnv: collations.MySQL8(),
+	}
+	td := newTableDiffer(wd, table, "select c1, c2 from t1")
+	td.tablePlan = &tablePlan{
+		table:        table,
+		compareCols:  cols,
+		comparePKs:   cols[:1],
+		pkCols:       []int{0},
+		sourcePkCols: []int{0},
+		selectPks:    []int{0},
+	}
+	return td
+}
+
+func TestChunkBounds(t *testing.T) {
+	td := newTestChunkDiffer(&tabletmanagerdatapb.VDiffOptions{})
+	assert.True(t, td.tablePlan.canSplit())
+	assert.Equal(t, "select `c1` from `vt_customer`.`t1` order by `c1` limit 1 offset 999", td.chunkBoundQuery(nil, 1000))
+
+	upper := td.lastPKFromRow([]sqltypes.Value{sqltypes.NewInt64(1000), {}})
+	assert.Equal(t, "select `c1` from `vt_customer`.`t1` where (`c1`) > (1000) order by `c1` limit 1 offset 999", td.chunkBoundQuery(upper, 1000))
+
+	td.chunk = &tableChunk{upper: upper}
+	td.setChunkEnd(td.chunk)
+	for _, tcase := range []struct {
+		c1   int64
+		want bool
+	}{{999, false}, {1000, false}, {1001, true}} {
+		got, err := td.pastChunkEnd([]sqltypes.Value{sqltypes.NewInt64(tcase.c1), sqltypes.NewVarChar("a")})
+		require.NoError(t, err)
+		assert.Equal(t, tcase.want, got, "c1 = %d", tcase.c1)
+	}
+
+	c, err := td.comparePKs(nil, []sqltypes.Value{sqltypes.NewInt64(1), sqltypes.NewVarChar("a")})
+	require.NoError(t, err)
+	assert.Equal(t, 1, c)
+	c, err = td.comparePKs([]sqltypes.Value{sqltypes.NewInt64(1), sqltypes.NewVarChar("a")}, nil)
+	require.NoError(t, err)
+	assert.Equal(t, -1, c)
+
+	td.tablePlan.sourcePkCols = []int{1}
+	assert.False(t, td.tablePlan.canSplit())
+}
+
+func TestMergeChunkReports(t *testing.T) {
+	td := newTestChunkDiffer(&tabletmanagerdatapb.VDiffOptions{
+		CoreOptions:   &tabletmanagerdatapb.VDiffCoreOptions{MaxExtraRowsToCompare: 1},
+		ReportOptions: &tabletmanagerdatapb.VDiffReportOptions{MaxSampleRows: 2},
+	})
+	rowDiff := func(c1 string) *RowDiff {
+		return &RowDiff{Row: map[string]string{"c1": c1}}
+	}
+	chunks := []*tableChunk{{
+		report: &DiffReport{
+			ProcessedRows:        10,
+			MatchingRows:         7,
+			MismatchedRows:       2,
+			ExtraRowsSource:      1,
+			MismatchedRowsDiffs:  []*DiffMismatch{{Source: rowDiff("1")}, {Source: rowDiff("2")}},
+			ExtraRowsSourceDiffs: []*RowDiff{rowDiff("3")},
+		},
+	}, {
+		// A chunk that is not diffed yet.
+	}, {
+		report: &DiffReport{
+			ProcessedRows:        10,
+			MatchingRows:         7,
+			MismatchedRows:       1,
+			ExtraRowsSource:      1,
+			ExtraRowsTarget:      1,
+			MismatchedRowsDiffs:  []*DiffMismatch{{Source: rowDiff("11")}},
+			ExtraRowsSourceDiffs: []*RowDiff{rowDiff("12")},
+			ExtraRowsTargetDiffs: []*RowDiff{rowDiff("13")},
+		},
+	}}
+	assert.Equal(t, &DiffReport{
+		TableName:            "t1",
+		ProcessedRows:        20,
+		MatchingRows:         14,
+		MismatchedRows:       3,
+		ExtraRowsSource:      2,
+		ExtraRowsTarget:      1,
+		MismatchedRowsDiffs:  []*DiffMismatch{{Source: rowDiff("1")}, {Source: rowDiff("2")}},
+		ExtraRowsSourceDiffs: []*RowDiff{rowDiff("3")},
+		ExtraRowsTargetDiffs: []*RowDiff{rowDiff("13")},
+	}, td.mergeChunkReports(chunks))
+}
+
+func TestExportRows(t *testing.T) {
+	td := newTestChunkDiffer(&tabletmanagerdatapb.VDiffOptions{})
+	row := []sqltypes.Value{sqltypes.NewInt64(1), sqltypes.NewVarChar("it's")}
+	assert.True(t, td.canRepair())
+
+	key, err := td.exportedKey(mismatchedRow, row)
+	require.NoError(t, err)
+	assert.Equal(t, `{"Type":"mismatch","PK":{"c1":{"Type":"INT64","Value":"MQ=="}}}`+"\n", string(key))
+	key, err = td.exportedKey(extraTargetRow, row)
+	require.NoError(t, err)
+	assert.Equal(t, `{"Type":"extra_target","PK":{"c1":{"Type":"INT64","Value":"MQ=="}}}`+"\n", string(key))
+
+	// Binary values are exported as they are.
+	binaryPK := []byte{0xff, 0x00, 'a', 0xc3}
+	btd := newTestChunkDiffer(&tabletmanagerdatapb.VDiffOptions{})
+	btd.tablePlan.table.Fields[0].Type = sqltypes.VarBinary
+	key, err = btd.exportedKey(mismatchedRow, []sqltypes.Value{sqltypes.MakeTrusted(sqltypes.VarBinary, binaryPK), sqltypes.NewVarChar("a")})
+	require.NoError(t, err)
+	var exported exportedKey
+	require.NoError(t, json.Unmarshal(key, &exported))
+	assert.Equal(t, exportedValue{Type: "VARBINARY", Value: binaryPK}, exported.PK["c1"])
+
+	assert.Equal(t, "replace into `t1`(`c1`, `c2`) values (1, 'it\\'s');\n", td.repairStatement(mismatchedRow, row))
+	assert.Equal(t, "replace into `t1`(`c1`, `c2`) values (1, 'it\\'s');\n", td.repairStatement(extraSourceRow, row))
+	assert.Equal(t, "delete from `t1` where `c1` = 1;\n", td.repairStatement(extraTargetRow, row))
+
+	td.wd.ct.sourceTimeZone = "US/Pacific"
+	assert.False(t, td.canRepair())
+
+	// A nil export, which is used when no export is requested, ignores rows.
+	var export *chunkExport
+	assert.NoError(t, export.add(mismatchedRow, row))
+}
+
+func TestChunkMaxDiffRuntime(t *testing.T) {
+	opts := &tabletmanagerdatapb.VDiffOptions{
+		CoreOptions: &tabletmanagerdatapb.VDiffCoreOptions{MaxDiffSeconds: 60, ChunkRows: 1000},
+	}
+	td := newTestChunkDiffer(opts)
+	td.wd.ct.options = opts
+	assert.Equal(t, time.Minute, td.wd.maxDiffRuntime(td))
+
+	// A chunk is not restarted, as it would restart from its lower bound.
+	ctd := td.newChunkDiffer(&tableChunk{id: 1})
+	assert.Greater(t, ctd.wd.maxDiffRuntime(ctd), 24*time.Hour)
+}
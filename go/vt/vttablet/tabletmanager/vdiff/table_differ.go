@@ -99,6 +99,12 @@ type tableDiffer struct {
 	wgShardStreamers   sync.WaitGroup
 	shardStreamsCtx    context.Context
 	shardStreamsCancel context.CancelFunc
+
+	// chunk is the chunk of the table that is diffed, when the table is
+	// diffed in chunks.
+	chunk *tableChunk
+	// export receives the rows that differ, when they are exported.
+	export *chunkExport
 }
 
 func newTableDiffer(wd *workflowDiffer, table *tabletmanagerdatapb.TableDefinition, sourceQuery string) *tableDiffer {
@@ -108,6 +114,32 @@ func newTableDiffer(wd *workflowDiffer, table *tabletmanagerdatapb.TableDefiniti
 // initialize
 func (td *tableDiffer) initialize(ctx context.Context) error {
 	defer td.wd.ct.TableDiffPhaseTimings.Record(fmt.Sprintf("%s.%s", td.table.Name, initializing), time.Now())
+	return td.whileTargetStreamsStopped(ctx, func(ctx context.Context) error {
+		td.shardStreamsCtx, td.shardStreamsCancel = context.WithCancel(ctx)
+
+		if err := td.selectTablets(ctx); err != nil {
+			return err
+		}
+		if err := td.syncSourceStreams(ctx); err != nil {
+			return err
+		}
+		if err := td.startSourceDataStreams(td.shardStreamsCtx); err != nil {
+			return err
+		}
+		if err := td.syncTargetStreams(ctx); err != nil {
+			return err
+		}
+		if err := td.startTargetDataStream(td.shardStreamsCtx); err != nil {
+			return err
+		}
+		td.setupRowSorters()
+		return nil
+	})
+}
+
+// whileTargetStreamsStopped locks the workflow and stops its streams on this
+// tablet, then runs cb and restarts the streams.
+func (td *tableDiffer) whileTargetStreamsStopped(ctx context.Context, cb func(ctx context.Context) error) error {
 	vdiffEngine := td.wd.ct.vde
 	vdiffEngine.snapshotMu.Lock()
 	defer vdiffEngine.snapshotMu.Unlock()
@@ -150,25 +182,7 @@ func (td *tableDiffer) initialize(ctx context.Context) error {
 		}
 	}()
 
-	td.shardStreamsCtx, td.shardStreamsCancel = context.WithCancel(ctx)
-
-	if err := td.selectTablets(ctx); err != nil {
-		return err
-	}
-	if err := td.syncSourceStreams(ctx); err != nil {
-		return err
-	}
-	if err := td.startSourceDataStreams(td.shardStreamsCtx); err != nil {
-		return err
-	}
-	if err := td.syncTargetStreams(ctx); err != nil {
-		return err
-	}
-	if err := td.startTargetDataStream(td.shardStreamsCtx); err != nil {
-		return err
-	}
-	td.setupRowSorters()
-	return nil
+	return cb(ctx)
 }
 
 func (td *tableDiffer) stopTargetVReplicationStreams(ctx context.Context, dbClient binlogplayer.DBClient) error {
@@ -498,39 +512,48 @@ func (td *tableDiffer) diff(ctx context.Context, coreOpts *tabletmanagerdatapb.V
 	}
 	defer dbClient.Close()
 
-	// We need to continue were we left off when appropriate. This can be an
-	// auto-retry on error, or a manual retry via the resume command.
-	// Otherwise the existing state will be empty and we start from scratch.
-	query, err := sqlparser.ParseAndBind(sqlGetVDiffTable,
-		sqltypes.Int64BindVariable(td.wd.ct.id),
-		sqltypes.StringBindVariable(td.table.Name),
-	)
-	if err != nil {
-		return nil, err
-	}
-	cs, err := dbClient.ExecuteFetch(query, -1)
-	if err != nil {
-		return nil, err
-	}
-	if len(cs.Rows) == 0 {
-		return nil, fmt.Errorf("no state found for vdiff table %s for vdiff_id %d on tablet %v",
-			td.table.Name, td.wd.ct.id, td.wd.ct.vde.thisTablet.Alias)
-	} else if len(cs.Rows) > 1 {
-		return nil, fmt.Errorf("invalid state found for vdiff table %s (multiple records) for vdiff_id %d on tablet %v",
-			td.table.Name, td.wd.ct.id, td.wd.ct.vde.thisTablet.Alias)
-	}
-	curState := cs.Named().Row()
-	mismatch := curState.AsBool("mismatch", false)
+	var err error
+	mismatch := false
 	dr := &DiffReport{}
-	if rpt := curState.AsBytes("report", []byte("{}")); json.Valid(rpt) {
-		if err = json.Unmarshal(rpt, dr); err != nil {
+	// A chunk is always diffed from scratch.
+	if td.chunk == nil {
+		// We need to continue were we left off when appropriate. This can be an
+		// auto-retry on error, or a manual retry via the resume command.
+		// Otherwise the existing state will be empty and we start from scratch.
+		query, err := sqlparser.ParseAndBind(sqlGetVDiffTable,
+			sqltypes.Int64BindVariable(td.wd.ct.id),
+			sqltypes.StringBindVariable(td.table.Name),
+		)
+		if err != nil {
+			return nil, err
+		}
+		cs, err := dbClient.ExecuteFetch(query, -1)
+		if err != nil {
 			return nil, err
 		}
+		if len(cs.Rows) == 0 {
+			return nil, fmt.Errorf("no state found for vdiff table %s for vdiff_id %d on tablet %v",
+				td.table.Name, td.wd.ct.id, td.wd.ct.vde.thisTablet.Alias)
+		} else if len(cs.Rows) > 1 {
+			return nil, fmt.Errorf("invalid state found for vdiff table %s (multiple records) for vdiff_id %d on tablet %v",
+				td.table.Name, td.wd.ct.id, td.wd.ct.vde.thisTablet.Alias)
+		}
+		curState := cs.Named().Row()
+		mismatch = curState.AsBool("mismatch", false)
+		if rpt := curState.AsBytes("report", []byte("{}")); json.Valid(rpt) {
+			if err = json.Unmarshal(rpt, dr); err != nil {
+				return nil, err
+			}
+		}
 	}
 	dr.TableName = td.table.Name
 
 	sourceExecutor := newPrimitiveExecutor(ctx, td.sourcePrimitive, "source")
 	targetExecutor := newPrimitiveExecutor(ctx, td.targetPrimitive, "target")
+	if td.chunk != nil && td.chunk.end != nil {
+		sourceExecutor.pastEnd = td.pastChunkEnd
+		targetExecutor.pastEnd = td.pastChunkEnd
+	}
 	var sourceRow, lastProcessedRow, targetRow []sqltypes.Value
 	advanceSource := true
 	advanceTarget := true
@@ -595,7 +618,9 @@ func (td *tableDiffer) diff(ctx context.Context, coreOpts *tabletmanagerdatapb.V
 
 		advanceSource = true
 		advanceTarget = true
-		if sourceRow == nil {
+		// The rows that are left when one side of a chunk ended are compared
+		// one by one below, so that they are all exported.
+		if sourceRow == nil && td.chunk == nil {
 			diffRow, err := td.genRowDiff(td.tablePlan.sourceQuery, targetRow, reportOpts)
 			if err != nil {
 				return nil, vterrors.Wrap(err, "unexpected error generating diff")
@@ -611,7 +636,7 @@ func (td *tableDiffer) diff(ctx context.Context, coreOpts *tabletmanagerdatapb.V
 			dr.ProcessedRows += 1 + count
 			return dr, nil
 		}
-		if targetRow == nil {
+		if targetRow == nil && td.chunk == nil {
 			// No more rows from the target but we know we have more rows from
 			// source, so drain them and update the counts.
 			diffRow, err := td.genRowDiff(td.tablePlan.sourceQuery, sourceRow, reportOpts)
@@ -631,11 +656,14 @@ func (td *tableDiffer) diff(ctx context.Context, coreOpts *tabletmanagerdatapb.V
 		dr.ProcessedRows++
 
 		// Compare pk values.
-		c, err := td.compare(sourceRow, targetRow, td.tablePlan.comparePKs, false)
+		c, err := td.comparePKs(sourceRow, targetRow)
 		switch {
 		case err != nil:
 			return nil, err
 		case c < 0:
+			if err := td.export.add(extraSourceRow, sourceRow); err != nil {
+				return nil, err
+			}
 			if dr.ExtraRowsSource < maxExtraRowsToCompare {
 				diffRow, err := td.genRowDiff(td.tablePlan.sourceQuery, sourceRow, reportOpts)
 				if err != nil {
@@ -647,6 +675,9 @@ func (td *tableDiffer) diff(ctx context.Context, coreOpts *tabletmanagerdatapb.V
 			advanceTarget = false
 			continue
 		case c > 0:
+			if err := td.export.add(extraTargetRow, targetRow); err != nil {
+				return nil, err
+			}
 			if dr.ExtraRowsTarget < maxExtraRowsToCompare {
 				diffRow, err := td.genRowDiff(td.tablePlan.targetQuery, targetRow, reportOpts)
 				if err != nil {
@@ -666,6 +697,9 @@ func (td *tableDiffer) diff(ctx context.Context, coreOpts *tabletmanagerdatapb.V
 		case err != nil:
 			return nil, err
 		case c != 0:
+			if err := td.export.add(mismatchedRow, sourceRow); err != nil {
+				return nil, err
+			}
 			// We don't do a second pass to compare mismatched rows so we can cap the slice here.
 			if maxReportSampleRows == 0 || dr.MismatchedRows < maxReportSampleRows {
 				sourceDiffRow, err := td.genRowDiff(td.tablePlan.targetQuery, sourceRow, reportOpts)
@@ -694,6 +728,18 @@ func (td *tableDiffer) diff(ctx context.Context, coreOpts *tabletmanagerdatapb.V
 	}
 }
 
+// comparePKs compares the primary keys of the rows. A missing row, which
+// only happens when diffing chunks, sorts after any other.
+func (td *tableDiffer) comparePKs(sourceRow, targetRow []sqltypes.Value) (int, error) {
+	switch {
+	case sourceRow == nil:
+		return 1, nil
+	case targetRow == nil:
+		return -1, nil
+	}
+	return td.compare(sourceRow, targetRow, td.tablePlan.comparePKs, false)
+}
+
 func (td *tableDiffer) compare(sourceRow, targetRow []sqltypes.Value, cols []compareColInfo, compareOnlyNonPKs bool) (int, error) {
 	for _, col := range cols {
 		if col.isPK && compareOnlyNonPKs {
@@ -725,6 +771,11 @@ func (td *tableDiffer) updateTableProgress(dbClient binlogplayer.DBClient, dr *D
 	if dr == nil {
 		return fmt.Errorf("cannot update progress with a nil diff report")
 	}
+	// A chunk is never restarted, see maxDiffRuntime, so its last primary
+	// key is not saved.
+	if td.chunk != nil {
+		return td.updateChunkProgress(dbClient, dr)
+	}
 
 	var err error
 	var query string
@@ -194,6 +194,49 @@ func (wd *workflowDiffer) doReconcileExtraRows(dr *DiffReport, maxExtraRowsToCom
 }
 
 func (wd *workflowDiffer) diffTable(ctx context.Context, dbClient binlogplayer.DBClient, td *tableDiffer) error {
+	log.Infof("Starting differ on table %s for vdiff %s", td.table.Name, wd.ct.uuid)
+	if err := td.updateTableState(ctx, dbClient, StartedState); err != nil {
+		return err
+	}
+
+	var (
+		diffReport *DiffReport
+		err        error
+	)
+	if wd.diffInChunks() {
+		diffReport, err = wd.diffTableChunks(ctx, dbClient, td)
+	} else {
+		diffReport, err = wd.runTableDiff(ctx, td)
+	}
+	if err != nil {
+		return err
+	}
+	log.Infof("Table diff done on table %s for vdiff %s with report: %+v", td.table.Name, wd.ct.uuid, diffReport)
+
+	if diffReport.ExtraRowsSource > 0 || diffReport.ExtraRowsTarget > 0 {
+		if err := wd.reconcileExtraRows(diffReport, wd.opts.CoreOptions.MaxExtraRowsToCompare, wd.opts.ReportOptions.MaxSampleRows); err != nil {
+			log.Errorf("Encountered an error reconciling extra rows found for table %s for vdiff %s: %v", td.table.Name, wd.ct.uuid, err)
+			return vterrors.Wrap(err, "failed to reconcile extra rows")
+		}
+	}
+
+	if diffReport.MismatchedRows > 0 || diffReport.ExtraRowsTarget > 0 || diffReport.ExtraRowsSource > 0 {
+		if err := updateTableMismatch(dbClient, wd.ct.id, td.table.Name); err != nil {
+			return err
+		}
+	}
+
+	log.Infof("Completed reconciliation on table %s for vdiff %s with updated report: %+v", td.table.Name, wd.ct.uuid, diffReport)
+	if err := td.updateTableStateAndReport(ctx, dbClient, CompletedState, diffReport); err != nil {
+		return err
+	}
+	return nil
+}
+
+// runTableDiff initializes the table differ and diffs its rows, restarting
+// the diff with new snapshots whenever it runs for longer than the max diff
+// duration.
+func (wd *workflowDiffer) runTableDiff(ctx context.Context, td *tableDiffer) (*DiffReport, error) {
 	cancelShardStreams := func() {
 		if td.shardStreamsCancel != nil {
 			td.shardStreamsCancel()
@@ -221,23 +264,14 @@ func (wd *workflowDiffer) diffTable(ctx context.Context, dbClient binlogplayer.D
 		}
 	}()
 
-	maxDiffRuntime := time.Duration(24 * time.Hour * 365) // 1 year (effectively forever)
-	if wd.ct.options.CoreOptions.MaxDiffSeconds > 0 {
-		// Restart the diff if it takes longer than the specified max diff time.
-		maxDiffRuntime = time.Duration(wd.ct.options.CoreOptions.MaxDiffSeconds) * time.Second
-	}
-
-	log.Infof("Starting differ on table %s for vdiff %s", td.table.Name, wd.ct.uuid)
-	if err := td.updateTableState(ctx, dbClient, StartedState); err != nil {
-		return err
-	}
+	maxDiffRuntime := wd.maxDiffRuntime(td)
 
 	for {
 		select {
 		case <-ctx.Done():
-			return vterrors.Errorf(vtrpcpb.Code_CANCELED, "context has expired")
+			return nil, vterrors.Errorf(vtrpcpb.Code_CANCELED, "context has expired")
 		case <-wd.ct.done:
-			return ErrVDiffStoppedByUser
+			return nil, ErrVDiffStoppedByUser
 		default:
 		}
 
@@ -255,39 +289,31 @@ func (wd *workflowDiffer) diffTable(ctx context.Context, dbClient binlogplayer.D
 			time.Sleep(30 * time.Second)
 		}
 		if err := td.initialize(ctx); err != nil { // Setup the consistent snapshots
-			return err
+			return nil, err
 		}
 		log.Infof("Table initialization done on table %s for vdiff %s", td.table.Name, wd.ct.uuid)
 		diffTimer = time.NewTimer(maxDiffRuntime)
-		diffReport, diffErr = td.diff(ctx, wd.opts.CoreOptions, wd.opts.ReportOptions, diffTimer.C)
+		diffReport, diffErr = td.diffAndExport(ctx, wd.opts.CoreOptions, wd.opts.ReportOptions, diffTimer.C)
 		if diffErr == nil { // We finished the diff successfully
-			break
+			return diffReport, nil
 		}
 		log.Errorf("Encountered an error diffing table %s for vdiff %s: %v", td.table.Name, wd.ct.uuid, diffErr)
 		if !errors.Is(diffErr, ErrMaxDiffDurationExceeded) { // We only want to retry if we hit the max-diff-duration
-			return diffErr
-		}
-	}
-	log.Infof("Table diff done on table %s for vdiff %s with report: %+v", td.table.Name, wd.ct.uuid, diffReport)
-
-	if diffReport.ExtraRowsSource > 0 || diffReport.ExtraRowsTarget > 0 {
-		if err := wd.reconcileExtraRows(diffReport, wd.opts.CoreOptions.MaxExtraRowsToCompare, wd.opts.ReportOptions.MaxSampleRows); err != nil {
-			log.Errorf("Encountered an error reconciling extra rows found for table %s for vdiff %s: %v", td.table.Name, wd.ct.uuid, err)
-			return vterrors.Wrap(err, "failed to reconcile extra rows")
-		}
-	}
-
-	if diffReport.MismatchedRows > 0 || diffReport.ExtraRowsTarget > 0 || diffReport.ExtraRowsSource > 0 {
-		if err := updateTableMismatch(dbClient, wd.ct.id, td.table.Name); err != nil {
-			return err
+			return nil, diffErr
 		}
 	}
+}
 
-	log.Infof("Completed reconciliation on table %s for vdiff %s with updated report: %+v", td.table.Name, wd.ct.uuid, diffReport)
-	if err := td.updateTableStateAndReport(ctx, dbClient, CompletedState, diffReport); err != nil {
-		return err
+// maxDiffRuntime returns how long the table diff runs before it is restarted
+// from its last primary key with new snapshots. A chunk is not restarted: it
+// is already diffed from snapshots of its own, and its rows that differ are
+// exported by a single diff of the chunk.
+func (wd *workflowDiffer) maxDiffRuntime(td *tableDiffer) time.Duration {
+	if wd.ct.options.CoreOptions.MaxDiffSeconds > 0 && td.chunk == nil {
+		// Restart the diff if it takes longer than the specified max diff time.
+		return time.Duration(wd.ct.options.CoreOptions.MaxDiffSeconds) * time.Second
 	}
-	return nil
+	return time.Duration(24 * time.Hour * 365) // 1 year (effectively forever)
 }
 
 func (wd *workflowDiffer) diff(ctx context.Context) (err error) {
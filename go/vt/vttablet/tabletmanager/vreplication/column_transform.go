@@ -0,0 +1,190 @@
+/*
+Copyright 2025 The Vitess Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package vreplication
+
+import (
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+	"unicode/utf8"
+
+	"vitess.io/vitess/go/sqltypes"
+	"vitess.io/vitess/go/vt/sqlparser"
+	"vitess.io/vitess/go/vt/vtenv"
+	"vitess.io/vitess/go/vt/vterrors"
+	"vitess.io/vitess/go/vt/vtgate/evalengine"
+
+	binlogdatapb "vitess.io/vitess/go/vt/proto/binlogdata"
+	querypb "vitess.io/vitess/go/vt/proto/query"
+	vtrpcpb "vitess.io/vitess/go/vt/proto/vtrpc"
+	vttablet "vitess.io/vitess/go/vt/vttablet/common"
+)
+
+// columnTransformKeysDir returns the directory of the keys of the TOKENIZE
+// transforms. It is a variable so that tests can override it.
+var columnTransformKeysDir = vttablet.GetVReplicationColumnTransformKeysDir
+
+// columnTransform transforms the values of a source column before they are
+// written to the target. Transforms are applied by bindFieldVal and
+// appendFromRow, so that the original values never make it into the
+// statements that are sent to the target.
+type columnTransform struct {
+	*binlogdatapb.ColumnTransform
+	env *vtenv.Environment
+	// expr is the evaluable expression of an EXPRESSION transform, in which
+	// the transformed column is at offset 0.
+	expr evalengine.Expr
+	// key is the key of a TOKENIZE transform, read from the file named by
+	// KeyName, so that it never appears in the workflow itself.
+	key []byte
+}
+
+// buildColumnTransforms validates the column transforms of a rule, and
+// prepares their expressions.
+func buildColumnTransforms(env *vtenv.Environment, transforms map[string]*binlogdatapb.ColumnTransform) (map[string]*columnTransform, error) {
+	if len(transforms) == 0 {
+		return nil, nil
+	}
+	cts := make(map[string]*columnTransform, len(transforms))
+	for column, transform := range transforms {
+		ct := &columnTransform{ColumnTransform: transform, env: env}
+		switch transform.Type {
+		case binlogdatapb.ColumnTransform_UNSPECIFIED:
+			return nil, vterrors.Errorf(vtrpcpb.Code_INVALID_ARGUMENT, "missing type for the transform of column %s", column)
+		case binlogdatapb.ColumnTransform_HASH, binlogdatapb.ColumnTransform_NULLIFY:
+		case binlogdatapb.ColumnTransform_TOKENIZE:
+			key, err := readColumnTransformKey(transform.KeyName)
+			if err != nil {
+				return nil, vterrors.Wrapf(err, "invalid key for the TOKENIZE transform of column %s", column)
+			}
+			ct.key = key
+		case binlogdatapb.ColumnTransform_TRUNCATE:
+			if transform.Length <= 0 {
+				return nil, vterrors.Errorf(vtrpcpb.Code_INVALID_ARGUMENT, "invalid length %d for the TRUNCATE transform of column %s", transform.Length, column)
+			}
+		case binlogdatapb.ColumnTransform_EXPRESSION:
+			expr, err := env.Parser().ParseExpr(transform.Expression)
+			if err != nil {
+				return nil, vterrors.Wrapf(err, "invalid expression for the transform of column %s", column)
+			}
+			ct.expr, err = evalengine.Translate(expr, &evalengine.Config{
+				ResolveColumn: func(col *sqlparser.ColName) (int, error) {
+					if !col.Qualifier.IsEmpty() || !col.Name.EqualString(column) {
+						return 0, fmt.Errorf("the transform of column %s cannot refer to %s", column, sqlparser.String(col))
+					}
+					return 0, nil
+				},
+				Collation:   env.CollationEnv().DefaultConnectionCharset(),
+				Environment: env,
+			})
+			if err != nil {
+				return nil, vterrors.Wrapf(err, "unsupported expression for the transform of column %s", column)
+			}
+		default:
+			return nil, vterrors.Errorf(vtrpcpb.Code_INVALID_ARGUMENT, "unsupported transform %v for column %s", transform.Type, column)
+		}
+		cts[column] = ct
+	}
+	return cts, nil
+}
+
+// readColumnTransformKey reads the key of a TOKENIZE transform from the file of
+// the given name in the keys directory. A trailing newline is not part of the key.
+func readColumnTransformKey(name string) ([]byte, error) {
+	if name == "" {
+		return nil, vterrors.Errorf(vtrpcpb.Code_INVALID_ARGUMENT, "missing key_name")
+	}
+	if name == "." || name == ".." || strings.ContainsAny(name, `/\`) {
+		return nil, vterrors.Errorf(vtrpcpb.Code_INVALID_ARGUMENT, "invalid key_name %q", name)
+	}
+	dir := columnTransformKeysDir()
+	if dir == "" {
+		return nil, vterrors.Errorf(vtrpcpb.Code_FAILED_PRECONDITION, "--vreplication-column-transform-keys-dir is not set")
+	}
+	key, err := os.ReadFile(filepath.Join(dir, name))
+	if err != nil {
+		return nil, vterrors.Wrapf(err, "failed to read key %s", name)
+	}
+	key = []byte(strings.TrimRight(string(key), "\r\n"))
+	if len(key) == 0 {
+		return nil, vterrors.Errorf(vtrpcpb.Code_INVALID_ARGUMENT, "empty key %s", name)
+	}
+	return key, nil
+}
+
+// apply returns the transformed value.
+func (ct *columnTransform) apply(field *querypb.Field, val sqltypes.Value) (sqltypes.Value, error) {
+	if ct.Type == binlogdatapb.ColumnTransform_EXPRESSION {
+		env := evalengine.EmptyExpressionEnv(ct.env)
+		env.Row = []sqltypes.Value{val}
+		env.Fields = []*querypb.Field{field}
+		res, err := env.Evaluate(ct.expr)
+		if err != nil {
+			return sqltypes.Value{}, vterrors.Wrapf(err, "failed to transform column %s", field.Name)
+		}
+		return res.Value(ct.env.CollationEnv().DefaultConnectionCharset()), nil
+	}
+	if val.IsNull() {
+		return val, nil
+	}
+	switch ct.Type {
+	case binlogdatapb.ColumnTransform_HASH:
+		sum := sha256.Sum256(val.Raw())
+		return sqltypes.NewVarChar(hex.EncodeToString(sum[:])), nil
+	case binlogdatapb.ColumnTransform_TOKENIZE:
+		mac := hmac.New(sha256.New, ct.key)
+		mac.Write(val.Raw())
+		return sqltypes.NewVarChar(hex.EncodeToString(mac.Sum(nil))), nil
+	case binlogdatapb.ColumnTransform_NULLIFY:
+		return sqltypes.NULL, nil
+	case binlogdatapb.ColumnTransform_TRUNCATE:
+		raw := val.Raw()
+		if !val.IsText() {
+			return sqltypes.MakeTrusted(val.Type(), raw[:min(int64(len(raw)), ct.Length)]), nil
+		}
+		// Text values keep their first characters rather than bytes.
+		n := 0
+		for i := int64(0); i < ct.Length && n < len(raw); i++ {
+			_, size := utf8.DecodeRune(raw[n:])
+			n += size
+		}
+		return sqltypes.MakeTrusted(val.Type(), raw[:n]), nil
+	}
+	return val, nil
+}
+
+// validateColumnTransforms checks that the transforms of the plan are on the
+// given source columns, and leave the columns it uses to compare and identify
+// rows alone.
+func (tp *TablePlan) validateColumnTransforms(columns []string) error {
+	for column := range tp.ColumnTransforms {
+		if !slices.Contains(columns, column) {
+			return fmt.Errorf("transform of unknown column %s", column)
+		}
+	}
+	for _, pk := range tp.PKReferences {
+		if _, ok := tp.ColumnTransforms[pk]; ok {
+			return fmt.Errorf("unsupported transform of primary key column %s", pk)
+		}
+	}
+	return nil
+}
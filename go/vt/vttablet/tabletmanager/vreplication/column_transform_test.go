@@ -0,0 +1,250 @@
+/*
+Copyright 2025 The Vitess Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package vreplication
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"vitess.io/vitess/go/bytes2"
+	"vitess.io/vitess/go/ptr"
+	"vitess.io/vitess/go/sqltypes"
+	"vitess.io/vitess/go/vt/binlog/binlogplayer"
+	"vitess.io/vitess/go/vt/sqlparser"
+	"vitess.io/vitess/go/vt/vtenv"
+
+	binlogdatapb "vitess.io/vitess/go/vt/proto/binlogdata"
+	querypb "vitess.io/vitess/go/vt/proto/query"
+	vttablet "vitess.io/vitess/go/vt/vttablet/common"
+)
+
+// setColumnTransformKey writes a key of the TOKENIZE transforms to the keys
+// directory of the test.
+func setColumnTransformKey(t *testing.T, name, key string) {
+	dir := t.TempDir()
+	require.NoError(t, os.WriteFile(filepath.Join(dir, name), []byte(key), 0o600))
+	saved := columnTransformKeysDir
+	columnTransformKeysDir = func() string { return dir }
+	t.Cleanup(func() { columnTransformKeysDir = saved })
+}
+
+func TestColumnTransformApply(t *testing.T) {
+	env := vtenv.NewTestEnv()
+	setColumnTransformKey(t, "email-key", "secret\n")
+	email := &querypb.Field{Name: "email", Type: querypb.Type_VARCHAR}
+	testcases := []struct {
+		name      string
+		transform *binlogdatapb.ColumnTransform
+		field     *querypb.Field
+		val       sqltypes.Value
+		want      sqltypes.Value
+	}{{
+		name:      "hash",
+		transform: &binlogdatapb.ColumnTransform{Type: binlogdatapb.ColumnTransform_HASH},
+		field:     email,
+		val:       sqltypes.NewVarChar("alice@example.com"),
+		want:      sqltypes.NewVarChar("ff8d9819fc0e12bf0d24892e45987e249a28dce836a85cad60e28eaaa8c6d976"),
+	}, {
+		name:      "hash of null",
+		transform: &binlogdatapb.ColumnTransform{Type: binlogdatapb.ColumnTransform_HASH},
+		field:     email,
+		val:       sqltypes.NULL,
+		want:      sqltypes.NULL,
+	}, {
+		name:      "tokenize",
+		transform: &binlogdatapb.ColumnTransform{Type: binlogdatapb.ColumnTransform_TOKENIZE, KeyName: "email-key"},
+		field:     email,
+		val:       sqltypes.NewVarChar("alice@example.com"),
+		want:      sqltypes.NewVarChar("a398d49ce1980b3642bc4dbd110121e3c953e1eadb497d50dea23e9611f83ee7"),
+	}, {
+		name:      "nullify",
+		transform: &binlogdatapb.ColumnTransform{Type: binlogdatapb.ColumnTransform_NULLIFY},
+		field:     email,
+		val:       sqltypes.NewVarChar("alice@example.com"),
+		want:      sqltypes.NULL,
+	}, {
+		name:      "truncate text",
+		transform: &binlogdatapb.ColumnTransform{Type: binlogdatapb.ColumnTransform_TRUNCATE, Length: 3},
+		field:     email,
+		val:       sqltypes.NewVarChar("ñandú"),
+		want:      sqltypes.NewVarChar("ñan"),
+	}, {
+		name:      "truncate binary",
+		transform: &binlogdatapb.ColumnTransform{Type: binlogdatapb.ColumnTransform_TRUNCATE, Length: 3},
+		field:     &querypb.Field{Name: "data", Type: querypb.Type_VARBINARY},
+		val:       sqltypes.NewVarBinary("ñandú"),
+		want:      sqltypes.NewVarBinary("ña"),
+	}, {
+		name:      "truncate short value",
+		transform: &binlogdatapb.ColumnTransform{Type: binlogdatapb.ColumnTransform_TRUNCATE, Length: 30},
+		field:     email,
+		val:       sqltypes.NewVarChar("alice"),
+		want:      sqltypes.NewVarChar("alice"),
+	}, {
+		name:      "expression",
+		transform: &binlogdatapb.ColumnTransform{Type: binlogdatapb.ColumnTransform_EXPRESSION, Expression: "concat('xxx', substr(email, instr(email, '@')))"},
+		field:     email,
+		val:       sqltypes.NewVarChar("alice@example.com"),
+		want:      sqltypes.NewVarChar("xxx@example.com"),
+	}, {
+		name:      "expression of null",
+		transform: &binlogdatapb.ColumnTransform{Type: binlogdatapb.ColumnTransform_EXPRESSION, Expression: "ifnull(email, 'none')"},
+		field:     email,
+		val:       sqltypes.NULL,
+		want:      sqltypes.NewVarChar("none"),
+	}}
+	for _, tcase := range testcases {
+		t.Run(tcase.name, func(t *testing.T) {
+			cts, err := buildColumnTransforms(env, map[string]*binlogdatapb.ColumnTransform{tcase.field.Name: tcase.transform})
+			require.NoError(t, err)
+			got, err := cts[tcase.field.Name].apply(tcase.field, tcase.val)
+			require.NoError(t, err)
+			assert.Equal(t, tcase.want.String(), got.String())
+		})
+	}
+}
+
+func TestBuildColumnTransformsErrors(t *testing.T) {
+	setColumnTransformKey(t, "empty", "\n")
+	testcases := []struct {
+		name      string
+		transform *binlogdatapb.ColumnTransform
+		wantErr   string
+	}{{
+		name:      "missing type",
+		transform: &binlogdatapb.ColumnTransform{},
+		wantErr:   "missing type for the transform of column c1",
+	}, {
+		name:      "tokenize without key name",
+		transform: &binlogdatapb.ColumnTransform{Type: binlogdatapb.ColumnTransform_TOKENIZE},
+		wantErr:   "invalid key for the TOKENIZE transform of column c1: missing key_name",
+	}, {
+		name:      "tokenize with a key path",
+		transform: &binlogdatapb.ColumnTransform{Type: binlogdatapb.ColumnTransform_TOKENIZE, KeyName: "../secret"},
+		wantErr:   `invalid key_name "../secret"`,
+	}, {
+		name:      "tokenize with a missing key",
+		transform: &binlogdatapb.ColumnTransform{Type: binlogdatapb.ColumnTransform_TOKENIZE, KeyName: "missing"},
+		wantErr:   "failed to read key missing",
+	}, {
+		name:      "tokenize with an empty key",
+		transform: &binlogdatapb.ColumnTransform{Type: binlogdatapb.ColumnTransform_TOKENIZE, KeyName: "empty"},
+		wantErr:   "empty key empty",
+	}, {
+		name:      "truncate without length",
+		transform: &binlogdatapb.ColumnTransform{Type: binlogdatapb.ColumnTransform_TRUNCATE},
+		wantErr:   "invalid length 0 for the TRUNCATE transform of column c1",
+	}, {
+		name:      "invalid expression",
+		transform: &binlogdatapb.ColumnTransform{Type: binlogdatapb.ColumnTransform_EXPRESSION, Expression: "concat("},
+		wantErr:   "invalid expression for the transform of column c1",
+	}, {
+		name:      "expression on another column",
+		transform: &binlogdatapb.ColumnTransform{Type: binlogdatapb.ColumnTransform_EXPRESSION, Expression: "concat(c1, c2)"},
+		wantErr:   "the transform of column c1 cannot refer to c2",
+	}, {
+		name:      "unknown type",
+		transform: &binlogdatapb.ColumnTransform{Type: 42},
+		wantErr:   "unsupported transform 42 for column c1",
+	}}
+	for _, tcase := range testcases {
+		t.Run(tcase.name, func(t *testing.T) {
+			_, err := buildColumnTransforms(vtenv.NewTestEnv(), map[string]*binlogdatapb.ColumnTransform{"c1": tcase.transform})
+			assert.ErrorContains(t, err, tcase.wantErr)
+		})
+	}
+}
+
+func TestColumnTransformsPlan(t *testing.T) {
+	filter := &binlogdatapb.Filter{
+		Rules: []*binlogdatapb.Rule{{
+			Match: "t1",
+			ColumnTransforms: map[string]*binlogdatapb.ColumnTransform{
+				"email": {Type: binlogdatapb.ColumnTransform_HASH},
+			},
+		}},
+	}
+	colInfos := map[string][]*ColumnInfo{
+		"t1": {&ColumnInfo{Name: "id", IsPK: true}, &ColumnInfo{Name: "email"}},
+	}
+	vttablet.InitVReplicationConfigDefaults()
+	vr := &vreplicator{workflowConfig: vttablet.DefaultVReplicationConfig}
+	plan, err := vr.buildReplicatorPlan(getSource(filter), colInfos, nil, binlogplayer.NewStats(), vtenv.NewTestEnv())
+	require.NoError(t, err)
+	tp, err := plan.buildExecutionPlan(&binlogdatapb.FieldEvent{
+		TableName: "t1",
+		Fields:    sqltypes.MakeTestFields("id|email", "int64|varchar"),
+	})
+	require.NoError(t, err)
+	require.Contains(t, tp.ColumnTransforms, "email")
+
+	bv, err := tp.bindFieldVal(tp.Fields[1], ptr.Of(sqltypes.NewVarChar("alice@example.com")))
+	require.NoError(t, err)
+	assert.Equal(t, "ff8d9819fc0e12bf0d24892e45987e249a28dce836a85cad60e28eaaa8c6d976", string(bv.Value))
+
+	filter.Rules[0].ColumnTransforms = map[string]*binlogdatapb.ColumnTransform{
+		"id": {Type: binlogdatapb.ColumnTransform_HASH},
+	}
+	plan, err = vr.buildReplicatorPlan(getSource(filter), colInfos, nil, binlogplayer.NewStats(), vtenv.NewTestEnv())
+	require.NoError(t, err)
+	_, err = plan.buildExecutionPlan(&binlogdatapb.FieldEvent{
+		TableName: "t1",
+		Fields:    sqltypes.MakeTestFields("id|email", "int64|varchar"),
+	})
+	assert.ErrorContains(t, err, "unsupported transform of primary key column id")
+
+	filter.Rules[0].ColumnTransforms = map[string]*binlogdatapb.ColumnTransform{
+		"mail": {Type: binlogdatapb.ColumnTransform_HASH},
+	}
+	plan, err = vr.buildReplicatorPlan(getSource(filter), colInfos, nil, binlogplayer.NewStats(), vtenv.NewTestEnv())
+	require.NoError(t, err)
+	_, err = plan.buildExecutionPlan(&binlogdatapb.FieldEvent{
+		TableName: "t1",
+		Fields:    sqltypes.MakeTestFields("id|email", "int64|varchar"),
+	})
+	assert.ErrorContains(t, err, "transform of unknown column mail")
+
+	filter.Rules[0].Filter = "select id, email from t1"
+	_, err = vr.buildReplicatorPlan(getSource(filter), colInfos, nil, binlogplayer.NewStats(), vtenv.NewTestEnv())
+	assert.ErrorContains(t, err, "transform of unknown column mail")
+}
+
+func TestAppendFromRowColumnTransforms(t *testing.T) {
+	cts, err := buildColumnTransforms(vtenv.NewTestEnv(), map[string]*binlogdatapb.ColumnTransform{
+		"email": {Type: binlogdatapb.ColumnTransform_TRUNCATE, Length: 3},
+		"phone": {Type: binlogdatapb.ColumnTransform_NULLIFY},
+	})
+	require.NoError(t, err)
+	tp := &TablePlan{
+		BulkInsertValues: sqlparser.BuildParsedQuery("values (%a, %a, %a)", ":id", ":email", ":phone"),
+		Fields:           sqltypes.MakeTestFields("id|email|phone", "int64|varchar|varchar"),
+		ColumnTransforms: cts,
+	}
+	buf := &bytes2.Buffer{}
+	row := sqltypes.RowToProto3([]sqltypes.Value{sqltypes.NewInt64(1), sqltypes.NewVarChar("alice@example.com"), sqltypes.NewVarChar("555-0100")})
+	require.NoError(t, tp.appendFromRow(buf, row))
+	assert.Equal(t, "values (1, 'ali', null)", buf.String())
+
+	buf = &bytes2.Buffer{}
+	row = sqltypes.RowToProto3([]sqltypes.Value{sqltypes.NewInt64(2), sqltypes.NULL, sqltypes.NULL})
+	require.NoError(t, tp.appendFromRow(buf, row))
+	assert.Equal(t, "values (2, null, null)", buf.String())
+}
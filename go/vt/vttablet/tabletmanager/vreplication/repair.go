@@ -0,0 +1,120 @@
+/*
+Copyright 2025 The Vitess Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package vreplication
+
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	"google.golang.org/protobuf/encoding/prototext"
+
+	"vitess.io/vitess/go/sqltypes"
+	"vitess.io/vitess/go/vt/binlog/binlogplayer"
+	"vitess.io/vitess/go/vt/vterrors"
+
+	binlogdatapb "vitess.io/vitess/go/vt/proto/binlogdata"
+	querypb "vitess.io/vitess/go/vt/proto/query"
+	vtrpcpb "vitess.io/vitess/go/vt/proto/vtrpc"
+)
+
+// RepairRows makes rows of the target match the rows of the source of the
+// stream, by applying them the way the stream applies row events. The
+// deletes are the source rows, or their target equivalents, whose target
+// rows are removed, and the inserts are the source rows that are then
+// inserted. The rows are in the form the source of the stream sends for
+// sourceTable, as described by fields, and are applied in one transaction.
+// The stream should be stopped while its rows are repaired.
+func (vre *Engine) RepairRows(ctx context.Context, id int32, sourceTable string, fields []*querypb.Field, deletes, inserts []*querypb.Row) error {
+	if err := func() error {
+		vre.mu.Lock()
+		defer vre.mu.Unlock()
+		if !vre.isOpen {
+			return errors.New("vreplication engine is closed")
+		}
+
+		// Ensure that the engine won't be closed while this is running.
+		vre.wg.Add(1)
+		return nil
+	}(); err != nil {
+		return err
+	}
+	defer vre.wg.Done()
+
+	dbClient := vre.dbClientFactoryFiltered()
+	if err := dbClient.Connect(); err != nil {
+		return err
+	}
+	defer dbClient.Close()
+
+	params, err := readRow(dbClient, id)
+	if err != nil {
+		return err
+	}
+	workflowConfig, err := processWorkflowOptions(params)
+	if err != nil {
+		return err
+	}
+	source := &binlogdatapb.BinlogSource{}
+	if err := prototext.Unmarshal([]byte(params["source"]), source); err != nil {
+		return err
+	}
+	if source.Filter == nil {
+		return vterrors.Errorf(vtrpcpb.Code_FAILED_PRECONDITION, "stream %d has no filter to repair rows with", id)
+	}
+	if err := setDBClientSettings(dbClient, workflowConfig); err != nil {
+		return err
+	}
+
+	stats := binlogplayer.NewStats()
+	defer stats.Stop()
+	vr := newVReplicator(id, source, nil, stats, dbClient, vre.mysqld, vre, workflowConfig)
+	colInfoMap, err := vr.buildColInfoMap(ctx)
+	if err != nil {
+		return err
+	}
+	plan, err := vr.buildReplicatorPlan(source, colInfoMap, nil, stats, vre.env)
+	if err != nil {
+		return err
+	}
+	tplan, err := plan.buildExecutionPlan(&binlogdatapb.FieldEvent{TableName: sourceTable, Fields: fields})
+	if err != nil {
+		return err
+	}
+	if tplan.Join != nil {
+		return vterrors.Errorf(vtrpcpb.Code_UNIMPLEMENTED, "rows of table %s cannot be repaired as it is the result of a join", tplan.TargetName)
+	}
+
+	applyFunc := func(sql string) (*sqltypes.Result, error) {
+		return vr.dbClient.ExecuteWithRetry(ctx, sql)
+	}
+	if err := vr.dbClient.Begin(); err != nil {
+		return err
+	}
+	defer vr.dbClient.Rollback()
+	for _, row := range deletes {
+		if _, err := tplan.applyChange(ctx, &binlogdatapb.RowChange{Before: row}, applyFunc); err != nil {
+			return fmt.Errorf("failed to delete a row of table %s: %v", tplan.TargetName, err)
+		}
+	}
+	for _, row := range inserts {
+		if _, err := tplan.applyChange(ctx, &binlogdatapb.RowChange{After: row}, applyFunc); err != nil {
+			return fmt.Errorf("failed to insert a row of table %s: %v", tplan.TargetName, err)
+		}
+	}
+	return vr.dbClient.Commit()
+}
@@ -18,6 +18,7 @@ package vreplication
 
 import (
 	"bytes"
+	"context"
 	"encoding/json"
 	"fmt"
 	"slices"
@@ -77,8 +78,9 @@ func (rp *ReplicatorPlan) buildExecutionPlan(fieldEvent *binlogdatapb.FieldEvent
 		return nil, fmt.Errorf("plan not found for %s", fieldEvent.TableName)
 	}
 	// If Insert is initialized, then it means that we knew the column
-	// names and have already built most of the plan.
-	if prelim.Insert != nil {
+	// names and have already built most of the plan. Join plans do not
+	// need more than the field info.
+	if prelim.Insert != nil || prelim.Join != nil {
 		tplanv := *prelim
 		// We know that we sent only column names, but they may be backticked.
 		// If so, we have to strip them out to allow them to match the expected
@@ -97,6 +99,14 @@ func (rp *ReplicatorPlan) buildExecutionPlan(fieldEvent *binlogdatapb.FieldEvent
 		return nil, vterrors.Wrapf(err, "failed to build replication plan for %s table", fieldEvent.TableName)
 	}
 	tplan.Fields = fieldEvent.Fields
+	tplan.ColumnTransforms = prelim.ColumnTransforms
+	columns := make([]string, 0, len(fieldEvent.Fields))
+	for _, field := range fieldEvent.Fields {
+		columns = append(columns, field.Name)
+	}
+	if err := tplan.validateColumnTransforms(columns); err != nil {
+		return nil, vterrors.Wrapf(err, "failed to build replication plan for %s table", fieldEvent.TableName)
+	}
 	return tplan, nil
 }
 
@@ -202,20 +212,31 @@ type TablePlan struct {
 	// If the plan is an insertIgnore type, then Insert
 	// and Update contain 'insert ignore' statements and
 	// Delete is nil.
-	Insert           *sqlparser.ParsedQuery
-	Update           *sqlparser.ParsedQuery
-	Delete           *sqlparser.ParsedQuery
-	MultiDelete      *sqlparser.ParsedQuery
+	Insert      *sqlparser.ParsedQuery
+	Update      *sqlparser.ParsedQuery
+	Delete      *sqlparser.ParsedQuery
+	MultiDelete *sqlparser.ParsedQuery
+	// Extremes is set if the plan has 'min(a)' or 'max(a)' columns, which
+	// are re-derived from the source when the row holding them changes.
+	Extremes *extremesPlan
+	// Join is set if the target is maintained from a join of source tables.
+	// The plan then only streams the first table of the join, and
+	// JoinedTablePlans stream the others.
+	Join             *joinPlan
+	JoinedTablePlans []*TablePlan
 	Fields           []*querypb.Field
 	ConvertIntToEnum map[string]bool
 	// PKReferences is used to check if an event changed
 	// a primary key column (row move).
 	PKReferences []string
 	// PKIndices is an array, length = #columns, true if column is part of the PK
-	PKIndices               []bool
-	Stats                   *binlogplayer.Stats
-	FieldsToSkip            map[string]bool
-	ConvertCharset          map[string](*binlogdatapb.CharsetConversion)
+	PKIndices      []bool
+	Stats          *binlogplayer.Stats
+	FieldsToSkip   map[string]bool
+	ConvertCharset map[string](*binlogdatapb.CharsetConversion)
+	// ColumnTransforms maps the names of the source columns whose values are
+	// transformed before they are written to the target to their transform.
+	ColumnTransforms        map[string]*columnTransform
 	HasExtraSourcePkColumns bool
 
 	TablePlanBuilder *tablePlanBuilder
@@ -350,6 +371,13 @@ func (tp *TablePlan) convertStringCharset(raw []byte, conversion *binlogdatapb.C
 // - enum values converted to text via Online DDL
 // - ...any other future possible values
 func (tp *TablePlan) bindFieldVal(field *querypb.Field, val *sqltypes.Value) (*querypb.BindVariable, error) {
+	if transform, ok := tp.ColumnTransforms[field.Name]; ok {
+		out, err := transform.apply(field, *val)
+		if err != nil {
+			return nil, err
+		}
+		return sqltypes.ValueBindVariable(out), nil
+	}
 	if conversion, ok := tp.ConvertCharset[field.Name]; ok && !val.IsNull() {
 		// Non-null string value, for which we have a charset conversion instruction
 		out, err := tp.convertStringCharset(val.Raw(), conversion, field.Name)
@@ -365,7 +393,10 @@ func (tp *TablePlan) bindFieldVal(field *querypb.Field, val *sqltypes.Value) (*q
 	return sqltypes.ValueBindVariable(*val), nil
 }
 
-func (tp *TablePlan) applyChange(rowChange *binlogdatapb.RowChange, executor func(string) (*sqltypes.Result, error)) (*sqltypes.Result, error) {
+func (tp *TablePlan) applyChange(ctx context.Context, rowChange *binlogdatapb.RowChange, executor func(string) (*sqltypes.Result, error)) (*sqltypes.Result, error) {
+	if tp.Join != nil {
+		return nil, tp.Join.applyChange(ctx, tp, rowChange, executor)
+	}
 	// MakeRowTrusted is needed here because Proto3ToResult is not convenient.
 	var (
 		before, after bool
@@ -453,7 +484,11 @@ func (tp *TablePlan) applyChange(rowChange *binlogdatapb.RowChange, executor fun
 		if tp.Delete == nil {
 			return nil, nil
 		}
-		return execParsedQuery(tp.Delete, bindvars, executor)
+		qr, err := execParsedQuery(tp.Delete, bindvars, executor)
+		if err != nil || tp.Extremes == nil {
+			return qr, err
+		}
+		return qr, tp.rederiveExtremes(ctx, bindvars, false, executor)
 	case before && after:
 		if !tp.pkChanged(bindvars) && !tp.HasExtraSourcePkColumns {
 			if tp.isPartial(rowChange) {
@@ -463,14 +498,22 @@ func (tp *TablePlan) applyChange(rowChange *binlogdatapb.RowChange, executor fun
 				}
 				tp.Stats.PartialQueryCount.Add([]string{"update"}, 1)
 				return execParsedQuery(upd, bindvars, executor)
-			} else {
-				return execParsedQuery(tp.Update, bindvars, executor)
 			}
+			qr, err := execParsedQuery(tp.Update, bindvars, executor)
+			if err != nil || tp.Extremes == nil {
+				return qr, err
+			}
+			return qr, tp.rederiveExtremes(ctx, bindvars, true, executor)
 		}
 		if tp.Delete != nil {
 			if _, err := execParsedQuery(tp.Delete, bindvars, executor); err != nil {
 				return nil, err
 			}
+			if tp.Extremes != nil {
+				if err := tp.rederiveExtremes(ctx, bindvars, false, executor); err != nil {
+					return nil, err
+				}
+			}
 		}
 		if tp.isOutsidePKRange(bindvars, before, after, "insert") {
 			return nil, nil
@@ -751,10 +794,22 @@ func (tp *TablePlan) appendFromRow(buf *bytes2.Buffer, row *querypb.Row) error {
 		buf.WriteString(tp.BulkInsertValues.Query[offsetQuery:loc.Offset])
 		typ := field.Type
 
-		switch typ {
-		case querypb.Type_TUPLE:
+		transform := tp.ColumnTransforms[field.Name]
+
+		switch {
+		case transform != nil:
+			val := sqltypes.NULL
+			if length >= 0 {
+				val = sqltypes.MakeTrusted(typ, row.Values[offset:offset+length])
+			}
+			out, err := transform.apply(field, val)
+			if err != nil {
+				return err
+			}
+			out.EncodeSQLBytes2(buf)
+		case typ == querypb.Type_TUPLE:
 			return vterrors.Errorf(vtrpcpb.Code_INTERNAL, "unexpected Type_TUPLE for value %d", i)
-		case querypb.Type_JSON:
+		case typ == querypb.Type_JSON:
 			if length < 0 { // An SQL NULL and not an actual JSON value
 				buf.WriteString(sqltypes.NullStr)
 			} else { // A JSON value (which may be a JSON null literal value)
@@ -25,10 +25,10 @@ import (
 	"github.com/stretchr/testify/require"
 
 	"vitess.io/vitess/go/bytes2"
-	"vitess.io/vitess/go/mysql/collations"
 	"vitess.io/vitess/go/sqltypes"
 	"vitess.io/vitess/go/vt/binlog/binlogplayer"
 	"vitess.io/vitess/go/vt/sqlparser"
+	"vitess.io/vitess/go/vt/vtenv"
 
 	binlogdatapb "vitess.io/vitess/go/vt/proto/binlogdata"
 	querypb "vitess.io/vitess/go/vt/proto/query"
@@ -322,6 +322,58 @@ func TestBuildPlayerPlan(t *testing.T) {
 				},
 			},
 		},
+	}, {
+		// aggregates
+		input: &binlogdatapb.Filter{
+			Rules: []*binlogdatapb.Rule{{
+				Match:  "t1",
+				Filter: "select c1, sum(c2 * c3) as s, count(c2 * c3) as c, avg(c2 * c3) as av, min(c2) as mn, max(c3) as mx from t2 group by c1",
+			}},
+		},
+		plan: &TestReplicatorPlan{
+			VStreamFilter: &binlogdatapb.Filter{
+				Rules: []*binlogdatapb.Rule{{
+					Match:  "t2",
+					Filter: "select c1, c2, c3, c2, c3, c2, c3, c2, c3 from t2",
+				}},
+			},
+			TargetTables: []string{"t1"},
+			TablePlans: map[string]*TestTablePlan{
+				"t2": {
+					TargetName:   "t1",
+					SendRule:     "t2",
+					PKReferences: []string{"c1"},
+					InsertFront:  "insert into t1(c1,s,c,av,mn,mx)",
+					InsertValues: "(:a_c1,ifnull(:a_c2 * :a_c3, 0),if(:a_c2 * :a_c3 is null, 0, 1),:a_c2 * :a_c3,:a_c2,:a_c3)",
+					InsertOnDup:  " on duplicate key update s=s+ifnull(values(s), 0), c=c+values(c), av=s/nullif(c, 0), mn=least(coalesce(mn, values(mn)), coalesce(values(mn), mn)), mx=greatest(coalesce(mx, values(mx)), coalesce(values(mx), mx))",
+					Insert:       "insert into t1(c1,s,c,av,mn,mx) values (:a_c1,ifnull(:a_c2 * :a_c3, 0),if(:a_c2 * :a_c3 is null, 0, 1),:a_c2 * :a_c3,:a_c2,:a_c3) on duplicate key update s=s+ifnull(values(s), 0), c=c+values(c), av=s/nullif(c, 0), mn=least(coalesce(mn, values(mn)), coalesce(values(mn), mn)), mx=greatest(coalesce(mx, values(mx)), coalesce(values(mx), mx))",
+					Update:       "update t1 set s=s-ifnull(:b_c2 * :b_c3, 0)+ifnull(:a_c2 * :a_c3, 0), c=c-if(:b_c2 * :b_c3 is null, 0, 1)+if(:a_c2 * :a_c3 is null, 0, 1), av=s/nullif(c, 0), mn=least(coalesce(mn, :a_c2), coalesce(:a_c2, mn)), mx=greatest(coalesce(mx, :a_c3), coalesce(:a_c3, mx)) where c1=:b_c1",
+					Delete:       "update t1 set s=s-ifnull(:b_c2 * :b_c3, 0), c=c-if(:b_c2 * :b_c3 is null, 0, 1), av=s/nullif(c, 0), mn=mn, mx=mx where c1=:b_c1",
+				},
+			},
+		},
+		planpk: &TestReplicatorPlan{
+			VStreamFilter: &binlogdatapb.Filter{
+				Rules: []*binlogdatapb.Rule{{
+					Match:  "t2",
+					Filter: "select c1, c2, c3, c2, c3, c2, c3, c2, c3, pk1, pk2 from t2",
+				}},
+			},
+			TargetTables: []string{"t1"},
+			TablePlans: map[string]*TestTablePlan{
+				"t2": {
+					TargetName:   "t1",
+					SendRule:     "t2",
+					PKReferences: []string{"c1", "pk1", "pk2"},
+					InsertFront:  "insert into t1(c1,s,c,av,mn,mx)",
+					InsertValues: "(:a_c1,ifnull(:a_c2 * :a_c3, 0),if(:a_c2 * :a_c3 is null, 0, 1),:a_c2 * :a_c3,:a_c2,:a_c3)",
+					InsertOnDup:  " on duplicate key update s=s+ifnull(values(s), 0), c=c+values(c), av=s/nullif(c, 0), mn=least(coalesce(mn, values(mn)), coalesce(values(mn), mn)), mx=greatest(coalesce(mx, values(mx)), coalesce(values(mx), mx))",
+					Insert:       "insert into t1(c1,s,c,av,mn,mx) select :a_c1, ifnull(:a_c2 * :a_c3, 0), if(:a_c2 * :a_c3 is null, 0, 1), :a_c2 * :a_c3, :a_c2, :a_c3 from dual where (:a_pk1,:a_pk2) <= (1,'aaa') on duplicate key update s=s+ifnull(values(s), 0), c=c+values(c), av=s/nullif(c, 0), mn=least(coalesce(mn, values(mn)), coalesce(values(mn), mn)), mx=greatest(coalesce(mx, values(mx)), coalesce(values(mx), mx))",
+					Update:       "update t1 set s=s-ifnull(:b_c2 * :b_c3, 0)+ifnull(:a_c2 * :a_c3, 0), c=c-if(:b_c2 * :b_c3 is null, 0, 1)+if(:a_c2 * :a_c3 is null, 0, 1), av=s/nullif(c, 0), mn=least(coalesce(mn, :a_c2), coalesce(:a_c2, mn)), mx=greatest(coalesce(mx, :a_c3), coalesce(:a_c3, mx)) where c1=:b_c1 and (:b_pk1,:b_pk2) <= (1,'aaa')",
+					Delete:       "update t1 set s=s-ifnull(:b_c2 * :b_c3, 0), c=c-if(:b_c2 * :b_c3 is null, 0, 1), av=s/nullif(c, 0), mn=mn, mx=mx where c1=:b_c1 and (:b_pk1,:b_pk2) <= (1,'aaa')",
+				},
+			},
+		},
 	}, {
 		input: &binlogdatapb.Filter{
 			Rules: []*binlogdatapb.Rule{{
@@ -606,14 +658,14 @@ func TestBuildPlayerPlan(t *testing.T) {
 		},
 		err: "failed to build table replication plan for t1 table: unsupported multi-table usage in query: select * from t1, t2",
 	}, {
-		// no join
+		// no join without a condition
 		input: &binlogdatapb.Filter{
 			Rules: []*binlogdatapb.Rule{{
 				Match:  "t1",
 				Filter: "select * from t1 join t2",
 			}},
 		},
-		err: "failed to build table replication plan for t1 table: unsupported from expression (*sqlparser.JoinTableExpr) in query: select * from t1 join t2",
+		err: "failed to build table replication plan for t1 table: table t2 is not joined on an equality with the other tables in query: select * from t1 join t2",
 	}, {
 		// no subqueries
 		input: &binlogdatapb.Filter{
@@ -660,14 +712,14 @@ func TestBuildPlayerPlan(t *testing.T) {
 		},
 		err: "failed to build table replication plan for t1 table: expression needs an alias: hour(c1) in query: select hour(c1) from t1",
 	}, {
-		// only count(*)
+		// count takes a single expression
 		input: &binlogdatapb.Filter{
 			Rules: []*binlogdatapb.Rule{{
 				Match:  "t1",
-				Filter: "select count(c1) as c from t1",
+				Filter: "select count(c1, c2) as c from t1",
 			}},
 		},
-		err: "failed to build table replication plan for t1 table: only count(*) is supported: count(c1) in query: select count(c1) as c from t1",
+		err: "failed to build table replication plan for t1 table: unsupported multiple expressions in count clause: count(c1, c2) in query: select count(c1, c2) as c from t1",
 	}, {
 		// no sum(*)
 		input: &binlogdatapb.Filter{
@@ -687,14 +739,50 @@ func TestBuildPlayerPlan(t *testing.T) {
 		},
 		err: "failed to build table replication plan for t1 table: syntax error at position 14 in query: select sum(a, b) as c from t1",
 	}, {
-		// no complex expr in sum
+		// no nested aggregates in sum
+		input: &binlogdatapb.Filter{
+			Rules: []*binlogdatapb.Rule{{
+				Match:  "t1",
+				Filter: "select sum(a + max(b)) as c from t1",
+			}},
+		},
+		err: "failed to build table replication plan for t1 table: unsupported aggregation function: max(b) in query: select sum(a + max(b)) as c from t1",
+	}, {
+		// no complex expr in min
+		input: &binlogdatapb.Filter{
+			Rules: []*binlogdatapb.Rule{{
+				Match:  "t1",
+				Filter: "select c1, min(a + b) as c from t1 group by c1",
+			}},
+		},
+		err: "failed to build table replication plan for t1 table: unsupported non-column name in min clause: min(a + b) in query: select c1, min(a + b) as c from t1 group by c1",
+	}, {
+		// min and max require a group by
+		input: &binlogdatapb.Filter{
+			Rules: []*binlogdatapb.Rule{{
+				Match:  "t1",
+				Filter: "select c1, max(a) as c from t1",
+			}},
+		},
+		err: "failed to build table replication plan for t1 table: unsupported min or max aggregation without a group by clause: c in query: select c1, max(a) as c from t1",
+	}, {
+		// min and max require source columns in the group by
+		input: &binlogdatapb.Filter{
+			Rules: []*binlogdatapb.Rule{{
+				Match:  "t1",
+				Filter: "select c1 + 1 as c1, max(a) as c from t1 group by c1",
+			}},
+		},
+		err: "failed to build table replication plan for t1 table: min or max aggregations require the group by expressions to be source columns: c1 in query: select c1 + 1 as c1, max(a) as c from t1 group by c1",
+	}, {
+		// avg requires sum and count
 		input: &binlogdatapb.Filter{
 			Rules: []*binlogdatapb.Rule{{
 				Match:  "t1",
-				Filter: "select sum(a + b) as c from t1",
+				Filter: "select c1, count(a) as c, avg(a) as av, sum(a) as s from t1 group by c1",
 			}},
 		},
-		err: "failed to build table replication plan for t1 table: unsupported non-column name in sum clause: sum(a + b) in query: select sum(a + b) as c from t1",
+		err: "failed to build table replication plan for t1 table: avg(a) requires sum(a) and count(a) to be selected before it in query: select c1, count(a) as c, avg(a) as av, sum(a) as s from t1 group by c1",
 	}, {
 		// no complex expr in group by
 		input: &binlogdatapb.Filter{
@@ -743,7 +831,7 @@ func TestBuildPlayerPlan(t *testing.T) {
 		vr := &vreplicator{
 			workflowConfig: vttablet.DefaultVReplicationConfig,
 		}
-		plan, err := vr.buildReplicatorPlan(getSource(tcase.input), PrimaryKeyInfos, nil, binlogplayer.NewStats(), collations.MySQL8(), sqlparser.NewTestParser())
+		plan, err := vr.buildReplicatorPlan(getSource(tcase.input), PrimaryKeyInfos, nil, binlogplayer.NewStats(), vtenv.NewTestEnv())
 		gotErr := ""
 		if err != nil {
 			gotErr = err.Error()
@@ -752,7 +840,7 @@ func TestBuildPlayerPlan(t *testing.T) {
 		gotPlan, _ := json.Marshal(plan)
 		wantPlan, _ := json.Marshal(tcase.plan)
 		require.Equal(t, string(wantPlan), string(gotPlan), "Filter(%v):\n%s, want\n%s", tcase.input, gotPlan, wantPlan)
-		plan, err = vr.buildReplicatorPlan(getSource(tcase.input), PrimaryKeyInfos, copyState, binlogplayer.NewStats(), collations.MySQL8(), sqlparser.NewTestParser())
+		plan, err = vr.buildReplicatorPlan(getSource(tcase.input), PrimaryKeyInfos, copyState, binlogplayer.NewStats(), vtenv.NewTestEnv())
 		if err != nil {
 			continue
 		}
@@ -783,7 +871,7 @@ func TestBuildPlayerPlanNoDup(t *testing.T) {
 	vr := &vreplicator{
 		workflowConfig: vttablet.DefaultVReplicationConfig,
 	}
-	_, err := vr.buildReplicatorPlan(getSource(input), PrimaryKeyInfos, nil, binlogplayer.NewStats(), collations.MySQL8(), sqlparser.NewTestParser())
+	_, err := vr.buildReplicatorPlan(getSource(input), PrimaryKeyInfos, nil, binlogplayer.NewStats(), vtenv.NewTestEnv())
 	want := "more than one target for source table t"
 	if err == nil || !strings.Contains(err.Error(), want) {
 		t.Errorf("buildReplicatorPlan err: %v, must contain: %v", err, want)
@@ -807,7 +895,7 @@ func TestBuildPlayerPlanExclude(t *testing.T) {
 	vr := &vreplicator{
 		workflowConfig: vttablet.DefaultVReplicationConfig,
 	}
-	plan, err := vr.buildReplicatorPlan(getSource(input), PrimaryKeyInfos, nil, binlogplayer.NewStats(), collations.MySQL8(), sqlparser.NewTestParser())
+	plan, err := vr.buildReplicatorPlan(getSource(input), PrimaryKeyInfos, nil, binlogplayer.NewStats(), vtenv.NewTestEnv())
 	assert.NoError(t, err)
 
 	want := &TestReplicatorPlan{
@@ -28,6 +28,7 @@ import (
 	"vitess.io/vitess/go/vt/binlog/binlogplayer"
 	"vitess.io/vitess/go/vt/key"
 	"vitess.io/vitess/go/vt/sqlparser"
+	"vitess.io/vitess/go/vt/vtenv"
 	"vitess.io/vitess/go/vt/vterrors"
 
 	binlogdatapb "vitess.io/vitess/go/vt/proto/binlogdata"
@@ -71,8 +72,10 @@ type colExpr struct {
 	colName sqlparser.IdentifierCI
 	colType querypb.Type
 	// operation==opExpr: full expression is set
-	// operation==opCount: nothing is set.
+	// operation==opCount: nothing is set for 'count(*)', and for 'count(a)', expr is set to 'a'.
 	// operation==opSum: for 'sum(a)', expr is set to 'a'.
+	// operation==opMin, opMax: for 'min(a)', expr is set to 'a'.
+	// operation==opAvg: for 'avg(a)', expr is set to 'a'.
 	operation operation
 	// expr stores the expected field name from vstreamer and dictates
 	// the generated bindvar names, like a_col or b_col.
@@ -85,6 +88,11 @@ type colExpr struct {
 	isGenerated bool
 	dataType    string
 	columnType  string
+
+	// avgSum and avgCount are the 'sum(a)' and 'count(a)' columns
+	// an 'avg(a)' column is derived from.
+	avgSum   *colExpr
+	avgCount *colExpr
 }
 
 // operation is the opcode for the colExpr.
@@ -95,6 +103,9 @@ const (
 	opExpr = operation(iota)
 	opCount
 	opSum
+	opMin
+	opMax
+	opAvg
 )
 
 // insertType describes the type of insert statement to generate.
@@ -133,7 +144,7 @@ const (
 // The TablePlan built is a partial plan. The full plan for a table is built
 // when we receive field information from events or rows sent by the source.
 // buildExecutionPlan is the function that builds the full plan.
-func (vr *vreplicator) buildReplicatorPlan(source *binlogdatapb.BinlogSource, colInfoMap map[string][]*ColumnInfo, copyState map[string]*sqltypes.Result, stats *binlogplayer.Stats, collationEnv *collations.Environment, parser *sqlparser.Parser) (*ReplicatorPlan, error) {
+func (vr *vreplicator) buildReplicatorPlan(source *binlogdatapb.BinlogSource, colInfoMap map[string][]*ColumnInfo, copyState map[string]*sqltypes.Result, stats *binlogplayer.Stats, env *vtenv.Environment) (*ReplicatorPlan, error) {
 	filter := source.Filter
 	plan := &ReplicatorPlan{
 		VStreamFilter:  &binlogdatapb.Filter{FieldEventMode: filter.FieldEventMode},
@@ -142,7 +153,7 @@ func (vr *vreplicator) buildReplicatorPlan(source *binlogdatapb.BinlogSource, co
 		ColInfoMap:     colInfoMap,
 		stats:          stats,
 		Source:         source,
-		collationEnv:   collationEnv,
+		collationEnv:   env.CollationEnv(),
 		workflowConfig: vr.workflowConfig,
 	}
 	for tableName := range colInfoMap {
@@ -162,7 +173,7 @@ func (vr *vreplicator) buildReplicatorPlan(source *binlogdatapb.BinlogSource, co
 		if !ok {
 			return nil, fmt.Errorf("table %s not found in schema", tableName)
 		}
-		tablePlan, err := buildTablePlan(tableName, rule, colInfos, lastpk, stats, source, collationEnv, parser, vr.workflowConfig)
+		tablePlan, err := buildTablePlan(tableName, rule, colInfos, lastpk, stats, source, env, vr.workflowConfig)
 		if err != nil {
 			return nil, vterrors.Wrapf(err, "failed to build table replication plan for %s table", tableName)
 		}
@@ -170,12 +181,20 @@ func (vr *vreplicator) buildReplicatorPlan(source *binlogdatapb.BinlogSource, co
 			// Table was excluded.
 			continue
 		}
-		if dup, ok := plan.TablePlans[tablePlan.SendRule.Match]; ok {
-			return nil, fmt.Errorf("more than one target for source table %s: %s and %s", tablePlan.SendRule.Match, dup.TargetName, tableName)
+		if tablePlan.Extremes != nil {
+			tablePlan.Extremes.sourceVStreamer = vr.sourceVStreamer
+		}
+		if tablePlan.Join != nil {
+			tablePlan.Join.sourceVStreamer = vr.sourceVStreamer
+		}
+		for _, tp := range append([]*TablePlan{tablePlan}, tablePlan.JoinedTablePlans...) {
+			if dup, ok := plan.TablePlans[tp.SendRule.Match]; ok {
+				return nil, fmt.Errorf("more than one target for source table %s: %s and %s", tp.SendRule.Match, dup.TargetName, tableName)
+			}
+			plan.VStreamFilter.Rules = append(plan.VStreamFilter.Rules, tp.SendRule)
+			plan.TablePlans[tp.SendRule.Match] = tp
 		}
-		plan.VStreamFilter.Rules = append(plan.VStreamFilter.Rules, tablePlan.SendRule)
 		plan.TargetTables[tableName] = tablePlan
-		plan.TablePlans[tablePlan.SendRule.Match] = tablePlan
 	}
 	return plan, nil
 }
@@ -202,8 +221,8 @@ func MatchTable(tableName string, filter *binlogdatapb.Filter) (*binlogdatapb.Ru
 }
 
 func buildTablePlan(tableName string, rule *binlogdatapb.Rule, colInfos []*ColumnInfo, lastpk *sqltypes.Result,
-	stats *binlogplayer.Stats, source *binlogdatapb.BinlogSource, collationEnv *collations.Environment,
-	parser *sqlparser.Parser, workflowConfig *vttablet.VReplicationConfig) (*TablePlan, error) {
+	stats *binlogplayer.Stats, source *binlogdatapb.BinlogSource, env *vtenv.Environment,
+	workflowConfig *vttablet.VReplicationConfig) (*TablePlan, error) {
 
 	planError := func(err error, query string) error {
 		// Use the error string here to ensure things are uniform across
@@ -226,10 +245,25 @@ func buildTablePlan(tableName string, rule *binlogdatapb.Rule, colInfos []*Colum
 	case filter == ExcludeStr:
 		return nil, nil
 	}
-	sel, fromTable, err := analyzeSelectFrom(query, parser)
+	sel, fromTable, err := analyzeSelectFrom(query, env.Parser())
 	if err != nil {
 		return nil, planError(err, query)
 	}
+	collationEnv := env.CollationEnv()
+	columnTransforms, err := buildColumnTransforms(env, rule.ColumnTransforms)
+	if err != nil {
+		return nil, err
+	}
+	if _, ok := sel.From[0].(*sqlparser.JoinTableExpr); ok {
+		if len(columnTransforms) > 0 {
+			return nil, planError(fmt.Errorf("unsupported column transforms with a join"), sqlparser.String(sel))
+		}
+		tablePlan, err := buildJoinTablePlan(tableName, sel, stats, collationEnv, workflowConfig)
+		if err != nil {
+			return nil, planError(err, sqlparser.String(sel))
+		}
+		return tablePlan, nil
+	}
 	sendRule := &binlogdatapb.Rule{
 		Match: fromTable,
 	}
@@ -251,6 +285,7 @@ func buildTablePlan(tableName string, rule *binlogdatapb.Rule, colInfos []*Colum
 			Stats:            stats,
 			ConvertCharset:   rule.ConvertCharset,
 			ConvertIntToEnum: rule.ConvertIntToEnum,
+			ColumnTransforms: columnTransforms,
 			CollationEnv:     collationEnv,
 			WorkflowConfig:   workflowConfig,
 		}
@@ -290,6 +325,9 @@ func buildTablePlan(tableName string, rule *binlogdatapb.Rule, colInfos []*Colum
 	if err := tpb.analyzeGroupBy(sel.GroupBy); err != nil {
 		return nil, planError(err, sqlparser.String(sel))
 	}
+	if err := tpb.analyzeAggregates(); err != nil {
+		return nil, planError(err, sqlparser.String(sel))
+	}
 	targetKeyColumnNames, err := textutil.SplitUnescape(rule.TargetUniqueKeyColumns, ",")
 	if err != nil {
 		return nil, err
@@ -333,6 +371,10 @@ func buildTablePlan(tableName string, rule *binlogdatapb.Rule, colInfos []*Colum
 	tablePlan.SendRule = sendRule
 	tablePlan.ConvertCharset = rule.ConvertCharset
 	tablePlan.ConvertIntToEnum = rule.ConvertIntToEnum
+	tablePlan.ColumnTransforms = columnTransforms
+	if err := tablePlan.validateColumnTransforms(tpb.sendColumns()); err != nil {
+		return nil, planError(err, sqlparser.String(sel))
+	}
 	return tablePlan, nil
 }
 
@@ -372,6 +414,7 @@ func (tpb *tablePlanBuilder) generate() *TablePlan {
 		Update:                  tpb.generateUpdateStatement(),
 		Delete:                  tpb.generateDeleteStatement(),
 		MultiDelete:             tpb.generateMultiDeleteStatement(),
+		Extremes:                tpb.generateExtremes(),
 		PKReferences:            pkrefs,
 		PKIndices:               tpb.pkIndices,
 		Stats:                   tpb.stats,
@@ -400,6 +443,10 @@ func analyzeSelectFrom(query string, parser *sqlparser.Parser) (sel *sqlparser.S
 	if len(sel.From) > 1 {
 		return nil, "", fmt.Errorf("unsupported multi-table usage")
 	}
+	if _, ok := sel.From[0].(*sqlparser.JoinTableExpr); ok {
+		// Joins are planned by buildJoinTablePlan.
+		return sel, "", nil
+	}
 	node, ok := sel.From[0].(*sqlparser.AliasedTableExpr)
 	if !ok {
 		return nil, "", fmt.Errorf("unsupported from expression (%T)", sel.From[0])
@@ -489,30 +536,51 @@ func (tpb *tablePlanBuilder) analyzeExpr(selExpr sqlparser.SelectExpr) (*colExpr
 		}
 		switch fname := expr.AggrName(); fname {
 		case "count":
-			if _, ok := expr.(*sqlparser.CountStar); !ok {
-				return nil, fmt.Errorf("only count(*) is supported: %v", sqlparser.String(expr))
-			}
 			cexpr.operation = opCount
-			return cexpr, nil
-		case "sum":
+			if _, ok := expr.(*sqlparser.CountStar); ok {
+				return cexpr, nil
+			}
 			if len(expr.GetArgs()) != 1 {
-				return nil, fmt.Errorf("unsupported multiple columns in sum clause: %v", sqlparser.String(expr))
+				return nil, fmt.Errorf("unsupported multiple expressions in count clause: %v", sqlparser.String(expr))
+			}
+			cexpr.expr = expr.GetArg()
+			return cexpr, tpb.analyzeReferences(cexpr, cexpr.expr)
+		case "sum", "avg":
+			cexpr.operation = opSum
+			if fname == "avg" {
+				cexpr.operation = opAvg
 			}
+			cexpr.expr = expr.GetArg()
+			return cexpr, tpb.analyzeReferences(cexpr, cexpr.expr)
+		case "min", "max":
 			innerCol, ok := expr.GetArg().(*sqlparser.ColName)
 			if !ok {
-				return nil, fmt.Errorf("unsupported non-column name in sum clause: %v", sqlparser.String(expr))
+				return nil, fmt.Errorf("unsupported non-column name in %s clause: %v", fname, sqlparser.String(expr))
 			}
 			if !innerCol.Qualifier.IsEmpty() {
 				return nil, fmt.Errorf("unsupported qualifier for column: %v", sqlparser.String(innerCol))
 			}
-			cexpr.operation = opSum
+			cexpr.operation = opMin
+			if fname == "max" {
+				cexpr.operation = opMax
+			}
 			cexpr.expr = innerCol
 			tpb.addCol(innerCol.Name)
 			cexpr.references[innerCol.Name.String()] = true
 			return cexpr, nil
 		}
 	}
-	err := sqlparser.Walk(func(node sqlparser.SQLNode) (kontinue bool, err error) {
+	if err := tpb.analyzeReferences(cexpr, aliased.Expr); err != nil {
+		return nil, err
+	}
+	cexpr.expr = aliased.Expr
+	return cexpr, nil
+}
+
+// analyzeReferences adds the columns referenced by the expression to the
+// send query and to the references of the colExpr.
+func (tpb *tablePlanBuilder) analyzeReferences(cexpr *colExpr, expr sqlparser.Expr) error {
+	return sqlparser.Walk(func(node sqlparser.SQLNode) (kontinue bool, err error) {
 		switch node := node.(type) {
 		case *sqlparser.ColName:
 			if !node.Qualifier.IsEmpty() {
@@ -526,16 +594,24 @@ func (tpb *tablePlanBuilder) analyzeExpr(selExpr sqlparser.SelectExpr) (*colExpr
 			return false, fmt.Errorf("unsupported aggregation function: %v", sqlparser.String(node))
 		}
 		return true, nil
-	}, aliased.Expr)
-	if err != nil {
-		return nil, err
-	}
-	cexpr.expr = aliased.Expr
-	return cexpr, nil
+	}, expr)
 }
 
 // addCol adds the specified column to the send query
 // if it's not already present.
+// sendColumns returns the names of the source columns that are streamed.
+func (tpb *tablePlanBuilder) sendColumns() []string {
+	var columns []string
+	for _, expr := range tpb.sendSelect.GetColumns() {
+		if aliased, ok := expr.(*sqlparser.AliasedExpr); ok {
+			if col, ok := aliased.Expr.(*sqlparser.ColName); ok {
+				columns = append(columns, col.Name.String())
+			}
+		}
+	}
+	return columns
+}
+
 func (tpb *tablePlanBuilder) addCol(ident sqlparser.IdentifierCI) {
 	tpb.sendSelect.AddSelectExpr(&sqlparser.AliasedExpr{
 		Expr: &sqlparser.ColName{Name: ident},
@@ -573,6 +649,48 @@ func (tpb *tablePlanBuilder) analyzeGroupBy(groupBy *sqlparser.GroupBy) error {
 	return nil
 }
 
+// analyzeAggregates validates the aggregate expressions that depend on the
+// rest of the select list.
+// An 'avg(a)' is derived from the 'sum(a)' and 'count(a)' columns, which have
+// to be selected before it since the generated statements update them first.
+// A 'min(a)' or 'max(a)' is re-derived from the source rows of its group when
+// the row that holds it is deleted or updated, which requires the group by
+// expressions to be source columns.
+func (tpb *tablePlanBuilder) analyzeAggregates() error {
+	for i, cexpr := range tpb.colExprs {
+		switch cexpr.operation {
+		case opAvg:
+			for _, prev := range tpb.colExprs[:i] {
+				if prev.expr == nil || !sqlparser.Equals.Expr(prev.expr, cexpr.expr) {
+					continue
+				}
+				switch prev.operation {
+				case opSum:
+					cexpr.avgSum = prev
+				case opCount:
+					cexpr.avgCount = prev
+				}
+			}
+			if cexpr.avgSum == nil || cexpr.avgCount == nil {
+				return fmt.Errorf("avg(%v) requires sum(%v) and count(%v) to be selected before it", sqlparser.String(cexpr.expr), sqlparser.String(cexpr.expr), sqlparser.String(cexpr.expr))
+			}
+		case opMin, opMax:
+			if tpb.onInsert == insertNormal {
+				return fmt.Errorf("unsupported min or max aggregation without a group by clause: %v", cexpr.colName)
+			}
+			for _, gexpr := range tpb.colExprs {
+				if !gexpr.isGrouped {
+					continue
+				}
+				if col, ok := gexpr.expr.(*sqlparser.ColName); !ok || !col.Qualifier.IsEmpty() {
+					return fmt.Errorf("min or max aggregations require the group by expressions to be source columns: %v", gexpr.colName)
+				}
+			}
+		}
+	}
+	return nil
+}
+
 func (tpb *tablePlanBuilder) getPKColsInfo(uniqueKeyColumns []string, colInfos []*ColumnInfo) (pkColsInfo []*ColumnInfo) {
 	if len(uniqueKeyColumns) == 0 {
 		// No PK override
@@ -730,10 +848,12 @@ func (tpb *tablePlanBuilder) generateValuesPart(buf *sqlparser.TrackedBuffer, bv
 				buf.Myprintf("%v", cexpr.expr)
 			}
 		case opCount:
-			buf.WriteString("1")
+			tpb.generateCountValue(buf, cexpr)
 		case opSum:
 			// NULL values must be treated as 0 for SUM.
 			buf.Myprintf("ifnull(%v, 0)", cexpr.expr)
+		case opMin, opMax, opAvg:
+			buf.Myprintf("%v", cexpr.expr)
 		}
 	}
 	buf.Myprintf(")")
@@ -754,9 +874,11 @@ func (tpb *tablePlanBuilder) generateSelectPart(buf *sqlparser.TrackedBuffer, bv
 		case opExpr:
 			buf.Myprintf("%v", cexpr.expr)
 		case opCount:
-			buf.WriteString("1")
+			tpb.generateCountValue(buf, cexpr)
 		case opSum:
 			buf.Myprintf("ifnull(%v, 0)", cexpr.expr)
+		case opMin, opMax, opAvg:
+			buf.Myprintf("%v", cexpr.expr)
 		}
 	}
 	buf.WriteString(" from dual where ")
@@ -790,15 +912,49 @@ func (tpb *tablePlanBuilder) generateOnDupPart(buf *sqlparser.TrackedBuffer) *sq
 		case opExpr:
 			buf.Myprintf("values(%v)", cexpr.colName)
 		case opCount:
-			buf.Myprintf("%v+1", cexpr.colName)
+			if cexpr.expr == nil {
+				buf.Myprintf("%v+1", cexpr.colName)
+			} else {
+				buf.Myprintf("%v+values(%v)", cexpr.colName, cexpr.colName)
+			}
 		case opSum:
 			buf.Myprintf("%v", cexpr.colName)
 			buf.Myprintf("+ifnull(values(%v), 0)", cexpr.colName)
+		case opMin, opMax:
+			// NULL values are ignored by MIN and MAX.
+			buf.Myprintf("%s(coalesce(%v, values(%v)), coalesce(values(%v), %v))", extremeFunc(cexpr), cexpr.colName, cexpr.colName, cexpr.colName, cexpr.colName)
+		case opAvg:
+			tpb.generateAvgValue(buf, cexpr)
 		}
 	}
 	return buf.ParsedQuery()
 }
 
+// generateCountValue generates the count of a single row.
+func (tpb *tablePlanBuilder) generateCountValue(buf *sqlparser.TrackedBuffer, cexpr *colExpr) {
+	if cexpr.expr == nil {
+		buf.WriteString("1")
+		return
+	}
+	// NULL values are not counted by COUNT(a).
+	buf.Myprintf("if(%v is null, 0, 1)", cexpr.expr)
+}
+
+// generateAvgValue generates an 'avg(a)' from its 'sum(a)' and 'count(a)'
+// columns, which have already been updated by the statement.
+func (tpb *tablePlanBuilder) generateAvgValue(buf *sqlparser.TrackedBuffer, cexpr *colExpr) {
+	buf.Myprintf("%v/nullif(%v, 0)", cexpr.avgSum.colName, cexpr.avgCount.colName)
+}
+
+// extremeFunc returns the function that merges values into a 'min(a)' or
+// 'max(a)' column.
+func extremeFunc(cexpr *colExpr) string {
+	if cexpr.operation == opMax {
+		return "greatest"
+	}
+	return "least"
+}
+
 func (tpb *tablePlanBuilder) generateUpdateStatement() *sqlparser.ParsedQuery {
 	if tpb.onInsert == insertIgnore {
 		return tpb.generateInsertStatement()
@@ -836,12 +992,25 @@ func (tpb *tablePlanBuilder) generateUpdateStatement() *sqlparser.ParsedQuery {
 			}
 		case opCount:
 			buf.Myprintf("%v", cexpr.colName)
+			if cexpr.expr != nil {
+				bvf.mode = bvBefore
+				buf.Myprintf("-if(%v is null, 0, 1)", cexpr.expr)
+				bvf.mode = bvAfter
+				buf.Myprintf("+if(%v is null, 0, 1)", cexpr.expr)
+			}
 		case opSum:
 			buf.Myprintf("%v", cexpr.colName)
 			bvf.mode = bvBefore
 			buf.Myprintf("-ifnull(%v, 0)", cexpr.expr)
 			bvf.mode = bvAfter
 			buf.Myprintf("+ifnull(%v, 0)", cexpr.expr)
+		case opMin, opMax:
+			// If the updated row held the extreme, it is re-derived from
+			// the source afterwards.
+			bvf.mode = bvAfter
+			buf.Myprintf("%s(coalesce(%v, %v), coalesce(%v, %v))", extremeFunc(cexpr), cexpr.colName, cexpr.expr, cexpr.expr, cexpr.colName)
+		case opAvg:
+			tpb.generateAvgValue(buf, cexpr)
 		}
 	}
 	tpb.generateWhere(buf, bvf)
@@ -869,9 +1038,19 @@ func (tpb *tablePlanBuilder) generateDeleteStatement() *sqlparser.ParsedQuery {
 			case opExpr:
 				buf.WriteString("null")
 			case opCount:
-				buf.Myprintf("%v-1", cexpr.colName)
+				if cexpr.expr == nil {
+					buf.Myprintf("%v-1", cexpr.colName)
+				} else {
+					buf.Myprintf("%v-if(%v is null, 0, 1)", cexpr.colName, cexpr.expr)
+				}
 			case opSum:
 				buf.Myprintf("%v-ifnull(%v, 0)", cexpr.colName, cexpr.expr)
+			case opMin, opMax:
+				// If the deleted row held the extreme, it is re-derived from
+				// the source afterwards.
+				buf.Myprintf("%v", cexpr.colName)
+			case opAvg:
+				tpb.generateAvgValue(buf, cexpr)
 			}
 		}
 		tpb.generateWhere(buf, bvf)
@@ -882,8 +1061,9 @@ func (tpb *tablePlanBuilder) generateDeleteStatement() *sqlparser.ParsedQuery {
 }
 
 func (tpb *tablePlanBuilder) generateMultiDeleteStatement() *sqlparser.ParsedQuery {
+	// Deletes of aggregated rows are updates of their group.
 	if tpb.workflowConfig.ExperimentalFlags&vttablet.VReplicationExperimentalFlagVPlayerBatching == 0 ||
-		(len(tpb.pkCols)+len(tpb.extraSourcePkCols)) != 1 {
+		(len(tpb.pkCols)+len(tpb.extraSourcePkCols)) != 1 || tpb.onInsert != insertNormal {
 		return nil
 	}
 	return sqlparser.BuildParsedQuery("delete from %s where %s in %a",
@@ -0,0 +1,230 @@
+/*
+Copyright 2025 The Vitess Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package vreplication
+
+import (
+	"context"
+	"fmt"
+
+	"vitess.io/vitess/go/mysql/collations"
+	"vitess.io/vitess/go/sqltypes"
+	"vitess.io/vitess/go/vt/sqlparser"
+	"vitess.io/vitess/go/vt/vterrors"
+	"vitess.io/vitess/go/vt/vtgate/evalengine"
+
+	binlogdatapb "vitess.io/vitess/go/vt/proto/binlogdata"
+	querypb "vitess.io/vitess/go/vt/proto/query"
+	vtrpcpb "vitess.io/vitess/go/vt/proto/vtrpc"
+)
+
+// extremesPlan re-derives the 'min(a)' and 'max(a)' columns of a group from
+// the source rows of the group, when the source row that held the extreme is
+// deleted, or updated to a value that is no longer the extreme. Inserts and
+// other updates merge their values into the extremes.
+// The source rows are read in their current state, which is safe: the events
+// that are not applied yet will either merge their values again, or trigger
+// another re-derivation.
+// The rows of a group have to live in the same source shard, e.g. by grouping
+// by the sharding key, as each stream only reads the rows of its own shard.
+type extremesPlan struct {
+	columns []*colExpr
+	// checkDelete and checkUpdate select, for every column, whether the deleted
+	// or updated source row held the extreme of its group.
+	checkDelete *sqlparser.ParsedQuery
+	checkUpdate *sqlparser.ParsedQuery
+	// updates set every column to its re-derived value, bound as :extreme.
+	updates []*sqlparser.ParsedQuery
+	// groupCols are the source columns of the group by expressions.
+	groupCols []*sqlparser.ColName
+	from      []sqlparser.TableExpr
+	where     *sqlparser.Where
+
+	sourceVStreamer VStreamerClient
+}
+
+// generateExtremes generates the plan that re-derives the 'min(a)' and
+// 'max(a)' columns. It returns nil if there are none.
+func (tpb *tablePlanBuilder) generateExtremes() *extremesPlan {
+	ep := &extremesPlan{}
+	for _, cexpr := range tpb.colExprs {
+		switch {
+		case cexpr.operation == opMin || cexpr.operation == opMax:
+			ep.columns = append(ep.columns, cexpr)
+		case cexpr.isGrouped:
+			ep.groupCols = append(ep.groupCols, cexpr.expr.(*sqlparser.ColName))
+		}
+	}
+	if len(ep.columns) == 0 {
+		return nil
+	}
+	// Plans built from the fields of a 'select *' have no aggregates, and no
+	// send select either.
+	ep.from, ep.where = tpb.sendSelect.From, tpb.sendSelect.Where
+	ep.checkDelete = tpb.generateExtremesCheck(ep.columns, false)
+	ep.checkUpdate = tpb.generateExtremesCheck(ep.columns, true)
+	for _, cexpr := range ep.columns {
+		bvf := &bindvarFormatter{}
+		buf := sqlparser.NewTrackedBuffer(bvf.formatter)
+		buf.Myprintf("update %v set %v=", tpb.name, cexpr.colName)
+		buf.WriteArg(":", "extreme")
+		tpb.generateWhere(buf, bvf)
+		ep.updates = append(ep.updates, buf.ParsedQuery())
+	}
+	return ep
+}
+
+// generateExtremesCheck generates the query that selects, for every column,
+// whether it still holds the value of the deleted or updated source row, which
+// then has to be re-derived. A column that holds the value an update left
+// unchanged does not have to.
+func (tpb *tablePlanBuilder) generateExtremesCheck(columns []*colExpr, update bool) *sqlparser.ParsedQuery {
+	bvf := &bindvarFormatter{}
+	buf := sqlparser.NewTrackedBuffer(bvf.formatter)
+	buf.WriteString("select ")
+	separator := ""
+	for _, cexpr := range columns {
+		bvf.mode = bvBefore
+		buf.Myprintf("%s%v is not null and %v<=>%v", separator, cexpr.expr, cexpr.colName, cexpr.expr)
+		if update {
+			buf.WriteString(" and not ")
+			bvf.mode = bvAfter
+			buf.Myprintf("%v", cexpr.expr)
+			bvf.mode = bvBefore
+			buf.Myprintf("<=>%v", cexpr.expr)
+		}
+		separator = ", "
+	}
+	buf.Myprintf(" from %v", tpb.name)
+	tpb.generateWhere(buf, bvf)
+	return buf.ParsedQuery()
+}
+
+// rederiveExtremes re-derives the extremes that were held by the deleted or
+// updated source row, once the change has been applied to its group.
+func (tp *TablePlan) rederiveExtremes(ctx context.Context, bindvars map[string]*querypb.BindVariable, update bool, executor func(string) (*sqltypes.Result, error)) error {
+	ep := tp.Extremes
+	check := ep.checkDelete
+	if update {
+		check = ep.checkUpdate
+	}
+	qr, err := execParsedQuery(check, bindvars, executor)
+	if err != nil {
+		return err
+	}
+	if len(qr.Rows) == 0 {
+		return nil
+	}
+	var stale []int
+	for i, val := range qr.Rows[0] {
+		if held, err := val.ToInt64(); err == nil && held == 1 {
+			stale = append(stale, i)
+		}
+	}
+	if len(stale) == 0 {
+		return nil
+	}
+	extremes, err := ep.readExtremes(ctx, bindvars, stale, tp.CollationEnv)
+	if err != nil {
+		return vterrors.Wrapf(err, "failed to re-derive the extremes of %s from the source", tp.TargetName)
+	}
+	for i, colIndex := range stale {
+		bindvars["extreme"] = sqltypes.ValueBindVariable(extremes[i])
+		if _, err := execParsedQuery(ep.updates[colIndex], bindvars, executor); err != nil {
+			return err
+		}
+	}
+	delete(bindvars, "extreme")
+	return nil
+}
+
+// readExtremes reads the source rows of the group of the deleted or updated
+// row, and returns the extremes of the given columns.
+func (ep *extremesPlan) readExtremes(ctx context.Context, bindvars map[string]*querypb.BindVariable, columns []int, collationEnv *collations.Environment) ([]sqltypes.Value, error) {
+	if ep.sourceVStreamer == nil {
+		return nil, vterrors.Errorf(vtrpcpb.Code_INTERNAL, "no source to read the rows of the group from")
+	}
+	sel := &sqlparser.Select{From: ep.from}
+	for _, colIndex := range columns {
+		sel.AddSelectExpr(&sqlparser.AliasedExpr{Expr: ep.columns[colIndex].expr})
+	}
+	if ep.where != nil {
+		sel.AddWhere(ep.where.Expr)
+	}
+	for _, col := range ep.groupCols {
+		bv := bindvars["b_"+col.Name.String()]
+		if bv == nil || bv.Type == querypb.Type_NULL_TYPE {
+			sel.AddWhere(&sqlparser.IsExpr{Left: col, Right: sqlparser.IsNullOp})
+			continue
+		}
+		sel.AddWhere(&sqlparser.ComparisonExpr{Operator: sqlparser.EqualOp, Left: col, Right: sqlparser.NewArgument("b_" + col.Name.String())})
+	}
+	query, err := sqlparser.NewParsedQuery(sel).GenerateQuery(bindvars, nil)
+	if err != nil {
+		return nil, err
+	}
+
+	extremes := make([]sqltypes.Value, len(columns))
+	var (
+		fields       []*querypb.Field
+		fieldIndexes []int
+	)
+	err = ep.sourceVStreamer.VStreamRows(ctx, query, nil, func(rows *binlogdatapb.VStreamRowsResponse) error {
+		// Only the first response carries the fields.
+		if fields == nil && len(rows.Fields) > 0 {
+			fields = rows.Fields
+			for _, colIndex := range columns {
+				name := ep.columns[colIndex].expr.(*sqlparser.ColName).Name
+				index := -1
+				for i, field := range fields {
+					if name.EqualString(field.Name) {
+						index = i
+						break
+					}
+				}
+				if index < 0 {
+					return fmt.Errorf("column %v not found in the rows streamed from the source", name)
+				}
+				fieldIndexes = append(fieldIndexes, index)
+			}
+		}
+		for _, row := range rows.Rows {
+			vals := sqltypes.MakeRowTrusted(fields, row)
+			for i, colIndex := range columns {
+				val := vals[fieldIndexes[i]]
+				if val.IsNull() {
+					continue
+				}
+				if extremes[i].IsNull() {
+					extremes[i] = val
+					continue
+				}
+				cmp, err := evalengine.NullsafeCompare(val, extremes[i], collationEnv, collations.ID(fields[fieldIndexes[i]].Charset), nil)
+				if err != nil {
+					return err
+				}
+				if (ep.columns[colIndex].operation == opMax && cmp > 0) || (ep.columns[colIndex].operation == opMin && cmp < 0) {
+					extremes[i] = val
+				}
+			}
+		}
+		return nil
+	}, nil)
+	if err != nil {
+		return nil, err
+	}
+	return extremes, nil
+}
@@ -0,0 +1,175 @@
+/*
+Copyright 2025 The Vitess Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package vreplication
+
+import (
+	"context"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"vitess.io/vitess/go/sqltypes"
+	"vitess.io/vitess/go/vt/binlog/binlogplayer"
+	"vitess.io/vitess/go/vt/vtenv"
+
+	binlogdatapb "vitess.io/vitess/go/vt/proto/binlogdata"
+	querypb "vitess.io/vitess/go/vt/proto/query"
+	vttablet "vitess.io/vitess/go/vt/vttablet/common"
+)
+
+// fakeRowsStreamer serves the rows of the VStreamRows queries it knows, no
+// rows for the others, and records the queries.
+type fakeRowsStreamer struct {
+	results map[string]*sqltypes.Result
+	queries []string
+}
+
+func (f *fakeRowsStreamer) Open(context.Context) error  { return nil }
+func (f *fakeRowsStreamer) Close(context.Context) error { return nil }
+
+func (f *fakeRowsStreamer) VStream(ctx context.Context, startPos string, tablePKs []*binlogdatapb.TableLastPK, filter *binlogdatapb.Filter, send func([]*binlogdatapb.VEvent) error, options *binlogdatapb.VStreamOptions) error {
+	return nil
+}
+
+func (f *fakeRowsStreamer) VStreamRows(ctx context.Context, query string, lastpk *querypb.QueryResult, send func(*binlogdatapb.VStreamRowsResponse) error, options *binlogdatapb.VStreamOptions) error {
+	f.queries = append(f.queries, query)
+	result, ok := f.results[query]
+	if !ok {
+		return send(&binlogdatapb.VStreamRowsResponse{})
+	}
+	// Like the row streamer, only send the fields in the first response.
+	if err := send(&binlogdatapb.VStreamRowsResponse{Fields: result.Fields}); err != nil {
+		return err
+	}
+	return send(&binlogdatapb.VStreamRowsResponse{Rows: sqltypes.RowsToProto3(result.Rows)})
+}
+
+func (f *fakeRowsStreamer) VStreamTables(ctx context.Context, send func(*binlogdatapb.VStreamTablesResponse) error, options *binlogdatapb.VStreamOptions) error {
+	return nil
+}
+
+func TestApplyChangeExtremes(t *testing.T) {
+	source := &fakeRowsStreamer{
+		results: map[string]*sqltypes.Result{
+			"select c2 from t2 where c3 = 'a' and c1 = 1": sqltypes.MakeTestResult(sqltypes.MakeTestFields("c2", "int64"), "7", "null", "6"),
+		},
+	}
+	vttablet.InitVReplicationConfigDefaults()
+	vr := &vreplicator{
+		workflowConfig:  vttablet.DefaultVReplicationConfig,
+		sourceVStreamer: source,
+	}
+	filter := &binlogdatapb.Filter{
+		Rules: []*binlogdatapb.Rule{{
+			Match:  "t1",
+			Filter: "select c1, min(c2) as mn, max(c2) as mx from t2 where c3 = 'a' group by c1",
+		}},
+	}
+	colInfos := map[string][]*ColumnInfo{
+		"t1": {&ColumnInfo{Name: "c1", IsPK: true}},
+	}
+	plan, err := vr.buildReplicatorPlan(getSource(filter), colInfos, nil, binlogplayer.NewStats(), vtenv.NewTestEnv())
+	require.NoError(t, err)
+	tp, err := plan.buildExecutionPlan(&binlogdatapb.FieldEvent{
+		TableName: "t2",
+		Fields:    sqltypes.MakeTestFields("c1|c2|c2", "int64|int64|int64"),
+	})
+	require.NoError(t, err)
+
+	testcases := []struct {
+		name          string
+		before, after []sqltypes.Value
+		held          string
+		wantQueries   []string
+		wantStreamed  []string
+	}{{
+		name:   "delete of the minimum",
+		before: []sqltypes.Value{sqltypes.NewInt64(1), sqltypes.NewInt64(5), sqltypes.NewInt64(5)},
+		held:   "1|0",
+		wantQueries: []string{
+			"update t1 set mn=mn, mx=mx where c1=1",
+			"select 5 is not null and mn<=>5, 5 is not null and mx<=>5 from t1 where c1=1",
+			"update t1 set mn=6 where c1=1",
+		},
+		wantStreamed: []string{"select c2 from t2 where c3 = 'a' and c1 = 1"},
+	}, {
+		name:   "update of the maximum",
+		before: []sqltypes.Value{sqltypes.NewInt64(1), sqltypes.NewInt64(9), sqltypes.NewInt64(9)},
+		after:  []sqltypes.Value{sqltypes.NewInt64(1), sqltypes.NewInt64(2), sqltypes.NewInt64(2)},
+		held:   "0|1",
+		wantQueries: []string{
+			"update t1 set mn=least(coalesce(mn, 2), coalesce(2, mn)), mx=greatest(coalesce(mx, 2), coalesce(2, mx)) where c1=1",
+			"select 9 is not null and mn<=>9 and not 2<=>9, 9 is not null and mx<=>9 and not 2<=>9 from t1 where c1=1",
+			"update t1 set mx=7 where c1=1",
+		},
+		wantStreamed: []string{"select c2 from t2 where c3 = 'a' and c1 = 1"},
+	}, {
+		name:   "delete of a row that held no extreme",
+		before: []sqltypes.Value{sqltypes.NewInt64(1), sqltypes.NewInt64(8), sqltypes.NewInt64(8)},
+		held:   "0|0",
+		wantQueries: []string{
+			"update t1 set mn=mn, mx=mx where c1=1",
+			"select 8 is not null and mn<=>8, 8 is not null and mx<=>8 from t1 where c1=1",
+		},
+	}}
+	for _, tcase := range testcases {
+		t.Run(tcase.name, func(t *testing.T) {
+			source.queries = nil
+			var queries []string
+			executor := func(query string) (*sqltypes.Result, error) {
+				queries = append(queries, query)
+				if strings.HasPrefix(query, "select ") {
+					return sqltypes.MakeTestResult(sqltypes.MakeTestFields("mn|mx", "int64|int64"), tcase.held), nil
+				}
+				return &sqltypes.Result{RowsAffected: 1}, nil
+			}
+			rowChange := &binlogdatapb.RowChange{}
+			if tcase.before != nil {
+				rowChange.Before = sqltypes.RowToProto3(tcase.before)
+			}
+			if tcase.after != nil {
+				rowChange.After = sqltypes.RowToProto3(tcase.after)
+			}
+			_, err := tp.applyChange(context.Background(), rowChange, executor)
+			require.NoError(t, err)
+			assert.Equal(t, tcase.wantQueries, queries)
+			assert.Equal(t, tcase.wantStreamed, source.queries)
+		})
+	}
+}
+
+func TestBuildExecutionPlanWithoutExtremes(t *testing.T) {
+	vttablet.InitVReplicationConfigDefaults()
+	vr := &vreplicator{workflowConfig: vttablet.DefaultVReplicationConfig}
+	filter := &binlogdatapb.Filter{
+		Rules: []*binlogdatapb.Rule{{Match: "t1"}},
+	}
+	colInfos := map[string][]*ColumnInfo{
+		"t1": {&ColumnInfo{Name: "c1", IsPK: true}, &ColumnInfo{Name: "c2"}},
+	}
+	plan, err := vr.buildReplicatorPlan(getSource(filter), colInfos, nil, binlogplayer.NewStats(), vtenv.NewTestEnv())
+	require.NoError(t, err)
+	// The plan of a 'select *' is built from the fields of the stream.
+	tp, err := plan.buildExecutionPlan(&binlogdatapb.FieldEvent{
+		TableName: "t1",
+		Fields:    sqltypes.MakeTestFields("c1|c2", "int64|int64"),
+	})
+	require.NoError(t, err)
+	assert.Nil(t, tp.Extremes)
+}
@@ -0,0 +1,763 @@
+/*
+Copyright 2025 The Vitess Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package vreplication
+
+import (
+	"context"
+	"fmt"
+	"slices"
+	"strings"
+
+	"vitess.io/vitess/go/mysql/collations"
+	"vitess.io/vitess/go/sqltypes"
+	"vitess.io/vitess/go/vt/binlog/binlogplayer"
+	"vitess.io/vitess/go/vt/sqlparser"
+	"vitess.io/vitess/go/vt/vterrors"
+	"vitess.io/vitess/go/vt/vtgate/evalengine"
+
+	binlogdatapb "vitess.io/vitess/go/vt/proto/binlogdata"
+	querypb "vitess.io/vitess/go/vt/proto/query"
+	vtrpcpb "vitess.io/vitess/go/vt/proto/vtrpc"
+	vttablet "vitess.io/vitess/go/vt/vttablet/common"
+)
+
+// joinPlan maintains a target table from an inner join of source tables, like
+// "select o.id, o.amount, c.name from orders o join customers c on o.customer_id = c.id".
+//
+// Every source table is streamed on its own, and a row change of any of them
+// is applied by re-evaluating the target rows that join the changed row: they
+// are located by the target columns that hold a column of the changed table,
+// or a column it is joined on. These rows are deleted, and re-derived by
+// reading the rows that join the changed row from the source. The copy phase
+// applies every row of the first table in the same way.
+// The source rows are read in their current state, which is safe: the events
+// that are not applied yet will re-evaluate the rows they affect again.
+// Every joined row has to live in the same source shard as the row it joins,
+// e.g. by joining on the sharding key, or joining reference tables, as each
+// stream only reads the rows of its own shard.
+type joinPlan struct {
+	targetName sqlparser.IdentifierCS
+	tables     []*joinTable
+	conditions []*joinCondition
+	// insert inserts a joined row, whose columns are bound as :j<table index>_<column>.
+	insert *sqlparser.ParsedQuery
+
+	sourceVStreamer VStreamerClient
+	// collationEnv compares the values read from the source, to match the rows
+	// that join on them.
+	collationEnv *collations.Environment
+}
+
+// joinTable is a source table of a joinPlan.
+type joinTable struct {
+	index int
+	name  string
+	alias string
+	// columns are the columns of the table that the target, the join
+	// conditions or the keys reference.
+	columns []sqlparser.IdentifierCI
+	// filters are the conditions of the query that only reference this table.
+	filters []sqlparser.Expr
+	// keys locate the target rows that join a row of this table.
+	keys []*joinKey
+	// delete deletes the target rows whose keys are bound as :k<key index>.
+	delete *sqlparser.ParsedQuery
+}
+
+// joinKey is a column of a source table, held by a column of the target.
+type joinKey struct {
+	column sqlparser.IdentifierCI
+	target sqlparser.IdentifierCI
+}
+
+// joinColumn is a column of a joinTable.
+type joinColumn struct {
+	table *joinTable
+	index int
+}
+
+// joinCondition is an equality between the columns of two tables.
+type joinCondition struct {
+	left, right joinColumn
+}
+
+// joinPlanBuilder builds a joinPlan.
+type joinPlanBuilder struct {
+	jp *joinPlan
+	// classes maps every column of a join condition to the columns it is
+	// transitively joined on, itself included.
+	classes map[joinColumn][]joinColumn
+}
+
+// buildJoinTablePlan builds the TablePlan of a rule whose filter joins source
+// tables. The plan streams the first table of the join, and its
+// JoinedTablePlans stream the other ones.
+func buildJoinTablePlan(tableName string, sel *sqlparser.Select, stats *binlogplayer.Stats,
+	collationEnv *collations.Environment, workflowConfig *vttablet.VReplicationConfig) (*TablePlan, error) {
+	jpb := &joinPlanBuilder{
+		jp:      &joinPlan{targetName: sqlparser.NewIdentifierCS(tableName), collationEnv: collationEnv},
+		classes: make(map[joinColumn][]joinColumn),
+	}
+	if sel.Distinct || sel.GroupBy != nil || sel.Having != nil || sel.OrderBy != nil || sel.Limit != nil {
+		return nil, fmt.Errorf("unsupported distinct, group by, having, order by or limit clause in a join")
+	}
+	var conditions []sqlparser.Expr
+	if err := jpb.analyzeFrom(sel.From[0], &conditions); err != nil {
+		return nil, err
+	}
+	if sel.Where != nil {
+		conditions = sqlparser.SplitAndExpression(conditions, sel.Where.Expr)
+	}
+	for _, cond := range conditions {
+		if err := jpb.analyzeCondition(cond); err != nil {
+			return nil, err
+		}
+	}
+	if err := jpb.analyzeConnected(); err != nil {
+		return nil, err
+	}
+	targetCols, targetExprs, err := jpb.analyzeExprs(sel.SelectExprs.Exprs)
+	if err != nil {
+		return nil, err
+	}
+	if err := jpb.analyzeKeys(targetCols, sel.SelectExprs.Exprs); err != nil {
+		return nil, err
+	}
+
+	jp := jpb.jp
+	buf := sqlparser.NewTrackedBuffer(nil)
+	buf.Myprintf("insert into %v(", jp.targetName)
+	for i, col := range targetCols {
+		if i > 0 {
+			buf.WriteString(",")
+		}
+		buf.Myprintf("%v", col)
+	}
+	buf.WriteString(") values (")
+	for i, expr := range targetExprs {
+		if i > 0 {
+			buf.WriteString(",")
+		}
+		buf.Myprintf("%v", expr)
+	}
+	buf.WriteString(")")
+	jp.insert = buf.ParsedQuery()
+
+	tablePlans := make([]*TablePlan, 0, len(jp.tables))
+	for _, table := range jp.tables {
+		buf := sqlparser.NewTrackedBuffer(nil)
+		buf.Myprintf("delete from %v where ", jp.targetName)
+		for i, key := range table.keys {
+			if i > 0 {
+				buf.WriteString(" and ")
+			}
+			buf.Myprintf("%v<=>%a", key.target, fmt.Sprintf(":k%d", i))
+		}
+		table.delete = buf.ParsedQuery()
+
+		sendSelect := &sqlparser.Select{
+			From: sqlparser.TableExprs{&sqlparser.AliasedTableExpr{Expr: sqlparser.NewTableName(table.name)}},
+		}
+		for _, key := range table.keys {
+			sendSelect.AddSelectExpr(&sqlparser.AliasedExpr{Expr: &sqlparser.ColName{Name: key.column}})
+		}
+		tablePlans = append(tablePlans, &TablePlan{
+			TargetName: tableName,
+			SendRule: &binlogdatapb.Rule{
+				Match:  table.name,
+				Filter: sqlparser.String(sendSelect),
+			},
+			Join:           jp,
+			Stats:          stats,
+			CollationEnv:   collationEnv,
+			WorkflowConfig: workflowConfig,
+		})
+	}
+	tablePlan := tablePlans[0]
+	tablePlan.JoinedTablePlans = tablePlans[1:]
+	return tablePlan, nil
+}
+
+// analyzeFrom collects the tables of the join, and the conditions they are
+// joined on.
+func (jpb *joinPlanBuilder) analyzeFrom(node sqlparser.TableExpr, conditions *[]sqlparser.Expr) error {
+	switch node := node.(type) {
+	case *sqlparser.AliasedTableExpr:
+		tableName := sqlparser.GetTableName(node.Expr)
+		if tableName.IsEmpty() {
+			return fmt.Errorf("unsupported from source (%T)", node.Expr)
+		}
+		table := &joinTable{
+			index: len(jpb.jp.tables),
+			name:  tableName.String(),
+			alias: tableName.String(),
+		}
+		if !node.As.IsEmpty() {
+			table.alias = node.As.String()
+		}
+		for _, other := range jpb.jp.tables {
+			if other.name == table.name {
+				return fmt.Errorf("unsupported join of table %s with itself", table.name)
+			}
+			if other.alias == table.alias {
+				return fmt.Errorf("duplicate table alias: %s", table.alias)
+			}
+		}
+		jpb.jp.tables = append(jpb.jp.tables, table)
+	case *sqlparser.JoinTableExpr:
+		if node.Join != sqlparser.NormalJoinType {
+			return fmt.Errorf("unsupported join type: %s", node.Join.ToString())
+		}
+		if node.Condition != nil && len(node.Condition.Using) > 0 {
+			return fmt.Errorf("unsupported using clause in join: %v", sqlparser.String(node))
+		}
+		if err := jpb.analyzeFrom(node.LeftExpr, conditions); err != nil {
+			return err
+		}
+		if err := jpb.analyzeFrom(node.RightExpr, conditions); err != nil {
+			return err
+		}
+		if node.Condition != nil {
+			*conditions = sqlparser.SplitAndExpression(*conditions, node.Condition.On)
+		}
+	case *sqlparser.ParenTableExpr:
+		for _, expr := range node.Exprs {
+			if err := jpb.analyzeFrom(expr, conditions); err != nil {
+				return err
+			}
+		}
+	default:
+		return fmt.Errorf("unsupported from expression (%T)", node)
+	}
+	return nil
+}
+
+// analyzeCondition records an equality between the columns of two tables as a
+// join condition, and any other condition on a single table as a filter of
+// that table.
+func (jpb *joinPlanBuilder) analyzeCondition(cond sqlparser.Expr) error {
+	if cmp, ok := cond.(*sqlparser.ComparisonExpr); ok && cmp.Operator == sqlparser.EqualOp {
+		left, lok := cmp.Left.(*sqlparser.ColName)
+		right, rok := cmp.Right.(*sqlparser.ColName)
+		if lok && rok && left.Qualifier.Name.String() != right.Qualifier.Name.String() {
+			leftCol, err := jpb.column(left)
+			if err != nil {
+				return err
+			}
+			rightCol, err := jpb.column(right)
+			if err != nil {
+				return err
+			}
+			jpb.jp.conditions = append(jpb.jp.conditions, &joinCondition{left: leftCol, right: rightCol})
+			jpb.join(leftCol, rightCol)
+			return nil
+		}
+	}
+	tables, err := jpb.tablesOf(cond)
+	if err != nil {
+		return err
+	}
+	if len(tables) != 1 {
+		return fmt.Errorf("unsupported join condition: %v", sqlparser.String(cond))
+	}
+	tables[0].filters = append(tables[0].filters, unqualify(cond))
+	return nil
+}
+
+// analyzeConnected verifies that every table is joined to the first one.
+func (jpb *joinPlanBuilder) analyzeConnected() error {
+	connected := make([]bool, len(jpb.jp.tables))
+	connected[0] = true
+	for table, _ := jpb.jp.nextTable(connected); table != nil; table, _ = jpb.jp.nextTable(connected) {
+		connected[table.index] = true
+	}
+	for _, table := range jpb.jp.tables {
+		if !connected[table.index] {
+			return fmt.Errorf("table %s is not joined on an equality with the other tables", table.alias)
+		}
+	}
+	return nil
+}
+
+// analyzeExprs returns the target columns of the select expressions, and the
+// expressions that compute them from the bound columns of the joined rows.
+func (jpb *joinPlanBuilder) analyzeExprs(selExprs []sqlparser.SelectExpr) ([]sqlparser.IdentifierCI, []sqlparser.Expr, error) {
+	targetCols := make([]sqlparser.IdentifierCI, 0, len(selExprs))
+	targetExprs := make([]sqlparser.Expr, 0, len(selExprs))
+	for _, selExpr := range selExprs {
+		aliased, ok := selExpr.(*sqlparser.AliasedExpr)
+		if !ok {
+			return nil, nil, fmt.Errorf("invalid expression: %v", sqlparser.String(selExpr))
+		}
+		as := aliased.As
+		if as.IsEmpty() {
+			colName, ok := aliased.Expr.(*sqlparser.ColName)
+			if !ok {
+				return nil, nil, fmt.Errorf("expression needs an alias: %v", sqlparser.String(aliased))
+			}
+			as = colName.Name
+		}
+		if sqlparser.ContainsAggregation(aliased.Expr) {
+			return nil, nil, fmt.Errorf("unsupported aggregation in a join: %v", sqlparser.String(aliased))
+		}
+		if _, err := jpb.tablesOf(aliased.Expr); err != nil {
+			return nil, nil, err
+		}
+		expr := sqlparser.CopyOnRewrite(aliased.Expr, nil, func(cursor *sqlparser.CopyOnWriteCursor) {
+			if col, ok := cursor.Node().(*sqlparser.ColName); ok {
+				jcol, _ := jpb.column(col)
+				cursor.Replace(sqlparser.NewArgument(jcol.bindVarName()))
+			}
+		}, nil).(sqlparser.Expr)
+		targetCols = append(targetCols, as)
+		targetExprs = append(targetExprs, expr)
+	}
+	return targetCols, targetExprs, nil
+}
+
+// analyzeKeys finds, for every table, the target columns that hold one of its
+// columns, or a column it is joined on.
+func (jpb *joinPlanBuilder) analyzeKeys(targetCols []sqlparser.IdentifierCI, selExprs []sqlparser.SelectExpr) error {
+	for i, selExpr := range selExprs {
+		colName, ok := selExpr.(*sqlparser.AliasedExpr).Expr.(*sqlparser.ColName)
+		if !ok {
+			continue
+		}
+		jcol, err := jpb.column(colName)
+		if err != nil {
+			return err
+		}
+		joined, ok := jpb.classes[jcol]
+		if !ok {
+			joined = []joinColumn{jcol}
+		}
+		for _, jcol := range joined {
+			jcol.table.keys = append(jcol.table.keys, &joinKey{
+				column: jcol.table.columns[jcol.index],
+				target: targetCols[i],
+			})
+		}
+	}
+	for _, table := range jpb.jp.tables {
+		if len(table.keys) == 0 {
+			return fmt.Errorf("%v has to include a column of table %s, or a column it is joined on, to locate the rows that join it", jpb.jp.targetName, table.alias)
+		}
+	}
+	return nil
+}
+
+// column returns the joinColumn a column name refers to, and adds it to the
+// columns of its table.
+func (jpb *joinPlanBuilder) column(colName *sqlparser.ColName) (joinColumn, error) {
+	table, err := jpb.tableOf(colName)
+	if err != nil {
+		return joinColumn{}, err
+	}
+	for i, col := range table.columns {
+		if col.Equal(colName.Name) {
+			return joinColumn{table: table, index: i}, nil
+		}
+	}
+	table.columns = append(table.columns, colName.Name)
+	return joinColumn{table: table, index: len(table.columns) - 1}, nil
+}
+
+// tableOf returns the table a column name refers to.
+func (jpb *joinPlanBuilder) tableOf(colName *sqlparser.ColName) (*joinTable, error) {
+	if colName.Qualifier.IsEmpty() {
+		return nil, fmt.Errorf("column %v has to be qualified by its table in a join", colName.Name)
+	}
+	for _, table := range jpb.jp.tables {
+		if colName.Qualifier.Name.String() == table.alias {
+			return table, nil
+		}
+	}
+	return nil, fmt.Errorf("unknown table %v in column %v", colName.Qualifier.Name, sqlparser.String(colName))
+}
+
+// tablesOf returns the tables an expression references.
+func (jpb *joinPlanBuilder) tablesOf(expr sqlparser.Expr) ([]*joinTable, error) {
+	var tables []*joinTable
+	err := sqlparser.Walk(func(node sqlparser.SQLNode) (bool, error) {
+		switch node := node.(type) {
+		case *sqlparser.ColName:
+			table, err := jpb.tableOf(node)
+			if err != nil {
+				return false, err
+			}
+			if !slices.Contains(tables, table) {
+				tables = append(tables, table)
+			}
+		case *sqlparser.Subquery:
+			return false, fmt.Errorf("unsupported subquery: %v", sqlparser.String(node))
+		}
+		return true, nil
+	}, expr)
+	return tables, err
+}
+
+// join records that two columns are joined on.
+func (jpb *joinPlanBuilder) join(left, right joinColumn) {
+	class := func(jcol joinColumn) []joinColumn {
+		if joined, ok := jpb.classes[jcol]; ok {
+			return joined
+		}
+		return []joinColumn{jcol}
+	}
+	leftClass, rightClass := class(left), class(right)
+	for _, jcol := range rightClass {
+		if jcol == left {
+			return
+		}
+	}
+	merged := append(append([]joinColumn{}, leftClass...), rightClass...)
+	for _, jcol := range merged {
+		jpb.classes[jcol] = merged
+	}
+}
+
+// bindVarName returns the name of the bind variable of the column in a joined
+// row.
+func (jcol joinColumn) bindVarName() string {
+	return fmt.Sprintf("j%d_%s", jcol.table.index, jcol.table.columns[jcol.index].Lowered())
+}
+
+// unqualify returns a copy of the expression, without the table qualifier of
+// its columns, to be sent to the source in the query of a single table.
+func unqualify(expr sqlparser.Expr) sqlparser.Expr {
+	return sqlparser.CopyOnRewrite(expr, nil, func(cursor *sqlparser.CopyOnWriteCursor) {
+		if col, ok := cursor.Node().(*sqlparser.ColName); ok {
+			cursor.Replace(&sqlparser.ColName{Name: col.Name})
+		}
+	}, nil).(sqlparser.Expr)
+}
+
+// nextTable returns a table that is not joined yet, but is joined on an
+// equality with the tables that are, along with the conditions it is joined
+// on, its own column on the left. It returns nil if there is none.
+func (jp *joinPlan) nextTable(joined []bool) (*joinTable, []*joinCondition) {
+	for _, table := range jp.tables {
+		if joined[table.index] {
+			continue
+		}
+		var conditions []*joinCondition
+		for _, cond := range jp.conditions {
+			switch {
+			case cond.left.table == table && joined[cond.right.table.index]:
+				conditions = append(conditions, cond)
+			case cond.right.table == table && joined[cond.left.table.index]:
+				conditions = append(conditions, &joinCondition{left: cond.right, right: cond.left})
+			}
+		}
+		if len(conditions) > 0 {
+			return table, conditions
+		}
+	}
+	return nil, nil
+}
+
+// table returns the source table of the given name.
+func (jp *joinPlan) table(name string) *joinTable {
+	for _, table := range jp.tables {
+		if table.name == name {
+			return table
+		}
+	}
+	return nil
+}
+
+// applyChange re-evaluates the target rows that join the row before and after
+// the change.
+func (jp *joinPlan) applyChange(ctx context.Context, tp *TablePlan, rowChange *binlogdatapb.RowChange, executor func(string) (*sqltypes.Result, error)) error {
+	table := jp.table(tp.SendRule.Match)
+	if table == nil {
+		return vterrors.Errorf(vtrpcpb.Code_INTERNAL, "table %s is not part of the join of %v", tp.SendRule.Match, jp.targetName)
+	}
+	var before []sqltypes.Value
+	if rowChange.Before != nil {
+		before = sqltypes.MakeRowTrusted(tp.Fields, rowChange.Before)
+		if err := jp.refresh(ctx, table, before, executor); err != nil {
+			return err
+		}
+	}
+	if rowChange.After != nil {
+		after := sqltypes.MakeRowTrusted(tp.Fields, rowChange.After)
+		if before != nil && slices.EqualFunc(before, after, valsEqual) {
+			// The same target rows were re-evaluated already.
+			return nil
+		}
+		return jp.refresh(ctx, table, after, executor)
+	}
+	return nil
+}
+
+// applyRows re-evaluates the target rows that join the copied rows. The rows
+// are refreshed together, so that every table is read once per copied batch.
+func (jp *joinPlan) applyRows(ctx context.Context, tp *TablePlan, rows []*querypb.Row, executor func(string) (*sqltypes.Result, error)) (*sqltypes.Result, error) {
+	table := jp.table(tp.SendRule.Match)
+	if table == nil {
+		return nil, vterrors.Errorf(vtrpcpb.Code_INTERNAL, "table %s is not part of the join of %v", tp.SendRule.Match, jp.targetName)
+	}
+	if len(rows) == 0 {
+		return &sqltypes.Result{}, nil
+	}
+	keyRows := make([][]sqltypes.Value, 0, len(rows))
+	for _, row := range rows {
+		keyRows = append(keyRows, sqltypes.MakeRowTrusted(tp.Fields, row))
+	}
+	if err := jp.refreshRows(ctx, table, keyRows, executor); err != nil {
+		return nil, err
+	}
+	return &sqltypes.Result{}, nil
+}
+
+// refresh deletes the target rows whose keys hold the given values of the keys
+// of the table, and re-derives them from the source.
+func (jp *joinPlan) refresh(ctx context.Context, table *joinTable, keys []sqltypes.Value, executor func(string) (*sqltypes.Result, error)) error {
+	return jp.refreshRows(ctx, table, [][]sqltypes.Value{keys}, executor)
+}
+
+// joinLookup is a distinct set of values that the rows of a table are joined on,
+// along with the rows of the table that match them.
+type joinLookup struct {
+	values []sqltypes.Value
+	rows   [][]sqltypes.Value
+}
+
+// refreshRows refreshes the target rows of many key values of the table at once.
+// Every table is read from the source with a single query, for all the rows it joins.
+func (jp *joinPlan) refreshRows(ctx context.Context, table *joinTable, keyRows [][]sqltypes.Value, executor func(string) (*sqltypes.Result, error)) error {
+	bindvars := make(map[string]*querypb.BindVariable, len(keyRows)*len(table.keys))
+	matches := make([]sqlparser.Expr, 0, len(keyRows))
+	for r, keys := range keyRows {
+		if len(keys) != len(table.keys) {
+			return vterrors.Errorf(vtrpcpb.Code_INTERNAL, "got %d key values for the %d keys of table %s", len(keys), len(table.keys), table.name)
+		}
+		deleteVars := make(map[string]*querypb.BindVariable, len(keys))
+		conditions := make([]sqlparser.Expr, 0, len(keys))
+		for i, key := range table.keys {
+			deleteVars[fmt.Sprintf("k%d", i)] = sqltypes.ValueBindVariable(keys[i])
+			name := fmt.Sprintf("k%d_%d", r, i)
+			bindvars[name] = sqltypes.ValueBindVariable(keys[i])
+			conditions = append(conditions, columnMatch(key.column, name, keys[i]))
+		}
+		if _, err := execParsedQuery(table.delete, deleteVars, executor); err != nil {
+			return err
+		}
+		matches = append(matches, sqlparser.AndExpressions(conditions...))
+	}
+
+	rows, _, err := jp.readRows(ctx, table, sqlparser.SplitAndExpression(nil, orExpressions(matches)), bindvars)
+	if err != nil {
+		return err
+	}
+	// joinedRows holds the rows of every table of the joined rows, by table index.
+	joinedRows := make([][][]sqltypes.Value, 0, len(rows))
+	for _, row := range rows {
+		joinedRow := make([][]sqltypes.Value, len(jp.tables))
+		joinedRow[table.index] = row
+		joinedRows = append(joinedRows, joinedRow)
+	}
+	joined := make([]bool, len(jp.tables))
+	joined[table.index] = true
+	for next, nextConditions := jp.nextTable(joined); next != nil && len(joinedRows) > 0; next, nextConditions = jp.nextTable(joined) {
+		joined[next.index] = true
+		if joinedRows, err = jp.joinRows(ctx, next, nextConditions, joinedRows); err != nil {
+			return err
+		}
+	}
+
+	for _, joinedRow := range joinedRows {
+		bindvars := make(map[string]*querypb.BindVariable)
+		for _, table := range jp.tables {
+			for i := range table.columns {
+				bindvars[joinColumn{table: table, index: i}.bindVarName()] = sqltypes.ValueBindVariable(joinedRow[table.index][i])
+			}
+		}
+		if _, err := execParsedQuery(jp.insert, bindvars, executor); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// joinRows joins the rows of the next table to the joined rows, on the given
+// conditions. The rows of the next table are read with a single query for the
+// distinct values of all the joined rows, and matched to them with the collation
+// of their columns, as the source compares them.
+func (jp *joinPlan) joinRows(ctx context.Context, next *joinTable, conditions []*joinCondition, joinedRows [][][]sqltypes.Value) ([][][]sqltypes.Value, error) {
+	lookups := make(map[string]*joinLookup)
+	rowLookups := make([]*joinLookup, len(joinedRows))
+	bindvars := make(map[string]*querypb.BindVariable)
+	var matches []sqlparser.Expr
+joinedRowsLoop:
+	for r, joinedRow := range joinedRows {
+		values := make([]sqltypes.Value, 0, len(conditions))
+		var key strings.Builder
+		for _, cond := range conditions {
+			val := joinedRow[cond.right.table.index][cond.right.index]
+			if val.IsNull() {
+				// Null never joins.
+				continue joinedRowsLoop
+			}
+			values = append(values, val)
+			val.EncodeSQL(&key)
+			key.WriteByte(',')
+		}
+		lookup, ok := lookups[key.String()]
+		if !ok {
+			lookup = &joinLookup{values: values}
+			lookups[key.String()] = lookup
+			exprs := make([]sqlparser.Expr, 0, len(conditions))
+			for i, cond := range conditions {
+				name := fmt.Sprintf("v%d_%d", len(matches), i)
+				bindvars[name] = sqltypes.ValueBindVariable(values[i])
+				exprs = append(exprs, columnMatch(next.columns[cond.left.index], name, values[i]))
+			}
+			matches = append(matches, sqlparser.AndExpressions(exprs...))
+		}
+		rowLookups[r] = lookup
+	}
+	if len(matches) == 0 {
+		return nil, nil
+	}
+
+	rows, fields, err := jp.readRows(ctx, next, sqlparser.SplitAndExpression(nil, orExpressions(matches)), bindvars)
+	if err != nil {
+		return nil, err
+	}
+	for _, lookup := range lookups {
+	rowsLoop:
+		for _, row := range rows {
+			for i, cond := range conditions {
+				cmp, err := evalengine.NullsafeCompare(row[cond.left.index], lookup.values[i], jp.collationEnv, collations.ID(fields[cond.left.index].Charset), nil)
+				if err != nil {
+					return nil, err
+				}
+				if cmp != 0 {
+					continue rowsLoop
+				}
+			}
+			lookup.rows = append(lookup.rows, row)
+		}
+	}
+
+	var nextJoinedRows [][][]sqltypes.Value
+	for r, joinedRow := range joinedRows {
+		if rowLookups[r] == nil {
+			continue
+		}
+		for _, row := range rowLookups[r].rows {
+			nextJoinedRow := append([][]sqltypes.Value(nil), joinedRow...)
+			nextJoinedRow[next.index] = row
+			nextJoinedRows = append(nextJoinedRows, nextJoinedRow)
+		}
+	}
+	return nextJoinedRows, nil
+}
+
+// orExpressions ors together one or more expressions.
+func orExpressions(exprs []sqlparser.Expr) sqlparser.Expr {
+	result := exprs[0]
+	for _, expr := range exprs[1:] {
+		result = &sqlparser.OrExpr{Left: result, Right: expr}
+	}
+	return result
+}
+
+// columnMatch returns the condition that a column holds a value bound as name.
+func columnMatch(column sqlparser.IdentifierCI, name string, val sqltypes.Value) sqlparser.Expr {
+	if val.IsNull() {
+		return &sqlparser.IsExpr{Left: &sqlparser.ColName{Name: column}, Right: sqlparser.IsNullOp}
+	}
+	return &sqlparser.ComparisonExpr{Operator: sqlparser.EqualOp, Left: &sqlparser.ColName{Name: column}, Right: sqlparser.NewArgument(name)}
+}
+
+// readRows reads the rows of a table that match the conditions from the source,
+// along with the fields of the columns of the table.
+func (jp *joinPlan) readRows(ctx context.Context, table *joinTable, conditions []sqlparser.Expr, bindvars map[string]*querypb.BindVariable) ([][]sqltypes.Value, []*querypb.Field, error) {
+	query, err := jp.rowsQuery(table, conditions, bindvars)
+	if err != nil {
+		return nil, nil, err
+	}
+	return jp.streamRows(ctx, table, query)
+}
+
+// rowsQuery returns the query that selects the columns of the rows of a table
+// that match its filters and the conditions.
+func (jp *joinPlan) rowsQuery(table *joinTable, conditions []sqlparser.Expr, bindvars map[string]*querypb.BindVariable) (string, error) {
+	sel := &sqlparser.Select{
+		From: sqlparser.TableExprs{&sqlparser.AliasedTableExpr{Expr: sqlparser.NewTableName(table.name)}},
+	}
+	for _, col := range table.columns {
+		sel.AddSelectExpr(&sqlparser.AliasedExpr{Expr: &sqlparser.ColName{Name: col}})
+	}
+	for _, filter := range table.filters {
+		sel.AddWhere(filter)
+	}
+	for _, cond := range conditions {
+		sel.AddWhere(cond)
+	}
+	return sqlparser.NewParsedQuery(sel).GenerateQuery(bindvars, nil)
+}
+
+// streamRows streams the rows of a query on a table from the source, with
+// their values and fields in the order of the columns of the table.
+func (jp *joinPlan) streamRows(ctx context.Context, table *joinTable, query string) ([][]sqltypes.Value, []*querypb.Field, error) {
+	if jp.sourceVStreamer == nil {
+		return nil, nil, vterrors.Errorf(vtrpcpb.Code_INTERNAL, "no source to read the rows of table %s from", table.name)
+	}
+	var (
+		result       [][]sqltypes.Value
+		fields       []*querypb.Field
+		tableFields  []*querypb.Field
+		fieldIndexes []int
+	)
+	err := jp.sourceVStreamer.VStreamRows(ctx, query, nil, func(rows *binlogdatapb.VStreamRowsResponse) error {
+		// Only the first response carries the fields.
+		if fields == nil && len(rows.Fields) > 0 {
+			fields = rows.Fields
+			for _, col := range table.columns {
+				index := -1
+				for i, field := range fields {
+					if col.EqualString(field.Name) {
+						index = i
+						break
+					}
+				}
+				if index < 0 {
+					return fmt.Errorf("column %v not found in the rows streamed from the source", col)
+				}
+				fieldIndexes = append(fieldIndexes, index)
+				tableFields = append(tableFields, fields[index])
+			}
+		}
+		for _, row := range rows.Rows {
+			vals := sqltypes.MakeRowTrusted(fields, row)
+			tableRow := make([]sqltypes.Value, len(fieldIndexes))
+			for i, index := range fieldIndexes {
+				tableRow[i] = vals[index]
+			}
+			result = append(result, tableRow)
+		}
+		return nil
+	}, nil)
+	if err != nil {
+		return nil, nil, vterrors.Wrapf(err, "failed to read the rows of %s joined by %v from the source", table.name, jp.targetName)
+	}
+	return result, tableFields, nil
+}
@@ -0,0 +1,266 @@
+/*
+Copyright 2025 The Vitess Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package vreplication
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"vitess.io/vitess/go/sqltypes"
+	"vitess.io/vitess/go/vt/binlog/binlogplayer"
+	"vitess.io/vitess/go/vt/vtenv"
+
+	binlogdatapb "vitess.io/vitess/go/vt/proto/binlogdata"
+	querypb "vitess.io/vitess/go/vt/proto/query"
+	vttablet "vitess.io/vitess/go/vt/vttablet/common"
+)
+
+const testJoinFilter = "select o.id as order_id, o.amount * 2 as amount, c.name as customer_name, o.customer_id from orders as o join customers as c on o.customer_id = c.id where o.status = 'open'"
+
+func buildTestJoinPlan(t *testing.T, filter string, source VStreamerClient) (*ReplicatorPlan, error) {
+	vttablet.InitVReplicationConfigDefaults()
+	vr := &vreplicator{
+		workflowConfig:  vttablet.DefaultVReplicationConfig,
+		sourceVStreamer: source,
+	}
+	colInfos := map[string][]*ColumnInfo{
+		"t1": {&ColumnInfo{Name: "order_id", IsPK: true}},
+	}
+	rules := &binlogdatapb.Filter{
+		Rules: []*binlogdatapb.Rule{{
+			Match:  "t1",
+			Filter: filter,
+		}},
+	}
+	return vr.buildReplicatorPlan(getSource(rules), colInfos, nil, binlogplayer.NewStats(), vtenv.NewTestEnv())
+}
+
+func TestBuildJoinPlan(t *testing.T) {
+	plan, err := buildTestJoinPlan(t, testJoinFilter, nil)
+	require.NoError(t, err)
+
+	// Every table is streamed with the columns that locate the target rows
+	// that join its rows.
+	assert.Equal(t, []*binlogdatapb.Rule{{
+		Match:  "orders",
+		Filter: "select id, customer_id from orders",
+	}, {
+		Match:  "customers",
+		Filter: "select `name`, id from customers",
+	}}, plan.VStreamFilter.Rules)
+	require.Contains(t, plan.TargetTables, "t1")
+	tp := plan.TargetTables["t1"]
+	assert.Same(t, tp, plan.TablePlans["orders"])
+	require.Len(t, tp.JoinedTablePlans, 1)
+	assert.Same(t, tp.JoinedTablePlans[0], plan.TablePlans["customers"])
+	assert.Same(t, tp.Join, plan.TablePlans["customers"].Join)
+
+	jp := tp.Join
+	assert.Equal(t, "insert into t1(order_id,amount,customer_name,customer_id) values (:j0_id,:j0_amount * 2,:j1_name,:j0_customer_id)", jp.insert.Query)
+	assert.Equal(t, "delete from t1 where order_id<=>:k0 and customer_id<=>:k1", jp.table("orders").delete.Query)
+	assert.Equal(t, "delete from t1 where customer_name<=>:k0 and customer_id<=>:k1", jp.table("customers").delete.Query)
+
+	testcases := []struct {
+		filter string
+		err    string
+	}{{
+		filter: "select o.id as order_id, c.name from orders o left join customers c on o.customer_id = c.id",
+		err:    "unsupported join type: left join",
+	}, {
+		filter: "select o.id as order_id, c.name from orders o join customers c using (id)",
+		err:    "unsupported using clause in join",
+	}, {
+		filter: "select o.id as order_id, c.name from orders o join orders c on o.customer_id = c.id",
+		err:    "unsupported join of table orders with itself",
+	}, {
+		filter: "select o.id as order_id, c.name from orders o join customers c on o.customer_id > c.id",
+		err:    "unsupported join condition: o.customer_id > c.id",
+	}, {
+		filter: "select o.id as order_id, c.name from orders o join customers c on o.status = 'open'",
+		err:    "table c is not joined on an equality with the other tables",
+	}, {
+		filter: "select id as order_id, c.name from orders o join customers c on o.customer_id = c.id",
+		err:    "column id has to be qualified by its table in a join",
+	}, {
+		filter: "select o.id as order_id, count(*) as c from orders o join customers c on o.customer_id = c.id",
+		err:    "unsupported aggregation in a join: count(*) as c",
+	}, {
+		filter: "select o.id as order_id, c.name from orders o join customers c on o.customer_id = c.id group by o.id",
+		err:    "unsupported distinct, group by, having, order by or limit clause in a join",
+	}, {
+		filter: "select o.id as order_id, upper(c.name) as name from orders o join customers c on o.customer_id = c.id",
+		err:    "t1 has to include a column of table c, or a column it is joined on, to locate the rows that join it",
+	}, {
+		filter: "select o.id as order_id, x.name from orders o join customers c on o.customer_id = c.id",
+		err:    "unknown table x in column x.`name`",
+	}}
+	for _, tcase := range testcases {
+		t.Run(tcase.filter, func(t *testing.T) {
+			_, err := buildTestJoinPlan(t, tcase.filter, nil)
+			assert.ErrorContains(t, err, tcase.err)
+		})
+	}
+}
+
+func TestJoinPlanApplyChange(t *testing.T) {
+	source := &fakeRowsStreamer{
+		results: map[string]*sqltypes.Result{
+			"select id, `name` from customers where `name` = 'alicia' and id = 5": sqltypes.MakeTestResult(
+				sqltypes.MakeTestFields("id|name", "int64|varchar"),
+				"5|alicia",
+			),
+			"select customer_id, id, amount from orders where `status` = 'open' and customer_id = 5": sqltypes.MakeTestResult(
+				sqltypes.MakeTestFields("customer_id|id|amount", "int64|int64|int64"),
+				"5|1|10",
+				"5|2|20",
+			),
+			"select customer_id, id, amount from orders where `status` = 'open' and id = 3 and customer_id = 6": sqltypes.MakeTestResult(
+				sqltypes.MakeTestFields("customer_id|id|amount", "int64|int64|int64"),
+				"6|3|30",
+			),
+		},
+	}
+	plan, err := buildTestJoinPlan(t, testJoinFilter, source)
+	require.NoError(t, err)
+
+	testcases := []struct {
+		name          string
+		table         string
+		fields        []string
+		before, after []sqltypes.Value
+		wantQueries   []string
+		wantStreamed  []string
+	}{{
+		name:   "customer renamed",
+		table:  "customers",
+		fields: []string{"name|id", "varchar|int64"},
+		before: []sqltypes.Value{sqltypes.NewVarChar("alice"), sqltypes.NewInt64(5)},
+		after:  []sqltypes.Value{sqltypes.NewVarChar("alicia"), sqltypes.NewInt64(5)},
+		wantQueries: []string{
+			"delete from t1 where customer_name<=>'alice' and customer_id<=>5",
+			"delete from t1 where customer_name<=>'alicia' and customer_id<=>5",
+			"insert into t1(order_id,amount,customer_name,customer_id) values (1,10 * 2,'alicia',5)",
+			"insert into t1(order_id,amount,customer_name,customer_id) values (2,20 * 2,'alicia',5)",
+		},
+		wantStreamed: []string{
+			"select id, `name` from customers where `name` = 'alice' and id = 5",
+			"select id, `name` from customers where `name` = 'alicia' and id = 5",
+			"select customer_id, id, amount from orders where `status` = 'open' and customer_id = 5",
+		},
+	}, {
+		name:   "order of an unknown customer",
+		table:  "orders",
+		fields: []string{"id|customer_id", "int64|int64"},
+		after:  []sqltypes.Value{sqltypes.NewInt64(3), sqltypes.NewInt64(6)},
+		wantQueries: []string{
+			"delete from t1 where order_id<=>3 and customer_id<=>6",
+		},
+		wantStreamed: []string{
+			"select customer_id, id, amount from orders where `status` = 'open' and id = 3 and customer_id = 6",
+			"select id, `name` from customers where id = 6",
+		},
+	}, {
+		name:   "order deleted",
+		table:  "orders",
+		fields: []string{"id|customer_id", "int64|int64"},
+		before: []sqltypes.Value{sqltypes.NewInt64(4), sqltypes.NewInt64(5)},
+		wantQueries: []string{
+			"delete from t1 where order_id<=>4 and customer_id<=>5",
+		},
+		wantStreamed: []string{
+			"select customer_id, id, amount from orders where `status` = 'open' and id = 4 and customer_id = 5",
+		},
+	}}
+	for _, tcase := range testcases {
+		t.Run(tcase.name, func(t *testing.T) {
+			source.queries = nil
+			var queries []string
+			executor := func(query string) (*sqltypes.Result, error) {
+				queries = append(queries, query)
+				return &sqltypes.Result{}, nil
+			}
+			tp, err := plan.buildExecutionPlan(&binlogdatapb.FieldEvent{
+				TableName: tcase.table,
+				Fields:    sqltypes.MakeTestFields(tcase.fields[0], tcase.fields[1]),
+			})
+			require.NoError(t, err)
+			rowChange := &binlogdatapb.RowChange{}
+			if tcase.before != nil {
+				rowChange.Before = sqltypes.RowToProto3(tcase.before)
+			}
+			if tcase.after != nil {
+				rowChange.After = sqltypes.RowToProto3(tcase.after)
+			}
+			_, err = tp.applyChange(context.Background(), rowChange, executor)
+			require.NoError(t, err)
+			assert.Equal(t, tcase.wantQueries, queries)
+			assert.Equal(t, tcase.wantStreamed, source.queries)
+		})
+	}
+}
+
+func TestJoinPlanApplyRows(t *testing.T) {
+	source := &fakeRowsStreamer{
+		results: map[string]*sqltypes.Result{
+			"select customer_id, id, amount from orders where `status` = 'open' and (id = 1 and customer_id = 5 or id = 2 and customer_id = 5 or id = 3 and customer_id = 6)": sqltypes.MakeTestResult(
+				sqltypes.MakeTestFields("customer_id|id|amount", "int64|int64|int64"),
+				"5|1|10",
+				"5|2|20",
+				"6|3|30",
+			),
+			"select id, `name` from customers where id = 5 or id = 6": sqltypes.MakeTestResult(
+				sqltypes.MakeTestFields("id|name", "int64|varchar"),
+				"5|alice",
+			),
+		},
+	}
+	plan, err := buildTestJoinPlan(t, testJoinFilter, source)
+	require.NoError(t, err)
+	tp, err := plan.buildExecutionPlan(&binlogdatapb.FieldEvent{
+		TableName: "orders",
+		Fields:    sqltypes.MakeTestFields("id|customer_id", "int64|int64"),
+	})
+	require.NoError(t, err)
+
+	var queries []string
+	executor := func(query string) (*sqltypes.Result, error) {
+		queries = append(queries, query)
+		return &sqltypes.Result{}, nil
+	}
+	// The copied rows are refreshed with a single query per table.
+	rows := []*querypb.Row{
+		sqltypes.RowToProto3([]sqltypes.Value{sqltypes.NewInt64(1), sqltypes.NewInt64(5)}),
+		sqltypes.RowToProto3([]sqltypes.Value{sqltypes.NewInt64(2), sqltypes.NewInt64(5)}),
+		sqltypes.RowToProto3([]sqltypes.Value{sqltypes.NewInt64(3), sqltypes.NewInt64(6)}),
+	}
+	_, err = tp.Join.applyRows(context.Background(), tp, rows, executor)
+	require.NoError(t, err)
+	assert.Equal(t, []string{
+		"delete from t1 where order_id<=>1 and customer_id<=>5",
+		"delete from t1 where order_id<=>2 and customer_id<=>5",
+		"delete from t1 where order_id<=>3 and customer_id<=>6",
+		"insert into t1(order_id,amount,customer_name,customer_id) values (1,10 * 2,'alice',5)",
+		"insert into t1(order_id,amount,customer_name,customer_id) values (2,20 * 2,'alice',5)",
+	}, queries)
+	assert.Equal(t, []string{
+		"select customer_id, id, amount from orders where `status` = 'open' and (id = 1 and customer_id = 5 or id = 2 and customer_id = 5 or id = 3 and customer_id = 6)",
+		"select id, `name` from customers where id = 5 or id = 6",
+	}, source.queries)
+}
@@ -220,7 +220,7 @@ func newVCopierCopyWorker(
 func (vc *vcopier) initTablesForCopy(ctx context.Context) error {
 	defer vc.vr.dbClient.Rollback()
 
-	plan, err := vc.vr.buildReplicatorPlan(vc.vr.source, vc.vr.colInfoMap, nil, vc.vr.stats, vc.vr.vre.env.CollationEnv(), vc.vr.vre.env.Parser())
+	plan, err := vc.vr.buildReplicatorPlan(vc.vr.source, vc.vr.colInfoMap, nil, vc.vr.stats, vc.vr.vre.env)
 	if err != nil {
 		return err
 	}
@@ -289,13 +289,16 @@ func (vc *vcopier) initTablesForCopy(ctx context.Context) error {
 // copyNext also builds the copyState metadata that contains the tables and their last
 // primary key that was copied. A nil Result means that nothing has been copied.
 // A table that was fully copied is removed from copyState.
+// Tables that are copied concurrently, each from its own snapshot, are
+// handed over to copyConcurrently instead.
 func (vc *vcopier) copyNext(ctx context.Context, settings binlogplayer.VRSettings) error {
-	qr, err := vc.vr.dbClient.Execute(fmt.Sprintf("select table_name, lastpk from _vt.copy_state where vrepl_id = %d and id in (select max(id) from _vt.copy_state group by vrepl_id, table_name) order by table_name", vc.vr.id))
+	qr, err := vc.vr.dbClient.Execute(fmt.Sprintf("select table_name, lastpk, pos from _vt.copy_state where vrepl_id = %d and id in (select max(id) from _vt.copy_state group by vrepl_id, table_name) order by table_name", vc.vr.id))
 	if err != nil {
 		return err
 	}
 	var tableToCopy string
 	copyState := make(map[string]*sqltypes.Result)
+	positions := make(map[string]replication.Position)
 	for _, row := range qr.Rows {
 		tableName := row[0].ToString()
 		lastpk := row[1].ToString()
@@ -310,10 +313,20 @@ func (vc *vcopier) copyNext(ctx context.Context, settings binlogplayer.VRSetting
 			}
 			copyState[tableName] = sqltypes.Proto3ToResult(&r)
 		}
+		if pos := row[2].ToString(); pos != "" {
+			if positions[tableName], err = binlogplayer.DecodePosition(pos); err != nil {
+				return err
+			}
+		}
 	}
 	if len(copyState) == 0 {
 		return fmt.Errorf("unexpected: there are no tables to copy")
 	}
+	// Tables that were being copied concurrently are copied the same way,
+	// even if the number of concurrent tables was changed since.
+	if len(positions) != 0 || vc.copyTablesConcurrently(copyState) {
+		return vc.copyConcurrently(ctx, copyState, positions)
+	}
 	if err := vc.catchup(ctx, copyState); err != nil {
 		return err
 	}
@@ -328,7 +341,7 @@ func (vc *vcopier) catchup(ctx context.Context, copyState map[string]*sqltypes.R
 	defer cancel()
 	defer vc.vr.stats.PhaseTimings.Record("catchup", time.Now())
 
-	settings, err := binlogplayer.ReadVRSettings(vc.vr.dbClient, vc.vr.id)
+	settings, err := vc.readSettings()
 	if err != nil {
 		return err
 	}
@@ -381,7 +394,7 @@ func (vc *vcopier) copyTable(ctx context.Context, tableName string, copyState ma
 
 	log.Infof("Copying table %s, lastpk: %v", tableName, copyState[tableName])
 
-	plan, err := vc.vr.buildReplicatorPlan(vc.vr.source, vc.vr.colInfoMap, nil, vc.vr.stats, vc.vr.vre.env.CollationEnv(), vc.vr.vre.env.Parser())
+	plan, err := vc.vr.buildReplicatorPlan(vc.vr.source, vc.vr.colInfoMap, nil, vc.vr.stats, vc.vr.vre.env)
 	if err != nil {
 		return err
 	}
@@ -493,10 +506,18 @@ func (vc *vcopier) copyTable(ctx context.Context, tableName string, copyState ma
 				pkfields = append(pkfields, f.CloneVT())
 			}
 			buf := sqlparser.NewTrackedBuffer(nil)
-			buf.Myprintf(
-				"insert into _vt.copy_state (lastpk, vrepl_id, table_name) values (%a, %s, %s)", ":lastpk",
-				strconv.Itoa(int(vc.vr.id)),
-				encodeString(tableName))
+			if tc := vc.vr.concurrentCopy; tc != nil {
+				buf.Myprintf(
+					"insert into _vt.copy_state (lastpk, pos, vrepl_id, table_name) values (%a, %s, %s, %s)", ":lastpk",
+					encodeString(replication.EncodePosition(tc.pos)),
+					strconv.Itoa(int(vc.vr.id)),
+					encodeString(tableName))
+			} else {
+				buf.Myprintf(
+					"insert into _vt.copy_state (lastpk, vrepl_id, table_name) values (%a, %s, %s)", ":lastpk",
+					strconv.Itoa(int(vc.vr.id)),
+					encodeString(tableName))
+			}
 			addLatestCopyState := buf.ParsedQuery()
 			copyWorkQueue.open(addLatestCopyState, pkfields, tablePlan)
 		}
@@ -665,17 +686,36 @@ func (vc *vcopier) copyTable(ctx context.Context, tableName string, copyState ma
 	}
 
 	log.Infof("Copy of %v finished at lastpk: %v", tableName, lastpkbv)
+	if tc := vc.vr.concurrentCopy; tc != nil {
+		return tc.merge(ctx, vc.vr)
+	}
+	return vc.vr.deleteCopyState(tableName)
+}
+
+// deleteCopyState removes a table that was fully copied from the copy state,
+// along with its post copy actions.
+func (vr *vreplicator) deleteCopyState(tableName string) error {
 	buf := sqlparser.NewTrackedBuffer(nil)
 	buf.Myprintf(
 		"delete cs, pca from _vt.%s as cs left join _vt.%s as pca on cs.vrepl_id=pca.vrepl_id and cs.table_name=pca.table_name where cs.vrepl_id=%d and cs.table_name=%s",
 		copyStateTableName, postCopyActionTableName,
-		vc.vr.id, encodeString(tableName),
+		vr.id, encodeString(tableName),
 	)
-	if _, err := vc.vr.dbClient.Execute(buf.String()); err != nil {
-		return err
-	}
+	_, err := vr.dbClient.Execute(buf.String())
+	return err
+}
 
-	return nil
+// readSettings reads the settings of the workflow. A table that is copied
+// concurrently with others is replicated from its own position instead.
+func (vc *vcopier) readSettings() (binlogplayer.VRSettings, error) {
+	settings, err := binlogplayer.ReadVRSettings(vc.vr.dbClient, vc.vr.id)
+	if err != nil {
+		return settings, err
+	}
+	if tc := vc.vr.concurrentCopy; tc != nil {
+		settings.StartPos = tc.pos
+	}
+	return settings, nil
 }
 
 // updatePos is called after the last table is copied in an atomic copy, to set the gtid so that the replicating phase
@@ -696,16 +736,31 @@ func (vc *vcopier) fastForward(ctx context.Context, copyState map[string]*sqltyp
 	if err != nil {
 		return err
 	}
-	settings, err := binlogplayer.ReadVRSettings(vc.vr.dbClient, vc.vr.id)
+	settings, err := vc.readSettings()
 	if err != nil {
 		return err
 	}
+	tc := vc.vr.concurrentCopy
+	if tc != nil && (settings.StartPos.IsZero() || copyState[tc.name] == nil) {
+		// Nothing was copied from an earlier snapshot of the table, so its
+		// rows are consistent with this one.
+		tc.pos = pos
+		return nil
+	}
 	if settings.StartPos.IsZero() {
 		update := binlogplayer.GenerateUpdatePos(vc.vr.id, pos, time.Now().Unix(), 0, vc.vr.stats.CopyRowCount.Get(), vc.vr.workflowConfig.StoreCompressedGTID)
 		_, err := vc.vr.dbClient.Execute(update)
 		return err
 	}
-	return newVPlayer(vc.vr, settings, copyState, pos, "fastforward").play(ctx)
+	if err := newVPlayer(vc.vr, settings, copyState, pos, "fastforward").play(ctx); err != nil {
+		return err
+	}
+	if tc != nil && !tc.pos.AtLeast(pos) {
+		// The rows of the snapshot must not be copied before the table
+		// caught up with it.
+		return fmt.Errorf("fast forward of table %s stopped at %v before reaching %v", tc.name, tc.pos, pos)
+	}
+	return nil
 }
 
 func (vc *vcopier) newCopyWorkQueue(
@@ -1154,6 +1209,11 @@ func (vbc *vcopierCopyWorker) insertCopyState(ctx context.Context, lastpk *query
 }
 
 func (vbc *vcopierCopyWorker) insertRows(ctx context.Context, rows []*querypb.Row) (*sqltypes.Result, error) {
+	if vbc.tablePlan.Join != nil {
+		return vbc.tablePlan.Join.applyRows(ctx, vbc.tablePlan, rows, func(sql string) (*sqltypes.Result, error) {
+			return vbc.vdbClient.ExecuteWithRetry(ctx, sql)
+		})
+	}
 	return vbc.tablePlan.applyBulkInsert(
 		&vbc.sqlbuffer,
 		rows,
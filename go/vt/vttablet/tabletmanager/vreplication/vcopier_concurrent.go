@@ -0,0 +1,247 @@
+/*
+Copyright 2025 The Vitess Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package vreplication
+
+import (
+	"context"
+	"fmt"
+	"math"
+	"slices"
+	"sync"
+	"time"
+
+	"golang.org/x/exp/maps"
+	"golang.org/x/sync/errgroup"
+
+	"vitess.io/vitess/go/mysql/replication"
+	"vitess.io/vitess/go/sqltypes"
+	"vitess.io/vitess/go/vt/binlog/binlogplayer"
+	"vitess.io/vitess/go/vt/log"
+
+	binlogdatapb "vitess.io/vitess/go/vt/proto/binlogdata"
+)
+
+/*
+This file is similar to vcopier.go: it handles a copy phase in which several tables are copied
+at the same time. Every table is copied from a snapshot of its own, and is caught up and fast
+forwarded by a replicator of its own, from a position that is saved in its copy state. Once a
+table is fully copied, whichever of the table and the tables that were already copied is behind
+is fast forwarded to the position of the other, and the table is replicated by the workflow from
+then on.
+*/
+
+// concurrentCopy coordinates the tables of a workflow that are copied
+// concurrently.
+type concurrentCopy struct {
+	vr *vreplicator
+
+	// mu serializes the merges of the tables into the workflow.
+	mu sync.Mutex
+	// pending are the tables that are not merged into the workflow yet.
+	pending map[string]bool
+}
+
+// concurrentTableCopy is the state of a table that is copied concurrently
+// with others.
+type concurrentTableCopy struct {
+	name string
+	// pos is the position the copied rows of the table are consistent with.
+	pos replication.Position
+	cc  *concurrentCopy
+}
+
+// copyTablesConcurrently returns true if the workflow copies more than one
+// table at a time. Workflows that apply DDLs copy one table at a time, so that
+// the DDLs are applied once.
+func (vc *vcopier) copyTablesConcurrently(copyState map[string]*sqltypes.Result) bool {
+	switch vc.vr.source.OnDdl {
+	case binlogdatapb.OnDDLAction_EXEC, binlogdatapb.OnDDLAction_EXEC_IGNORE:
+		return false
+	}
+	return vc.vr.workflowConfig.CopyPhaseMaxConcurrentTables > 1 && len(copyState) > 1
+}
+
+// copyConcurrently copies up to CopyPhaseMaxConcurrentTables tables at a time,
+// each for up to the copy phase duration. Tables that are not fully copied by
+// then are resumed by the next call.
+func (vc *vcopier) copyConcurrently(ctx context.Context, copyState map[string]*sqltypes.Result, positions map[string]replication.Position) error {
+	settings, err := binlogplayer.ReadVRSettings(vc.vr.dbClient, vc.vr.id)
+	if err != nil {
+		return err
+	}
+	tableNames := maps.Keys(copyState)
+	slices.Sort(tableNames)
+	cc := &concurrentCopy{
+		vr:      vc.vr,
+		pending: make(map[string]bool, len(tableNames)),
+	}
+	for _, tableName := range tableNames {
+		cc.pending[tableName] = true
+	}
+
+	g, gctx := errgroup.WithContext(ctx)
+	g.SetLimit(max(1, vc.vr.workflowConfig.CopyPhaseMaxConcurrentTables))
+	for _, tableName := range tableNames {
+		if gctx.Err() != nil {
+			break
+		}
+		tc := &concurrentTableCopy{
+			name: tableName,
+			pos:  positions[tableName],
+			cc:   cc,
+		}
+		if tc.pos.IsZero() && copyState[tableName] != nil {
+			// The table was being copied from the position of the workflow,
+			// one table at a time.
+			tc.pos = settings.StartPos
+		}
+		lastpk := copyState[tableName]
+		g.Go(func() error {
+			return vc.copyTableConcurrently(gctx, tc, lastpk)
+		})
+	}
+	return g.Wait()
+}
+
+// copyTableConcurrently catches up and copies a table with a replicator of
+// its own.
+func (vc *vcopier) copyTableConcurrently(ctx context.Context, tc *concurrentTableCopy, lastpk *sqltypes.Result) error {
+	dbClient, err := vc.vr.newClientConnection(ctx)
+	if err != nil {
+		return err
+	}
+	defer dbClient.Close()
+
+	tvc := newVCopier(vc.vr.newTableReplicator(dbClient, tc))
+	copyState := map[string]*sqltypes.Result{tc.name: lastpk}
+	if lastpk != nil {
+		if err := tvc.catchup(ctx, copyState); err != nil {
+			return err
+		}
+	}
+	return tvc.copyTable(ctx, tc.name, copyState)
+}
+
+// newTableReplicator returns a replicator of a single table of the workflow,
+// which runs on its own connection. It shares the counters of the workflow,
+// but not its replication lag, which is the one of the table.
+func (vr *vreplicator) newTableReplicator(dbClient *vdbClient, tc *concurrentTableCopy) *vreplicator {
+	tvr := *vr
+	tvr.dbClient = dbClient
+	tvr.colInfoMap = map[string][]*ColumnInfo{tc.name: vr.colInfoMap[tc.name]}
+	tvr.concurrentCopy = tc
+	tvr.stats = &binlogplayer.Stats{
+		Timings:               vr.stats.Timings,
+		Rates:                 vr.stats.Rates,
+		History:               vr.stats.History,
+		PhaseTimings:          vr.stats.PhaseTimings,
+		QueryTimings:          vr.stats.QueryTimings,
+		QueryCount:            vr.stats.QueryCount,
+		BulkQueryCount:        vr.stats.BulkQueryCount,
+		TrxQueryBatchCount:    vr.stats.TrxQueryBatchCount,
+		CopyRowCount:          vr.stats.CopyRowCount,
+		CopyLoopCount:         vr.stats.CopyLoopCount,
+		ErrorCounts:           vr.stats.ErrorCounts,
+		NoopQueryCount:        vr.stats.NoopQueryCount,
+		VReplicationLags:      vr.stats.VReplicationLags,
+		VReplicationLagRates:  vr.stats.VReplicationLagRates,
+		TableCopyRowCounts:    vr.stats.TableCopyRowCounts,
+		TableCopyTimings:      vr.stats.TableCopyTimings,
+		PartialQueryCount:     vr.stats.PartialQueryCount,
+		PartialQueryCacheSize: vr.stats.PartialQueryCacheSize,
+		ThrottledCounts:       vr.stats.ThrottledCounts,
+		DDLEventActions:       vr.stats.DDLEventActions,
+		WorkflowConfig:        vr.stats.WorkflowConfig,
+	}
+	tvr.stats.ReplicationLagSeconds.Store(math.MaxInt64)
+	return &tvr
+}
+
+// generateUpdatePos returns the statement that saves the position of the
+// table in its latest copy state.
+func (tc *concurrentTableCopy) generateUpdatePos(pos replication.Position) string {
+	return fmt.Sprintf("update _vt.copy_state set pos = %s where vrepl_id = %d and table_name = %s order by id desc limit 1",
+		encodeString(replication.EncodePosition(pos)), tc.cc.vr.id, encodeString(tc.name))
+}
+
+// merge hands a fully copied table over to the workflow. If the table is
+// ahead of the tables that were already copied, those are fast forwarded to
+// the position of the table, otherwise the table is fast forwarded to theirs.
+// tvr is the replicator of the table.
+func (tc *concurrentTableCopy) merge(ctx context.Context, tvr *vreplicator) error {
+	cc := tc.cc
+	cc.mu.Lock()
+	defer cc.mu.Unlock()
+	defer cc.vr.stats.PhaseTimings.Record("merge", time.Now())
+
+	vr := cc.vr
+	settings, err := binlogplayer.ReadVRSettings(vr.dbClient, vr.id)
+	if err != nil {
+		return err
+	}
+	switch {
+	case settings.StartPos.IsZero():
+		// This is the first table that is fully copied.
+		update := binlogplayer.GenerateUpdatePos(vr.id, tc.pos, time.Now().Unix(), 0, vr.stats.CopyRowCount.Get(), vr.workflowConfig.StoreCompressedGTID)
+		if _, err := vr.dbClient.Execute(update); err != nil {
+			return err
+		}
+	case tc.pos.Equal(settings.StartPos):
+	case tc.pos.AtLeast(settings.StartPos):
+		// The tables that are not merged yet, including this one, have
+		// positions of their own.
+		copyState := make(map[string]*sqltypes.Result, len(cc.pending))
+		for tableName := range cc.pending {
+			copyState[tableName] = nil
+		}
+		if err := newVPlayer(vr, settings, copyState, tc.pos, "fastforward").play(ctx); err != nil {
+			return err
+		}
+		if settings, err = binlogplayer.ReadVRSettings(vr.dbClient, vr.id); err != nil {
+			return err
+		}
+		if !settings.StartPos.AtLeast(tc.pos) {
+			return tc.mergeStopped(ctx, settings.StartPos, tc.pos)
+		}
+	default:
+		tsettings := settings
+		tsettings.StartPos = tc.pos
+		if err := newVPlayer(tvr, tsettings, nil, settings.StartPos, "fastforward").play(ctx); err != nil {
+			return err
+		}
+		if !tc.pos.AtLeast(settings.StartPos) {
+			return tc.mergeStopped(ctx, tc.pos, settings.StartPos)
+		}
+	}
+	if err := vr.deleteCopyState(tc.name); err != nil {
+		return err
+	}
+	delete(cc.pending, tc.name)
+	log.Infof("Table %s was merged into workflow %s", tc.name, vr.WorkflowName)
+	return nil
+}
+
+// mergeStopped is called when a fast forward of a merge stopped short of its
+// position. If the copy phase was interrupted, the table is merged by the
+// next one.
+func (tc *concurrentTableCopy) mergeStopped(ctx context.Context, pos, stopPos replication.Position) error {
+	if ctx.Err() != nil {
+		log.Infof("Merge of table %s stopped at %v before reaching %v", tc.name, pos, stopPos)
+		return nil
+	}
+	return fmt.Errorf("merge of table %s stopped at %v before reaching %v", tc.name, pos, stopPos)
+}
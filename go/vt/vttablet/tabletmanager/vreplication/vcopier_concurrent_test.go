@@ -0,0 +1,355 @@
+/*
+Copyright 2025 The Vitess Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package vreplication
+
+import (
+	"context"
+	"fmt"
+	"math"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"vitess.io/vitess/go/mysql/replication"
+	"vitess.io/vitess/go/sqltypes"
+	"vitess.io/vitess/go/vt/binlog/binlogplayer"
+
+	binlogdatapb "vitess.io/vitess/go/vt/proto/binlogdata"
+	vttablet "vitess.io/vitess/go/vt/vttablet/common"
+)
+
+func TestCopyTablesConcurrently(t *testing.T) {
+	vttablet.InitVReplicationConfigDefaults()
+	twoTables := map[string]*sqltypes.Result{"t1": nil, "t2": nil}
+	testcases := []struct {
+		name       string
+		onDDL      binlogdatapb.OnDDLAction
+		concurrent int
+		copyState  map[string]*sqltypes.Result
+		want       bool
+	}{{
+		name:       "default",
+		concurrent: 1,
+		copyState:  twoTables,
+	}, {
+		name:       "concurrent",
+		concurrent: 4,
+		copyState:  twoTables,
+		want:       true,
+	}, {
+		name:       "single table",
+		concurrent: 4,
+		copyState:  map[string]*sqltypes.Result{"t1": nil},
+	}, {
+		name:       "exec ddl",
+		onDDL:      binlogdatapb.OnDDLAction_EXEC,
+		concurrent: 4,
+		copyState:  twoTables,
+	}, {
+		name:       "exec ignore ddl",
+		onDDL:      binlogdatapb.OnDDLAction_EXEC_IGNORE,
+		concurrent: 4,
+		copyState:  twoTables,
+	}}
+	for _, tcase := range testcases {
+		t.Run(tcase.name, func(t *testing.T) {
+			config := *vttablet.DefaultVReplicationConfig
+			config.CopyPhaseMaxConcurrentTables = tcase.concurrent
+			vc := newVCopier(&vreplicator{
+				source:         &binlogdatapb.BinlogSource{OnDdl: tcase.onDDL},
+				workflowConfig: &config,
+			})
+			assert.Equal(t, tcase.want, vc.copyTablesConcurrently(tcase.copyState))
+		})
+	}
+}
+
+func TestNewTableReplicator(t *testing.T) {
+	vr := &vreplicator{
+		id:    1,
+		stats: binlogplayer.NewStats(),
+		colInfoMap: map[string][]*ColumnInfo{
+			"t1": {{Name: "id", IsPK: true}},
+			"t2": {{Name: "id", IsPK: true}},
+		},
+	}
+	defer vr.stats.Stop()
+	vr.stats.ReplicationLagSeconds.Store(0)
+	cc := &concurrentCopy{vr: vr, pending: map[string]bool{"t1": true, "t2": true}}
+	tc := &concurrentTableCopy{name: "t2", cc: cc}
+
+	tvr := vr.newTableReplicator(nil, tc)
+	assert.Equal(t, tc, tvr.concurrentCopy)
+	assert.Nil(t, vr.concurrentCopy)
+	assert.Equal(t, map[string][]*ColumnInfo{"t2": vr.colInfoMap["t2"]}, tvr.colInfoMap)
+	assert.Len(t, vr.colInfoMap, 2)
+	assert.EqualValues(t, math.MaxInt64, tvr.stats.ReplicationLagSeconds.Load())
+	tvr.stats.CopyRowCount.Add(10)
+	assert.EqualValues(t, 10, vr.stats.CopyRowCount.Get())
+
+	pos, err := binlogplayer.DecodePosition("MySQL56/16b1039f-22b6-11ed-b765-0a43f95f28a3:1-10")
+	require.NoError(t, err)
+	assert.Equal(t, "update _vt.copy_state set pos = 'MySQL56/16b1039f-22b6-11ed-b765-0a43f95f28a3:1-10' where vrepl_id = 1 and table_name = 't2' order by id desc limit 1",
+		tc.generateUpdatePos(pos))
+	assert.Equal(t, "update _vt.copy_state set pos = '' where vrepl_id = 1 and table_name = 't2' order by id desc limit 1",
+		tc.generateUpdatePos(replication.Position{}))
+}
+
+// setCopyPhaseMaxConcurrentTables sets the number of tables that the
+// workflows of the test copy concurrently.
+func setCopyPhaseMaxConcurrentTables(t *testing.T, concurrent int) {
+	saved := vttablet.DefaultVReplicationConfig.CopyPhaseMaxConcurrentTables
+	vttablet.DefaultVReplicationConfig.CopyPhaseMaxConcurrentTables = concurrent
+	t.Cleanup(func() { vttablet.DefaultVReplicationConfig.CopyPhaseMaxConcurrentTables = saved })
+}
+
+// waitForWorkflowQuery waits for the query on the sidecar tables of the
+// workflow to return the given rows.
+func waitForWorkflowQuery(t *testing.T, query string, values [][]string) {
+	t.Helper()
+	require.EventuallyWithT(t, func(c *assert.CollectT) {
+		qr, err := env.Mysqld.FetchSuperQuery(context.Background(), query)
+		require.NoError(c, err)
+		var got [][]string
+		for _, row := range qr.Rows {
+			var vals []string
+			for _, val := range row {
+				vals = append(vals, val.ToString())
+			}
+			got = append(got, vals)
+		}
+		assert.Equal(c, values, got)
+	}, 30*time.Second, 100*time.Millisecond, "query %s", query)
+}
+
+func TestPlayerCopyTablesConcurrently(t *testing.T) {
+	// The tables are copied concurrently, so the order of the queries is not
+	// deterministic.
+	doNotLogDBQueries = true
+	defer func() { doNotLogDBQueries = false }()
+	setCopyPhaseMaxConcurrentTables(t, 2)
+	defer deleteTablet(addTablet(100))
+
+	execStatements(t, []string{
+		"create table src1(id int, val varchar(128), primary key(id))",
+		"insert into src1 values(1, 'aaa'), (2, 'bbb'), (3, 'ccc')",
+		fmt.Sprintf("create table %s.src1(id int, val varchar(128), primary key(id))", vrepldb),
+		"create table src2(id int, val varchar(128), primary key(id))",
+		"insert into src2 values(1, 'aaa'), (2, 'bbb')",
+		fmt.Sprintf("create table %s.src2(id int, val varchar(128), primary key(id))", vrepldb),
+		"create table src3(id int, val varchar(128), primary key(id))",
+		"insert into src3 values(1, 'aaa')",
+		fmt.Sprintf("create table %s.src3(id int, val varchar(128), primary key(id))", vrepldb),
+	})
+	defer execStatements(t, []string{
+		"drop table src1",
+		fmt.Sprintf("drop table %s.src1", vrepldb),
+		"drop table src2",
+		fmt.Sprintf("drop table %s.src2", vrepldb),
+		"drop table src3",
+		fmt.Sprintf("drop table %s.src3", vrepldb),
+	})
+
+	// Change all the tables before each snapshot, so that the tables are
+	// copied from different positions, and have to be fast forwarded to
+	// each other when they are merged into the workflow.
+	var mu sync.Mutex
+	hookCalls := 0
+	vstreamRowsHook = func(context.Context) {
+		mu.Lock()
+		defer mu.Unlock()
+		hookCalls++
+		execStatements(t, []string{
+			fmt.Sprintf("insert into src1 values(%d, 'new')", 10+hookCalls),
+			fmt.Sprintf("update src2 set val = 'updated %d' where id = 1", hookCalls),
+			fmt.Sprintf("insert into src3 values(%d, 'new')", 10+hookCalls),
+		})
+	}
+	defer func() { vstreamRowsHook = nil }()
+
+	filter := &binlogdatapb.Filter{
+		Rules: []*binlogdatapb.Rule{{
+			Match: "/src.*",
+		}},
+	}
+	bls := &binlogdatapb.BinlogSource{
+		Keyspace: env.KeyspaceName,
+		Shard:    env.ShardName,
+		Filter:   filter,
+		OnDdl:    binlogdatapb.OnDDLAction_IGNORE,
+	}
+	query := binlogplayer.CreateVReplicationState("test", bls, "", binlogdatapb.VReplicationWorkflowState_Init, playerEngine.dbName, 0, 0)
+	qr, err := playerEngine.Exec(query)
+	require.NoError(t, err)
+	defer func() {
+		_, err := playerEngine.Exec(fmt.Sprintf("delete from _vt.vreplication where id = %d", qr.InsertID))
+		require.NoError(t, err)
+	}()
+
+	waitForWorkflowQuery(t, fmt.Sprintf("select state from _vt.vreplication where id = %d", qr.InsertID), [][]string{{"Running"}})
+	waitForWorkflowQuery(t, fmt.Sprintf("select count(*) from _vt.copy_state where vrepl_id = %d", qr.InsertID), [][]string{{"0"}})
+	vstreamRowsHook = nil
+	mu.Lock()
+	calls := hookCalls
+	mu.Unlock()
+	require.Equal(t, 3, calls, "each table is copied from a snapshot of its own")
+
+	expectData(t, "src1", [][]string{
+		{"1", "aaa"}, {"2", "bbb"}, {"3", "ccc"}, {"11", "new"}, {"12", "new"}, {"13", "new"},
+	})
+	expectData(t, "src2", [][]string{
+		{"1", "updated 3"}, {"2", "bbb"},
+	})
+	expectData(t, "src3", [][]string{
+		{"1", "aaa"}, {"11", "new"}, {"12", "new"}, {"13", "new"},
+	})
+
+	// The workflow replicates all the tables once they are merged.
+	execStatements(t, []string{
+		"insert into src1 values(20, 'replicated')",
+		"delete from src2 where id = 2",
+		"update src3 set val = 'replicated' where id = 1",
+	})
+	expectData(t, "src1", [][]string{
+		{"1", "aaa"}, {"2", "bbb"}, {"3", "ccc"}, {"11", "new"}, {"12", "new"}, {"13", "new"}, {"20", "replicated"},
+	})
+	expectData(t, "src2", [][]string{
+		{"1", "updated 3"},
+	})
+	expectData(t, "src3", [][]string{
+		{"1", "replicated"}, {"11", "new"}, {"12", "new"}, {"13", "new"},
+	})
+}
+
+// TestPlayerCopyTablesConcurrentlyResume tests that a copy phase that copied
+// tables concurrently is resumed from the positions saved in the copy state,
+// even if the workflow now copies one table at a time.
+func TestPlayerCopyTablesConcurrentlyResume(t *testing.T) {
+	doNotLogDBQueries = true
+	defer func() { doNotLogDBQueries = false }()
+	setCopyPhaseMaxConcurrentTables(t, 1)
+	defer deleteTablet(addTablet(100))
+
+	execStatements(t, []string{
+		// copied was fully copied, and merged into the workflow.
+		"create table copied(id int, val varchar(128), primary key(id))",
+		fmt.Sprintf("create table %s.copied(id int, val varchar(128), primary key(id))", vrepldb),
+		// src1 was partially copied, up to id 2.
+		"create table src1(id int, val varchar(128), primary key(id))",
+		"insert into src1 values(1, 'aaa'), (2, 'bbb'), (3, 'ccc'), (4, 'ddd')",
+		fmt.Sprintf("create table %s.src1(id int, val varchar(128), primary key(id))", vrepldb),
+		fmt.Sprintf("insert into %s.src1 values(1, 'aaa'), (2, 'bbb')", vrepldb),
+		// src2 was not copied yet.
+		"create table src2(id int, val varchar(128), primary key(id))",
+		"insert into src2 values(1, 'aaa')",
+		fmt.Sprintf("create table %s.src2(id int, val varchar(128), primary key(id))", vrepldb),
+	})
+	defer execStatements(t, []string{
+		"drop table copied",
+		fmt.Sprintf("drop table %s.copied", vrepldb),
+		"drop table src1",
+		fmt.Sprintf("drop table %s.src1", vrepldb),
+		"drop table src2",
+		fmt.Sprintf("drop table %s.src2", vrepldb),
+	})
+
+	// src1 is consistent with tablePos, and is behind the workflow.
+	tablePos := primaryPosition(t)
+	execStatements(t, []string{
+		// Inside and outside the copied range of src1.
+		"update src1 set val = 'updated' where id in (1, 3)",
+		"insert into copied values(1, 'aaa')",
+	})
+	execStatements(t, []string{
+		fmt.Sprintf("insert into %s.copied values(1, 'aaa')", vrepldb),
+	})
+	workflowPos := primaryPosition(t)
+	execStatements(t, []string{
+		"update src1 set val = 'updated again' where id = 2",
+		"insert into src1 values(5, 'eee')",
+		"update copied set val = 'bbb' where id = 1",
+		"insert into src2 values(2, 'bbb')",
+	})
+
+	filter := &binlogdatapb.Filter{
+		Rules: []*binlogdatapb.Rule{{
+			Match: "copied",
+		}, {
+			Match: "src1",
+		}, {
+			Match: "src2",
+		}},
+	}
+	bls := &binlogdatapb.BinlogSource{
+		Keyspace: env.KeyspaceName,
+		Shard:    env.ShardName,
+		Filter:   filter,
+		OnDdl:    binlogdatapb.OnDDLAction_IGNORE,
+	}
+	query := binlogplayer.CreateVReplicationState("test", bls, "", binlogdatapb.VReplicationWorkflowState_Stopped, playerEngine.dbName, 0, 0)
+	qr, err := playerEngine.Exec(query)
+	require.NoError(t, err)
+	id := qr.InsertID
+	defer func() {
+		_, err := playerEngine.Exec(fmt.Sprintf("delete from _vt.vreplication where id = %d", id))
+		require.NoError(t, err)
+	}()
+	lastpk := sqltypes.ResultToProto3(sqltypes.MakeTestResult(
+		sqltypes.MakeTestFields(
+			"id",
+			"int32",
+		),
+		"2",
+	))
+	lastpk.RowsAffected = 0
+	execStatements(t, []string{
+		fmt.Sprintf("insert into _vt.copy_state (vrepl_id, table_name, lastpk, pos) values(%d, 'src1', %s, %s)", id, encodeString(fmt.Sprintf("%v", lastpk)), encodeString(tablePos)),
+		fmt.Sprintf("insert into _vt.copy_state (vrepl_id, table_name, lastpk, pos) values(%d, 'src2', null, '')", id),
+	})
+	_, err = playerEngine.Exec(fmt.Sprintf("update _vt.vreplication set state='Copying', pos=%s where id=%d", encodeString(workflowPos), id))
+	require.NoError(t, err)
+
+	waitForWorkflowQuery(t, fmt.Sprintf("select state from _vt.vreplication where id = %d", id), [][]string{{"Running"}})
+	waitForWorkflowQuery(t, fmt.Sprintf("select count(*) from _vt.copy_state where vrepl_id = %d", id), [][]string{{"0"}})
+
+	// src1 was caught up from its own position, rather than the one of the
+	// workflow, before the rest of it was copied.
+	expectData(t, "src1", [][]string{
+		{"1", "updated"}, {"2", "updated again"}, {"3", "updated"}, {"4", "ddd"}, {"5", "eee"},
+	})
+	// copied was fast forwarded by the merge of the tables.
+	expectData(t, "copied", [][]string{
+		{"1", "bbb"},
+	})
+	expectData(t, "src2", [][]string{
+		{"1", "aaa"}, {"2", "bbb"},
+	})
+
+	execStatements(t, []string{
+		"insert into copied values(2, 'replicated')",
+		"insert into src2 values(3, 'replicated')",
+	})
+	expectData(t, "copied", [][]string{
+		{"1", "bbb"}, {"2", "replicated"},
+	})
+	expectData(t, "src2", [][]string{
+		{"1", "aaa"}, {"2", "bbb"}, {"3", "replicated"},
+	})
+}
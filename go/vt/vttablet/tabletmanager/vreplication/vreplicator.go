@@ -116,6 +116,10 @@ type vreplicator struct {
 
 	throttleUpdatesRateLimiter *timer.RateLimiter
 	workflowConfig             *vttablet.VReplicationConfig
+
+	// concurrentCopy is set on the replicator of a table that is copied
+	// concurrently with others.
+	concurrentCopy *concurrentTableCopy
 }
 
 // newVReplicator creates a new vreplicator. The valid fields from the source are:
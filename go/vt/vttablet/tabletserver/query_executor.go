@@ -127,7 +127,7 @@ func (qre *QueryExecutor) Execute() (reply *sqltypes.Result, err error) {
 	qre.logStats.PlanType = planName
 	defer func(start time.Time) {
 		duration := time.Since(start)
-		qre.tsv.stats.QueryTimings.Add(planName, duration)
+		qre.tsv.stats.QueryTimings.AddContext(qre.ctx, planName, duration)
 		qre.tsv.stats.QueryTimingsByTabletType.Add(qre.targetTabletType.String(), duration)
 		qre.recordUserQuery("Execute", int64(duration))
 
@@ -341,7 +341,7 @@ func (qre *QueryExecutor) Stream(callback StreamCallback) error {
 	qre.logStats.PlanType = qre.plan.PlanID.String()
 
 	defer func(start time.Time) {
-		qre.tsv.stats.QueryTimings.Record(qre.plan.PlanID.String(), start)
+		qre.tsv.stats.QueryTimings.RecordContext(qre.ctx, qre.plan.PlanID.String(), start)
 		qre.tsv.stats.QueryTimingsByTabletType.Record(qre.targetTabletType.String(), start)
 		qre.recordUserQuery("Stream", int64(time.Since(start)))
 	}(time.Now())
@@ -431,7 +431,7 @@ func (qre *QueryExecutor) MessageStream(callback StreamCallback) error {
 	qre.logStats.PlanType = qre.plan.PlanID.String()
 
 	defer func(start time.Time) {
-		qre.tsv.stats.QueryTimings.Record(qre.plan.PlanID.String(), start)
+		qre.tsv.stats.QueryTimings.RecordContext(qre.ctx, qre.plan.PlanID.String(), start)
 		qre.tsv.stats.QueryTimingsByTabletType.Record(qre.targetTabletType.String(), start)
 		qre.recordUserQuery("MessageStream", int64(time.Since(start)))
 	}(time.Now())
@@ -911,15 +911,22 @@ func (qre *QueryExecutor) generateFinalSQL(parsedQuery *sqlparser.ParsedQuery, b
 		qre.marginComments.Leading = buf.String()
 	}
 
-	if qre.marginComments.Leading == "" && qre.marginComments.Trailing == "" {
+	trailing := qre.marginComments.Trailing
+	if qre.tsv.config.AnnotateQueriesTraceparent {
+		if traceparent, ok := trace.Traceparent(qre.ctx); ok {
+			trailing += " /*traceparent='" + traceparent + "'*/"
+		}
+	}
+
+	if qre.marginComments.Leading == "" && trailing == "" {
 		return query, query, nil
 	}
 
 	var buf strings.Builder
-	buf.Grow(len(qre.marginComments.Leading) + len(query) + len(qre.marginComments.Trailing))
+	buf.Grow(len(qre.marginComments.Leading) + len(query) + len(trailing))
 	buf.WriteString(qre.marginComments.Leading)
 	buf.WriteString(query)
-	buf.WriteString(qre.marginComments.Trailing)
+	buf.WriteString(trailing)
 	return buf.String(), query, nil
 }
 
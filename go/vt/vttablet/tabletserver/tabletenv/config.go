@@ -158,6 +158,7 @@ func registerTabletEnvFlags(fs *pflag.FlagSet) {
 	fs.BoolVar(&currentConfig.TerseErrors, "queryserver-config-terse-errors", defaultConfig.TerseErrors, "prevent bind vars from escaping in client error messages")
 	fs.IntVar(&currentConfig.TruncateErrorLen, "queryserver-config-truncate-error-len", defaultConfig.TruncateErrorLen, "truncate errors sent to client if they are longer than this value (0 means do not truncate)")
 	fs.BoolVar(&currentConfig.AnnotateQueries, "queryserver-config-annotate-queries", defaultConfig.AnnotateQueries, "prefix queries to MySQL backend with comment indicating vtgate principal (user) and target tablet type")
+	fs.BoolVar(&currentConfig.AnnotateQueriesTraceparent, "queryserver-config-annotate-queries-traceparent", defaultConfig.AnnotateQueriesTraceparent, "suffix queries to MySQL backend with a comment holding the W3C traceparent of their trace, when the tracer supports it")
 	utils.SetFlagBoolVar(fs, &currentConfig.WatchReplication, "watch-replication-stream", false, "When enabled, vttablet will stream the MySQL replication stream from the local server, and use it to update schema when it sees a DDL.")
 	fs.BoolVar(&currentConfig.TrackSchemaVersions, "track_schema_versions", false, "When enabled, vttablet will store versions of schemas at each position that a DDL is applied and allow retrieval of the schema corresponding to a position")
 	fs.Int64Var(&currentConfig.SchemaVersionMaxAgeSeconds, "schema-version-max-age-seconds", 0, "max age of schema version records to kept in memory by the vreplication historian")
@@ -341,6 +342,7 @@ type TabletConfig struct {
 	TerseErrors                 bool          `json:"terseErrors,omitempty"`
 	TruncateErrorLen            int           `json:"truncateErrorLen,omitempty"`
 	AnnotateQueries             bool          `json:"annotateQueries,omitempty"`
+	AnnotateQueriesTraceparent  bool          `json:"annotateQueriesTraceparent,omitempty"`
 	MessagePostponeParallelism  int           `json:"messagePostponeParallelism,omitempty"`
 	SignalWhenSchemaChange      bool          `json:"signalWhenSchemaChange,omitempty"`
 
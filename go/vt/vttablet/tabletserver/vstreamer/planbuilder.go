@@ -20,6 +20,7 @@ import (
 	"context"
 	"fmt"
 	"regexp"
+	"slices"
 	"strconv"
 	"strings"
 
@@ -64,10 +65,16 @@ type Plan struct {
 	// in the Filter's WHERE clause with the exception of the
 	// in_keyrange() function which is a filter that must be applied
 	// by the VStreamer (it's not a valid MySQL function). Note that
-	// the Filter cannot contain any MySQL functions because the
-	// VStreamer cannot filter binlog events using them.
+	// the Filter can only contain expressions that the evalengine
+	// can evaluate, because the VStreamer must filter binlog events
+	// using them.
 	whereExprsToPushDown []sqlparser.Expr
 
+	// exprEnv is used to evaluate the EvalExpr filters and the column
+	// expressions of the plan. It is only set when the plan has any.
+	// A plan is used by a single streamer, so it is not shared.
+	exprEnv *evalengine.ExpressionEnv
+
 	// Convert any integer values seen in the binlog events for ENUM or SET
 	// columns to the string values. The map is keyed on the column number, with
 	// the value being the map of ordinal values to string values.
@@ -107,6 +114,10 @@ const (
 	// in the plan we rewrite `x BETWEEN a AND b` to `x >= a AND x <= b`
 	// NotBetween is used to filter a comparable column if it doesn't lie within a specific range
 	NotBetween
+	// EvalExpr is used to filter on any other predicate, which is evaluated
+	// with the evalengine, like OR or NOT expressions, comparisons between
+	// columns, or comparisons of JSON values
+	EvalExpr
 )
 
 // Filter contains opcodes for filtering.
@@ -126,6 +137,9 @@ type Filter struct {
 	Vindex        vindexes.Vindex
 	VindexColumns []int
 	KeyRange      *topodatapb.KeyRange
+
+	// Expr is the predicate for EvalExpr.
+	Expr evalengine.Expr
 }
 
 // ColExpr represents a column expression.
@@ -143,6 +157,10 @@ type ColExpr struct {
 	Field *querypb.Field
 
 	FixedValue sqltypes.Value
+
+	// Expr, if set, is evaluated with the evalengine to generate
+	// the value. If so, ColNum is ignored.
+	Expr evalengine.Expr
 }
 
 // Table contains the metadata for a table.
@@ -283,6 +301,15 @@ func (plan *Plan) filter(values, result []sqltypes.Value, charsets []collations.
 			if !found {
 				return false, nil
 			}
+		case EvalExpr:
+			plan.exprEnv.Row = values
+			res, err := plan.exprEnv.Evaluate(filter.Expr)
+			if err != nil {
+				return false, err
+			}
+			if !res.ToBoolean() {
+				return false, nil
+			}
 		case NotBetween:
 			// Note that we do not implement filtering for BETWEEN because
 			// in the plan we rewrite `x BETWEEN a AND b` to `x >= a AND x <= b`
@@ -314,6 +341,15 @@ func (plan *Plan) filter(values, result []sqltypes.Value, charsets []collations.
 		}
 	}
 	for i, colExpr := range plan.ColExprs {
+		if colExpr.Expr != nil {
+			plan.exprEnv.Row = values
+			res, err := plan.exprEnv.Evaluate(colExpr.Expr)
+			if err != nil {
+				return false, err
+			}
+			result[i] = res.Value(collations.ID(colExpr.Field.Charset))
+			continue
+		}
 		if colExpr.ColNum == -1 {
 			result[i] = colExpr.FixedValue
 			continue
@@ -621,9 +657,17 @@ func (plan *Plan) analyzeWhere(vschema *localVSchema, where *sqlparser.Where) er
 	if where == nil {
 		return nil
 	}
-	// Only a series of AND expressions are supported.
+	// The AND expressions that compare a column with literal values use
+	// the dedicated filters, and the others are evaluated with the
+	// evalengine.
 	exprs := splitAndExpression(nil, where.Expr)
 	for _, expr := range exprs {
+		if !isColumnFilter(expr) {
+			if err := plan.appendEvalExprFilter(expr); err != nil {
+				return err
+			}
+			continue
+		}
 		switch expr := expr.(type) {
 		case *sqlparser.ComparisonExpr:
 			opcode, err := getOpcode(expr)
@@ -669,11 +713,7 @@ func (plan *Plan) analyzeWhere(vschema *localVSchema, where *sqlparser.Where) er
 			// Add it to the expressions that get pushed down to mysqld.
 			plan.whereExprsToPushDown = append(plan.whereExprsToPushDown, expr)
 		case *sqlparser.FuncExpr:
-			// We cannot filter binlog events in VStreamer using MySQL functions so
-			// we only allow the in_keyrange() function, which is VStreamer specific.
-			if !expr.Name.EqualString("in_keyrange") {
-				return fmt.Errorf("unsupported constraint: %v", sqlparser.String(expr))
-			}
+			// The in_keyrange() function is VStreamer specific.
 			if err := plan.analyzeInKeyRange(vschema, expr.Exprs); err != nil {
 				return err
 			}
@@ -762,6 +802,94 @@ func (plan *Plan) analyzeWhere(vschema *localVSchema, where *sqlparser.Where) er
 	return nil
 }
 
+// isColumnFilter returns true if the expression compares a column with
+// literal values in a way that a dedicated filter supports, or is an
+// in_keyrange() function.
+func isColumnFilter(expr sqlparser.Expr) bool {
+	isLiteral := func(expr sqlparser.Expr) bool {
+		_, ok := expr.(*sqlparser.Literal)
+		return ok
+	}
+	switch expr := expr.(type) {
+	case *sqlparser.ComparisonExpr:
+		if _, ok := expr.Left.(*sqlparser.ColName); !ok {
+			return false
+		}
+		opcode, err := getOpcode(expr)
+		if err != nil {
+			return false
+		}
+		if opcode == In {
+			values, ok := expr.Right.(sqlparser.ValTuple)
+			return ok && !slices.ContainsFunc(values, func(value sqlparser.Expr) bool { return !isLiteral(value) })
+		}
+		return isLiteral(expr.Right)
+	case *sqlparser.FuncExpr:
+		return expr.Name.EqualString("in_keyrange")
+	case *sqlparser.IsExpr:
+		_, ok := expr.Left.(*sqlparser.ColName)
+		return ok && (expr.Right == sqlparser.IsNullOp || expr.Right == sqlparser.IsNotNullOp)
+	case *sqlparser.BetweenExpr:
+		_, ok := expr.Left.(*sqlparser.ColName)
+		return ok && isLiteral(expr.From) && isLiteral(expr.To)
+	}
+	return false
+}
+
+// translateExpr translates an expression of the columns of the table
+// for the evalengine. Only expressions whose value depends on nothing
+// but the row are supported, as rows are filtered when their binlog
+// events are streamed, not when they are written.
+func (plan *Plan) translateExpr(expr sqlparser.Expr) (evalengine.Expr, error) {
+	err := sqlparser.Walk(func(node sqlparser.SQLNode) (bool, error) {
+		switch node := node.(type) {
+		case *sqlparser.ColName:
+			if !node.Qualifier.IsEmpty() {
+				return false, fmt.Errorf("unsupported qualifier for column: %v", sqlparser.String(node))
+			}
+			if _, err := findColumn(plan.Table, node.Name); err != nil {
+				return false, err
+			}
+		case *sqlparser.FuncExpr, sqlparser.AggrFunc, *sqlparser.CurTimeFuncExpr, *sqlparser.Subquery,
+			*sqlparser.Argument, *sqlparser.Variable:
+			return false, vterrors.Errorf(vtrpcpb.Code_UNIMPLEMENTED, "unsupported: %v", sqlparser.String(node))
+		}
+		return true, nil
+	}, expr)
+	if err != nil {
+		return nil, err
+	}
+	if plan.exprEnv == nil {
+		plan.exprEnv = evalengine.EmptyExpressionEnv(plan.env)
+	}
+	fields := evalengine.FieldResolver(plan.Table.Fields)
+	return evalengine.Translate(expr, &evalengine.Config{
+		ResolveColumn: fields.Column,
+		ResolveType:   fields.Type,
+		Collation:     plan.env.CollationEnv().DefaultConnectionCharset(),
+		Environment:   plan.env,
+	})
+}
+
+// appendEvalExprFilter adds a filter for a predicate that is evaluated
+// with the evalengine.
+func (plan *Plan) appendEvalExprFilter(expr sqlparser.Expr) error {
+	evalExpr, err := plan.translateExpr(expr)
+	if vterrors.Code(err) == vtrpcpb.Code_UNIMPLEMENTED {
+		return fmt.Errorf("unsupported constraint: %v", sqlparser.String(expr))
+	}
+	if err != nil {
+		return err
+	}
+	plan.Filters = append(plan.Filters, Filter{
+		Opcode: EvalExpr,
+		Expr:   evalExpr,
+	})
+	// Add it to the expressions that get pushed down to mysqld.
+	plan.whereExprsToPushDown = append(plan.whereExprsToPushDown, expr)
+	return nil
+}
+
 // splitAndExpression breaks up the Expr into AND-separated conditions
 // and appends them to filters, which can be shuffled and recombined
 // as needed.
@@ -937,8 +1065,29 @@ func (plan *Plan) analyzeExpr(vschema *localVSchema, selExpr sqlparser.SelectExp
 			Field:  field,
 		}, nil
 	default:
-		log.Infof("Unsupported expression: %v", inner)
-		return ColExpr{}, fmt.Errorf("unsupported: %v", sqlparser.String(aliased.Expr))
+		// Any other expression of the columns is evaluated with the
+		// evalengine, so that only the values that are needed are sent.
+		evalExpr, err := plan.translateExpr(aliased.Expr)
+		if vterrors.Code(err) == vtrpcpb.Code_UNIMPLEMENTED {
+			log.Infof("Unsupported expression: %v", inner)
+			return ColExpr{}, fmt.Errorf("unsupported: %v", sqlparser.String(aliased.Expr))
+		}
+		if err != nil {
+			return ColExpr{}, err
+		}
+		typ, err := plan.exprEnv.TypeOf(evalExpr)
+		if err != nil {
+			return ColExpr{}, err
+		}
+		name := aliased.As.String()
+		if aliased.As.IsEmpty() {
+			name = sqlparser.String(aliased.Expr)
+		}
+		return ColExpr{
+			ColNum: -1,
+			Field:  typ.ToField(name),
+			Expr:   evalExpr,
+		}, nil
 	}
 }
 
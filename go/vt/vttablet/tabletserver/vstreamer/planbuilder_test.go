@@ -741,8 +741,8 @@ func TestPlanBuilder(t *testing.T) {
 		outErr:  `unsupported function: max(val)`,
 	}, {
 		inTable: t1,
-		inRule:  &binlogdatapb.Rule{Match: "t1", Filter: "select id+1, val from t1"},
-		outErr:  `unsupported: id + 1`,
+		inRule:  &binlogdatapb.Rule{Match: "t1", Filter: "select id, now() from t1"},
+		outErr:  `unsupported: now()`,
 	}, {
 		inTable: t1,
 		inRule:  &binlogdatapb.Rule{Match: "t1", Filter: "select t1.id, val from t1"},
@@ -895,6 +895,116 @@ func TestPlanBuilderFilterComparison(t *testing.T) {
 	}
 }
 
+func TestPlanBuilderEvalExpr(t *testing.T) {
+	t1 := &Table{
+		Name: "t1",
+		Fields: []*querypb.Field{{
+			Name:    "id",
+			Type:    sqltypes.Int64,
+			Charset: collations.CollationBinaryID,
+			Flags:   uint32(querypb.MySqlFlag_BINARY_FLAG | querypb.MySqlFlag_NUM_FLAG),
+		}, {
+			Name:    "status",
+			Type:    sqltypes.VarChar,
+			Charset: uint32(collations.CollationUtf8mb4ID),
+		}, {
+			Name:    "doc",
+			Type:    sqltypes.TypeJSON,
+			Charset: collations.CollationBinaryID,
+			Flags:   uint32(querypb.MySqlFlag_BINARY_FLAG | querypb.MySqlFlag_BLOB_FLAG),
+		}},
+	}
+	row := func(id int64, status, doc string) []sqltypes.Value {
+		return []sqltypes.Value{sqltypes.NewInt64(id), sqltypes.NewVarChar(status), sqltypes.MakeTrusted(sqltypes.TypeJSON, []byte(doc))}
+	}
+	type check struct {
+		row    []sqltypes.Value
+		want   bool
+		values []string
+	}
+	testcases := []struct {
+		name     string
+		inFilter string
+		checks   []check
+		outErr   string
+	}{{
+		name:     "or",
+		inFilter: "select id, status from t1 where status = 'open' or id > 10",
+		checks: []check{
+			{row: row(1, "open", "{}"), want: true, values: []string{"1", "open"}},
+			{row: row(2, "closed", "{}"), want: false},
+			{row: row(11, "closed", "{}"), want: true, values: []string{"11", "closed"}},
+		},
+	}, {
+		name:     "not-and-literal-on-left",
+		inFilter: "select id from t1 where 1 < id and not (status in ('open', 'new') or status is null)",
+		checks: []check{
+			{row: row(1, "closed", "{}"), want: false},
+			{row: row(2, "new", "{}"), want: false},
+			{row: row(2, "closed", "{}"), want: true, values: []string{"2"}},
+		},
+	}, {
+		name:     "json-projection",
+		inFilter: "select id, doc->>'$.state' as state from t1 where doc->>'$.kind' = 'order'",
+		checks: []check{
+			{row: row(1, "open", `{"kind": "order", "state": "paid", "items": [1, 2, 3]}`), want: true, values: []string{"1", "paid"}},
+			{row: row(2, "open", `{"kind": "refund", "state": "paid"}`), want: false},
+		},
+	}, {
+		name:     "column-comparison",
+		inFilter: "select id + 1 from t1 where id = 1 and status = doc->>'$.status'",
+		checks: []check{
+			{row: row(1, "open", `{"status": "open"}`), want: true, values: []string{"2"}},
+			{row: row(1, "open", `{"status": "closed"}`), want: false},
+		},
+	}, {
+		name:     "non-deterministic",
+		inFilter: "select id from t1 where id = 1 or status = now()",
+		outErr:   "unsupported constraint: id = 1 or `status` = now()",
+	}, {
+		name:     "unknown-column",
+		inFilter: "select id from t1 where id = 1 or state = 'open'",
+		outErr:   "column state not found in table t1",
+	}, {
+		name:     "qualifier",
+		inFilter: "select id from t1 where id = 1 or t2.id = 2",
+		outErr:   "unsupported qualifier for column: t2.id",
+	}}
+	for _, tcase := range testcases {
+		t.Run(tcase.name, func(t *testing.T) {
+			plan, err := buildPlan(vtenv.NewTestEnv(), t1, testLocalVSchema, &binlogdatapb.Filter{
+				Rules: []*binlogdatapb.Rule{{Match: "t1", Filter: tcase.inFilter}},
+			})
+			if tcase.outErr != "" {
+				assert.Nil(t, plan)
+				assert.EqualError(t, err, tcase.outErr)
+				return
+			}
+			require.NoError(t, err)
+			charsets := make([]collations.ID, len(t1.Fields))
+			for i, field := range t1.Fields {
+				charsets[i] = collations.ID(field.Charset)
+			}
+			// The same filter applies to the before and after images of
+			// the row changes, and to the rows of the copy phase.
+			for _, check := range tcase.checks {
+				result := make([]sqltypes.Value, len(plan.ColExprs))
+				ok, err := plan.filter(check.row, result, charsets)
+				require.NoError(t, err)
+				require.Equal(t, check.want, ok, "row %v", check.row)
+				if !ok {
+					continue
+				}
+				var values []string
+				for _, value := range result {
+					values = append(values, value.ToString())
+				}
+				assert.Equal(t, check.values, values)
+			}
+		})
+	}
+}
+
 func TestCompare(t *testing.T) {
 	type testcase struct {
 		opcode                   Opcode